@@ -0,0 +1,199 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	nephiodeployv1alpha1 "github.com/nephio-project/api/nf_deployments/v1alpha1"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nephio-project/nephio/krm-functions/lib/kptrl"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Entry describes a published revision of a blueprint package
+type Entry struct {
+	// Repository is the name of the porch repository of the package
+	Repository string `json:"repository"`
+	// Package is the name of the package
+	Package string `json:"package"`
+	// Revision is the revision of the package, e.g. v1
+	Revision string `json:"revision"`
+	// Latest is true for the latest revision of the package
+	Latest bool `json:"latest,omitempty"`
+	// Description is the description of the Kptfile of the package
+	Description string `json:"description,omitempty"`
+	// RequirementKinds are the kinds of the req.nephio.org requirements of the package, e.g. Interface
+	RequirementKinds []string `json:"requirementKinds,omitempty"`
+	// NFs are the network functions deployed by the package, e.g. upf for an UPFDeployment
+	NFs []string `json:"nfs,omitempty"`
+	// Namespace is the namespace of the PackageRevision of the package
+	Namespace string `json:"namespace"`
+	// PackageRevision is the name of the PackageRevision of the package
+	PackageRevision string `json:"packageRevision"`
+}
+
+func (r Entry) key() types.NamespacedName {
+	return types.NamespacedName{Namespace: r.Namespace, Name: r.PackageRevision}
+}
+
+// Query selects the entries of the catalog, an empty field selects all the entries
+type Query struct {
+	// Repository selects the packages of a repository
+	Repository string `json:"repository,omitempty"`
+	// Package selects the revisions of a package
+	Package string `json:"package,omitempty"`
+	// NF selects the packages deploying a network function, e.g. upf
+	NF string `json:"nf,omitempty"`
+	// RequirementKind selects the packages with a requirement of the kind, e.g. Interface
+	RequirementKind string `json:"requirementKind,omitempty"`
+	// Latest selects the latest revision of the packages only
+	Latest bool `json:"latest,omitempty"`
+}
+
+// Matches returns true when the entry is selected by the query, the nfs and requirement kinds are
+// matched case insensitively
+func (r Query) Matches(e Entry) bool {
+	if r.Repository != "" && r.Repository != e.Repository {
+		return false
+	}
+	if r.Package != "" && r.Package != e.Package {
+		return false
+	}
+	if r.Latest && !e.Latest {
+		return false
+	}
+	if r.NF != "" && !containsFold(e.NFs, r.NF) {
+		return false
+	}
+	if r.RequirementKind != "" && !containsFold(e.RequirementKinds, r.RequirementKind) {
+		return false
+	}
+	return true
+}
+
+func containsFold(l []string, s string) bool {
+	for _, x := range l {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Catalog indexes the blueprint packages by their PackageRevision, it is safe for concurrent use
+type Catalog struct {
+	m       sync.RWMutex
+	entries map[types.NamespacedName]Entry
+}
+
+// New returns an empty catalog
+func New() *Catalog {
+	return &Catalog{entries: map[types.NamespacedName]Entry{}}
+}
+
+// Set adds or replaces the entry of its PackageRevision
+func (r *Catalog) Set(e Entry) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.entries[e.key()] = e
+}
+
+// Get returns the entry of a PackageRevision
+func (r *Catalog) Get(nsn types.NamespacedName) (Entry, bool) {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	e, ok := r.entries[nsn]
+	return e, ok
+}
+
+// Delete removes the entry of a PackageRevision, if any
+func (r *Catalog) Delete(nsn types.NamespacedName) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	delete(r.entries, nsn)
+}
+
+// Search returns the entries selected by the query, sorted by repository, package and revision
+func (r *Catalog) Search(q Query) []Entry {
+	r.m.RLock()
+	entries := []Entry{}
+	for _, e := range r.entries {
+		if q.Matches(e) {
+			entries = append(entries, e)
+		}
+	}
+	r.m.RUnlock()
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Repository != entries[j].Repository {
+			return entries[i].Repository < entries[j].Repository
+		}
+		if entries[i].Package != entries[j].Package {
+			return entries[i].Package < entries[j].Package
+		}
+		return entries[i].Revision < entries[j].Revision
+	})
+	return entries
+}
+
+// NewEntry returns the entry of a package revision with the files of the package
+func NewEntry(pr *porchv1alpha1.PackageRevision, resources map[string]string) (Entry, error) {
+	e := Entry{
+		Repository:      pr.Spec.RepositoryName,
+		Package:         pr.Spec.PackageName,
+		Revision:        pr.Spec.Revision,
+		Latest:          pr.GetLabels()[porchv1alpha1.LatestPackageRevisionKey] == porchv1alpha1.LatestPackageRevisionValue,
+		Namespace:       pr.GetNamespace(),
+		PackageRevision: pr.GetName(),
+	}
+	rl, err := kptrl.GetResourceList(resources)
+	if err != nil {
+		return Entry{}, err
+	}
+	if kptfile := rl.Items.GetRootKptfile(); kptfile != nil {
+		e.Description, _, _ = kptfile.NestedString("info", "description")
+	}
+	requirementKinds := map[string]struct{}{}
+	nfs := map[string]struct{}{}
+	for _, o := range rl.Items {
+		gvk := o.GroupVersionKind()
+		switch {
+		case gvk.Group == nephioreqv1alpha1.Group:
+			requirementKinds[gvk.Kind] = struct{}{}
+		case gvk.Group == nephiodeployv1alpha1.Group && strings.HasSuffix(gvk.Kind, "Deployment"):
+			nfs[strings.ToLower(strings.TrimSuffix(gvk.Kind, "Deployment"))] = struct{}{}
+		}
+	}
+	e.RequirementKinds = sortedKeys(requirementKinds)
+	e.NFs = sortedKeys(nfs)
+	return e, nil
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
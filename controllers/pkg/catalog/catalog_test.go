@@ -0,0 +1,173 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"testing"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const kptfile = `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: pkg-example-upf-bp
+info:
+  description: free5gc upf blueprint
+`
+
+const upf = `apiVersion: workload.nephio.org/v1alpha1
+kind: UPFDeployment
+metadata:
+  name: upf
+---
+apiVersion: req.nephio.org/v1alpha1
+kind: Interface
+metadata:
+  name: n3
+---
+apiVersion: req.nephio.org/v1alpha1
+kind: Interface
+metadata:
+  name: n4
+---
+apiVersion: req.nephio.org/v1alpha1
+kind: Capacity
+metadata:
+  name: dataplane
+`
+
+func newPackageRevision(name, pkg, revision string, latest bool) *porchv1alpha1.PackageRevision {
+	pr := &porchv1alpha1.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: porchv1alpha1.PackageRevisionSpec{
+			RepositoryName: "catalog",
+			PackageName:    pkg,
+			Revision:       revision,
+			Lifecycle:      porchv1alpha1.PackageRevisionLifecyclePublished,
+		},
+	}
+	if latest {
+		pr.SetLabels(map[string]string{porchv1alpha1.LatestPackageRevisionKey: porchv1alpha1.LatestPackageRevisionValue})
+	}
+	return pr
+}
+
+func TestNewEntry(t *testing.T) {
+	cases := map[string]struct {
+		resources map[string]string
+		want      Entry
+	}{
+		"Blueprint": {
+			resources: map[string]string{"Kptfile": kptfile, "upf.yaml": upf, "README.md": "# upf"},
+			want: Entry{
+				Repository:       "catalog",
+				Package:          "pkg-example-upf-bp",
+				Revision:         "v1",
+				Latest:           true,
+				Description:      "free5gc upf blueprint",
+				RequirementKinds: []string{"Capacity", "Interface"},
+				NFs:              []string{"upf"},
+				Namespace:        "default",
+				PackageRevision:  "catalog-upf-v1",
+			},
+		},
+		"NoRequirements": {
+			resources: map[string]string{"Kptfile": kptfile},
+			want: Entry{
+				Repository:      "catalog",
+				Package:         "pkg-example-upf-bp",
+				Revision:        "v1",
+				Latest:          true,
+				Description:     "free5gc upf blueprint",
+				Namespace:       "default",
+				PackageRevision: "catalog-upf-v1",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewEntry(newPackageRevision("catalog-upf-v1", "pkg-example-upf-bp", "v1", true), tc.resources)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	c := New()
+	for _, e := range []Entry{
+		{Repository: "catalog", Package: "upf", Revision: "v2", Latest: true, NFs: []string{"upf"}, RequirementKinds: []string{"Capacity", "Interface"}},
+		{Repository: "catalog", Package: "upf", Revision: "v1", NFs: []string{"upf"}, RequirementKinds: []string{"Interface"}},
+		{Repository: "catalog", Package: "smf", Revision: "v1", Latest: true, NFs: []string{"smf"}, RequirementKinds: []string{"Interface"}},
+		{Repository: "mgmt", Package: "nephio-workload-cluster", Revision: "v1", Latest: true},
+	} {
+		e.Namespace = "default"
+		e.PackageRevision = e.Repository + "-" + e.Package + "-" + e.Revision
+		c.Set(e)
+	}
+	c.Delete(types.NamespacedName{Namespace: "default", Name: "mgmt-nephio-workload-cluster-v1"})
+
+	cases := map[string]struct {
+		q    Query
+		want []string
+	}{
+		"All": {
+			want: []string{"catalog-smf-v1", "catalog-upf-v1", "catalog-upf-v2"},
+		},
+		"NF": {
+			q:    Query{NF: "UPF"},
+			want: []string{"catalog-upf-v1", "catalog-upf-v2"},
+		},
+		"Latest": {
+			q:    Query{NF: "upf", Latest: true},
+			want: []string{"catalog-upf-v2"},
+		},
+		"RequirementKind": {
+			q:    Query{RequirementKind: "capacity"},
+			want: []string{"catalog-upf-v2"},
+		},
+		"Package": {
+			q:    Query{Repository: "catalog", Package: "smf"},
+			want: []string{"catalog-smf-v1"},
+		},
+		"NoMatch": {
+			q:    Query{Repository: "mgmt"},
+			want: []string{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := []string{}
+			for _, e := range c.Search(tc.q) {
+				got = append(got, e.PackageRevision)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// HTTPPath is the path of the search requests of the http api
+	HTTPPath = "/v1alpha1/packages"
+	// ServiceName is the name of the catalog service of the grpc api
+	ServiceName = "catalog.nephio.org.v1alpha1.Catalog"
+	// JSONCodecName is the content subtype of the grpc api, the messages are encoded in json
+	JSONCodecName = "json"
+)
+
+// SearchResponse holds the entries selected by a Query
+type SearchResponse struct {
+	Entries []Entry `json:"entries"`
+}
+
+// CatalogServer is the server of the catalog service
+type CatalogServer interface {
+	Search(context.Context, *Query) (*SearchResponse, error)
+}
+
+// Server serves the search of the catalog over grpc and http, such that UIs and CLIs discover the
+// blueprint packages without cloning the repositories
+type Server struct {
+	catalog     *Catalog
+	grpcAddress string
+	httpAddress string
+	l           logr.Logger
+}
+
+// NewServer returns the server of the catalog, an empty address disables its api
+func NewServer(c *Catalog, grpcAddress, httpAddress string) *Server {
+	return &Server{
+		catalog:     c,
+		grpcAddress: grpcAddress,
+		httpAddress: httpAddress,
+		l:           ctrl.Log.WithName("catalog"),
+	}
+}
+
+// Search returns the entries of the catalog selected by the query
+func (r *Server) Search(ctx context.Context, q *Query) (*SearchResponse, error) {
+	return &SearchResponse{Entries: r.catalog.Search(*q)}, nil
+}
+
+// ServeHTTP serves the search requests of the http api, a GET of HTTPPath with the fields of the
+// Query as parameters is answered with a json SearchResponse
+func (r *Server) ServeHTTP(w http.ResponseWriter, hr *http.Request) {
+	if hr.URL.Path != HTTPPath {
+		http.NotFound(w, hr)
+		return
+	}
+	if hr.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	params := hr.URL.Query()
+	q := &Query{
+		Repository:      params.Get("repository"),
+		Package:         params.Get("package"),
+		NF:              params.Get("nf"),
+		RequirementKind: params.Get("requirementKind"),
+	}
+	if latest := params.Get("latest"); latest != "" {
+		var err error
+		if q.Latest, err = strconv.ParseBool(latest); err != nil {
+			http.Error(w, fmt.Sprintf("invalid latest parameter: %s", latest), http.StatusBadRequest)
+			return
+		}
+	}
+	resp, err := r.Search(hr.Context(), q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		r.l.Error(err, "cannot write response")
+	}
+}
+
+// Start serves the apis until the context is done
+func (r *Server) Start(ctx context.Context) error {
+	errCh := make(chan error, 2)
+	if r.grpcAddress != "" {
+		lis, err := net.Listen("tcp", r.grpcAddress)
+		if err != nil {
+			return err
+		}
+		gs := grpc.NewServer()
+		RegisterCatalogServer(gs, r)
+		go func() {
+			errCh <- gs.Serve(lis)
+		}()
+		defer gs.GracefulStop()
+		r.l.Info("serving grpc api", "address", r.grpcAddress)
+	}
+	if r.httpAddress != "" {
+		hs := &http.Server{Addr: r.httpAddress, Handler: r, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := hs.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				errCh <- err
+			}
+		}()
+		defer hs.Shutdown(context.Background()) //nolint:errcheck
+		r.l.Info("serving http api", "address", r.httpAddress)
+	}
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
+
+// NeedLeaderElection returns false, every replica of the manager indexes the packages and serves
+// the apis
+func (r *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// jsonCodec encodes the messages of the grpc api in json, the api has no protobuf definition. It
+// is the codec of the specializer service, registered under the same name
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return JSONCodecName }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*CatalogServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Search", Handler: searchHandler},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// RegisterCatalogServer registers the catalog service on the grpc server
+func RegisterCatalogServer(s grpc.ServiceRegistrar, srv CatalogServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+func searchHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	req := &Query{}
+	if err := dec(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		resp, err := srv.(CatalogServer).Search(ctx, req.(*Query))
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return resp, nil
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fmt.Sprintf("/%s/Search", ServiceName)}
+	return interceptor(ctx, req, info, handler)
+}
+
+// Client is the client of the grpc api
+type Client struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewClient returns the client of the catalog service of the connection
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{cc: cc}
+}
+
+// Search returns the entries of the catalog selected by the query
+func (r *Client) Search(ctx context.Context, q *Query, opts ...grpc.CallOption) (*SearchResponse, error) {
+	resp := &SearchResponse{}
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(JSONCodecName)}, opts...)
+	if err := r.cc.Invoke(ctx, fmt.Sprintf("/%s/Search", ServiceName), q, resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestServer() *Server {
+	c := New()
+	c.Set(Entry{
+		Repository:       "catalog",
+		Package:          "upf",
+		Revision:         "v1",
+		Latest:           true,
+		NFs:              []string{"upf"},
+		RequirementKinds: []string{"Interface"},
+		Namespace:        "default",
+		PackageRevision:  "catalog-upf-v1",
+	})
+	c.Set(Entry{
+		Repository:      "catalog",
+		Package:         "smf",
+		Revision:        "v1",
+		NFs:             []string{"smf"},
+		Namespace:       "default",
+		PackageRevision: "catalog-smf-v1",
+	})
+	return NewServer(c, "", "")
+}
+
+func TestServerHTTP(t *testing.T) {
+	cases := map[string]struct {
+		method   string
+		target   string
+		wantCode int
+		want     []string
+	}{
+		"All": {
+			method:   http.MethodGet,
+			target:   HTTPPath,
+			wantCode: http.StatusOK,
+			want:     []string{"smf", "upf"},
+		},
+		"Query": {
+			method:   http.MethodGet,
+			target:   HTTPPath + "?nf=upf&requirementKind=Interface&latest=true",
+			wantCode: http.StatusOK,
+			want:     []string{"upf"},
+		},
+		"NoMatch": {
+			method:   http.MethodGet,
+			target:   HTTPPath + "?nf=smf&latest=true",
+			wantCode: http.StatusOK,
+			want:     []string{},
+		},
+		"InvalidLatest": {
+			method:   http.MethodGet,
+			target:   HTTPPath + "?latest=maybe",
+			wantCode: http.StatusBadRequest,
+		},
+		"Method": {
+			method:   http.MethodPost,
+			target:   HTTPPath,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		"Path": {
+			method:   http.MethodGet,
+			target:   "/packages",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newTestServer().ServeHTTP(w, httptest.NewRequest(tc.method, tc.target, nil))
+			if w.Code != tc.wantCode {
+				t.Fatalf("want code %d, got %d: %s", tc.wantCode, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			resp := &SearchResponse{}
+			if err := json.NewDecoder(w.Body).Decode(resp); err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, e := range resp.Entries {
+				got = append(got, e.Package)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestServerGRPC(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	RegisterCatalogServer(gs, newTestServer())
+	go func() {
+		_ = gs.Serve(lis)
+	}()
+	defer gs.Stop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	resp, err := NewClient(conn).Search(context.Background(), &Query{NF: "upf"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	want := []Entry{{
+		Repository:       "catalog",
+		Package:          "upf",
+		Revision:         "v1",
+		Latest:           true,
+		NFs:              []string{"upf"},
+		RequirementKinds: []string{"Interface"},
+		Namespace:        "default",
+		PackageRevision:  "catalog-upf-v1",
+	}}
+	if diff := cmp.Diff(want, resp.Entries); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
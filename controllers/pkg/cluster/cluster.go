@@ -21,20 +21,32 @@ import (
 	"strings"
 
 	"github.com/nephio-project/nephio/controllers/pkg/cluster/capi"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/external"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/fleet"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// Provider returns the cluster client of a kubeconfig secret when the secret
+// has the signature of the cluster provider
+type Provider func(c client.Client, secret *corev1.Secret) (ClusterClient, bool)
+
+// providers are tried in order, the first provider recognizing the secret wins
+var providers = []Provider{
+	capiProvider,
+	fleetProvider,
+	externalProvider,
+}
+
 type Cluster struct {
 	client.Client
 }
 
 func (r Cluster) GetClusterClient(secret *corev1.Secret) (ClusterClient, bool) {
-	switch string(secret.Type) {
-	case "cluster.x-k8s.io/secret":
-		if strings.Contains(secret.GetName(), "kubeconfig") {
-			return &capi.Capi{Client: r.Client, Secret: secret}, true
+	for _, p := range providers {
+		if cl, ok := p(r.Client, secret); ok {
+			return cl, true
 		}
 	}
 	return nil, false
@@ -44,3 +56,27 @@ type ClusterClient interface {
 	GetClusterClient(context.Context) (resource.APIPatchingApplicator, bool, error)
 	GetClusterName() string
 }
+
+func capiProvider(c client.Client, secret *corev1.Secret) (ClusterClient, bool) {
+	switch string(secret.Type) {
+	case "cluster.x-k8s.io/secret":
+		if strings.Contains(secret.GetName(), "kubeconfig") {
+			return &capi.Capi{Client: c, Secret: secret}, true
+		}
+	}
+	return nil, false
+}
+
+func fleetProvider(c client.Client, secret *corev1.Secret) (ClusterClient, bool) {
+	if _, ok := fleet.Memberships[secret.GetLabels()[external.ClusterProviderKey]]; ok {
+		return &fleet.Fleet{Client: c, Secret: secret}, true
+	}
+	return nil, false
+}
+
+func externalProvider(c client.Client, secret *corev1.Secret) (ClusterClient, bool) {
+	if secret.GetLabels()[external.ClusterProviderKey] == external.ProviderName {
+		return &external.External{Secret: secret}, true
+	}
+	return nil, false
+}
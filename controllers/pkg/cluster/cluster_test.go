@@ -42,6 +42,33 @@ func TestGetClusterClient(t *testing.T) {
 			},
 			want: true,
 		},
+		"External": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "a",
+					Labels: map[string]string{"nephio.org/cluster-provider": "external"},
+				},
+			},
+			want: true,
+		},
+		"AzureFleet": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "a",
+					Labels: map[string]string{"nephio.org/cluster-provider": "azurefleet"},
+				},
+			},
+			want: true,
+		},
+		"UnknownProvider": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "a",
+					Labels: map[string]string{"nephio.org/cluster-provider": "unknown"},
+				},
+			},
+			want: false,
+		},
 	}
 
 	for name, tc := range cases {
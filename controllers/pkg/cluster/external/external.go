@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package external
+
+import (
+	"context"
+
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ClusterProviderKey is the label of the kubeconfig secrets of the clusters not managed by cluster api,
+	// its value is the provider of the cluster
+	ClusterProviderKey = "nephio.org/cluster-provider"
+	// ClusterNameKey is the label holding the name of the cluster of the kubeconfig secret, by default the
+	// name of the secret is the name of the cluster
+	ClusterNameKey = "nephio.org/cluster-name"
+	// KubeConfigKey is the key of the kubeconfig in the data of the secret
+	KubeConfigKey = "kubeconfig"
+	// ProviderName is the provider of the externally registered clusters
+	ProviderName = "external"
+)
+
+// External is a cluster registered by a kubeconfig secret labeled with the external provider,
+// the cluster is ready as soon as its kubeconfig is available
+type External struct {
+	Secret *corev1.Secret
+}
+
+func (r *External) GetClusterName() string {
+	return GetClusterName(r.Secret)
+}
+
+func (r *External) GetClusterClient(ctx context.Context) (resource.APIPatchingApplicator, bool, error) {
+	return GetClusterClient(r.Secret)
+}
+
+// GetClusterName returns the name of the cluster of the kubeconfig secret
+func GetClusterName(secret *corev1.Secret) string {
+	if secret == nil {
+		return ""
+	}
+	if name, ok := secret.GetLabels()[ClusterNameKey]; ok && name != "" {
+		return name
+	}
+	return secret.GetName()
+}
+
+// GetClusterClient returns the client of the cluster of the kubeconfig secret
+func GetClusterClient(secret *corev1.Secret) (resource.APIPatchingApplicator, bool, error) {
+	//provide a rest config from the secret value
+	config, err := clientcmd.RESTConfigFromKubeConfig(secret.Data[KubeConfigKey])
+	if err != nil {
+		return resource.APIPatchingApplicator{}, false, err
+	}
+	// build a cluster client from the kube rest config
+	clClient, err := client.New(config, client.Options{})
+	if err != nil {
+		return resource.APIPatchingApplicator{}, false, err
+	}
+	return resource.NewAPIPatchingApplicator(clClient), true, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fleet
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster/external"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// AzureProviderName is the provider of the members of an Azure Kubernetes Fleet
+	AzureProviderName = "azurefleet"
+	// GKEProviderName is the provider of the members of a GKE fleet
+	GKEProviderName = "gkefleet"
+)
+
+// Membership is the fleet membership object gating the readiness of a member cluster
+type Membership struct {
+	GVK schema.GroupVersionKind
+	// Namespaced defines if the membership object lives in the namespace of the kubeconfig secret
+	Namespaced bool
+	// ConditionType is the type of the condition reporting the cluster joined the fleet
+	ConditionType string
+}
+
+// Memberships holds the membership object per fleet provider
+var Memberships = map[string]Membership{
+	AzureProviderName: {
+		GVK:           schema.GroupVersionKind{Group: "fleet.azure.com", Version: "v1beta1", Kind: "MemberCluster"},
+		ConditionType: "Joined",
+	},
+	GKEProviderName: {
+		GVK:           schema.GroupVersionKind{Group: "gkehub.cnrm.cloud.google.com", Version: "v1beta1", Kind: "GKEHubMembership"},
+		Namespaced:    true,
+		ConditionType: "Ready",
+	},
+}
+
+// Fleet is a member cluster of a fleet registered by a kubeconfig secret labeled with the fleet
+// provider, the cluster is ready when its membership object, named after the cluster, joined the fleet
+type Fleet struct {
+	client.Client
+	Secret *corev1.Secret
+	l      logr.Logger
+}
+
+func (r *Fleet) GetClusterName() string {
+	return external.GetClusterName(r.Secret)
+}
+
+func (r *Fleet) GetClusterClient(ctx context.Context) (resource.APIPatchingApplicator, bool, error) {
+	if !r.isMemberReady(ctx) {
+		return resource.APIPatchingApplicator{}, false, nil
+	}
+	return external.GetClusterClient(r.Secret)
+}
+
+func (r *Fleet) isMemberReady(ctx context.Context) bool {
+	r.l = log.FromContext(ctx)
+	m, ok := Memberships[r.Secret.GetLabels()[external.ClusterProviderKey]]
+	if !ok {
+		return false
+	}
+
+	key := types.NamespacedName{Name: r.GetClusterName()}
+	if m.Namespaced {
+		key.Namespace = r.Secret.GetNamespace()
+	}
+	u := resource.GetUnstructuredFromGVK(&m.GVK)
+	if err := r.Get(ctx, key, u); err != nil {
+		r.l.Error(err, "cannot get fleet membership", "kind", m.GVK.Kind, "name", key.Name)
+		return false
+	}
+	return isReady(u, m.ConditionType)
+}
+
+func isReady(u *unstructured.Unstructured, conditionType string) bool {
+	cs, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range cs {
+		c, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		if c["type"] == conditionType && c["status"] == string(corev1.ConditionTrue) {
+			return true
+		}
+	}
+	return false
+}
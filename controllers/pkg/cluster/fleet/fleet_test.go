@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fleet
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestIsReady(t *testing.T) {
+	cases := map[string]struct {
+		conditions    []any
+		conditionType string
+		want          bool
+	}{
+		"NoConditions": {
+			conditions:    nil,
+			conditionType: "Joined",
+			want:          false,
+		},
+		"Joined": {
+			conditions: []any{
+				map[string]any{"type": "Joined", "status": "True"},
+			},
+			conditionType: "Joined",
+			want:          true,
+		},
+		"NotJoined": {
+			conditions: []any{
+				map[string]any{"type": "Joined", "status": "False"},
+			},
+			conditionType: "Joined",
+			want:          false,
+		},
+		"OtherCondition": {
+			conditions: []any{
+				map[string]any{"type": "Ready", "status": "True"},
+			},
+			conditionType: "Joined",
+			want:          false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			u := &unstructured.Unstructured{Object: map[string]any{}}
+			if tc.conditions != nil {
+				if err := unstructured.SetNestedSlice(u.Object, tc.conditions, "status", "conditions"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := isReady(u, tc.conditionType)
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+
+	"code.gitea.io/sdk/gitea"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+// giteaProvider manages the repositories of the gitea user of the git secret; a token scoped to a
+// repository is the token of a dedicated user, collaborator of the repository, which requires an admin user
+type giteaProvider struct {
+	client *gitea.Client
+	url    string
+}
+
+func newGitea(url string, secret *corev1.Secret) (Provider, error) {
+	// To create/list tokens we can only use basic authentication using username and password
+	client, err := gitea.NewClient(url,
+		gitea.SetHTTPClient(newHTTPClient()),
+		gitea.SetBasicAuth(string(secret.Data["username"]), string(secret.Data["password"])))
+	if err != nil {
+		return nil, err
+	}
+	return &giteaProvider{client: client, url: url}, nil
+}
+
+func (r *giteaProvider) GetUserName(ctx context.Context) (string, error) {
+	u, _, err := r.client.GetMyUserInfo()
+	if err != nil {
+		return "", err
+	}
+	return u.UserName, nil
+}
+
+func (r *giteaProvider) GetRepo(ctx context.Context, name string) (*Repository, error) {
+	owner, err := r.GetUserName(ctx)
+	if err != nil {
+		return nil, err
+	}
+	repo, resp, err := r.client.GetRepo(owner, name)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return giteaRepository(repo), nil
+}
+
+func (r *giteaProvider) CreateRepo(ctx context.Context, opts RepositoryOptions) (*Repository, error) {
+	createRepo := gitea.CreateRepoOption{Name: opts.Name}
+	if opts.Description != nil {
+		createRepo.Description = *opts.Description
+	}
+	if opts.Private != nil {
+		createRepo.Private = *opts.Private
+	}
+	if opts.IssueLabels != nil {
+		createRepo.IssueLabels = *opts.IssueLabels
+	}
+	if opts.Gitignores != nil {
+		createRepo.Gitignores = *opts.Gitignores
+	}
+	if opts.License != nil {
+		createRepo.License = *opts.License
+	}
+	if opts.Readme != nil {
+		createRepo.Readme = *opts.Readme
+	}
+	if opts.DefaultBranch != nil {
+		createRepo.DefaultBranch = *opts.DefaultBranch
+	}
+	if opts.TrustModel != nil {
+		createRepo.TrustModel = gitea.TrustModel(*opts.TrustModel)
+	}
+	createRepo.AutoInit = true
+
+	repo, _, err := r.client.CreateRepo(createRepo)
+	if err != nil {
+		return nil, err
+	}
+	return giteaRepository(repo), nil
+}
+
+func (r *giteaProvider) EditRepo(ctx context.Context, name string, opts RepositoryOptions) (*Repository, error) {
+	owner, err := r.GetUserName(ctx)
+	if err != nil {
+		return nil, err
+	}
+	editRepo := gitea.EditRepoOption{
+		Name:          pointer.String(opts.Name),
+		Description:   opts.Description,
+		Private:       opts.Private,
+		DefaultBranch: opts.DefaultBranch,
+	}
+	repo, _, err := r.client.EditRepo(owner, name, editRepo)
+	if err != nil {
+		return nil, err
+	}
+	return giteaRepository(repo), nil
+}
+
+func (r *giteaProvider) DeleteRepo(ctx context.Context, name string) error {
+	owner, err := r.GetUserName(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.DeleteRepo(owner, name)
+	return err
+}
+
+func (r *giteaProvider) ArchiveRepo(ctx context.Context, name string) error {
+	owner, err := r.GetUserName(ctx)
+	if err != nil {
+		return err
+	}
+	_, _, err = r.client.EditRepo(owner, name, gitea.EditRepoOption{Archived: pointer.Bool(true)})
+	return err
+}
+
+func (r *giteaProvider) AddDeployKey(ctx context.Context, repo string, key DeployKey) error {
+	owner, err := r.GetUserName(ctx)
+	if err != nil {
+		return err
+	}
+	keys, _, err := r.client.ListDeployKeys(owner, repo, gitea.ListDeployKeysOptions{})
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if k.Title == key.Title {
+			return nil
+		}
+	}
+	_, _, err = r.client.CreateDeployKey(owner, repo, gitea.CreateKeyOption{Title: key.Title, Key: key.Key, ReadOnly: key.ReadOnly})
+	return err
+}
+
+func (r *giteaProvider) ProtectBranch(ctx context.Context, repo, branch string) error {
+	owner, err := r.GetUserName(ctx)
+	if err != nil {
+		return err
+	}
+	if _, resp, err := r.client.GetBranchProtection(owner, repo, branch); err == nil {
+		return nil
+	} else if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return err
+	}
+	// force pushes are rejected by a protected branch, pushes of the owner are allowed
+	_, _, err = r.client.CreateBranchProtection(owner, repo, gitea.CreateBranchProtectionOption{
+		BranchName:             branch,
+		EnablePush:             true,
+		EnablePushWhitelist:    true,
+		PushWhitelistUsernames: []string{owner},
+	})
+	return err
+}
+
+func (r *giteaProvider) ListTokens(ctx context.Context, scope *TokenScope) ([]string, error) {
+	if scope != nil {
+		// the users of the scoped tokens are the collaborators of the repository
+		owner, err := r.GetUserName(ctx)
+		if err != nil {
+			return nil, err
+		}
+		users, _, err := r.client.ListCollaborators(owner, scope.Repository, gitea.ListCollaboratorsOptions{})
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(users))
+		for _, u := range users {
+			names = append(names, u.UserName)
+		}
+		return names, nil
+	}
+	tokens, _, err := r.client.ListAccessTokens(gitea.ListAccessTokensOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		names = append(names, t.Name)
+	}
+	return names, nil
+}
+
+func (r *giteaProvider) CreateToken(ctx context.Context, name string, scope *TokenScope) (*Token, error) {
+	client := r.client
+	if scope != nil {
+		var err error
+		if client, err = r.createScopedUser(ctx, name, scope); err != nil {
+			return nil, err
+		}
+	}
+	token, _, err := client.CreateAccessToken(gitea.CreateAccessTokenOption{
+		Name: name,
+		Scopes: []gitea.AccessTokenScope{
+			gitea.AccessTokenScopeRepo,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	t := &Token{Name: token.Name, Token: token.Token}
+	if scope != nil {
+		t.UserName = name
+	}
+	return t, nil
+}
+
+// createScopedUser creates the user of the scoped token as collaborator of the repository of the scope,
+// it returns a client authenticated as the user
+func (r *giteaProvider) createScopedUser(ctx context.Context, name string, scope *TokenScope) (*gitea.Client, error) {
+	owner, err := r.GetUserName(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	password := base64.RawURLEncoding.EncodeToString(b)
+	if _, _, err := r.client.AdminCreateUser(gitea.CreateUserOption{
+		Username:           name,
+		Email:              name + "@noreply.nephio.org",
+		Password:           password,
+		MustChangePassword: pointer.Bool(false),
+	}); err != nil {
+		return nil, err
+	}
+	permission := gitea.AccessModeWrite
+	if scope.ReadOnly {
+		permission = gitea.AccessModeRead
+	}
+	if _, err := r.client.AddCollaborator(owner, scope.Repository, name, gitea.AddCollaboratorOption{Permission: &permission}); err != nil {
+		return nil, err
+	}
+	return gitea.NewClient(r.url, gitea.SetBasicAuth(name, password))
+}
+
+func (r *giteaProvider) DeleteToken(ctx context.Context, name string, scope *TokenScope) error {
+	if scope != nil {
+		// deleting the user of the scoped token revokes its token and access to the repository
+		resp, err := r.client.AdminDeleteUser(name)
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return err
+		}
+		return nil
+	}
+	_, err := r.client.DeleteAccessToken(name)
+	return err
+}
+
+func giteaRepository(repo *gitea.Repository) *Repository {
+	return &Repository{Name: repo.Name, CloneURL: repo.CloneURL, DefaultBranch: repo.DefaultBranch}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// githubProvider manages the repositories of the user authenticated with the token of the git
+// secret, or of the organization of GIT_ORG; the api of github does not manage access tokens
+type githubProvider struct {
+	restClient
+	org string
+}
+
+func newGitHub(baseURL string, secret *corev1.Secret) Provider {
+	return &githubProvider{
+		restClient: restClient{
+			client:  newHTTPClient(),
+			baseURL: strings.TrimSuffix(baseURL, "/"),
+			header: http.Header{
+				"Authorization": []string{"Bearer " + getToken(secret)},
+				"Accept":        []string{"application/vnd.github+json"},
+			},
+		},
+		org: os.Getenv("GIT_ORG"),
+	}
+}
+
+type githubRepository struct {
+	Name          string `json:"name"`
+	CloneURL      string `json:"clone_url"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+func (r *githubProvider) GetUserName(ctx context.Context) (string, error) {
+	u := struct {
+		Login string `json:"login"`
+	}{}
+	if err := r.do(ctx, http.MethodGet, "/user", nil, &u); err != nil {
+		return "", err
+	}
+	return u.Login, nil
+}
+
+// repoPath returns the path of the repository of the organization or of the user
+func (r *githubProvider) repoPath(ctx context.Context, name string) (string, error) {
+	owner := r.org
+	if owner == "" {
+		var err error
+		if owner, err = r.GetUserName(ctx); err != nil {
+			return "", err
+		}
+	}
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name), nil
+}
+
+func (r *githubProvider) GetRepo(ctx context.Context, name string) (*Repository, error) {
+	p, err := r.repoPath(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	repo := &githubRepository{}
+	if err := r.do(ctx, http.MethodGet, p, nil, repo); err != nil {
+		return nil, err
+	}
+	return repo.repository(), nil
+}
+
+func (r *githubProvider) CreateRepo(ctx context.Context, opts RepositoryOptions) (*Repository, error) {
+	body := map[string]any{
+		"name":      opts.Name,
+		"auto_init": true,
+	}
+	setGitHubOptions(body, opts)
+	p := "/user/repos"
+	if r.org != "" {
+		p = "/orgs/" + url.PathEscape(r.org) + "/repos"
+	}
+	repo := &githubRepository{}
+	if err := r.do(ctx, http.MethodPost, p, body, repo); err != nil {
+		return nil, err
+	}
+	return repo.repository(), nil
+}
+
+func (r *githubProvider) EditRepo(ctx context.Context, name string, opts RepositoryOptions) (*Repository, error) {
+	p, err := r.repoPath(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{"name": opts.Name}
+	setGitHubOptions(body, opts)
+	if opts.DefaultBranch != nil {
+		body["default_branch"] = *opts.DefaultBranch
+	}
+	repo := &githubRepository{}
+	if err := r.do(ctx, http.MethodPatch, p, body, repo); err != nil {
+		return nil, err
+	}
+	return repo.repository(), nil
+}
+
+func (r *githubProvider) DeleteRepo(ctx context.Context, name string) error {
+	p, err := r.repoPath(ctx, name)
+	if err != nil {
+		return err
+	}
+	return r.do(ctx, http.MethodDelete, p, nil, nil)
+}
+
+func (r *githubProvider) ArchiveRepo(ctx context.Context, name string) error {
+	p, err := r.repoPath(ctx, name)
+	if err != nil {
+		return err
+	}
+	return r.do(ctx, http.MethodPatch, p, map[string]any{"archived": true}, nil)
+}
+
+func (r *githubProvider) AddDeployKey(ctx context.Context, repo string, key DeployKey) error {
+	p, err := r.repoPath(ctx, repo)
+	if err != nil {
+		return err
+	}
+	keys := []DeployKey{}
+	if err := r.do(ctx, http.MethodGet, p+"/keys", nil, &keys); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if k.Title == key.Title {
+			return nil
+		}
+	}
+	return r.do(ctx, http.MethodPost, p+"/keys", map[string]any{
+		"title":     key.Title,
+		"key":       key.Key,
+		"read_only": key.ReadOnly,
+	}, nil)
+}
+
+func (r *githubProvider) ProtectBranch(ctx context.Context, repo, branch string) error {
+	p, err := r.repoPath(ctx, repo)
+	if err != nil {
+		return err
+	}
+	p = p + "/branches/" + url.PathEscape(branch) + "/protection"
+	err = r.do(ctx, http.MethodGet, p, nil, nil)
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return r.do(ctx, http.MethodPut, p, map[string]any{
+		"required_status_checks":        nil,
+		"enforce_admins":                false,
+		"required_pull_request_reviews": nil,
+		"restrictions":                  nil,
+		"allow_force_pushes":            false,
+		"allow_deletions":               false,
+	}, nil)
+}
+
+func (r *githubProvider) ListTokens(ctx context.Context, scope *TokenScope) ([]string, error) {
+	return nil, ErrNotSupported
+}
+
+func (r *githubProvider) CreateToken(ctx context.Context, name string, scope *TokenScope) (*Token, error) {
+	return nil, ErrNotSupported
+}
+
+func (r *githubProvider) DeleteToken(ctx context.Context, name string, scope *TokenScope) error {
+	return ErrNotSupported
+}
+
+func setGitHubOptions(body map[string]any, opts RepositoryOptions) {
+	if opts.Description != nil {
+		body["description"] = *opts.Description
+	}
+	if opts.Private != nil {
+		body["private"] = *opts.Private
+	}
+}
+
+func (r *githubRepository) repository() *Repository {
+	return &Repository{Name: r.Name, CloneURL: r.CloneURL, DefaultBranch: r.DefaultBranch}
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// gitlabProvider manages the projects of the user authenticated with the personal access token of
+// the git secret, the tokens are created with the admin api and require an admin user
+type gitlabProvider struct {
+	restClient
+}
+
+func newGitLab(baseURL string, secret *corev1.Secret) Provider {
+	return &gitlabProvider{
+		restClient: restClient{
+			client:  newHTTPClient(),
+			baseURL: strings.TrimSuffix(baseURL, "/") + "/api/v4",
+			header:  http.Header{"Private-Token": []string{getToken(secret)}},
+		},
+	}
+}
+
+type gitlabUser struct {
+	ID       int64  `json:"id"`
+	UserName string `json:"username"`
+}
+
+type gitlabProject struct {
+	Name          string `json:"name"`
+	CloneURL      string `json:"http_url_to_repo"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+type gitlabToken struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Revoked bool   `json:"revoked"`
+	Token   string `json:"token,omitempty"`
+}
+
+func (r *gitlabProvider) getUser(ctx context.Context) (*gitlabUser, error) {
+	u := &gitlabUser{}
+	if err := r.do(ctx, http.MethodGet, "/user", nil, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (r *gitlabProvider) GetUserName(ctx context.Context) (string, error) {
+	u, err := r.getUser(ctx)
+	if err != nil {
+		return "", err
+	}
+	return u.UserName, nil
+}
+
+// projectPath returns the url encoded path of the project of the user
+func (r *gitlabProvider) projectPath(ctx context.Context, name string) (string, error) {
+	owner, err := r.GetUserName(ctx)
+	if err != nil {
+		return "", err
+	}
+	return "/projects/" + url.PathEscape(owner+"/"+name), nil
+}
+
+func (r *gitlabProvider) GetRepo(ctx context.Context, name string) (*Repository, error) {
+	p, err := r.projectPath(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	project := &gitlabProject{}
+	if err := r.do(ctx, http.MethodGet, p, nil, project); err != nil {
+		return nil, err
+	}
+	return project.repository(), nil
+}
+
+func (r *gitlabProvider) CreateRepo(ctx context.Context, opts RepositoryOptions) (*Repository, error) {
+	body := map[string]any{
+		"name":                   opts.Name,
+		"path":                   opts.Name,
+		"initialize_with_readme": true,
+	}
+	setGitLabOptions(body, opts)
+	if opts.DefaultBranch != nil {
+		body["default_branch"] = *opts.DefaultBranch
+	}
+	project := &gitlabProject{}
+	if err := r.do(ctx, http.MethodPost, "/projects", body, project); err != nil {
+		return nil, err
+	}
+	return project.repository(), nil
+}
+
+func (r *gitlabProvider) EditRepo(ctx context.Context, name string, opts RepositoryOptions) (*Repository, error) {
+	p, err := r.projectPath(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{}
+	setGitLabOptions(body, opts)
+	if opts.DefaultBranch != nil {
+		body["default_branch"] = *opts.DefaultBranch
+	}
+	project := &gitlabProject{}
+	if err := r.do(ctx, http.MethodPut, p, body, project); err != nil {
+		return nil, err
+	}
+	return project.repository(), nil
+}
+
+func (r *gitlabProvider) DeleteRepo(ctx context.Context, name string) error {
+	p, err := r.projectPath(ctx, name)
+	if err != nil {
+		return err
+	}
+	return r.do(ctx, http.MethodDelete, p, nil, nil)
+}
+
+func (r *gitlabProvider) ArchiveRepo(ctx context.Context, name string) error {
+	p, err := r.projectPath(ctx, name)
+	if err != nil {
+		return err
+	}
+	return r.do(ctx, http.MethodPost, p+"/archive", nil, nil)
+}
+
+func (r *gitlabProvider) AddDeployKey(ctx context.Context, repo string, key DeployKey) error {
+	p, err := r.projectPath(ctx, repo)
+	if err != nil {
+		return err
+	}
+	keys := []DeployKey{}
+	if err := r.do(ctx, http.MethodGet, p+"/deploy_keys", nil, &keys); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if k.Title == key.Title {
+			return nil
+		}
+	}
+	return r.do(ctx, http.MethodPost, p+"/deploy_keys", map[string]any{
+		"title":    key.Title,
+		"key":      key.Key,
+		"can_push": !key.ReadOnly,
+	}, nil)
+}
+
+func (r *gitlabProvider) ProtectBranch(ctx context.Context, repo, branch string) error {
+	p, err := r.projectPath(ctx, repo)
+	if err != nil {
+		return err
+	}
+	err = r.do(ctx, http.MethodGet, p+"/protected_branches/"+url.PathEscape(branch), nil, nil)
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return r.do(ctx, http.MethodPost, p+"/protected_branches", map[string]any{
+		"name":             branch,
+		"allow_force_push": false,
+	}, nil)
+}
+
+// tokensPath returns the path of the personal access tokens of the user, or of the access tokens
+// of the project of the scope
+func (r *gitlabProvider) tokensPath(ctx context.Context, scope *TokenScope) (string, error) {
+	if scope != nil {
+		p, err := r.projectPath(ctx, scope.Repository)
+		if err != nil {
+			return "", err
+		}
+		return p + "/access_tokens", nil
+	}
+	return "/personal_access_tokens", nil
+}
+
+// listTokens returns the active personal access tokens of the user, or the active access tokens of the
+// project of the scope
+func (r *gitlabProvider) listTokens(ctx context.Context, scope *TokenScope) ([]gitlabToken, error) {
+	p, err := r.tokensPath(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	if scope == nil {
+		u, err := r.getUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		p = fmt.Sprintf("%s?user_id=%d&state=active", p, u.ID)
+	}
+	tokens := []gitlabToken{}
+	if err := r.do(ctx, http.MethodGet, p, nil, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *gitlabProvider) ListTokens(ctx context.Context, scope *TokenScope) ([]string, error) {
+	tokens, err := r.listTokens(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if !t.Revoked {
+			names = append(names, t.Name)
+		}
+	}
+	return names, nil
+}
+
+func (r *gitlabProvider) CreateToken(ctx context.Context, name string, scope *TokenScope) (*Token, error) {
+	token := &gitlabToken{}
+	if scope != nil {
+		// project access tokens authenticate the bot user of the project, with a reporter (read)
+		// or developer (write) access level
+		p, err := r.tokensPath(ctx, scope)
+		if err != nil {
+			return nil, err
+		}
+		scopes := []string{"read_repository", "write_repository"}
+		accessLevel := 30
+		if scope.ReadOnly {
+			scopes = []string{"read_repository"}
+			accessLevel = 20
+		}
+		if err := r.do(ctx, http.MethodPost, p, map[string]any{
+			"name":         name,
+			"scopes":       scopes,
+			"access_level": accessLevel,
+			// the maximum lifetime of a project access token
+			"expires_at": time.Now().AddDate(1, 0, 0).Format("2006-01-02"),
+		}, token); err != nil {
+			return nil, err
+		}
+		return &Token{Name: token.Name, Token: token.Token, UserName: token.Name}, nil
+	}
+	u, err := r.getUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.do(ctx, http.MethodPost, fmt.Sprintf("/users/%d/personal_access_tokens", u.ID), map[string]any{
+		"name":   name,
+		"scopes": []string{"read_repository", "write_repository"},
+	}, token); err != nil {
+		return nil, err
+	}
+	return &Token{Name: token.Name, Token: token.Token}, nil
+}
+
+func (r *gitlabProvider) DeleteToken(ctx context.Context, name string, scope *TokenScope) error {
+	tokens, err := r.listTokens(ctx, scope)
+	if err != nil {
+		return err
+	}
+	p, err := r.tokensPath(ctx, scope)
+	if err != nil {
+		return err
+	}
+	for _, t := range tokens {
+		if t.Name == name && !t.Revoked {
+			if err := r.do(ctx, http.MethodDelete, fmt.Sprintf("%s/%d", p, t.ID), nil, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setGitLabOptions(body map[string]any, opts RepositoryOptions) {
+	if opts.Description != nil {
+		body["description"] = *opts.Description
+	}
+	if opts.Private != nil {
+		body["visibility"] = "public"
+		if *opts.Private {
+			body["visibility"] = "private"
+		}
+	}
+}
+
+func (r *gitlabProject) repository() *Repository {
+	return &Repository{Name: r.Name, CloneURL: r.CloneURL, DefaultBranch: r.DefaultBranch}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"context"
+	"time"
+
+	"github.com/nephio-project/nephio/controllers/pkg/metrics"
+)
+
+// instrumentedProvider records the latency and the errors of the calls to the git server, a
+// repository or token which does not exist, or an operation the provider does not support, is
+// an answer of the git server rather than an error
+type instrumentedProvider struct {
+	provider Provider
+	name     string
+}
+
+func newInstrumentedProvider(name string, provider Provider) Provider {
+	return &instrumentedProvider{provider: provider, name: name}
+}
+
+func (r *instrumentedProvider) observe(operation string, start time.Time, err error) {
+	metrics.ObserveGitAPICall(r.name, operation, start, err, ErrNotFound, ErrNotSupported)
+}
+
+func (r *instrumentedProvider) GetUserName(ctx context.Context) (string, error) {
+	start := time.Now()
+	name, err := r.provider.GetUserName(ctx)
+	r.observe("GetUserName", start, err)
+	return name, err
+}
+
+func (r *instrumentedProvider) GetRepo(ctx context.Context, name string) (*Repository, error) {
+	start := time.Now()
+	repo, err := r.provider.GetRepo(ctx, name)
+	r.observe("GetRepo", start, err)
+	return repo, err
+}
+
+func (r *instrumentedProvider) CreateRepo(ctx context.Context, opts RepositoryOptions) (*Repository, error) {
+	start := time.Now()
+	repo, err := r.provider.CreateRepo(ctx, opts)
+	r.observe("CreateRepo", start, err)
+	return repo, err
+}
+
+func (r *instrumentedProvider) EditRepo(ctx context.Context, name string, opts RepositoryOptions) (*Repository, error) {
+	start := time.Now()
+	repo, err := r.provider.EditRepo(ctx, name, opts)
+	r.observe("EditRepo", start, err)
+	return repo, err
+}
+
+func (r *instrumentedProvider) DeleteRepo(ctx context.Context, name string) error {
+	start := time.Now()
+	err := r.provider.DeleteRepo(ctx, name)
+	r.observe("DeleteRepo", start, err)
+	return err
+}
+
+func (r *instrumentedProvider) ArchiveRepo(ctx context.Context, name string) error {
+	start := time.Now()
+	err := r.provider.ArchiveRepo(ctx, name)
+	r.observe("ArchiveRepo", start, err)
+	return err
+}
+
+func (r *instrumentedProvider) AddDeployKey(ctx context.Context, repo string, key DeployKey) error {
+	start := time.Now()
+	err := r.provider.AddDeployKey(ctx, repo, key)
+	r.observe("AddDeployKey", start, err)
+	return err
+}
+
+func (r *instrumentedProvider) ProtectBranch(ctx context.Context, repo, branch string) error {
+	start := time.Now()
+	err := r.provider.ProtectBranch(ctx, repo, branch)
+	r.observe("ProtectBranch", start, err)
+	return err
+}
+
+func (r *instrumentedProvider) ListTokens(ctx context.Context, scope *TokenScope) ([]string, error) {
+	start := time.Now()
+	names, err := r.provider.ListTokens(ctx, scope)
+	r.observe("ListTokens", start, err)
+	return names, err
+}
+
+func (r *instrumentedProvider) CreateToken(ctx context.Context, name string, scope *TokenScope) (*Token, error) {
+	start := time.Now()
+	token, err := r.provider.CreateToken(ctx, name, scope)
+	r.observe("CreateToken", start, err)
+	return token, err
+}
+
+func (r *instrumentedProvider) DeleteToken(ctx context.Context, name string, scope *TokenScope) error {
+	start := time.Now()
+	err := r.provider.DeleteToken(ctx, name, scope)
+	r.observe("DeleteToken", start, err)
+	return err
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Gitea is the default git provider
+	Gitea  = "gitea"
+	GitLab = "gitlab"
+	GitHub = "github"
+)
+
+var (
+	// ErrNotFound is returned when the repository or token does not exist in the git server
+	ErrNotFound = errors.New("not found")
+	// ErrNotSupported is returned when the git provider does not support the operation
+	ErrNotSupported = errors.New("not supported by the git provider")
+)
+
+// Provider manages the repositories, deploy keys and tokens of the user authenticated to a git server
+type Provider interface {
+	// GetUserName returns the name of the authenticated user, the owner of the repositories
+	GetUserName(ctx context.Context) (string, error)
+	// GetRepo returns the repository or ErrNotFound
+	GetRepo(ctx context.Context, name string) (*Repository, error)
+	CreateRepo(ctx context.Context, opts RepositoryOptions) (*Repository, error)
+	EditRepo(ctx context.Context, name string, opts RepositoryOptions) (*Repository, error)
+	DeleteRepo(ctx context.Context, name string) error
+	// ArchiveRepo makes the repository read-only
+	ArchiveRepo(ctx context.Context, name string) error
+	// AddDeployKey adds the deploy key to the repository unless a deploy key with the same title exists
+	AddDeployKey(ctx context.Context, repo string, key DeployKey) error
+	// ProtectBranch protects the branch of the repository against force pushes and deletion
+	ProtectBranch(ctx context.Context, repo, branch string) error
+	// ListTokens returns the names of the access tokens of the authenticated user, or of the tokens
+	// of the repository of the scope
+	ListTokens(ctx context.Context, scope *TokenScope) ([]string, error)
+	// CreateToken returns a new access token with access to the repositories of the authenticated user,
+	// or to the repository of the scope only
+	CreateToken(ctx context.Context, name string, scope *TokenScope) (*Token, error)
+	DeleteToken(ctx context.Context, name string, scope *TokenScope) error
+}
+
+type Repository struct {
+	Name          string
+	CloneURL      string
+	DefaultBranch string
+}
+
+// RepositoryOptions defines the settings of a repository, the nil settings are left unchanged
+type RepositoryOptions struct {
+	Name          string
+	Description   *string
+	Private       *bool
+	DefaultBranch *string
+	// the following settings initialize the repository in gitea and are ignored by the other providers
+	IssueLabels *string
+	Gitignores  *string
+	License     *string
+	Readme      *string
+	TrustModel  *string
+}
+
+type DeployKey struct {
+	Title    string
+	Key      string
+	ReadOnly bool
+}
+
+type Token struct {
+	Name  string
+	Token string
+	// UserName is the user authenticated with a scoped token, empty for the tokens of the authenticated user
+	UserName string
+}
+
+// TokenScope restricts a token to a single repository
+type TokenScope struct {
+	Repository string
+	ReadOnly   bool
+}
+
+// Client connects to the git server of the provider selected by the environment
+type Client interface {
+	Start(ctx context.Context)
+	// Get returns the provider once connected, nil otherwise
+	Get() Provider
+}
+
+func New(client resource.APIPatchingApplicator) Client {
+	return &gc{
+		client: client,
+	}
+}
+
+type gc struct {
+	client resource.APIPatchingApplicator
+
+	provider Provider
+	l        logr.Logger
+}
+
+func (r *gc) Start(ctx context.Context) {
+	for {
+		select {
+		// The context is the one returned by ctrl.SetupSignalHandler().
+		// cancel() of this context will trigger <- ctx.Done().
+		// The Idea for continuously retrying is for enabling the user to
+		// create a secret eventually even after the controllers are started.
+		case <-ctx.Done():
+			fmt.Printf("controller manager context cancelled: Exit\n")
+			return
+		default:
+			r.l = log.FromContext(ctx)
+			//var err error
+			time.Sleep(5 * time.Second)
+
+			gitURL, ok := os.LookupEnv("GIT_URL")
+			if !ok {
+				r.l.Error(fmt.Errorf("git url not defined"), "cannot connect to git server")
+				break
+			}
+			providerName := Gitea
+			if p, ok := os.LookupEnv("GIT_PROVIDER"); ok && p != "" {
+				providerName = p
+			}
+
+			namespace := os.Getenv("POD_NAMESPACE")
+			if gitNamespace, ok := os.LookupEnv("GIT_NAMESPACE"); ok {
+				namespace = gitNamespace
+			}
+			secretName := "git-user-secret"
+			if gitSecretName, ok := os.LookupEnv("GIT_SECRET_NAME"); ok {
+				secretName = gitSecretName
+			}
+
+			// get secret that was created when installing the git server
+			secret := &corev1.Secret{}
+			if err := r.client.Get(ctx, types.NamespacedName{
+				Namespace: namespace,
+				Name:      secretName,
+			},
+				secret); err != nil {
+				r.l.Error(err, "Cannot get secret, please follow README and create the git secret")
+				break
+			}
+
+			provider, err := newProvider(providerName, gitURL, secret)
+			if err != nil {
+				r.l.Error(err, "cannot authenticate to git server", "provider", providerName)
+				break
+			}
+
+			r.provider = newInstrumentedProvider(providerName, provider)
+			r.l.Info("git provider init done", "provider", providerName)
+			return
+		}
+	}
+}
+
+func (r *gc) Get() Provider {
+	return r.provider
+}
+
+func newProvider(name, url string, secret *corev1.Secret) (Provider, error) {
+	switch name {
+	case Gitea:
+		return newGitea(url, secret)
+	case GitLab:
+		return newGitLab(url, secret), nil
+	case GitHub:
+		return newGitHub(url, secret), nil
+	default:
+		return nil, fmt.Errorf("git provider %s not supported, supported providers: [%s, %s, %s]", name, Gitea, GitLab, GitHub)
+	}
+}
+
+// getToken returns the token of the secret, the providers authenticated with a token accept
+// the token as the password of a basic auth secret
+func getToken(secret *corev1.Secret) string {
+	if token, ok := secret.Data["token"]; ok {
+		return string(token)
+	}
+	return string(secret.Data["password"])
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// fakeServer serves the responses per method and request uri and records the requests
+type fakeServer struct {
+	responses map[string]any
+	requests  []string
+}
+
+func (r *fakeServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	key := req.Method + " " + req.URL.RequestURI()
+	r.requests = append(r.requests, key)
+	resp, ok := r.responses[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func TestNewProvider(t *testing.T) {
+	cases := map[string]struct {
+		provider  string
+		wantError bool
+	}{
+		"Gitea": {
+			provider:  Gitea,
+			wantError: false,
+		},
+		"GitLab": {
+			provider:  GitLab,
+			wantError: false,
+		},
+		"GitHub": {
+			provider:  GitHub,
+			wantError: false,
+		},
+		"Unknown": {
+			provider:  "bitbucket",
+			wantError: true,
+		},
+	}
+
+	// the gitea client checks the version of the server
+	ts := httptest.NewServer(&fakeServer{responses: map[string]any{
+		"GET /api/v1/version": map[string]any{"version": "1.19.0"},
+	}})
+	defer ts.Close()
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := newProvider(tc.provider, ts.URL, &corev1.Secret{})
+			if (err != nil) != tc.wantError {
+				t.Errorf("newProvider() error = %v, wantError %v", err, tc.wantError)
+			}
+		})
+	}
+}
+
+func TestGitLab(t *testing.T) {
+	server := &fakeServer{responses: map[string]any{
+		"GET /api/v4/user": map[string]any{"id": 1, "username": "nephio"},
+		"GET /api/v4/projects/nephio%2Fmgmt": map[string]any{
+			"name": "mgmt", "http_url_to_repo": "http://gitlab/nephio/mgmt.git", "default_branch": "main",
+		},
+		"GET /api/v4/projects/nephio%2Fmgmt/deploy_keys":         []any{map[string]any{"title": "mgmt-key"}},
+		"POST /api/v4/projects/nephio%2Fmgmt/protected_branches": map[string]any{},
+		"GET /api/v4/projects/nephio%2Fmgmt/access_tokens": []any{
+			map[string]any{"id": 1, "name": "edge01-1", "revoked": true},
+			map[string]any{"id": 2, "name": "edge01-2"},
+		},
+		"POST /api/v4/projects/nephio%2Fmgmt/access_tokens": map[string]any{"id": 3, "name": "edge01-3", "token": "secret"},
+	}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	p := newGitLab(ts.URL, &corev1.Secret{Data: map[string][]byte{"password": []byte("token")}})
+	ctx := context.Background()
+
+	repo, err := p.GetRepo(ctx, "mgmt")
+	if err != nil {
+		t.Fatalf("GetRepo() error = %v", err)
+	}
+	if diff := cmp.Diff(&Repository{Name: "mgmt", CloneURL: "http://gitlab/nephio/mgmt.git", DefaultBranch: "main"}, repo); diff != "" {
+		t.Errorf("GetRepo() -want, +got:\n%s", diff)
+	}
+	if _, err := p.GetRepo(ctx, "edge"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetRepo() error = %v, want %v", err, ErrNotFound)
+	}
+	// the deploy key exists and is not added again
+	if err := p.AddDeployKey(ctx, "mgmt", DeployKey{Title: "mgmt-key", Key: "ssh-ed25519 AAAA"}); err != nil {
+		t.Errorf("AddDeployKey() error = %v", err)
+	}
+	if err := p.ProtectBranch(ctx, "mgmt", "main"); err != nil {
+		t.Errorf("ProtectBranch() error = %v", err)
+	}
+	scope := &TokenScope{Repository: "mgmt", ReadOnly: true}
+	tokens, err := p.ListTokens(ctx, scope)
+	if err != nil {
+		t.Fatalf("ListTokens() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"edge01-2"}, tokens); diff != "" {
+		t.Errorf("ListTokens() -want, +got:\n%s", diff)
+	}
+	token, err := p.CreateToken(ctx, "edge01-3", scope)
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+	if diff := cmp.Diff(&Token{Name: "edge01-3", Token: "secret", UserName: "edge01-3"}, token); diff != "" {
+		t.Errorf("CreateToken() -want, +got:\n%s", diff)
+	}
+	for _, req := range server.requests {
+		if req == "POST /api/v4/projects/nephio%2Fmgmt/deploy_keys" {
+			t.Errorf("AddDeployKey() added an existing deploy key")
+		}
+	}
+}
+
+func TestGitHub(t *testing.T) {
+	t.Setenv("GIT_ORG", "nephio-org")
+	server := &fakeServer{responses: map[string]any{
+		"POST /orgs/nephio-org/repos": map[string]any{
+			"name": "mgmt", "clone_url": "https://github.com/nephio-org/mgmt.git", "default_branch": "main",
+		},
+		"GET /repos/nephio-org/mgmt/keys":  []any{},
+		"POST /repos/nephio-org/mgmt/keys": map[string]any{},
+	}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	p := newGitHub(ts.URL, &corev1.Secret{Data: map[string][]byte{"token": []byte("token")}})
+	ctx := context.Background()
+
+	repo, err := p.CreateRepo(ctx, RepositoryOptions{Name: "mgmt"})
+	if err != nil {
+		t.Fatalf("CreateRepo() error = %v", err)
+	}
+	if diff := cmp.Diff(&Repository{Name: "mgmt", CloneURL: "https://github.com/nephio-org/mgmt.git", DefaultBranch: "main"}, repo); diff != "" {
+		t.Errorf("CreateRepo() -want, +got:\n%s", diff)
+	}
+	if err := p.AddDeployKey(ctx, "mgmt", DeployKey{Title: "mgmt-key", Key: "ssh-ed25519 AAAA"}); err != nil {
+		t.Errorf("AddDeployKey() error = %v", err)
+	}
+	if _, err := p.CreateToken(ctx, "mgmt", nil); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("CreateToken() error = %v, want %v", err, ErrNotSupported)
+	}
+	want := []string{
+		"POST /orgs/nephio-org/repos",
+		"GET /repos/nephio-org/mgmt/keys",
+		"POST /repos/nephio-org/mgmt/keys",
+	}
+	if diff := cmp.Diff(want, server.requests); diff != "" {
+		t.Errorf("requests -want, +got:\n%s", diff)
+	}
+}
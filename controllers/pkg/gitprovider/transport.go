@@ -0,0 +1,310 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// RateLimitEnv sets the number of requests per second sent to the git server, 10 by default
+	RateLimitEnv = "GIT_RATE_LIMIT"
+	// RateBurstEnv sets the number of requests sent at once to the git server, 20 by default
+	RateBurstEnv = "GIT_RATE_BURST"
+	// CacheTTLEnv sets how long a GET response of the git server is cached, 30s by default, 0 disables the cache
+	CacheTTLEnv = "GIT_CACHE_TTL"
+
+	defaultRateLimit = 10
+	defaultRateBurst = 20
+	defaultCacheTTL  = 30 * time.Second
+	maxRetries       = 4
+	minBackoff       = 200 * time.Millisecond
+	maxBackoff       = 5 * time.Second
+)
+
+// sharedTransport is used by all the providers, so that the reconcilers of the repositories and the
+// tokens together stay within the rate limit of the git server
+var sharedTransport = newTransport(http.DefaultTransport, transportOptionsFromEnv())
+
+// newHTTPClient returns the client the providers call the git server with
+func newHTTPClient() *http.Client {
+	return &http.Client{Transport: sharedTransport}
+}
+
+type transportOptions struct {
+	rateLimit rate.Limit
+	rateBurst int
+	cacheTTL  time.Duration
+}
+
+// transportOptionsFromEnv returns the options of the environment, an invalid value is replaced by the default
+func transportOptionsFromEnv() transportOptions {
+	opts := transportOptions{
+		rateLimit: defaultRateLimit,
+		rateBurst: defaultRateBurst,
+		cacheTTL:  defaultCacheTTL,
+	}
+	if v, err := strconv.ParseFloat(os.Getenv(RateLimitEnv), 64); err == nil && v > 0 {
+		opts.rateLimit = rate.Limit(v)
+	}
+	if v, err := strconv.Atoi(os.Getenv(RateBurstEnv)); err == nil && v > 0 {
+		opts.rateBurst = v
+	}
+	if v, err := time.ParseDuration(os.Getenv(CacheTTLEnv)); err == nil && v >= 0 {
+		opts.cacheTTL = v
+	}
+	return opts
+}
+
+// transport protects a small git server against reconcile storms: the requests wait for the rate
+// limiter, the successful GET responses are cached until any other request changes the server, and
+// the requests failing with a network error, 429 or 5xx are retried with an exponential backoff
+type transport struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+	ttl     time.Duration
+	backoff time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedResponse
+	// generation changes with every write, a GET sent before a write is not cached
+	generation uint64
+}
+
+type cachedResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+func newTransport(next http.RoundTripper, opts transportOptions) *transport {
+	return &transport{
+		next:    next,
+		limiter: rate.NewLimiter(opts.rateLimit, opts.rateBurst),
+		ttl:     opts.cacheTTL,
+		backoff: minBackoff,
+		cache:   map[string]cachedResponse{},
+	}
+}
+
+func (r *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		// the write is retried, so the cache is invalidated before and after it
+		r.invalidate()
+		defer r.invalidate()
+	}
+	cacheable := req.Method == http.MethodGet && r.ttl > 0
+	key := cacheKey(req)
+	if cacheable {
+		if resp := r.lookup(key, req); resp != nil {
+			return resp, nil
+		}
+	}
+	generation := r.currentGeneration()
+
+	resp, err := r.roundTripWithRetry(req)
+	if err != nil || !cacheable || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	r.store(key, generation, cachedResponse{
+		status:  resp.StatusCode,
+		header:  resp.Header.Clone(),
+		body:    body,
+		expires: time.Now().Add(r.ttl),
+	})
+	return resp, nil
+}
+
+func (r *transport) roundTripWithRetry(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	backoff := r.backoff
+	for attempt := 0; ; attempt++ {
+		if err := r.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		attemptReq := req
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+		resp, err := r.next.RoundTrip(attemptReq)
+		if attempt >= maxRetries || !retriable(req, resp, err) {
+			return resp, err
+		}
+		wait := backoff
+		if resp != nil {
+			if d, ok := retryAfter(resp); ok {
+				wait = d
+			}
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		if wait > maxBackoff {
+			wait = maxBackoff
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// retriable returns true when the request can be sent again: the server asked to slow down, or an
+// idempotent request failed before or in a gateway of the server
+func retriable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	// a body which cannot be sent again cannot be retried
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if err != nil {
+		return idempotent(req.Method)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(req.Method)
+	}
+	return false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryAfter returns the delay of the Retry-After header in seconds or as a date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// cacheKey identifies a response by the url and the headers of the request, the responses of
+// different users are cached apart
+func cacheKey(req *http.Request) string {
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(req.URL.String())
+	for _, k := range keys {
+		b.WriteString("\n" + k + ": " + strings.Join(req.Header[k], ","))
+	}
+	return b.String()
+}
+
+func (r *transport) lookup(key string, req *http.Request) *http.Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.cache[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(c.expires) {
+		delete(r.cache, key)
+		return nil
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(c.status) + " " + http.StatusText(c.status),
+		StatusCode:    c.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        c.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(c.body)),
+		ContentLength: int64(len(c.body)),
+		Request:       req,
+	}
+}
+
+func (r *transport) store(key string, generation uint64, c cachedResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if generation != r.generation {
+		return
+	}
+	now := time.Now()
+	for k, c := range r.cache {
+		if now.After(c.expires) {
+			delete(r.cache, k)
+		}
+	}
+	r.cache[key] = c
+}
+
+func (r *transport) currentGeneration() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.generation
+}
+
+func (r *transport) invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generation++
+	r.cache = map[string]cachedResponse{}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitprovider
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/time/rate"
+)
+
+// statusServer answers the requests with the statuses in order, then with 200, and records the requests
+type statusServer struct {
+	statuses []int
+	requests []string
+}
+
+func (r *statusServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	b, _ := io.ReadAll(req.Body)
+	r.requests = append(r.requests, strings.TrimSpace(req.Method+" "+req.URL.RequestURI()+" "+string(b)))
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	if status == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", "0")
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(req.URL.Path))
+}
+
+func TestTransport(t *testing.T) {
+	type request struct {
+		method string
+		path   string
+		body   string
+	}
+	cases := map[string]struct {
+		statuses     []int
+		requests     []request
+		wantStatuses []int
+		wantRequests []string
+	}{
+		"CachedGet": {
+			requests:     []request{{method: "GET", path: "/user"}, {method: "GET", path: "/user"}, {method: "GET", path: "/repos"}},
+			wantStatuses: []int{200, 200, 200},
+			wantRequests: []string{"GET /user", "GET /repos"},
+		},
+		"WriteInvalidatesCache": {
+			requests:     []request{{method: "GET", path: "/repos"}, {method: "POST", path: "/repos", body: "a"}, {method: "GET", path: "/repos"}},
+			wantStatuses: []int{200, 200, 200},
+			wantRequests: []string{"GET /repos", "POST /repos a", "GET /repos"},
+		},
+		"ErrorNotCached": {
+			statuses:     []int{404},
+			requests:     []request{{method: "GET", path: "/repos/a"}, {method: "GET", path: "/repos/a"}},
+			wantStatuses: []int{404, 200},
+			wantRequests: []string{"GET /repos/a", "GET /repos/a"},
+		},
+		"RetryGet": {
+			statuses:     []int{502, 500},
+			requests:     []request{{method: "GET", path: "/user"}},
+			wantStatuses: []int{200},
+			wantRequests: []string{"GET /user", "GET /user", "GET /user"},
+		},
+		"RetryTooManyRequestsWithBody": {
+			statuses:     []int{429},
+			requests:     []request{{method: "POST", path: "/repos", body: "a"}},
+			wantStatuses: []int{200},
+			wantRequests: []string{"POST /repos a", "POST /repos a"},
+		},
+		"NoRetryPost": {
+			statuses:     []int{500},
+			requests:     []request{{method: "POST", path: "/repos", body: "a"}},
+			wantStatuses: []int{500},
+			wantRequests: []string{"POST /repos a"},
+		},
+		"MaxRetries": {
+			statuses:     []int{503, 503, 503, 503, 503, 503},
+			requests:     []request{{method: "DELETE", path: "/repos/a"}},
+			wantStatuses: []int{503},
+			wantRequests: []string{"DELETE /repos/a", "DELETE /repos/a", "DELETE /repos/a", "DELETE /repos/a", "DELETE /repos/a"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := &statusServer{statuses: tc.statuses}
+			ts := httptest.NewServer(s)
+			defer ts.Close()
+			tr := newTransport(http.DefaultTransport, transportOptions{rateLimit: rate.Inf, rateBurst: 1, cacheTTL: time.Minute})
+			tr.backoff = time.Millisecond
+			client := &http.Client{Transport: tr}
+
+			statuses := []int{}
+			for _, r := range tc.requests {
+				var body io.Reader
+				if r.body != "" {
+					body = strings.NewReader(r.body)
+				}
+				req, err := http.NewRequest(r.method, ts.URL+r.path, body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				b, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if string(b) != r.path {
+					t.Errorf("body = %q, want %q", string(b), r.path)
+				}
+				statuses = append(statuses, resp.StatusCode)
+			}
+			if diff := cmp.Diff(tc.wantStatuses, statuses); diff != "" {
+				t.Errorf("statuses -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantRequests, s.requests); diff != "" {
+				t.Errorf("requests -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTransportCacheKey(t *testing.T) {
+	s := &statusServer{}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	client := &http.Client{Transport: newTransport(http.DefaultTransport, transportOptions{rateLimit: rate.Inf, rateBurst: 1, cacheTTL: time.Minute})}
+
+	for _, user := range []string{"a", "b", "a"} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/user", nil)
+		req.SetBasicAuth(user, "password")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+	// the response of a user is not served to another user
+	if diff := cmp.Diff([]string{"GET /user", "GET /user"}, s.requests); diff != "" {
+		t.Errorf("requests -want, +got:\n%s", diff)
+	}
+}
+
+func TestTransportOptionsFromEnv(t *testing.T) {
+	t.Setenv(RateLimitEnv, "2.5")
+	t.Setenv(RateBurstEnv, "invalid")
+	t.Setenv(CacheTTLEnv, "0")
+	want := transportOptions{rateLimit: 2.5, rateBurst: defaultRateBurst, cacheTTL: 0}
+	if diff := cmp.Diff(want, transportOptionsFromEnv(), cmp.AllowUnexported(transportOptions{})); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nephio-project/nephio/krm-functions/lib/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	namespace = "nephio"
+
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+var (
+	// ReconcileDuration observes the duration of the reconciles per controller and result
+	ReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of the reconciles of the nephio controllers.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"controller", "result"})
+	// GitAPICallDuration observes the latency of the calls to the git server per provider and operation
+	GitAPICallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "git_api_call_duration_seconds",
+		Help:      "Latency of the calls to the api of the git server.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider", "operation"})
+	// GitAPICallErrors counts the failed calls to the git server per provider and operation
+	GitAPICallErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "git_api_call_errors_total",
+		Help:      "Number of failed calls to the api of the git server.",
+	}, []string{"provider", "operation"})
+	// PackagesApproved counts the package revisions approved per lifecycle they were moved to
+	PackagesApproved = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "packages_approved_total",
+		Help:      "Number of package revisions proposed or published by the approval controller.",
+	}, []string{"lifecycle"})
+	// PackagesHeld counts the approvals held back per reason
+	PackagesHeld = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "packages_held_total",
+		Help:      "Number of times the approval of a package revision was held back.",
+	}, []string{"reason"})
+	// TokensRotated counts the git tokens rotated
+	TokensRotated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tokens_rotated_total",
+		Help:      "Number of git tokens rotated by the token controller.",
+	})
+)
+
+// the metrics are served by the metrics endpoint of the controller manager
+func init() {
+	metrics.Registry.MustRegister(
+		ReconcileDuration,
+		GitAPICallDuration,
+		GitAPICallErrors,
+		PackagesApproved,
+		PackagesHeld,
+		TokensRotated,
+	)
+}
+
+// ObserveGitAPICall records the latency of a call to the git server started at start,
+// and the error of the call unless it is one of the ignored errors
+func ObserveGitAPICall(provider, operation string, start time.Time, err error, ignored ...error) {
+	GitAPICallDuration.WithLabelValues(provider, operation).Observe(time.Since(start).Seconds())
+	if err == nil {
+		return
+	}
+	for _, ignore := range ignored {
+		if errors.Is(err, ignore) {
+			return
+		}
+	}
+	GitAPICallErrors.WithLabelValues(provider, operation).Inc()
+}
+
+// NewReconciler returns a reconciler observing the duration of the reconciles of the controller,
+// each reconcile is traced as a span whose trace the specializations it drives join
+func NewReconciler(controller string, r reconcile.Reconciler) reconcile.Reconciler {
+	return &reconciler{controller: controller, r: r}
+}
+
+type reconciler struct {
+	controller string
+	r          reconcile.Reconciler
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "reconcile "+r.controller)
+	span.SetAttribute("controller", r.controller)
+	span.SetAttribute("namespace", req.Namespace)
+	span.SetAttribute("name", req.Name)
+	result, err := r.r.Reconcile(ctx, req)
+	span.RecordError(err)
+	span.End()
+	res := resultSuccess
+	if err != nil {
+		res = resultError
+	}
+	ReconcileDuration.WithLabelValues(r.controller, res).Observe(time.Since(start).Seconds())
+	return result, err
+}
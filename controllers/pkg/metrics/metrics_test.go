@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var errNotFound = errors.New("not found")
+
+func sampleCount(t *testing.T, o prometheus.Observer) uint64 {
+	t.Helper()
+	m := &dto.Metric{}
+	if err := o.(prometheus.Histogram).Write(m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestObserveGitAPICall(t *testing.T) {
+	cases := map[string]struct {
+		err        error
+		wantErrors float64
+	}{
+		"Success": {
+			wantErrors: 0,
+		},
+		"Error": {
+			err:        errors.New("connection refused"),
+			wantErrors: 1,
+		},
+		"IgnoredError": {
+			err:        errNotFound,
+			wantErrors: 0,
+		},
+		"WrappedIgnoredError": {
+			err:        errors.Join(errors.New("cannot get repo"), errNotFound),
+			wantErrors: 0,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ObserveGitAPICall("test", name, time.Now(), tc.err, errNotFound)
+			if got := sampleCount(t, GitAPICallDuration.WithLabelValues("test", name)); got != 1 {
+				t.Errorf("want 1 observed call, got %d", got)
+			}
+			if got := testutil.ToFloat64(GitAPICallErrors.WithLabelValues("test", name)); got != tc.wantErrors {
+				t.Errorf("want %v errors, got %v", tc.wantErrors, got)
+			}
+		})
+	}
+}
+
+type fakeReconciler struct {
+	err error
+}
+
+func (r *fakeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return ctrl.Result{Requeue: true}, r.err
+}
+
+func TestNewReconciler(t *testing.T) {
+	cases := map[string]struct {
+		err        error
+		wantResult string
+	}{
+		"Success": {
+			wantResult: resultSuccess,
+		},
+		"Error": {
+			err:        errors.New("cannot reconcile"),
+			wantResult: resultError,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewReconciler(name, &fakeReconciler{err: tc.err})
+			result, err := r.Reconcile(context.Background(), reconcile.Request{})
+			if !errors.Is(err, tc.err) {
+				t.Errorf("want error %v, got %v", tc.err, err)
+			}
+			if !result.Requeue {
+				t.Errorf("want the result of the reconciler, got %v", result)
+			}
+			if got := sampleCount(t, ReconcileDuration.WithLabelValues(name, tc.wantResult)); got != 1 {
+				t.Errorf("want 1 observed %s reconcile, got %d", tc.wantResult, got)
+			}
+		})
+	}
+}
@@ -31,7 +31,35 @@ import (
 func PackageVariantReady(ctx context.Context, pr *porchv1alpha1.PackageRevision, c client.Client) (bool, error) {
 	// If the package revision is owned by a PackageVariant, check the Ready condition
 	// of the package variant.
-	owned := false
+	pv, err := GetPackageVariant(ctx, pr, c)
+	if err != nil {
+		return false, err
+	}
+	// if the package revision is not owned by a packagevariant, consider it Ready
+	if pv == nil {
+		return true, nil
+	}
+
+	for _, cond := range pv.Status.Conditions {
+		if cond.Type != "Ready" {
+			continue
+		}
+
+		if cond.Status == metav1.ConditionTrue {
+			return true, nil
+		}
+
+		return false, nil
+	}
+
+	// falling through to here should be considered not Ready, since
+	// the readiness condition was not found at all.
+	return false, nil
+}
+
+// GetPackageVariant returns the PackageVariant owning the package revision, nil when the
+// package revision is not owned by a PackageVariant
+func GetPackageVariant(ctx context.Context, pr *porchv1alpha1.PackageRevision, c client.Client) (*pvapi.PackageVariant, error) {
 	for _, ownerRef := range pr.GetOwnerReferences() {
 		if ownerRef.Controller == nil || !*ownerRef.Controller {
 			continue
@@ -43,28 +71,11 @@ func PackageVariantReady(ctx context.Context, pr *porchv1alpha1.PackageRevision,
 			continue
 		}
 
-		owned = true
 		var pv pvapi.PackageVariant
 		if err := c.Get(ctx, types.NamespacedName{Namespace: pr.Namespace, Name: ownerRef.Name}, &pv); err != nil {
-			return false, err
-		}
-
-		for _, cond := range pv.Status.Conditions {
-			if cond.Type != "Ready" {
-				continue
-			}
-
-			if cond.Status == metav1.ConditionTrue {
-				return true, nil
-			}
-
-			return false, nil
+			return nil, err
 		}
+		return &pv, nil
 	}
-
-	// if the package revision is not owned by a packagevariant, consider it Ready
-	// otherwise, falling through to here should be considered not Ready, since
-	// the readiness condition was not found at all.
-
-	return !owned, nil
+	return nil, nil
 }
@@ -0,0 +1,148 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package approval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/cel-go/cel"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// CELContentPolicyLabelKey labels the ConfigMaps holding the user written CEL content policies,
+	// the name of the ConfigMap is the name of the policy in the content policies annotation
+	CELContentPolicyLabelKey = "approval.nephio.org/cel-content-policy"
+	// CELExpressionKey is the data key of the CEL expression every matched resource must meet
+	CELExpressionKey = "expression"
+	// CELMatchKey is the optional data key of the CEL expression matching the resources the policy
+	// applies to, by default the policy applies to every resource of the package
+	CELMatchKey = "match"
+	// CELMessageKey is the optional data key of the message of the violations
+	CELMessageKey = "message"
+)
+
+// celContentPolicy is a content policy written as CEL expressions on the resources of the package,
+// the resource is the object variable and the resources of the package are the objects variable
+type celContentPolicy struct {
+	expression cel.Program
+	match      cel.Program
+	message    string
+}
+
+// NewCELContentPolicy returns the content policy violated by the resources matched by the match
+// expression for which the expression is false, an empty match expression matches every resource
+func NewCELContentPolicy(expression, match, message string) (ContentPolicy, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("object", cel.DynType),
+		cel.Variable("objects", cel.ListType(cel.DynType)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	p := &celContentPolicy{message: message}
+	if p.message == "" {
+		p.message = fmt.Sprintf("does not meet %s", expression)
+	}
+	if p.expression, err = compileCEL(env, expression); err != nil {
+		return nil, err
+	}
+	if match != "" {
+		if p.match, err = compileCEL(env, match); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func compileCEL(env *cel.Env, expression string) (cel.Program, error) {
+	ast, iss := env.Compile(expression)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("invalid expression %q: %s", expression, iss.Err().Error())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression %q does not evaluate to a bool", expression)
+	}
+	return env.Program(ast)
+}
+
+// Evaluate returns a violation per matched resource for which the expression is false
+func (r *celContentPolicy) Evaluate(objs fn.KubeObjects) ([]string, error) {
+	objects := make([]any, 0, len(objs))
+	for _, o := range objs {
+		object := map[string]any{}
+		if err := yaml.Unmarshal([]byte(o.String()), &object); err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	violations := []string{}
+	for i, o := range objs {
+		vars := map[string]any{"object": objects[i], "objects": objects}
+		if r.match != nil {
+			matched, err := evalCEL(r.match, vars)
+			if err != nil {
+				return nil, fmt.Errorf("cannot match %s %s: %s", o.GetKind(), o.GetName(), err.Error())
+			}
+			if !matched {
+				continue
+			}
+		}
+		met, err := evalCEL(r.expression, vars)
+		if err != nil {
+			return nil, fmt.Errorf("cannot evaluate %s %s: %s", o.GetKind(), o.GetName(), err.Error())
+		}
+		if !met {
+			violations = append(violations, fmt.Sprintf("%s %s %s", o.GetKind(), o.GetName(), r.message))
+		}
+	}
+	return violations, nil
+}
+
+func evalCEL(p cel.Program, vars map[string]any) (bool, error) {
+	v, _, err := p.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expecting a bool, got %v", v.Value())
+	}
+	return b, nil
+}
+
+// getCELContentPolicies returns the CEL content policies of the ConfigMaps of the namespace of the
+// package revision keyed by the name of the ConfigMap
+func (r *reconciler) getCELContentPolicies(ctx context.Context, namespace string) (map[string]ContentPolicy, error) {
+	cms := &corev1.ConfigMapList{}
+	if err := r.List(ctx, cms, client.InNamespace(namespace), client.HasLabels{CELContentPolicyLabelKey}); err != nil {
+		return nil, err
+	}
+	policies := map[string]ContentPolicy{}
+	for _, cm := range cms.Items {
+		p, err := NewCELContentPolicy(cm.Data[CELExpressionKey], cm.Data[CELMatchKey], cm.Data[CELMessageKey])
+		if err != nil {
+			return nil, fmt.Errorf("content policy %q: %s", cm.GetName(), err.Error())
+		}
+		policies[cm.GetName()] = p
+	}
+	return policies, nil
+}
@@ -0,0 +1,137 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package approval
+
+import (
+	"context"
+	"testing"
+
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCELContentPolicy(t *testing.T) {
+	testCases := map[string]struct {
+		expression         string
+		match              string
+		message            string
+		docs               []string
+		expectedViolations []string
+		expectedErr        bool
+	}{
+		"met": {
+			expression:         "!has(object.spec.template.spec.hostNetwork) || !object.spec.template.spec.hostNetwork",
+			match:              "object.kind == 'Deployment'",
+			docs:               []string{smfPod},
+			expectedViolations: []string{},
+		},
+		"violated": {
+			expression:         "!has(object.spec.template.spec.hostNetwork) || !object.spec.template.spec.hostNetwork",
+			match:              "object.kind == 'Deployment'",
+			message:            "uses hostNetwork",
+			docs:               []string{upfDeployment, smfPod},
+			expectedViolations: []string{"Deployment upf uses hostNetwork"},
+		},
+		"nad names": {
+			expression:         "object.metadata.name.startsWith('upf-')",
+			match:              "object.kind == 'NetworkAttachmentDefinition'",
+			docs:               []string{n6NAD, upfDeployment},
+			expectedViolations: []string{},
+		},
+		"all resources": {
+			expression:         "object.metadata.name != 'smf'",
+			docs:               []string{upfDeployment, smfPod},
+			expectedViolations: []string{"Pod smf does not meet object.metadata.name != 'smf'"},
+		},
+		"objects": {
+			expression:         "objects.exists(o, o.kind == 'NetworkAttachmentDefinition')",
+			match:              "object.kind == 'Deployment'",
+			message:            "has no NetworkAttachmentDefinition",
+			docs:               []string{upfDeployment, smfPod},
+			expectedViolations: []string{"Deployment upf has no NetworkAttachmentDefinition"},
+		},
+		"missing field": {
+			expression:  "object.spec.template.spec.hostNetwork",
+			docs:        []string{smfPod},
+			expectedErr: true,
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			p, err := NewCELContentPolicy(tc.expression, tc.match, tc.message)
+			require.NoError(t, err)
+			violations, err := p.Evaluate(getTestObjects(t, tc.docs...))
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedViolations, violations)
+		})
+	}
+}
+
+func TestNewCELContentPolicyInvalid(t *testing.T) {
+	for tn, expression := range map[string]string{
+		"syntax":   "object.kind ==",
+		"not bool": "object.kind + 'a' == 1 ? 'a' : 'b'",
+	} {
+		t.Run(tn, func(t *testing.T) {
+			_, err := NewCELContentPolicy(expression, "", "")
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestGetPolicies(t *testing.T) {
+	c := fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-smf", Namespace: "default", Labels: map[string]string{CELContentPolicyLabelKey: "true"}},
+			Data:       map[string]string{CELExpressionKey: "object.metadata.name != 'smf'"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "unlabeled", Namespace: "default"},
+			Data:       map[string]string{CELExpressionKey: "true"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "other", Labels: map[string]string{CELContentPolicyLabelKey: "true"}},
+			Data:       map[string]string{CELExpressionKey: "true"},
+		},
+	).Build()
+	r := &reconciler{Client: c}
+
+	pr := &porchapi.PackageRevision{ObjectMeta: metav1.ObjectMeta{Namespace: "default",
+		Annotations: map[string]string{ContentPoliciesAnnotationName: "no-smf,no-host-network"}}}
+	policies, err := r.getPolicies(context.Background(), pr)
+	require.NoError(t, err)
+	names, err := getContentPolicies(pr, policies)
+	require.NoError(t, err)
+	require.Equal(t, []string{"no-smf", NoHostNetworkContentPolicy}, names)
+
+	_, failed, err := getContentPolicyConditions(getTestObjects(t, smfPod), policies, names)
+	require.NoError(t, err)
+	require.Equal(t, []string{"no-smf"}, failed)
+
+	for _, name := range []string{"unlabeled", "other-namespace"} {
+		pr.Annotations[ContentPoliciesAnnotationName] = name
+		_, err := getContentPolicies(pr, policies)
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,273 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package approval
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	"github.com/nephio-project/nephio/krm-functions/lib/kptrl"
+	nadlibv1 "github.com/nephio-project/nephio/krm-functions/lib/nad/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+)
+
+const (
+	// ContentPoliciesAnnotationName selects the comma separated content policies the resources of
+	// the package revision must meet before it is proposed or approved
+	ContentPoliciesAnnotationName = "approval.nephio.org/content-policies"
+	// ContentPolicyConditionTypePrefix prefixes the Kptfile condition of each content policy
+	ContentPolicyConditionTypePrefix = "approval.nephio.org.content-policy"
+
+	NoHostNetworkContentPolicy    = "no-host-network"
+	NADsValidatedContentPolicy    = "nads-validated"
+	ResourceRequestsContentPolicy = "resource-requests"
+	contentPolicyViolationReason  = "PolicyViolation"
+	contentPolicyMetReason        = "PolicyMet"
+	nadAPIVersion                 = "k8s.cni.cncf.io/v1"
+	nadKind                       = "NetworkAttachmentDefinition"
+)
+
+// ContentPolicy is a policy on the resources of a package revision; a policy engine, e.g. OPA, plugs
+// in the approval controller as a ContentPolicy registered with RegisterContentPolicy, the user written
+// CEL policies are read from ConfigMaps, see NewCELContentPolicy
+type ContentPolicy interface {
+	// Evaluate returns the violations of the policy by the resources of the package revision
+	Evaluate(objs fn.KubeObjects) ([]string, error)
+}
+
+// ContentPolicyFunc is a ContentPolicy implemented by a function
+type ContentPolicyFunc func(objs fn.KubeObjects) ([]string, error)
+
+func (f ContentPolicyFunc) Evaluate(objs fn.KubeObjects) ([]string, error) {
+	return f(objs)
+}
+
+// ContentPolicies holds the content policies selectable by name in the content policies annotation
+var ContentPolicies = map[string]ContentPolicy{}
+
+func RegisterContentPolicy(name string, p ContentPolicy) {
+	ContentPolicies[name] = p
+}
+
+func init() {
+	RegisterContentPolicy(NoHostNetworkContentPolicy, ContentPolicyFunc(noHostNetwork))
+	RegisterContentPolicy(NADsValidatedContentPolicy, ContentPolicyFunc(nadsValidated))
+	RegisterContentPolicy(ResourceRequestsContentPolicy, ContentPolicyFunc(resourceRequests))
+}
+
+// getPolicies returns the content policies selectable by the package revision, the registered
+// content policies and the CEL content policies of its namespace
+func (r *reconciler) getPolicies(ctx context.Context, pr *porchv1alpha1.PackageRevision) (map[string]ContentPolicy, error) {
+	if _, ok := pr.GetAnnotations()[ContentPoliciesAnnotationName]; !ok {
+		return ContentPolicies, nil
+	}
+	policies, err := r.getCELContentPolicies(ctx, pr.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	// the registered content policies take precedence over the CEL content policies
+	for name, p := range ContentPolicies {
+		policies[name] = p
+	}
+	return policies, nil
+}
+
+// getContentPolicies returns the names of the content policies of the package revision
+func getContentPolicies(pr *porchv1alpha1.PackageRevision, policies map[string]ContentPolicy) ([]string, error) {
+	v, ok := pr.GetAnnotations()[ContentPoliciesAnnotationName]
+	if !ok {
+		return nil, nil
+	}
+	names := []string{}
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := policies[name]; !ok {
+			return nil, fmt.Errorf("unknown content policy %q in %q annotation", name, ContentPoliciesAnnotationName)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// evaluateContentPolicies evaluates the content policies against the resources of the package
+// revision; the result of each policy is reported as a condition of the Kptfile of a draft, it
+// returns the policies that are not met
+func (r *reconciler) evaluateContentPolicies(ctx context.Context, pr *porchv1alpha1.PackageRevision, policies map[string]ContentPolicy, names []string) ([]string, error) {
+	prr := &porchv1alpha1.PackageRevisionResources{}
+	if err := r.porchClient.Get(ctx, types.NamespacedName{Namespace: pr.Namespace, Name: pr.Name}, prr); err != nil {
+		return nil, err
+	}
+	rl, err := kptrl.GetResourceList(prr.Spec.Resources)
+	if err != nil {
+		return nil, err
+	}
+	conditions, failed, err := getContentPolicyConditions(rl.Items, policies, names)
+	if err != nil {
+		return nil, err
+	}
+
+	// the resources of a proposed package revision cannot be updated, the violations are reported as events
+	kfko := rl.Items.GetRootKptfile()
+	if kfko == nil || pr.Spec.Lifecycle != porchv1alpha1.PackageRevisionLifecycleDraft {
+		return failed, nil
+	}
+	kf := kptfilelibv1.KptFile{Kptfile: kfko}
+	changed := false
+	for _, c := range conditions {
+		if ec := kf.GetCondition(c.Type); ec == nil || !reflect.DeepEqual(*ec, c) {
+			changed = true
+		}
+	}
+	if !changed {
+		return failed, nil
+	}
+	if err := kf.SetConditions(conditions...); err != nil {
+		return nil, err
+	}
+	prr.Spec.Resources[kfko.GetAnnotation(kioutil.PathAnnotation)] = kfko.String()
+	if err := r.porchClient.Update(ctx, prr); err != nil {
+		return nil, err
+	}
+	return failed, nil
+}
+
+// getContentPolicyConditions returns the condition of each content policy and the policies that are not met
+func getContentPolicyConditions(objs fn.KubeObjects, policies map[string]ContentPolicy, names []string) ([]kptv1.Condition, []string, error) {
+	conditions := []kptv1.Condition{}
+	failed := []string{}
+	for _, name := range names {
+		violations, err := policies[name].Evaluate(objs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("content policy %q: %s", name, err.Error())
+		}
+		c := kptv1.Condition{
+			Type:    fmt.Sprintf("%s.%s", ContentPolicyConditionTypePrefix, name),
+			Status:  kptv1.ConditionTrue,
+			Reason:  contentPolicyMetReason,
+			Message: fmt.Sprintf("content policy %s met", name),
+		}
+		if len(violations) != 0 {
+			sort.Strings(violations)
+			c.Status = kptv1.ConditionFalse
+			c.Reason = contentPolicyViolationReason
+			c.Message = strings.Join(violations, "; ")
+			failed = append(failed, name)
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, failed, nil
+}
+
+// podSpecPaths holds the path of the pod spec of the kinds of workloads
+var podSpecPaths = map[string][]string{
+	"Pod":         {"spec"},
+	"Deployment":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"ReplicaSet":  {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// getPodSpecs returns the pod specs of the workloads of the resources per workload, e.g. Deployment upf
+func getPodSpecs(objs fn.KubeObjects) map[string]*fn.SubObject {
+	specs := map[string]*fn.SubObject{}
+	for _, o := range objs {
+		path, ok := podSpecPaths[o.GetKind()]
+		if !ok {
+			continue
+		}
+		spec, ok, err := o.NestedSubObject(path...)
+		if err != nil || !ok {
+			continue
+		}
+		specs[fmt.Sprintf("%s %s", o.GetKind(), o.GetName())] = &spec
+	}
+	return specs
+}
+
+// noHostNetwork is violated by the workloads using the network namespace of the host
+func noHostNetwork(objs fn.KubeObjects) ([]string, error) {
+	violations := []string{}
+	for name, spec := range getPodSpecs(objs) {
+		hostNetwork, _, err := spec.NestedBool("hostNetwork")
+		if err != nil {
+			return nil, err
+		}
+		if hostNetwork {
+			violations = append(violations, fmt.Sprintf("%s uses hostNetwork", name))
+		}
+	}
+	return violations, nil
+}
+
+// resourceRequests is violated by the containers of the workloads without cpu and memory requests
+func resourceRequests(objs fn.KubeObjects) ([]string, error) {
+	violations := []string{}
+	for name, spec := range getPodSpecs(objs) {
+		for _, field := range []string{"initContainers", "containers"} {
+			containers, _, err := spec.NestedSlice(field)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range containers {
+				missing := []string{}
+				for _, resource := range []string{"cpu", "memory"} {
+					if v, _, _ := c.NestedString("resources", "requests", resource); v == "" {
+						missing = append(missing, resource)
+					}
+				}
+				if len(missing) != 0 {
+					violations = append(violations, fmt.Sprintf("container %s of %s has no %s requests", c.GetString("name"), name, strings.Join(missing, " and ")))
+				}
+			}
+		}
+	}
+	return violations, nil
+}
+
+// nadsValidated is violated by the NetworkAttachmentDefinitions without a valid CNI config
+func nadsValidated(objs fn.KubeObjects) ([]string, error) {
+	violations := []string{}
+	for _, o := range objs.Where(fn.IsGroupVersionKind(schema.FromAPIVersionAndKind(nadAPIVersion, nadKind))) {
+		nad, err := nadlibv1.NewFromKubeObject(o)
+		if err != nil {
+			return nil, err
+		}
+		if nad.GetConfigSpec() == "" {
+			violations = append(violations, fmt.Sprintf("%s %s has no config", nadKind, o.GetName()))
+			continue
+		}
+		// the config is validated against the schemas of the nad fn, which renders it
+		if err := nad.ValidateConfig(); err != nil {
+			violations = append(violations, fmt.Sprintf("%s %s has an invalid config: %s", nadKind, o.GetName(), err.Error()))
+		}
+	}
+	return violations, nil
+}
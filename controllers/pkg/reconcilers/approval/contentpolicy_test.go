@@ -0,0 +1,198 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package approval
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	porchapi "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	upfDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: upf
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      initContainers:
+      - name: init
+        resources:
+          requests:
+            cpu: 100m
+            memory: 64Mi
+      containers:
+      - name: upf
+        resources:
+          requests:
+            cpu: "1"
+`
+	smfPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: smf
+spec:
+  containers:
+  - name: smf
+    resources:
+      requests:
+        cpu: 500m
+        memory: 256Mi
+`
+	n6NAD = `apiVersion: k8s.cni.cncf.io/v1
+kind: NetworkAttachmentDefinition
+metadata:
+  name: upf-n6
+spec:
+  config: '{"cniVersion": "0.3.1", "plugins": [{"type": "macvlan", "master": "eth1"}]}'
+`
+	n3NAD = `apiVersion: k8s.cni.cncf.io/v1
+kind: NetworkAttachmentDefinition
+metadata:
+  name: upf-n3
+spec:
+  config: '{"type": "macvlan"}'
+`
+	n4NAD = `apiVersion: k8s.cni.cncf.io/v1
+kind: NetworkAttachmentDefinition
+metadata:
+  name: upf-n4
+spec:
+  config: ""
+`
+)
+
+func getTestObjects(t *testing.T, docs ...string) fn.KubeObjects {
+	objs := fn.KubeObjects{}
+	for _, doc := range docs {
+		o, err := fn.ParseKubeObject([]byte(doc))
+		require.NoError(t, err)
+		objs = append(objs, o)
+	}
+	return objs
+}
+
+func TestGetContentPolicies(t *testing.T) {
+	testCases := map[string]struct {
+		annotations      map[string]string
+		expectedPolicies []string
+		expectedErr      bool
+	}{
+		"no annotation": {
+			annotations:      nil,
+			expectedPolicies: nil,
+		},
+		"policies": {
+			annotations:      map[string]string{"approval.nephio.org/content-policies": "no-host-network, resource-requests,"},
+			expectedPolicies: []string{"no-host-network", "resource-requests"},
+		},
+		"unknown policy": {
+			annotations: map[string]string{"approval.nephio.org/content-policies": "no-privileged"},
+			expectedErr: true,
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			pr := &porchapi.PackageRevision{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			policies, err := getContentPolicies(pr, ContentPolicies)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedPolicies, policies)
+		})
+	}
+}
+
+func TestContentPolicies(t *testing.T) {
+	testCases := map[string]struct {
+		policy             string
+		docs               []string
+		expectedViolations []string
+	}{
+		"no host network met": {
+			policy:             NoHostNetworkContentPolicy,
+			docs:               []string{smfPod},
+			expectedViolations: []string{},
+		},
+		"no host network violated": {
+			policy:             NoHostNetworkContentPolicy,
+			docs:               []string{upfDeployment, smfPod},
+			expectedViolations: []string{"Deployment upf uses hostNetwork"},
+		},
+		"resource requests met": {
+			policy:             ResourceRequestsContentPolicy,
+			docs:               []string{smfPod},
+			expectedViolations: []string{},
+		},
+		"resource requests violated": {
+			policy:             ResourceRequestsContentPolicy,
+			docs:               []string{upfDeployment},
+			expectedViolations: []string{"container upf of Deployment upf has no memory requests"},
+		},
+		"nads validated met": {
+			policy:             NADsValidatedContentPolicy,
+			docs:               []string{n6NAD, smfPod},
+			expectedViolations: []string{},
+		},
+		"nads validated violated": {
+			policy:             NADsValidatedContentPolicy,
+			docs:               []string{n6NAD, n3NAD},
+			expectedViolations: []string{"NetworkAttachmentDefinition upf-n3 has an invalid config: master is required"},
+		},
+		"nads validated no config": {
+			policy:             NADsValidatedContentPolicy,
+			docs:               []string{n4NAD},
+			expectedViolations: []string{"NetworkAttachmentDefinition upf-n4 has no config"},
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			violations, err := ContentPolicies[tc.policy].Evaluate(getTestObjects(t, tc.docs...))
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedViolations, violations)
+		})
+	}
+}
+
+func TestGetContentPolicyConditions(t *testing.T) {
+	objs := getTestObjects(t, upfDeployment, n6NAD)
+	conditions, failed, err := getContentPolicyConditions(objs, ContentPolicies, []string{NoHostNetworkContentPolicy, NADsValidatedContentPolicy})
+	require.NoError(t, err)
+	require.Equal(t, []string{NoHostNetworkContentPolicy}, failed)
+	require.Equal(t, []kptv1.Condition{
+		{
+			Type:    "approval.nephio.org.content-policy.no-host-network",
+			Status:  kptv1.ConditionFalse,
+			Reason:  "PolicyViolation",
+			Message: "Deployment upf uses hostNetwork",
+		},
+		{
+			Type:    "approval.nephio.org.content-policy.nads-validated",
+			Status:  kptv1.ConditionTrue,
+			Reason:  "PolicyMet",
+			Message: "content policy nads-validated met",
+		},
+	}, conditions)
+}
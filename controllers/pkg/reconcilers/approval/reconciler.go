@@ -20,8 +20,11 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
+	"github.com/nephio-project/nephio/controllers/pkg/metrics"
+	"github.com/nephio-project/nephio/controllers/pkg/sharding"
 	"k8s.io/client-go/rest"
 
 	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
@@ -57,9 +60,12 @@ func init() {
 // +kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions/status,verbs=get
 // +kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions/approval,verbs=get;update;patch
+// +kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisionresources,verbs=get;update;patch
 // +kubebuilder:rbac:groups=config.porch.kpt.dev,resources=packagevariants,verbs=get;list;watch
 // +kubebuilder:rbac:groups=config.porch.kpt.dev,resources=packagevariants/status,verbs=get
+// +kubebuilder:rbac:groups=config.porch.kpt.dev,resources=repositories,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c interface{}) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
 	cfg, ok := c.(*ctrlconfig.ControllerConfig)
@@ -75,7 +81,7 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("ApprovalController").
 		For(&porchv1alpha1.PackageRevision{}).
-		Complete(r)
+		Complete(sharding.NewReconciler(cfg.Shard, metrics.NewReconciler("ApprovalController", r)))
 }
 
 // reconciler reconciles a NetworkInstance object
@@ -124,6 +130,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if !pvReady {
 		r.recorder.Event(pr, corev1.EventTypeNormal,
 			"NotApproved", "owning PackageVariant not Ready")
+		metrics.PackagesHeld.WithLabelValues("PackageVariantNotReady").Inc()
 
 		return ctrl.Result{}, nil
 	}
@@ -133,6 +140,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if !porchconds.PackageRevisionIsReady(pr.Spec.ReadinessGates, pr.Status.Conditions) {
 		r.recorder.Event(pr, corev1.EventTypeNormal,
 			"NotApproved", "readiness gates not met")
+		metrics.PackagesHeld.WithLabelValues("ReadinessGates").Inc()
 
 		return ctrl.Result{}, nil
 	}
@@ -159,10 +167,42 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if !approve {
 		r.recorder.Eventf(pr, corev1.EventTypeNormal,
 			"NotApproved", "approval policy %q not met", policy)
+		metrics.PackagesHeld.WithLabelValues("ApprovalPolicy").Inc()
 
 		return ctrl.Result{}, nil
 	}
 
+	// The resources of the package must meet the content policies, the
+	// result of each policy is reported as a condition of the package
+	policies, err := r.getPolicies(ctx, pr)
+	if err != nil {
+		r.recorder.Event(pr, corev1.EventTypeWarning, "InvalidPolicy", err.Error())
+
+		return ctrl.Result{}, nil
+	}
+	contentPolicies, err := getContentPolicies(pr, policies)
+	if err != nil {
+		r.recorder.Event(pr, corev1.EventTypeWarning, "InvalidPolicy", err.Error())
+
+		return ctrl.Result{}, nil
+	}
+	if len(contentPolicies) != 0 {
+		failed, err := r.evaluateContentPolicies(ctx, pr, policies, contentPolicies)
+		if err != nil {
+			r.recorder.Eventf(pr, corev1.EventTypeWarning,
+				"Error", "error evaluating content policies: %s", err.Error())
+
+			return ctrl.Result{}, nil
+		}
+		if len(failed) != 0 {
+			r.recorder.Eventf(pr, corev1.EventTypeNormal,
+				"NotApproved", "content policies %q not met", strings.Join(failed, ", "))
+			metrics.PackagesHeld.WithLabelValues("ContentPolicy").Inc()
+
+			return ctrl.Result{}, nil
+		}
+	}
+
 	// Delay if needed, and let the user know via an event
 	// We should be able to get rid of this if we add a policy to check
 	// the specializer condition. We need to check the *specific* condition,
@@ -185,23 +225,55 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if requeue > 0 {
 		r.recorder.Event(pr, corev1.EventTypeNormal,
 			"NotApproved", "delay time not met")
+		metrics.PackagesHeld.WithLabelValues("Delay").Inc()
 		return ctrl.Result{RequeueAfter: requeue}, nil
 	}
 
+	// A proposed package is only approved in the approval window of its
+	// PackageVariant or repository, it is held in Proposed otherwise
+	if pr.Spec.Lifecycle == porchv1alpha1.PackageRevisionLifecycleProposed {
+		window, err := r.getApprovalWindow(ctx, pr)
+		if err != nil {
+			r.recorder.Eventf(pr, corev1.EventTypeWarning,
+				"Error", "error getting the approval window: %s", err.Error())
+
+			return ctrl.Result{}, nil
+		}
+		if window != nil {
+			requeue, err := window.untilOpen(time.Now())
+			if err != nil {
+				r.recorder.Eventf(pr, corev1.EventTypeWarning,
+					"Error", "error processing %q: %s", WindowScheduleAnnotationName, err.Error())
+
+				return ctrl.Result{}, nil
+			}
+			if requeue > 0 {
+				r.recorder.Eventf(pr, corev1.EventTypeNormal,
+					"NotApproved", "outside of the approval window, next window opens in %s", requeue.Round(time.Second))
+				metrics.PackagesHeld.WithLabelValues("ApprovalWindow").Inc()
+				return ctrl.Result{RequeueAfter: requeue}, nil
+			}
+		}
+	}
+
 	// All policies met
+	lifecycle := porchv1alpha1.PackageRevisionLifecyclePublished
 	if pr.Spec.Lifecycle == porchv1alpha1.PackageRevisionLifecycleDraft {
-		pr.Spec.Lifecycle = porchv1alpha1.PackageRevisionLifecycleProposed
+		lifecycle = porchv1alpha1.PackageRevisionLifecycleProposed
+		pr.Spec.Lifecycle = lifecycle
 		err = r.Update(ctx, pr)
 	} else {
 		err = porchclient.UpdatePackageRevisionApproval(ctx, r.porchRESTClient, client.ObjectKey{
 			Namespace: pr.Namespace,
 			Name:      pr.Name,
-		}, porchv1alpha1.PackageRevisionLifecyclePublished)
+		}, lifecycle)
 	}
 
 	if err != nil {
 		r.recorder.Eventf(pr, corev1.EventTypeWarning,
 			"Error", "error approving: %s", err.Error())
+	} else {
+		metrics.PackagesApproved.WithLabelValues(string(lifecycle)).Inc()
 	}
 
 	return ctrl.Result{}, err
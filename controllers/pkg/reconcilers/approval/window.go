@@ -0,0 +1,232 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package approval
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	porchutil "github.com/nephio-project/nephio/controllers/pkg/porch/util"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// WindowScheduleAnnotationName is the cron schedule, e.g. "0 2 * * 6", of the opening of the
+	// approval windows of the packages of a PackageVariant or a repository
+	WindowScheduleAnnotationName = "approval.nephio.org/window-schedule"
+	// WindowDurationAnnotationName is the duration of an approval window
+	WindowDurationAnnotationName = "approval.nephio.org/window-duration"
+	// WindowTimezoneAnnotationName is the timezone of the schedule, UTC by default
+	WindowTimezoneAnnotationName = "approval.nephio.org/window-timezone"
+
+	defaultWindowDuration = time.Hour
+	// maxScheduleSearch bounds the search of the next opening of a window
+	maxScheduleSearch = 5 * 366 * 24 * time.Hour
+)
+
+// approvalWindow is a recurring window during which proposed packages are approved
+type approvalWindow struct {
+	schedule *cronSchedule
+	duration time.Duration
+	location *time.Location
+}
+
+// getApprovalWindow returns the approval window of the package revision, the window of its
+// PackageVariant or else of its repository; nil when the package revision has no window
+func (r *reconciler) getApprovalWindow(ctx context.Context, pr *porchv1alpha1.PackageRevision) (*approvalWindow, error) {
+	pv, err := porchutil.GetPackageVariant(ctx, pr, r.porchClient)
+	if err != nil {
+		return nil, err
+	}
+	if pv != nil {
+		if _, ok := pv.GetAnnotations()[WindowScheduleAnnotationName]; ok {
+			return parseApprovalWindow(pv.GetAnnotations())
+		}
+	}
+	repo := &porchconfigv1alpha1.Repository{}
+	if err := r.porchClient.Get(ctx, types.NamespacedName{Namespace: pr.Namespace, Name: pr.Spec.RepositoryName}, repo); err != nil {
+		return nil, resource.IgnoreNotFound(err)
+	}
+	return parseApprovalWindow(repo.GetAnnotations())
+}
+
+func parseApprovalWindow(annotations map[string]string) (*approvalWindow, error) {
+	s, ok := annotations[WindowScheduleAnnotationName]
+	if !ok {
+		return nil, nil
+	}
+	schedule, err := parseCronSchedule(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %q annotation value %q: %s", WindowScheduleAnnotationName, s, err.Error())
+	}
+	w := &approvalWindow{schedule: schedule, duration: defaultWindowDuration, location: time.UTC}
+	if d, ok := annotations[WindowDurationAnnotationName]; ok {
+		w.duration, err = time.ParseDuration(d)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q annotation value %q: %s", WindowDurationAnnotationName, d, err.Error())
+		}
+		if w.duration < time.Minute {
+			return nil, fmt.Errorf("invalid %q annotation value %q: the duration must be at least 1m", WindowDurationAnnotationName, d)
+		}
+	}
+	if tz, ok := annotations[WindowTimezoneAnnotationName]; ok {
+		w.location, err = time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q annotation value %q: %s", WindowTimezoneAnnotationName, tz, err.Error())
+		}
+	}
+	return w, nil
+}
+
+// untilOpen returns 0 when the window is open at t, otherwise the time until the next opening
+func (r *approvalWindow) untilOpen(t time.Time) (time.Duration, error) {
+	t = t.In(r.location)
+	// the window is open when it opened in the last duration
+	if opening, ok := r.schedule.next(t.Add(-r.duration)); ok && !opening.After(t) {
+		return 0, nil
+	}
+	opening, ok := r.schedule.next(t)
+	if !ok {
+		return 0, fmt.Errorf("the schedule has no window")
+	}
+	return opening.Sub(t), nil
+}
+
+// cronSchedule is a standard 5 field cron schedule: minute, hour, day of month, month, day of week
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are true when the day of month or day of week is *, with both
+	// restricted a day matches either of them
+	domStar, dowStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// parseCronSchedule parses a cron schedule; a field is *, a value, a range a-b or a list of them,
+// with an optional step /n
+func parseCronSchedule(s string) (*cronSchedule, error) {
+	fields := strings.Fields(s)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(cronFields), len(fields))
+	}
+	bits := make([]uint64, len(fields))
+	for i, f := range fields {
+		var err error
+		bits[i], err = parseCronField(f, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	// sunday is 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &cronSchedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseCronField(s string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, step, hasStep := strings.Cut(part, "/")
+		inc := 1
+		if hasStep {
+			var err error
+			inc, err = strconv.Atoi(step)
+			if err != nil || inc <= 0 {
+				return 0, fmt.Errorf("invalid step %q of the %s", step, f.name)
+			}
+		}
+		start, end := f.min, f.max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if start, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid %s %q", f.name, part)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid %s %q", f.name, part)
+				}
+			} else if hasStep {
+				end = f.max
+			}
+		}
+		if start < f.min || end > f.max || start > end {
+			return 0, fmt.Errorf("%s %q out of range [%d-%d]", f.name, part, f.min, f.max)
+		}
+		for v := start; v <= end; v += inc {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (r *cronSchedule) matchDay(t time.Time) bool {
+	dom := r.dom&(1<<uint(t.Day())) != 0
+	dow := r.dow&(1<<uint(t.Weekday())) != 0
+	if r.domStar || r.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// next returns the first time after t matching the schedule, in the location of t
+func (r *cronSchedule) next(t time.Time) (time.Time, bool) {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxScheduleSearch)
+	for t.Before(limit) {
+		switch {
+		case r.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !r.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case r.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case r.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,193 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package approval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	testCases := map[string]struct {
+		schedule    string
+		expectedErr bool
+	}{
+		"every minute":       {schedule: "* * * * *"},
+		"saturday night":     {schedule: "0 2 * * 6"},
+		"lists and steps":    {schedule: "0,30 */4 1-15 1-12/3 1-5"},
+		"sunday as 7":        {schedule: "0 0 * * 7"},
+		"missing field":      {schedule: "0 2 * *", expectedErr: true},
+		"out of range":       {schedule: "60 2 * * *", expectedErr: true},
+		"invalid range":      {schedule: "0 5-2 * * *", expectedErr: true},
+		"invalid step":       {schedule: "*/0 * * * *", expectedErr: true},
+		"invalid value":      {schedule: "0 2 * * sat", expectedErr: true},
+		"zero day of month":  {schedule: "0 2 0 * *", expectedErr: true},
+		"extra field":        {schedule: "0 2 * * * 2023", expectedErr: true},
+		"step from a value":  {schedule: "5/15 * * * *"},
+		"range with a step":  {schedule: "0-30/10 * * * *"},
+		"negative range end": {schedule: "0 2-  * * *", expectedErr: true},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			_, err := parseCronSchedule(tc.schedule)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	// a wednesday
+	now := time.Date(2023, time.June, 14, 10, 17, 30, 0, time.UTC)
+	testCases := map[string]struct {
+		schedule string
+		expected time.Time
+	}{
+		"every minute": {
+			schedule: "* * * * *",
+			expected: time.Date(2023, time.June, 14, 10, 18, 0, 0, time.UTC),
+		},
+		"every quarter": {
+			schedule: "*/15 * * * *",
+			expected: time.Date(2023, time.June, 14, 10, 30, 0, 0, time.UTC),
+		},
+		"saturday night": {
+			schedule: "0 2 * * 6",
+			expected: time.Date(2023, time.June, 17, 2, 0, 0, 0, time.UTC),
+		},
+		"first of the month": {
+			schedule: "30 1 1 * *",
+			expected: time.Date(2023, time.July, 1, 1, 30, 0, 0, time.UTC),
+		},
+		"day of month or day of week": {
+			schedule: "0 0 20 * 5",
+			expected: time.Date(2023, time.June, 16, 0, 0, 0, 0, time.UTC),
+		},
+		"next year": {
+			schedule: "0 0 1 1 *",
+			expected: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			s, err := parseCronSchedule(tc.schedule)
+			require.NoError(t, err)
+			next, ok := s.next(now)
+			require.True(t, ok)
+			require.Equal(t, tc.expected, next)
+		})
+	}
+
+	s, err := parseCronSchedule("0 0 31 2 *")
+	require.NoError(t, err)
+	_, ok := s.next(now)
+	require.False(t, ok)
+}
+
+func TestApprovalWindow(t *testing.T) {
+	testCases := map[string]struct {
+		annotations     map[string]string
+		now             time.Time
+		expectedRequeue time.Duration
+		expectedNil     bool
+		expectedErr     bool
+	}{
+		"no window": {
+			annotations: map[string]string{},
+			expectedNil: true,
+		},
+		"open": {
+			annotations: map[string]string{
+				"approval.nephio.org/window-schedule": "0 2 * * 6",
+				"approval.nephio.org/window-duration": "4h",
+			},
+			now:             time.Date(2023, time.June, 17, 5, 59, 0, 0, time.UTC),
+			expectedRequeue: 0,
+		},
+		"opening": {
+			annotations: map[string]string{
+				"approval.nephio.org/window-schedule": "0 2 * * 6",
+				"approval.nephio.org/window-duration": "4h",
+			},
+			now:             time.Date(2023, time.June, 17, 2, 0, 0, 0, time.UTC),
+			expectedRequeue: 0,
+		},
+		"closed": {
+			annotations: map[string]string{
+				"approval.nephio.org/window-schedule": "0 2 * * 6",
+				"approval.nephio.org/window-duration": "4h",
+			},
+			now:             time.Date(2023, time.June, 17, 6, 0, 0, 0, time.UTC),
+			expectedRequeue: 7*24*time.Hour - 4*time.Hour,
+		},
+		"default duration": {
+			annotations: map[string]string{
+				"approval.nephio.org/window-schedule": "0 2 * * *",
+			},
+			now:             time.Date(2023, time.June, 17, 3, 0, 0, 0, time.UTC),
+			expectedRequeue: 23 * time.Hour,
+		},
+		"timezone": {
+			annotations: map[string]string{
+				"approval.nephio.org/window-schedule": "0 2 * * *",
+				"approval.nephio.org/window-timezone": "UTC",
+			},
+			now:             time.Date(2023, time.June, 17, 1, 30, 0, 0, time.UTC),
+			expectedRequeue: 30 * time.Minute,
+		},
+		"invalid schedule": {
+			annotations: map[string]string{"approval.nephio.org/window-schedule": "0 2 * *"},
+			expectedErr: true,
+		},
+		"invalid duration": {
+			annotations: map[string]string{
+				"approval.nephio.org/window-schedule": "0 2 * * *",
+				"approval.nephio.org/window-duration": "30s",
+			},
+			expectedErr: true,
+		},
+		"invalid timezone": {
+			annotations: map[string]string{
+				"approval.nephio.org/window-schedule": "0 2 * * *",
+				"approval.nephio.org/window-timezone": "Mars/Olympus",
+			},
+			expectedErr: true,
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			w, err := parseApprovalWindow(tc.annotations)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tc.expectedNil {
+				require.Nil(t, w)
+				return
+			}
+			requeue, err := w.untilOpen(tc.now)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedRequeue, requeue)
+		})
+	}
+}
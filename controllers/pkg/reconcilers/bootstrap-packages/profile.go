@@ -0,0 +1,149 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrappackages
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// BootstrapProfileKind is the kind of the ordered package sets installed on new workload clusters,
+	// it is not part of the nephio api yet and is read as unstructured
+	BootstrapProfileKind = "BootstrapProfile"
+	// clusterNameVariable is the template variable holding the name of the workload cluster
+	clusterNameVariable = "clusterName"
+)
+
+// BootstrapProfileGroupVersionKind is the GroupVersionKind of the bootstrap profiles
+var BootstrapProfileGroupVersionKind = schema.GroupVersionKind{Group: "infra.nephio.org", Version: "v1alpha1", Kind: BootstrapProfileKind}
+
+// BootstrapProfile lists the packages installed in order on the workload clusters selected by the profile
+type BootstrapProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              BootstrapProfileSpec `json:"spec,omitempty"`
+}
+
+type BootstrapProfileSpec struct {
+	// ClusterSelector selects the workload clusters by the labels of their kubeconfig secret,
+	// an empty selector selects all workload clusters
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+	// Variables defines the template variables of all workload clusters, e.g. the proxy settings
+	Variables map[string]string `json:"variables,omitempty"`
+	// Clusters defines the template variables per workload cluster name, e.g. the region, they
+	// override the variables of all workload clusters
+	Clusters map[string]map[string]string `json:"clusters,omitempty"`
+	// Packages defines the packages installed in order on the workload cluster
+	Packages []BootstrapPackage `json:"packages"`
+}
+
+type BootstrapPackage struct {
+	// Repository is the name of the porch repository of the package
+	Repository string `json:"repository"`
+	// PackageName is the name of the package in the repository
+	PackageName string `json:"packageName"`
+	// Revision is the published revision of the package, by default the latest revision
+	Revision string `json:"revision,omitempty"`
+}
+
+// getBootstrapProfile returns the bootstrap profile of the unstructured object
+func getBootstrapProfile(u *unstructured.Unstructured) (*BootstrapProfile, error) {
+	b, err := json.Marshal(u)
+	if err != nil {
+		return nil, err
+	}
+	p := &BootstrapProfile{}
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, err
+	}
+	return p, p.Validate()
+}
+
+// Validate checks the bootstrap profile
+func (r *BootstrapProfile) Validate() error {
+	if len(r.Spec.Packages) == 0 {
+		return fmt.Errorf("BootstrapProfile %s has no packages", r.GetName())
+	}
+	for i, pkg := range r.Spec.Packages {
+		if pkg.Repository == "" || pkg.PackageName == "" {
+			return fmt.Errorf("package %d of BootstrapProfile %s requires a repository and a packageName", i, r.GetName())
+		}
+	}
+	return nil
+}
+
+// Selects returns true when the profile selects the workload cluster of the kubeconfig secret
+func (r *BootstrapProfile) Selects(secret *corev1.Secret) (bool, error) {
+	if r.Spec.ClusterSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(r.Spec.ClusterSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(secret.GetLabels())), nil
+}
+
+// GetVariables returns the template variables of the workload cluster
+func (r *BootstrapProfile) GetVariables(clusterName string) map[string]string {
+	vars := map[string]string{}
+	for k, v := range r.Spec.Variables {
+		vars[k] = v
+	}
+	for k, v := range r.Spec.Clusters[clusterName] {
+		vars[k] = v
+	}
+	vars[clusterNameVariable] = clusterName
+	return vars
+}
+
+// renderResources executes the files of a package as go templates of the variables, a variable
+// missing from the profile fails the rendering
+func renderResources(resources map[string]string, vars map[string]string) (map[string]string, error) {
+	paths := make([]string, 0, len(resources))
+	for path := range resources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	rendered := make(map[string]string, len(resources))
+	for _, path := range paths {
+		if !includeFile(path, []string{"*.yaml", "*.yml", "Kptfile"}) {
+			continue
+		}
+		t, err := template.New(path).Option("missingkey=error").Parse(resources[path])
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse template %s: %s", path, err.Error())
+		}
+		var out bytes.Buffer
+		if err := t.Execute(&out, vars); err != nil {
+			return nil, fmt.Errorf("cannot render template %s: %s", path, err.Error())
+		}
+		rendered[path] = out.String()
+	}
+	return rendered, nil
+}
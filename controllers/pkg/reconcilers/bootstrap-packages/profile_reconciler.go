@@ -0,0 +1,241 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrappackages
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster"
+	"github.com/nephio-project/nephio/controllers/pkg/metrics"
+	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/nephio-project/nephio/controllers/pkg/sharding"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+//+kubebuilder:rbac:groups="*",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=infra.nephio.org,resources=bootstrapprofiles,verbs=get;list;watch
+//+kubebuilder:rbac:groups=infra.nephio.org,resources=bootstrapstatuses,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=infra.nephio.org,resources=bootstrapstatuses/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions,verbs=get;list;watch
+//+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisionresources,verbs=get
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *profileReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c any) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
+	cfg, ok := c.(*ctrlconfig.ControllerConfig)
+	if !ok {
+		return nil, fmt.Errorf("cannot initialize, expecting controllerConfig, got: %s", reflect.TypeOf(c).Name())
+	}
+
+	if err := porchv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
+
+	r.Client = mgr.GetClient()
+	r.porchClient = cfg.PorchClient
+
+	profile := resource.GetUnstructuredFromGVK(&BootstrapProfileGroupVersionKind)
+	return nil, ctrl.NewControllerManagedBy(mgr).
+		Named("BootstrapProfileController").
+		For(&corev1.Secret{}).
+		Watches(profile, handler.EnqueueRequestsFromMapFunc(r.clusterSecrets)).
+		Complete(sharding.NewReconciler(cfg.Shard, metrics.NewReconciler("BootstrapProfileController", r)))
+}
+
+// profileReconciler installs the packages of the bootstrap profiles on the workload clusters
+// of the kubeconfig secrets selected by the profiles
+type profileReconciler struct {
+	client.Client
+	porchClient client.Client
+
+	l logr.Logger
+}
+
+func (r *profileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.l = log.FromContext(ctx)
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, req.NamespacedName, secret); err != nil {
+		// if the resource no longer exists the reconcile loop is done
+		if resource.IgnoreNotFound(err) != nil {
+			msg := "cannot get resource"
+			r.l.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(resource.IgnoreNotFound(err), msg)
+		}
+		return reconcile.Result{}, nil
+	}
+	if resource.WasDeleted(secret) {
+		return reconcile.Result{}, nil
+	}
+	// only the kubeconfig secrets of the workload clusters are relevant
+	clusterClient, ok := cluster.Cluster{Client: r.Client}.GetClusterClient(secret)
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+	clusterName := clusterClient.GetClusterName()
+
+	profiles, err := r.getProfiles(ctx, secret)
+	if err != nil {
+		msg := "cannot get bootstrap profiles"
+		r.l.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	if len(profiles) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	cl, ready, err := clusterClient.GetClusterClient(ctx)
+	if err != nil {
+		msg := "cannot get clusterClient"
+		r.l.Error(err, msg)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
+	}
+	if !ready {
+		r.l.Info("cluster not ready", "cluster", clusterName)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	for _, profile := range profiles {
+		vars := profile.GetVariables(clusterName)
+		// the packages are installed in order
+		for _, pkg := range profile.Spec.Packages {
+			name := fmt.Sprintf("%s/%s/%s", profile.GetName(), pkg.Repository, pkg.PackageName)
+			resources, err := r.getProfilePackageResources(ctx, pkg, vars)
+			if err != nil {
+				msg := fmt.Sprintf("cannot get resources of package %s/%s of bootstrap profile %s", pkg.Repository, pkg.PackageName, profile.GetName())
+				r.l.Error(err, msg)
+				reportPackageStatus(ctx, r.l, r.Client, cl, secret, clusterName, name, nil, errors.Wrap(err, msg))
+				return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
+			}
+			for _, u := range resources {
+				u := u // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
+				r.l.Info("install manifest", "cluster", clusterName, "profile", profile.GetName(), "resource",
+					fmt.Sprintf("%s.%s.%s", u.GetAPIVersion(), u.GetKind(), u.GetName()))
+				if err := cl.Apply(ctx, &u); err != nil {
+					msg := fmt.Sprintf("cannot apply resource to cluster %s: resourceName: %s", clusterName, u.GetName())
+					r.l.Error(err, msg)
+					reportPackageStatus(ctx, r.l, r.Client, cl, secret, clusterName, name, resources, errors.Wrap(err, msg))
+					return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, msg)
+				}
+			}
+			// the next package is installed once the resources of the package are ready,
+			// e.g. the crds it installs are established
+			if !reportPackageStatus(ctx, r.l, r.Client, cl, secret, clusterName, name, resources, nil) {
+				r.l.Info("package not ready, retry...", "cluster", clusterName, "package", name)
+				return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+			}
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// getProfiles returns the bootstrap profiles selecting the workload cluster of the secret in name order
+func (r *profileReconciler) getProfiles(ctx context.Context, secret *corev1.Secret) ([]*BootstrapProfile, error) {
+	ul := &unstructured.UnstructuredList{}
+	ul.SetGroupVersionKind(BootstrapProfileGroupVersionKind.GroupVersion().WithKind(BootstrapProfileKind + "List"))
+	if err := r.List(ctx, ul); err != nil {
+		return nil, err
+	}
+	profiles := []*BootstrapProfile{}
+	for _, u := range ul.Items {
+		u := u // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
+		p, err := getBootstrapProfile(&u)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := p.Selects(secret)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			profiles = append(profiles, p)
+		}
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].GetName() < profiles[j].GetName()
+	})
+	return profiles, nil
+}
+
+// getProfilePackageResources returns the resources of the published revision of the package rendered with the variables
+func (r *profileReconciler) getProfilePackageResources(ctx context.Context, pkg BootstrapPackage, vars map[string]string) ([]unstructured.Unstructured, error) {
+	prl := &porchv1alpha1.PackageRevisionList{}
+	if err := r.porchClient.List(ctx, prl); err != nil {
+		return nil, err
+	}
+	var pr *porchv1alpha1.PackageRevision
+	for _, item := range prl.Items {
+		item := item // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
+		if item.Spec.RepositoryName != pkg.Repository || item.Spec.PackageName != pkg.PackageName ||
+			!porchv1alpha1.LifecycleIsPublished(item.Spec.Lifecycle) {
+			continue
+		}
+		if pkg.Revision == item.Spec.Revision ||
+			(pkg.Revision == "" && item.GetLabels()[porchv1alpha1.LatestPackageRevisionKey] == porchv1alpha1.LatestPackageRevisionValue) {
+			pr = &item
+			break
+		}
+	}
+	if pr == nil {
+		return nil, fmt.Errorf("no published revision %q of package %s in repository %s", pkg.Revision, pkg.PackageName, pkg.Repository)
+	}
+
+	prr := &porchv1alpha1.PackageRevisionResources{}
+	if err := r.porchClient.Get(ctx, types.NamespacedName{Namespace: pr.GetNamespace(), Name: pr.GetName()}, prr); err != nil {
+		return nil, err
+	}
+	resources, err := renderResources(prr.Spec.Resources, vars)
+	if err != nil {
+		return nil, err
+	}
+	ul, _, err := (&reconciler{l: r.l}).getResourcesPRR(resources)
+	return ul, err
+}
+
+// clusterSecrets returns the kubeconfig secrets of the workload clusters, such that a change of
+// a bootstrap profile is installed on the workload clusters
+func (r *profileReconciler) clusterSecrets(ctx context.Context, o client.Object) []reconcile.Request {
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets); err != nil {
+		log.FromContext(ctx).Error(err, "cannot list secrets")
+		return nil
+	}
+	reqs := []reconcile.Request{}
+	for _, secret := range secrets.Items {
+		secret := secret // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
+		if _, ok := (cluster.Cluster{Client: r.Client}).GetClusterClient(&secret); ok {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: secret.GetNamespace(), Name: secret.GetName()}})
+		}
+	}
+	return reqs
+}
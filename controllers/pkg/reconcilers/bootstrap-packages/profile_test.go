@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrappackages
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetVariables(t *testing.T) {
+	p := &BootstrapProfile{
+		Spec: BootstrapProfileSpec{
+			Variables: map[string]string{"region": "default", "proxy": "http://proxy:3128"},
+			Clusters: map[string]map[string]string{
+				"edge01": {"region": "us-east-1"},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		clusterName string
+		want        map[string]string
+	}{
+		"Defaults": {
+			clusterName: "edge02",
+			want:        map[string]string{"clusterName": "edge02", "region": "default", "proxy": "http://proxy:3128"},
+		},
+		"ClusterOverride": {
+			clusterName: "edge01",
+			want:        map[string]string{"clusterName": "edge01", "region": "us-east-1", "proxy": "http://proxy:3128"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := p.GetVariables(tc.clusterName)
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSelects(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "edge01-kubeconfig",
+			Labels: map[string]string{"nephio.org/region": "us-east"},
+		},
+	}
+
+	cases := map[string]struct {
+		selector *metav1.LabelSelector
+		want     bool
+	}{
+		"NoSelector": {
+			selector: nil,
+			want:     true,
+		},
+		"Match": {
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"nephio.org/region": "us-east"}},
+			want:     true,
+		},
+		"NoMatch": {
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"nephio.org/region": "eu-west"}},
+			want:     false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := &BootstrapProfile{Spec: BootstrapProfileSpec{ClusterSelector: tc.selector}}
+			got, err := p.Selects(secret)
+			assert.NoError(t, err)
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRenderResources(t *testing.T) {
+	cases := map[string]struct {
+		resources   map[string]string
+		want        map[string]string
+		expectedErr bool
+	}{
+		"Render": {
+			resources: map[string]string{
+				"a.md":    "{{ .unknown }}",
+				"cm.yaml": "metadata:\n  name: {{ .clusterName }}-{{ .region }}\n",
+			},
+			want: map[string]string{
+				"cm.yaml": "metadata:\n  name: edge01-us-east-1\n",
+			},
+		},
+		"MissingVariable": {
+			resources: map[string]string{
+				"cm.yaml": "metadata:\n  name: {{ .zone }}\n",
+			},
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := renderResources(tc.resources, map[string]string{"clusterName": "edge01", "region": "us-east-1"})
+
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -28,9 +28,11 @@ import (
 	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	"github.com/go-logr/logr"
 	"github.com/nephio-project/nephio/controllers/pkg/cluster"
+	"github.com/nephio-project/nephio/controllers/pkg/metrics"
 	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/nephio-project/nephio/controllers/pkg/sharding"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -48,6 +50,7 @@ import (
 
 func init() {
 	reconcilerinterface.Register("bootstrappackages", &reconciler{})
+	reconcilerinterface.Register("bootstrapprofiles", &profileReconciler{})
 }
 
 const (
@@ -59,9 +62,13 @@ const (
 //+kubebuilder:rbac:groups="*",resources=secrets,verbs=get;list;watch
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters/status,verbs=get
+//+kubebuilder:rbac:groups=fleet.azure.com,resources=memberclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gkehub.cnrm.cloud.google.com,resources=gkehubmemberships,verbs=get;list;watch
 //+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions,verbs=get;list;watch
 //+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions/status,verbs=get
 //+kubebuilder:rbac:groups=config.porch.kpt.dev,resources=repositories,verbs=get;list;watch
+//+kubebuilder:rbac:groups=infra.nephio.org,resources=bootstrapstatuses,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=infra.nephio.org,resources=bootstrapstatuses/status,verbs=get;update;patch
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c any) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
@@ -83,7 +90,7 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c a
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("BootstrapPackageController").
 		For(&porchv1alpha1.PackageRevision{}).
-		Complete(r)
+		Complete(sharding.NewReconciler(cfg.Shard, metrics.NewReconciler("BootstrapPackageController", r)))
 }
 
 type reconciler struct {
@@ -143,46 +150,51 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			}
 			found := false
 			for _, secret := range secrets.Items {
-				if strings.Contains(secret.GetName(), clusterName) {
-					secret := secret // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
-					clusterClient, ok := cluster.Cluster{Client: r.Client}.GetClusterClient(&secret)
-					if ok {
-						found = true
-						clusterClient, ready, err := clusterClient.GetClusterClient(ctx)
-						if err != nil {
-							msg := "cannot get clusterClient"
-							r.l.Error(err, msg)
-							return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
-						}
-						if !ready {
-							r.l.Info("cluster not ready")
-							return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
-						}
-						if !namespacePresent {
-							ns := &corev1.Namespace{}
-							if err = clusterClient.Get(ctx, types.NamespacedName{Name: configsyncNamespace}, ns); err != nil {
-								if resource.IgnoreNotFound(err) != nil {
-									msg := fmt.Sprintf("cannot get namespace: %s", configsyncNamespace)
-									r.l.Error(err, msg)
-									return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
-								}
-								msg := fmt.Sprintf("namespace: %s, does not exist, retry...", configsyncNamespace)
-								r.l.Info(msg)
-								return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
-							}
-						}
-						// install resources
-						for _, resource := range resources {
-							resource := resource // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
-							r.l.Info("install manifest", "resource",
-								fmt.Sprintf("%s.%s.%s", resource.GetAPIVersion(), resource.GetKind(), resource.GetName()))
-							if err := clusterClient.Apply(ctx, &resource); err != nil {
-								msg := fmt.Sprintf("cannot apply resource to cluster: resourceName: %s", resource.GetName())
+				secret := secret // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
+				clusterClient, ok := cluster.Cluster{Client: r.Client}.GetClusterClient(&secret)
+				// the cluster provider of the secret knows the name of the cluster
+				if ok && clusterClient.GetClusterName() == clusterName {
+					found = true
+					clusterClient, ready, err := clusterClient.GetClusterClient(ctx)
+					if err != nil {
+						msg := "cannot get clusterClient"
+						r.l.Error(err, msg)
+						return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
+					}
+					if !ready {
+						r.l.Info("cluster not ready")
+						return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+					}
+					if !namespacePresent {
+						ns := &corev1.Namespace{}
+						if err = clusterClient.Get(ctx, types.NamespacedName{Name: configsyncNamespace}, ns); err != nil {
+							if resource.IgnoreNotFound(err) != nil {
+								msg := fmt.Sprintf("cannot get namespace: %s", configsyncNamespace)
 								r.l.Error(err, msg)
-								return ctrl.Result{}, errors.Wrap(err, msg)
+								return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
 							}
+							msg := fmt.Sprintf("namespace: %s, does not exist, retry...", configsyncNamespace)
+							r.l.Info(msg)
+							return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+						}
+					}
+					// install resources
+					for _, resource := range resources {
+						resource := resource // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
+						r.l.Info("install manifest", "resource",
+							fmt.Sprintf("%s.%s.%s", resource.GetAPIVersion(), resource.GetKind(), resource.GetName()))
+						if err := clusterClient.Apply(ctx, &resource); err != nil {
+							msg := fmt.Sprintf("cannot apply resource to cluster: resourceName: %s", resource.GetName())
+							r.l.Error(err, msg)
+							reportPackageStatus(ctx, r.l, r.Client, clusterClient, &secret, clusterName, cr.GetName(), resources, errors.Wrap(err, msg))
+							return ctrl.Result{}, errors.Wrap(err, msg)
 						}
 					}
+					// verify the installed resources become ready
+					if !reportPackageStatus(ctx, r.l, r.Client, clusterClient, &secret, clusterName, cr.GetName(), resources, nil) {
+						r.l.Info("package not ready, retry...", "cluster", clusterName)
+						return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+					}
 				}
 			}
 			if !found {
@@ -0,0 +1,266 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrappackages
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// BootstrapStatusKind is the kind of the bootstrap status of a workload cluster, it is not part
+	// of the nephio api yet and is written as unstructured
+	BootstrapStatusKind = "BootstrapStatus"
+
+	ConditionTypeInstalled       = "Installed"
+	ConditionTypeCRDsEstablished = "CRDsEstablished"
+	ConditionTypeRootSyncsSynced = "RootSyncsSynced"
+	ConditionTypeReady           = "Ready"
+)
+
+var (
+	// BootstrapStatusGroupVersionKind is the GroupVersionKind of the bootstrap status of a workload cluster
+	BootstrapStatusGroupVersionKind = schema.GroupVersionKind{Group: "infra.nephio.org", Version: "v1alpha1", Kind: BootstrapStatusKind}
+
+	crdGroupKind      = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+	rootSyncGroupKind = schema.GroupKind{Group: "configsync.gke.io", Kind: "RootSync"}
+)
+
+// BootstrapStatus reports the bootstrap of the packages of a workload cluster, it is named after
+// the workload cluster in the namespace of its kubeconfig secret such that failed bootstraps are
+// visible from the management cluster
+type BootstrapStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Status            BootstrapStatusStatus `json:"status,omitempty"`
+}
+
+type BootstrapStatusStatus struct {
+	// Ready is true when all the packages are ready
+	Ready bool `json:"ready"`
+	// Conditions holds the Ready condition of the workload cluster
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Packages holds the status of the packages installed on the workload cluster
+	Packages []PackageStatus `json:"packages,omitempty"`
+}
+
+type PackageStatus struct {
+	// Name of the package, the package revision or the package of a bootstrap profile
+	Name string `json:"name"`
+	// Ready is true when all the conditions are true
+	Ready bool `json:"ready"`
+	// Conditions holds the Installed, CRDsEstablished and RootSyncsSynced conditions of the package
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// getPackageStatus verifies the resources of the package installed on the workload cluster are
+// ready: the CustomResourceDefinitions are established and the RootSyncs are synced
+func getPackageStatus(ctx context.Context, cl client.Reader, name string, resources []unstructured.Unstructured, installErr error) PackageStatus {
+	if installErr != nil {
+		return newPackageStatus(name, []metav1.Condition{
+			{Type: ConditionTypeInstalled, Status: metav1.ConditionFalse, Reason: "InstallFailed", Message: installErr.Error()},
+		})
+	}
+	crds := []string{}
+	rootSyncs := []string{}
+	pendingCRDs := []string{}
+	pendingRootSyncs := []string{}
+	for _, u := range resources {
+		gk := u.GroupVersionKind().GroupKind()
+		if gk != crdGroupKind && gk != rootSyncGroupKind {
+			continue
+		}
+		o := &unstructured.Unstructured{}
+		o.SetGroupVersionKind(u.GroupVersionKind())
+		ready := false
+		if err := cl.Get(ctx, types.NamespacedName{Namespace: u.GetNamespace(), Name: u.GetName()}, o); err == nil {
+			if gk == crdGroupKind {
+				ready = isCRDEstablished(o)
+			} else {
+				ready = isRootSyncSynced(o)
+			}
+		}
+		if gk == crdGroupKind {
+			crds = append(crds, u.GetName())
+			if !ready {
+				pendingCRDs = append(pendingCRDs, u.GetName())
+			}
+		} else {
+			rootSyncs = append(rootSyncs, u.GetName())
+			if !ready {
+				pendingRootSyncs = append(pendingRootSyncs, u.GetName())
+			}
+		}
+	}
+	return newPackageStatus(name, []metav1.Condition{
+		{Type: ConditionTypeInstalled, Status: metav1.ConditionTrue, Reason: ConditionTypeInstalled, Message: fmt.Sprintf("%d resources installed", len(resources))},
+		newCondition(ConditionTypeCRDsEstablished, "crds established", len(crds), pendingCRDs),
+		newCondition(ConditionTypeRootSyncsSynced, "rootsyncs synced", len(rootSyncs), pendingRootSyncs),
+	})
+}
+
+func newPackageStatus(name string, conditions []metav1.Condition) PackageStatus {
+	ready := true
+	for _, c := range conditions {
+		if c.Status != metav1.ConditionTrue {
+			ready = false
+		}
+	}
+	return PackageStatus{Name: name, Ready: ready, Conditions: conditions}
+}
+
+func newCondition(ct, resources string, total int, pending []string) metav1.Condition {
+	if len(pending) == 0 {
+		return metav1.Condition{Type: ct, Status: metav1.ConditionTrue, Reason: ct, Message: fmt.Sprintf("%d/%d %s", total, total, resources)}
+	}
+	sort.Strings(pending)
+	return metav1.Condition{
+		Type:    ct,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Pending",
+		Message: fmt.Sprintf("%d/%d %s, pending: %s", total-len(pending), total, resources, strings.Join(pending, ", ")),
+	}
+}
+
+func isCRDEstablished(u *unstructured.Unstructured) bool {
+	return hasCondition(u, "Established", metav1.ConditionTrue)
+}
+
+// isRootSyncSynced returns true when the RootSync is not syncing and synced the commit of its source
+func isRootSyncSynced(u *unstructured.Unstructured) bool {
+	sourceCommit, _, _ := unstructured.NestedString(u.Object, "status", "source", "commit")
+	syncCommit, _, _ := unstructured.NestedString(u.Object, "status", "sync", "commit")
+	return syncCommit != "" && syncCommit == sourceCommit && hasCondition(u, "Syncing", metav1.ConditionFalse)
+}
+
+func hasCondition(u *unstructured.Unstructured, ct string, status metav1.ConditionStatus) bool {
+	cs, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range cs {
+		c, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		if c["type"] == ct && c["status"] == string(status) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportPackageStatus verifies the package installed on the workload cluster and reports its status
+// in the bootstrap status of the workload cluster, it returns true when the package is ready; a
+// failure to report the status does not fail the bootstrap
+func reportPackageStatus(ctx context.Context, l logr.Logger, c client.Client, cl client.Reader, kubeconfig *corev1.Secret, clusterName, name string, resources []unstructured.Unstructured, installErr error) bool {
+	ps := getPackageStatus(ctx, cl, name, resources, installErr)
+	if err := setPackageStatus(ctx, c, kubeconfig, clusterName, ps); err != nil {
+		l.Error(err, "cannot update bootstrap status", "cluster", clusterName, "package", name)
+	}
+	return ps.Ready
+}
+
+// setPackageStatus updates the status of the package in the bootstrap status of the workload
+// cluster of the kubeconfig secret
+func setPackageStatus(ctx context.Context, c client.Client, kubeconfig *corev1.Secret, clusterName string, ps PackageStatus) error {
+	key := types.NamespacedName{Namespace: kubeconfig.GetNamespace(), Name: clusterName}
+	u := resource.GetUnstructuredFromGVK(&BootstrapStatusGroupVersionKind)
+	if err := c.Get(ctx, key, u); err != nil {
+		if resource.IgnoreNotFound(err) != nil {
+			return err
+		}
+		u.SetNamespace(key.Namespace)
+		u.SetName(key.Name)
+		if err := c.Create(ctx, u); err != nil {
+			return err
+		}
+	}
+	b, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	s := &BootstrapStatus{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return err
+	}
+
+	s.Status.setPackageStatus(ps)
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&s.Status)
+	if err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedMap(u.Object, status, "status"); err != nil {
+		return err
+	}
+	return c.Status().Update(ctx, u)
+}
+
+// setPackageStatus replaces the status of the package, keeping the transition times of the
+// unchanged conditions, and aggregates the Ready condition of the workload cluster
+func (r *BootstrapStatusStatus) setPackageStatus(ps PackageStatus) {
+	found := false
+	for i, existing := range r.Packages {
+		if existing.Name != ps.Name {
+			continue
+		}
+		found = true
+		conditions := existing.Conditions
+		for _, c := range ps.Conditions {
+			meta.SetStatusCondition(&conditions, c)
+		}
+		// drop the conditions no longer reported, e.g. after an install failure
+		kept := []metav1.Condition{}
+		for _, c := range conditions {
+			if meta.FindStatusCondition(ps.Conditions, c.Type) != nil {
+				kept = append(kept, c)
+			}
+		}
+		r.Packages[i] = PackageStatus{Name: ps.Name, Ready: ps.Ready, Conditions: kept}
+	}
+	if !found {
+		conditions := []metav1.Condition{}
+		for _, c := range ps.Conditions {
+			meta.SetStatusCondition(&conditions, c)
+		}
+		r.Packages = append(r.Packages, PackageStatus{Name: ps.Name, Ready: ps.Ready, Conditions: conditions})
+		sort.Slice(r.Packages, func(i, j int) bool {
+			return r.Packages[i].Name < r.Packages[j].Name
+		})
+	}
+
+	pending := []string{}
+	for _, p := range r.Packages {
+		if !p.Ready {
+			pending = append(pending, p.Name)
+		}
+	}
+	r.Ready = len(pending) == 0
+	c := newCondition(ConditionTypeReady, "packages ready", len(r.Packages), pending)
+	meta.SetStatusCondition(&r.Conditions, c)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrappackages
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestIsRootSyncSynced(t *testing.T) {
+	cases := map[string]struct {
+		status map[string]any
+		want   bool
+	}{
+		"NoStatus": {
+			status: nil,
+			want:   false,
+		},
+		"Synced": {
+			status: map[string]any{
+				"source":     map[string]any{"commit": "abc"},
+				"sync":       map[string]any{"commit": "abc"},
+				"conditions": []any{map[string]any{"type": "Syncing", "status": "False"}},
+			},
+			want: true,
+		},
+		"Syncing": {
+			status: map[string]any{
+				"source":     map[string]any{"commit": "abc"},
+				"sync":       map[string]any{"commit": "abc"},
+				"conditions": []any{map[string]any{"type": "Syncing", "status": "True"}},
+			},
+			want: false,
+		},
+		"BehindSource": {
+			status: map[string]any{
+				"source":     map[string]any{"commit": "def"},
+				"sync":       map[string]any{"commit": "abc"},
+				"conditions": []any{map[string]any{"type": "Syncing", "status": "False"}},
+			},
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			u := &unstructured.Unstructured{Object: map[string]any{}}
+			if tc.status != nil {
+				u.Object["status"] = tc.status
+			}
+			got := isRootSyncSynced(u)
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetPackageStatus(t *testing.T) {
+	s := &BootstrapStatusStatus{}
+
+	s.setPackageStatus(newPackageStatus("b", []metav1.Condition{
+		{Type: ConditionTypeInstalled, Status: metav1.ConditionFalse, Reason: "InstallFailed", Message: errors.New("failed").Error()},
+	}))
+	s.setPackageStatus(newPackageStatus("a", []metav1.Condition{
+		{Type: ConditionTypeInstalled, Status: metav1.ConditionTrue, Reason: ConditionTypeInstalled},
+		newCondition(ConditionTypeCRDsEstablished, "crds established", 1, nil),
+	}))
+	if diff := cmp.Diff([]string{"a", "b"}, []string{s.Packages[0].Name, s.Packages[1].Name}); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+	if s.Ready {
+		t.Errorf("want not ready, got ready")
+	}
+	if diff := cmp.Diff("1/2 packages ready, pending: b", s.Conditions[0].Message); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+
+	// the install failure is resolved
+	s.setPackageStatus(newPackageStatus("b", []metav1.Condition{
+		{Type: ConditionTypeInstalled, Status: metav1.ConditionTrue, Reason: ConditionTypeInstalled},
+		newCondition(ConditionTypeRootSyncsSynced, "rootsyncs synced", 0, nil),
+	}))
+	if !s.Ready {
+		t.Errorf("want ready, got not ready")
+	}
+	if diff := cmp.Diff(2, len(s.Packages[1].Conditions)); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
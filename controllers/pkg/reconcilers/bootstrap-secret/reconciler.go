@@ -19,13 +19,16 @@ package bootstrapsecret
 import (
 	"context"
 	"fmt"
-	"strings"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/nephio-project/nephio/controllers/pkg/cluster"
+	"github.com/nephio-project/nephio/controllers/pkg/metrics"
+	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/nephio-project/nephio/controllers/pkg/sharding"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -34,6 +37,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -44,7 +48,13 @@ func init() {
 
 const (
 	clusterNameKey      = "nephio.org/cluster-name"
+	clusterSelectorKey  = "nephio.org/cluster-selector"
+	targetNamespaceKey  = "nephio.org/target-namespace"
+	reencryptKey        = "nephio.org/reencrypt"
+	encryptionKey       = "nephio.org/encryption"
+	syncKey             = "nephio.org/sync"
 	nephioAppKey        = "nephio.org/app"
+	mgmtClusterName     = "mgmt"
 	configsyncApp       = "configsync"
 	bootstrapApp        = "bootstrap"
 	configsyncNamespace = "config-management-system"
@@ -53,15 +63,22 @@ const (
 //+kubebuilder:rbac:groups="*",resources=secrets,verbs=get;list;watch
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters/status,verbs=get
+//+kubebuilder:rbac:groups=fleet.azure.com,resources=memberclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gkehub.cnrm.cloud.google.com,resources=gkehubmemberships,verbs=get;list;watch
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c any) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
+	cfg, ok := c.(*ctrlconfig.ControllerConfig)
+	if !ok {
+		return nil, fmt.Errorf("cannot initialize, expecting controllerConfig, got: %s", reflect.TypeOf(c).Name())
+	}
 	r.Client = mgr.GetClient()
 
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("BootstrapSecretController").
 		For(&corev1.Secret{}).
-		Complete(r)
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.syncedSecrets)).
+		Complete(sharding.NewReconciler(cfg.Shard, metrics.NewReconciler("BootstrapSecretController", r)))
 }
 
 type reconciler struct {
@@ -89,76 +106,125 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return reconcile.Result{}, nil
 	}
 
-	// this branch handles installing the secrets to the remote cluster
-	// the secret is relevant to be installed in the workload cluster if:
-	// annotation key "nephio.org/app" == configsync
+	// this branch handles installing the secrets to the remote clusters
+	// the secret is relevant to be installed in the workload clusters if either:
+	// annotation key "nephio.org/app" == configsync, the config-sync git credentials
+	// label key "nephio.org/sync" == true
+	// and it targets the workload clusters with either:
 	// annotation key "nephio.org/cluster-name" different then "" and different then management
-	if cr.GetAnnotations()[nephioAppKey] == configsyncApp &&
-		cr.GetAnnotations()[clusterNameKey] != "" &&
-		cr.GetAnnotations()[clusterNameKey] != "mgmt" {
-		r.l.Info("reconcile secret")
-		clusterName := cr.GetAnnotations()[clusterNameKey]
-
-		secrets := &corev1.SecretList{}
-		if err := r.List(ctx, secrets); err != nil {
-			msg := "cannot list secrets"
+	// annotation key "nephio.org/cluster-selector" selecting the kubeconfig secrets of the clusters
+	target, err := getSyncTarget(cr)
+	if err != nil {
+		msg := "invalid sync target"
+		r.l.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	if target == nil {
+		return ctrl.Result{}, nil
+	}
+	r.l.Info("reconcile secret")
+
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets); err != nil {
+		msg := "cannot list secrets"
+		r.l.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	found := false
+	result := ctrl.Result{}
+	for _, secret := range secrets.Items {
+		secret := secret // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
+		clusterClient, ok := cluster.Cluster{Client: r.Client}.GetClusterClient(&secret)
+		// the cluster provider of the secret knows the name of the cluster
+		if !ok || !target.selects(clusterClient.GetClusterName(), &secret) {
+			continue
+		}
+		found = true
+		clusterName := clusterClient.GetClusterName()
+		cl, ready, err := clusterClient.GetClusterClient(ctx)
+		if err != nil {
+			msg := "cannot get clusterClient"
 			r.l.Error(err, msg)
-			return ctrl.Result{}, errors.Wrap(err, msg)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
+		}
+		if !ready {
+			// the other clusters are synced meanwhile
+			r.l.Info("cluster not ready", "cluster", clusterName)
+			result = ctrl.Result{RequeueAfter: 10 * time.Second}
+			continue
+		}
+		// check if namespace exists, if not retry
+		ns := &corev1.Namespace{}
+		if err = cl.Get(ctx, types.NamespacedName{Name: target.namespace}, ns); err != nil {
+			if resource.IgnoreNotFound(err) != nil {
+				msg := fmt.Sprintf("cannot get namespace: %s", target.namespace)
+				r.l.Error(err, msg)
+				return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
+			}
+			msg := fmt.Sprintf("namespace: %s, does not exist, retry...", target.namespace)
+			r.l.Info(msg, "cluster", clusterName)
+			result = ctrl.Result{RequeueAfter: 10 * time.Second}
+			continue
 		}
-		found := false
-		for _, secret := range secrets.Items {
-			if strings.Contains(secret.GetName(), clusterName) {
-				secret := secret // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
-				clusterClient, ok := cluster.Cluster{Client: r.Client}.GetClusterClient(&secret)
-				if ok {
-					found = true
-					clusterClient, ready, err := clusterClient.GetClusterClient(ctx)
-					if err != nil {
-						msg := "cannot get clusterClient"
-						r.l.Error(err, msg)
-						return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
-					}
-					if !ready {
-						r.l.Info("cluster not ready")
-						return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
-					}
-					// check if namespace exists, if not retry
-					ns := &corev1.Namespace{}
-					if err = clusterClient.Get(ctx, types.NamespacedName{Name: configsyncNamespace}, ns); err != nil {
-						if resource.IgnoreNotFound(err) != nil {
-							msg := fmt.Sprintf("cannot get namespace: %s", configsyncNamespace)
-							r.l.Error(err, msg)
-							return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
-						}
-						msg := fmt.Sprintf("namespace: %s, does not exist, retry...", configsyncNamespace)
-						r.l.Info(msg)
-						return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
-					}
-
-					newcr := cr.DeepCopy()
-					newcr.Namespace = configsyncNamespace
-					// since the original annotations are set by configsync we need to reset them
-					// so apply 2 annotations to the secret: app = bootstrap +  cluster-name = clusterName
-					newcr.SetAnnotations(map[string]string{
-						nephioAppKey:   bootstrapApp,
-						clusterNameKey: clusterName,
-					})
-					newcr.ResourceVersion = ""
-					newcr.UID = ""
-					r.l.Info("secret info", "secret", newcr.Annotations)
-					if err := clusterClient.Apply(ctx, newcr); err != nil {
-						msg := fmt.Sprintf("cannot apply secret to cluster %s", clusterName)
-						r.l.Error(err, msg)
-						return ctrl.Result{}, errors.Wrap(err, msg)
-					}
-				}
+
+		existing := &corev1.Secret{}
+		if err := cl.Get(ctx, types.NamespacedName{Namespace: target.namespace, Name: cr.GetName()}, existing); err != nil {
+			if resource.IgnoreNotFound(err) != nil {
+				msg := fmt.Sprintf("cannot get secret of cluster %s", clusterName)
+				r.l.Error(err, msg)
+				return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
 			}
+			existing = nil
+		}
+		newcr, err := r.getSyncedSecret(ctx, cr, target, clusterName, existing)
+		if err != nil {
+			msg := fmt.Sprintf("cannot build secret for cluster %s", clusterName)
+			r.l.Error(err, msg)
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, msg)
+		}
+		// the secret is only applied when its data changed, a reencrypted secret keeps its ciphertext
+		if isSynced(existing, newcr) {
+			continue
+		}
+		r.l.Info("secret info", "secret", newcr.Annotations)
+		if err := cl.Apply(ctx, newcr); err != nil {
+			msg := fmt.Sprintf("cannot apply secret to cluster %s", clusterName)
+			r.l.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
 		}
-		if !found {
-			// the cluster client was not found, we retry
-			r.l.Info("cluster client not found, retry...")
-			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	if !found && target.clusterName != "" {
+		// the cluster client was not found, we retry
+		r.l.Info("cluster client not found, retry...")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	return result, nil
+}
+
+// syncedSecrets returns the secrets synced to the workload cluster of a kubeconfig secret, such that
+// the secrets are synced when a cluster is added or its labels change
+func (r *reconciler) syncedSecrets(ctx context.Context, o client.Object) []reconcile.Request {
+	kubeconfig, ok := o.(*corev1.Secret)
+	if !ok {
+		return nil
+	}
+	clusterClient, ok := cluster.Cluster{Client: r.Client}.GetClusterClient(kubeconfig)
+	if !ok {
+		return nil
+	}
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets); err != nil {
+		log.FromContext(ctx).Error(err, "cannot list secrets")
+		return nil
+	}
+	reqs := []reconcile.Request{}
+	for _, secret := range secrets.Items {
+		secret := secret // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
+		target, err := getSyncTarget(&secret)
+		if err != nil || target == nil || !target.selects(clusterClient.GetClusterName(), kubeconfig) {
+			continue
 		}
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: secret.GetNamespace(), Name: secret.GetName()}})
 	}
-	return ctrl.Result{}, nil
+	return reqs
 }
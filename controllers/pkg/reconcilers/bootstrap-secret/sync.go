@@ -0,0 +1,225 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapsecret
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// encryptionKeySuffix suffixes the name of the cluster to find the secret holding the
+	// encryption key of the cluster, in the namespace of the synced secret
+	encryptionKeySuffix = "-encryption-key"
+	// encryptionKeyDataKey is the data key of the encryption key, a 32 bytes AES-256 key
+	encryptionKeyDataKey = "key"
+	aes256gcm            = "aes-256-gcm"
+)
+
+// syncTarget defines the workload clusters and the namespace a secret is synced to
+type syncTarget struct {
+	// clusterName is the name of the target cluster, or empty when the clusters are selected
+	clusterName string
+	// selector selects the target clusters by the labels of their kubeconfig secret
+	selector labels.Selector
+	// namespace is the namespace of the secret in the workload clusters
+	namespace string
+	// reencrypt defines if the data of the secret is encrypted with the key of the workload cluster
+	reencrypt bool
+}
+
+// getSyncTarget returns the sync target of the secret, or nil when the secret is not synced
+func getSyncTarget(cr *corev1.Secret) (*syncTarget, error) {
+	annotations := cr.GetAnnotations()
+	clusterName := annotations[clusterNameKey]
+	if clusterName == mgmtClusterName {
+		return nil, nil
+	}
+
+	// the config-sync git credentials are installed in the config-sync namespace
+	if annotations[nephioAppKey] == configsyncApp {
+		if clusterName == "" {
+			return nil, nil
+		}
+		return &syncTarget{clusterName: clusterName, namespace: configsyncNamespace}, nil
+	}
+
+	if cr.GetLabels()[syncKey] != "true" {
+		return nil, nil
+	}
+	t := &syncTarget{
+		clusterName: clusterName,
+		namespace:   cr.GetNamespace(),
+		reencrypt:   annotations[reencryptKey] == "true",
+	}
+	if ns, ok := annotations[targetNamespaceKey]; ok && ns != "" {
+		t.namespace = ns
+	}
+	if clusterName == "" {
+		s, ok := annotations[clusterSelectorKey]
+		if !ok {
+			return nil, nil
+		}
+		selector, err := labels.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cluster selector %s: %s", s, err.Error())
+		}
+		t.selector = selector
+	}
+	if t.namespace == "" {
+		t.namespace = corev1.NamespaceDefault
+	}
+	return t, nil
+}
+
+// selects returns true when the workload cluster of the kubeconfig secret is targeted
+func (r *syncTarget) selects(clusterName string, kubeconfig *corev1.Secret) bool {
+	if r.clusterName != "" {
+		return r.clusterName == clusterName
+	}
+	return clusterName != mgmtClusterName && r.selector.Matches(labels.Set(kubeconfig.GetLabels()))
+}
+
+// getSyncedSecret returns the secret installed in the workload cluster, the existing secret of the
+// workload cluster keeps its ciphertext when it decrypts to the same data
+func (r *reconciler) getSyncedSecret(ctx context.Context, cr *corev1.Secret, target *syncTarget, clusterName string, existing *corev1.Secret) (*corev1.Secret, error) {
+	newcr := cr.DeepCopy()
+	newcr.Namespace = target.namespace
+	// since the original annotations are set by configsync we need to reset them
+	// so apply 2 annotations to the secret: app = bootstrap +  cluster-name = clusterName
+	annotations := map[string]string{
+		nephioAppKey:   bootstrapApp,
+		clusterNameKey: clusterName,
+	}
+	newcr.ResourceVersion = ""
+	newcr.UID = ""
+	newcr.OwnerReferences = nil
+	newcr.ManagedFields = nil
+
+	if target.reencrypt {
+		key, err := r.getEncryptionKey(ctx, cr.GetNamespace(), clusterName)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range newcr.Data {
+			if newcr.Data[k], err = encryptOnChange(key, v, existing, k); err != nil {
+				return nil, err
+			}
+		}
+		// string data is merged into data by the api server
+		for k, v := range newcr.StringData {
+			if newcr.Data == nil {
+				newcr.Data = map[string][]byte{}
+			}
+			if newcr.Data[k], err = encryptOnChange(key, []byte(v), existing, k); err != nil {
+				return nil, err
+			}
+		}
+		newcr.StringData = nil
+		annotations[encryptionKey] = aes256gcm
+	}
+	newcr.SetAnnotations(annotations)
+	return newcr, nil
+}
+
+// getEncryptionKey returns the encryption key of the workload cluster
+func (r *reconciler) getEncryptionKey(ctx context.Context, namespace, clusterName string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: clusterName + encryptionKeySuffix}, secret); err != nil {
+		return nil, err
+	}
+	key, ok := secret.Data[encryptionKeyDataKey]
+	if !ok || len(key) != 32 {
+		return nil, fmt.Errorf("secret %s%s requires a 32 bytes %s", clusterName, encryptionKeySuffix, encryptionKeyDataKey)
+	}
+	return key, nil
+}
+
+// isSynced returns true when the existing secret of the workload cluster holds the synced secret,
+// such that it is not applied again
+func isSynced(existing, newcr *corev1.Secret) bool {
+	if existing == nil || existing.Type != newcr.Type || !reflect.DeepEqual(existing.Data, newcr.Data) || len(newcr.StringData) != 0 {
+		return false
+	}
+	for k, v := range newcr.GetAnnotations() {
+		if existing.GetAnnotations()[k] != v {
+			return false
+		}
+	}
+	for k, v := range newcr.GetLabels() {
+		if existing.GetLabels()[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// encryptOnChange returns the ciphertext of the key of the existing secret when it decrypts to the
+// data, otherwise the data is encrypted with a new nonce
+func encryptOnChange(key, data []byte, existing *corev1.Secret, k string) ([]byte, error) {
+	if existing != nil {
+		if ciphertext, ok := existing.Data[k]; ok {
+			if plain, err := decrypt(key, ciphertext); err == nil && bytes.Equal(plain, data) {
+				return ciphertext, nil
+			}
+		}
+	}
+	return encrypt(key, data)
+}
+
+// encrypt returns the nonce followed by the AES-GCM ciphertext of the data
+func encrypt(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// decrypt returns the data of the nonce and AES-GCM ciphertext returned by encrypt
+func decrypt(key, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext shorter than the nonce")
+	}
+	return gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], nil)
+}
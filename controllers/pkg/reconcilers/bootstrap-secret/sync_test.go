@@ -0,0 +1,240 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapsecret
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestGetSyncTarget(t *testing.T) {
+	cases := map[string]struct {
+		secret        *corev1.Secret
+		want          bool
+		wantCluster   string
+		wantNamespace string
+		expectedErr   bool
+	}{
+		"NotSynced": {
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}},
+			want:   false,
+		},
+		"ConfigSync": {
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default",
+				Annotations: map[string]string{nephioAppKey: configsyncApp, clusterNameKey: "edge01"}}},
+			want:          true,
+			wantCluster:   "edge01",
+			wantNamespace: configsyncNamespace,
+		},
+		"ConfigSyncMgmt": {
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default",
+				Annotations: map[string]string{nephioAppKey: configsyncApp, clusterNameKey: mgmtClusterName}}},
+			want: false,
+		},
+		"SyncLabelSourceNamespace": {
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "app",
+				Labels:      map[string]string{syncKey: "true"},
+				Annotations: map[string]string{clusterNameKey: "edge01"}}},
+			want:          true,
+			wantCluster:   "edge01",
+			wantNamespace: "app",
+		},
+		"SyncLabelTargetNamespace": {
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "app",
+				Labels:      map[string]string{syncKey: "true"},
+				Annotations: map[string]string{clusterSelectorKey: "nephio.org/site-type=edge", targetNamespaceKey: "monitoring"}}},
+			want:          true,
+			wantNamespace: "monitoring",
+		},
+		"SyncLabelNoTarget": {
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "app",
+				Labels: map[string]string{syncKey: "true"}}},
+			want: false,
+		},
+		"InvalidSelector": {
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "app",
+				Labels:      map[string]string{syncKey: "true"},
+				Annotations: map[string]string{clusterSelectorKey: "a=(b"}}},
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := getSyncTarget(tc.secret)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tc.want, got != nil); diff != "" {
+				t.Fatalf("-want, +got:\n%s", diff)
+			}
+			if got == nil {
+				return
+			}
+			if diff := cmp.Diff(tc.wantCluster, got.clusterName); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantNamespace, got.namespace); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSelects(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default",
+			Labels:      map[string]string{syncKey: "true"},
+			Annotations: map[string]string{clusterSelectorKey: "nephio.org/site-type=edge"}},
+	}
+	target, err := getSyncTarget(secret)
+	assert.NoError(t, err)
+
+	edge := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"nephio.org/site-type": "edge"}}}
+	core := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"nephio.org/site-type": "core"}}}
+	assert.True(t, target.selects("edge01", edge))
+	assert.False(t, target.selects("core01", core))
+	assert.False(t, target.selects(mgmtClusterName, edge))
+}
+
+func TestEncrypt(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	data := []byte("git-token")
+
+	got, err := encrypt(key, data)
+	assert.NoError(t, err)
+
+	block, err := aes.NewCipher(key)
+	assert.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	assert.NoError(t, err)
+	plain, err := gcm.Open(nil, got[:gcm.NonceSize()], got[gcm.NonceSize():], nil)
+	assert.NoError(t, err)
+	if diff := cmp.Diff(data, plain); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
+
+func TestEncryptOnChange(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	ciphertext, err := encrypt(key, []byte("git-token"))
+	assert.NoError(t, err)
+	existing := &corev1.Secret{Data: map[string][]byte{"token": ciphertext}}
+
+	cases := map[string]struct {
+		data     string
+		existing *corev1.Secret
+		want     bool
+	}{
+		"Unchanged": {data: "git-token", existing: existing, want: true},
+		"Changed":   {data: "new-token", existing: existing, want: false},
+		"NoSecret":  {data: "git-token", existing: nil, want: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := encryptOnChange(key, []byte(tc.data), tc.existing, "token")
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tc.want, cmp.Equal(ciphertext, got)); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+			plain, err := decrypt(key, got)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tc.data, string(plain)); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsSynced(t *testing.T) {
+	newcr := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Annotations: map[string]string{nephioAppKey: bootstrapApp}},
+		Data:       map[string][]byte{"token": []byte("a")},
+	}
+	cases := map[string]struct {
+		existing *corev1.Secret
+		want     bool
+	}{
+		"NoSecret": {existing: nil, want: false},
+		"Synced": {existing: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "a", ResourceVersion: "1", Annotations: map[string]string{nephioAppKey: bootstrapApp, "x": "y"}},
+			Data:       map[string][]byte{"token": []byte("a")},
+		}, want: true},
+		"DataChanged": {existing: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "a", Annotations: map[string]string{nephioAppKey: bootstrapApp}},
+			Data:       map[string][]byte{"token": []byte("b")},
+		}, want: false},
+		"AnnotationChanged": {existing: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "a"},
+			Data:       map[string][]byte{"token": []byte("a")},
+		}, want: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, isSynced(tc.existing, newcr)); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSyncedSecrets(t *testing.T) {
+	kubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge01-kubeconfig", Namespace: "default", Labels: map[string]string{"nephio.org/site-type": "edge"}},
+		Type:       "cluster.x-k8s.io/secret",
+	}
+	c := fake.NewClientBuilder().WithObjects(
+		kubeconfig,
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "selected", Namespace: "app",
+			Labels:      map[string]string{syncKey: "true"},
+			Annotations: map[string]string{clusterSelectorKey: "nephio.org/site-type=edge"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "named", Namespace: "app",
+			Labels:      map[string]string{syncKey: "true"},
+			Annotations: map[string]string{clusterNameKey: "edge01"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "app",
+			Labels:      map[string]string{syncKey: "true"},
+			Annotations: map[string]string{clusterSelectorKey: "nephio.org/site-type=core"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unsynced", Namespace: "app"}},
+	).Build()
+	r := &reconciler{Client: c}
+
+	want := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "app", Name: "named"}},
+		{NamespacedName: types.NamespacedName{Namespace: "app", Name: "selected"}},
+	}
+	if diff := cmp.Diff(want, r.syncedSecrets(context.Background(), kubeconfig)); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+	// a secret that is not a kubeconfig secret enqueues nothing
+	if diff := cmp.Diff(0, len(r.syncedSecrets(context.Background(), &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "a"}}))); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
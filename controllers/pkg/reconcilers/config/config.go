@@ -19,6 +19,8 @@ package ctrlrconfig
 import (
 	"time"
 
+	"github.com/nephio-project/nephio/controllers/pkg/catalog"
+	"github.com/nephio-project/nephio/controllers/pkg/sharding"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
 	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
 	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy"
@@ -35,4 +37,6 @@ type ControllerConfig struct {
 	Address         string // backend server address
 	IpamClientProxy clientproxy.Proxy[*ipamv1alpha1.NetworkInstance, *ipamv1alpha1.IPClaim]
 	VlanClientProxy clientproxy.Proxy[*vlanv1alpha1.VLANIndex, *vlanv1alpha1.VLANClaim]
+	Shard           sharding.Shard   // the requests reconciled by this replica
+	Catalog         *catalog.Catalog // the index of the blueprint packages
 }
@@ -0,0 +1,200 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package driftdetection
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/nephio-project/nephio/krm-functions/lib/kptrl"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+)
+
+const (
+	DriftReasonMissing  = "Missing"
+	DriftReasonModified = "Modified"
+)
+
+// ignoredAnnotationPrefixes are the prefixes of the annotations kpt adds to the resources of a
+// package, they are not deployed to the workload cluster
+var ignoredAnnotationPrefixes = []string{
+	"config.kubernetes.io/",
+	"internal.config.kubernetes.io/",
+	"config.k8s.io/",
+}
+
+// DriftedResource is a resource of the package that differs on the workload cluster
+type DriftedResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// Reason is Missing when the resource is not on the workload cluster, Modified when its fields differ
+	Reason string `json:"reason"`
+	// Fields are the paths of the fields that differ
+	Fields []string `json:"fields,omitempty"`
+}
+
+func (r DriftedResource) String() string {
+	name := r.Name
+	if r.Namespace != "" {
+		name = fmt.Sprintf("%s/%s", r.Namespace, r.Name)
+	}
+	return fmt.Sprintf("%s %s", r.Kind, name)
+}
+
+// getDesiredResources returns the resources of the package deployed to the workload cluster, the
+// Kptfile and the local config are left out
+func getDesiredResources(resources map[string]string) ([]unstructured.Unstructured, error) {
+	rl, err := kptrl.GetResourceList(resources)
+	if err != nil {
+		return nil, err
+	}
+	ul := []unstructured.Unstructured{}
+	for _, o := range rl.Items {
+		if o.GetAnnotation(filters.LocalConfigAnnotation) == "true" || o.GetKind() == "Kptfile" {
+			continue
+		}
+		u := unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(o.String()), &u); err != nil {
+			return nil, err
+		}
+		annotations := map[string]string{}
+		for k, v := range u.GetAnnotations() {
+			if !hasIgnoredPrefix(k) {
+				annotations[k] = v
+			}
+		}
+		if len(annotations) == 0 {
+			unstructured.RemoveNestedField(u.Object, "metadata", "annotations")
+		} else {
+			u.SetAnnotations(annotations)
+		}
+		ul = append(ul, u)
+	}
+	return ul, nil
+}
+
+func hasIgnoredPrefix(k string) bool {
+	for _, p := range ignoredAnnotationPrefixes {
+		if strings.HasPrefix(k, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// getDriftedResources compares the desired resources with the live resources of the workload
+// cluster, namespaced resources without a namespace are deployed in the default namespace
+func getDriftedResources(ctx context.Context, cl client.Client, desired []unstructured.Unstructured) ([]DriftedResource, error) {
+	drifted := []DriftedResource{}
+	for i := range desired {
+		d := desired[i].DeepCopy()
+		if d.GetNamespace() == "" {
+			namespaced, err := cl.IsObjectNamespaced(d)
+			if err != nil {
+				return nil, err
+			}
+			if namespaced {
+				d.SetNamespace("default")
+			}
+		}
+		dr := DriftedResource{APIVersion: d.GetAPIVersion(), Kind: d.GetKind(), Namespace: d.GetNamespace(), Name: d.GetName()}
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(d.GroupVersionKind())
+		if err := cl.Get(ctx, types.NamespacedName{Namespace: d.GetNamespace(), Name: d.GetName()}, live); err != nil {
+			if resource.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+			dr.Reason = DriftReasonMissing
+			drifted = append(drifted, dr)
+			continue
+		}
+		if fields := getDriftedFields(d.Object, live.Object); len(fields) > 0 {
+			dr.Reason = DriftReasonModified
+			dr.Fields = fields
+			drifted = append(drifted, dr)
+		}
+	}
+	sort.Slice(drifted, func(i, j int) bool {
+		return drifted[i].String() < drifted[j].String()
+	})
+	return drifted, nil
+}
+
+// getDriftedFields returns the paths of the fields of the desired resource that differ on the live
+// resource; the fields only set on the live resource, e.g. defaulted by the api server, and the
+// status are not drift
+func getDriftedFields(desired, live map[string]any) []string {
+	fields := []string{}
+	for k, v := range desired {
+		if k == "status" {
+			continue
+		}
+		fields = append(fields, diff(k, v, live[k])...)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func diff(path string, desired, live any) []string {
+	switch d := desired.(type) {
+	case map[string]any:
+		l, ok := live.(map[string]any)
+		if !ok {
+			return []string{path}
+		}
+		fields := []string{}
+		for k, v := range d {
+			fields = append(fields, diff(fmt.Sprintf("%s.%s", path, k), v, l[k])...)
+		}
+		return fields
+	case []any:
+		l, ok := live.([]any)
+		if !ok || len(l) != len(d) {
+			return []string{path}
+		}
+		fields := []string{}
+		for i := range d {
+			fields = append(fields, diff(fmt.Sprintf("%s[%d]", path, i), d[i], l[i])...)
+		}
+		return fields
+	case nil:
+		return nil
+	default:
+		if live == nil || !equalScalar(d, live) {
+			return []string{path}
+		}
+		return nil
+	}
+}
+
+// equalScalar compares the scalars of the package and of the api server, which decode the
+// numbers in different types
+func equalScalar(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driftdetection
+
+import (
+	"testing"
+	"time"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const kptfile = `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: upf
+`
+
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: upf
+  namespace: upf
+  annotations:
+    config.kubernetes.io/index: "0"
+    nephio.org/owner: upf
+spec:
+  replicas: 1
+`
+
+const localConfig = `apiVersion: req.nephio.org/v1alpha1
+kind: Interface
+metadata:
+  name: n3
+  annotations:
+    config.kubernetes.io/local-config: "true"
+`
+
+func TestGetDesiredResources(t *testing.T) {
+	cases := map[string]struct {
+		resources map[string]string
+		want      []string
+	}{
+		"Empty": {
+			resources: map[string]string{"Kptfile": kptfile},
+			want:      []string{},
+		},
+		"Resources": {
+			resources: map[string]string{"Kptfile": kptfile, "deployment.yaml": deployment, "n3.yaml": localConfig},
+			want:      []string{"Deployment upf/upf"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ul, err := getDesiredResources(tc.resources)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			got := []string{}
+			for _, u := range ul {
+				got = append(got, DriftedResource{Kind: u.GetKind(), Namespace: u.GetNamespace(), Name: u.GetName()}.String())
+				// only the annotations deployed to the cluster are compared
+				if diff := cmp.Diff(map[string]string{"nephio.org/owner": "upf"}, u.GetAnnotations()); diff != "" {
+					t.Errorf("annotations -want, +got:\n%s", diff)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetDriftedFields(t *testing.T) {
+	desired := map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]any{"name": "upf", "labels": map[string]any{"app": "upf"}},
+		"spec": map[string]any{
+			"replicas": int64(1),
+			"template": map[string]any{"spec": map[string]any{"containers": []any{
+				map[string]any{"name": "upf", "image": "upf:v1"},
+			}}},
+		},
+		"status": map[string]any{"replicas": int64(1)},
+	}
+	cases := map[string]struct {
+		live map[string]any
+		want []string
+	}{
+		"InSync": {
+			live: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]any{"name": "upf", "uid": "1234", "labels": map[string]any{"app": "upf"}},
+				"spec": map[string]any{
+					"replicas": float64(1),
+					"template": map[string]any{"spec": map[string]any{"containers": []any{
+						map[string]any{"name": "upf", "image": "upf:v1", "imagePullPolicy": "IfNotPresent"},
+					}}},
+				},
+				"status": map[string]any{"replicas": int64(0)},
+			},
+			want: []string{},
+		},
+		"Modified": {
+			live: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]any{"name": "upf"},
+				"spec": map[string]any{
+					"replicas": int64(3),
+					"template": map[string]any{"spec": map[string]any{"containers": []any{
+						map[string]any{"name": "upf", "image": "upf:v2"},
+					}}},
+				},
+			},
+			want: []string{
+				"metadata.labels",
+				"spec.replicas",
+				"spec.template.spec.containers[0].image",
+			},
+		},
+		"ListLength": {
+			live: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]any{"name": "upf", "labels": map[string]any{"app": "upf"}},
+				"spec": map[string]any{
+					"replicas": int64(1),
+					"template": map[string]any{"spec": map[string]any{"containers": []any{
+						map[string]any{"name": "upf", "image": "upf:v1"},
+						map[string]any{"name": "debug", "image": "busybox"},
+					}}},
+				},
+			},
+			want: []string{"spec.template.spec.containers"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getDriftedFields(desired, tc.live)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewSyncedCondition(t *testing.T) {
+	cases := map[string]struct {
+		drifted []DriftedResource
+		want    metav1.Condition
+	}{
+		"InSync": {
+			want: metav1.Condition{Type: ConditionTypeSynced, Status: metav1.ConditionTrue, Reason: ConditionTypeSynced, Message: "3/3 resources in sync"},
+		},
+		"Drifted": {
+			drifted: []DriftedResource{
+				{Kind: "ConfigMap", Namespace: "upf", Name: "upf-config", Reason: DriftReasonModified},
+				{Kind: "Namespace", Name: "upf", Reason: DriftReasonMissing},
+			},
+			want: metav1.Condition{
+				Type:    ConditionTypeSynced,
+				Status:  metav1.ConditionFalse,
+				Reason:  "Drifted",
+				Message: "1/3 resources in sync, drifted: ConfigMap upf/upf-config (modified), Namespace upf (missing)",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := newSyncedCondition(3, tc.drifted)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetInterval(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		want        time.Duration
+	}{
+		"Default": {
+			want: defaultInterval,
+		},
+		"Interval": {
+			annotations: map[string]string{intervalKey: "10m"},
+			want:        10 * time.Minute,
+		},
+		"Invalid": {
+			annotations: map[string]string{intervalKey: "often"},
+			want:        defaultInterval,
+		},
+		"Minimum": {
+			annotations: map[string]string{intervalKey: "1s"},
+			want:        minInterval,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			repo := &porchconfigv1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Name: "edge01", Annotations: tc.annotations}}
+			if diff := cmp.Diff(tc.want, getInterval(repo)); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetPendingRevision(t *testing.T) {
+	published := porchv1alpha1.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge01-upf-v1"},
+		Spec:       porchv1alpha1.PackageRevisionSpec{RepositoryName: "edge01", PackageName: "upf", Revision: "v1", Lifecycle: porchv1alpha1.PackageRevisionLifecyclePublished},
+	}
+	cases := map[string]struct {
+		prs  []porchv1alpha1.PackageRevision
+		want string
+	}{
+		"NoPending": {
+			prs: []porchv1alpha1.PackageRevision{published},
+		},
+		"Draft": {
+			prs: []porchv1alpha1.PackageRevision{published, {
+				ObjectMeta: metav1.ObjectMeta{Name: "edge01-upf-draft"},
+				Spec:       porchv1alpha1.PackageRevisionSpec{RepositoryName: "edge01", PackageName: "upf", Lifecycle: porchv1alpha1.PackageRevisionLifecycleDraft},
+			}},
+			want: "edge01-upf-draft",
+		},
+		"OtherPackage": {
+			prs: []porchv1alpha1.PackageRevision{published, {
+				ObjectMeta: metav1.ObjectMeta{Name: "edge01-smf-draft"},
+				Spec:       porchv1alpha1.PackageRevisionSpec{RepositoryName: "edge01", PackageName: "smf", Lifecycle: porchv1alpha1.PackageRevisionLifecycleProposed},
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ""
+			if pr := getPendingRevision(tc.prs, &published); pr != nil {
+				got = pr.GetName()
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetRemediationRevision(t *testing.T) {
+	cr := &porchv1alpha1.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge01-upf-v1", Namespace: "default"},
+		Spec:       porchv1alpha1.PackageRevisionSpec{RepositoryName: "edge01", PackageName: "upf", Revision: "v1", Lifecycle: porchv1alpha1.PackageRevisionLifecyclePublished},
+	}
+	want := porchv1alpha1.PackageRevisionSpec{
+		RepositoryName: "edge01",
+		PackageName:    "upf",
+		WorkspaceName:  "drift-remediation-v1",
+		Lifecycle:      porchv1alpha1.PackageRevisionLifecycleDraft,
+		Tasks: []porchv1alpha1.Task{
+			{Type: porchv1alpha1.TaskTypeEdit, Edit: &porchv1alpha1.PackageEditTaskSpec{Source: &porchv1alpha1.PackageRevisionRef{Name: "edge01-upf-v1"}}},
+		},
+	}
+	got := getRemediationRevision(cr)
+	if diff := cmp.Diff(want, got.Spec); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{remediationOfKey: "edge01-upf-v1"}, got.GetAnnotations()); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
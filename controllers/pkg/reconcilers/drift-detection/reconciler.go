@@ -0,0 +1,303 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driftdetection
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/nephio-project/nephio/controllers/pkg/cluster"
+	"github.com/nephio-project/nephio/controllers/pkg/metrics"
+	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
+	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/nephio-project/nephio/controllers/pkg/sharding"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func init() {
+	reconcilerinterface.Register("driftdetections", &reconciler{})
+}
+
+const (
+	// clusterNameKey selects the workload cluster of a deployment repository, by default the
+	// deployment repository is named after its workload cluster
+	clusterNameKey = "nephio.org/cluster-name"
+	// intervalKey sets the interval of the drift checks of a deployment repository
+	intervalKey = "nephio.org/drift-check-interval"
+	// remediationKey opens a remediation package revision for the drift of a deployment repository
+	// when "true"
+	remediationKey = "nephio.org/drift-remediation"
+	// remediationOfKey annotates a remediation package revision with the package revision it remediates
+	remediationOfKey = "nephio.org/drift-remediation-of"
+
+	defaultInterval = 5 * time.Minute
+	minInterval     = 30 * time.Second
+)
+
+//+kubebuilder:rbac:groups="*",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions/status,verbs=get
+//+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisionresources,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.porch.kpt.dev,resources=repositories,verbs=get;list;watch
+//+kubebuilder:rbac:groups=infra.nephio.org,resources=driftstatuses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=infra.nephio.org,resources=driftstatuses/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c any) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
+	cfg, ok := c.(*ctrlconfig.ControllerConfig)
+	if !ok {
+		return nil, fmt.Errorf("cannot initialize, expecting controllerConfig, got: %s", reflect.TypeOf(c).Name())
+	}
+
+	if err := porchv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
+	if err := porchconfigv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
+
+	r.Client = mgr.GetClient()
+	r.porchClient = cfg.PorchClient
+	r.recorder = mgr.GetEventRecorderFor("drift-detection")
+
+	return nil, ctrl.NewControllerManagedBy(mgr).
+		Named("DriftDetectionController").
+		For(&porchv1alpha1.PackageRevision{}).
+		Complete(sharding.NewReconciler(cfg.Shard, metrics.NewReconciler("DriftDetectionController", r)))
+}
+
+type reconciler struct {
+	client.Client
+	porchClient client.Client
+	recorder    record.EventRecorder
+
+	l logr.Logger
+}
+
+// Reconcile compares the latest published package revision of a deployment repository with the
+// workload cluster of the repository and reports the drift, the comparison is repeated at the
+// interval of the repository since the workload cluster is not watched
+func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.l = log.FromContext(ctx)
+	cr := &porchv1alpha1.PackageRevision{}
+	if err := r.Get(ctx, req.NamespacedName, cr); err != nil {
+		// There's no need to requeue if we no longer exist. Otherwise we'll be
+		// requeued implicitly because we return an error.
+		if resource.IgnoreNotFound(err) != nil {
+			msg := "cannot get resource"
+			r.l.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(resource.IgnoreNotFound(err), msg)
+		}
+		return ctrl.Result{}, errors.Wrap(deleteDriftStatus(ctx, r.Client, req.NamespacedName), "cannot delete drift status")
+	}
+
+	// only the latest published revision is deployed
+	if !porchv1alpha1.LifecycleIsPublished(cr.Spec.Lifecycle) ||
+		cr.GetLabels()[porchv1alpha1.LatestPackageRevisionKey] != porchv1alpha1.LatestPackageRevisionValue {
+		return ctrl.Result{}, errors.Wrap(deleteDriftStatus(ctx, r.Client, req.NamespacedName), "cannot delete drift status")
+	}
+	repo := &porchconfigv1alpha1.Repository{}
+	if err := r.porchClient.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.Spec.RepositoryName}, repo); err != nil {
+		msg := "cannot get repository"
+		r.l.Error(err, msg, "repository", cr.Spec.RepositoryName)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	if !repo.Spec.Deployment {
+		return ctrl.Result{}, nil
+	}
+	clusterName := getClusterName(repo)
+	interval := getInterval(repo)
+
+	prr := &porchv1alpha1.PackageRevisionResources{}
+	if err := r.porchClient.Get(ctx, req.NamespacedName, prr); err != nil {
+		msg := "cannot get package revision resources"
+		r.l.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	desired, err := getDesiredResources(prr.Spec.Resources)
+	if err != nil {
+		msg := "cannot get resources"
+		r.l.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+
+	clusterClient, ready, err := r.getClusterClient(ctx, clusterName)
+	if err != nil {
+		msg := "cannot get clusterClient"
+		r.l.Error(err, msg, "cluster", clusterName)
+		return ctrl.Result{RequeueAfter: interval}, errors.Wrap(err, msg)
+	}
+	if !ready {
+		r.l.Info("cluster not ready, retry...", "cluster", clusterName)
+		return ctrl.Result{RequeueAfter: interval}, nil
+	}
+
+	drifted, err := getDriftedResources(ctx, clusterClient, desired)
+	if err != nil {
+		msg := "cannot compare resources"
+		r.l.Error(err, msg, "cluster", clusterName)
+		return ctrl.Result{RequeueAfter: interval}, errors.Wrap(err, msg)
+	}
+	if len(drifted) > 0 && repo.GetAnnotations()[remediationKey] == "true" {
+		// the workload cluster is only changed by config sync, hence the drift is remediated by a new
+		// revision of the package, which config sync re-applies once it is approved
+		remediation, err := r.remediate(ctx, cr)
+		if err != nil {
+			r.recorder.Event(cr, corev1.EventTypeWarning, "RemediationFailed", err.Error())
+			msg := "cannot remediate drift"
+			r.l.Error(err, msg, "cluster", clusterName)
+			return ctrl.Result{RequeueAfter: interval}, errors.Wrap(err, msg)
+		}
+		if remediation != nil {
+			r.recorder.Event(cr, corev1.EventTypeNormal, "RemediationOpened", fmt.Sprintf("package revision %s opened to re-apply %d drifted resources to cluster %s", remediation.GetName(), len(drifted), clusterName))
+		}
+	}
+
+	changed, err := setDriftStatus(ctx, r.Client, cr, clusterName, len(desired), drifted)
+	if err != nil {
+		msg := "cannot update drift status"
+		r.l.Error(err, msg)
+		return ctrl.Result{RequeueAfter: interval}, errors.Wrap(err, msg)
+	}
+	if changed {
+		c := newSyncedCondition(len(desired), drifted)
+		if len(drifted) > 0 {
+			r.l.Info("drift detected", "cluster", clusterName, "drifted", len(drifted))
+			r.recorder.Event(cr, corev1.EventTypeWarning, c.Reason, c.Message)
+		} else {
+			r.recorder.Event(cr, corev1.EventTypeNormal, c.Reason, c.Message)
+		}
+	}
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// getClusterClient returns the client of the workload cluster from its kubeconfig secret
+func (r *reconciler) getClusterClient(ctx context.Context, clusterName string) (resource.APIPatchingApplicator, bool, error) {
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets); err != nil {
+		return resource.APIPatchingApplicator{}, false, err
+	}
+	for _, secret := range secrets.Items {
+		secret := secret // required to prevent gosec warning: G601 (CWE-118): Implicit memory aliasing in for loop
+		clusterClient, ok := cluster.Cluster{Client: r.Client}.GetClusterClient(&secret)
+		if ok && clusterClient.GetClusterName() == clusterName {
+			return clusterClient.GetClusterClient(ctx)
+		}
+	}
+	return resource.APIPatchingApplicator{}, false, nil
+}
+
+// remediate opens a draft revision of the package, copied from the published package revision, such
+// that the approval of the draft re-triggers config sync with the resources of the package. No
+// revision is opened while a revision of the package is pending, e.g. a previous remediation.
+func (r *reconciler) remediate(ctx context.Context, cr *porchv1alpha1.PackageRevision) (*porchv1alpha1.PackageRevision, error) {
+	prList := &porchv1alpha1.PackageRevisionList{}
+	if err := r.porchClient.List(ctx, prList, client.InNamespace(cr.GetNamespace())); err != nil {
+		return nil, errors.Wrap(err, "cannot list package revisions")
+	}
+	if pending := getPendingRevision(prList.Items, cr); pending != nil {
+		r.l.Info("package revision pending, no remediation opened", "packageRevision", pending.GetName())
+		return nil, nil
+	}
+	remediation := getRemediationRevision(cr)
+	if err := r.porchClient.Create(ctx, remediation); err != nil {
+		return nil, errors.Wrap(err, "cannot create remediation package revision")
+	}
+	return remediation, nil
+}
+
+// getPendingRevision returns a revision of the package of the package revision that is not published
+func getPendingRevision(prs []porchv1alpha1.PackageRevision, cr *porchv1alpha1.PackageRevision) *porchv1alpha1.PackageRevision {
+	for i := range prs {
+		pr := &prs[i]
+		if pr.Spec.RepositoryName == cr.Spec.RepositoryName && pr.Spec.PackageName == cr.Spec.PackageName &&
+			!porchv1alpha1.LifecycleIsPublished(pr.Spec.Lifecycle) {
+			return pr
+		}
+	}
+	return nil
+}
+
+// getRemediationRevision returns the draft revision of the package copied from the package revision,
+// its workspace is named after the revision it remediates
+func getRemediationRevision(cr *porchv1alpha1.PackageRevision) *porchv1alpha1.PackageRevision {
+	return &porchv1alpha1.PackageRevision{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: porchv1alpha1.SchemeGroupVersion.Identifier(),
+			Kind:       "PackageRevision",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   cr.GetNamespace(),
+			Annotations: map[string]string{remediationOfKey: cr.GetName()},
+		},
+		Spec: porchv1alpha1.PackageRevisionSpec{
+			PackageName:    cr.Spec.PackageName,
+			RepositoryName: cr.Spec.RepositoryName,
+			WorkspaceName:  porchv1alpha1.WorkspaceName(fmt.Sprintf("drift-remediation-%s", cr.Spec.Revision)),
+			Lifecycle:      porchv1alpha1.PackageRevisionLifecycleDraft,
+			Tasks: []porchv1alpha1.Task{
+				{
+					Type: porchv1alpha1.TaskTypeEdit,
+					Edit: &porchv1alpha1.PackageEditTaskSpec{
+						Source: &porchv1alpha1.PackageRevisionRef{Name: cr.GetName()},
+					},
+				},
+			},
+		},
+	}
+}
+
+// getClusterName returns the workload cluster of the deployment repository
+func getClusterName(repo *porchconfigv1alpha1.Repository) string {
+	if name := repo.GetAnnotations()[clusterNameKey]; name != "" {
+		return name
+	}
+	return repo.GetName()
+}
+
+// getInterval returns the interval of the drift checks of the deployment repository
+func getInterval(repo *porchconfigv1alpha1.Repository) time.Duration {
+	s := strings.TrimSpace(repo.GetAnnotations()[intervalKey])
+	if s == "" {
+		return defaultInterval
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return defaultInterval
+	}
+	if d < minInterval {
+		return minInterval
+	}
+	return d
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driftdetection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DriftStatusKind is the kind of the drift status of a package revision of a deployment
+	// repository, it is not part of the nephio api yet and is written as unstructured
+	DriftStatusKind = "DriftStatus"
+
+	ConditionTypeSynced = "Synced"
+
+	// PackageRevisionLabelKey and ClusterNameLabelKey label the drift statuses with their package
+	// revision and workload cluster
+	PackageRevisionLabelKey = "nephio.org/package-revision"
+	ClusterNameLabelKey     = "nephio.org/cluster-name"
+)
+
+// DriftStatusGroupVersionKind is the GroupVersionKind of the drift status of a package revision
+var DriftStatusGroupVersionKind = schema.GroupVersionKind{Group: "infra.nephio.org", Version: "v1alpha1", Kind: DriftStatusKind}
+
+// DriftStatus reports the drift between a package revision of a deployment repository and the
+// workload cluster, it is named after the package revision in its namespace
+type DriftStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Status            DriftStatusStatus `json:"status,omitempty"`
+}
+
+type DriftStatusStatus struct {
+	// ClusterName is the workload cluster the package revision is deployed to
+	ClusterName string `json:"clusterName,omitempty"`
+	// InSync is true when the resources of the package revision match the workload cluster
+	InSync bool `json:"inSync"`
+	// Conditions holds the Synced condition
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Drifted holds the resources that differ on the workload cluster
+	Drifted []DriftedResource `json:"drifted,omitempty"`
+	// LastCheckTime is the time the workload cluster was last compared
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+}
+
+func newSyncedCondition(total int, drifted []DriftedResource) metav1.Condition {
+	if len(drifted) == 0 {
+		return metav1.Condition{Type: ConditionTypeSynced, Status: metav1.ConditionTrue, Reason: ConditionTypeSynced, Message: fmt.Sprintf("%d/%d resources in sync", total, total)}
+	}
+	resources := make([]string, 0, len(drifted))
+	for _, d := range drifted {
+		resources = append(resources, fmt.Sprintf("%s (%s)", d.String(), strings.ToLower(d.Reason)))
+	}
+	return metav1.Condition{
+		Type:    ConditionTypeSynced,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Drifted",
+		Message: fmt.Sprintf("%d/%d resources in sync, drifted: %s", total-len(drifted), total, strings.Join(resources, ", ")),
+	}
+}
+
+// setDriftStatus creates or updates the drift status of the package revision, it returns true
+// when the Synced condition changed
+func setDriftStatus(ctx context.Context, c client.Client, pr *porchv1alpha1.PackageRevision, clusterName string, total int, drifted []DriftedResource) (bool, error) {
+	key := types.NamespacedName{Namespace: pr.GetNamespace(), Name: pr.GetName()}
+	u := resource.GetUnstructuredFromGVK(&DriftStatusGroupVersionKind)
+	if err := c.Get(ctx, key, u); err != nil {
+		if resource.IgnoreNotFound(err) != nil {
+			return false, err
+		}
+		u.SetNamespace(key.Namespace)
+		u.SetName(key.Name)
+		u.SetLabels(map[string]string{PackageRevisionLabelKey: pr.GetName(), ClusterNameLabelKey: clusterName})
+		if err := c.Create(ctx, u); err != nil {
+			return false, err
+		}
+	}
+	b, err := json.Marshal(u)
+	if err != nil {
+		return false, err
+	}
+	s := &DriftStatus{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return false, err
+	}
+
+	s.Status.ClusterName = clusterName
+	s.Status.InSync = len(drifted) == 0
+	s.Status.Drifted = drifted
+	s.Status.LastCheckTime = metav1.Now()
+	condition := newSyncedCondition(total, drifted)
+	previous := meta.FindStatusCondition(s.Status.Conditions, ConditionTypeSynced)
+	changed := previous == nil || previous.Status != condition.Status || previous.Message != condition.Message
+	meta.SetStatusCondition(&s.Status.Conditions, condition)
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&s.Status)
+	if err != nil {
+		return false, err
+	}
+	if err := unstructured.SetNestedMap(u.Object, status, "status"); err != nil {
+		return false, err
+	}
+	return changed, c.Status().Update(ctx, u)
+}
+
+// deleteDriftStatus deletes the drift status of the package revision
+func deleteDriftStatus(ctx context.Context, c client.Client, key types.NamespacedName) error {
+	u := resource.GetUnstructuredFromGVK(&DriftStatusGroupVersionKind)
+	u.SetNamespace(key.Namespace)
+	u.SetName(key.Name)
+	return resource.IgnoreNotFound(c.Delete(ctx, u))
+}
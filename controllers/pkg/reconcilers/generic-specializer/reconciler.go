@@ -27,16 +27,20 @@ import (
 	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	"github.com/go-logr/logr"
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/metrics"
 	porchcondition "github.com/nephio-project/nephio/controllers/pkg/porch/condition"
 	porchutil "github.com/nephio-project/nephio/controllers/pkg/porch/util"
 	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/nephio-project/nephio/controllers/pkg/sharding"
 	configinjectfn "github.com/nephio-project/nephio/krm-functions/configinject-fn/fn"
 	ipamfn "github.com/nephio-project/nephio/krm-functions/ipam-fn/fn"
 	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
 	"github.com/nephio-project/nephio/krm-functions/lib/kptrl"
 	"github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+	"github.com/nephio-project/nephio/krm-functions/lib/tracing"
 	vlanfn "github.com/nephio-project/nephio/krm-functions/vlan-fn/fn"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
 	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
@@ -79,7 +83,7 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("GenericSpecializer").
 		For(&porchv1alpha1.PackageRevision{}).
-		Complete(r)
+		Complete(sharding.NewReconciler(cfg.Shard, metrics.NewReconciler("GenericSpecializer", r)))
 }
 
 // reconciler reconciles a NetworkInstance object
@@ -126,15 +130,15 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	ipamf := ipamfn.New(r.ipamClientProxy)
 	ipamkrmfn := fn.ResourceListProcessorFunc(ipamf.Run)
-	ipamFor := ipamf.GetConfig().For
+	ipamFor := ipamf.GetConfig().For[0]
 
 	vlanf := vlanfn.New(r.vlanClientProxy)
 	vlankrmfn := fn.ResourceListProcessorFunc(vlanf.Run)
-	vlanFor := vlanf.GetConfig().For
+	vlanFor := vlanf.GetConfig().For[0]
 
 	configInjectf := configinjectfn.New(r.porchClient)
 	configInjectkrmfn := fn.ResourceListProcessorFunc(configInjectf.Run)
-	configInjectFor := configInjectf.GetConfig().For
+	configInjectFor := configInjectf.GetConfig().For[0]
 
 	// we just check for forResource conditions and we don't care if it is satisfied already
 	// this allows us to refresh the allocation.
@@ -156,6 +160,10 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			r.l.Error(err, "cannot get resourceList")
 			return ctrl.Result{}, errors.Wrap(err, "cannot get resourceList")
 		}
+		// the functions trace their runs in the trace of the specialization of the package
+		if _, err := tracing.Propagate(ctx, rl.Items.GetRootKptfile()); err != nil {
+			r.l.Error(err, "cannot propagate the trace context to the Kptfile")
+		}
 
 		if porchcondition.HasSpecificTypeConditions(pr.Status.Conditions, kptfilelibv1.GetConditionType(&ipamFor)) {
 			// run the function SDK
@@ -187,7 +195,8 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			}
 			r.l.Info("configInject specializer fn run successful")
 		}
-		workloadClusterObjs := rl.Items.Where(fn.IsGroupVersionKind(infrav1alpha1.WorkloadClusterGroupVersionKind))
+		r.recordResults(pr, rl)
+		workloadClusterObjs := rl.Items.Where(kubeobject.IsGroupVersionKind(infrav1alpha1.WorkloadClusterGroupVersionKind))
 		clusterName := r.getClusterName(workloadClusterObjs)
 
 		// We want to process the functions to refresh the claims
@@ -306,6 +315,24 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	return ctrl.Result{}, nil
 }
 
+// recordResults records an event per coded warning or error result of the functions, with the
+// code as reason, such that the users see why the specialization is not progressing
+func (r *reconciler) recordResults(pr *porchv1alpha1.PackageRevision, rl *fn.ResourceList) {
+	for _, res := range results.GetResults(rl.Results) {
+		if res.Severity == fn.Info {
+			continue
+		}
+		msg := res.Message
+		if res.Resource != nil {
+			msg = fmt.Sprintf("%s %s: %s", res.Resource.Kind, res.Resource.Name, msg)
+		}
+		if res.Remediation != "" {
+			msg = fmt.Sprintf("%s; %s", msg, res.Remediation)
+		}
+		r.recorder.Event(pr, corev1.EventTypeWarning, string(res.Code), msg)
+	}
+}
+
 func (r *reconciler) getClusterName(workloadClusterObjs fn.KubeObjects) string {
 	clusterName := ""
 	if len(workloadClusterObjs) > 0 {
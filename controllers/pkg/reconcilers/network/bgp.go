@@ -0,0 +1,213 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"sort"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	"github.com/openconfig/ygot/ygot"
+	"github.com/srl-labs/ygotsrl/v22"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// bgpPeeringAnnotation holds the json list of the eBGP peerings of the routing tables of the network
+	bgpPeeringAnnotation = "nephio.org/bgp-peering"
+
+	defaultRouterID = "1.1.1.1"
+	bgpNFGroupName  = "nf"
+)
+
+// bgpPeering is the eBGP peering intent of a routing table of the network: the nodes of the routing
+// table peer with the addresses claimed in the routing table, e.g. the interfaces of the NFs, and
+// accept the prefixes of the routing table advertised by the peers, e.g. the ue pools of the NFs,
+// such that the dynamically allocated prefixes reach the fabric without manual switch config
+type bgpPeering struct {
+	// RoutingTable is the name of the routing table of the network
+	RoutingTable string `json:"routingTable"`
+	// LocalASN is the autonomous system of the nodes in the routing table
+	LocalASN uint32 `json:"localASN"`
+	// PeerASN is the autonomous system of the peers
+	PeerASN uint32 `json:"peerASN"`
+	// PeerAddresses are the addresses of the peers not claimed from the ipam
+	PeerAddresses []string `json:"peerAddresses,omitempty"`
+	// PeerSelector selects the IPClaims of the routing table whose claimed addresses are the
+	// peers, all the network claims of the routing table when not set
+	PeerSelector *metav1.LabelSelector `json:"peerSelector,omitempty"`
+}
+
+// getBGPPeerings returns the eBGP peerings of the routing tables of the network
+func getBGPPeerings(cr *infrav1alpha1.Network) ([]bgpPeering, error) {
+	v, ok := cr.GetAnnotations()[bgpPeeringAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	peerings := []bgpPeering{}
+	if err := json.Unmarshal([]byte(v), &peerings); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %s", bgpPeeringAnnotation, err.Error())
+	}
+	for _, peering := range peerings {
+		if peering.LocalASN == 0 || peering.PeerASN == 0 {
+			return nil, fmt.Errorf("invalid %s annotation: routing table %s requires a localASN and a peerASN", bgpPeeringAnnotation, peering.RoutingTable)
+		}
+		if getRoutingTable(cr, peering.RoutingTable) == nil {
+			return nil, fmt.Errorf("invalid %s annotation: routing table %s not found in the network", bgpPeeringAnnotation, peering.RoutingTable)
+		}
+		for _, a := range peering.PeerAddresses {
+			if _, err := netip.ParseAddr(a); err != nil {
+				return nil, fmt.Errorf("invalid %s annotation: invalid peer address %s of routing table %s", bgpPeeringAnnotation, a, peering.RoutingTable)
+			}
+		}
+	}
+	return peerings, nil
+}
+
+func getRoutingTable(cr *infrav1alpha1.Network, name string) *infrav1alpha1.RoutingTable {
+	for i := range cr.Spec.RoutingTables {
+		if cr.Spec.RoutingTables[i].Name == name {
+			return &cr.Spec.RoutingTables[i]
+		}
+	}
+	return nil
+}
+
+// getBGPPeers returns the peer addresses of the peering, the static addresses and the addresses
+// claimed in the network instance of the routing table
+func (r *reconciler) getBGPPeers(ctx context.Context, cr *infrav1alpha1.Network, peering bgpPeering) ([]string, error) {
+	selector := labels.Everything()
+	if peering.PeerSelector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(peering.PeerSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+	claims := &ipamv1alpha1.IPClaimList{}
+	if err := r.List(ctx, claims, client.InNamespace(cr.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	return getPeerAddresses(peering, claims.Items)
+}
+
+func getPeerAddresses(peering bgpPeering, claims []ipamv1alpha1.IPClaim) ([]string, error) {
+	peers := map[string]struct{}{}
+	for _, a := range peering.PeerAddresses {
+		peers[a] = struct{}{}
+	}
+	for _, claim := range claims {
+		if claim.Spec.NetworkInstance.Name != peering.RoutingTable ||
+			claim.Spec.Kind != ipamv1alpha1.PrefixKindNetwork ||
+			claim.Status.Prefix == nil {
+			continue
+		}
+		p, err := netip.ParsePrefix(*claim.Status.Prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prefix %s of ip claim %s: %s", *claim.Status.Prefix, claim.GetName(), err.Error())
+		}
+		peers[p.Addr().String()] = struct{}{}
+	}
+	addresses := make([]string, 0, len(peers))
+	for a := range peers {
+		addresses = append(addresses, a)
+	}
+	sort.Strings(addresses)
+	return addresses, nil
+}
+
+// getImportPolicyName returns the routing policy accepting the prefixes of the routing table from the peers
+func getImportPolicyName(rtName string) string {
+	return fmt.Sprintf("import-nf-%s", rtName)
+}
+
+// addBGPPeering adds the eBGP group and neighbors of the peering to the network instance of the
+// routing table of the device, with an import policy accepting the prefixes of the routing table;
+// a device without the routing table is left untouched
+func addBGPPeering(device *ygotsrl.Device, peering bgpPeering, peers []string, prefixes []ipamv1alpha1.Prefix) error {
+	ni, ok := device.NetworkInstance[peering.RoutingTable]
+	if !ok || len(peers) == 0 {
+		return nil
+	}
+
+	policyName := getImportPolicyName(peering.RoutingTable)
+	rp := device.GetOrCreateRoutingPolicy().GetOrCreatePolicy(policyName)
+	for _, prefix := range prefixes {
+		p, err := netip.ParsePrefix(prefix.Prefix)
+		if err != nil {
+			return err
+		}
+		af, maxLength, seqID := "ipv4", 32, uint32(10)
+		if p.Addr().Is6() {
+			af, maxLength, seqID = "ipv6", 128, 20
+		}
+		psName := fmt.Sprintf("nf-%s-%s", peering.RoutingTable, af)
+		device.GetOrCreateRoutingPolicy().GetOrCreatePrefixSet(psName).
+			GetOrCreatePrefix(p.Masked().String(), fmt.Sprintf("%d..%d", p.Bits(), maxLength))
+		st := rp.GetOrCreateStatement(seqID)
+		st.GetOrCreateMatch().PrefixSet = ygot.String(psName)
+		st.GetOrCreateAction().PolicyResult = ygotsrl.SrlNokiaPolicyTypes_PolicyResultType_accept
+	}
+	rp.GetOrCreateDefaultAction().PolicyResult = ygotsrl.SrlNokiaPolicyTypes_PolicyResultType_reject
+
+	bgp := ni.GetOrCreateProtocols().GetOrCreateBgp()
+	bgp.AdminState = ygotsrl.SrlNokiaCommon_AdminState_enable
+	bgp.AutonomousSystem = ygot.Uint32(peering.LocalASN)
+	bgp.RouterId = ygot.String(getRouterID(device))
+	bgp.GetOrCreateIpv4Unicast().AdminState = ygotsrl.SrlNokiaCommon_AdminState_enable
+	bgp.GetOrCreateIpv6Unicast().AdminState = ygotsrl.SrlNokiaCommon_AdminState_enable
+
+	group := bgp.GetOrCreateGroup(bgpNFGroupName)
+	group.AdminState = ygotsrl.SrlNokiaCommon_AdminState_enable
+	group.PeerAs = ygot.Uint32(peering.PeerASN)
+	group.ImportPolicy = ygot.String(policyName)
+	for _, peer := range peers {
+		neighbor := bgp.GetOrCreateNeighbor(peer)
+		neighbor.AdminState = ygotsrl.SrlNokiaCommon_AdminState_enable
+		neighbor.PeerGroup = ygot.String(bgpNFGroupName)
+	}
+	return nil
+}
+
+// getRouterID returns the ipv4 address of the system interface of the device
+func getRouterID(device *ygotsrl.Device) string {
+	itfce, ok := device.Interface[systemInterfaceName]
+	if !ok {
+		return defaultRouterID
+	}
+	si, ok := itfce.Subinterface[0]
+	if !ok || si.Ipv4 == nil {
+		return defaultRouterID
+	}
+	addresses := make([]string, 0, len(si.Ipv4.Address))
+	for a := range si.Ipv4.Address {
+		addresses = append(addresses, a)
+	}
+	sort.Strings(addresses)
+	for _, a := range addresses {
+		if p, err := netip.ParsePrefix(a); err == nil {
+			return p.Addr().String()
+		}
+	}
+	return defaultRouterID
+}
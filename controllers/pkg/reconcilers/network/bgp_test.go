@@ -0,0 +1,245 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func getTestNetwork(annotation string) *infrav1alpha1.Network {
+	cr := &infrav1alpha1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "vpc-internet", Namespace: "default"},
+		Spec: infrav1alpha1.NetworkSpec{
+			RoutingTables: []infrav1alpha1.RoutingTable{{
+				Name:     "rt1",
+				Prefixes: []ipamv1alpha1.Prefix{{Prefix: "10.0.0.0/16"}, {Prefix: "2001:db8:1::/48"}},
+			}},
+		},
+	}
+	if annotation != "" {
+		cr.Annotations = map[string]string{bgpPeeringAnnotation: annotation}
+	}
+	return cr
+}
+
+func TestGetBGPPeerings(t *testing.T) {
+	cases := map[string]struct {
+		annotation  string
+		want        []bgpPeering
+		expectedErr bool
+	}{
+		"NoAnnotation": {
+			annotation: "",
+			want:       nil,
+		},
+		"Peering": {
+			annotation: `[{"routingTable":"rt1","localASN":65001,"peerASN":65100,"peerAddresses":["10.0.0.100"]}]`,
+			want:       []bgpPeering{{RoutingTable: "rt1", LocalASN: 65001, PeerASN: 65100, PeerAddresses: []string{"10.0.0.100"}}},
+		},
+		"InvalidJSON": {
+			annotation:  `{"routingTable":"rt1"}`,
+			expectedErr: true,
+		},
+		"MissingASN": {
+			annotation:  `[{"routingTable":"rt1","localASN":65001}]`,
+			expectedErr: true,
+		},
+		"UnknownRoutingTable": {
+			annotation:  `[{"routingTable":"rt2","localASN":65001,"peerASN":65100}]`,
+			expectedErr: true,
+		},
+		"InvalidPeerAddress": {
+			annotation:  `[{"routingTable":"rt1","localASN":65001,"peerASN":65100,"peerAddresses":["10.0.0.0/24"]}]`,
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := getBGPPeerings(getTestNetwork(tc.annotation))
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("getBGPPeerings() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getBGPPeerings() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("getBGPPeerings() -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func getTestIPClaim(name, networkInstance string, kind ipamv1alpha1.PrefixKind, prefix *string) ipamv1alpha1.IPClaim {
+	return ipamv1alpha1.IPClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: ipamv1alpha1.IPClaimSpec{
+			Kind:            kind,
+			NetworkInstance: corev1.ObjectReference{Name: networkInstance},
+		},
+		Status: ipamv1alpha1.IPClaimStatus{Prefix: prefix},
+	}
+}
+
+func TestGetPeerAddresses(t *testing.T) {
+	claims := []ipamv1alpha1.IPClaim{
+		getTestIPClaim("upf-n6", "rt1", ipamv1alpha1.PrefixKindNetwork, pointer.String("10.0.0.10/24")),
+		getTestIPClaim("upf-n6-ipv6", "rt1", ipamv1alpha1.PrefixKindNetwork, pointer.String("2001:db8:1::10/64")),
+		getTestIPClaim("upf-pool", "rt1", ipamv1alpha1.PrefixKindPool, pointer.String("10.0.128.0/24")),
+		getTestIPClaim("smf-n4", "rt2", ipamv1alpha1.PrefixKindNetwork, pointer.String("10.1.0.10/24")),
+		getTestIPClaim("upf-n6-pending", "rt1", ipamv1alpha1.PrefixKindNetwork, nil),
+	}
+	got, err := getPeerAddresses(bgpPeering{RoutingTable: "rt1", PeerAddresses: []string{"10.0.0.100", "10.0.0.10"}}, claims)
+	if err != nil {
+		t.Fatalf("getPeerAddresses() error = %v", err)
+	}
+	want := []string{"10.0.0.10", "10.0.0.100", "2001:db8:1::10"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("getPeerAddresses() -want, +got:\n%s", diff)
+	}
+}
+
+func getTestBGPDevice(t *testing.T) *fabricConfig {
+	device := getTestDevice()
+	peering := bgpPeering{RoutingTable: "rt1", LocalASN: 65001, PeerASN: 65100}
+	prefixes := getTestNetwork("").Spec.RoutingTables[0].Prefixes
+	if err := addBGPPeering(device, peering, []string{"10.0.0.10", "2001:db8:1::10"}, prefixes); err != nil {
+		t.Fatalf("addBGPPeering() error = %v", err)
+	}
+	// the device has no network instance of rt2
+	if err := addBGPPeering(device, bgpPeering{RoutingTable: "rt2", LocalASN: 65001, PeerASN: 65200}, []string{"10.1.0.10"}, nil); err != nil {
+		t.Fatalf("addBGPPeering() error = %v", err)
+	}
+	if _, err := renderConfig(nokiaSRLProvider, device); err != nil {
+		t.Fatalf("renderConfig() error = %v", err)
+	}
+	return getFabricConfig(device)
+}
+
+func TestAddBGPPeering(t *testing.T) {
+	fc := getTestBGPDevice(t)
+	bgps := map[string]*fabricBGP{}
+	for _, ni := range fc.networkInstances {
+		if ni.bgp != nil {
+			bgps[ni.name] = ni.bgp
+		}
+	}
+	wantBGP := map[string]*fabricBGP{
+		"rt1": {
+			asn:      65001,
+			routerID: "100.64.0.1",
+			neighbors: []*fabricBGPNeighbor{
+				{address: "10.0.0.10", peerASN: 65100, importPolicy: "import-nf-rt1"},
+				{address: "2001:db8:1::10", peerASN: 65100, importPolicy: "import-nf-rt1"},
+			},
+		},
+	}
+	if diff := cmp.Diff(wantBGP, bgps, cmp.AllowUnexported(fabricBGP{}, fabricBGPNeighbor{})); diff != "" {
+		t.Errorf("addBGPPeering() bgp -want, +got:\n%s", diff)
+	}
+	wantPolicies := []*fabricPolicy{{
+		name: "import-nf-rt1",
+		prefixSets: []*fabricPrefixSet{
+			{name: "nf-rt1-ipv4", prefixes: []fabricPrefix{{prefix: "10.0.0.0/16", minLength: 16, maxLength: 32}}},
+			{name: "nf-rt1-ipv6", ipv6: true, prefixes: []fabricPrefix{{prefix: "2001:db8:1::/48", minLength: 48, maxLength: 128}}},
+		},
+	}}
+	if diff := cmp.Diff(wantPolicies, fc.policies, cmp.AllowUnexported(fabricPolicy{}, fabricPrefixSet{}, fabricPrefix{})); diff != "" {
+		t.Errorf("addBGPPeering() policies -want, +got:\n%s", diff)
+	}
+}
+
+func TestRenderSONiCBGP(t *testing.T) {
+	b, err := renderSONiC(getTestBGPDevice(t))
+	if err != nil {
+		t.Fatalf("renderSONiC() error = %v", err)
+	}
+	got := map[string]map[string]map[string]string{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("renderSONiC() invalid json: %v", err)
+	}
+	want := map[string]map[string]map[string]string{
+		"PREFIX_SET": {
+			"nf-rt1-ipv4": {"mode": "IPv4"},
+			"nf-rt1-ipv6": {"mode": "IPv6"},
+		},
+		"PREFIX": {
+			"nf-rt1-ipv4|10|10.0.0.0/16|16..32":      {"action": "permit"},
+			"nf-rt1-ipv6|10|2001:db8:1::/48|48..128": {"action": "permit"},
+		},
+		"ROUTE_MAP": {
+			"import-nf-rt1|10": {"route_operation": "permit", "match_prefix_set": "nf-rt1-ipv4"},
+			"import-nf-rt1|20": {"route_operation": "permit", "match_prefix_set": "nf-rt1-ipv6"},
+		},
+		"BGP_GLOBALS": {
+			"Vrf-rt1": {"local_asn": "65001", "router_id": "100.64.0.1"},
+		},
+		"BGP_NEIGHBOR": {
+			"Vrf-rt1|10.0.0.10":      {"asn": "65100", "admin_status": "up"},
+			"Vrf-rt1|2001:db8:1::10": {"asn": "65100", "admin_status": "up"},
+		},
+		"BGP_NEIGHBOR_AF": {
+			"Vrf-rt1|10.0.0.10|ipv4_unicast":      {"admin_status": "true", "route_map_in": "import-nf-rt1"},
+			"Vrf-rt1|2001:db8:1::10|ipv6_unicast": {"admin_status": "true", "route_map_in": "import-nf-rt1"},
+		},
+	}
+	for table, entries := range want {
+		if diff := cmp.Diff(entries, got[table]); diff != "" {
+			t.Errorf("renderSONiC() %s -want, +got:\n%s", table, diff)
+		}
+	}
+}
+
+func TestRenderEOSBGP(t *testing.T) {
+	got, err := renderEOSBGP(getTestBGPDevice(t))
+	if err != nil {
+		t.Fatalf("renderEOSBGP() error = %v", err)
+	}
+	want := []string{
+		"ip prefix-list nf-rt1-ipv4",
+		"   seq 10 permit 10.0.0.0/16 le 32",
+		"ipv6 prefix-list nf-rt1-ipv6",
+		"   seq 10 permit 2001:db8:1::/48 le 128",
+		"route-map import-nf-rt1 permit 10",
+		"   match ip address prefix-list nf-rt1-ipv4",
+		"route-map import-nf-rt1 permit 20",
+		"   match ipv6 address prefix-list nf-rt1-ipv6",
+		"router bgp 65001",
+		"   vrf rt1",
+		"      router-id 100.64.0.1",
+		"      neighbor 10.0.0.10 remote-as 65100",
+		"      neighbor 10.0.0.10 route-map import-nf-rt1 in",
+		"      neighbor 2001:db8:1::10 remote-as 65100",
+		"      neighbor 2001:db8:1::10 route-map import-nf-rt1 in",
+		"      address-family ipv6",
+		"         neighbor 2001:db8:1::10 activate",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("renderEOSBGP() -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,599 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/ygot/ygot"
+	"github.com/srl-labs/ygotsrl/v22"
+)
+
+const (
+	sonicProvider     = "sonic-net.github.io"
+	aristaEOSProvider = "eos.arista.com"
+
+	irbInterfaceName    = "irb0"
+	systemInterfaceName = "system0"
+)
+
+// supportedProviders are the providers of the nodes the network config is rendered for, the
+// provider of a node in the inventory selects the NOS of its config
+var supportedProviders = []string{nokiaSRLProvider, sonicProvider, aristaEOSProvider}
+
+func isSupportedProvider(provider string) bool {
+	for _, p := range supportedProviders {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// renderConfig renders the device config of the network, modelled after SR Linux, in the config
+// format of the NOS of the provider
+func renderConfig(provider string, device *ygotsrl.Device) ([]byte, error) {
+	switch provider {
+	case sonicProvider:
+		return renderSONiC(getFabricConfig(device))
+	case aristaEOSProvider:
+		return renderEOS(getFabricConfig(device))
+	default:
+		j, err := ygot.EmitJSON(device, &ygot.EmitJSONConfig{
+			Format: ygot.RFC7951,
+			Indent: "  ",
+			RFC7951Config: &ygot.RFC7951JSONConfig{
+				AppendModuleName: true,
+			},
+			SkipValidation: false,
+		})
+		return []byte(j), err
+	}
+}
+
+// fabricConfig is the vendor neutral view of the interfaces, network instances and eBGP peerings of a device config
+type fabricConfig struct {
+	interfaces       []*fabricInterface
+	networkInstances []*fabricNetworkInstance
+	// policies are the routing policies imported from the bgp neighbors
+	policies []*fabricPolicy
+}
+
+type fabricInterface struct {
+	// name is the SR Linux name of the interface, e.g. ethernet-1/1, irb0 or system0
+	name  string
+	index uint32
+	// vlanID is the vlan of a tagged subinterface, 0 when untagged
+	vlanID    uint16
+	addresses []string
+	anycast   bool
+	// bridged is the mac-vrf of the subinterface, routed is the ip-vrf or default network instance of the subinterface
+	bridged *fabricNetworkInstance
+	routed  *fabricNetworkInstance
+}
+
+type fabricNetworkInstance struct {
+	name   string
+	niType ygotsrl.E_SrlNokiaNetworkInstance_NiType
+	// vlanID is the vlan of a mac-vrf
+	vlanID uint16
+	// bgp is the bgp of the network instance, nil without bgp neighbors
+	bgp *fabricBGP
+}
+
+type fabricBGP struct {
+	asn       uint32
+	routerID  string
+	neighbors []*fabricBGPNeighbor
+}
+
+type fabricBGPNeighbor struct {
+	address      string
+	peerASN      uint32
+	importPolicy string
+}
+
+// fabricPolicy is a routing policy accepting the prefixes of its prefix sets
+type fabricPolicy struct {
+	name       string
+	prefixSets []*fabricPrefixSet
+}
+
+type fabricPrefixSet struct {
+	name     string
+	ipv6     bool
+	prefixes []fabricPrefix
+}
+
+type fabricPrefix struct {
+	prefix    string
+	minLength int
+	maxLength int
+}
+
+func getFabricConfig(device *ygotsrl.Device) *fabricConfig {
+	fc := &fabricConfig{}
+	niNames := make([]string, 0, len(device.NetworkInstance))
+	for name := range device.NetworkInstance {
+		niNames = append(niNames, name)
+	}
+	sort.Strings(niNames)
+	// network instances per subinterface, e.g. ethernet-1/1.10
+	niItfces := map[string][]*fabricNetworkInstance{}
+	for _, name := range niNames {
+		ni := device.NetworkInstance[name]
+		fni := &fabricNetworkInstance{name: name, niType: ni.Type}
+		if ni.Protocols != nil {
+			fni.bgp = getFabricBGP(ni.Protocols.Bgp)
+		}
+		fc.networkInstances = append(fc.networkInstances, fni)
+		for itfceName := range ni.Interface {
+			niItfces[itfceName] = append(niItfces[itfceName], fni)
+		}
+	}
+
+	itfceNames := make([]string, 0, len(device.Interface))
+	for name := range device.Interface {
+		itfceNames = append(itfceNames, name)
+	}
+	sort.Strings(itfceNames)
+	for _, name := range itfceNames {
+		itfce := device.Interface[name]
+		indexes := make([]int, 0, len(itfce.Subinterface))
+		for index := range itfce.Subinterface {
+			indexes = append(indexes, int(index))
+		}
+		sort.Ints(indexes)
+		for _, index := range indexes {
+			si := itfce.Subinterface[uint32(index)]
+			fi := &fabricInterface{name: name, index: uint32(index)}
+			if si.Vlan != nil && si.Vlan.Encap != nil && si.Vlan.Encap.SingleTagged != nil {
+				if vlanID, ok := si.Vlan.Encap.SingleTagged.VlanId.(ygotsrl.UnionUint16); ok {
+					fi.vlanID = uint16(vlanID)
+				}
+			}
+			if si.Ipv4 != nil {
+				for prefix, a := range si.Ipv4.Address {
+					fi.addresses = append(fi.addresses, prefix)
+					fi.anycast = fi.anycast || (a.AnycastGw != nil && *a.AnycastGw)
+				}
+			}
+			if si.Ipv6 != nil {
+				for prefix, a := range si.Ipv6.Address {
+					fi.addresses = append(fi.addresses, prefix)
+					fi.anycast = fi.anycast || (a.AnycastGw != nil && *a.AnycastGw)
+				}
+			}
+			sort.Strings(fi.addresses)
+			for _, ni := range niItfces[fmt.Sprintf("%s.%d", name, index)] {
+				if ni.niType == ygotsrl.SrlNokiaNetworkInstance_NiType_mac_vrf {
+					fi.bridged = ni
+				} else {
+					fi.routed = ni
+				}
+			}
+			fc.interfaces = append(fc.interfaces, fi)
+		}
+	}
+
+	// the vlan of a mac-vrf is the vlan of its tagged subinterfaces, or the index of its irb subinterface
+	for _, fi := range fc.interfaces {
+		if fi.bridged == nil || fi.bridged.vlanID != 0 {
+			continue
+		}
+		if fi.vlanID != 0 {
+			fi.bridged.vlanID = fi.vlanID
+		}
+	}
+	for _, fi := range fc.interfaces {
+		if fi.bridged != nil && fi.bridged.vlanID == 0 && fi.name == irbInterfaceName {
+			fi.bridged.vlanID = uint16(fi.index)
+		}
+	}
+
+	policies := map[string]struct{}{}
+	for _, ni := range fc.networkInstances {
+		if ni.bgp == nil {
+			continue
+		}
+		for _, n := range ni.bgp.neighbors {
+			if _, ok := policies[n.importPolicy]; ok || n.importPolicy == "" {
+				continue
+			}
+			policies[n.importPolicy] = struct{}{}
+			if fp := getFabricPolicy(device.RoutingPolicy, n.importPolicy); fp != nil {
+				fc.policies = append(fc.policies, fp)
+			}
+		}
+	}
+	return fc
+}
+
+// getFabricBGP returns the bgp neighbors with their peer as and import policy, inherited from
+// their group when not set on the neighbor
+func getFabricBGP(bgp *ygotsrl.SrlNokiaNetworkInstance_NetworkInstance_Protocols_Bgp) *fabricBGP {
+	if bgp == nil || len(bgp.Neighbor) == 0 || bgp.AutonomousSystem == nil {
+		return nil
+	}
+	fb := &fabricBGP{asn: *bgp.AutonomousSystem}
+	if bgp.RouterId != nil {
+		fb.routerID = *bgp.RouterId
+	}
+	addresses := make([]string, 0, len(bgp.Neighbor))
+	for a := range bgp.Neighbor {
+		addresses = append(addresses, a)
+	}
+	sort.Strings(addresses)
+	for _, a := range addresses {
+		n := bgp.Neighbor[a]
+		fbn := &fabricBGPNeighbor{address: a}
+		if n.PeerGroup != nil {
+			if g, ok := bgp.Group[*n.PeerGroup]; ok {
+				if g.PeerAs != nil {
+					fbn.peerASN = *g.PeerAs
+				}
+				if g.ImportPolicy != nil {
+					fbn.importPolicy = *g.ImportPolicy
+				}
+			}
+		}
+		if n.PeerAs != nil {
+			fbn.peerASN = *n.PeerAs
+		}
+		if n.ImportPolicy != nil {
+			fbn.importPolicy = *n.ImportPolicy
+		}
+		if fbn.peerASN == 0 {
+			continue
+		}
+		fb.neighbors = append(fb.neighbors, fbn)
+	}
+	return fb
+}
+
+// getFabricPolicy returns the prefix sets of the accept statements of the policy in sequence order
+func getFabricPolicy(rp *ygotsrl.SrlNokiaRoutingPolicy_RoutingPolicy, name string) *fabricPolicy {
+	if rp == nil {
+		return nil
+	}
+	p, ok := rp.Policy[name]
+	if !ok {
+		return nil
+	}
+	fp := &fabricPolicy{name: name}
+	seqIDs := make([]int, 0, len(p.Statement))
+	for seqID := range p.Statement {
+		seqIDs = append(seqIDs, int(seqID))
+	}
+	sort.Ints(seqIDs)
+	for _, seqID := range seqIDs {
+		st := p.Statement[uint32(seqID)]
+		if st.Match == nil || st.Match.PrefixSet == nil ||
+			st.Action == nil || st.Action.PolicyResult != ygotsrl.SrlNokiaPolicyTypes_PolicyResultType_accept {
+			continue
+		}
+		ps, ok := rp.PrefixSet[*st.Match.PrefixSet]
+		if !ok {
+			continue
+		}
+		fps := &fabricPrefixSet{name: *st.Match.PrefixSet}
+		for key := range ps.Prefix {
+			fpx := fabricPrefix{prefix: key.IpPrefix}
+			if _, err := fmt.Sscanf(key.MaskLengthRange, "%d..%d", &fpx.minLength, &fpx.maxLength); err != nil {
+				continue
+			}
+			fps.ipv6 = strings.Contains(key.IpPrefix, ":")
+			fps.prefixes = append(fps.prefixes, fpx)
+		}
+		sort.Slice(fps.prefixes, func(i, j int) bool {
+			return fps.prefixes[i].prefix < fps.prefixes[j].prefix
+		})
+		fp.prefixSets = append(fp.prefixSets, fps)
+	}
+	return fp
+}
+
+// getIRBVLANID returns the vlan of the irb subinterface, the vlan of its mac-vrf
+func (r *fabricInterface) getIRBVLANID() uint16 {
+	if r.bridged != nil && r.bridged.vlanID != 0 {
+		return r.bridged.vlanID
+	}
+	return uint16(r.index)
+}
+
+// getVRFName returns the vrf of the routed subinterface, empty in the default network instance
+func (r *fabricInterface) getVRFName() string {
+	if r.routed == nil || r.routed.niType == ygotsrl.SrlNokiaNetworkInstance_NiType_default {
+		return ""
+	}
+	return r.routed.name
+}
+
+// getPortNumber returns the port number of an SR Linux ethernet interface, e.g. 1 for ethernet-1/1
+func getPortNumber(name string) (int, error) {
+	_, port, ok := strings.Cut(strings.TrimPrefix(name, "ethernet-"), "/")
+	if !ok {
+		return 0, fmt.Errorf("unsupported interface %s", name)
+	}
+	return strconv.Atoi(port)
+}
+
+// renderSONiC renders the config_db of SONiC; the ports follow the naming of the 4 lane ports of the
+// SONiC platforms, ethernet-1/1 is Ethernet0, ethernet-1/2 is Ethernet4
+func renderSONiC(fc *fabricConfig) ([]byte, error) {
+	db := map[string]map[string]map[string]string{}
+	set := func(table, key string, fields map[string]string) {
+		if _, ok := db[table]; !ok {
+			db[table] = map[string]map[string]string{}
+		}
+		if _, ok := db[table][key]; !ok {
+			db[table][key] = map[string]string{}
+		}
+		for k, v := range fields {
+			db[table][key][k] = v
+		}
+	}
+	vrfName := func(fi *fabricInterface) map[string]string {
+		if name := fi.getVRFName(); name != "" {
+			return map[string]string{"vrf_name": "Vrf-" + name}
+		}
+		return map[string]string{}
+	}
+
+	for _, ni := range fc.networkInstances {
+		switch ni.niType {
+		case ygotsrl.SrlNokiaNetworkInstance_NiType_ip_vrf:
+			set("VRF", "Vrf-"+ni.name, nil)
+		case ygotsrl.SrlNokiaNetworkInstance_NiType_mac_vrf:
+			if ni.vlanID == 0 {
+				return nil, fmt.Errorf("mac-vrf %s has no vlan", ni.name)
+			}
+			set("VLAN", fmt.Sprintf("Vlan%d", ni.vlanID), map[string]string{"vlanid": strconv.Itoa(int(ni.vlanID))})
+		}
+	}
+	for _, fi := range fc.interfaces {
+		var table, key string
+		switch fi.name {
+		case irbInterfaceName:
+			table, key = "VLAN_INTERFACE", fmt.Sprintf("Vlan%d", fi.getIRBVLANID())
+		case systemInterfaceName:
+			table, key = "LOOPBACK_INTERFACE", "Loopback0"
+		default:
+			port, err := getPortNumber(fi.name)
+			if err != nil {
+				return nil, err
+			}
+			key = fmt.Sprintf("Ethernet%d", (port-1)*4)
+			if fi.bridged != nil {
+				mode := "untagged"
+				if fi.vlanID != 0 {
+					mode = "tagged"
+				}
+				set("VLAN_MEMBER", fmt.Sprintf("Vlan%d|%s", fi.bridged.vlanID, key), map[string]string{"tagging_mode": mode})
+			}
+			table = "INTERFACE"
+			if fi.vlanID != 0 {
+				table, key = "VLAN_SUB_INTERFACE", fmt.Sprintf("%s.%d", key, fi.vlanID)
+			}
+		}
+		if fi.routed == nil {
+			continue
+		}
+		fields := vrfName(fi)
+		if table == "VLAN_SUB_INTERFACE" {
+			fields["admin_status"] = "up"
+		}
+		set(table, key, fields)
+		for _, a := range fi.addresses {
+			set(table, key+"|"+a, nil)
+		}
+	}
+
+	for _, fp := range fc.policies {
+		for i, ps := range fp.prefixSets {
+			mode := "IPv4"
+			if ps.ipv6 {
+				mode = "IPv6"
+			}
+			set("PREFIX_SET", ps.name, map[string]string{"mode": mode})
+			for j, px := range ps.prefixes {
+				set("PREFIX", fmt.Sprintf("%s|%d|%s|%d..%d", ps.name, (j+1)*10, px.prefix, px.minLength, px.maxLength), map[string]string{"action": "permit"})
+			}
+			set("ROUTE_MAP", fmt.Sprintf("%s|%d", fp.name, (i+1)*10), map[string]string{"route_operation": "permit", "match_prefix_set": ps.name})
+		}
+	}
+	for _, ni := range fc.networkInstances {
+		if ni.bgp == nil {
+			continue
+		}
+		vrf := "default"
+		if ni.niType != ygotsrl.SrlNokiaNetworkInstance_NiType_default {
+			vrf = "Vrf-" + ni.name
+		}
+		global := map[string]string{"local_asn": strconv.FormatUint(uint64(ni.bgp.asn), 10)}
+		if ni.bgp.routerID != "" {
+			global["router_id"] = ni.bgp.routerID
+		}
+		set("BGP_GLOBALS", vrf, global)
+		for _, n := range ni.bgp.neighbors {
+			key := vrf + "|" + n.address
+			set("BGP_NEIGHBOR", key, map[string]string{"asn": strconv.FormatUint(uint64(n.peerASN), 10), "admin_status": "up"})
+			af := "ipv4_unicast"
+			if strings.Contains(n.address, ":") {
+				af = "ipv6_unicast"
+			}
+			fields := map[string]string{"admin_status": "true"}
+			if n.importPolicy != "" {
+				fields["route_map_in"] = n.importPolicy
+			}
+			set("BGP_NEIGHBOR_AF", key+"|"+af, fields)
+		}
+	}
+	return json.MarshalIndent(db, "", "  ")
+}
+
+// eosConfig is the config of an Arista EOS device, the cli commands applied with eAPI
+type eosConfig struct {
+	Cmds []string `json:"cmds"`
+}
+
+// renderEOS renders the cli config of Arista EOS; ethernet-1/1 is Ethernet1
+func renderEOS(fc *fabricConfig) ([]byte, error) {
+	cmds := []string{}
+	for _, ni := range fc.networkInstances {
+		switch ni.niType {
+		case ygotsrl.SrlNokiaNetworkInstance_NiType_ip_vrf:
+			cmds = append(cmds, "vrf instance "+ni.name, "ip routing vrf "+ni.name)
+		case ygotsrl.SrlNokiaNetworkInstance_NiType_mac_vrf:
+			if ni.vlanID == 0 {
+				return nil, fmt.Errorf("mac-vrf %s has no vlan", ni.name)
+			}
+			cmds = append(cmds, fmt.Sprintf("vlan %d", ni.vlanID), "   name "+ni.name)
+		}
+	}
+	for _, fi := range fc.interfaces {
+		var name string
+		switch fi.name {
+		case irbInterfaceName:
+			name = fmt.Sprintf("Vlan%d", fi.getIRBVLANID())
+		case systemInterfaceName:
+			name = "Loopback0"
+		default:
+			port, err := getPortNumber(fi.name)
+			if err != nil {
+				return nil, err
+			}
+			name = fmt.Sprintf("Ethernet%d", port)
+			switch {
+			case fi.bridged != nil && fi.vlanID != 0:
+				cmds = append(cmds, "interface "+name, "   switchport", "   switchport mode trunk",
+					fmt.Sprintf("   switchport trunk allowed vlan add %d", fi.bridged.vlanID))
+			case fi.bridged != nil:
+				cmds = append(cmds, "interface "+name, "   switchport", fmt.Sprintf("   switchport access vlan %d", fi.bridged.vlanID))
+			case fi.routed != nil:
+				cmds = append(cmds, "interface "+name, "   no switchport")
+				if fi.vlanID != 0 {
+					name = fmt.Sprintf("%s.%d", name, fi.vlanID)
+					cmds = append(cmds, "interface "+name, fmt.Sprintf("   encapsulation dot1q vlan %d", fi.vlanID))
+				}
+			}
+		}
+		if fi.routed == nil {
+			continue
+		}
+		if fi.name == irbInterfaceName || fi.name == systemInterfaceName {
+			cmds = append(cmds, "interface "+name)
+		}
+		if vrf := fi.getVRFName(); vrf != "" {
+			cmds = append(cmds, "   vrf "+vrf)
+		}
+		for _, a := range fi.addresses {
+			af := "ip"
+			if strings.Contains(a, ":") {
+				af = "ipv6"
+			}
+			if fi.anycast {
+				cmds = append(cmds, fmt.Sprintf("   %s address virtual %s", af, a))
+			} else {
+				cmds = append(cmds, fmt.Sprintf("   %s address %s", af, a))
+			}
+		}
+	}
+
+	bgpCmds, err := renderEOSBGP(fc)
+	if err != nil {
+		return nil, err
+	}
+	cmds = append(cmds, bgpCmds...)
+	return json.MarshalIndent(eosConfig{Cmds: cmds}, "", "  ")
+}
+
+// renderEOSBGP renders the prefix lists, route maps and bgp neighbors of the network instances;
+// EOS runs a single bgp instance, the network instances share its autonomous system
+func renderEOSBGP(fc *fabricConfig) ([]string, error) {
+	cmds := []string{}
+	for _, fp := range fc.policies {
+		for _, ps := range fp.prefixSets {
+			af := "ip"
+			if ps.ipv6 {
+				af = "ipv6"
+			}
+			cmds = append(cmds, fmt.Sprintf("%s prefix-list %s", af, ps.name))
+			for j, px := range ps.prefixes {
+				cmd := fmt.Sprintf("   seq %d permit %s", (j+1)*10, px.prefix)
+				if p, err := netip.ParsePrefix(px.prefix); err == nil && px.minLength > p.Bits() {
+					cmd = fmt.Sprintf("%s ge %d", cmd, px.minLength)
+				}
+				cmds = append(cmds, fmt.Sprintf("%s le %d", cmd, px.maxLength))
+			}
+		}
+		for i, ps := range fp.prefixSets {
+			af := "ip"
+			if ps.ipv6 {
+				af = "ipv6"
+			}
+			cmds = append(cmds, fmt.Sprintf("route-map %s permit %d", fp.name, (i+1)*10),
+				fmt.Sprintf("   match %s address prefix-list %s", af, ps.name))
+		}
+	}
+
+	var asn uint32
+	bgpCmds := []string{}
+	for _, ni := range fc.networkInstances {
+		if ni.bgp == nil {
+			continue
+		}
+		if asn != 0 && asn != ni.bgp.asn {
+			return nil, fmt.Errorf("network instances with different bgp autonomous systems %d and %d", asn, ni.bgp.asn)
+		}
+		asn = ni.bgp.asn
+		indent := "   "
+		if ni.niType != ygotsrl.SrlNokiaNetworkInstance_NiType_default {
+			bgpCmds = append(bgpCmds, "   vrf "+ni.name)
+			indent = "      "
+		}
+		if ni.bgp.routerID != "" {
+			bgpCmds = append(bgpCmds, indent+"router-id "+ni.bgp.routerID)
+		}
+		ipv6 := []string{}
+		for _, n := range ni.bgp.neighbors {
+			bgpCmds = append(bgpCmds, fmt.Sprintf("%sneighbor %s remote-as %d", indent, n.address, n.peerASN))
+			if n.importPolicy != "" {
+				bgpCmds = append(bgpCmds, fmt.Sprintf("%sneighbor %s route-map %s in", indent, n.address, n.importPolicy))
+			}
+			if strings.Contains(n.address, ":") {
+				ipv6 = append(ipv6, n.address)
+			}
+		}
+		if len(ipv6) != 0 {
+			bgpCmds = append(bgpCmds, indent+"address-family ipv6")
+			for _, a := range ipv6 {
+				bgpCmds = append(bgpCmds, fmt.Sprintf("%s   neighbor %s activate", indent, a))
+			}
+		}
+	}
+	if asn == 0 {
+		return cmds, nil
+	}
+	cmds = append(cmds, fmt.Sprintf("router bgp %d", asn))
+	return append(cmds, bgpCmds...), nil
+}
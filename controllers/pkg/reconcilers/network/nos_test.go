@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henderiw-nephio/network/pkg/device"
+	reqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nokia/k8s-ipam/pkg/iputil"
+	"github.com/srl-labs/ygotsrl/v22"
+	"k8s.io/utils/pointer"
+)
+
+// getTestDevice returns a device with a bridged and irb vlan 10 in the rt1 ip-vrf and a routed
+// vlan 20 and loopback in the default network instance
+func getTestDevice() *ygotsrl.Device {
+	d := &device.Device{Device: new(ygotsrl.Device)}
+	d.AddBridgedInterface("bd1", "e1-1", 10, reqv1alpha1.AttachmentTypeVLAN)
+	d.AddBridgedInterface("bd1", device.IRBInterfaceName, 10, reqv1alpha1.AttachmentTypeNone)
+	d.AddRoutedInterface("rt1", device.IRBInterfaceName, 10, reqv1alpha1.AttachmentTypeNone, iputil.PrefixClaims{
+		false: []*string{pointer.String("10.0.0.1/24")},
+	})
+	d.AddRoutedInterface("default", "e1-2", 20, reqv1alpha1.AttachmentTypeVLAN, iputil.PrefixClaims{
+		false: []*string{pointer.String("192.168.0.0/31")},
+		true:  []*string{pointer.String("2001:db8::/127")},
+	})
+	d.AddRoutedInterface("default", device.SystemInterfaceName, 0, reqv1alpha1.AttachmentTypeNone, iputil.PrefixClaims{
+		false: []*string{pointer.String("100.64.0.1/32")},
+	})
+	return d.Device
+}
+
+func TestRenderSONiC(t *testing.T) {
+	b, err := renderConfig(sonicProvider, getTestDevice())
+	if err != nil {
+		t.Fatalf("renderConfig() error = %v", err)
+	}
+	got := map[string]map[string]map[string]string{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("renderConfig() invalid json: %v", err)
+	}
+	want := map[string]map[string]map[string]string{
+		"VRF":         {"Vrf-rt1": {}},
+		"VLAN":        {"Vlan10": {"vlanid": "10"}},
+		"VLAN_MEMBER": {"Vlan10|Ethernet0": {"tagging_mode": "tagged"}},
+		"VLAN_INTERFACE": {
+			"Vlan10":             {"vrf_name": "Vrf-rt1"},
+			"Vlan10|10.0.0.1/24": {},
+		},
+		"VLAN_SUB_INTERFACE": {
+			"Ethernet4.20":                {"admin_status": "up"},
+			"Ethernet4.20|192.168.0.0/31": {},
+			"Ethernet4.20|2001:db8::/127": {},
+		},
+		"LOOPBACK_INTERFACE": {
+			"Loopback0":               {},
+			"Loopback0|100.64.0.1/32": {},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("renderConfig() -want, +got:\n%s", diff)
+	}
+}
+
+func TestRenderEOS(t *testing.T) {
+	b, err := renderConfig(aristaEOSProvider, getTestDevice())
+	if err != nil {
+		t.Fatalf("renderConfig() error = %v", err)
+	}
+	got := eosConfig{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("renderConfig() invalid json: %v", err)
+	}
+	want := []string{
+		"vlan 10",
+		"   name bd1",
+		"vrf instance rt1",
+		"ip routing vrf rt1",
+		"interface Ethernet1",
+		"   switchport",
+		"   switchport mode trunk",
+		"   switchport trunk allowed vlan add 10",
+		"interface Ethernet2",
+		"   no switchport",
+		"interface Ethernet2.20",
+		"   encapsulation dot1q vlan 20",
+		"   ip address 192.168.0.0/31",
+		"   ipv6 address 2001:db8::/127",
+		"interface Vlan10",
+		"   vrf rt1",
+		"   ip address virtual 10.0.0.1/24",
+		"interface Loopback0",
+		"   ip address 100.64.0.1/32",
+	}
+	if diff := cmp.Diff(want, got.Cmds); diff != "" {
+		t.Errorf("renderConfig() -want, +got:\n%s", diff)
+	}
+}
+
+func TestRenderSRL(t *testing.T) {
+	b, err := renderConfig(nokiaSRLProvider, getTestDevice())
+	if err != nil {
+		t.Fatalf("renderConfig() error = %v", err)
+	}
+	if !json.Valid(b) {
+		t.Errorf("renderConfig() invalid json: %s", string(b))
+	}
+}
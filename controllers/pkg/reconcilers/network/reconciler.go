@@ -29,6 +29,7 @@ import (
 	"github.com/henderiw-nephio/network/pkg/network"
 	"github.com/henderiw-nephio/network/pkg/nodes"
 	"github.com/henderiw-nephio/network/pkg/resources"
+	"github.com/nephio-project/nephio/controllers/pkg/metrics"
 	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 
 	//"github.com/henderiw-nephio/network/pkg/targets"
@@ -36,19 +37,21 @@ import (
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/nephio-project/nephio/controllers/pkg/sharding"
 	invv1alpha1 "github.com/nokia/k8s-ipam/apis/inv/v1alpha1"
 	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
 	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
 	"github.com/nokia/k8s-ipam/pkg/meta"
 	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy"
-	"github.com/openconfig/ygot/ygot"
 
 	"github.com/pkg/errors"
 	"github.com/srl-labs/ygotsrl/v22"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -74,6 +77,7 @@ const (
 //+kubebuilder:rbac:groups=ipam.resource.nephio.org,resources=networkinstances/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ipam.resource.nephio.org,resources=ipprefixes,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=ipam.resource.nephio.org,resources=ipprefixes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=ipam.resource.nephio.org,resources=ipclaims,verbs=get;list;watch
 //+kubebuilder:rbac:groups=config.resource.nephio.org,resources=networks,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=config.resource.nephio.org,resources=networks/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=inv.nephio.org,resources=endpoints,verbs=get;list;watch;create;update;patch;delete
@@ -118,7 +122,8 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 		Owns(&configv1alpha1.Network{}).
 		Watches(&invv1alpha1.Endpoint{}, &endpointEventHandler{client: mgr.GetClient()}).
 		Watches(&invv1alpha1.Endpoint{}, &nodeEventHandler{client: mgr.GetClient()}).
-		Complete(r)
+		Watches(&ipamv1alpha1.IPClaim{}, &ipClaimEventHandler{client: mgr.GetClient()}).
+		Complete(sharding.NewReconciler(cfg.Shard, metrics.NewReconciler("NetworkController", r)))
 
 }
 
@@ -227,11 +232,9 @@ func getMatchingNodeLabels(cr client.Object, nodeName string) client.MatchingLab
 }
 
 func (r *reconciler) getProviderEndpoints(ctx context.Context, topology string) (*endpoints.Endpoints, error) {
-	opts := []client.ListOption{
-		client.MatchingLabels{
-			invv1alpha1.NephioProviderKey: nokiaSRLProvider,
-			invv1alpha1.NephioTopologyKey: topology,
-		},
+	opts, err := getProviderListOptions(topology)
+	if err != nil {
+		return nil, err
 	}
 	eps := &invv1alpha1.EndpointList{}
 	if err := r.List(ctx, eps, opts...); err != nil {
@@ -242,11 +245,9 @@ func (r *reconciler) getProviderEndpoints(ctx context.Context, topology string)
 }
 
 func (r *reconciler) getProviderNodes(ctx context.Context, topology string) (*nodes.Nodes, error) {
-	opts := []client.ListOption{
-		client.MatchingLabels{
-			invv1alpha1.NephioProviderKey: nokiaSRLProvider,
-			invv1alpha1.NephioTopologyKey: topology,
-		},
+	opts, err := getProviderListOptions(topology)
+	if err != nil {
+		return nil, err
 	}
 	nos := &invv1alpha1.NodeList{}
 	if err := r.List(ctx, nos, opts...); err != nil {
@@ -256,6 +257,21 @@ func (r *reconciler) getProviderNodes(ctx context.Context, topology string) (*no
 	return &nodes.Nodes{NodeList: nos}, nil
 }
 
+// getProviderListOptions selects the inventory of the topology with a supported provider
+func getProviderListOptions(topology string) ([]client.ListOption, error) {
+	providerReq, err := labels.NewRequirement(invv1alpha1.NephioProviderKey, selection.In, supportedProviders)
+	if err != nil {
+		return nil, err
+	}
+	topologyReq, err := labels.NewRequirement(invv1alpha1.NephioTopologyKey, selection.Equals, []string{topology})
+	if err != nil {
+		return nil, err
+	}
+	return []client.ListOption{
+		client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*providerReq, *topologyReq)},
+	}, nil
+}
+
 func (r *reconciler) applyInitialresources(ctx context.Context, cr *infrav1alpha1.Network, eps *endpoints.Endpoints, nodes *nodes.Nodes) error {
 	n := network.New(&network.Config{
 		Config:    &infra2v1alpha1.NetworkConfig{},
@@ -294,6 +310,26 @@ func (r *reconciler) getNewResources(ctx context.Context, cr *infrav1alpha1.Netw
 		return err
 	}
 
+	// the eBGP peerings of the routing tables toward the NFs
+	peerings, err := getBGPPeerings(cr)
+	if err != nil {
+		return err
+	}
+	for _, peering := range peerings {
+		peers, err := r.getBGPPeers(ctx, cr, peering)
+		if err != nil {
+			r.l.Error(err, "cannot get bgp peers", "routingTable", peering.RoutingTable)
+			return err
+		}
+		r.l.Info("bgp peering", "routingTable", peering.RoutingTable, "peers", peers)
+		for nodeName, device := range n.GetDevices() {
+			if err := addBGPPeering(device, peering, peers, getRoutingTable(cr, peering.RoutingTable).Prefixes); err != nil {
+				r.l.Error(err, "cannot add bgp peering", "nodeName", nodeName, "routingTable", peering.RoutingTable)
+				return err
+			}
+		}
+	}
+
 	// list all networkConfigs
 	opts := []client.ListOption{
 		resourcev1alpha1.GetOwnerLabelsFromCR(cr),
@@ -308,31 +344,39 @@ func (r *reconciler) getNewResources(ctx context.Context, cr *infrav1alpha1.Netw
 		networkConfigs[nc.Name] = nc
 	}
 
+	// the provider of the node selects the NOS of its config
+	providers := map[string]string{}
+	for _, node := range nodes.GetNodes() {
+		providers[node.Name] = node.Spec.Provider
+		if provider, ok := node.Labels[invv1alpha1.NephioProviderKey]; ok {
+			providers[node.Name] = provider
+		}
+	}
+
 	for nodeName, device := range n.GetDevices() {
-		r.l.Info("node config", "nodeName", nodeName)
+		provider, ok := providers[nodeName]
+		if !ok {
+			provider = nokiaSRLProvider
+		}
+		r.l.Info("node config", "nodeName", nodeName, "provider", provider)
 
-		j, err := ygot.EmitJSON(device, &ygot.EmitJSONConfig{
-			Format: ygot.RFC7951,
-			Indent: "  ",
-			RFC7951Config: &ygot.RFC7951JSONConfig{
-				AppendModuleName: true,
-			},
-			SkipValidation: false,
-		})
+		j, err := renderConfig(provider, device)
 		if err != nil {
 			r.l.Error(err, "cannot construct json device info")
 			return err
 		}
+		nodeLabels := getMatchingNodeLabels(cr, nodeName)
+		nodeLabels[invv1alpha1.NephioProviderKey] = provider
 
 		o := configv1alpha1.BuildNetworkConfig(
 			metav1.ObjectMeta{
 				Name:            fmt.Sprintf("%s-%s", cr.Name, nodeName),
 				Namespace:       cr.Namespace,
-				Labels:          getMatchingNodeLabels(cr, nodeName),
+				Labels:          nodeLabels,
 				OwnerReferences: []metav1.OwnerReference{{APIVersion: cr.APIVersion, Kind: cr.Kind, Name: cr.Name, UID: cr.UID, Controller: pointer.Bool(true)}},
 			}, configv1alpha1.NetworkSpec{
 				Config: runtime.RawExtension{
-					Raw: []byte(j),
+					Raw: j,
 				},
 			}, configv1alpha1.NetworkStatus{})
 		if existingNetwNodeConfig, ok := networkConfigs[fmt.Sprintf("%s-%s", cr.Name, nodeName)]; ok {
@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type ipClaimEventHandler struct {
+	client client.Client
+	l      logr.Logger
+}
+
+// Create enqueues a request for the networks peering with the claims of the network instance
+func (e *ipClaimEventHandler) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.add(ctx, evt.Object, q)
+}
+
+// Update enqueues a request for the networks peering with the claims of the network instance
+func (e *ipClaimEventHandler) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.add(ctx, evt.ObjectOld, q)
+	e.add(ctx, evt.ObjectNew, q)
+}
+
+// Delete enqueues a request for the networks peering with the claims of the network instance
+func (e *ipClaimEventHandler) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(ctx, evt.Object, q)
+}
+
+// Generic enqueues a request for the networks peering with the claims of the network instance
+func (e *ipClaimEventHandler) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.add(ctx, evt.Object, q)
+}
+
+func (e *ipClaimEventHandler) add(ctx context.Context, obj runtime.Object, queue adder) {
+	cr, ok := obj.(*ipamv1alpha1.IPClaim)
+	if !ok {
+		return
+	}
+	e.l = log.FromContext(ctx)
+
+	networks := &infrav1alpha1.NetworkList{}
+	if err := e.client.List(ctx, networks, client.InNamespace(cr.GetNamespace())); err != nil {
+		return
+	}
+
+	for _, network := range networks.Items {
+		// only enqueue if the network peers with the claims of the routing table of the claim
+		peerings, err := getBGPPeerings(&network)
+		if err != nil {
+			continue
+		}
+		for _, peering := range peerings {
+			if peering.RoutingTable == cr.Spec.NetworkInstance.Name {
+				e.l.Info("event requeue network", "name", network.GetName(), "ipclaim", cr.GetName())
+				queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+					Namespace: network.GetNamespace(),
+					Name:      network.GetName()}})
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package packagecatalog
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/nephio-project/nephio/controllers/pkg/catalog"
+	"github.com/nephio-project/nephio/controllers/pkg/metrics"
+	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
+	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func init() {
+	reconcilerinterface.Register("packagecatalogs", &reconciler{})
+}
+
+//+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions,verbs=get;list;watch
+//+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisionresources,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.porch.kpt.dev,resources=repositories,verbs=get;list;watch
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c any) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
+	cfg, ok := c.(*ctrlconfig.ControllerConfig)
+	if !ok {
+		return nil, fmt.Errorf("cannot initialize, expecting controllerConfig, got: %s", reflect.TypeOf(c).Name())
+	}
+	if cfg.Catalog == nil {
+		return nil, fmt.Errorf("cannot initialize, expecting a catalog in the controllerConfig")
+	}
+
+	if err := porchv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
+	if err := porchconfigv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
+
+	r.Client = mgr.GetClient()
+	r.porchClient = cfg.PorchClient
+	r.catalog = cfg.Catalog
+
+	// the catalog is served by every replica, so every replica indexes all the packages: the
+	// controller is neither sharded nor leader elected
+	return nil, ctrl.NewControllerManagedBy(mgr).
+		Named("PackageCatalogController").
+		WithOptions(controller.Options{NeedLeaderElection: pointer.Bool(false)}).
+		For(&porchv1alpha1.PackageRevision{}).
+		Complete(metrics.NewReconciler("PackageCatalogController", r))
+}
+
+type reconciler struct {
+	client.Client
+	porchClient client.Client
+	catalog     *catalog.Catalog
+
+	l logr.Logger
+}
+
+// Reconcile indexes the published package revisions of the blueprint repositories in the catalog
+func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.l = log.FromContext(ctx)
+	cr := &porchv1alpha1.PackageRevision{}
+	if err := r.Get(ctx, req.NamespacedName, cr); err != nil {
+		// There's no need to requeue if we no longer exist. Otherwise we'll be
+		// requeued implicitly because we return an error.
+		if resource.IgnoreNotFound(err) != nil {
+			msg := "cannot get resource"
+			r.l.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(resource.IgnoreNotFound(err), msg)
+		}
+		r.catalog.Delete(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	// only the published revisions are blueprints
+	if !porchv1alpha1.LifecycleIsPublished(cr.Spec.Lifecycle) {
+		r.catalog.Delete(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+	// the content of a published revision does not change, only whether it is the latest
+	if e, ok := r.catalog.Get(req.NamespacedName); ok {
+		e.Latest = cr.GetLabels()[porchv1alpha1.LatestPackageRevisionKey] == porchv1alpha1.LatestPackageRevisionValue
+		r.catalog.Set(e)
+		return ctrl.Result{}, nil
+	}
+	repo := &porchconfigv1alpha1.Repository{}
+	if err := r.porchClient.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.Spec.RepositoryName}, repo); err != nil {
+		msg := "cannot get repository"
+		r.l.Error(err, msg, "repository", cr.Spec.RepositoryName)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	// the packages of the deployment repositories are specialized packages, not blueprints
+	if repo.Spec.Deployment {
+		return ctrl.Result{}, nil
+	}
+
+	prr := &porchv1alpha1.PackageRevisionResources{}
+	if err := r.porchClient.Get(ctx, req.NamespacedName, prr); err != nil {
+		msg := "cannot get package revision resources"
+		r.l.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	e, err := catalog.NewEntry(cr, prr.Spec.Resources)
+	if err != nil {
+		msg := "cannot index package"
+		r.l.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	r.catalog.Set(e)
+	r.l.Info("package indexed", "repository", e.Repository, "package", e.Package, "revision", e.Revision)
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	commonv1alpha1 "github.com/nephio-project/api/common/v1alpha1"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"github.com/pkg/errors"
+)
+
+const (
+	// deletionPolicyKey is the annotation of the repository overriding the deletion policy of the lifecycle
+	// of its spec with delete, archive or orphan
+	deletionPolicyKey = "nephio.org/deletion-policy"
+	// deletionProtectionKey is the annotation of the repository archiving the repo instead of deleting it when "true"
+	deletionProtectionKey = "nephio.org/deletion-protection"
+	// retentionKey is the annotation of the repository delaying the deletion of the repo by the duration, the
+	// repo is archived in the meantime
+	retentionKey = "nephio.org/retention"
+	// protectedBranchesKey is the annotation of the repository with the comma separated branches protected
+	// against force pushes and deletion
+	protectedBranchesKey = "nephio.org/protected-branches"
+
+	deletionArchive commonv1alpha1.DeletionPolicy = "archive"
+)
+
+// lifecyclePolicy defines what happens to the repo when the repository is deleted
+type lifecyclePolicy struct {
+	deletionPolicy commonv1alpha1.DeletionPolicy
+	protected      bool
+	retention      time.Duration
+}
+
+func getLifecyclePolicy(cr *infrav1alpha1.Repository) (*lifecyclePolicy, error) {
+	annotations := cr.GetAnnotations()
+	p := &lifecyclePolicy{
+		deletionPolicy: cr.Spec.Lifecycle.DeletionPolicy,
+		protected:      annotations[deletionProtectionKey] == "true",
+	}
+	if dp, ok := annotations[deletionPolicyKey]; ok {
+		p.deletionPolicy = commonv1alpha1.DeletionPolicy(dp)
+	}
+	switch p.deletionPolicy {
+	case "", commonv1alpha1.DeletionOrphan, commonv1alpha1.DeletionDelete, deletionArchive:
+	default:
+		return nil, fmt.Errorf("deletion policy %s not supported, supported policies: [%s, %s, %s]",
+			p.deletionPolicy, commonv1alpha1.DeletionDelete, deletionArchive, commonv1alpha1.DeletionOrphan)
+	}
+	if r, ok := annotations[retentionKey]; ok {
+		retention, err := time.ParseDuration(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid retention")
+		}
+		p.retention = retention
+	}
+	return p, nil
+}
+
+// finalizeRepo applies the lifecycle policy to the repo of the deleted repository, it returns the time
+// after which the repo is deleted when retained
+func (r *reconciler) finalizeRepo(ctx context.Context, gitProvider gitprovider.Provider, cr *infrav1alpha1.Repository) (time.Duration, error) {
+	p, err := getLifecyclePolicy(cr)
+	if err != nil {
+		r.l.Error(err, "invalid lifecycle policy")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return 0, err
+	}
+	switch p.deletionPolicy {
+	case deletionArchive:
+		return 0, r.archiveRepo(ctx, gitProvider, cr)
+	case commonv1alpha1.DeletionDelete:
+		if p.protected {
+			r.l.Info("repo protected against deletion, archiving it", "name", cr.GetName())
+			return 0, r.archiveRepo(ctx, gitProvider, cr)
+		}
+		if cr.GetDeletionTimestamp() != nil && p.retention > 0 {
+			if remaining := time.Until(cr.GetDeletionTimestamp().Add(p.retention)); remaining > 0 {
+				r.l.Info("repo retained", "name", cr.GetName(), "remaining", remaining.String())
+				return remaining, r.archiveRepo(ctx, gitProvider, cr)
+			}
+		}
+		return 0, r.deleteRepo(ctx, gitProvider, cr)
+	default:
+		// orphan: the repo is left in the git server
+		return 0, nil
+	}
+}
+
+func (r *reconciler) archiveRepo(ctx context.Context, gitProvider gitprovider.Provider, cr *infrav1alpha1.Repository) error {
+	if err := gitProvider.ArchiveRepo(ctx, cr.GetName()); err != nil && !errors.Is(err, gitprovider.ErrNotFound) {
+		r.l.Error(err, "cannot archive repo")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	r.l.Info("repo archived", "name", cr.GetName())
+	return nil
+}
+
+// protectBranches protects the branches of the annotation of the repository
+func (r *reconciler) protectBranches(ctx context.Context, gitProvider gitprovider.Provider, cr *infrav1alpha1.Repository) error {
+	for _, branch := range getProtectedBranches(cr) {
+		if err := gitProvider.ProtectBranch(ctx, cr.GetName(), branch); err != nil {
+			r.l.Error(err, "cannot protect branch", "branch", branch)
+			cr.SetConditions(infrav1alpha1.Failed(fmt.Sprintf("cannot protect branch %s", branch)))
+			return err
+		}
+	}
+	return nil
+}
+
+func getProtectedBranches(cr *infrav1alpha1.Repository) []string {
+	branches := []string{}
+	for _, b := range strings.Split(cr.GetAnnotations()[protectedBranchesKey], ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			branches = append(branches, b)
+		}
+	}
+	return branches
+}
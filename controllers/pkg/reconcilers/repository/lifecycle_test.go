@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	commonv1alpha1 "github.com/nephio-project/api/common/v1alpha1"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetLifecyclePolicy(t *testing.T) {
+	cases := map[string]struct {
+		deletionPolicy commonv1alpha1.DeletionPolicy
+		annotations    map[string]string
+		want           *lifecyclePolicy
+		wantError      bool
+	}{
+		"Spec": {
+			deletionPolicy: commonv1alpha1.DeletionDelete,
+			want:           &lifecyclePolicy{deletionPolicy: commonv1alpha1.DeletionDelete},
+		},
+		"Annotations": {
+			deletionPolicy: commonv1alpha1.DeletionOrphan,
+			annotations: map[string]string{
+				deletionPolicyKey:     "delete",
+				deletionProtectionKey: "true",
+				retentionKey:          "24h",
+			},
+			want: &lifecyclePolicy{deletionPolicy: commonv1alpha1.DeletionDelete, protected: true, retention: 24 * time.Hour},
+		},
+		"Archive": {
+			annotations: map[string]string{deletionPolicyKey: "archive"},
+			want:        &lifecyclePolicy{deletionPolicy: deletionArchive},
+		},
+		"InvalidPolicy": {
+			annotations: map[string]string{deletionPolicyKey: "purge"},
+			wantError:   true,
+		},
+		"InvalidRetention": {
+			annotations: map[string]string{retentionKey: "1 day"},
+			wantError:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &infrav1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "edge01", Annotations: tc.annotations},
+				Spec: infrav1alpha1.RepositorySpec{
+					Lifecycle: commonv1alpha1.Lifecycle{DeletionPolicy: tc.deletionPolicy},
+				},
+			}
+			got, err := getLifecyclePolicy(cr)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("getLifecyclePolicy() error = %v, wantError %v", err, tc.wantError)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(lifecyclePolicy{})); diff != "" {
+				t.Errorf("getLifecyclePolicy() -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetProtectedBranches(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		want        []string
+	}{
+		"None": {
+			want: []string{},
+		},
+		"Branches": {
+			annotations: map[string]string{protectedBranchesKey: "main, deploy,"},
+			want:        []string{"main", "deploy"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getProtectedBranches(&infrav1alpha1.Repository{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("getProtectedBranches() -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// porchRegistrationKey is the annotation of the repository registering the repo in porch when "true"
+	porchRegistrationKey = "nephio.org/porch-registration"
+	// porchDeploymentKey is the annotation of the repository registering a deployment repository in porch when "true"
+	porchDeploymentKey = "nephio.org/porch-deployment"
+	// porchBranchKey is the annotation of the repository overriding the branch of the packages, by default the default branch
+	porchBranchKey = "nephio.org/porch-branch"
+	// porchDirectoryKey is the annotation of the repository overriding the directory of the packages, by default the root directory
+	porchDirectoryKey = "nephio.org/porch-directory"
+	// porchSecretKey is the annotation of the repository overriding the name of the auth secret, by default the secret of
+	// the <repo>-access-token-porch token
+	porchSecretKey = "nephio.org/porch-secret"
+
+	defaultBranch = "main"
+	// porchSecretSuffix is the suffix of the name of the token created for porch per repository
+	porchSecretSuffix = "-access-token-porch"
+)
+
+// upsertPorchRepository registers the repo in porch with a Repository owned by the repo CR,
+// such that porch manages the packages of the repo without a manual registration
+func (r *reconciler) upsertPorchRepository(ctx context.Context, cr *infrav1alpha1.Repository) error {
+	if cr.GetAnnotations()[porchRegistrationKey] != "true" {
+		return nil
+	}
+	repo, err := buildPorchRepository(cr)
+	if err != nil {
+		r.l.Error(err, "cannot register repo in porch")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	if err := r.Apply(ctx, repo); err != nil {
+		r.l.Error(err, "cannot register repo in porch")
+		cr.SetConditions(infrav1alpha1.Failed("cannot register repo in porch"))
+		return err
+	}
+	r.l.Info("repo registered in porch", "name", repo.GetName())
+	return nil
+}
+
+// buildPorchRepository returns the porch Repository of the repo CR
+func buildPorchRepository(cr *infrav1alpha1.Repository) (*porchconfigv1alpha1.Repository, error) {
+	if cr.Status.URL == nil {
+		return nil, fmt.Errorf("repo %s has no url", cr.GetName())
+	}
+	annotations := cr.GetAnnotations()
+	branch := defaultBranch
+	if cr.Spec.DefaultBranch != nil && *cr.Spec.DefaultBranch != "" {
+		branch = *cr.Spec.DefaultBranch
+	}
+	if b, ok := annotations[porchBranchKey]; ok {
+		branch = b
+	}
+	directory := "/"
+	if d, ok := annotations[porchDirectoryKey]; ok {
+		directory = d
+	}
+	secretName := cr.GetName() + porchSecretSuffix
+	if s, ok := annotations[porchSecretKey]; ok {
+		secretName = s
+	}
+	description := ""
+	if cr.Spec.Description != nil {
+		description = *cr.Spec.Description
+	}
+
+	return &porchconfigv1alpha1.Repository{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: porchconfigv1alpha1.GroupVersion.Identifier(),
+			Kind:       reflect.TypeOf(porchconfigv1alpha1.Repository{}).Name(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cr.GetNamespace(),
+			Name:      cr.GetName(),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: infrav1alpha1.GroupVersion.Identifier(),
+					Kind:       infrav1alpha1.RepositoryKind,
+					Name:       cr.Name,
+					UID:        cr.UID,
+					Controller: pointer.Bool(true),
+				},
+			},
+		},
+		Spec: porchconfigv1alpha1.RepositorySpec{
+			Description: description,
+			Deployment:  annotations[porchDeploymentKey] == "true",
+			Type:        porchconfigv1alpha1.RepositoryTypeGit,
+			Content:     porchconfigv1alpha1.RepositoryContentPackage,
+			Git: &porchconfigv1alpha1.GitRepository{
+				Repo:      *cr.Status.URL,
+				Branch:    branch,
+				Directory: directory,
+				SecretRef: porchconfigv1alpha1.SecretRef{Name: secretName},
+			},
+		},
+	}, nil
+}
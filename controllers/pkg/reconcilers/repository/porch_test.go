@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"testing"
+
+	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestBuildPorchRepository(t *testing.T) {
+	cases := map[string]struct {
+		annotations   map[string]string
+		defaultBranch *string
+		url           *string
+		want          *porchconfigv1alpha1.GitRepository
+		wantDeploy    bool
+		wantError     bool
+	}{
+		"NoURL": {
+			url:       nil,
+			wantError: true,
+		},
+		"Defaults": {
+			url: pointer.String("http://172.18.0.200:3000/nephio/edge01.git"),
+			want: &porchconfigv1alpha1.GitRepository{
+				Repo:      "http://172.18.0.200:3000/nephio/edge01.git",
+				Branch:    "main",
+				Directory: "/",
+				SecretRef: porchconfigv1alpha1.SecretRef{Name: "edge01-access-token-porch"},
+			},
+		},
+		"DefaultBranch": {
+			url:           pointer.String("http://172.18.0.200:3000/nephio/edge01.git"),
+			defaultBranch: pointer.String("master"),
+			want: &porchconfigv1alpha1.GitRepository{
+				Repo:      "http://172.18.0.200:3000/nephio/edge01.git",
+				Branch:    "master",
+				Directory: "/",
+				SecretRef: porchconfigv1alpha1.SecretRef{Name: "edge01-access-token-porch"},
+			},
+		},
+		"Annotations": {
+			annotations: map[string]string{
+				porchDeploymentKey: "true",
+				porchBranchKey:     "deploy",
+				porchDirectoryKey:  "packages",
+				porchSecretKey:     "git-user-secret",
+			},
+			url:           pointer.String("http://172.18.0.200:3000/nephio/edge01.git"),
+			defaultBranch: pointer.String("master"),
+			want: &porchconfigv1alpha1.GitRepository{
+				Repo:      "http://172.18.0.200:3000/nephio/edge01.git",
+				Branch:    "deploy",
+				Directory: "packages",
+				SecretRef: porchconfigv1alpha1.SecretRef{Name: "git-user-secret"},
+			},
+			wantDeploy: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &infrav1alpha1.Repository{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "edge01", Annotations: tc.annotations},
+				Spec:       infrav1alpha1.RepositorySpec{DefaultBranch: tc.defaultBranch},
+				Status:     infrav1alpha1.RepositoryStatus{URL: tc.url},
+			}
+			got, err := buildPorchRepository(cr)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("buildPorchRepository() error = %v, wantError %v", err, tc.wantError)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want, got.Spec.Git); diff != "" {
+				t.Errorf("buildPorchRepository() -want, +got:\n%s", diff)
+			}
+			if got.Spec.Deployment != tc.wantDeploy {
+				t.Errorf("buildPorchRepository() deployment = %t, want %t", got.Spec.Deployment, tc.wantDeploy)
+			}
+			if got.GetNamespace() != "default" || got.OwnerReferences[0].Kind != infrav1alpha1.RepositoryKind {
+				t.Errorf("buildPorchRepository() not owned by the repo: %v", got.ObjectMeta)
+			}
+		})
+	}
+}
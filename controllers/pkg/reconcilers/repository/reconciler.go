@@ -21,16 +21,19 @@ import (
 	"fmt"
 	"reflect"
 
-	"code.gitea.io/sdk/gitea"
+	porchconfigv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porchconfig/v1alpha1"
 	"github.com/go-logr/logr"
-	commonv1alpha1 "github.com/nephio-project/api/common/v1alpha1"
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
-	"github.com/nephio-project/nephio/controllers/pkg/giteaclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"github.com/nephio-project/nephio/controllers/pkg/metrics"
 	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/nephio-project/nephio/controllers/pkg/sharding"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -43,6 +46,12 @@ func init() {
 
 const (
 	finalizer = "infra.nephio.org/finalizer"
+	// deployKeySecretKey is the annotation of the repository referencing the secret of its deploy key
+	deployKeySecretKey = "nephio.org/deploy-key-secret"
+	// deployKeyReadOnlyKey is the annotation of the repository restricting its deploy key to pulls when "true"
+	deployKeyReadOnlyKey = "nephio.org/deploy-key-read-only"
+	// deployKeyDataKey is the key of the public ssh key in the data of the deploy key secret
+	deployKeyDataKey = "ssh-publickey"
 	// errors
 	errGetCr        = "cannot get cr"
 	errUpdateStatus = "cannot update status"
@@ -50,16 +59,18 @@ const (
 
 //+kubebuilder:rbac:groups=infra.nephio.org,resources=repositories,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infra.nephio.org,resources=repositories/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="*",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.porch.kpt.dev,resources=repositories,verbs=get;list;watch;create;update;patch;delete
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c interface{}) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
 	cfg, ok := c.(*ctrlconfig.ControllerConfig)
-	// Sending the porchclient to gitea, this will be used to get
-	// the secret objects for gitea client authentication. The client
+	// Sending the porchclient to the git provider, this will be used to get
+	// the secret objects for git client authentication. The client
 	// of the manager of this controller cannot be used at this point.
 	// Should this be conditional ? Only if we have repo/token reconciler
-	r.giteaClient = giteaclient.New(resource.NewAPIPatchingApplicator(cfg.PorchClient))
-	go r.giteaClient.Start(ctx)
+	r.gitClient = gitprovider.New(resource.NewAPIPatchingApplicator(cfg.PorchClient))
+	go r.gitClient.Start(ctx)
 
 	if !ok {
 		return nil, fmt.Errorf("cannot initialize, expecting controllerConfig, got: %s", reflect.TypeOf(c).Name())
@@ -68,6 +79,9 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 	if err := infrav1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
 		return nil, err
 	}
+	if err := porchconfigv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
 
 	r.APIPatchingApplicator = resource.NewAPIPatchingApplicator(mgr.GetClient())
 	r.finalizer = resource.NewAPIFinalizer(mgr.GetClient(), finalizer)
@@ -75,13 +89,14 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("RepositoryController").
 		For(&infrav1alpha1.Repository{}).
-		Complete(r)
+		Owns(&porchconfigv1alpha1.Repository{}).
+		Complete(sharding.NewReconciler(cfg.Shard, metrics.NewReconciler("RepositoryController", r)))
 }
 
 type reconciler struct {
 	resource.APIPatchingApplicator
-	giteaClient giteaclient.GiteaClient
-	finalizer   *resource.APIFinalizer
+	gitClient gitprovider.Client
+	finalizer *resource.APIFinalizer
 
 	l logr.Logger
 }
@@ -101,24 +116,25 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	// check if client exists otherwise retry
-	giteaClient := r.giteaClient.Get()
-	if giteaClient == nil {
-		err := fmt.Errorf("gitea server unreachable")
+	gitProvider := r.gitClient.Get()
+	if gitProvider == nil {
+		err := fmt.Errorf("git server unreachable")
 		r.l.Error(err, "cannot connect to git server")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
 	if resource.WasDeleted(cr) {
-		// TODO DELETION POLICY: "orphan" deletion policy
 		// repo being deleted
-		// Delete the repo from the git server
+		// Delete, archive or orphan the repo in the git server following the lifecycle policy
 		// when successful remove the finalizer
-		if cr.Spec.Lifecycle.DeletionPolicy == commonv1alpha1.DeletionDelete {
-			if err := r.deleteRepo(ctx, giteaClient, cr); err != nil {
-				r.l.Error(err, "cannot delete repo in git server")
-				return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
-			}
+		retained, err := r.finalizeRepo(ctx, gitProvider, cr)
+		if err != nil {
+			r.l.Error(err, "cannot finalize repo in git server")
+			return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
+		if retained > 0 {
+			return ctrl.Result{RequeueAfter: retained}, nil
 		}
 
 		if err := r.finalizer.RemoveFinalizer(ctx, cr); err != nil {
@@ -139,53 +155,46 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	// upsert repo in git server
-	if err := r.upsertRepo(ctx, giteaClient, cr); err != nil {
+	if err := r.upsertRepo(ctx, gitProvider, cr); err != nil {
+		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+	// protect the branches of the repo
+	if err := r.protectBranches(ctx, gitProvider, cr); err != nil {
+		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+	// register repo in porch
+	if err := r.upsertPorchRepository(ctx, cr); err != nil {
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 	cr.SetConditions(infrav1alpha1.Ready())
 	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 }
 
-func (r *reconciler) upsertRepo(ctx context.Context, giteaClient *gitea.Client, cr *infrav1alpha1.Repository) error {
-	u, _, err := giteaClient.GetMyUserInfo()
-	if err != nil {
-		r.l.Error(err, "cannot get user info")
-		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-		return err
-	}
-
-	_, _, err = giteaClient.GetRepo(u.UserName, cr.GetName())
+func (r *reconciler) upsertRepo(ctx context.Context, gitProvider gitprovider.Provider, cr *infrav1alpha1.Repository) error {
+	_, err := gitProvider.GetRepo(ctx, cr.GetName())
 	if err != nil {
-		// create repo
-		createRepo := gitea.CreateRepoOption{Name: cr.GetName()}
-		if cr.Spec.Description != nil {
-			createRepo.Description = *cr.Spec.Description
-		}
-		if cr.Spec.Private != nil {
-			createRepo.Private = *cr.Spec.Private
-		}
-		if cr.Spec.IssueLabels != nil {
-			createRepo.IssueLabels = *cr.Spec.IssueLabels
-		}
-		if cr.Spec.Gitignores != nil {
-			createRepo.Gitignores = *cr.Spec.Gitignores
-		}
-		if cr.Spec.License != nil {
-			createRepo.License = *cr.Spec.License
-		}
-		if cr.Spec.Readme != nil {
-			createRepo.Readme = *cr.Spec.Readme
+		if !errors.Is(err, gitprovider.ErrNotFound) {
+			r.l.Error(err, "cannot get repo")
+			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+			return err
 		}
-		if cr.Spec.DefaultBranch != nil {
-			createRepo.DefaultBranch = *cr.Spec.DefaultBranch
+		// create repo
+		createRepo := gitprovider.RepositoryOptions{
+			Name:          cr.GetName(),
+			Description:   cr.Spec.Description,
+			Private:       cr.Spec.Private,
+			IssueLabels:   cr.Spec.IssueLabels,
+			Gitignores:    cr.Spec.Gitignores,
+			License:       cr.Spec.License,
+			Readme:        cr.Spec.Readme,
+			DefaultBranch: cr.Spec.DefaultBranch,
 		}
 		if cr.Spec.TrustModel != nil {
-			createRepo.TrustModel = gitea.TrustModel(*cr.Spec.TrustModel)
+			createRepo.TrustModel = pointer.String(string(*cr.Spec.TrustModel))
 		}
-		createRepo.AutoInit = true
 		r.l.Info("repository", "config", createRepo)
 
-		repo, _, err := giteaClient.CreateRepo(createRepo)
+		repo, err := gitProvider.CreateRepo(ctx, createRepo)
 		if err != nil {
 			r.l.Error(err, "cannot create repo")
 			// Here we don't provide the full error since the message change every time and this will re-trigger
@@ -195,20 +204,15 @@ func (r *reconciler) upsertRepo(ctx context.Context, giteaClient *gitea.Client,
 		}
 		r.l.Info("repo created", "name", cr.GetName())
 		cr.Status.URL = &repo.CloneURL
-		return nil
-	}
-	editRepo := gitea.EditRepoOption{Name: pointer.String(cr.GetName())}
-	if cr.Spec.Description != nil {
-		editRepo.Description = cr.Spec.Description
-	} else {
-		editRepo.Description = nil
+		return r.addDeployKey(ctx, gitProvider, cr)
 	}
-	if cr.Spec.Private != nil {
-		editRepo.Private = cr.Spec.Private
-	} else {
-		editRepo.Private = nil
+	editRepo := gitprovider.RepositoryOptions{
+		Name:          cr.GetName(),
+		Description:   cr.Spec.Description,
+		Private:       cr.Spec.Private,
+		DefaultBranch: cr.Spec.DefaultBranch,
 	}
-	repo, _, err := giteaClient.EditRepo(u.UserName, cr.GetName(), editRepo)
+	repo, err := gitProvider.EditRepo(ctx, cr.GetName(), editRepo)
 	if err != nil {
 		r.l.Error(err, "cannot update repo")
 		// Here we don't provide the full error since the message change every time and this will re-trigger
@@ -219,19 +223,42 @@ func (r *reconciler) upsertRepo(ctx context.Context, giteaClient *gitea.Client,
 	r.l.Info("repo updated", "name", cr.GetName())
 	cr.Status.URL = &repo.CloneURL
 
-	return nil
+	return r.addDeployKey(ctx, gitProvider, cr)
 }
 
-func (r *reconciler) deleteRepo(ctx context.Context, giteaClient *gitea.Client, cr *infrav1alpha1.Repository) error {
-	u, _, err := giteaClient.GetMyUserInfo()
-	if err != nil {
-		r.l.Error(err, "cannot get user info")
+// addDeployKey adds the public key of the deploy key secret of the annotation to the repo
+func (r *reconciler) addDeployKey(ctx context.Context, gitProvider gitprovider.Provider, cr *infrav1alpha1.Repository) error {
+	secretName, ok := cr.GetAnnotations()[deployKeySecretKey]
+	if !ok {
+		return nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: secretName}, secret); err != nil {
+		r.l.Error(err, "cannot get deploy key secret", "name", secretName)
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return err
 	}
+	key, ok := secret.Data[deployKeyDataKey]
+	if !ok {
+		err := fmt.Errorf("deploy key secret %s has no %s", secretName, deployKeyDataKey)
+		r.l.Error(err, "cannot add deploy key")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	if err := gitProvider.AddDeployKey(ctx, cr.GetName(), gitprovider.DeployKey{
+		Title:    secretName,
+		Key:      string(key),
+		ReadOnly: cr.GetAnnotations()[deployKeyReadOnlyKey] == "true",
+	}); err != nil {
+		r.l.Error(err, "cannot add deploy key")
+		cr.SetConditions(infrav1alpha1.Failed("cannot add deploy key"))
+		return err
+	}
+	return nil
+}
 
-	_, err = giteaClient.DeleteRepo(u.UserName, cr.GetName())
-	if err != nil {
+func (r *reconciler) deleteRepo(ctx context.Context, gitProvider gitprovider.Provider, cr *infrav1alpha1.Repository) error {
+	if err := gitProvider.DeleteRepo(ctx, cr.GetName()); err != nil && !errors.Is(err, gitprovider.ErrNotFound) {
 		r.l.Error(err, "cannot delete repo")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return err
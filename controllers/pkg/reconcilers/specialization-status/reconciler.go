@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specializationstatus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/nephio-project/nephio/controllers/pkg/metrics"
+	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
+	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/nephio-project/nephio/controllers/pkg/sharding"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func init() {
+	reconcilerinterface.Register("specializationstatuses", &reconciler{})
+}
+
+//+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions,verbs=get;list;watch
+//+kubebuilder:rbac:groups=porch.kpt.dev,resources=packagerevisions/status,verbs=get
+//+kubebuilder:rbac:groups=workload.nephio.org,resources=specializationstatuses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=workload.nephio.org,resources=specializationstatuses/status,verbs=get;update;patch
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c any) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
+	cfg, ok := c.(*ctrlconfig.ControllerConfig)
+	if !ok {
+		return nil, fmt.Errorf("cannot initialize, expecting controllerConfig, got: %s", reflect.TypeOf(c).Name())
+	}
+
+	if err := porchv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return nil, err
+	}
+
+	r.Client = mgr.GetClient()
+
+	return nil, ctrl.NewControllerManagedBy(mgr).
+		Named("SpecializationStatusController").
+		For(&porchv1alpha1.PackageRevision{}).
+		Complete(sharding.NewReconciler(cfg.Shard, metrics.NewReconciler("SpecializationStatusController", r)))
+}
+
+type reconciler struct {
+	client.Client
+
+	l logr.Logger
+}
+
+// Reconcile aggregates the conditions of the specialization of the NF deployments of the package
+// revision in a SpecializationStatus per NF deployment
+func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.l = log.FromContext(ctx)
+	cr := &porchv1alpha1.PackageRevision{}
+	if err := r.Get(ctx, req.NamespacedName, cr); err != nil {
+		// There's no need to requeue if we no longer exist. Otherwise we'll be
+		// requeued implicitly because we return an error.
+		if resource.IgnoreNotFound(err) != nil {
+			msg := "cannot get resource"
+			r.l.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(resource.IgnoreNotFound(err), msg)
+		}
+		// the package revision is deleted, so are the statuses of its NF deployments
+		if err := r.deleteSpecializationStatuses(ctx, req.Namespace, req.Name, nil); err != nil {
+			msg := "cannot delete specialization statuses"
+			r.l.Error(err, msg)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	names := map[string]struct{}{}
+	for _, nf := range getNFDeployments(cr.Status.Conditions) {
+		s := getSpecializationStatus(cr, nf)
+		name, err := r.setSpecializationStatus(ctx, cr, nf, s)
+		if err != nil {
+			msg := "cannot update specialization status"
+			r.l.Error(err, msg, "nfDeployment", nf.Name)
+			return ctrl.Result{}, errors.Wrap(err, msg)
+		}
+		names[name] = struct{}{}
+		if !s.Ready {
+			r.l.Info("specialization not ready", "nfDeployment", nf.Name, "pending", len(s.Pending))
+		}
+	}
+	// the statuses of the NF deployments removed from the package revision are deleted
+	if err := r.deleteSpecializationStatuses(ctx, cr.GetNamespace(), cr.GetName(), names); err != nil {
+		msg := "cannot delete specialization statuses"
+		r.l.Error(err, msg)
+		return ctrl.Result{}, errors.Wrap(err, msg)
+	}
+	return ctrl.Result{}, nil
+}
+
+// deleteSpecializationStatuses deletes the statuses of the package revision not in keep
+func (r *reconciler) deleteSpecializationStatuses(ctx context.Context, namespace, prName string, keep map[string]struct{}) error {
+	ul := &unstructured.UnstructuredList{}
+	ul.SetGroupVersionKind(SpecializationStatusGroupVersionKind.GroupVersion().WithKind(SpecializationStatusKind + "List"))
+	if err := r.List(ctx, ul, client.InNamespace(namespace), client.MatchingLabels{PackageRevisionLabelKey: prName}); err != nil {
+		return err
+	}
+	for i := range ul.Items {
+		u := &ul.Items[i]
+		if _, ok := keep[u.GetName()]; ok {
+			continue
+		}
+		r.l.Info("delete specialization status", "name", u.GetName())
+		if err := r.Delete(ctx, u); resource.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
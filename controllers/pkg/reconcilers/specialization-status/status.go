@@ -0,0 +1,330 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specializationstatus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	nephiodeployv1alpha1 "github.com/nephio-project/api/nf_deployments/v1alpha1"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// SpecializationStatusKind is the kind of the specialization status of an NF deployment, it is
+	// not part of the nephio api yet and is written as unstructured
+	SpecializationStatusKind = "SpecializationStatus"
+
+	ConditionTypeSpecialized     = "Specialized"
+	ConditionTypeInterfacesReady = "InterfacesReady"
+	ConditionTypeIPClaimsReady   = "IPClaimsReady"
+	ConditionTypeVLANClaimsReady = "VLANClaimsReady"
+	ConditionTypeNADsReady       = "NADsReady"
+	ConditionTypeConfigInjected  = "ConfigInjected"
+	ConditionTypeReady           = "Ready"
+
+	// PackageRevisionLabelKey, RepositoryLabelKey and PackageNameLabelKey label the statuses with the
+	// package revision of the NF deployment, such that the statuses of a repository are listed
+	PackageRevisionLabelKey = "nephio.org/package-revision"
+	RepositoryLabelKey      = "nephio.org/repository"
+	PackageNameLabelKey     = "nephio.org/package-name"
+
+	nadKind    = "NetworkAttachmentDefinition"
+	configKind = "Config"
+)
+
+// SpecializationStatusGroupVersionKind is the GroupVersionKind of the specialization status of an NF deployment
+var SpecializationStatusGroupVersionKind = nephiodeployv1alpha1.GroupVersion.WithKind(SpecializationStatusKind)
+
+// specializations are the conditions aggregated per kind of the specialized resources
+var specializations = []struct {
+	conditionType string
+	kinds         []string
+	resources     string
+}{
+	{conditionType: ConditionTypeInterfacesReady, kinds: []string{nephioreqv1alpha1.InterfaceKind}, resources: "interfaces"},
+	{conditionType: ConditionTypeIPClaimsReady, kinds: []string{ipamv1alpha1.IPClaimKind}, resources: "ip claims"},
+	{conditionType: ConditionTypeVLANClaimsReady, kinds: []string{vlanv1alpha1.VLANClaimKind}, resources: "vlan claims"},
+	{conditionType: ConditionTypeNADsReady, kinds: []string{nadKind}, resources: "nads"},
+	{conditionType: ConditionTypeConfigInjected, kinds: []string{nephioreqv1alpha1.DependencyKind, configKind}, resources: "configs"},
+}
+
+// SpecializationStatus aggregates the conditions of the specialization of an NF deployment of a
+// package revision, such that the stuck specializations of a fleet are visible with a single list
+type SpecializationStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              SpecializationStatusSpec   `json:"spec,omitempty"`
+	Status            SpecializationStatusStatus `json:"status,omitempty"`
+}
+
+type SpecializationStatusSpec struct {
+	// NFDeployment references the NF deployment of the package revision
+	NFDeployment corev1.ObjectReference `json:"nfDeployment"`
+	// PackageRevision is the name of the package revision of the NF deployment
+	PackageRevision string `json:"packageRevision"`
+	// Repository is the repository of the package revision
+	Repository string `json:"repository"`
+	// PackageName is the package of the package revision
+	PackageName string `json:"packageName"`
+}
+
+type SpecializationStatusStatus struct {
+	// Ready is true when all the conditions are true
+	Ready bool `json:"ready"`
+	// Lifecycle is the lifecycle of the package revision
+	Lifecycle string `json:"lifecycle,omitempty"`
+	// Conditions holds the Specialized, InterfacesReady, IPClaimsReady, VLANClaimsReady, NADsReady,
+	// ConfigInjected and Ready conditions
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Pending holds the specializations that are not ready with the reason they are stuck
+	Pending []PendingSpecialization `json:"pending,omitempty"`
+}
+
+type PendingSpecialization struct {
+	// Type is the condition type of the specialized resource, e.g. req.nephio.org/v1alpha1.Interface.n3
+	Type string `json:"type"`
+	// Reason is the condition type of the resource the specialized resource is derived from
+	Reason string `json:"reason,omitempty"`
+	// Message is the message of the condition of the specialized resource
+	Message string `json:"message,omitempty"`
+}
+
+// getNFDeployments returns the NF deployments of the conditions of the package revision
+func getNFDeployments(conditions []porchv1alpha1.Condition) []corev1.ObjectReference {
+	nfs := []corev1.ObjectReference{}
+	for _, c := range conditions {
+		ref := kptfilelibv1.GetGVKNFromConditionType(c.Type)
+		if ref.APIVersion == nephiodeployv1alpha1.GroupVersion.Identifier() && strings.HasSuffix(ref.Kind, "Deployment") {
+			nfs = append(nfs, *ref)
+		}
+	}
+	sort.Slice(nfs, func(i, j int) bool {
+		return nfs[i].Name < nfs[j].Name
+	})
+	return nfs
+}
+
+// getNFConditions returns the condition of the NF deployment and the conditions of the resources
+// specialized for it, the resources derived from the NF deployment or from one of its resources
+func getNFConditions(conditions []porchv1alpha1.Condition, nf corev1.ObjectReference) (*porchv1alpha1.Condition, []porchv1alpha1.Condition) {
+	nfType := kptfilelibv1.GetConditionType(&nf)
+	var nfCondition *porchv1alpha1.Condition
+	owners := map[string]struct{}{nfType: {}}
+	children := []porchv1alpha1.Condition{}
+	for found := true; found; {
+		found = false
+		for i, c := range conditions {
+			if c.Type == nfType {
+				nfCondition = &conditions[i]
+				continue
+			}
+			if _, ok := owners[c.Type]; ok {
+				continue
+			}
+			if _, ok := owners[c.Reason]; ok {
+				owners[c.Type] = struct{}{}
+				children = append(children, c)
+				found = true
+			}
+		}
+	}
+	return nfCondition, children
+}
+
+// getSpecializationStatus aggregates the conditions of the specialization of the NF deployment
+func getSpecializationStatus(pr *porchv1alpha1.PackageRevision, nf corev1.ObjectReference) SpecializationStatusStatus {
+	nfCondition, children := getNFConditions(pr.Status.Conditions, nf)
+	s := SpecializationStatusStatus{Lifecycle: string(pr.Spec.Lifecycle), Pending: []PendingSpecialization{}}
+
+	specialized := metav1.Condition{Type: ConditionTypeSpecialized, Status: metav1.ConditionFalse, Reason: "Pending", Message: "nf deployment not specialized"}
+	if nfCondition != nil {
+		specialized.Message = nfCondition.Message
+		if nfCondition.Status == porchv1alpha1.ConditionTrue {
+			specialized.Status = metav1.ConditionTrue
+			specialized.Reason = ConditionTypeSpecialized
+		}
+		if specialized.Message == "" {
+			specialized.Message = "nf deployment specialized"
+		}
+	}
+	conditions := []metav1.Condition{specialized}
+
+	for _, sp := range specializations {
+		total := 0
+		pending := []string{}
+		for _, c := range children {
+			ref := kptfilelibv1.GetGVKNFromConditionType(c.Type)
+			if !contains(sp.kinds, ref.Kind) {
+				continue
+			}
+			total++
+			if c.Status != porchv1alpha1.ConditionTrue {
+				pending = append(pending, fmt.Sprintf("%s %s", ref.Kind, ref.Name))
+			}
+		}
+		conditions = append(conditions, newCondition(sp.conditionType, sp.resources, total, pending))
+	}
+	for _, c := range children {
+		if c.Status != porchv1alpha1.ConditionTrue {
+			s.Pending = append(s.Pending, PendingSpecialization{Type: c.Type, Reason: c.Reason, Message: c.Message})
+		}
+	}
+	sort.Slice(s.Pending, func(i, j int) bool {
+		return s.Pending[i].Type < s.Pending[j].Type
+	})
+
+	notReady := []string{}
+	for _, c := range conditions {
+		if c.Status != metav1.ConditionTrue {
+			notReady = append(notReady, c.Type)
+		}
+	}
+	s.Ready = len(notReady) == 0
+	ready := metav1.Condition{Type: ConditionTypeReady, Status: metav1.ConditionTrue, Reason: ConditionTypeReady, Message: "nf deployment specialized"}
+	if !s.Ready {
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "NotReady"
+		ready.Message = fmt.Sprintf("waiting for %s", strings.Join(notReady, ", "))
+	}
+	s.Conditions = append(conditions, ready)
+	return s
+}
+
+// setStatus replaces the status keeping the transition times of the unchanged conditions
+func (r *SpecializationStatusStatus) setStatus(s SpecializationStatusStatus) {
+	conditions := r.Conditions
+	for _, c := range s.Conditions {
+		meta.SetStatusCondition(&conditions, c)
+	}
+	kept := []metav1.Condition{}
+	for _, c := range conditions {
+		if meta.FindStatusCondition(s.Conditions, c.Type) != nil {
+			kept = append(kept, c)
+		}
+	}
+	s.Conditions = kept
+	*r = s
+}
+
+func newCondition(ct, resources string, total int, pending []string) metav1.Condition {
+	if total == 0 {
+		return metav1.Condition{Type: ct, Status: metav1.ConditionTrue, Reason: ct, Message: fmt.Sprintf("no %s", resources)}
+	}
+	if len(pending) == 0 {
+		return metav1.Condition{Type: ct, Status: metav1.ConditionTrue, Reason: ct, Message: fmt.Sprintf("%d/%d %s ready", total, total, resources)}
+	}
+	sort.Strings(pending)
+	return metav1.Condition{
+		Type:    ct,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Pending",
+		Message: fmt.Sprintf("%d/%d %s ready, pending: %s", total-len(pending), total, resources, strings.Join(pending, ", ")),
+	}
+}
+
+func contains(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// setSpecializationStatus creates or updates the status of the NF deployment of the package
+// revision, it returns the name of the status
+func (r *reconciler) setSpecializationStatus(ctx context.Context, pr *porchv1alpha1.PackageRevision, nf corev1.ObjectReference, ss SpecializationStatusStatus) (string, error) {
+	key := types.NamespacedName{Namespace: pr.GetNamespace(), Name: fmt.Sprintf("%s-%s", pr.GetName(), nf.Name)}
+	spec := SpecializationStatusSpec{
+		NFDeployment:    nf,
+		PackageRevision: pr.GetName(),
+		Repository:      pr.Spec.RepositoryName,
+		PackageName:     pr.Spec.PackageName,
+	}
+	labels := map[string]string{
+		PackageRevisionLabelKey: pr.GetName(),
+		RepositoryLabelKey:      pr.Spec.RepositoryName,
+		PackageNameLabelKey:     pr.Spec.PackageName,
+	}
+	u := resource.GetUnstructuredFromGVK(&SpecializationStatusGroupVersionKind)
+	if err := r.Get(ctx, key, u); err != nil {
+		if resource.IgnoreNotFound(err) != nil {
+			return "", err
+		}
+		u.SetNamespace(key.Namespace)
+		u.SetName(key.Name)
+		u.SetLabels(labels)
+		if err := setSpec(u, spec); err != nil {
+			return "", err
+		}
+		if err := r.Create(ctx, u); err != nil {
+			return "", err
+		}
+	}
+	b, err := json.Marshal(u)
+	if err != nil {
+		return "", err
+	}
+	s := &SpecializationStatus{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return "", err
+	}
+
+	if !reflect.DeepEqual(s.Spec, spec) || !reflect.DeepEqual(s.GetLabels(), labels) {
+		u.SetLabels(labels)
+		if err := setSpec(u, spec); err != nil {
+			return "", err
+		}
+		if err := r.Update(ctx, u); err != nil {
+			return "", err
+		}
+	}
+
+	s.Status.setStatus(ss)
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&s.Status)
+	if err != nil {
+		return "", err
+	}
+	if err := unstructured.SetNestedMap(u.Object, status, "status"); err != nil {
+		return "", err
+	}
+	return key.Name, r.Status().Update(ctx, u)
+}
+
+func setSpec(u *unstructured.Unstructured, spec SpecializationStatusSpec) error {
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&spec)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedMap(u.Object, m, "spec")
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specializationstatus
+
+import (
+	"testing"
+
+	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	upfType   = "workload.nephio.org/v1alpha1.UPFDeployment.upf-regional"
+	n3Type    = "req.nephio.org/v1alpha1.Interface.n3"
+	n6Type    = "req.nephio.org/v1alpha1.Interface.n6"
+	depType   = "req.nephio.org/v1alpha1.Dependency.upf-regional-smf"
+	n3IPType  = "ipam.resource.nephio.org/v1alpha1.IPClaim.n3-ipv4"
+	n6IPType  = "ipam.resource.nephio.org/v1alpha1.IPClaim.n6-ipv4"
+	n3VLType  = "vlan.resource.nephio.org/v1alpha1.VLANClaim.n3"
+	n3NADType = "k8s.cni.cncf.io/v1.NetworkAttachmentDefinition.upf-regional-n3"
+)
+
+var upf = corev1.ObjectReference{APIVersion: "workload.nephio.org/v1alpha1", Kind: "UPFDeployment", Name: "upf-regional"}
+
+func TestGetNFDeployments(t *testing.T) {
+	cases := map[string]struct {
+		conditions []porchv1alpha1.Condition
+		want       []corev1.ObjectReference
+	}{
+		"NoNFDeployment": {
+			conditions: []porchv1alpha1.Condition{{Type: n3Type}},
+			want:       []corev1.ObjectReference{},
+		},
+		"NFDeployments": {
+			conditions: []porchv1alpha1.Condition{
+				{Type: upfType},
+				{Type: n3Type, Reason: upfType},
+				{Type: "workload.nephio.org/v1alpha1.SMFDeployment.smf-regional"},
+			},
+			want: []corev1.ObjectReference{
+				{APIVersion: "workload.nephio.org/v1alpha1", Kind: "SMFDeployment", Name: "smf-regional"},
+				upf,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getNFDeployments(tc.conditions)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetSpecializationStatus(t *testing.T) {
+	cases := map[string]struct {
+		conditions []porchv1alpha1.Condition
+		want       SpecializationStatusStatus
+	}{
+		"NotSpecialized": {
+			conditions: []porchv1alpha1.Condition{},
+			want: SpecializationStatusStatus{
+				Lifecycle: "Draft",
+				Conditions: []metav1.Condition{
+					{Type: ConditionTypeSpecialized, Status: metav1.ConditionFalse, Reason: "Pending", Message: "nf deployment not specialized"},
+					{Type: ConditionTypeInterfacesReady, Status: metav1.ConditionTrue, Reason: ConditionTypeInterfacesReady, Message: "no interfaces"},
+					{Type: ConditionTypeIPClaimsReady, Status: metav1.ConditionTrue, Reason: ConditionTypeIPClaimsReady, Message: "no ip claims"},
+					{Type: ConditionTypeVLANClaimsReady, Status: metav1.ConditionTrue, Reason: ConditionTypeVLANClaimsReady, Message: "no vlan claims"},
+					{Type: ConditionTypeNADsReady, Status: metav1.ConditionTrue, Reason: ConditionTypeNADsReady, Message: "no nads"},
+					{Type: ConditionTypeConfigInjected, Status: metav1.ConditionTrue, Reason: ConditionTypeConfigInjected, Message: "no configs"},
+					{Type: ConditionTypeReady, Status: metav1.ConditionFalse, Reason: "NotReady", Message: "waiting for Specialized"},
+				},
+				Pending: []PendingSpecialization{},
+			},
+		},
+		"Pending": {
+			conditions: []porchv1alpha1.Condition{
+				{Type: upfType, Status: porchv1alpha1.ConditionFalse, Message: "update for condition"},
+				{Type: n3Type, Status: porchv1alpha1.ConditionTrue, Reason: upfType},
+				{Type: n6Type, Status: porchv1alpha1.ConditionFalse, Reason: upfType, Message: "update condition for initial resource"},
+				{Type: depType, Status: porchv1alpha1.ConditionTrue, Reason: upfType},
+				{Type: n3IPType, Status: porchv1alpha1.ConditionTrue, Reason: n3Type},
+				{Type: n6IPType, Status: porchv1alpha1.ConditionFalse, Reason: n6Type, Message: "no prefix available"},
+				{Type: n3VLType, Status: porchv1alpha1.ConditionTrue, Reason: n3Type},
+				{Type: n3NADType, Status: porchv1alpha1.ConditionFalse, Reason: n3Type, Message: "update for condition"},
+				// a claim of another NF deployment is not aggregated
+				{Type: "ipam.resource.nephio.org/v1alpha1.IPClaim.other", Status: porchv1alpha1.ConditionFalse, Reason: "req.nephio.org/v1alpha1.Interface.other"},
+			},
+			want: SpecializationStatusStatus{
+				Lifecycle: "Draft",
+				Conditions: []metav1.Condition{
+					{Type: ConditionTypeSpecialized, Status: metav1.ConditionFalse, Reason: "Pending", Message: "update for condition"},
+					{Type: ConditionTypeInterfacesReady, Status: metav1.ConditionFalse, Reason: "Pending", Message: "1/2 interfaces ready, pending: Interface n6"},
+					{Type: ConditionTypeIPClaimsReady, Status: metav1.ConditionFalse, Reason: "Pending", Message: "1/2 ip claims ready, pending: IPClaim n6-ipv4"},
+					{Type: ConditionTypeVLANClaimsReady, Status: metav1.ConditionTrue, Reason: ConditionTypeVLANClaimsReady, Message: "1/1 vlan claims ready"},
+					{Type: ConditionTypeNADsReady, Status: metav1.ConditionFalse, Reason: "Pending", Message: "0/1 nads ready, pending: NetworkAttachmentDefinition upf-regional-n3"},
+					{Type: ConditionTypeConfigInjected, Status: metav1.ConditionTrue, Reason: ConditionTypeConfigInjected, Message: "1/1 configs ready"},
+					{Type: ConditionTypeReady, Status: metav1.ConditionFalse, Reason: "NotReady", Message: "waiting for Specialized, InterfacesReady, IPClaimsReady, NADsReady"},
+				},
+				Pending: []PendingSpecialization{
+					{Type: n6IPType, Reason: n6Type, Message: "no prefix available"},
+					{Type: n3NADType, Reason: n3Type, Message: "update for condition"},
+					{Type: n6Type, Reason: upfType, Message: "update condition for initial resource"},
+				},
+			},
+		},
+		"Ready": {
+			conditions: []porchv1alpha1.Condition{
+				{Type: upfType, Status: porchv1alpha1.ConditionTrue},
+				{Type: n3Type, Status: porchv1alpha1.ConditionTrue, Reason: upfType},
+				{Type: n3IPType, Status: porchv1alpha1.ConditionTrue, Reason: n3Type},
+			},
+			want: SpecializationStatusStatus{
+				Ready:     true,
+				Lifecycle: "Draft",
+				Conditions: []metav1.Condition{
+					{Type: ConditionTypeSpecialized, Status: metav1.ConditionTrue, Reason: ConditionTypeSpecialized, Message: "nf deployment specialized"},
+					{Type: ConditionTypeInterfacesReady, Status: metav1.ConditionTrue, Reason: ConditionTypeInterfacesReady, Message: "1/1 interfaces ready"},
+					{Type: ConditionTypeIPClaimsReady, Status: metav1.ConditionTrue, Reason: ConditionTypeIPClaimsReady, Message: "1/1 ip claims ready"},
+					{Type: ConditionTypeVLANClaimsReady, Status: metav1.ConditionTrue, Reason: ConditionTypeVLANClaimsReady, Message: "no vlan claims"},
+					{Type: ConditionTypeNADsReady, Status: metav1.ConditionTrue, Reason: ConditionTypeNADsReady, Message: "no nads"},
+					{Type: ConditionTypeConfigInjected, Status: metav1.ConditionTrue, Reason: ConditionTypeConfigInjected, Message: "no configs"},
+					{Type: ConditionTypeReady, Status: metav1.ConditionTrue, Reason: ConditionTypeReady, Message: "nf deployment specialized"},
+				},
+				Pending: []PendingSpecialization{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pr := &porchv1alpha1.PackageRevision{
+				Spec:   porchv1alpha1.PackageRevisionSpec{Lifecycle: porchv1alpha1.PackageRevisionLifecycleDraft},
+				Status: porchv1alpha1.PackageRevisionStatus{Conditions: tc.conditions},
+			}
+			got := getSpecializationStatus(pr, upf)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -20,19 +20,22 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
-	"code.gitea.io/sdk/gitea"
 	"github.com/go-logr/logr"
 	commonv1alpha1 "github.com/nephio-project/api/common/v1alpha1"
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
-	"github.com/nephio-project/nephio/controllers/pkg/giteaclient"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	"github.com/nephio-project/nephio/controllers/pkg/metrics"
 	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
 	"github.com/nephio-project/nephio/controllers/pkg/resource"
+	"github.com/nephio-project/nephio/controllers/pkg/sharding"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -52,16 +55,17 @@ const (
 
 //+kubebuilder:rbac:groups=infra.nephio.org,resources=tokens,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infra.nephio.org,resources=tokens/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="*",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c interface{}) (map[schema.GroupVersionKind]chan event.GenericEvent, error) {
 	cfg, ok := c.(*ctrlconfig.ControllerConfig)
-	// Sending the porchclient to gitea, this will be used to get
-	// the secret objects for gitea client authentication. The client
+	// Sending the porchclient to the git provider, this will be used to get
+	// the secret objects for git client authentication. The client
 	// of the manager of this controller cannot be used at this point.
 	// Should this be conditional ? Only if we have repo/token reconciler
-	r.giteaClient = giteaclient.New(resource.NewAPIPatchingApplicator(cfg.PorchClient))
-	go r.giteaClient.Start(ctx)
+	r.gitClient = gitprovider.New(resource.NewAPIPatchingApplicator(cfg.PorchClient))
+	go r.gitClient.Start(ctx)
 
 	if !ok {
 		return nil, fmt.Errorf("cannot initialize, expecting controllerConfig, got: %s", reflect.TypeOf(c).Name())
@@ -77,13 +81,13 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("TokenController").
 		For(&infrav1alpha1.Token{}).
-		Complete(r)
+		Complete(sharding.NewReconciler(cfg.Shard, metrics.NewReconciler("TokenController", r)))
 }
 
 type reconciler struct {
 	resource.APIPatchingApplicator
-	giteaClient giteaclient.GiteaClient
-	finalizer   *resource.APIFinalizer
+	gitClient gitprovider.Client
+	finalizer *resource.APIFinalizer
 
 	l logr.Logger
 }
@@ -103,10 +107,10 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	// check if client exists otherwise retry
-	giteaClient := r.giteaClient.Get()
-	if giteaClient == nil {
-		err := fmt.Errorf("gitea server unreachable")
-		r.l.Error(err, "cannot connect to gitea server")
+	gitProvider := r.gitClient.Get()
+	if gitProvider == nil {
+		err := fmt.Errorf("git server unreachable")
+		r.l.Error(err, "cannot connect to git server")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
@@ -117,7 +121,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// Delete the token from the git server
 		// when successful remove the finalizer
 		if cr.Spec.Lifecycle.DeletionPolicy == commonv1alpha1.DeletionDelete {
-			if err := r.deleteToken(ctx, giteaClient, cr); err != nil {
+			if err := r.deleteToken(ctx, gitProvider, cr); err != nil {
 				return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 			}
 		}
@@ -139,91 +143,148 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
-	// create token and secret
-	if err := r.createToken(ctx, giteaClient, cr); err != nil {
+	// create or rotate token and secret
+	rotateAfter, err := r.createToken(ctx, gitProvider, cr)
+	if err != nil {
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 	cr.SetConditions(infrav1alpha1.Ready())
-	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	return ctrl.Result{RequeueAfter: rotateAfter}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 }
 
-func (r *reconciler) createToken(ctx context.Context, giteaClient *gitea.Client, cr *infrav1alpha1.Token) error {
-	tokens, _, err := giteaClient.ListAccessTokens(gitea.ListAccessTokensOptions{})
+func (r *reconciler) createToken(ctx context.Context, gitProvider gitprovider.Provider, cr *infrav1alpha1.Token) (time.Duration, error) {
+	policy, err := getRotationPolicy(cr)
+	if err != nil {
+		r.l.Error(err, "invalid rotation policy")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return 0, err
+	}
+	scope := getTokenScope(cr)
+	tokens, err := gitProvider.ListTokens(ctx, scope)
 	if err != nil {
 		r.l.Error(err, "cannot list repo")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-		return err
+		return 0, err
 	}
+	// the secret references the token in use, the name of the token of the cr by default
+	current := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}, current); resource.IgnoreNotFound(err) != nil {
+		r.l.Error(err, "cannot get secret")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return 0, err
+	}
+	currentTokenName := getTokenName(cr, current)
 	tokenFound := false
-	for _, repo := range tokens {
-		if repo.Name == cr.GetTokenName() {
+	for _, name := range tokens {
+		if name == currentTokenName {
 			tokenFound = true
 			break
 		}
 	}
-	if !tokenFound {
-		u, _, err := giteaClient.GetMyUserInfo()
-		if err != nil {
-			r.l.Error(err, "cannot get user info")
-			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-			return err
+	now := time.Now()
+	if tokenFound {
+		if policy == nil {
+			return 0, nil
 		}
+		if rotateAfter := policy.rotateAfter(current, now); rotateAfter > 0 {
+			return rotateAfter, nil
+		}
+		r.l.Info("token expiring, rotating", "name", currentTokenName)
+	}
 
-		token, _, err := giteaClient.CreateAccessToken(gitea.CreateAccessTokenOption{
-			Name: cr.GetTokenName(),
-			Scopes: []gitea.AccessTokenScope{
-				gitea.AccessTokenScopeRepo,
-			},
-		})
-		if err != nil {
-			r.l.Error(err, "cannot create token")
+	tokenName := cr.GetTokenName()
+	if policy != nil {
+		// the rotated tokens coexist until the previous one is revoked
+		tokenName = fmt.Sprintf("%s-%d", cr.GetTokenName(), now.Unix())
+	}
+	token, err := gitProvider.CreateToken(ctx, tokenName, scope)
+	if err != nil {
+		r.l.Error(err, "cannot create token")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return 0, err
+	}
+	// a scoped token authenticates its own user
+	userName := token.UserName
+	if userName == "" {
+		if userName, err = gitProvider.GetUserName(ctx); err != nil {
+			r.l.Error(err, "cannot get user info")
 			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-			return err
+			return 0, err
 		}
-		r.l.Info("token created", "name", cr.GetName())
-		secret := &corev1.Secret{
-			TypeMeta: metav1.TypeMeta{
-				APIVersion: corev1.SchemeGroupVersion.Identifier(),
-				Kind:       reflect.TypeOf(corev1.Secret{}).Name(),
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace:   cr.GetNamespace(),
-				Name:        cr.GetName(),
-				Annotations: cr.GetAnnotations(),
-				OwnerReferences: []metav1.OwnerReference{
-					{
-						APIVersion: cr.APIVersion,
-						Kind:       cr.Kind,
-						Name:       cr.Name,
-						UID:        cr.UID,
-						Controller: pointer.Bool(true),
-					},
+	}
+	r.l.Info("token created", "name", cr.GetName())
+	annotations := map[string]string{}
+	for k, v := range cr.GetAnnotations() {
+		annotations[k] = v
+	}
+	annotations[tokenNameKey] = tokenName
+	if policy != nil {
+		annotations[tokenExpiresKey] = now.Add(policy.ttl).UTC().Format(time.RFC3339)
+	}
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.Identifier(),
+			Kind:       reflect.TypeOf(corev1.Secret{}).Name(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   cr.GetNamespace(),
+			Name:        cr.GetName(),
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: cr.APIVersion,
+					Kind:       cr.Kind,
+					Name:       cr.Name,
+					UID:        cr.UID,
+					Controller: pointer.Bool(true),
 				},
 			},
-			Data: map[string][]byte{
-				"username": []byte(u.UserName),
-				"password": []byte(token.Token), // needed for porch
-				"token":    []byte(token.Token), // needed for configsync
-			},
-			Type: corev1.SecretTypeBasicAuth,
-		}
-		if err := r.Apply(ctx, secret); err != nil {
+		},
+		Data: map[string][]byte{
+			"username": []byte(userName),
+			"password": []byte(token.Token), // needed for porch
+			"token":    []byte(token.Token), // needed for configsync
+		},
+		Type: corev1.SecretTypeBasicAuth,
+	}
+	if err := r.Apply(ctx, secret); err != nil {
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		r.l.Error(err, "cannot create secret")
+		return 0, err
+	}
+	r.l.Info("secret for token created", "name", cr.GetName())
+
+	if policy == nil {
+		return 0, nil
+	}
+	// the previous token is revoked once the secret holds the new token, the copies of the secret
+	// synced to the workload clusters follow the secret
+	if tokenFound && currentTokenName != tokenName {
+		if err := gitProvider.DeleteToken(ctx, currentTokenName, scope); err != nil {
+			r.l.Error(err, "cannot revoke token", "name", currentTokenName)
 			cr.SetConditions(infrav1alpha1.Failed(err.Error()))
-			r.l.Error(err, "cannot create secret")
-			return err
+			return 0, err
 		}
-		r.l.Info("secret for token created", "name", cr.GetName())
+		r.l.Info("token revoked", "name", currentTokenName)
+		metrics.TokensRotated.Inc()
 	}
-	return nil
+	cr.SetConditions(rotated(now, now.Add(policy.ttl)))
+	return policy.rotateAfter(secret, now), nil
 }
 
-func (r *reconciler) deleteToken(ctx context.Context, giteaClient *gitea.Client, cr *infrav1alpha1.Token) error {
-	_, err := giteaClient.DeleteAccessToken(cr.GetTokenName())
-	if err != nil {
+func (r *reconciler) deleteToken(ctx context.Context, gitProvider gitprovider.Provider, cr *infrav1alpha1.Token) error {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}, secret); resource.IgnoreNotFound(err) != nil {
+		r.l.Error(err, "cannot get secret")
+		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
+		return err
+	}
+	tokenName := getTokenName(cr, secret)
+	if err := gitProvider.DeleteToken(ctx, tokenName, getTokenScope(cr)); err != nil {
 		r.l.Error(err, "cannot delete token")
 		cr.SetConditions(infrav1alpha1.Failed(err.Error()))
 		return err
 	}
-	r.l.Info("token deleted", "name", cr.GetTokenName())
+	r.l.Info("token deleted", "name", tokenName)
 	return nil
 }
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"fmt"
+	"time"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ttlKey is the annotation of the token defining the duration after which the token expires and
+	// is rotated, a token without ttl is never rotated
+	ttlKey = "nephio.org/token-ttl"
+	// rotateBeforeKey is the annotation of the token defining how long before its expiry the token is
+	// rotated, by default a tenth of the ttl
+	rotateBeforeKey = "nephio.org/token-rotate-before"
+	// tokenNameKey is the annotation of the secret with the name of the token in the git server
+	tokenNameKey = "nephio.org/token-name"
+	// tokenExpiresKey is the annotation of the secret with the RFC3339 expiry time of the token
+	tokenExpiresKey = "nephio.org/token-expires"
+
+	// ConditionTypeRotated reports the last rotation of the token
+	ConditionTypeRotated infrav1alpha1.ConditionType = "Rotated"
+)
+
+type rotationPolicy struct {
+	ttl          time.Duration
+	rotateBefore time.Duration
+}
+
+// getRotationPolicy returns the rotation policy of the token, nil when the token has no ttl
+func getRotationPolicy(cr *infrav1alpha1.Token) (*rotationPolicy, error) {
+	annotations := cr.GetAnnotations()
+	ttl, ok := annotations[ttlKey]
+	if !ok {
+		return nil, nil
+	}
+	p := &rotationPolicy{}
+	var err error
+	if p.ttl, err = time.ParseDuration(ttl); err != nil || p.ttl <= 0 {
+		return nil, fmt.Errorf("invalid token ttl %s", ttl)
+	}
+	p.rotateBefore = p.ttl / 10
+	if rotateBefore, ok := annotations[rotateBeforeKey]; ok {
+		if p.rotateBefore, err = time.ParseDuration(rotateBefore); err != nil {
+			return nil, errors.Wrap(err, "invalid token rotate before")
+		}
+		if p.rotateBefore >= p.ttl {
+			return nil, fmt.Errorf("token rotate before %s is not shorter than the ttl %s", rotateBefore, ttl)
+		}
+	}
+	return p, nil
+}
+
+// rotateAfter returns the time after which the token of the secret is rotated, 0 when the
+// token is due for rotation or has no expiry
+func (r *rotationPolicy) rotateAfter(secret *corev1.Secret, now time.Time) time.Duration {
+	expires, err := time.Parse(time.RFC3339, secret.GetAnnotations()[tokenExpiresKey])
+	if err != nil {
+		return 0
+	}
+	if d := expires.Add(-r.rotateBefore).Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// getTokenName returns the name of the token of the secret in the git server, the name
+// of the token of the cr when the secret was created before the token was rotated
+func getTokenName(cr *infrav1alpha1.Token, secret *corev1.Secret) string {
+	if name, ok := secret.GetAnnotations()[tokenNameKey]; ok && name != "" {
+		return name
+	}
+	return cr.GetTokenName()
+}
+
+func rotated(at, expires time.Time) infrav1alpha1.Condition {
+	return infrav1alpha1.Condition{Condition: metav1.Condition{
+		Type:               string(ConditionTypeRotated),
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(at),
+		Reason:             "Rotated",
+		Message:            fmt.Sprintf("token rotated, expires at %s", expires.UTC().Format(time.RFC3339)),
+	}}
+}
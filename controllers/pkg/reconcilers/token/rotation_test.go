@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetRotationPolicy(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		want        *rotationPolicy
+		wantError   bool
+	}{
+		"NoTTL": {
+			annotations: nil,
+			want:        nil,
+		},
+		"TTL": {
+			annotations: map[string]string{ttlKey: "100h"},
+			want:        &rotationPolicy{ttl: 100 * time.Hour, rotateBefore: 10 * time.Hour},
+		},
+		"RotateBefore": {
+			annotations: map[string]string{ttlKey: "100h", rotateBeforeKey: "24h"},
+			want:        &rotationPolicy{ttl: 100 * time.Hour, rotateBefore: 24 * time.Hour},
+		},
+		"InvalidTTL": {
+			annotations: map[string]string{ttlKey: "30d"},
+			wantError:   true,
+		},
+		"RotateBeforeTTL": {
+			annotations: map[string]string{ttlKey: "24h", rotateBeforeKey: "24h"},
+			wantError:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := getRotationPolicy(&infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{Name: "a", Annotations: tc.annotations}})
+			if (err != nil) != tc.wantError {
+				t.Fatalf("getRotationPolicy() error = %v, wantError %v", err, tc.wantError)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(rotationPolicy{})); diff != "" {
+				t.Errorf("getRotationPolicy() -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRotateAfter(t *testing.T) {
+	now := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	p := &rotationPolicy{ttl: 100 * time.Hour, rotateBefore: 10 * time.Hour}
+	cases := map[string]struct {
+		annotations map[string]string
+		want        time.Duration
+	}{
+		"NoExpiry": {
+			annotations: nil,
+			want:        0,
+		},
+		"Valid": {
+			annotations: map[string]string{tokenExpiresKey: now.Add(100 * time.Hour).Format(time.RFC3339)},
+			want:        90 * time.Hour,
+		},
+		"Expiring": {
+			annotations: map[string]string{tokenExpiresKey: now.Add(5 * time.Hour).Format(time.RFC3339)},
+			want:        0,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := p.rotateAfter(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}, now)
+			if got != tc.want {
+				t.Errorf("rotateAfter() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetTokenName(t *testing.T) {
+	cr := &infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{Namespace: "edge", Name: "a"}}
+	if got := getTokenName(cr, &corev1.Secret{}); got != "a-edge" {
+		t.Errorf("getTokenName() = %s, want a-edge", got)
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{tokenNameKey: "a-edge-1688169600"}}}
+	if got := getTokenName(cr, secret); got != "a-edge-1688169600" {
+		t.Errorf("getTokenName() = %s, want a-edge-1688169600", got)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+)
+
+const (
+	// repositoryKey is the annotation of the token restricting the token to the repository, e.g. the
+	// deployment repository of a workload cluster; a token without repository accesses all the repositories
+	repositoryKey = "nephio.org/token-repository"
+	// permissionKey is the annotation of the token with the permission on the repository, read or
+	// write (default)
+	permissionKey = "nephio.org/token-permission"
+
+	permissionRead = "read"
+)
+
+// getTokenScope returns the scope of the token, nil for a token accessing all the repositories
+func getTokenScope(cr *infrav1alpha1.Token) *gitprovider.TokenScope {
+	repo, ok := cr.GetAnnotations()[repositoryKey]
+	if !ok || repo == "" {
+		return nil
+	}
+	return &gitprovider.TokenScope{
+		Repository: repo,
+		ReadOnly:   cr.GetAnnotations()[permissionKey] == permissionRead,
+	}
+}
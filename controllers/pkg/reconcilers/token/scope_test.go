@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/controllers/pkg/gitprovider"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetTokenScope(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		want        *gitprovider.TokenScope
+	}{
+		"NoScope": {
+			annotations: map[string]string{"nephio.org/app": "configsync"},
+			want:        nil,
+		},
+		"ReadWrite": {
+			annotations: map[string]string{repositoryKey: "edge01"},
+			want:        &gitprovider.TokenScope{Repository: "edge01"},
+		},
+		"ReadOnly": {
+			annotations: map[string]string{repositoryKey: "edge01", permissionKey: "read"},
+			want:        &gitprovider.TokenScope{Repository: "edge01", ReadOnly: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getTokenScope(&infrav1alpha1.Token{ObjectMeta: metav1.ObjectMeta{Name: "edge01-access-token-configsync", Annotations: tc.annotations}})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("getTokenScope() -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
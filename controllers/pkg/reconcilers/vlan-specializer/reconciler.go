@@ -21,9 +21,13 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/nephio-project/nephio/controllers/pkg/metrics"
 	porchcondition "github.com/nephio-project/nephio/controllers/pkg/porch/condition"
 	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconcilerinterface "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
+	"github.com/nephio-project/nephio/controllers/pkg/sharding"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+	"github.com/nephio-project/nephio/krm-functions/lib/tracing"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 
@@ -62,7 +66,7 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 		return nil, fmt.Errorf("cannot initialize, expecting controllerConfig, got: %s", reflect.TypeOf(c).Name())
 	}
 
-	f := &function.FnR{ClientProxy: cfg.VlanClientProxy}
+	f := function.New(cfg.VlanClientProxy)
 
 	r.For = corev1.ObjectReference{
 		APIVersion: vlanv1alpha1.SchemeBuilder.GroupVersion.Identifier(),
@@ -76,7 +80,7 @@ func (r *reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, c i
 	return nil, ctrl.NewControllerManagedBy(mgr).
 		Named("VlanSpecializer").
 		For(&porchv1alpha1.PackageRevision{}).
-		Complete(r)
+		Complete(sharding.NewReconciler(cfg.Shard, metrics.NewReconciler("VlanSpecializer", r)))
 }
 
 // reconciler reconciles a NetworkInstance object
@@ -119,6 +123,10 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			r.l.Error(err, "cannot get resourceList")
 			return ctrl.Result{}, errors.Wrap(err, "cannot get resourceList")
 		}
+		// the functions trace their runs in the trace of the specialization of the package
+		if _, err := tracing.Propagate(ctx, rl.Items.GetRootKptfile()); err != nil {
+			r.l.Error(err, "cannot propagate the trace context to the Kptfile")
+		}
 
 		// run the function SDK
 		_, err = r.krmfn.Process(rl)
@@ -127,6 +135,9 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			// TBD if we need to return here + check if kptfile is set
 			//return ctrl.Result{}, errors.Wrap(err, "function run failed")
 		}
+		for _, res := range results.GetResults(rl.Results) {
+			r.l.Info("function result", "code", res.Code, "severity", res.Severity, "resource", res.Resource, "message", res.Message)
+		}
 		for _, o := range rl.Items {
 			r.l.Info("resourceList", "data", o.String())
 			// TBD what if we create new resources
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Mode selects the key of a reconcile request which assigns the request to a shard
+type Mode string
+
+const (
+	// ByNamespace assigns all the objects of a namespace to the same shard, cluster scoped
+	// objects share the shard of the empty namespace
+	ByNamespace Mode = "namespace"
+	// ByName spreads the objects over the shards by namespace and name, the owned objects
+	// follow their owner since their events are reconciled as requests of the owner
+	ByName Mode = "name"
+)
+
+// Shard is the part of the objects reconciled by a replica of the controller manager, the
+// replicas of the other shards reconcile the other objects
+type Shard struct {
+	// Shards is the number of shards, sharding is disabled with 1 shard or less
+	Shards int
+	// Index is the shard of the replica, from 0 to Shards-1
+	Index int
+	By    Mode
+}
+
+func (r Shard) Enabled() bool {
+	return r.Shards > 1
+}
+
+func (r Shard) Validate() error {
+	if !r.Enabled() {
+		return nil
+	}
+	if r.Index < 0 || r.Index >= r.Shards {
+		return fmt.Errorf("shard index %d out of range [0, %d)", r.Index, r.Shards)
+	}
+	switch r.By {
+	case ByNamespace, ByName:
+		return nil
+	default:
+		return fmt.Errorf("shard mode %q not supported, supported modes: [%s, %s]", r.By, ByNamespace, ByName)
+	}
+}
+
+// Owns returns true when the request belongs to the shard
+func (r Shard) Owns(req ctrl.Request) bool {
+	if !r.Enabled() {
+		return true
+	}
+	key := req.Namespace
+	if r.By == ByName {
+		key = req.NamespacedName.String()
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32()%uint32(r.Shards)) == r.Index
+}
+
+// LeaderElectionID returns the lease of the shard, the replicas of a shard elect their own leader
+func (r Shard) LeaderElectionID(id string) string {
+	if !r.Enabled() {
+		return id
+	}
+	name, domain, _ := strings.Cut(id, ".")
+	return fmt.Sprintf("%s-shard-%d.%s", name, r.Index, domain)
+}
+
+// IndexFromHostname returns the ordinal of the pod of a statefulset, e.g. 2 for nephio-controller-2;
+// the ordinal is unique, hence a shard indexed by the ordinal has a single replica and no failover
+func IndexFromHostname(hostname string) (int, error) {
+	i := strings.LastIndex(hostname, "-")
+	if i < 0 {
+		return 0, fmt.Errorf("hostname %q is not the name of a statefulset pod", hostname)
+	}
+	index, err := strconv.Atoi(hostname[i+1:])
+	if err != nil {
+		return 0, fmt.Errorf("hostname %q is not the name of a statefulset pod: %w", hostname, err)
+	}
+	return index, nil
+}
+
+// NewReconciler returns the reconciler which drops the requests of the other shards; all the
+// replicas watch all the objects, only the reconciles are partitioned
+func NewReconciler(shard Shard, r reconcile.Reconciler) reconcile.Reconciler {
+	if !shard.Enabled() {
+		return r
+	}
+	return &reconciler{shard: shard, r: r}
+}
+
+type reconciler struct {
+	shard Shard
+	r     reconcile.Reconciler
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if !r.shard.Owns(req) {
+		return ctrl.Result{}, nil
+	}
+	return r.r.Reconcile(ctx, req)
+}
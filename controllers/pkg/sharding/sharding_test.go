@@ -0,0 +1,191 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestOwns(t *testing.T) {
+	cases := map[string]struct {
+		by Mode
+		// sameShard are requests which must be reconciled by the same shard
+		sameShard []ctrl.Request
+	}{
+		"ByNamespace": {
+			by: ByNamespace,
+			sameShard: []ctrl.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "a", Name: "x"}},
+				{NamespacedName: types.NamespacedName{Namespace: "a", Name: "y"}},
+				{NamespacedName: types.NamespacedName{Namespace: "a", Name: "z"}},
+			},
+		},
+		"ByName": {
+			by: ByName,
+			sameShard: []ctrl.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "a", Name: "x"}},
+				{NamespacedName: types.NamespacedName{Namespace: "a", Name: "x"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			shards := make([]Shard, 3)
+			for i := range shards {
+				shards[i] = Shard{Shards: 3, Index: i, By: tc.by}
+			}
+			// every request is owned by exactly one shard
+			owner := -1
+			for _, req := range tc.sameShard {
+				owners := []int{}
+				for i, s := range shards {
+					if s.Owns(req) {
+						owners = append(owners, i)
+					}
+				}
+				if len(owners) != 1 {
+					t.Fatalf("request %s owned by shards %v, want exactly one", req, owners)
+				}
+				if owner != -1 && owners[0] != owner {
+					t.Errorf("request %s owned by shard %d, want %d", req, owners[0], owner)
+				}
+				owner = owners[0]
+			}
+		})
+	}
+}
+
+func TestOwnsSpread(t *testing.T) {
+	shards := make([]Shard, 3)
+	for i := range shards {
+		shards[i] = Shard{Shards: 3, Index: i, By: ByName}
+	}
+	counts := make([]int, 3)
+	for i := 0; i < 300; i++ {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("repo-%d", i)}}
+		for j, s := range shards {
+			if s.Owns(req) {
+				counts[j]++
+			}
+		}
+	}
+	for i, c := range counts {
+		if c < 50 {
+			t.Errorf("shard %d owns %d of 300 requests, want a spread over the shards: %v", i, c, counts)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]struct {
+		shard     Shard
+		wantError bool
+	}{
+		"Disabled": {
+			shard:     Shard{},
+			wantError: false,
+		},
+		"Valid": {
+			shard:     Shard{Shards: 2, Index: 1, By: ByNamespace},
+			wantError: false,
+		},
+		"IndexOutOfRange": {
+			shard:     Shard{Shards: 2, Index: 2, By: ByNamespace},
+			wantError: true,
+		},
+		"UnknownMode": {
+			shard:     Shard{Shards: 2, Index: 0, By: "owner"},
+			wantError: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.shard.Validate()
+			if (err != nil) != tc.wantError {
+				t.Errorf("Validate() error = %v, wantError %v", err, tc.wantError)
+			}
+		})
+	}
+}
+
+func TestLeaderElectionID(t *testing.T) {
+	id := "nephio-operators.nephio.org"
+	if got := (Shard{}).LeaderElectionID(id); got != id {
+		t.Errorf("LeaderElectionID() = %s, want %s", got, id)
+	}
+	want := "nephio-operators-shard-1.nephio.org"
+	if got := (Shard{Shards: 2, Index: 1, By: ByName}).LeaderElectionID(id); got != want {
+		t.Errorf("LeaderElectionID() = %s, want %s", got, want)
+	}
+}
+
+func TestIndexFromHostname(t *testing.T) {
+	cases := map[string]struct {
+		hostname  string
+		want      int
+		wantError bool
+	}{
+		"StatefulSet": {hostname: "nephio-controller-2", want: 2},
+		"Deployment":  {hostname: "nephio-controller-7d9f8b-x2x4z", wantError: true},
+		"NoOrdinal":   {hostname: "localhost", wantError: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := IndexFromHostname(tc.hostname)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("IndexFromHostname() error = %v, wantError %v", err, tc.wantError)
+			}
+			if got != tc.want {
+				t.Errorf("IndexFromHostname() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+type countingReconciler struct {
+	requests int
+}
+
+func (r *countingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.requests++
+	return ctrl.Result{}, nil
+}
+
+func TestNewReconciler(t *testing.T) {
+	counts := 0
+	for i := 0; i < 3; i++ {
+		cr := &countingReconciler{}
+		var r reconcile.Reconciler = NewReconciler(Shard{Shards: 3, Index: i, By: ByNamespace}, cr)
+		for _, ns := range []string{"a", "b", "c", "d"} {
+			_, _ = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: ns, Name: "x"}})
+		}
+		counts += cr.requests
+	}
+	// every request is reconciled by a single shard
+	if counts != 4 {
+		t.Errorf("reconciled %d requests, want 4", counts)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specializer
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	ctrlconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
+	configinjectfn "github.com/nephio-project/nephio/krm-functions/configinject-fn/fn"
+	interfacefn "github.com/nephio-project/nephio/krm-functions/interface-fn/fn"
+	ipamfn "github.com/nephio-project/nephio/krm-functions/ipam-fn/fn"
+	nadfn "github.com/nephio-project/nephio/krm-functions/nad-fn/fn"
+	vlanfn "github.com/nephio-project/nephio/krm-functions/vlan-fn/fn"
+)
+
+const (
+	StageInterface    = "interface"
+	StageIPAM         = "ipam"
+	StageVLAN         = "vlan"
+	StageNAD          = "nad"
+	StageConfigInject = "configinject"
+
+	// defaultMaxPasses bounds the passes of the pipeline, a pass specializes the resources
+	// created by the previous pass, e.g. the claims of the interfaces
+	defaultMaxPasses = 5
+)
+
+// Stage is a specializer function of the pipeline
+type Stage struct {
+	Name string
+	Fn   fn.ResourceListProcessorFunc
+}
+
+// Pipeline runs the specializer functions on a package like the specializer pipeline of porch:
+// interface-fn, ipam-fn, vlan-fn, nad-fn and configinject-fn, until the package no longer changes
+type Pipeline struct {
+	Stages    []Stage
+	MaxPasses int
+}
+
+// NewPipeline returns the specializer pipeline with the backends of the controller config
+func NewPipeline(cfg *ctrlconfig.ControllerConfig) *Pipeline {
+	return &Pipeline{
+		Stages: []Stage{
+			{Name: StageInterface, Fn: interfacefn.Run},
+			{Name: StageIPAM, Fn: ipamfn.New(cfg.IpamClientProxy).Run},
+			{Name: StageVLAN, Fn: vlanfn.New(cfg.VlanClientProxy).Run},
+			{Name: StageNAD, Fn: nadfn.Run},
+			{Name: StageConfigInject, Fn: configinjectfn.New(cfg.PorchClient).Run},
+		},
+		MaxPasses: defaultMaxPasses,
+	}
+}
+
+// Run specializes the resources of the ResourceList in place with the function configs of the
+// stages, the results of the ResourceList are the results of the last pass; it returns an error
+// when a stage fails or the package does not converge
+func (r *Pipeline) Run(rl *fn.ResourceList, functionConfigs map[string]*fn.KubeObject) error {
+	for name := range functionConfigs {
+		if !r.hasStage(name) {
+			return fmt.Errorf("function config of unknown stage %s", name)
+		}
+	}
+	// the function config of the ResourceList is restored once the stages ran with theirs
+	fc := rl.FunctionConfig
+	defer func() {
+		rl.FunctionConfig = fc
+	}()
+	maxPasses := r.MaxPasses
+	if maxPasses <= 0 {
+		maxPasses = defaultMaxPasses
+	}
+	for pass := 1; pass <= maxPasses; pass++ {
+		before := itemsYAML(rl)
+		rl.Results = fn.Results{}
+		for _, s := range r.Stages {
+			rl.FunctionConfig = fn.NewEmptyKubeObject()
+			if o, ok := functionConfigs[s.Name]; ok {
+				rl.FunctionConfig = o
+			}
+			if _, err := s.Fn(rl); err != nil {
+				return fmt.Errorf("%s function failed: %s", s.Name, err.Error())
+			}
+			if rl.Results.ExitCode() != 0 {
+				return fmt.Errorf("%s function failed: %s", s.Name, rl.Results.Error())
+			}
+		}
+		if bytes.Equal(before, itemsYAML(rl)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("package not specialized after %d passes", maxPasses)
+}
+
+func (r *Pipeline) hasStage(name string) bool {
+	for _, s := range r.Stages {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// itemsYAML returns the items of the ResourceList in yaml, without the function config and results
+func itemsYAML(rl *fn.ResourceList) []byte {
+	var b bytes.Buffer
+	for _, o := range rl.Items {
+		b.WriteString(o.String())
+		b.WriteString("---\n")
+	}
+	return b.Bytes()
+}
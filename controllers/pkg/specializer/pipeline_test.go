@@ -0,0 +1,154 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specializer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/go-cmp/cmp"
+)
+
+const itfce = `apiVersion: req.nephio.org/v1alpha1
+kind: Interface
+metadata:
+  name: n3
+`
+
+// claimStage creates a claim per interface
+func claimStage(rl *fn.ResourceList) (bool, error) {
+	for _, o := range rl.Items.Where(fn.IsGVK("req.nephio.org", "v1alpha1", "Interface")) {
+		if rl.Items.Where(fn.IsName(o.GetName())).Where(fn.IsGVK("ipam.resource.nephio.org", "v1alpha1", "IPClaim")).Len() > 0 {
+			continue
+		}
+		claim := fn.NewEmptyKubeObject()
+		if err := claim.SetAPIVersion("ipam.resource.nephio.org/v1alpha1"); err != nil {
+			return false, err
+		}
+		if err := claim.SetKind("IPClaim"); err != nil {
+			return false, err
+		}
+		if err := claim.SetName(o.GetName()); err != nil {
+			return false, err
+		}
+		if err := rl.UpsertObjectToItems(claim, nil, false); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// allocateStage allocates the claims with the prefix of its function config
+func allocateStage(rl *fn.ResourceList) (bool, error) {
+	prefix, ok, _ := rl.FunctionConfig.NestedString("data", "prefix")
+	if !ok {
+		prefix = "10.0.0.0/24"
+	}
+	for _, o := range rl.Items.Where(fn.IsGVK("ipam.resource.nephio.org", "v1alpha1", "IPClaim")) {
+		if err := o.SetNestedString(prefix, "status", "prefix"); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// counterStage never converges
+func counterStage(rl *fn.ResourceList) (bool, error) {
+	for _, o := range rl.Items {
+		n, _, _ := o.NestedInt("spec", "counter")
+		if err := o.SetNestedInt(n+1, "spec", "counter"); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func TestPipelineRun(t *testing.T) {
+	cases := map[string]struct {
+		stages          []Stage
+		functionConfigs map[string]string
+		wantPrefix      string
+		wantErr         string
+	}{
+		"Specialized": {
+			stages:     []Stage{{Name: StageInterface, Fn: claimStage}, {Name: StageIPAM, Fn: allocateStage}},
+			wantPrefix: "10.0.0.0/24",
+		},
+		"FunctionConfig": {
+			stages: []Stage{{Name: StageInterface, Fn: claimStage}, {Name: StageIPAM, Fn: allocateStage}},
+			functionConfigs: map[string]string{StageIPAM: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ipam
+data:
+  prefix: 10.1.0.0/24
+`},
+			wantPrefix: "10.1.0.0/24",
+		},
+		"UnknownStage": {
+			stages:          []Stage{{Name: StageInterface, Fn: claimStage}},
+			functionConfigs: map[string]string{StageVLAN: itfce},
+			wantErr:         "function config of unknown stage vlan",
+		},
+		"StageFailed": {
+			stages: []Stage{{Name: StageInterface, Fn: func(rl *fn.ResourceList) (bool, error) {
+				return false, fmt.Errorf("no WorkloadCluster")
+			}}},
+			wantErr: "interface function failed: no WorkloadCluster",
+		},
+		"NotConverged": {
+			stages:  []Stage{{Name: StageInterface, Fn: counterStage}},
+			wantErr: "package not specialized after 5 passes",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o, err := fn.ParseKubeObject([]byte(itfce))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rl := &fn.ResourceList{Items: fn.KubeObjects{o}}
+			functionConfigs := map[string]*fn.KubeObject{}
+			for name, s := range tc.functionConfigs {
+				if functionConfigs[name], err = fn.ParseKubeObject([]byte(s)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			p := &Pipeline{Stages: tc.stages}
+			err = p.Run(rl, functionConfigs)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("want error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			claims := rl.Items.Where(fn.IsGVK("ipam.resource.nephio.org", "v1alpha1", "IPClaim"))
+			if len(claims) != 1 {
+				t.Fatalf("want 1 claim, got %d", len(claims))
+			}
+			got, _, _ := claims[0].NestedString("status", "prefix")
+			if diff := cmp.Diff(tc.wantPrefix, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specializer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/nephio-project/nephio/krm-functions/lib/kptrl"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+)
+
+// readerAnnotations are the annotations set on the resources read from the files of a package
+var readerAnnotations = []string{
+	kioutil.PathAnnotation,
+	kioutil.LegacyPathAnnotation,
+	kioutil.IndexAnnotation,
+	kioutil.LegacyIndexAnnotation,
+	kioutil.IdAnnotation,
+	kioutil.LegacyIdAnnotation,
+}
+
+// getResourceList returns the ResourceList of the files of a package, the files are keyed by
+// their path like the resources of a PackageRevisionResources
+func getResourceList(resources map[string]string) (*fn.ResourceList, error) {
+	return kptrl.GetResourceList(resources)
+}
+
+// getResources returns the files of the package of the specialized ResourceList, the files
+// without resources, e.g. the README.md, are kept from the package; the resources created by the
+// pipeline are written in a file per resource
+func getResources(rl *fn.ResourceList, original map[string]string) (map[string]string, error) {
+	type indexed struct {
+		index int
+		s     string
+	}
+	files := map[string][]indexed{}
+	for _, o := range rl.Items {
+		path := o.GetAnnotation(kioutil.PathAnnotation)
+		if path == "" {
+			path = o.GetAnnotation(kioutil.LegacyPathAnnotation)
+		}
+		if path == "" {
+			path = fmt.Sprintf("%s_%s.yaml", strings.ToLower(o.GetKind()), o.GetName())
+			if o.GetNamespace() != "" {
+				path = filepath.Join(o.GetNamespace(), path)
+			}
+		}
+		index, _ := strconv.Atoi(o.GetAnnotation(kioutil.IndexAnnotation))
+		o, err := fn.ParseKubeObject([]byte(o.String()))
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range readerAnnotations {
+			if _, err := o.RemoveNestedField("metadata", "annotations", a); err != nil {
+				return nil, err
+			}
+		}
+		if len(o.GetAnnotations()) == 0 {
+			if _, err := o.RemoveNestedField("metadata", "annotations"); err != nil {
+				return nil, err
+			}
+		}
+		files[path] = append(files[path], indexed{index: index, s: o.String()})
+	}
+
+	resources := map[string]string{}
+	for path, data := range original {
+		if !isResourceFile(path) {
+			resources[path] = data
+		}
+	}
+	for path, objs := range files {
+		sort.SliceStable(objs, func(i, j int) bool {
+			return objs[i].index < objs[j].index
+		})
+		docs := make([]string, 0, len(objs))
+		for _, o := range objs {
+			docs = append(docs, o.s)
+		}
+		resources[path] = strings.Join(docs, "---\n")
+	}
+	return resources, nil
+}
+
+// isResourceFile returns true for the files read as resources of the package
+func isResourceFile(path string) bool {
+	for _, m := range []string{"*.yaml", "*.yml", "Kptfile"} {
+		if matched, err := filepath.Match(m, filepath.Base(path)); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specializer
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetResources(t *testing.T) {
+	original := map[string]string{
+		"Kptfile": `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: upf
+`,
+		"README.md": "# upf\n",
+		"interfaces.yaml": `apiVersion: req.nephio.org/v1alpha1
+kind: Interface
+metadata:
+  name: n3
+---
+apiVersion: req.nephio.org/v1alpha1
+kind: Interface
+metadata:
+  name: n6
+  annotations:
+    nephio.org/owner: upf
+`,
+		"stale.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: stale
+`,
+	}
+	rl, err := getResourceList(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the stale resource is deleted and a claim is created by the pipeline
+	rl.Items = rl.Items.WhereNot(fn.IsName("stale"))
+	claim, err := fn.ParseKubeObject([]byte(`apiVersion: ipam.resource.nephio.org/v1alpha1
+kind: IPClaim
+metadata:
+  name: n3
+  namespace: upf
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rl.Items = append(rl.Items, claim)
+
+	got, err := getResources(rl, original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"Kptfile":   original["Kptfile"],
+		"README.md": original["README.md"],
+		"interfaces.yaml": `apiVersion: req.nephio.org/v1alpha1
+kind: Interface
+metadata:
+  name: n3
+---
+apiVersion: req.nephio.org/v1alpha1
+kind: Interface
+metadata:
+  name: n6
+  annotations:
+    nephio.org/owner: upf
+`,
+		"upf/ipclaim_n3.yaml": `apiVersion: ipam.resource.nephio.org/v1alpha1
+kind: IPClaim
+metadata:
+  name: n3
+  namespace: upf
+`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
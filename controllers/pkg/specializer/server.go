@@ -0,0 +1,294 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specializer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/go-logr/logr"
+	"github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// HTTPPath is the path of the specialize requests of the http api
+	HTTPPath = "/v1alpha1/specialize"
+	// ServiceName is the name of the specializer service of the grpc api
+	ServiceName = "specializer.nephio.org.v1alpha1.Specializer"
+	// JSONCodecName is the content subtype of the grpc api, the messages are encoded in json
+	JSONCodecName = "json"
+
+	maxRequestBytes = 16 << 20
+)
+
+// SpecializeRequest holds the package to specialize, either as a ResourceList or as the files of
+// the package
+type SpecializeRequest struct {
+	// ResourceList is the kpt function ResourceList of the package in yaml
+	ResourceList string `json:"resourceList,omitempty"`
+	// Resources are the files of the package keyed by their path, like the resources of a
+	// PackageRevisionResources
+	Resources map[string]string `json:"resources,omitempty"`
+	// FunctionConfigs are the yaml function configs keyed by the stage of the pipeline: interface,
+	// ipam, vlan, nad or configinject
+	FunctionConfigs map[string]string `json:"functionConfigs,omitempty"`
+}
+
+// SpecializeResponse holds the specialized package in the format of the request
+type SpecializeResponse struct {
+	// ResourceList is the specialized ResourceList in yaml
+	ResourceList string `json:"resourceList,omitempty"`
+	// Resources are the files of the specialized package keyed by their path
+	Resources map[string]string `json:"resources,omitempty"`
+	// Results are the results of the functions of the pipeline
+	Results fn.Results `json:"results,omitempty"`
+	// Error is the reason the package could not be specialized
+	Error string `json:"error,omitempty"`
+}
+
+// SpecializerServer is the server of the specializer service
+type SpecializerServer interface {
+	Specialize(context.Context, *SpecializeRequest) (*SpecializeResponse, error)
+}
+
+// errInvalidRequest is returned for the requests that cannot be specialized as is
+var errInvalidRequest = errors.New("invalid request")
+
+// Server serves the specializer pipeline over grpc and http, such that packages are specialized
+// without kpt or porch
+type Server struct {
+	pipeline    *Pipeline
+	grpcAddress string
+	httpAddress string
+	// m serializes the runs of the pipeline, the functions are not safe for concurrent use
+	m sync.Mutex
+	l logr.Logger
+}
+
+// NewServer returns the server of the pipeline, an empty address disables its api
+func NewServer(p *Pipeline, grpcAddress, httpAddress string) *Server {
+	return &Server{
+		pipeline:    p,
+		grpcAddress: grpcAddress,
+		httpAddress: httpAddress,
+		l:           ctrl.Log.WithName("specializer"),
+	}
+}
+
+// Specialize runs the pipeline on the package of the request
+func (r *Server) Specialize(ctx context.Context, req *SpecializeRequest) (*SpecializeResponse, error) {
+	if (req.ResourceList == "") == (req.Resources == nil) {
+		return nil, fmt.Errorf("%w: expecting either a resourceList or resources", errInvalidRequest)
+	}
+	functionConfigs := map[string]*fn.KubeObject{}
+	for name, s := range req.FunctionConfigs {
+		o, err := fn.ParseKubeObject([]byte(s))
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot parse the function config of %s: %s", errInvalidRequest, name, err.Error())
+		}
+		functionConfigs[name] = o
+	}
+	var rl *fn.ResourceList
+	var err error
+	if req.ResourceList != "" {
+		rl, err = fn.ParseResourceList([]byte(req.ResourceList))
+	} else {
+		rl, err = getResourceList(req.Resources)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot parse the package: %s", errInvalidRequest, err.Error())
+	}
+
+	r.m.Lock()
+	endRender := kubeobject.StartRender()
+	err = r.pipeline.Run(rl, functionConfigs)
+	endRender()
+	r.m.Unlock()
+	resp := &SpecializeResponse{Results: rl.Results}
+	if err != nil {
+		r.l.Info("package not specialized", "error", err.Error())
+		resp.Error = err.Error()
+		return resp, err
+	}
+	if req.ResourceList != "" {
+		b, err := rl.ToYAML()
+		if err != nil {
+			return nil, err
+		}
+		resp.ResourceList = string(b)
+		return resp, nil
+	}
+	if resp.Resources, err = getResources(rl, req.Resources); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ServeHTTP serves the specialize requests of the http api, a json SpecializeRequest posted to
+// HTTPPath is answered with a json SpecializeResponse
+func (r *Server) ServeHTTP(w http.ResponseWriter, hr *http.Request) {
+	if hr.URL.Path != HTTPPath {
+		http.NotFound(w, hr)
+		return
+	}
+	if hr.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	req := &SpecializeRequest{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, hr.Body, maxRequestBytes)).Decode(req); err != nil {
+		http.Error(w, fmt.Sprintf("cannot decode request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	resp, err := r.Specialize(hr.Context(), req)
+	code := http.StatusOK
+	switch {
+	case errors.Is(err, errInvalidRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil && resp == nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	case err != nil:
+		code = http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		r.l.Error(err, "cannot write response")
+	}
+}
+
+// Start serves the apis until the context is done
+func (r *Server) Start(ctx context.Context) error {
+	errCh := make(chan error, 2)
+	if r.grpcAddress != "" {
+		lis, err := net.Listen("tcp", r.grpcAddress)
+		if err != nil {
+			return err
+		}
+		gs := grpc.NewServer()
+		RegisterSpecializerServer(gs, r)
+		go func() {
+			errCh <- gs.Serve(lis)
+		}()
+		defer gs.GracefulStop()
+		r.l.Info("serving grpc api", "address", r.grpcAddress)
+	}
+	if r.httpAddress != "" {
+		hs := &http.Server{Addr: r.httpAddress, Handler: r, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := hs.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				errCh <- err
+			}
+		}()
+		defer hs.Shutdown(context.Background()) //nolint:errcheck
+		r.l.Info("serving http api", "address", r.httpAddress)
+	}
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
+
+// NeedLeaderElection returns false, every replica of the manager serves the apis
+func (r *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// jsonCodec encodes the messages of the grpc api in json, the api has no protobuf definition
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return JSONCodecName }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*SpecializerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Specialize", Handler: specializeHandler},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// RegisterSpecializerServer registers the specializer service on the grpc server
+func RegisterSpecializerServer(s grpc.ServiceRegistrar, srv SpecializerServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+func specializeHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	req := &SpecializeRequest{}
+	if err := dec(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		resp, err := srv.(SpecializerServer).Specialize(ctx, req.(*SpecializeRequest))
+		switch {
+		case errors.Is(err, errInvalidRequest):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case err != nil && resp != nil:
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case err != nil:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return resp, nil
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fmt.Sprintf("/%s/Specialize", ServiceName)}
+	return interceptor(ctx, req, info, handler)
+}
+
+// Client is the client of the grpc api
+type Client struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewClient returns the client of the specializer service of the connection
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{cc: cc}
+}
+
+// Specialize specializes the package of the request
+func (r *Client) Specialize(ctx context.Context, req *SpecializeRequest, opts ...grpc.CallOption) (*SpecializeResponse, error) {
+	resp := &SpecializeResponse{}
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(JSONCodecName)}, opts...)
+	if err := r.cc.Invoke(ctx, fmt.Sprintf("/%s/Specialize", ServiceName), req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package specializer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const resourceList = `apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: req.nephio.org/v1alpha1
+  kind: Interface
+  metadata:
+    name: n3
+`
+
+func newTestServer() *Server {
+	return NewServer(&Pipeline{Stages: []Stage{
+		{Name: StageInterface, Fn: claimStage},
+		{Name: StageIPAM, Fn: allocateStage},
+	}}, "", "")
+}
+
+func TestServerHTTP(t *testing.T) {
+	cases := map[string]struct {
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantErr  string
+	}{
+		"ResourceList": {
+			method:   http.MethodPost,
+			path:     HTTPPath,
+			body:     mustJSON(t, &SpecializeRequest{ResourceList: resourceList}),
+			wantCode: http.StatusOK,
+		},
+		"Resources": {
+			method:   http.MethodPost,
+			path:     HTTPPath,
+			body:     mustJSON(t, &SpecializeRequest{Resources: map[string]string{"n3.yaml": itfce}}),
+			wantCode: http.StatusOK,
+		},
+		"NoPackage": {
+			method:   http.MethodPost,
+			path:     HTTPPath,
+			body:     "{}",
+			wantCode: http.StatusBadRequest,
+		},
+		"UnknownStage": {
+			method:   http.MethodPost,
+			path:     HTTPPath,
+			body:     mustJSON(t, &SpecializeRequest{ResourceList: resourceList, FunctionConfigs: map[string]string{"dnn": itfce}}),
+			wantCode: http.StatusUnprocessableEntity,
+			wantErr:  "function config of unknown stage dnn",
+		},
+		"Method": {
+			method:   http.MethodGet,
+			path:     HTTPPath,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		"Path": {
+			method:   http.MethodPost,
+			path:     "/specialize",
+			body:     "{}",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newTestServer().ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+			if w.Code != tc.wantCode {
+				t.Fatalf("want code %d, got %d: %s", tc.wantCode, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK && w.Code != http.StatusUnprocessableEntity {
+				return
+			}
+			resp := &SpecializeResponse{}
+			if err := json.NewDecoder(w.Body).Decode(resp); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.wantErr, resp.Error); diff != "" {
+				t.Errorf("error -want, +got:\n%s", diff)
+			}
+			if tc.wantErr == "" && !strings.Contains(resp.ResourceList+resp.Resources["ipclaim_n3.yaml"], "prefix: 10.0.0.0/24") {
+				t.Errorf("package not specialized: %v", resp)
+			}
+		})
+	}
+}
+
+func TestServerGRPC(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	RegisterSpecializerServer(gs, newTestServer())
+	go func() {
+		_ = gs.Serve(lis)
+	}()
+	defer gs.Stop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := NewClient(conn)
+
+	resp, err := c.Specialize(context.Background(), &SpecializeRequest{ResourceList: resourceList})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	rl, err := fn.ParseResourceList([]byte(resp.ResourceList))
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := rl.Items.Where(fn.IsGVK("ipam.resource.nephio.org", "v1alpha1", "IPClaim"))
+	if len(claims) != 1 {
+		t.Fatalf("want 1 claim, got %d", len(claims))
+	}
+
+	_, err = c.Specialize(context.Background(), &SpecializeRequest{})
+	if diff := cmp.Diff(codes.InvalidArgument, status.Code(err)); diff != "" {
+		t.Errorf("code -want, +got:\n%s", diff)
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	var b bytes.Buffer
+	if err := json.NewEncoder(&b).Encode(v); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// validateCapacity validates the capacity is not negative
+func validateCapacity(ctx context.Context, capacity *nephioreqv1alpha1.Capacity) (admission.Warnings, error) {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	if capacity.Spec.MaxUplinkThroughput.Sign() < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("maxUplinkThroughput"), capacity.Spec.MaxUplinkThroughput.String(), "must not be negative"))
+	}
+	if capacity.Spec.MaxDownlinkThroughput.Sign() < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("maxDownlinkThroughput"), capacity.Spec.MaxDownlinkThroughput.String(), "must not be negative"))
+	}
+	if capacity.Spec.MaxSessions < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("maxSessions"), capacity.Spec.MaxSessions, "must not be negative"))
+	}
+	if capacity.Spec.MaxSubscribers < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("maxSubscribers"), capacity.Spec.MaxSubscribers, "must not be negative"))
+	}
+	if len(allErrs) != 0 {
+		return nil, apierrors.NewInvalid(nephioreqv1alpha1.CapacityGroupVersionKind.GroupKind(), capacity.GetName(), allErrs)
+	}
+	return nil, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// ConversionPath is the path of the conversion webhook of the custom resources served in multiple
+// versions, e.g. the WorkloadCluster and Network of infra.nephio.org in v1alpha1 and v1alpha2
+const ConversionPath = "/convert"
+
+// conversionHandler converts the objects of a ConversionReview with the conversions registered in
+// the kubeobject library, the same conversions the functions use to read the other versions
+type conversionHandler struct{}
+
+func (r *conversionHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	review := &apiextensionsv1.ConversionReview{}
+	if err := json.NewDecoder(req.Body).Decode(review); err != nil || review.Request == nil {
+		http.Error(w, "invalid ConversionReview", http.StatusBadRequest)
+		return
+	}
+	review.Response = convert(review.Request)
+	review.Request = nil
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(review)
+}
+
+// convert converts all the objects to the desired api version, or none when any conversion fails
+func convert(req *apiextensionsv1.ConversionRequest) *apiextensionsv1.ConversionResponse {
+	resp := &apiextensionsv1.ConversionResponse{UID: req.UID}
+	objs, err := convertObjects(req.Objects, req.DesiredAPIVersion)
+	if err != nil {
+		resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+		return resp
+	}
+	resp.ConvertedObjects = objs
+	resp.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return resp
+}
+
+func convertObjects(objs []runtime.RawExtension, desiredAPIVersion string) ([]runtime.RawExtension, error) {
+	gv, err := schema.ParseGroupVersion(desiredAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+	converted := make([]runtime.RawExtension, 0, len(objs))
+	for _, raw := range objs {
+		o, err := fn.ParseKubeObject(raw.Raw)
+		if err != nil {
+			return nil, err
+		}
+		if o.GroupVersionKind().Group != gv.Group {
+			return nil, fmt.Errorf("cannot convert %s %s to the group %s", o.GetKind(), o.GetName(), gv.Group)
+		}
+		x, err := kubeobject.ConvertKubeObject(o, gv.Version)
+		if err != nil {
+			return nil, err
+		}
+		b, err := yaml.YAMLToJSON([]byte(x.String()))
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, runtime.RawExtension{Raw: b})
+	}
+	return converted, nil
+}
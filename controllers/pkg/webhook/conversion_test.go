@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestConversion(t *testing.T) {
+	cluster := `{"apiVersion":"infra.nephio.org/v1alpha1","kind":"WorkloadCluster","metadata":{"name":"edge01"},"spec":{"clusterName":"edge01","cnis":["macvlan"]}}`
+	cases := map[string]struct {
+		desiredAPIVersion string
+		wantStatus        string
+		wantObjects       []string
+	}{
+		"Converted": {
+			desiredAPIVersion: "infra.nephio.org/v1alpha2",
+			wantStatus:        metav1.StatusSuccess,
+			wantObjects:       []string{`{"apiVersion":"infra.nephio.org/v1alpha2","kind":"WorkloadCluster","metadata":{"name":"edge01"},"spec":{"clusterName":"edge01","cnis":["macvlan"]}}`},
+		},
+		"SameVersion": {
+			desiredAPIVersion: "infra.nephio.org/v1alpha1",
+			wantStatus:        metav1.StatusSuccess,
+			wantObjects:       []string{cluster},
+		},
+		"NoConversion": {
+			desiredAPIVersion: "infra.nephio.org/v1beta1",
+			wantStatus:        metav1.StatusFailure,
+		},
+		"OtherGroup": {
+			desiredAPIVersion: "req.nephio.org/v1alpha1",
+			wantStatus:        metav1.StatusFailure,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			review := &apiextensionsv1.ConversionReview{
+				TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+				Request: &apiextensionsv1.ConversionRequest{
+					UID:               "1",
+					DesiredAPIVersion: tc.desiredAPIVersion,
+					Objects:           []runtime.RawExtension{{Raw: []byte(cluster)}},
+				},
+			}
+			b, err := json.Marshal(review)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			(&conversionHandler{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, ConversionPath, bytes.NewReader(b)))
+			if !assert.Equal(t, http.StatusOK, w.Code) {
+				return
+			}
+			got := &apiextensionsv1.ConversionReview{}
+			if err := json.Unmarshal(w.Body.Bytes(), got); err != nil {
+				t.Fatal(err)
+			}
+			assert.Nil(t, got.Request)
+			assert.Equal(t, "1", string(got.Response.UID))
+			assert.Equal(t, tc.wantStatus, got.Response.Result.Status)
+			objs := []string{}
+			for _, o := range got.Response.ConvertedObjects {
+				objs = append(objs, string(o.Raw))
+			}
+			if len(tc.wantObjects) == 0 {
+				assert.Empty(t, objs)
+				return
+			}
+			assert.Equal(t, len(tc.wantObjects), len(objs))
+			for i := range tc.wantObjects {
+				assert.JSONEq(t, tc.wantObjects[i], objs[i])
+			}
+		})
+	}
+}
+
+func TestConversionInvalidReview(t *testing.T) {
+	w := httptest.NewRecorder()
+	(&conversionHandler{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, ConversionPath, bytes.NewReader([]byte("{}"))))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
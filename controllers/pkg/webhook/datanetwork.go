@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var ipFamilies = []string{string(nephioreqv1alpha1.IPFamilyIPv4), string(nephioreqv1alpha1.IPFamilyIPv6)}
+
+// validateDataNetwork validates the network instance and the pools of a data network, a pool
+// without prefix length is sized by the dnn fn from the capacity
+func validateDataNetwork(ctx context.Context, dnn *nephioreqv1alpha1.DataNetwork) (admission.Warnings, error) {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	if dnn.Spec.NetworkInstance.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("networkInstance", "name"), ""))
+	}
+	names := map[string]bool{}
+	for i, pool := range dnn.Spec.Pools {
+		poolPath := specPath.Child("pools").Index(i)
+		if pool == nil {
+			allErrs = append(allErrs, field.Required(poolPath, ""))
+			continue
+		}
+		if pool.Name == "" {
+			allErrs = append(allErrs, field.Required(poolPath.Child("name"), ""))
+		} else if names[pool.Name] {
+			allErrs = append(allErrs, field.Duplicate(poolPath.Child("name"), pool.Name))
+		}
+		names[pool.Name] = true
+
+		maxPrefixLength := uint8(32)
+		switch pool.IPFamily {
+		case "", nephioreqv1alpha1.IPFamilyIPv4:
+		case nephioreqv1alpha1.IPFamilyIPv6:
+			maxPrefixLength = 128
+		default:
+			allErrs = append(allErrs, field.NotSupported(poolPath.Child("ipFamily"), pool.IPFamily, ipFamilies))
+		}
+		if pool.PrefixLength > maxPrefixLength {
+			allErrs = append(allErrs, field.Invalid(poolPath.Child("prefixLength"), int(pool.PrefixLength), "exceeds the length of the addresses of the ip family"))
+		}
+	}
+	if len(allErrs) != 0 {
+		return nil, apierrors.NewInvalid(nephioreqv1alpha1.DataNetworkGroupVersionKind.GroupKind(), dnn.GetName(), allErrs)
+	}
+	return nil, nil
+}
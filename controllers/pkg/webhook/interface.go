@@ -0,0 +1,208 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// the annotations of an interface read by the interface fn
+const (
+	staticPrefixesAnnotation = "nephio.org/static-prefixes"
+	staticGatewaysAnnotation = "nephio.org/static-gateways"
+	vlanRangeAnnotation      = "nephio.org/vlan-range"
+
+	minVLANID = 1
+	maxVLANID = 4094
+)
+
+var (
+	cniTypes         = []string{string(nephioreqv1alpha1.CNITypeSRIOV), string(nephioreqv1alpha1.CNITypeIPVLAN), string(nephioreqv1alpha1.CNITypeMACVLAN)}
+	attachmentTypes  = []string{string(nephioreqv1alpha1.AttachmentTypeNone), string(nephioreqv1alpha1.AttachmentTypeVLAN)}
+	ipFamilyPolicies = []string{
+		string(nephioreqv1alpha1.IpFamilyPolicyNone),
+		string(nephioreqv1alpha1.IpFamilyPolicyIPv4Only),
+		string(nephioreqv1alpha1.IpFamilyPolicyIPv6Only),
+		string(nephioreqv1alpha1.IpFamilyPolicyDualStack),
+	}
+)
+
+// interfaceValidator validates an interface; the cni type of an interface of a cluster, with
+// the nephio.org/cluster-name label or annotation, is validated against the cnis of the
+// workload cluster of the namespace with the same cluster name
+type interfaceValidator struct {
+	client client.Reader
+}
+
+func (r *interfaceValidator) validate(ctx context.Context, itfce *nephioreqv1alpha1.Interface) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	allErrs := validateInterfaceSpec(&itfce.Spec, field.NewPath("spec"))
+	allErrs = append(allErrs, validateInterfaceAnnotations(itfce, field.NewPath("metadata", "annotations"))...)
+
+	clusterName := itfce.GetLabels()[resourcev1alpha1.NephioClusterNameKey]
+	if clusterName == "" {
+		clusterName = itfce.GetAnnotations()[resourcev1alpha1.NephioClusterNameKey]
+	}
+	// an interface without attachment is not attached to the pod and does not need a cni
+	if clusterName != "" && itfce.Spec.CNIType != "" && itfce.Spec.AttachmentType != nephioreqv1alpha1.AttachmentTypeNone {
+		cluster, err := r.getWorkloadCluster(ctx, itfce.GetNamespace(), clusterName)
+		if err != nil {
+			return nil, err
+		}
+		if cluster == nil {
+			warnings = append(warnings, fmt.Sprintf("workload cluster %s not found in namespace %s, the cniType is not validated", clusterName, itfce.GetNamespace()))
+		} else if !hasString(cluster.Spec.CNIs, string(itfce.Spec.CNIType)) {
+			allErrs = append(allErrs, field.NotSupported(field.NewPath("spec", "cniType"), itfce.Spec.CNIType, cluster.Spec.CNIs))
+		}
+	}
+	if len(allErrs) != 0 {
+		return warnings, apierrors.NewInvalid(nephioreqv1alpha1.InterfaceGroupVersionKind.GroupKind(), itfce.GetName(), allErrs)
+	}
+	return warnings, nil
+}
+
+// getWorkloadCluster returns the workload cluster with the cluster name, nil when not found
+func (r *interfaceValidator) getWorkloadCluster(ctx context.Context, namespace, clusterName string) (*infrav1alpha1.WorkloadCluster, error) {
+	clusters := &infrav1alpha1.WorkloadClusterList{}
+	if err := r.client.List(ctx, clusters, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("cannot list workload clusters: %w", err)
+	}
+	for i := range clusters.Items {
+		if clusters.Items[i].Spec.ClusterName == clusterName {
+			return &clusters.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func validateInterfaceSpec(spec *nephioreqv1alpha1.InterfaceSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.NetworkInstance == nil || spec.NetworkInstance.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("networkInstance", "name"), ""))
+	}
+	if spec.CNIType != "" && !nephioreqv1alpha1.IsCNITypeSupported(string(spec.CNIType)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("cniType"), spec.CNIType, cniTypes))
+	}
+	if spec.AttachmentType != "" && !nephioreqv1alpha1.IsAttachmentTypeSupported(string(spec.AttachmentType)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("attachmentType"), spec.AttachmentType, attachmentTypes))
+	}
+	if spec.IpFamilyPolicy != "" && !nephioreqv1alpha1.IsIPFamilyPolicySupported(string(spec.IpFamilyPolicy)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("ipFamilyPolicy"), spec.IpFamilyPolicy, ipFamilyPolicies))
+	}
+	return allErrs
+}
+
+// validateInterfaceAnnotations validates the syntax of the static prefixes and gateways and the
+// bounds of the vlan range, the interface fn validates them against the ip families and the vlan index
+func validateInterfaceAnnotations(itfce *nephioreqv1alpha1.Interface, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	annotations := itfce.GetAnnotations()
+
+	prefixes := []netip.Prefix{}
+	for _, s := range splitList(annotations[staticPrefixesAnnotation]) {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(staticPrefixesAnnotation), s, err.Error()))
+			continue
+		}
+		prefixes = append(prefixes, p.Masked())
+	}
+	for _, s := range splitList(annotations[staticGatewaysAnnotation]) {
+		a, err := netip.ParseAddr(s)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(staticGatewaysAnnotation), s, err.Error()))
+			continue
+		}
+		if !containsAddr(prefixes, a) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(staticGatewaysAnnotation), s, "the gateway is not part of a static prefix"))
+		}
+	}
+
+	if vlanRange, ok := annotations[vlanRangeAnnotation]; ok {
+		if itfce.Spec.AttachmentType != nephioreqv1alpha1.AttachmentTypeVLAN {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(vlanRangeAnnotation), vlanRange, fmt.Sprintf("requires attachmentType %s", nephioreqv1alpha1.AttachmentTypeVLAN)))
+		}
+		if err := validateVLANRange(vlanRange); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(vlanRangeAnnotation), vlanRange, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validateVLANRange validates a vlan range as start:end, or as the number of vlans, within the
+// vlan ids 1 to 4094
+func validateVLANRange(vlanRange string) error {
+	start, end, isRange := strings.Cut(vlanRange, ":")
+	s, err := strconv.Atoi(start)
+	if err != nil {
+		return fmt.Errorf("expected start:end or a size")
+	}
+	if !isRange {
+		if s < minVLANID || s > maxVLANID {
+			return fmt.Errorf("the size must be between %d and %d", minVLANID, maxVLANID)
+		}
+		return nil
+	}
+	e, err := strconv.Atoi(end)
+	if err != nil {
+		return fmt.Errorf("expected start:end or a size")
+	}
+	if s < minVLANID || e > maxVLANID || s > e {
+		return fmt.Errorf("the range must be within %d:%d with start <= end", minVLANID, maxVLANID)
+	}
+	return nil
+}
+
+func containsAddr(prefixes []netip.Prefix, a netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(a) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(s string) []string {
+	l := []string{}
+	for _, x := range strings.Split(s, ",") {
+		if x = strings.TrimSpace(x); x != "" {
+			l = append(l, x)
+		}
+	}
+	return l
+}
+
+func hasString(l []string, s string) bool {
+	for _, x := range l {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
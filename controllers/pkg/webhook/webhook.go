@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:webhook:path=/validate-req-nephio-org-v1alpha1-interface,mutating=false,failurePolicy=fail,sideEffects=None,groups=req.nephio.org,resources=interfaces,verbs=create;update,versions=v1alpha1,name=vinterface.req.nephio.org,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-req-nephio-org-v1alpha1-datanetwork,mutating=false,failurePolicy=fail,sideEffects=None,groups=req.nephio.org,resources=datanetworks,verbs=create;update,versions=v1alpha1,name=vdatanetwork.req.nephio.org,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-req-nephio-org-v1alpha1-capacity,mutating=false,failurePolicy=fail,sideEffects=None,groups=req.nephio.org,resources=capacities,verbs=create;update,versions=v1alpha1,name=vcapacity.req.nephio.org,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-infra-nephio-org-v1alpha1-workloadcluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=infra.nephio.org,resources=workloadclusters,verbs=create;update,versions=v1alpha1,name=vworkloadcluster.infra.nephio.org,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups=infra.nephio.org,resources=workloadclusters,verbs=get;list;watch
+
+// SetupWithManager registers the validating webhooks of the requirements and the workload clusters,
+// and the conversion webhook of the infra resources, with the webhook server of the manager
+func SetupWithManager(mgr ctrl.Manager) error {
+	if err := infrav1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		return err
+	}
+	if err := addRequirementsToScheme(mgr.GetScheme()); err != nil {
+		return err
+	}
+
+	webhooks := []struct {
+		obj       runtime.Object
+		validator admission.CustomValidator
+	}{
+		{obj: &nephioreqv1alpha1.Interface{}, validator: newValidator((&interfaceValidator{client: mgr.GetClient()}).validate)},
+		{obj: &nephioreqv1alpha1.DataNetwork{}, validator: newValidator(validateDataNetwork)},
+		{obj: &nephioreqv1alpha1.Capacity{}, validator: newValidator(validateCapacity)},
+		{obj: &infrav1alpha1.WorkloadCluster{}, validator: newValidator(validateWorkloadCluster)},
+	}
+	for _, w := range webhooks {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(w.obj).WithValidator(w.validator).Complete(); err != nil {
+			return fmt.Errorf("cannot register the webhook of %T: %w", w.obj, err)
+		}
+	}
+	mgr.GetWebhookServer().Register(ConversionPath, &conversionHandler{})
+	return nil
+}
+
+// addRequirementsToScheme registers the requirements, the api module only registers them
+// in the kpt packages and not in a scheme
+func addRequirementsToScheme(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(nephioreqv1alpha1.GroupVersion,
+		&nephioreqv1alpha1.Interface{},
+		&nephioreqv1alpha1.DataNetwork{},
+		&nephioreqv1alpha1.Capacity{},
+	)
+	metav1.AddToGroupVersion(scheme, nephioreqv1alpha1.GroupVersion)
+	return nil
+}
+
+// validator validates an object of type T on create and update, deletes are always allowed
+type validator[T runtime.Object] struct {
+	validate func(ctx context.Context, o T) (admission.Warnings, error)
+}
+
+func newValidator[T runtime.Object](validate func(ctx context.Context, o T) (admission.Warnings, error)) admission.CustomValidator {
+	return &validator[T]{validate: validate}
+}
+
+func (r *validator[T]) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return r.validateObject(ctx, obj)
+}
+
+func (r *validator[T]) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return r.validateObject(ctx, newObj)
+}
+
+func (r *validator[T]) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *validator[T]) validateObject(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	o, ok := obj.(T)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T", obj)
+	}
+	return r.validate(ctx, o)
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// clusterReader lists the workload clusters
+type clusterReader struct {
+	clusters []infrav1alpha1.WorkloadCluster
+}
+
+func (r *clusterReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (r *clusterReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	l, ok := list.(*infrav1alpha1.WorkloadClusterList)
+	if !ok {
+		return fmt.Errorf("unexpected list %T", list)
+	}
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	for _, c := range r.clusters {
+		if c.Namespace == listOpts.Namespace {
+			l.Items = append(l.Items, c)
+		}
+	}
+	return nil
+}
+
+func TestValidateInterface(t *testing.T) {
+	cluster := infrav1alpha1.WorkloadCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge01", Namespace: "default"},
+		Spec: infrav1alpha1.WorkloadClusterSpec{
+			ClusterName:     "edge01",
+			CNIs:            []string{"macvlan"},
+			MasterInterface: pointer.String("eth1"),
+		},
+	}
+	itfce := func(cniType nephioreqv1alpha1.CNIType, attachmentType nephioreqv1alpha1.AttachmentType, annotations map[string]string) *nephioreqv1alpha1.Interface {
+		return &nephioreqv1alpha1.Interface{
+			ObjectMeta: metav1.ObjectMeta{Name: "n3", Namespace: "default", Annotations: annotations},
+			Spec: nephioreqv1alpha1.InterfaceSpec{
+				NetworkInstance: &corev1.ObjectReference{Name: "vpc-ran"},
+				CNIType:         cniType,
+				AttachmentType:  attachmentType,
+			},
+		}
+	}
+	cases := map[string]struct {
+		itfce        *nephioreqv1alpha1.Interface
+		wantErrors   []string
+		wantWarnings int
+	}{
+		"Valid": {
+			itfce: itfce("macvlan", "vlan", map[string]string{
+				"nephio.org/cluster-name": "edge01",
+				staticPrefixesAnnotation:  "10.0.0.10/24,2001:db8::10/64",
+				staticGatewaysAnnotation:  "10.0.0.1",
+				vlanRangeAnnotation:       "100:199",
+			}),
+		},
+		"MissingNetworkInstance": {
+			itfce:      &nephioreqv1alpha1.Interface{ObjectMeta: metav1.ObjectMeta{Name: "n3"}},
+			wantErrors: []string{"spec.networkInstance.name: Required value"},
+		},
+		"UnsupportedTypes": {
+			itfce:      itfce("calico", "trunk", nil),
+			wantErrors: []string{`spec.cniType: Unsupported value: "calico"`, `spec.attachmentType: Unsupported value: "trunk"`},
+		},
+		"CNINotInCluster": {
+			itfce:      itfce("sriov", "vlan", map[string]string{"nephio.org/cluster-name": "edge01"}),
+			wantErrors: []string{`spec.cniType: Unsupported value: "sriov": supported values: "macvlan"`},
+		},
+		"CNINotAttached": {
+			itfce: itfce("sriov", "none", map[string]string{"nephio.org/cluster-name": "edge01"}),
+		},
+		"ClusterNotFound": {
+			itfce:        itfce("sriov", "vlan", map[string]string{"nephio.org/cluster-name": "edge02"}),
+			wantWarnings: 1,
+		},
+		"InvalidStaticPrefixes": {
+			itfce: itfce("macvlan", "vlan", map[string]string{
+				staticPrefixesAnnotation: "10.0.0.300/24",
+				staticGatewaysAnnotation: "10.0.1.1",
+			}),
+			wantErrors: []string{"metadata.annotations[nephio.org/static-prefixes]", "metadata.annotations[nephio.org/static-gateways]"},
+		},
+		"VLANRangeOutOfBounds": {
+			itfce:      itfce("macvlan", "vlan", map[string]string{vlanRangeAnnotation: "4000:4095"}),
+			wantErrors: []string{"metadata.annotations[nephio.org/vlan-range]"},
+		},
+		"VLANRangeWithoutVLANAttachment": {
+			itfce:      itfce("macvlan", "none", map[string]string{vlanRangeAnnotation: "10"}),
+			wantErrors: []string{"requires attachmentType vlan"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := newValidator((&interfaceValidator{client: &clusterReader{clusters: []infrav1alpha1.WorkloadCluster{cluster}}}).validate)
+			warnings, err := v.ValidateCreate(context.Background(), tc.itfce)
+			assertErrors(t, tc.wantErrors, err)
+			assert.Len(t, warnings, tc.wantWarnings)
+		})
+	}
+}
+
+func TestValidateVLANRange(t *testing.T) {
+	cases := map[string]bool{
+		"10":       true,
+		"100:200":  true,
+		"1:4094":   true,
+		"0":        false,
+		"5000":     false,
+		"0:10":     false,
+		"200:100":  false,
+		"10:4095":  false,
+		"10-20":    false,
+		"10:20:30": false,
+		"":         false,
+	}
+	for vlanRange, valid := range cases {
+		t.Run(vlanRange, func(t *testing.T) {
+			err := validateVLANRange(vlanRange)
+			if (err == nil) != valid {
+				t.Errorf("validateVLANRange(%q) error = %v, want valid %t", vlanRange, err, valid)
+			}
+		})
+	}
+}
+
+func TestValidateDataNetwork(t *testing.T) {
+	cases := map[string]struct {
+		pools      []*nephioreqv1alpha1.Pool
+		wantErrors []string
+	}{
+		"Valid": {
+			pools: []*nephioreqv1alpha1.Pool{{Name: "pool1", PrefixLength: 16}, {Name: "pool2", IPFamily: "ipv6", PrefixLength: 48}},
+		},
+		"DuplicatePool": {
+			pools:      []*nephioreqv1alpha1.Pool{{Name: "pool1"}, {Name: "pool1"}},
+			wantErrors: []string{`spec.pools[1].name: Duplicate value: "pool1"`},
+		},
+		"PrefixLength": {
+			pools:      []*nephioreqv1alpha1.Pool{{Name: "pool1", PrefixLength: 48}},
+			wantErrors: []string{"spec.pools[0].prefixLength: Invalid value: 48"},
+		},
+		"IPFamily": {
+			pools:      []*nephioreqv1alpha1.Pool{{Name: "pool1", IPFamily: "ipv5"}},
+			wantErrors: []string{`spec.pools[0].ipFamily: Unsupported value: "ipv5"`},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dnn := &nephioreqv1alpha1.DataNetwork{
+				ObjectMeta: metav1.ObjectMeta{Name: "internet"},
+				Spec: nephioreqv1alpha1.DataNetworkSpec{
+					NetworkInstance: corev1.ObjectReference{Name: "vpc-internet"},
+					Pools:           tc.pools,
+				},
+			}
+			_, err := newValidator(validateDataNetwork).ValidateUpdate(context.Background(), dnn, dnn)
+			assertErrors(t, tc.wantErrors, err)
+		})
+	}
+}
+
+func TestValidateCapacity(t *testing.T) {
+	capacity := &nephioreqv1alpha1.Capacity{
+		ObjectMeta: metav1.ObjectMeta{Name: "upf"},
+		Spec: nephioreqv1alpha1.CapacitySpec{
+			MaxUplinkThroughput:   resource.MustParse("-1G"),
+			MaxDownlinkThroughput: resource.MustParse("5G"),
+			MaxSessions:           -1,
+		},
+	}
+	_, err := newValidator(validateCapacity).ValidateCreate(context.Background(), capacity)
+	assertErrors(t, []string{"spec.maxUplinkThroughput", "spec.maxSessions"}, err)
+}
+
+func TestValidateWorkloadCluster(t *testing.T) {
+	cases := map[string]struct {
+		spec       infrav1alpha1.WorkloadClusterSpec
+		wantErrors []string
+	}{
+		"Valid": {
+			spec: infrav1alpha1.WorkloadClusterSpec{ClusterName: "edge01", CNIs: []string{"macvlan", "bridge"}, MasterInterface: pointer.String("eth1")},
+		},
+		"Missing": {
+			spec:       infrav1alpha1.WorkloadClusterSpec{},
+			wantErrors: []string{"spec.clusterName: Required value", "spec.cnis: Required value", "spec.masterInterface: Required value"},
+		},
+		"DuplicateCNI": {
+			spec:       infrav1alpha1.WorkloadClusterSpec{ClusterName: "edge01", CNIs: []string{"macvlan", "macvlan"}, MasterInterface: pointer.String("eth1")},
+			wantErrors: []string{`spec.cnis[1]: Duplicate value: "macvlan"`},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cluster := &infrav1alpha1.WorkloadCluster{ObjectMeta: metav1.ObjectMeta{Name: "edge01"}, Spec: tc.spec}
+			_, err := newValidator(validateWorkloadCluster).ValidateCreate(context.Background(), cluster)
+			assertErrors(t, tc.wantErrors, err)
+		})
+	}
+}
+
+func TestValidateDelete(t *testing.T) {
+	_, err := newValidator(validateWorkloadCluster).ValidateDelete(context.Background(), &infrav1alpha1.WorkloadCluster{})
+	assert.NoError(t, err)
+}
+
+// assertErrors asserts the error contains all the wanted errors, or no error when none is wanted
+func assertErrors(t *testing.T, want []string, err error) {
+	t.Helper()
+	if len(want) == 0 {
+		assert.NoError(t, err)
+		return
+	}
+	if !assert.Error(t, err) {
+		return
+	}
+	for _, w := range want {
+		assert.Contains(t, err.Error(), w)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// validateWorkloadCluster validates the mandatory fields the functions read from a workload cluster,
+// the cnis are not restricted to the cni types of the interfaces since the nad fn renders e.g. bridge
+func validateWorkloadCluster(ctx context.Context, cluster *infrav1alpha1.WorkloadCluster) (admission.Warnings, error) {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	if cluster.Spec.ClusterName == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("clusterName"), ""))
+	}
+	if len(cluster.Spec.CNIs) == 0 {
+		allErrs = append(allErrs, field.Required(specPath.Child("cnis"), ""))
+	}
+	cnis := map[string]bool{}
+	for i, cni := range cluster.Spec.CNIs {
+		if cnis[cni] {
+			allErrs = append(allErrs, field.Duplicate(specPath.Child("cnis").Index(i), cni))
+		}
+		cnis[cni] = true
+	}
+	if cluster.Spec.MasterInterface == nil || *cluster.Spec.MasterInterface == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("masterInterface"), ""))
+	}
+	if len(allErrs) != 0 {
+		return nil, apierrors.NewInvalid(infrav1alpha1.WorkloadClusterGroupVersionKind.GroupKind(), cluster.GetName(), allErrs)
+	}
+	return nil, nil
+}
@@ -0,0 +1,78 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// frrConfigKey is the key of the FRR configuration in the data of the ConfigMap
+const frrConfigKey = "frr.conf"
+
+// getFRRConfig returns a ConfigMap with the FRR configuration announcing the prefixes, with a
+// BGP instance per routing table, the default routing table uses the default VRF
+func (r advertisements) getFRRConfig(forFullName string, cfg *config) (fn.KubeObjects, error) {
+	var sb strings.Builder
+	for _, rt := range r.routingTables() {
+		if rt == defaultRoutingTable {
+			fmt.Fprintf(&sb, "router bgp %d\n", cfg.asn)
+		} else {
+			fmt.Fprintf(&sb, "router bgp %d vrf %s\n", cfg.asn, rt)
+		}
+		for _, af := range []string{"ipv4", "ipv6"} {
+			networks := []string{}
+			for _, p := range r[rt] {
+				if p.Addr().Is4() == (af == "ipv4") {
+					networks = append(networks, p.String())
+				}
+			}
+			if len(networks) == 0 {
+				continue
+			}
+			fmt.Fprintf(&sb, " address-family %s unicast\n", af)
+			for _, n := range networks {
+				fmt.Fprintf(&sb, "  network %s\n", n)
+			}
+			sb.WriteString(" exit-address-family\n")
+		}
+		sb.WriteString("exit\n")
+	}
+	o, err := fn.NewFromTypedObject(&corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.Identifier(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-frr", getForName(forFullName)),
+			Annotations: map[string]string{
+				advertisementForAnnotation: forFullName,
+			},
+		},
+		Data: map[string]string{
+			frrConfigKey: sb.String(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fn.KubeObjects{o}, nil
+}
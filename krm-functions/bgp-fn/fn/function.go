@@ -0,0 +1,260 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/krm-functions/lib/condkptsdk"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+)
+
+const (
+	// advertisementForAnnotation identifies the resources generated by the fn with the resource
+	// whose prefixes they advertise
+	advertisementForAnnotation = "nephio.org/bgp-advertisement-for"
+
+	// outputKey is the key in the data of the function config ConfigMap that selects how the
+	// prefixes are advertised, with MetalLB by default
+	outputKey = "output"
+	// metallbOutput advertises the prefixes with MetalLB IPAddressPools and BGPAdvertisements
+	metallbOutput = "metallb"
+	// frrOutput advertises the prefixes with an FRR configuration
+	frrOutput = "frr"
+	// namespaceKey is the key in the data of the function config ConfigMap that defines the
+	// namespace of the MetalLB resources, metallb-system by default
+	namespaceKey = "namespace"
+	// peersKey is the key in the data of the function config ConfigMap that restricts the
+	// MetalLB BGP peers the prefixes are advertised to, comma separated, all the peers by default
+	peersKey = "peers"
+	// asnKey is the key in the data of the function config ConfigMap that defines the
+	// autonomous system number of the FRR configuration
+	asnKey = "asn"
+
+	defaultMetallbNamespace = "metallb-system"
+	defaultRoutingTable     = "default"
+)
+
+type config struct {
+	output    string
+	namespace string
+	peers     []string
+	asn       uint32
+}
+
+// advertisements holds the prefixes to advertise per routing table
+type advertisements map[string][]netip.Prefix
+
+// Run is the entry point of the KRM function (called by the upstream fn SDK)
+// it advertises the resolved pool and loopback prefixes of the package to the fabric
+// in the routing tables of the Network resources of the package
+func Run(rl *fn.ResourceList) (bool, error) {
+	cfg, err := getConfig(rl.FunctionConfig)
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+
+	// the advertisements are regenerated, as such the prefixes of deleted claims are withdrawn
+	items := fn.KubeObjects{}
+	for _, o := range rl.Items {
+		if o.GetAnnotation(advertisementForAnnotation) == "" {
+			items = append(items, o)
+		}
+	}
+	routingTables, err := getRoutingTablePrefixes(items)
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+
+	advertisementsPerNF := map[string]advertisements{}
+	for _, o := range items.Where(fn.IsGroupVersionKind(ipamv1alpha1.IPClaimGroupVersionKind)) {
+		claim, err := ko.KubeObjectToStruct[ipamv1alpha1.IPClaim](o)
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		// the prefixes of the network claims are the subnets connected to the fabric
+		if claim.Spec.Kind != ipamv1alpha1.PrefixKindPool && claim.Spec.Kind != ipamv1alpha1.PrefixKindLoopback {
+			continue
+		}
+		if claim.Status.Prefix == nil {
+			rl.Results.Warningf("IPClaim %s is not resolved, its prefix is not advertised", claim.GetName())
+			continue
+		}
+		pi, err := netip.ParsePrefix(*claim.Status.Prefix)
+		if err != nil {
+			err = fmt.Errorf("invalid prefix %s in the status of IPClaim %s: %s", *claim.Status.Prefix, claim.GetName(), err.Error())
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		pi = pi.Masked()
+		rt := claim.Spec.NetworkInstance.Name
+		rtPrefixes, ok := routingTables[rt]
+		if !ok {
+			rl.Results.Warningf("no routing table %s in the Network resources of the kpt package, the prefix %s of IPClaim %s is not advertised", rt, pi.String(), claim.GetName())
+			continue
+		}
+		if !containsPrefix(rtPrefixes, pi) {
+			rl.Results.Warningf("prefix %s of IPClaim %s is not part of routing table %s, it is not advertised", pi.String(), claim.GetName(), rt)
+			continue
+		}
+		forFullName := getForFullName(o.GetAnnotations())
+		if _, ok := advertisementsPerNF[forFullName]; !ok {
+			advertisementsPerNF[forFullName] = advertisements{}
+		}
+		advertisementsPerNF[forFullName][rt] = append(advertisementsPerNF[forFullName][rt], pi)
+	}
+
+	forFullNames := make([]string, 0, len(advertisementsPerNF))
+	for forFullName := range advertisementsPerNF {
+		forFullNames = append(forFullNames, forFullName)
+	}
+	sort.Strings(forFullNames)
+	for _, forFullName := range forFullNames {
+		adv := advertisementsPerNF[forFullName]
+		adv.sort()
+		var objs fn.KubeObjects
+		switch cfg.output {
+		case frrOutput:
+			objs, err = adv.getFRRConfig(forFullName, cfg)
+		default:
+			objs, err = adv.getMetallbResources(forFullName, cfg)
+		}
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		rl.Results.Infof("prefixes of %s advertised in routing tables %v", forFullName, adv.routingTables())
+		items = append(items, objs...)
+	}
+	rl.Items = items
+	return true, nil
+}
+
+func getConfig(fc *fn.KubeObject) (*config, error) {
+	cfg := &config{output: metallbOutput, namespace: defaultMetallbNamespace}
+	if fc == nil {
+		return cfg, nil
+	}
+	if s, ok, _ := fc.NestedString("data", outputKey); ok {
+		cfg.output = s
+	}
+	if cfg.output != metallbOutput && cfg.output != frrOutput {
+		return nil, fmt.Errorf("output %s not supported, supported outputs: [%s %s]", cfg.output, metallbOutput, frrOutput)
+	}
+	if s, ok, _ := fc.NestedString("data", namespaceKey); ok && s != "" {
+		cfg.namespace = s
+	}
+	if s, ok, _ := fc.NestedString("data", peersKey); ok {
+		for _, p := range strings.Split(s, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				cfg.peers = append(cfg.peers, p)
+			}
+		}
+	}
+	if s, ok, _ := fc.NestedString("data", asnKey); ok && s != "" {
+		asn, err := strconv.ParseUint(s, 10, 32)
+		if err != nil || asn == 0 {
+			return nil, fmt.Errorf("invalid %s %s", asnKey, s)
+		}
+		cfg.asn = uint32(asn)
+	}
+	if cfg.output == frrOutput && cfg.asn == 0 {
+		return nil, fmt.Errorf("the %s output requires an %s", frrOutput, asnKey)
+	}
+	return cfg, nil
+}
+
+// getRoutingTablePrefixes returns the prefixes of the routing tables of the Network resources per routing table
+func getRoutingTablePrefixes(objs fn.KubeObjects) (map[string][]netip.Prefix, error) {
+	prefixes := map[string][]netip.Prefix{}
+	for _, o := range objs.Where(ko.IsGroupVersionKind(infrav1alpha1.NetworkGroupVersionKind)) {
+		network, err := ko.KubeObjectToStruct[infrav1alpha1.Network](o)
+		if err != nil {
+			return nil, err
+		}
+		for _, rt := range network.Spec.RoutingTables {
+			for _, p := range rt.Prefixes {
+				pi, err := netip.ParsePrefix(p.Prefix)
+				if err != nil {
+					return nil, fmt.Errorf("invalid prefix %s in routing table %s of Network %s: %s", p.Prefix, rt.Name, o.GetName(), err.Error())
+				}
+				prefixes[rt.Name] = append(prefixes[rt.Name], pi.Masked())
+			}
+		}
+	}
+	return prefixes, nil
+}
+
+// containsPrefix returns true when one of the prefixes holds the prefix
+func containsPrefix(prefixes []netip.Prefix, pi netip.Prefix) bool {
+	for _, p := range prefixes {
+		if p.Bits() <= pi.Bits() && p.Contains(pi.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// sort sorts the prefixes of the routing tables and removes the duplicates
+func (r advertisements) sort() {
+	for rt, prefixes := range r {
+		sort.Slice(prefixes, func(i, j int) bool {
+			return prefixes[i].String() < prefixes[j].String()
+		})
+		unique := []netip.Prefix{}
+		for i, p := range prefixes {
+			if i == 0 || p != prefixes[i-1] {
+				unique = append(unique, p)
+			}
+		}
+		r[rt] = unique
+	}
+}
+
+// routingTables returns the sorted routing tables of the advertisements
+func (r advertisements) routingTables() []string {
+	rts := make([]string, 0, len(r))
+	for rt := range r {
+		rts = append(rts, rt)
+	}
+	sort.Strings(rts)
+	return rts
+}
+
+func getForFullName(annotations map[string]string) string {
+	forFullName := annotations[condkptsdk.SpecializerOwner]
+	if owner, ok := annotations[condkptsdk.SpecializerFor]; ok {
+		forFullName = owner
+	}
+	return forFullName
+}
+
+// getForName returns the name of the resource whose prefixes are advertised
+func getForName(forFullName string) string {
+	split := strings.Split(forFullName, ".")
+	return split[len(split)-1]
+}
@@ -0,0 +1,108 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	metallbAPIVersion    = "metallb.io/v1beta1"
+	ipAddressPoolKind    = "IPAddressPool"
+	bgpAdvertisementKind = "BGPAdvertisement"
+)
+
+// ipAddressPool is the MetalLB IPAddressPool resource
+type ipAddressPool struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Spec              ipAddressPoolSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+type ipAddressPoolSpec struct {
+	// Addresses defines the prefixes of the pool
+	Addresses []string `json:"addresses" yaml:"addresses"`
+	// AutoAssign defines if MetalLB assigns the addresses of the pool to services
+	AutoAssign *bool `json:"autoAssign,omitempty" yaml:"autoAssign,omitempty"`
+}
+
+// bgpAdvertisement is the MetalLB BGPAdvertisement resource
+type bgpAdvertisement struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Spec              bgpAdvertisementSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+type bgpAdvertisementSpec struct {
+	// IPAddressPools defines the pools whose prefixes are advertised
+	IPAddressPools []string `json:"ipAddressPools,omitempty" yaml:"ipAddressPools,omitempty"`
+	// Peers restricts the BGP peers the prefixes are advertised to
+	Peers []string `json:"peers,omitempty" yaml:"peers,omitempty"`
+}
+
+// getMetallbResources returns an IPAddressPool and a BGPAdvertisement per routing table, the
+// addresses of the pools are not assigned to services since they belong to the NF
+func (r advertisements) getMetallbResources(forFullName string, cfg *config) (fn.KubeObjects, error) {
+	objs := fn.KubeObjects{}
+	for _, rt := range r.routingTables() {
+		meta := metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", getForName(forFullName), rt),
+			Namespace: cfg.namespace,
+			Annotations: map[string]string{
+				advertisementForAnnotation: forFullName,
+			},
+		}
+		addresses := []string{}
+		for _, p := range r[rt] {
+			addresses = append(addresses, p.String())
+		}
+		autoAssign := false
+		pool, err := fn.NewFromTypedObject(&ipAddressPool{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: metallbAPIVersion,
+				Kind:       ipAddressPoolKind,
+			},
+			ObjectMeta: meta,
+			Spec: ipAddressPoolSpec{
+				Addresses:  addresses,
+				AutoAssign: &autoAssign,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		adv, err := fn.NewFromTypedObject(&bgpAdvertisement{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: metallbAPIVersion,
+				Kind:       bgpAdvertisementKind,
+			},
+			ObjectMeta: meta,
+			Spec: bgpAdvertisementSpec{
+				IPAddressPools: []string{meta.Name},
+				Peers:          cfg.peers,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, pool, adv)
+	}
+	return objs, nil
+}
@@ -0,0 +1,135 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"strings"
+
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CapacityDimensioning is the function config of the capacity fn, it defines the dimensioning
+// table translating the Capacity requirements of the package into the resources of the workloads
+type CapacityDimensioning struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	// Selector selects the Deployments and StatefulSets of the package by their labels, all of them by default
+	Selector *metav1.LabelSelector `json:"selector,omitempty" yaml:"selector,omitempty"`
+	// Containers restricts the containers whose requests are patched, all containers by default
+	Containers []string `json:"containers,omitempty" yaml:"containers,omitempty"`
+	// Tiers defines the dimensioning table, the first tier whose bounds hold the Capacity is selected
+	Tiers []DimensioningTier `json:"tiers" yaml:"tiers"`
+}
+
+// DimensioningTier defines the resources of the workloads up to the bounds of the tier,
+// a bound which is not set does not limit the tier
+type DimensioningTier struct {
+	// Name defines the name of the tier
+	Name string `json:"name" yaml:"name"`
+	// MaxUplinkThroughput defines the bound of the max uplink throughput of the tier
+	MaxUplinkThroughput *resource.Quantity `json:"maxUplinkThroughput,omitempty" yaml:"maxUplinkThroughput,omitempty"`
+	// MaxDownlinkThroughput defines the bound of the max downlink throughput of the tier
+	MaxDownlinkThroughput *resource.Quantity `json:"maxDownlinkThroughput,omitempty" yaml:"maxDownlinkThroughput,omitempty"`
+	// MaxSessions defines the bound of the max sessions of the tier, in units of 1000
+	MaxSessions int `json:"maxSessions,omitempty" yaml:"maxSessions,omitempty"`
+	// MaxSubscribers defines the bound of the max subscribers of the tier, in units of 1000
+	MaxSubscribers int `json:"maxSubscribers,omitempty" yaml:"maxSubscribers,omitempty"`
+	// Replicas defines the replicas of the workloads, the replicas are not patched when not set
+	Replicas *int32 `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+	// Requests defines the resource requests of the containers, e.g. cpu, memory and hugepages-1Gi
+	Requests corev1.ResourceList `json:"requests,omitempty" yaml:"requests,omitempty"`
+}
+
+// Validate checks the dimensioning table
+func (r *CapacityDimensioning) Validate() error {
+	if len(r.Tiers) == 0 {
+		return fmt.Errorf("dimensioning %s has no tiers", r.GetName())
+	}
+	names := map[string]struct{}{}
+	for i, tier := range r.Tiers {
+		if tier.Name == "" {
+			return fmt.Errorf("tier %d of dimensioning %s has no name", i, r.GetName())
+		}
+		if _, ok := names[tier.Name]; ok {
+			return fmt.Errorf("tier %s of dimensioning %s is defined multiple times", tier.Name, r.GetName())
+		}
+		names[tier.Name] = struct{}{}
+		if tier.Replicas != nil && *tier.Replicas < 0 {
+			return fmt.Errorf("tier %s of dimensioning %s has negative replicas", tier.Name, r.GetName())
+		}
+	}
+	return nil
+}
+
+// getTier returns the first tier whose bounds hold the capacity
+func (r *CapacityDimensioning) getTier(c *nephioreqv1alpha1.CapacitySpec) (*DimensioningTier, error) {
+	for i := range r.Tiers {
+		if r.Tiers[i].holds(c) {
+			return &r.Tiers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no tier of dimensioning %s holds the capacity %s", r.GetName(), capacityString(c))
+}
+
+func (r *DimensioningTier) holds(c *nephioreqv1alpha1.CapacitySpec) bool {
+	if r.MaxUplinkThroughput != nil && c.MaxUplinkThroughput.Cmp(*r.MaxUplinkThroughput) > 0 {
+		return false
+	}
+	if r.MaxDownlinkThroughput != nil && c.MaxDownlinkThroughput.Cmp(*r.MaxDownlinkThroughput) > 0 {
+		return false
+	}
+	if r.MaxSessions != 0 && c.MaxSessions > r.MaxSessions {
+		return false
+	}
+	if r.MaxSubscribers != 0 && c.MaxSubscribers > r.MaxSubscribers {
+		return false
+	}
+	return true
+}
+
+// mergeCapacity returns the largest value of each attribute of the capacities
+func mergeCapacity(capacities []nephioreqv1alpha1.Capacity) *nephioreqv1alpha1.CapacitySpec {
+	c := &nephioreqv1alpha1.CapacitySpec{}
+	for _, x := range capacities {
+		if x.Spec.MaxUplinkThroughput.Cmp(c.MaxUplinkThroughput) > 0 {
+			c.MaxUplinkThroughput = x.Spec.MaxUplinkThroughput
+		}
+		if x.Spec.MaxDownlinkThroughput.Cmp(c.MaxDownlinkThroughput) > 0 {
+			c.MaxDownlinkThroughput = x.Spec.MaxDownlinkThroughput
+		}
+		if x.Spec.MaxSessions > c.MaxSessions {
+			c.MaxSessions = x.Spec.MaxSessions
+		}
+		if x.Spec.MaxSubscribers > c.MaxSubscribers {
+			c.MaxSubscribers = x.Spec.MaxSubscribers
+		}
+	}
+	return c
+}
+
+func capacityString(c *nephioreqv1alpha1.CapacitySpec) string {
+	return strings.Join([]string{
+		fmt.Sprintf("maxUplinkThroughput=%s", c.MaxUplinkThroughput.String()),
+		fmt.Sprintf("maxDownlinkThroughput=%s", c.MaxDownlinkThroughput.String()),
+		fmt.Sprintf("maxSessions=%d", c.MaxSessions),
+		fmt.Sprintf("maxSubscribers=%d", c.MaxSubscribers),
+	}, " ")
+}
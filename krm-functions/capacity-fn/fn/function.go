@@ -0,0 +1,137 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	wclib "github.com/nephio-project/nephio/krm-functions/lib/workloadcluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Run is the entry point of the KRM function (called by the upstream fn SDK)
+// it patches the workloads of the package with the resources of the tier of the
+// dimensioning table holding the Capacity requirements of the package
+func Run(rl *fn.ResourceList) (bool, error) {
+	capacities, err := ko.KubeObjectsToStructs[nephioreqv1alpha1.Capacity](rl.Items.Where(fn.IsGroupVersionKind(nephioreqv1alpha1.CapacityGroupVersionKind)))
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	if len(capacities) == 0 {
+		rl.Results.Infof("no Capacity found in the kpt package, the workloads are not dimensioned")
+		return true, nil
+	}
+	if rl.FunctionConfig == nil {
+		err = fmt.Errorf("the capacity fn requires a CapacityDimensioning function config")
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	dimensioning := &CapacityDimensioning{}
+	if err := rl.FunctionConfig.As(dimensioning); err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	if err := dimensioning.Validate(); err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	selector := labels.Everything()
+	if dimensioning.Selector != nil {
+		selector, err = metav1.LabelSelectorAsSelector(dimensioning.Selector)
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+	}
+
+	capacity := mergeCapacity(capacities)
+	tier, err := dimensioning.getTier(capacity)
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+
+	for _, o := range rl.Items {
+		if !wclib.IsWorkload(o) || !selector.Matches(labels.Set(o.GetLabels())) {
+			continue
+		}
+		if err := patchWorkload(o, tier, dimensioning.Containers); err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		rl.Results.Infof("%s %s dimensioned with tier %s for capacity %s", o.GetKind(), o.GetName(), tier.Name, capacityString(capacity))
+	}
+	return true, nil
+}
+
+// patchWorkload sets the replicas of the tier on the workload and the requests of the tier on its
+// containers, hugepages are also set as limits since kubernetes requires them to be equal
+func patchWorkload(o *fn.KubeObject, tier *DimensioningTier, containers []string) error {
+	if tier.Replicas != nil {
+		if err := o.SetNestedInt(int(*tier.Replicas), "spec", "replicas"); err != nil {
+			return err
+		}
+	}
+	if len(tier.Requests) == 0 {
+		return nil
+	}
+	// the resources are patched in name order to keep the output stable
+	names := make([]string, 0, len(tier.Requests))
+	for name := range tier.Requests {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	cs, _, err := o.NestedSlice("spec", "template", "spec", "containers")
+	if err != nil {
+		return err
+	}
+	for _, c := range cs {
+		if len(containers) > 0 && !contains(containers, c.GetString("name")) {
+			continue
+		}
+		for _, name := range names {
+			q := tier.Requests[corev1.ResourceName(name)]
+			if err := c.SetNestedString(q.String(), "resources", "requests", name); err != nil {
+				return err
+			}
+			if strings.HasPrefix(name, corev1.ResourceHugePagesPrefix) {
+				if err := c.SetNestedString(q.String(), "resources", "limits", name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func contains(s []string, x string) bool {
+	for _, v := range s {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
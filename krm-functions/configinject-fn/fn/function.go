@@ -56,10 +56,10 @@ func New(c client.Client) *FnR {
 		Client: c,
 	}
 	f.sdkConfig = &condkptsdk.Config{
-		For: corev1.ObjectReference{
+		For: []corev1.ObjectReference{{
 			APIVersion: nephioreqv1alpha1.GroupVersion.Identifier(),
 			Kind:       nephioreqv1alpha1.DependencyKind,
-		},
+		}},
 		Owns: map[corev1.ObjectReference]condkptsdk.ResourceKind{
 			{
 				APIVersion: nephiorefv1alpha1.GroupVersion.Identifier(),
@@ -91,7 +91,16 @@ func (f *FnR) Run(rl *fn.ResourceList) (bool, error) {
 		rl.Results.ErrorE(err)
 		return false, err
 	}
-	return sdk.Run()
+	ok, err := sdk.Run()
+	if !ok || err != nil {
+		return ok, err
+	}
+	// the ue pools of the NF config files follow the pool claims of the package
+	if err := injectUEPools(rl); err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	return true, nil
 }
 
 // WorkloadClusterCallbackFn provides a callback for the workload cluster
@@ -102,7 +111,7 @@ func (f *FnR) WorkloadClusterCallbackFn(o *fn.KubeObject) error {
 	if f.workloadCluster != nil {
 		return fmt.Errorf("multiple WorkloadCluster objects found in the kpt package")
 	}
-	f.workloadCluster, err = ko.KubeObjectToStruct[infrav1alpha1.WorkloadCluster](o)
+	f.workloadCluster, err = ko.CachedKubeObjectToStruct[infrav1alpha1.WorkloadCluster](o)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,319 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// NFConfigTemplateKind is the kind of the vendor templates of the NF config files
+const NFConfigTemplateKind = "NFConfigTemplate"
+
+// NFConfigTemplateGroupVersionKind is the GroupVersionKind of the vendor templates of the NF config files
+var NFConfigTemplateGroupVersionKind = schema.GroupVersionKind{Group: "fn.kpt.dev", Version: "v1alpha1", Kind: NFConfigTemplateKind}
+
+// builtinTemplateFS bundles the templates of the supported NF vendors, a template of the function
+// config or of the package with the same name replaces the bundled template
+//
+//go:embed templates/*.yaml
+var builtinTemplateFS embed.FS
+
+// NFConfigTemplate defines how the ue pools of the DataNetworks are injected in a config file of
+// an NF vendor, such that a new NF vendor is supported without recompiling the function
+type NFConfigTemplate struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Spec              NFConfigTemplateSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+type NFConfigTemplateSpec struct {
+	// Vendor defines the vendor of the NF, e.g. free5gc, oai, sdcore
+	Vendor string `json:"vendor" yaml:"vendor"`
+	// ConfigMapKey defines the data key of the ConfigMaps holding the config file, e.g. smfcfg.yaml;
+	// a config file with the .json extension is written back as json
+	ConfigMapKey string `json:"configMapKey" yaml:"configMapKey"`
+	// Injections defines the fields of the config file set per DataNetwork
+	Injections []Injection `json:"injections" yaml:"injections"`
+}
+
+type Injection struct {
+	// Path defines the dot separated path of the field, a go template of the ue pool data; a path
+	// part is either a field of a map, '*' matching the values of a map or the elements of a list,
+	// or [key=value] matching the elements of a list with the value; the field is set in the
+	// matching maps, a config file without a matching map is left untouched
+	Path string `json:"path" yaml:"path"`
+	// Value defines the yaml value of the field, a go template of the ue pool data
+	Value string `json:"value" yaml:"value"`
+}
+
+// uePoolData is the data of the templates, the resolved pool prefixes of a DataNetwork
+type uePoolData struct {
+	DNN      string
+	Prefixes []string
+}
+
+// getTemplates returns the bundled templates, replaced by the templates of the package and of
+// the function config with the same name, in name order
+func getTemplates(rl *fn.ResourceList) ([]*NFConfigTemplate, error) {
+	templates := map[string]*NFConfigTemplate{}
+	entries, err := builtinTemplateFS.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		b, err := builtinTemplateFS.ReadFile(path.Join("templates", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		o, err := fn.ParseKubeObject(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundled template %s: %s", e.Name(), err.Error())
+		}
+		if err := addTemplate(templates, o); err != nil {
+			return nil, err
+		}
+	}
+	for _, o := range rl.Items.Where(fn.IsGroupVersionKind(NFConfigTemplateGroupVersionKind)) {
+		if err := addTemplate(templates, o); err != nil {
+			return nil, err
+		}
+	}
+	if rl.FunctionConfig != nil && rl.FunctionConfig.IsGroupVersionKind(NFConfigTemplateGroupVersionKind) {
+		if err := addTemplate(templates, rl.FunctionConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]*NFConfigTemplate, 0, len(templates))
+	for _, name := range names {
+		result = append(result, templates[name])
+	}
+	return result, nil
+}
+
+func addTemplate(templates map[string]*NFConfigTemplate, o *fn.KubeObject) error {
+	t, err := ko.KubeObjectToStruct[NFConfigTemplate](o)
+	if err != nil {
+		return err
+	}
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	templates[t.GetName()] = t
+	return nil
+}
+
+// Validate checks the template
+func (r *NFConfigTemplate) Validate() error {
+	if r.Spec.Vendor == "" || r.Spec.ConfigMapKey == "" {
+		return fmt.Errorf("NFConfigTemplate %s requires a vendor and a configMapKey", r.GetName())
+	}
+	for i, inj := range r.Spec.Injections {
+		if inj.Path == "" {
+			return fmt.Errorf("injection %d of NFConfigTemplate %s has no path", i, r.GetName())
+		}
+		for _, s := range []string{inj.Path, inj.Value} {
+			if _, err := template.New(r.GetName()).Option("missingkey=error").Parse(s); err != nil {
+				return fmt.Errorf("invalid injection %d of NFConfigTemplate %s: %s", i, r.GetName(), err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// Inject sets the fields of the injections of the template in the config file per DataNetwork;
+// it returns the config file and the DataNetworks injected
+func (r *NFConfigTemplate) Inject(data string, pools map[string][]string) (string, []string, error) {
+	node, err := yaml.Parse(data)
+	if err != nil {
+		return "", nil, err
+	}
+	dnns := make([]string, 0, len(pools))
+	for dnn := range pools {
+		dnns = append(dnns, dnn)
+	}
+	sort.Strings(dnns)
+
+	injected := []string{}
+	for _, dnn := range dnns {
+		d := uePoolData{DNN: dnn, Prefixes: pools[dnn]}
+		found := false
+		for _, inj := range r.Spec.Injections {
+			ok, err := inj.inject(node, d)
+			if err != nil {
+				return "", nil, err
+			}
+			found = found || ok
+		}
+		if found {
+			injected = append(injected, dnn)
+		}
+	}
+	if len(injected) == 0 {
+		return data, nil, nil
+	}
+	if strings.HasSuffix(r.Spec.ConfigMapKey, ".json") {
+		b, err := node.MarshalJSON()
+		if err != nil {
+			return "", nil, err
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, b, "", "  "); err != nil {
+			return "", nil, err
+		}
+		return out.String() + "\n", injected, nil
+	}
+	s, err := node.String()
+	return s, injected, err
+}
+
+// inject sets the field of the injection in the maps matching its path, it returns false when no map matches
+func (r *Injection) inject(node *yaml.RNode, d uePoolData) (bool, error) {
+	p, err := render(r.Path, d)
+	if err != nil {
+		return false, err
+	}
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return false, fmt.Errorf("empty path rendered from %s", r.Path)
+	}
+	parts := splitPath(p)
+	parents, err := lookup([]*yaml.RNode{node}, parts[:len(parts)-1])
+	if err != nil || len(parents) == 0 {
+		return false, err
+	}
+	v, err := render(r.Value, d)
+	if err != nil {
+		return false, err
+	}
+	value, err := yaml.Parse(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid value rendered for path %s: %s", p, err.Error())
+	}
+	injected := false
+	for _, parent := range parents {
+		if parent.YNode().Kind != yaml.MappingNode {
+			continue
+		}
+		if err := parent.PipeE(yaml.SetField(parts[len(parts)-1], value.Copy())); err != nil {
+			return false, err
+		}
+		injected = true
+	}
+	return injected, nil
+}
+
+func render(s string, d uePoolData) (string, error) {
+	t, err := template.New("").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := t.Execute(&out, d); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// splitPath splits the path on the dots outside of the [key=value] parts
+func splitPath(p string) []string {
+	parts := []string{}
+	depth := 0
+	start := 0
+	for i, c := range p {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				parts = append(parts, p[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, p[start:])
+}
+
+// lookup returns the maps matching the path parts
+func lookup(nodes []*yaml.RNode, parts []string) ([]*yaml.RNode, error) {
+	for _, part := range parts {
+		next := []*yaml.RNode{}
+		for _, n := range nodes {
+			switch {
+			case part == "*":
+				switch n.YNode().Kind {
+				case yaml.MappingNode:
+					if err := n.VisitFields(func(f *yaml.MapNode) error {
+						next = append(next, f.Value)
+						return nil
+					}); err != nil {
+						return nil, err
+					}
+				case yaml.SequenceNode:
+					elems, err := n.Elements()
+					if err != nil {
+						return nil, err
+					}
+					next = append(next, elems...)
+				}
+			case strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]"):
+				key, value, ok := strings.Cut(part[1:len(part)-1], "=")
+				if !ok || n.YNode().Kind != yaml.SequenceNode {
+					continue
+				}
+				elems, err := n.Elements()
+				if err != nil {
+					return nil, err
+				}
+				for _, e := range elems {
+					if f := e.Field(key); f != nil && yaml.GetValue(f.Value) == value {
+						next = append(next, e)
+					}
+				}
+			default:
+				if n.YNode().Kind != yaml.MappingNode {
+					continue
+				}
+				if f := n.Field(part); f != nil {
+					next = append(next, f.Value)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes, nil
+}
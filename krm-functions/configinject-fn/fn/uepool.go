@@ -0,0 +1,105 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nephio-project/nephio/krm-functions/lib/condkptsdk"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// dnnOwnerPrefix prefixes the owner annotation of the pool claims of a DataNetwork with the name of the DataNetwork
+var dnnOwnerPrefix = fmt.Sprintf("%s.%s.", nephioreqv1alpha1.GroupVersion.Identifier(), nephioreqv1alpha1.DataNetworkKind)
+
+// injectUEPools writes the prefixes of the resolved pool claims of the DataNetworks of the package
+// in the NF config files of the package with the templates of the NF vendors, such that the NFs
+// allocate the ue addresses from the pools allocated by the ipam
+func injectUEPools(rl *fn.ResourceList) error {
+	pools, err := getUEPools(rl.Items)
+	if err != nil {
+		return err
+	}
+	if len(pools) == 0 {
+		return nil
+	}
+	templates, err := getTemplates(rl)
+	if err != nil {
+		return err
+	}
+	for _, o := range rl.Items.Where(fn.IsGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))) {
+		for _, t := range templates {
+			data, ok, err := o.NestedString("data", t.Spec.ConfigMapKey)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			s, dnns, err := t.Inject(data, pools)
+			if err != nil {
+				return fmt.Errorf("cannot inject the ue pools in %s of ConfigMap %s with NFConfigTemplate %s: %s", t.Spec.ConfigMapKey, o.GetName(), t.GetName(), err.Error())
+			}
+			if len(dnns) == 0 {
+				continue
+			}
+			if err := o.SetNestedString(s, "data", t.Spec.ConfigMapKey); err != nil {
+				return err
+			}
+			rl.Results.Infof("ue pools of dnns %v injected in %s of ConfigMap %s with %s template %s", dnns, t.Spec.ConfigMapKey, o.GetName(), t.Spec.Vendor, t.GetName())
+		}
+	}
+	return nil
+}
+
+// getUEPools returns the prefixes of the resolved pool claims per DataNetwork, a DataNetwork
+// with an unresolved claim is left out such that its pools are injected once all are resolved
+func getUEPools(objs fn.KubeObjects) (map[string][]string, error) {
+	pools := map[string][]string{}
+	unresolved := map[string]struct{}{}
+	for _, o := range objs.Where(fn.IsGroupVersionKind(ipamv1alpha1.IPClaimGroupVersionKind)) {
+		dnn, ok := strings.CutPrefix(o.GetAnnotation(condkptsdk.SpecializerOwner), dnnOwnerPrefix)
+		if !ok {
+			continue
+		}
+		claim, err := ko.KubeObjectToStruct[ipamv1alpha1.IPClaim](o)
+		if err != nil {
+			return nil, err
+		}
+		if claim.Spec.Kind != ipamv1alpha1.PrefixKindPool {
+			continue
+		}
+		if claim.Status.Prefix == nil {
+			unresolved[dnn] = struct{}{}
+			continue
+		}
+		pools[dnn] = append(pools[dnn], *claim.Status.Prefix)
+	}
+	for dnn := range unresolved {
+		delete(pools, dnn)
+	}
+	for _, prefixes := range pools {
+		sort.Strings(prefixes)
+	}
+	return pools, nil
+}
@@ -10,9 +10,11 @@ require (
 	github.com/GoogleContainerTools/kpt/porch/api v0.0.0-20230608012444-ee7c8cf378e9
 	github.com/nephio-project/api v0.0.0-20230627152656-a2bf013a68da
 	github.com/nephio-project/nephio/krm-functions/lib v0.0.0-00010101000000-000000000000
+	github.com/nokia/k8s-ipam v0.0.4-0.20230614172255-e361e59e279c
 	k8s.io/api v0.27.3
 	k8s.io/apimachinery v0.27.3
 	sigs.k8s.io/controller-runtime v0.15.0
+	sigs.k8s.io/kustomize/kyaml v0.14.2
 )
 
 require (
@@ -41,7 +43,6 @@ require (
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/monochromegane/go-gitignore v0.0.0-20200626010858-205db1a8cc00 // indirect
 	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
-	github.com/nokia/k8s-ipam v0.0.4-0.20230614172255-e361e59e279c // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/prometheus/common v0.44.0 // indirect
 	github.com/prometheus/procfs v0.10.1 // indirect
@@ -67,7 +68,6 @@ require (
 	k8s.io/utils v0.0.0-20230505201702-9f6742963106 // indirect
 	sigs.k8s.io/json v0.0.0-20221116044647-bc3834ca7abd // indirect
 	sigs.k8s.io/kustomize/api v0.13.3 // indirect
-	sigs.k8s.io/kustomize/kyaml v0.14.2 // indirect
 	sigs.k8s.io/structured-merge-diff/v4 v4.2.3 // indirect
 	sigs.k8s.io/yaml v1.3.0 // indirect
 )
@@ -0,0 +1,83 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"math/bits"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+)
+
+const (
+	// ipv6UEPrefixLength is the prefix length delegated to an UE from an ipv6 pool
+	ipv6UEPrefixLength = 64
+	// capacityUnit is the unit of the max sessions and max subscribers of a Capacity
+	capacityUnit = 1000
+)
+
+// capacity captures the number of UEs the pools of the DataNetworks are sized for
+type capacity struct {
+	// name of the Capacity resource the number of UEs is derived from
+	name string
+	// source is the attribute of the Capacity resource the number of UEs is derived from
+	source string
+	ues    int
+}
+
+// CapacityCallbackFn provides a callback for the capacity resources in the resourceList
+// the largest number of subscribers, or sessions when no subscribers are defined, sizes the pools
+func (f *dnnFn) CapacityCallbackFn(o *fn.KubeObject) error {
+	c, err := ko.KubeObjectToStruct[nephioreqv1alpha1.Capacity](o)
+	if err != nil {
+		return err
+	}
+	ues, source := c.Spec.MaxSubscribers*capacityUnit, "maxSubscribers"
+	if ues == 0 {
+		ues, source = c.Spec.MaxSessions*capacityUnit, "maxSessions"
+	}
+	if ues > 0 && (f.capacity == nil || ues > f.capacity.ues) {
+		f.capacity = &capacity{name: c.GetName(), source: source, ues: ues}
+	}
+	return nil
+}
+
+// getPoolPrefixLength returns the prefix length of the pool, a pool without prefix length
+// is sized from the capacity to hold an address (ipv4) or a /64 (ipv6) per UE
+func (f *dnnFn) getPoolPrefixLength(dnn *nephioreqv1alpha1.DataNetwork, pool *nephioreqv1alpha1.Pool) (uint8, error) {
+	if pool.PrefixLength != 0 {
+		return pool.PrefixLength, nil
+	}
+	if f.capacity == nil {
+		return 0, results.Errorf(results.CodeMissingResource, "pool %s of DataNetwork %s has no prefixLength and no Capacity with maxSubscribers or maxSessions is found in the kpt package", pool.Name, dnn.GetName()).
+			WithField("spec.pools").WithRemediation("set the prefixLength of the pool or add a Capacity to the package")
+	}
+	hostLength := 32
+	if pool.IPFamily == nephioreqv1alpha1.IPFamilyIPv6 {
+		hostLength = ipv6UEPrefixLength
+	}
+	// the number of bits needed to address all UEs
+	ueBits := bits.Len(uint(f.capacity.ues - 1))
+	if ueBits >= hostLength {
+		return 0, results.Errorf(results.CodeInvalidInput, "pool %s of DataNetwork %s cannot hold %d UEs", pool.Name, dnn.GetName(), f.capacity.ues).WithField("spec.pools")
+	}
+	prefixLength := uint8(hostLength - ueBits)
+	f.rl.Results.Infof("pool %s of DataNetwork %s sized to prefixLength %d for %d UEs from %s of Capacity %s", pool.Name, dnn.GetName(), prefixLength, f.capacity.ues, f.capacity.source, f.capacity.name)
+	return prefixLength, nil
+}
@@ -27,6 +27,7 @@ import (
 	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
 	"github.com/nephio-project/nephio/krm-functions/lib/condkptsdk"
 	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
 	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
 	"github.com/nokia/k8s-ipam/pkg/iputil"
@@ -45,6 +46,7 @@ func init() {
 type dnnFn struct {
 	sdk             condkptsdk.KptCondSDK
 	workloadCluster *infrav1alpha1.WorkloadCluster
+	capacity        *capacity
 	rl              *fn.ResourceList
 }
 
@@ -56,10 +58,10 @@ func Run(rl *fn.ResourceList) (bool, error) {
 	myFn.sdk, err = condkptsdk.New(
 		rl,
 		&condkptsdk.Config{
-			For: corev1.ObjectReference{
+			For: []corev1.ObjectReference{{
 				APIVersion: nephioreqv1alpha1.GroupVersion.Identifier(),
 				Kind:       nephioreqv1alpha1.DataNetworkKind,
-			},
+			}},
 			Owns: map[corev1.ObjectReference]condkptsdk.ResourceKind{
 				{
 					APIVersion: ipamv1alpha1.GroupVersion.Identifier(),
@@ -71,13 +73,17 @@ func Run(rl *fn.ResourceList) (bool, error) {
 					APIVersion: infrav1alpha1.GroupVersion.Identifier(),
 					Kind:       reflect.TypeOf(infrav1alpha1.WorkloadCluster{}).Name(),
 				}: myFn.WorkloadClusterCallbackFn,
+				{
+					APIVersion: nephioreqv1alpha1.GroupVersion.Identifier(),
+					Kind:       nephioreqv1alpha1.CapacityKind,
+				}: myFn.CapacityCallbackFn,
 			},
 			PopulateOwnResourcesFn: myFn.desiredOwnedResourceList,
 			UpdateResourceFn:       myFn.updateDnnResource,
 		},
 	)
 	if err != nil {
-		rl.Results.ErrorE(err)
+		results.ErrorE(rl, err)
 		return false, err
 	}
 	return myFn.sdk.Run()
@@ -89,9 +95,9 @@ func (f *dnnFn) WorkloadClusterCallbackFn(o *fn.KubeObject) error {
 	var err error
 
 	if f.workloadCluster != nil {
-		return fmt.Errorf("multiple WorkloadCluster objects found in the kpt package")
+		return results.Errorf(results.CodeDuplicateResource, "multiple WorkloadCluster objects found in the kpt package").WithResource(o)
 	}
-	f.workloadCluster, err = ko.KubeObjectToStruct[infrav1alpha1.WorkloadCluster](o)
+	f.workloadCluster, err = ko.CachedKubeObjectToStruct[infrav1alpha1.WorkloadCluster](o)
 	if err != nil {
 		return err
 	}
@@ -104,7 +110,7 @@ func (f *dnnFn) WorkloadClusterCallbackFn(o *fn.KubeObject) error {
 func (f *dnnFn) desiredOwnedResourceList(o *fn.KubeObject) (fn.KubeObjects, error) {
 	if f.workloadCluster == nil {
 		// no WorkloadCluster resource in the package
-		return nil, fmt.Errorf("workload cluster is missing from the kpt package")
+		return nil, results.Errorf(results.CodeMissingResource, "workload cluster is missing from the kpt package")
 	}
 
 	// get "parent"| DNN struct
@@ -113,7 +119,12 @@ func (f *dnnFn) desiredOwnedResourceList(o *fn.KubeObject) (fn.KubeObjects, erro
 		return nil, err
 	}
 
-	// add IpClaim for each pool
+	qos, err := getPoolQoS(o)
+	if err != nil {
+		return nil, err
+	}
+
+	// add IpClaim for each pool, the labels carry the QoS metadata of the pool
 	resources := fn.KubeObjects{}
 	for _, pool := range dnn.Spec.Pools {
 
@@ -121,16 +132,21 @@ func (f *dnnFn) desiredOwnedResourceList(o *fn.KubeObject) (fn.KubeObjects, erro
 		if pool.IPFamily == nephioreqv1alpha1.IPFamilyIPv6 {
 			af = iputil.AddressFamilyIpv6
 		}
+		prefixLength, err := f.getPoolPrefixLength(dnn, pool)
+		if err != nil {
+			return nil, err
+		}
 
 		ipClaim := ipamv1alpha1.BuildIPClaim(
 			metav1.ObjectMeta{
 				Name:        fmt.Sprintf("%s-%s-%s", getForName(o.GetAnnotations()), dnn.Name, pool.Name),
 				Annotations: getAnnotations(dnn.GetAnnotations()),
+				Labels:      qos[pool.Name].getLabels(),
 			},
 			ipamv1alpha1.IPClaimSpec{
 				Kind:            ipamv1alpha1.PrefixKindPool,
 				NetworkInstance: dnn.Spec.NetworkInstance,
-				PrefixLength:    &pool.PrefixLength,
+				PrefixLength:    &prefixLength,
 				CreatePrefix:    pointer.Bool(true),
 				ClaimLabels: resourcev1alpha1.ClaimLabels{
 					Selector: &metav1.LabelSelector{
@@ -0,0 +1,132 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+)
+
+const (
+	// the labels of a pool IPClaim carrying the QoS metadata of the pool, used by the UPF config
+	// functions to map the pool to a DSCP marking rule
+	fiveQILabel   = "nephio.org/5qi"
+	dscpLabel     = "nephio.org/dscp"
+	sliceSSTLabel = "nephio.org/slice-sst"
+	sliceSDLabel  = "nephio.org/slice-sd"
+)
+
+var sliceSDRegexp = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// dataNetworkExt captures the optional attributes of a DataNetwork resource
+// that the dnn fn uses to generate the pool IPClaims, but which are not part of the nf requirements api
+type dataNetworkExt struct {
+	Spec dataNetworkExtSpec `json:"spec,omitempty"`
+}
+
+type dataNetworkExtSpec struct {
+	Pools []poolExt `json:"pools,omitempty"`
+}
+
+type poolExt struct {
+	// Name defines the name of the pool
+	Name string `json:"name,omitempty"`
+	// QoS defines the QoS metadata of the traffic of the pool, e.g. a pool per 5QI or per slice
+	QoS *poolQoSExt `json:"qos,omitempty"`
+}
+
+type poolQoSExt struct {
+	// FiveQI defines the 5G QoS identifier of the traffic of the pool
+	FiveQI int `json:"5qi,omitempty"`
+	// DSCP defines the DSCP marking of the traffic of the pool
+	DSCP *int `json:"dscp,omitempty"`
+	// Slice defines the network slice (S-NSSAI) of the pool
+	Slice *sliceExt `json:"slice,omitempty"`
+}
+
+type sliceExt struct {
+	// SST defines the slice/service type
+	SST int `json:"sst"`
+	// SD defines the optional slice differentiator, 6 hexadecimal digits
+	SD string `json:"sd,omitempty"`
+}
+
+// getPoolQoS returns the QoS metadata per pool name of the DataNetwork
+func getPoolQoS(o *fn.KubeObject) (map[string]*poolQoSExt, error) {
+	dnnExt, err := ko.KubeObjectToStruct[dataNetworkExt](o)
+	if err != nil {
+		return nil, err
+	}
+	qos := map[string]*poolQoSExt{}
+	for _, pool := range dnnExt.Spec.Pools {
+		if _, ok := qos[pool.Name]; ok {
+			return nil, results.Errorf(results.CodeInvalidInput, "duplicate pool %s in DataNetwork %s", pool.Name, o.GetName()).WithResource(o).WithField("spec.pools")
+		}
+		if pool.QoS != nil {
+			if err := pool.QoS.validate(); err != nil {
+				return nil, results.Errorf(results.CodeInvalidInput, "invalid qos of pool %s in DataNetwork %s: %s", pool.Name, o.GetName(), err.Error()).
+					WithResource(o).WithField("spec.pools")
+			}
+		}
+		qos[pool.Name] = pool.QoS
+	}
+	return qos, nil
+}
+
+func (r *poolQoSExt) validate() error {
+	if r.FiveQI < 0 || r.FiveQI > 255 {
+		return fmt.Errorf("5qi %d out of range [1, 255]", r.FiveQI)
+	}
+	if r.DSCP != nil && (*r.DSCP < 0 || *r.DSCP > 63) {
+		return fmt.Errorf("dscp %d out of range [0, 63]", *r.DSCP)
+	}
+	if r.Slice != nil {
+		if r.Slice.SST < 0 || r.Slice.SST > 255 {
+			return fmt.Errorf("slice sst %d out of range [0, 255]", r.Slice.SST)
+		}
+		if r.Slice.SD != "" && !sliceSDRegexp.MatchString(r.Slice.SD) {
+			return fmt.Errorf("slice sd %s is not 6 hexadecimal digits", r.Slice.SD)
+		}
+	}
+	return nil
+}
+
+// getLabels returns the labels of the pool IPClaim carrying the QoS metadata
+func (r *poolQoSExt) getLabels() map[string]string {
+	labels := map[string]string{}
+	if r == nil {
+		return labels
+	}
+	if r.FiveQI != 0 {
+		labels[fiveQILabel] = strconv.Itoa(r.FiveQI)
+	}
+	if r.DSCP != nil {
+		labels[dscpLabel] = strconv.Itoa(*r.DSCP)
+	}
+	if r.Slice != nil {
+		labels[sliceSSTLabel] = strconv.Itoa(r.Slice.SST)
+		if r.Slice.SD != "" {
+			labels[sliceSDLabel] = r.Slice.SD
+		}
+	}
+	return labels
+}
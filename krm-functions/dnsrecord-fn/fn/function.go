@@ -0,0 +1,211 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nephio-project/nephio/krm-functions/lib/condkptsdk"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// recordsForAnnotation identifies the records generated by the fn with the resource they are published for
+	recordsForAnnotation = "nephio.org/dns-records-for"
+
+	// outputKey is the key in the data of the function config ConfigMap that selects the
+	// resource the records are published with, a DNSEndpoint by default
+	outputKey = "output"
+	// dnsEndpointOutput publishes the records with an external-dns DNSEndpoint
+	dnsEndpointOutput = "dnsEndpoint"
+	// configMapOutput publishes the records with a ConfigMap
+	configMapOutput = "configMap"
+	// domainKey is the key in the data of the function config ConfigMap that defines the domain
+	// appended to the logical names, e.g. 5gc.example.com
+	domainKey = "domain"
+	// ttlKey is the key in the data of the function config ConfigMap that defines the TTL in seconds
+	// of the DNSEndpoint records
+	ttlKey = "ttl"
+	// nfNameKey is the key in the data of the function config ConfigMap that overrides the NF name
+	// prefixing the logical names, derived from the kind of the NF deployment by default
+	nfNameKey = "nfName"
+)
+
+// interfaceOwnerPrefix prefixes the owner annotation of the IPClaims of an Interface with the name of the Interface
+var interfaceOwnerPrefix = fmt.Sprintf("%s.%s.", nephioreqv1alpha1.GroupVersion.Identifier(), nephioreqv1alpha1.InterfaceKind)
+
+type config struct {
+	output string
+	domain string
+	ttl    int64
+	nfName string
+}
+
+// Run is the entry point of the KRM function (called by the upstream fn SDK)
+// it publishes the addresses of the resolved IPClaims of the interfaces of the NF
+// under their logical names, e.g. upf-n3
+func Run(rl *fn.ResourceList) (bool, error) {
+	cfg, err := getConfig(rl.FunctionConfig)
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+
+	// the records are regenerated, as such the records of deleted claims are removed
+	items := fn.KubeObjects{}
+	for _, o := range rl.Items {
+		if o.GetAnnotation(recordsForAnnotation) == "" {
+			items = append(items, o)
+		}
+	}
+	// the network instance of the interfaces distinguishes the claims of their additional network instances
+	networkInstances := map[string]string{}
+	for _, o := range items.Where(fn.IsGroupVersionKind(nephioreqv1alpha1.InterfaceGroupVersionKind)) {
+		itfce, err := ko.KubeObjectToStruct[nephioreqv1alpha1.Interface](o)
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		if itfce.Spec.NetworkInstance != nil {
+			networkInstances[itfce.GetName()] = itfce.Spec.NetworkInstance.Name
+		}
+	}
+
+	recordsPerNF := map[string]records{}
+	for _, o := range items.Where(fn.IsGroupVersionKind(ipamv1alpha1.IPClaimGroupVersionKind)) {
+		owner := o.GetAnnotation(condkptsdk.SpecializerOwner)
+		if !strings.HasPrefix(owner, interfaceOwnerPrefix) {
+			continue
+		}
+		claim, err := ko.KubeObjectToStruct[ipamv1alpha1.IPClaim](o)
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		if claim.Spec.Kind != ipamv1alpha1.PrefixKindNetwork && claim.Spec.Kind != ipamv1alpha1.PrefixKindLoopback {
+			continue
+		}
+		if claim.Status.Prefix == nil {
+			rl.Results.Warningf("IPClaim %s is not resolved, its address is not published", claim.GetName())
+			continue
+		}
+		pi, err := netip.ParsePrefix(*claim.Status.Prefix)
+		if err != nil {
+			err = fmt.Errorf("invalid prefix %s in the status of IPClaim %s: %s", *claim.Status.Prefix, claim.GetName(), err.Error())
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		forFullName := o.GetAnnotation(condkptsdk.SpecializerFor)
+		if forFullName == "" {
+			rl.Results.Warningf("IPClaim %s has no %s annotation, its address is not published", claim.GetName(), condkptsdk.SpecializerFor)
+			continue
+		}
+		nfName := cfg.nfName
+		if nfName == "" {
+			nfName = getNFName(forFullName)
+		}
+		itfceName := strings.TrimPrefix(owner, interfaceOwnerPrefix)
+		name := fmt.Sprintf("%s-%s", nfName, itfceName)
+		if ni, ok := networkInstances[itfceName]; ok && ni != claim.Spec.NetworkInstance.Name {
+			name = fmt.Sprintf("%s-%s", name, claim.Spec.NetworkInstance.Name)
+		}
+		recordType := recordTypeA
+		if pi.Addr().Is6() {
+			recordType = recordTypeAAAA
+		}
+		if _, ok := recordsPerNF[forFullName]; !ok {
+			recordsPerNF[forFullName] = records{}
+		}
+		recordsPerNF[forFullName].add(name, recordType, pi.Addr().String())
+	}
+
+	forFullNames := make([]string, 0, len(recordsPerNF))
+	for forFullName := range recordsPerNF {
+		forFullNames = append(forFullNames, forFullName)
+	}
+	sort.Strings(forFullNames)
+	for _, forFullName := range forFullNames {
+		meta := metav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-dns", getForName(forFullName)),
+			Annotations: map[string]string{
+				recordsForAnnotation: forFullName,
+			},
+		}
+		var o *fn.KubeObject
+		switch cfg.output {
+		case configMapOutput:
+			o, err = recordsPerNF[forFullName].getConfigMap(meta, cfg.domain)
+		default:
+			o, err = recordsPerNF[forFullName].getDNSEndpoint(meta, cfg.domain, cfg.ttl)
+		}
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		rl.Results.Infof("%d records of %s published with %s %s", len(recordsPerNF[forFullName]), forFullName, o.GetKind(), o.GetName())
+		items = append(items, o)
+	}
+	rl.Items = items
+	return true, nil
+}
+
+func getConfig(fc *fn.KubeObject) (*config, error) {
+	cfg := &config{output: dnsEndpointOutput}
+	if fc == nil {
+		return cfg, nil
+	}
+	if s, ok, _ := fc.NestedString("data", outputKey); ok {
+		cfg.output = s
+	}
+	if cfg.output != dnsEndpointOutput && cfg.output != configMapOutput {
+		return nil, fmt.Errorf("output %s not supported, supported outputs: [%s %s]", cfg.output, dnsEndpointOutput, configMapOutput)
+	}
+	if s, ok, _ := fc.NestedString("data", ttlKey); ok && s != "" {
+		ttl, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid %s %s, expected a number of seconds", ttlKey, s)
+		}
+		cfg.ttl = ttl
+	}
+	cfg.domain, _, _ = fc.NestedString("data", domainKey)
+	cfg.nfName, _, _ = fc.NestedString("data", nfNameKey)
+	return cfg, nil
+}
+
+// getNFName returns the NF name of the resource the records are published for, based
+// on its kind, e.g. upf for workload.nephio.org/v1alpha1.UPFDeployment.upf-cluster01
+func getNFName(forFullName string) string {
+	split := strings.Split(forFullName, ".")
+	if len(split) < 2 {
+		return forFullName
+	}
+	return strings.ToLower(strings.TrimSuffix(split[len(split)-2], "Deployment"))
+}
+
+// getForName returns the name of the resource the records are published for
+func getForName(forFullName string) string {
+	split := strings.Split(forFullName, ".")
+	return split[len(split)-1]
+}
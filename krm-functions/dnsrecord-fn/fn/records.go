@@ -0,0 +1,138 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	dnsEndpointAPIVersion = "externaldns.k8s.io/v1alpha1"
+	dnsEndpointKind       = "DNSEndpoint"
+
+	recordTypeA    = "A"
+	recordTypeAAAA = "AAAA"
+)
+
+// dnsEndpoint is the DNSEndpoint resource of the external-dns CRD source
+type dnsEndpoint struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Spec              dnsEndpointSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+type dnsEndpointSpec struct {
+	Endpoints []endpoint `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+}
+
+type endpoint struct {
+	// DNSName defines the hostname of the record
+	DNSName string `json:"dnsName" yaml:"dnsName"`
+	// Targets defines the addresses of the record
+	Targets []string `json:"targets" yaml:"targets"`
+	// RecordType defines the type of the record, A or AAAA
+	RecordType string `json:"recordType" yaml:"recordType"`
+	// RecordTTL defines the TTL of the record in seconds, the provider default when not set
+	RecordTTL int64 `json:"recordTTL,omitempty" yaml:"recordTTL,omitempty"`
+}
+
+// records holds the addresses of the logical names of a NF per record type
+type records map[string]map[string][]string
+
+func (r records) add(name, recordType, address string) {
+	if _, ok := r[name]; !ok {
+		r[name] = map[string][]string{}
+	}
+	for _, a := range r[name][recordType] {
+		if a == address {
+			return
+		}
+	}
+	r[name][recordType] = append(r[name][recordType], address)
+}
+
+// names returns the sorted logical names of the records
+func (r records) names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getDNSEndpoint returns a DNSEndpoint with an endpoint per logical name and record type
+func (r records) getDNSEndpoint(meta metav1.ObjectMeta, domain string, ttl int64) (*fn.KubeObject, error) {
+	endpoints := []endpoint{}
+	for _, name := range r.names() {
+		for _, recordType := range []string{recordTypeA, recordTypeAAAA} {
+			addresses, ok := r[name][recordType]
+			if !ok {
+				continue
+			}
+			sort.Strings(addresses)
+			endpoints = append(endpoints, endpoint{
+				DNSName:    getDNSName(name, domain),
+				Targets:    addresses,
+				RecordType: recordType,
+				RecordTTL:  ttl,
+			})
+		}
+	}
+	return fn.NewFromTypedObject(&dnsEndpoint{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: dnsEndpointAPIVersion,
+			Kind:       dnsEndpointKind,
+		},
+		ObjectMeta: meta,
+		Spec:       dnsEndpointSpec{Endpoints: endpoints},
+	})
+}
+
+// getConfigMap returns a ConfigMap with the comma separated addresses per logical name
+func (r records) getConfigMap(meta metav1.ObjectMeta, domain string) (*fn.KubeObject, error) {
+	data := map[string]string{}
+	for _, name := range r.names() {
+		addresses := []string{}
+		for _, recordType := range []string{recordTypeA, recordTypeAAAA} {
+			a := r[name][recordType]
+			sort.Strings(a)
+			addresses = append(addresses, a...)
+		}
+		data[getDNSName(name, domain)] = strings.Join(addresses, ",")
+	}
+	return fn.NewFromTypedObject(&corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.Identifier(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: meta,
+		Data:       data,
+	})
+}
+
+func getDNSName(name, domain string) string {
+	if domain == "" {
+		return name
+	}
+	return strings.Join([]string{name, strings.Trim(domain, ".")}, ".")
+}
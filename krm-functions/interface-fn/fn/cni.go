@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fn
+
+import (
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+)
+
+const (
+	// cniPreferenceKey is the key in the data of the function config ConfigMap that lists the cni types
+	// in order of preference, comma separated; it enables the selection of the cni type of an interface
+	// with attachmentType vlan that omits the cniType
+	cniPreferenceKey = "cniPreference"
+	// cniTypeAnnotation records the selected cni type on the interface, the nad fn renders the nad with it
+	cniTypeAnnotation = "nephio.org/cni-type"
+)
+
+// getCNIPreference returns the cni types of the function config in order of preference
+func getCNIPreference(fc *fn.KubeObject) []nephioreqv1alpha1.CNIType {
+	if fc == nil {
+		return nil
+	}
+	v, _, _ := fc.NestedString("data", cniPreferenceKey)
+	cniTypes := []nephioreqv1alpha1.CNIType{}
+	for _, cniType := range splitList(v) {
+		cniTypes = append(cniTypes, nephioreqv1alpha1.CNIType(cniType))
+	}
+	return cniTypes
+}
+
+// getCNIType returns the cni type of the interface and true when the cni type is selected
+// from the cni types of the workload cluster, the first cni type of the preference supported
+// by the cluster is selected. An interface without cniType and without vlan attachment remains
+// a loopback interface
+func (f *itfceFn) getCNIType(itfce *nephioreqv1alpha1.Interface) (nephioreqv1alpha1.CNIType, bool, error) {
+	if itfce.Spec.CNIType != "" || len(f.cniPreference) == 0 || itfce.Spec.AttachmentType != nephioreqv1alpha1.AttachmentTypeVLAN {
+		return itfce.Spec.CNIType, false, nil
+	}
+	for _, cniType := range f.cniPreference {
+		if f.IsCNITypePresent(cniType) {
+			return cniType, true, nil
+		}
+	}
+	return "", false, results.Errorf(results.CodeUnsupportedByCluster, "no cniType of the preference supported in workload cluster; workload cluster CNI(s): %v, cniType preference: %v", f.workloadCluster.Spec.CNIs, f.cniPreference).
+		WithRemediation("add a cniType of the workload cluster to the %s of the function config", cniPreferenceKey)
+}
@@ -28,31 +28,45 @@ import (
 	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
 	"github.com/nephio-project/nephio/krm-functions/lib/condkptsdk"
 	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+	wclib "github.com/nephio-project/nephio/krm-functions/lib/workloadcluster"
 	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
 	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
 )
 
-const defaultPODNetwork = "default"
+const (
+	defaultPODNetwork = "default"
+	// networkInstancesAnnotation lists the additional network instances of an interface, comma separated
+	// e.g. when signalling and media are attached to separate VRFs
+	networkInstancesAnnotation = "nephio.org/network-instances"
+	// vlanRangeAnnotation requests a trunk of vlans for the interface, either as start:end or as a size
+	vlanRangeAnnotation = "nephio.org/vlan-range"
+)
 
 type itfceFn struct {
-	sdk             condkptsdk.KptCondSDK
-	workloadCluster *infrav1alpha1.WorkloadCluster
+	sdk                condkptsdk.KptCondSDK
+	workloadCluster    *infrav1alpha1.WorkloadCluster
+	workloadClusterExt *wclib.WorkloadClusterExt
+	cniPreference      []nephioreqv1alpha1.CNIType
 }
 
 func Run(rl *fn.ResourceList) (bool, error) {
-	myFn := itfceFn{}
+	myFn := itfceFn{
+		cniPreference: getCNIPreference(rl.FunctionConfig),
+	}
 	var err error
 	myFn.sdk, err = condkptsdk.New(
 		rl,
 		&condkptsdk.Config{
-			For: corev1.ObjectReference{
+			For: []corev1.ObjectReference{{
 				APIVersion: nephioreqv1alpha1.GroupVersion.Identifier(),
 				Kind:       nephioreqv1alpha1.InterfaceKind,
-			},
+			}},
 			Owns: map[corev1.ObjectReference]condkptsdk.ResourceKind{
 				{
 					APIVersion: nadv1.SchemeGroupVersion.Identifier(),
@@ -66,6 +80,10 @@ func Run(rl *fn.ResourceList) (bool, error) {
 					APIVersion: vlanv1alpha1.GroupVersion.Identifier(),
 					Kind:       vlanv1alpha1.VLANClaimKind,
 				}: condkptsdk.ChildRemote,
+				{
+					APIVersion: sriovNetworkAPIVersion,
+					Kind:       sriovNetworkNodePolicyKind,
+				}: condkptsdk.ChildLocal,
 			},
 			Watch: map[corev1.ObjectReference]condkptsdk.WatchCallbackFn{
 				{
@@ -78,7 +96,7 @@ func Run(rl *fn.ResourceList) (bool, error) {
 		},
 	)
 	if err != nil {
-		rl.Results.ErrorE(err)
+		results.ErrorE(rl, err)
 		return false, err
 	}
 	return myFn.sdk.Run()
@@ -90,9 +108,13 @@ func (f *itfceFn) WorkloadClusterCallbackFn(o *fn.KubeObject) error {
 	var err error
 
 	if f.workloadCluster != nil {
-		return fmt.Errorf("multiple WorkloadCluster objects found in the kpt package")
+		return results.Errorf(results.CodeDuplicateResource, "multiple WorkloadCluster objects found in the kpt package").WithResource(o)
 	}
-	f.workloadCluster, err = ko.KubeObjectToStruct[infrav1alpha1.WorkloadCluster](o)
+	f.workloadCluster, err = ko.CachedKubeObjectToStruct[infrav1alpha1.WorkloadCluster](o)
+	if err != nil {
+		return err
+	}
+	f.workloadClusterExt, err = ko.KubeObjectToStruct[wclib.WorkloadClusterExt](o)
 	if err != nil {
 		return err
 	}
@@ -106,7 +128,7 @@ func (f *itfceFn) WorkloadClusterCallbackFn(o *fn.KubeObject) error {
 func (f *itfceFn) desiredOwnedResourceList(o *fn.KubeObject) (fn.KubeObjects, error) {
 	if f.workloadCluster == nil {
 		// no WorkloadCluster resource in the package
-		return nil, fmt.Errorf("workload cluster is missing from the kpt package")
+		return nil, results.Errorf(results.CodeMissingResource, "workload cluster is missing from the kpt package")
 	}
 	// resources contain the list of child resources
 	// belonging to the parent object
@@ -129,26 +151,71 @@ func (f *itfceFn) desiredOwnedResourceList(o *fn.KubeObject) (fn.KubeObjects, er
 		return fn.KubeObjects{}, nil
 	}
 
-	afs := getAddressFamilies(itfce.Spec.IpFamilyPolicy)
+	// an IPClaim is generated per address family of the interface, the ipFamilyPolicy none
+	// defines an L2 interface without IPClaims
+	afs, err := getAddressFamilies(itfce.Spec.IpFamilyPolicy)
+	if err != nil {
+		return nil, err
+	}
 	purpose := o.GetAnnotation(resourcev1alpha1.NephioNetworkNameKey)
+	cniType, selected, err := f.getCNIType(itfce)
+	if err != nil {
+		return nil, err
+	}
+	if selected {
+		// the selected cni type is recorded on the interface, the nad fn renders the nad with it
+		if err := o.SetAnnotation(cniTypeAnnotation, string(cniType)); err != nil {
+			return nil, err
+		}
+	}
 
 	// When the CNIType is not set this is a loopback interface
-	if itfce.Spec.CNIType != "" {
-		if !f.IsCNITypePresent(itfce.Spec.CNIType) {
-			return nil, fmt.Errorf("cniType not supported in workload cluster; workload cluster CNI(s): %v, interface cniType requested: %s", f.workloadCluster.Spec.CNIs, itfce.Spec.CNIType)
+	if cniType != "" {
+		// an internal-only interface, e.g. for the N4 internal addresses, is not attached to the pod
+		// hence only the IPClaims are generated and the interface is ready without a NAD
+		internalOnly := itfce.Spec.AttachmentType == nephioreqv1alpha1.AttachmentTypeNone
+		if !internalOnly && !f.IsCNITypePresent(cniType) {
+			return nil, results.Errorf(results.CodeUnsupportedByCluster, "cniType not supported in workload cluster; workload cluster CNI(s): %v, interface cniType requested: %s", f.workloadCluster.Spec.CNIs, cniType).WithResource(o).WithField("spec.cniType")
+		}
+		if o.GetAnnotation(vlanRangeAnnotation) != "" && itfce.Spec.AttachmentType != nephioreqv1alpha1.AttachmentTypeVLAN {
+			return nil, results.Errorf(results.CodeInvalidInput, "annotation %s requires attachmentType %s", vlanRangeAnnotation, nephioreqv1alpha1.AttachmentTypeVLAN).WithResource(o).WithField("spec.attachmentType")
+		}
+		if len(afs) == 0 && itfce.Spec.AttachmentType != nephioreqv1alpha1.AttachmentTypeVLAN {
+			return nil, results.Errorf(results.CodeInvalidInput, "ipFamilyPolicy %s requires attachmentType %s, since the interface has no ip addresses", itfce.Spec.IpFamilyPolicy, nephioreqv1alpha1.AttachmentTypeVLAN).WithResource(o).WithField("spec.attachmentType")
+		}
+		// add IPClaim of type network, the static prefixes are pre-resolved
+		sps, err := getStaticPrefixes(o, afs, false)
+		if err != nil {
+			return nil, err
 		}
-		// add IPClaim of type network
 		for _, af := range afs {
 			meta := metav1.ObjectMeta{
 				Name:        fmt.Sprintf("%s-%s-%s", getForName(o.GetAnnotations()), o.GetName(), string(af)),
 				Annotations: getAnnotations(o.GetAnnotations()),
 			}
-			obj, err := f.getIPClaim(meta, *itfce.Spec.NetworkInstance, ipamv1alpha1.PrefixKindNetwork, af, purpose)
+			obj, err := f.getIPClaim(meta, *itfce.Spec.NetworkInstance, ipamv1alpha1.PrefixKindNetwork, af, purpose, sps[af])
 			if err != nil {
 				return nil, err
 			}
 			resources = append(resources, obj)
 		}
+		// add IPClaims of type network for the additional network instances
+		for _, ni := range getAdditionalNetworkInstances(o.GetAnnotation(networkInstancesAnnotation), itfce.Spec.NetworkInstance.Name) {
+			for _, af := range afs {
+				meta := metav1.ObjectMeta{
+					Name:        fmt.Sprintf("%s-%s-%s-%s", getForName(o.GetAnnotations()), o.GetName(), ni, string(af)),
+					Annotations: getAnnotations(o.GetAnnotations()),
+				}
+				obj, err := f.getIPClaim(meta, corev1.ObjectReference{Name: ni}, ipamv1alpha1.PrefixKindNetwork, af, purpose, nil)
+				if err != nil {
+					return nil, err
+				}
+				resources = append(resources, obj)
+			}
+		}
+		if internalOnly {
+			return resources, nil
+		}
 
 		if itfce.Spec.AttachmentType == nephioreqv1alpha1.AttachmentTypeVLAN {
 			// add VLANClaim
@@ -156,13 +223,32 @@ func (f *itfceFn) desiredOwnedResourceList(o *fn.KubeObject) (fn.KubeObjects, er
 				Name:        fmt.Sprintf("%s-%s", getForName(o.GetAnnotations()), o.GetName()),
 				Annotations: f.getAnnotationsWithvlanClaimName(itfce),
 			}
-			obj, err := f.getVLANClaim(meta)
+			obj, err := f.getVLANClaim(meta, o.GetAnnotation(vlanRangeAnnotation))
 			if err != nil {
 				return nil, err
 			}
 			resources = append(resources, obj)
 		}
 
+		if cniType == sriovCNIType {
+			// add the SriovNetworkNodePolicy of the resource pool the interface is allocated from
+			pool, err := f.getSRIOVResourcePool(o)
+			if err != nil {
+				return nil, err
+			}
+			if pool != nil {
+				meta := metav1.ObjectMeta{
+					Name:        fmt.Sprintf("%s-%s", getForName(o.GetAnnotations()), o.GetName()),
+					Annotations: getAnnotations(o.GetAnnotations()),
+				}
+				obj, err := f.getSRIOVNetworkNodePolicy(meta, pool)
+				if err != nil {
+					return nil, err
+				}
+				resources = append(resources, obj)
+			}
+		}
+
 		// claim nad
 		meta := metav1.ObjectMeta{
 			Name:        fmt.Sprintf("%s-%s", getForName(o.GetAnnotations()), o.GetName()),
@@ -174,13 +260,20 @@ func (f *itfceFn) desiredOwnedResourceList(o *fn.KubeObject) (fn.KubeObjects, er
 		}
 		resources = append(resources, o)
 	} else {
-		// add IPClaim of type loopback
+		if len(afs) == 0 {
+			return nil, results.Errorf(results.CodeInvalidInput, "ipFamilyPolicy %s not supported for a loopback interface", itfce.Spec.IpFamilyPolicy).WithResource(o).WithField("spec.ipFamilyPolicy")
+		}
+		// add IPClaim of type loopback, the static prefixes are pre-resolved
+		sps, err := getStaticPrefixes(o, afs, true)
+		if err != nil {
+			return nil, err
+		}
 		for _, af := range afs {
 			meta := metav1.ObjectMeta{
 				Name:        fmt.Sprintf("%s-%s-%s", getForName(o.GetAnnotations()), o.GetName(), string(af)),
 				Annotations: getAnnotations(o.GetAnnotations()),
 			}
-			o, err := f.getIPClaim(meta, *itfce.Spec.NetworkInstance, ipamv1alpha1.PrefixKindLoopback, af, purpose)
+			o, err := f.getIPClaim(meta, *itfce.Spec.NetworkInstance, ipamv1alpha1.PrefixKindLoopback, af, purpose, sps[af])
 			if err != nil {
 				return nil, err
 			}
@@ -208,51 +301,56 @@ func (f *itfceFn) updateItfceResource(forObj *fn.KubeObject, objs fn.KubeObjects
 	sort.Slice(ipclaims, func(i, j int) bool {
 		return ipclaims[i].GetName() < ipclaims[j].GetName()
 	})
-	for _, ipclaim := range ipclaims {
-		// Don't care about the name since the condSDK sorts the data
-		// based on owner reference
-		claim, err := ko.NewFromKubeObject[ipamv1alpha1.IPClaim](ipclaim)
-		if err != nil {
-			return nil, err
-		}
-		claimGoStruct, err := claim.GetGoStruct()
-		if err != nil {
-			return nil, err
-		}
+	// Don't care about the name since the condSDK sorts the data
+	// based on owner reference
+	ipClaimGoStructs, err := ko.KubeObjectsToStructs[ipamv1alpha1.IPClaim](ipclaims)
+	if err != nil {
+		return nil, err
+	}
+	for _, claimGoStruct := range ipClaimGoStructs {
 		itfce.Status.UpsertIPClaim(claimGoStruct.Status)
 	}
 	vlanclaims := objs.Where(fn.IsGroupVersionKind(vlanv1alpha1.VLANClaimGroupVersionKind))
-	for _, vlanclaim := range vlanclaims {
-		claim, err := ko.NewFromKubeObject[vlanv1alpha1.VLANClaim](vlanclaim)
-		if err != nil {
-			return nil, err
-		}
-		claimGoStruct, err := claim.GetGoStruct()
-		if err != nil {
-			return nil, err
-		}
-		itfce.Status.VLANClaimStatus = &claimGoStruct.Status
+	vlanClaimGoStructs, err := ko.KubeObjectsToStructs[vlanv1alpha1.VLANClaim](vlanclaims)
+	if err != nil {
+		return nil, err
+	}
+	for i := range vlanClaimGoStructs {
+		itfce.Status.VLANClaimStatus = &vlanClaimGoStructs[i].Status
 	}
 	// set the status
 	err = itfceKOE.SetStatus(itfce)
 	return fn.KubeObjects{&itfceKOE.KubeObject}, err
 }
 
-func (f *itfceFn) getVLANClaim(meta metav1.ObjectMeta) (*fn.KubeObject, error) {
+// getVLANClaim returns the VLANClaim of the interface, a vlan range claims a trunk of vlans
+// instead of a single vlan
+func (f *itfceFn) getVLANClaim(meta metav1.ObjectMeta, vlanRange string) (*fn.KubeObject, error) {
 	claim := vlanv1alpha1.BuildVLANClaim(
 		meta,
 		vlanv1alpha1.VLANClaimSpec{
 			VLANIndex: corev1.ObjectReference{
-				Name: f.workloadCluster.Spec.ClusterName,
+				Name: f.getVLANDomain(),
 			},
 		},
 		vlanv1alpha1.VLANClaimStatus{},
 	)
+	if vlanRange != "" {
+		claim.Spec.VLANRange = pointer.String(vlanRange)
+		ctx, err := claim.GetVLANClaimCtx()
+		if err != nil {
+			return nil, results.Errorf(results.CodeInvalidInput, "invalid vlan range %s in annotation %s: %s", vlanRange, vlanRangeAnnotation, err.Error()).WithField("metadata.annotations." + vlanRangeAnnotation)
+		}
+		if ctx.Size == 0 {
+			return nil, results.Errorf(results.CodeInvalidInput, "invalid vlan range %s in annotation %s: the range is empty", vlanRange, vlanRangeAnnotation).WithField("metadata.annotations." + vlanRangeAnnotation)
+		}
+	}
 
 	return fn.NewFromTypedObject(claim)
 }
 
-func (f *itfceFn) getIPClaim(meta metav1.ObjectMeta, ni corev1.ObjectReference, kind ipamv1alpha1.PrefixKind, af nephioreqv1alpha1.IPFamily, purpose string) (*fn.KubeObject, error) {
+// getIPClaim returns the IPClaim of the address family, a static prefix pre-resolves the claim
+func (f *itfceFn) getIPClaim(meta metav1.ObjectMeta, ni corev1.ObjectReference, kind ipamv1alpha1.PrefixKind, af nephioreqv1alpha1.IPFamily, purpose string, sp *staticPrefix) (*fn.KubeObject, error) {
 	matchLabels := map[string]string{
 		resourcev1alpha1.NephioClusterNameKey:   f.workloadCluster.Spec.ClusterName,
 		resourcev1alpha1.NephioAddressFamilyKey: string(af),
@@ -274,6 +372,9 @@ func (f *itfceFn) getIPClaim(meta metav1.ObjectMeta, ni corev1.ObjectReference,
 		},
 		ipamv1alpha1.IPClaimStatus{},
 	)
+	if sp != nil {
+		sp.setIPClaim(claim)
+	}
 	return fn.NewFromTypedObject(claim)
 }
 
@@ -307,10 +408,19 @@ func (f *itfceFn) IsCNITypePresent(itfceCNIType nephioreqv1alpha1.CNIType) bool
 
 func (f *itfceFn) getAnnotationsWithvlanClaimName(itfce *nephioreqv1alpha1.Interface) map[string]string {
 	a := getAnnotations(itfce.GetAnnotations())
-	a[condkptsdk.SpecializervlanClaimName] = fmt.Sprintf("%s-%s-bd", itfce.Spec.NetworkInstance.Name, f.workloadCluster.Spec.ClusterName)
+	a[condkptsdk.SpecializervlanClaimName] = fmt.Sprintf("%s-%s-bd", itfce.Spec.NetworkInstance.Name, f.getVLANDomain())
 	return a
 }
 
+// getVLANDomain returns the vlan domain of the workload cluster, which is the vlan index
+// the vlans are claimed from; the cluster name when the cluster defines no vlan domain
+func (f *itfceFn) getVLANDomain() string {
+	if f.workloadClusterExt != nil && f.workloadClusterExt.Spec.VLANDomain != "" {
+		return f.workloadClusterExt.Spec.VLANDomain
+	}
+	return f.workloadCluster.Spec.ClusterName
+}
+
 func getAnnotations(annotations map[string]string) map[string]string {
 	a := map[string]string{}
 	for k, v := range annotations {
@@ -337,18 +447,47 @@ func getForName(annotations map[string]string) string {
 	return split[len(split)-1]
 }
 
-func getAddressFamilies(pol nephioreqv1alpha1.IpFamilyPolicy) []nephioreqv1alpha1.IPFamily {
+// getAdditionalNetworkInstances returns the network instances of the annotation
+// excluding the network instance of the interface and duplicates
+func getAdditionalNetworkInstances(annotation, networkInstance string) []string {
+	nis := []string{}
+	for _, ni := range strings.Split(annotation, ",") {
+		ni = strings.TrimSpace(ni)
+		if ni == "" || ni == networkInstance || ni == defaultPODNetwork {
+			continue
+		}
+		found := false
+		for _, n := range nis {
+			if n == ni {
+				found = true
+			}
+		}
+		if !found {
+			nis = append(nis, ni)
+		}
+	}
+	return nis
+}
+
+// getAddressFamilies returns the address families of the ipFamilyPolicy, ipv4 when no policy is set
+func getAddressFamilies(pol nephioreqv1alpha1.IpFamilyPolicy) ([]nephioreqv1alpha1.IPFamily, error) {
 	afs := []nephioreqv1alpha1.IPFamily{}
 	switch pol {
+	case nephioreqv1alpha1.IpFamilyPolicyNone:
 	case nephioreqv1alpha1.IpFamilyPolicyDualStack:
 		afs = append(afs, nephioreqv1alpha1.IPFamilyIPv4)
 		afs = append(afs, nephioreqv1alpha1.IPFamilyIPv6)
 	case nephioreqv1alpha1.IpFamilyPolicyIPv6Only:
 		afs = append(afs, nephioreqv1alpha1.IPFamilyIPv6)
-	case nephioreqv1alpha1.IpFamilyPolicyIPv4Only:
+	case nephioreqv1alpha1.IpFamilyPolicyIPv4Only, "":
 		afs = append(afs, nephioreqv1alpha1.IPFamilyIPv4)
 	default:
-		afs = append(afs, nephioreqv1alpha1.IPFamilyIPv4)
+		return nil, results.Errorf(results.CodeInvalidInput, "ipFamilyPolicy %s not supported, supported policies: %v", pol, []nephioreqv1alpha1.IpFamilyPolicy{
+			nephioreqv1alpha1.IpFamilyPolicyNone,
+			nephioreqv1alpha1.IpFamilyPolicyIPv4Only,
+			nephioreqv1alpha1.IpFamilyPolicyIPv6Only,
+			nephioreqv1alpha1.IpFamilyPolicyDualStack,
+		}).WithField("spec.ipFamilyPolicy")
 	}
-	return afs
+	return afs, nil
 }
@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fn
+
+import (
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+	wclib "github.com/nephio-project/nephio/krm-functions/lib/workloadcluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+)
+
+const (
+	sriovNetworkAPIVersion        = "sriovnetwork.openshift.io/v1"
+	sriovNetworkNodePolicyKind    = "SriovNetworkNodePolicy"
+	sriovNetworkOperatorNamespace = "sriov-network-operator"
+	sriovDefaultDeviceType        = "netdevice"
+	sriovCapableNodeLabel         = "feature.node.kubernetes.io/network-sriov.capable"
+	sriovCNIType                  = nephioreqv1alpha1.CNIType("sriov")
+	// sriovResourcePoolAnnotation selects the sriov resource pool of the workload cluster the interface is allocated from
+	sriovResourcePoolAnnotation = "nephio.org/sriov-resource-pool"
+)
+
+// sriovNetworkNodePolicy is the SriovNetworkNodePolicy of the sriov network operator, which
+// configures the virtual functions and the device plugin resource of a resource pool
+type sriovNetworkNodePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              sriovNetworkNodePolicySpec `json:"spec,omitempty"`
+}
+
+type sriovNetworkNodePolicySpec struct {
+	ResourceName string            `json:"resourceName"`
+	NodeSelector map[string]string `json:"nodeSelector"`
+	MTU          int               `json:"mtu,omitempty"`
+	NumVfs       int               `json:"numVfs"`
+	NicSelector  sriovNicSelector  `json:"nicSelector"`
+	DeviceType   string            `json:"deviceType,omitempty"`
+}
+
+type sriovNicSelector struct {
+	PfNames []string `json:"pfNames,omitempty"`
+}
+
+// getSRIOVResourcePool returns the sriov resource pool of the workload cluster the interface is
+// allocated from; the pool is selected with the sriov resource pool annotation of the interface and
+// a single pool is selected by default. No pool is returned when the workload cluster has no pools
+func (f *itfceFn) getSRIOVResourcePool(o *fn.KubeObject) (*wclib.SRIOVResourcePool, error) {
+	pools := f.workloadClusterExt.Spec.SRIOVResourcePools
+	if len(pools) == 0 {
+		return nil, nil
+	}
+	name := o.GetAnnotation(sriovResourcePoolAnnotation)
+	if name == "" {
+		if len(pools) != 1 {
+			return nil, results.Errorf(results.CodeInvalidInput, "interface %s requires the %s annotation since workload cluster %s has multiple sriov resource pools", o.GetName(), sriovResourcePoolAnnotation, f.workloadCluster.Spec.ClusterName).
+				WithResource(o).WithField("metadata.annotations." + sriovResourcePoolAnnotation)
+		}
+		return &pools[0], nil
+	}
+	available := make([]string, 0, len(pools))
+	for i, p := range pools {
+		if p.Name == name {
+			return &pools[i], nil
+		}
+		available = append(available, p.Name)
+	}
+	return nil, results.Errorf(results.CodeUnsupportedByCluster, "sriov resource pool %s requested by interface %s is not available in workload cluster %s; available resource pools: %v", name, o.GetName(), f.workloadCluster.Spec.ClusterName, available).
+		WithResource(o).WithField("metadata.annotations." + sriovResourcePoolAnnotation)
+}
+
+// getSRIOVNetworkNodePolicy returns the SriovNetworkNodePolicy of the resource pool, the policy
+// is deployed in the workload cluster and hence is not local config
+func (f *itfceFn) getSRIOVNetworkNodePolicy(meta metav1.ObjectMeta, pool *wclib.SRIOVResourcePool) (*fn.KubeObject, error) {
+	if len(pool.PFNames) == 0 || pool.NumVFs == 0 {
+		return nil, results.Errorf(results.CodeInvalidInput, "sriov resource pool %s of workload cluster %s requires pfNames and numVfs", pool.Name, f.workloadCluster.Spec.ClusterName).
+			WithRef(corev1.ObjectReference{APIVersion: f.workloadCluster.APIVersion, Kind: f.workloadCluster.Kind, Name: f.workloadCluster.Name}).
+			WithField("spec.sriovResourcePools")
+	}
+	delete(meta.Annotations, filters.LocalConfigAnnotation)
+	meta.Namespace = sriovNetworkOperatorNamespace
+
+	// the operator prefixes the resource name with its resource prefix, e.g. intel.com
+	resourceName := pool.Name
+	if i := strings.LastIndex(resourceName, "/"); i >= 0 {
+		resourceName = resourceName[i+1:]
+	}
+	nodeSelector := pool.NodeSelector
+	if len(nodeSelector) == 0 {
+		nodeSelector = map[string]string{sriovCapableNodeLabel: "true"}
+	}
+	deviceType := pool.DeviceType
+	if deviceType == "" {
+		deviceType = sriovDefaultDeviceType
+	}
+	return fn.NewFromTypedObject(&sriovNetworkNodePolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: sriovNetworkAPIVersion,
+			Kind:       sriovNetworkNodePolicyKind,
+		},
+		ObjectMeta: meta,
+		Spec: sriovNetworkNodePolicySpec{
+			ResourceName: resourceName,
+			NodeSelector: nodeSelector,
+			MTU:          pool.MTU,
+			NumVfs:       pool.NumVFs,
+			NicSelector:  sriovNicSelector{PfNames: pool.PFNames},
+			DeviceType:   deviceType,
+		},
+	})
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fn
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// staticPrefixesAnnotation defines the externally managed prefixes of the interface, one per address family,
+	// comma separated (e.g. 10.0.0.10/24,2001:db8::10/64); the ip claims of these prefixes bypass the ipam
+	staticPrefixesAnnotation = "nephio.org/static-prefixes"
+	// staticGatewaysAnnotation defines the gateways of the static prefixes, one per address family, comma separated
+	staticGatewaysAnnotation = "nephio.org/static-gateways"
+	// staticIPAnnotation marks an ip claim as pre-resolved, the ipam fn does not claim it from the ipam backend
+	staticIPAnnotation = "nephio.org/static-ip"
+)
+
+// staticPrefix defines an externally managed prefix and its optional gateway
+type staticPrefix struct {
+	prefix  string
+	gateway string
+}
+
+// getStaticPrefixes returns the static prefixes of the interface per address family from the annotations,
+// an address family without static prefix is claimed from the ipam
+func getStaticPrefixes(o *fn.KubeObject, afs []nephioreqv1alpha1.IPFamily, loopback bool) (map[nephioreqv1alpha1.IPFamily]*staticPrefix, error) {
+	sps := map[nephioreqv1alpha1.IPFamily]*staticPrefix{}
+	for _, s := range splitList(o.GetAnnotation(staticPrefixesAnnotation)) {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, results.Errorf(results.CodeInvalidInput, "invalid static prefix %s in annotation %s: %s", s, staticPrefixesAnnotation, err.Error()).WithResource(o).WithField("metadata.annotations." + staticPrefixesAnnotation)
+		}
+		af := getIPFamily(p.Addr())
+		if !hasIPFamily(afs, af) {
+			return nil, results.Errorf(results.CodeInvalidInput, "static prefix %s in annotation %s does not match the ip families of the interface: %v", s, staticPrefixesAnnotation, afs).WithResource(o).WithField("metadata.annotations." + staticPrefixesAnnotation)
+		}
+		if _, ok := sps[af]; ok {
+			return nil, results.Errorf(results.CodeInvalidInput, "multiple static %s prefixes in annotation %s", af, staticPrefixesAnnotation).WithResource(o).WithField("metadata.annotations." + staticPrefixesAnnotation)
+		}
+		sps[af] = &staticPrefix{prefix: s}
+	}
+	gws := splitList(o.GetAnnotation(staticGatewaysAnnotation))
+	if len(gws) != 0 && loopback {
+		return nil, results.Errorf(results.CodeInvalidInput, "annotation %s not supported for a loopback interface", staticGatewaysAnnotation).WithResource(o).WithField("metadata.annotations." + staticGatewaysAnnotation)
+	}
+	for _, s := range gws {
+		a, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, results.Errorf(results.CodeInvalidInput, "invalid static gateway %s in annotation %s: %s", s, staticGatewaysAnnotation, err.Error()).WithResource(o).WithField("metadata.annotations." + staticGatewaysAnnotation)
+		}
+		af := getIPFamily(a)
+		sp, ok := sps[af]
+		if !ok {
+			return nil, results.Errorf(results.CodeInvalidInput, "static gateway %s in annotation %s has no static %s prefix", s, staticGatewaysAnnotation, af).WithResource(o).WithField("metadata.annotations." + staticGatewaysAnnotation)
+		}
+		if sp.gateway != "" {
+			return nil, results.Errorf(results.CodeInvalidInput, "multiple static %s gateways in annotation %s", af, staticGatewaysAnnotation).WithResource(o).WithField("metadata.annotations." + staticGatewaysAnnotation)
+		}
+		if !netip.MustParsePrefix(sp.prefix).Masked().Contains(a) {
+			return nil, results.Errorf(results.CodeInvalidInput, "static gateway %s is not part of the static prefix %s", s, sp.prefix).WithResource(o).WithField("metadata.annotations." + staticGatewaysAnnotation)
+		}
+		sp.gateway = s
+	}
+	return sps, nil
+}
+
+// setIPClaim pre-resolves the ip claim with the static prefix, hence the claim
+// is ready without an allocation from the ipam backend
+func (r *staticPrefix) setIPClaim(claim *ipamv1alpha1.IPClaim) {
+	if claim.Annotations == nil {
+		claim.Annotations = map[string]string{}
+	}
+	claim.Annotations[staticIPAnnotation] = "true"
+	claim.Spec.Prefix = pointer.String(r.prefix)
+	claim.Status.Prefix = pointer.String(r.prefix)
+	if r.gateway != "" {
+		claim.Status.Gateway = pointer.String(r.gateway)
+	}
+}
+
+func getIPFamily(a netip.Addr) nephioreqv1alpha1.IPFamily {
+	if a.Is4() {
+		return nephioreqv1alpha1.IPFamilyIPv4
+	}
+	return nephioreqv1alpha1.IPFamilyIPv6
+}
+
+func hasIPFamily(afs []nephioreqv1alpha1.IPFamily, af nephioreqv1alpha1.IPFamily) bool {
+	for _, x := range afs {
+		if x == af {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(s string) []string {
+	l := []string{}
+	for _, x := range strings.Split(s, ",") {
+		if x = strings.TrimSpace(x); x != "" {
+			l = append(l, x)
+		}
+	}
+	return l
+}
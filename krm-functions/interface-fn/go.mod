@@ -12,6 +12,7 @@ require (
 	github.com/nokia/k8s-ipam v0.0.4-0.20230628092530-8a292aec80a4
 	k8s.io/api v0.27.3
 	k8s.io/apimachinery v0.27.3
+	k8s.io/utils v0.0.0-20230505201702-9f6742963106
 	sigs.k8s.io/kustomize/kyaml v0.14.2
 )
 
@@ -60,7 +61,6 @@ require (
 	k8s.io/client-go v0.27.2 // indirect
 	k8s.io/klog/v2 v2.100.1 // indirect
 	k8s.io/kube-openapi v0.0.0-20230525220651-2546d827e515 // indirect
-	k8s.io/utils v0.0.0-20230505201702-9f6742963106 // indirect
 	sigs.k8s.io/controller-runtime v0.15.0 // indirect
 	sigs.k8s.io/json v0.0.0-20221116044647-bc3834ca7abd // indirect
 	sigs.k8s.io/kustomize/api v0.13.3 // indirect
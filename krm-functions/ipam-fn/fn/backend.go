@@ -0,0 +1,70 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// backendKey is the key in the data of the function config ConfigMap that selects the ipam backend
+	// resolving the ip claims, by default the ip claims are resolved by the backend the fn is created with
+	backendKey = "backend"
+	// legacyBackendKey is the key that selected the offline backend before the backend key,
+	// it is still accepted when the backend key is not set
+	legacyBackendKey = "mode"
+	// offlineBackend allocates the ip claims from the prefixes of the Network resources in the package
+	offlineBackend = "offline"
+	// netboxBackend allocates the ip claims from a Netbox instance
+	netboxBackend = "netbox"
+)
+
+// Backend resolves the ip claims of the package. A clientproxy of the k8s-ipam controller
+// implements the Backend, alternative implementations resolve the ip claims from the
+// prefixes in the package or from an existing IPAM system
+type Backend interface {
+	// GetClaim returns the ip claim with the status of the existing prefix
+	GetClaim(ctx context.Context, cr client.Object, d any) (*ipamv1alpha1.IPClaim, error)
+	// Claim returns the ip claim with the status of the claimed prefix
+	Claim(ctx context.Context, cr client.Object, d any) (*ipamv1alpha1.IPClaim, error)
+}
+
+// getBackend returns the backend selected by the function config
+func (f *FnR) getBackend(rl *fn.ResourceList) (Backend, error) {
+	backend := ""
+	if rl.FunctionConfig != nil {
+		backend, _, _ = rl.FunctionConfig.NestedString("data", backendKey)
+		if backend == "" {
+			backend, _, _ = rl.FunctionConfig.NestedString("data", legacyBackendKey)
+		}
+	}
+	switch backend {
+	case "":
+		return f.Backend, nil
+	case offlineBackend:
+		return newLocalAllocator(rl.Items)
+	case netboxBackend:
+		return newNetbox(rl.FunctionConfig)
+	default:
+		return nil, fmt.Errorf("backend %s not supported, supported backends: [%s %s]", backend, offlineBackend, netboxBackend)
+	}
+}
@@ -25,24 +25,28 @@ import (
 	"github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
 	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
-	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy"
 	corev1 "k8s.io/api/core/v1"
 )
 
+// staticIPAnnotation marks an ip claim pre-resolved with an externally managed prefix
+const staticIPAnnotation = "nephio.org/static-ip"
+
 type FnR struct {
-	ClientProxy clientproxy.Proxy[*ipamv1alpha1.NetworkInstance, *ipamv1alpha1.IPClaim]
-	sdkConfig   *condkptsdk.Config
+	Backend   Backend
+	sdkConfig *condkptsdk.Config
+	// backend is the backend of the run, the Backend or the backend selected by the function config
+	backend Backend
 }
 
-func New(c clientproxy.Proxy[*ipamv1alpha1.NetworkInstance, *ipamv1alpha1.IPClaim]) *FnR {
+func New(b Backend) *FnR {
 	f := &FnR{
-		ClientProxy: c,
+		Backend: b,
 	}
 	f.sdkConfig = &condkptsdk.Config{
-		For: corev1.ObjectReference{
+		For: []corev1.ObjectReference{{
 			APIVersion: ipamv1alpha1.GroupVersion.Identifier(),
 			Kind:       ipamv1alpha1.IPClaimKind,
-		},
+		}},
 		PopulateOwnResourcesFn: nil,
 		UpdateResourceFn:       f.updateIPClaimResource,
 	}
@@ -54,6 +58,12 @@ func (f *FnR) GetConfig() condkptsdk.Config {
 }
 
 func (f *FnR) Run(rl *fn.ResourceList) (bool, error) {
+	backend, err := f.getBackend(rl)
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	f.backend = backend
 	sdk, err := condkptsdk.New(
 		rl,
 		f.sdkConfig,
@@ -80,18 +90,26 @@ func (f *FnR) updateIPClaimResource(forObj *fn.KubeObject, objs fn.KubeObjects)
 	if err != nil {
 		return nil, err
 	}
+	if claim.Annotations[staticIPAnnotation] == "true" {
+		// the prefix is managed externally, the claim bypasses the ipam backend
+		fn.Log("claim action static\n")
+		if claim.Status.Prefix == nil {
+			return nil, fmt.Errorf("static ip claim %s has no prefix", claim.GetName())
+		}
+		return fn.KubeObjects{&claimKOE.KubeObject}, nil
+	}
 	newclaim := claim.DeepCopy()
 	var resp *ipamv1alpha1.IPClaim
 	if _, ok := claim.Annotations[resourcev1alpha1.NephioAPIAction]; ok {
 		// get action
 		fn.Log("claim action get\n")
-		resp, err = f.ClientProxy.GetClaim(context.Background(), newclaim, nil)
+		resp, err = f.backend.GetClaim(context.Background(), newclaim, nil)
 		if err != nil {
 			return nil, err
 		}
 	} else {
 		fn.Log("claim action claim\n")
-		resp, err = f.ClientProxy.Claim(context.Background(), newclaim, nil)
+		resp, err = f.backend.Claim(context.Background(), newclaim, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,311 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/pkg/iputil"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// localPrefix is a prefix of a routing table of a Network resource the local allocator allocates from
+type localPrefix struct {
+	networkInstance string
+	kind            ipamv1alpha1.PrefixKind
+	prefix          netip.Prefix
+	labels          map[string]string
+	// allocated holds the allocated prefixes, the addresses for network and loopback prefixes
+	allocated []netip.Prefix
+}
+
+// localAllocator allocates the ip claims of a package deterministically from the prefixes of the
+// routing tables of the Network resources in the package. A claim whose status prefix belongs to
+// the prefixes keeps it, the other claims are allocated in name order, as such rendering the package
+// again results in the same allocations
+type localAllocator struct {
+	prefixes []*localPrefix
+	claims   map[string]ipamv1alpha1.IPClaimStatus
+	errs     map[string]error
+}
+
+func newLocalAllocator(objs fn.KubeObjects) (Backend, error) {
+	r := &localAllocator{
+		claims: map[string]ipamv1alpha1.IPClaimStatus{},
+		errs:   map[string]error{},
+	}
+	for _, o := range objs.Where(ko.IsGroupVersionKind(infrav1alpha1.NetworkGroupVersionKind)) {
+		network, err := ko.KubeObjectToStruct[infrav1alpha1.Network](o)
+		if err != nil {
+			return nil, err
+		}
+		for _, rt := range network.Spec.RoutingTables {
+			for _, p := range rt.Prefixes {
+				pi, err := netip.ParsePrefix(p.Prefix)
+				if err != nil {
+					return nil, fmt.Errorf("invalid prefix %s in routing table %s of Network %s: %s", p.Prefix, rt.Name, o.GetName(), err.Error())
+				}
+				lp := &localPrefix{
+					networkInstance: rt.Name,
+					kind:            ipamv1alpha1.PrefixKindNetwork,
+					prefix:          pi.Masked(),
+					labels:          p.Labels,
+				}
+				if kind, ok := p.Labels[resourcev1alpha1.NephioPrefixKindKey]; ok {
+					lp.kind = ipamv1alpha1.PrefixKind(kind)
+				}
+				if lp.kind == ipamv1alpha1.PrefixKindNetwork || lp.kind == ipamv1alpha1.PrefixKindLoopback {
+					// the network address is not allocated, for a network the first address is the gateway
+					lp.allocated = append(lp.allocated, netip.PrefixFrom(lp.prefix.Addr(), lp.prefix.Addr().BitLen()))
+					if lp.kind == ipamv1alpha1.PrefixKindNetwork {
+						lp.allocated = append(lp.allocated, netip.PrefixFrom(lp.prefix.Addr().Next(), lp.prefix.Addr().BitLen()))
+					}
+				}
+				r.prefixes = append(r.prefixes, lp)
+			}
+		}
+	}
+
+	claims, err := ko.KubeObjectsToStructs[ipamv1alpha1.IPClaim](objs.Where(fn.IsGroupVersionKind(ipamv1alpha1.IPClaimGroupVersionKind)))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(claims, func(i, j int) bool { return claims[i].GetName() < claims[j].GetName() })
+	// the claims keep the prefix they were allocated before, then the other claims are allocated
+	pending := []ipamv1alpha1.IPClaim{}
+	for _, claim := range claims {
+		if claim.Annotations[staticIPAnnotation] == "true" {
+			continue
+		}
+		if _, ok := claim.Annotations[resourcev1alpha1.NephioAPIAction]; ok {
+			r.getNetworkPrefix(claim)
+			continue
+		}
+		if !r.reserve(claim) {
+			pending = append(pending, claim)
+		}
+	}
+	for _, claim := range pending {
+		r.allocate(claim)
+	}
+	return r, nil
+}
+
+// getPrefixes returns the prefixes of the routing table of the network instance of the claim,
+// matching the kind, the address family and the selector of the claim. A selector label
+// only restricts the prefixes which define the label
+func (r *localAllocator) getPrefixes(claim ipamv1alpha1.IPClaim) []*localPrefix {
+	af := getClaimAddressFamily(&claim)
+	matchLabels := map[string]string{}
+	if claim.Spec.Selector != nil {
+		matchLabels = claim.Spec.Selector.MatchLabels
+	}
+	prefixes := []*localPrefix{}
+	for _, p := range r.prefixes {
+		if p.networkInstance != claim.Spec.NetworkInstance.Name || p.kind != claim.Spec.Kind {
+			continue
+		}
+		if af != "" && af != getAddressFamily(p.prefix) {
+			continue
+		}
+		match := true
+		for k, v := range matchLabels {
+			if l, ok := p.labels[k]; ok && k != resourcev1alpha1.NephioAddressFamilyKey && l != v {
+				match = false
+			}
+		}
+		if match {
+			prefixes = append(prefixes, p)
+		}
+	}
+	return prefixes
+}
+
+// getNetworkPrefix resolves a claim with the get action to the first matching prefix
+func (r *localAllocator) getNetworkPrefix(claim ipamv1alpha1.IPClaim) {
+	prefixes := r.getPrefixes(claim)
+	if len(prefixes) == 0 {
+		r.errs[claim.GetName()] = r.noPrefixError(claim)
+		return
+	}
+	r.claims[claim.GetName()] = ipamv1alpha1.IPClaimStatus{Prefix: pointer.String(prefixes[0].prefix.String())}
+}
+
+// reserve keeps the status prefix of the claim when the prefix belongs to one of its prefixes
+// and is not allocated yet
+func (r *localAllocator) reserve(claim ipamv1alpha1.IPClaim) bool {
+	if claim.Status.Prefix == nil {
+		return false
+	}
+	pi, err := netip.ParsePrefix(*claim.Status.Prefix)
+	if err != nil {
+		return false
+	}
+	for _, p := range r.getPrefixes(claim) {
+		a, ok := p.getAllocation(pi)
+		if !ok || p.isAllocated(a) {
+			continue
+		}
+		p.allocated = append(p.allocated, a)
+		r.claims[claim.GetName()] = p.getStatus(a)
+		return true
+	}
+	return false
+}
+
+// allocate allocates the first available prefix or address of the prefixes of the claim
+func (r *localAllocator) allocate(claim ipamv1alpha1.IPClaim) {
+	prefixes := r.getPrefixes(claim)
+	if len(prefixes) == 0 {
+		r.errs[claim.GetName()] = r.noPrefixError(claim)
+		return
+	}
+	for _, p := range prefixes {
+		length := p.prefix.Addr().BitLen()
+		if claim.Spec.Kind == ipamv1alpha1.PrefixKindPool {
+			if claim.Spec.PrefixLength == nil {
+				r.errs[claim.GetName()] = fmt.Errorf("ip claim %s of kind pool requires a prefixLength", claim.GetName())
+				return
+			}
+			length = int(*claim.Spec.PrefixLength)
+		}
+		if a, ok := p.next(length); ok {
+			p.allocated = append(p.allocated, a)
+			r.claims[claim.GetName()] = p.getStatus(a)
+			return
+		}
+	}
+	r.errs[claim.GetName()] = fmt.Errorf("no %s prefix available in network instance %s for ip claim %s", claim.Spec.Kind, claim.Spec.NetworkInstance.Name, claim.GetName())
+}
+
+func (r *localAllocator) noPrefixError(claim ipamv1alpha1.IPClaim) error {
+	return fmt.Errorf("no %s prefix in network instance %s of the Network resources of the package selected by ip claim %s", claim.Spec.Kind, claim.Spec.NetworkInstance.Name, claim.GetName())
+}
+
+// getAllocation returns the allocation of a status prefix within the prefix
+func (r *localPrefix) getAllocation(pi netip.Prefix) (netip.Prefix, bool) {
+	if r.kind == ipamv1alpha1.PrefixKindPool {
+		return pi, pi.Bits() >= r.prefix.Bits() && r.prefix.Contains(pi.Addr()) && pi.Masked() == pi
+	}
+	return netip.PrefixFrom(pi.Addr(), pi.Addr().BitLen()), r.prefix.Contains(pi.Addr())
+}
+
+// getStatus returns the status of a claim for the allocation, an address of a network prefix
+// has the length of the network prefix and the first address of the network prefix as gateway
+func (r *localPrefix) getStatus(a netip.Prefix) ipamv1alpha1.IPClaimStatus {
+	switch r.kind {
+	case ipamv1alpha1.PrefixKindNetwork:
+		return ipamv1alpha1.IPClaimStatus{
+			Prefix:  pointer.String(netip.PrefixFrom(a.Addr(), r.prefix.Bits()).String()),
+			Gateway: pointer.String(r.prefix.Addr().Next().String()),
+		}
+	default:
+		return ipamv1alpha1.IPClaimStatus{Prefix: pointer.String(a.String())}
+	}
+}
+
+func (r *localPrefix) isAllocated(a netip.Prefix) bool {
+	for _, x := range r.allocated {
+		if x.Overlaps(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// next returns the first prefix of the given length within the prefix that does not overlap
+// with the allocated prefixes
+func (r *localPrefix) next(length int) (netip.Prefix, bool) {
+	if length < r.prefix.Bits() || length > r.prefix.Addr().BitLen() {
+		return netip.Prefix{}, false
+	}
+	c := netip.PrefixFrom(r.prefix.Addr(), length)
+	for r.prefix.Contains(c.Addr()) {
+		overlap := false
+		for _, x := range r.allocated {
+			if !x.Overlaps(c) {
+				continue
+			}
+			overlap = true
+			// continue after the allocated prefix or the candidate, whichever is the larger
+			last := lastAddr(x)
+			if x.Bits() > c.Bits() {
+				last = lastAddr(c)
+			}
+			next := last.Next()
+			if !next.IsValid() {
+				return netip.Prefix{}, false
+			}
+			// the next address is aligned to the length since it follows a prefix of at least the length
+			c = netip.PrefixFrom(next, length)
+			break
+		}
+		if !overlap {
+			return c, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// lastAddr returns the last address of the prefix
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - uint(i%8))
+	}
+	a, _ := netip.AddrFromSlice(b)
+	return a
+}
+
+func getAddressFamily(p netip.Prefix) iputil.AddressFamily {
+	if p.Addr().Is4() {
+		return iputil.AddressFamilyIpv4
+	}
+	return iputil.AddressFamilyIpv6
+}
+
+func (r *localAllocator) GetClaim(ctx context.Context, cr client.Object, d any) (*ipamv1alpha1.IPClaim, error) {
+	return r.getClaim(cr)
+}
+func (r *localAllocator) Claim(ctx context.Context, cr client.Object, d any) (*ipamv1alpha1.IPClaim, error) {
+	return r.getClaim(cr)
+}
+
+func (r *localAllocator) getClaim(cr client.Object) (*ipamv1alpha1.IPClaim, error) {
+	claim, err := getIPClaim(cr)
+	if err != nil {
+		return nil, err
+	}
+	if err, ok := r.errs[claim.GetName()]; ok {
+		return nil, err
+	}
+	status, ok := r.claims[claim.GetName()]
+	if !ok {
+		return nil, fmt.Errorf("ip claim %s not allocated", claim.GetName())
+	}
+	claim.Status = status
+	return claim, nil
+}
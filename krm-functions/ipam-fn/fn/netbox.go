@@ -0,0 +1,290 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/pkg/iputil"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// netboxURLKey is the key in the data of the function config ConfigMap with the url of the Netbox instance
+	netboxURLKey = "netboxURL"
+	// netboxTokenEnv is the environment variable with the api token of the Netbox instance,
+	// the token is not part of the function config since the function config is part of the package
+	netboxTokenEnv = "NETBOX_TOKEN"
+)
+
+// netbox resolves the ip claims from the prefixes of a Netbox instance.
+// The network instance of a claim maps to the VRF with the same name, the prefixes of the
+// VRF with the role of the claim kind (pool, network or loopback) are the prefixes the claim
+// is allocated from. The allocated prefix or ip address gets the name of the claim as
+// description, such that a claim resolves to the same allocation when the package is rendered again
+type netbox struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func newNetbox(fc *fn.KubeObject) (Backend, error) {
+	u, _, _ := fc.NestedString("data", netboxURLKey)
+	if u == "" {
+		return nil, fmt.Errorf("netbox backend requires %s in the function config", netboxURLKey)
+	}
+	token := os.Getenv(netboxTokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("netbox backend requires the api token in the %s environment variable", netboxTokenEnv)
+	}
+	return &netbox{
+		url:    strings.TrimSuffix(u, "/"),
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+type netboxList[T any] struct {
+	Count   int `json:"count"`
+	Results []T `json:"results"`
+}
+
+type netboxObject struct {
+	ID int `json:"id"`
+}
+
+type netboxPrefix struct {
+	ID     int    `json:"id"`
+	Prefix string `json:"prefix"`
+}
+
+type netboxIPAddress struct {
+	ID      int    `json:"id"`
+	Address string `json:"address"`
+}
+
+func (r *netbox) GetClaim(ctx context.Context, cr client.Object, d any) (*ipamv1alpha1.IPClaim, error) {
+	claim, err := getIPClaim(cr)
+	if err != nil {
+		return nil, err
+	}
+	vrf, err := r.getVRF(ctx, claim.Spec.NetworkInstance.Name)
+	if err != nil {
+		return nil, err
+	}
+	prefixes, err := r.getPrefixes(ctx, vrf, claim)
+	if err != nil {
+		return nil, err
+	}
+	claim.Status = ipamv1alpha1.IPClaimStatus{Prefix: pointer.String(prefixes[0].Prefix)}
+	return claim, nil
+}
+
+func (r *netbox) Claim(ctx context.Context, cr client.Object, d any) (*ipamv1alpha1.IPClaim, error) {
+	claim, err := getIPClaim(cr)
+	if err != nil {
+		return nil, err
+	}
+	vrf, err := r.getVRF(ctx, claim.Spec.NetworkInstance.Name)
+	if err != nil {
+		return nil, err
+	}
+	if claim.Spec.Kind == ipamv1alpha1.PrefixKindPool {
+		return r.claimPrefix(ctx, vrf, claim)
+	}
+	return r.claimIPAddress(ctx, vrf, claim)
+}
+
+// claimPrefix returns the prefix allocated to the claim before or allocates an available prefix
+// of the prefix length of the claim from the pool prefixes
+func (r *netbox) claimPrefix(ctx context.Context, vrf int, claim *ipamv1alpha1.IPClaim) (*ipamv1alpha1.IPClaim, error) {
+	if claim.Spec.PrefixLength == nil {
+		return nil, fmt.Errorf("ip claim %s of kind pool requires a prefixLength", claim.GetName())
+	}
+	existing := netboxList[netboxPrefix]{}
+	if err := r.do(ctx, http.MethodGet, "/api/ipam/prefixes/", url.Values{"vrf_id": {fmt.Sprint(vrf)}, "description": {claim.GetName()}}, nil, &existing); err != nil {
+		return nil, err
+	}
+	if len(existing.Results) > 0 {
+		claim.Status = ipamv1alpha1.IPClaimStatus{Prefix: pointer.String(existing.Results[0].Prefix)}
+		return claim, nil
+	}
+	prefixes, err := r.getPrefixes(ctx, vrf, claim)
+	if err != nil {
+		return nil, err
+	}
+	req := map[string]any{"prefix_length": *claim.Spec.PrefixLength, "vrf": vrf, "description": claim.GetName()}
+	for _, p := range prefixes {
+		allocated := netboxPrefix{}
+		if err := r.do(ctx, http.MethodPost, fmt.Sprintf("/api/ipam/prefixes/%d/available-prefixes/", p.ID), nil, req, &allocated); err != nil {
+			// the prefix has no space left, try the next prefix
+			fn.Logf("netbox prefix %s, err: %s\n", p.Prefix, err.Error())
+			continue
+		}
+		claim.Status = ipamv1alpha1.IPClaimStatus{Prefix: pointer.String(allocated.Prefix)}
+		return claim, nil
+	}
+	return nil, fmt.Errorf("no pool prefix available in netbox vrf %s for ip claim %s", claim.Spec.NetworkInstance.Name, claim.GetName())
+}
+
+// claimIPAddress returns the ip address allocated to the claim before or allocates an available ip address
+// from the network or loopback prefixes. The first address of a network prefix is the gateway
+func (r *netbox) claimIPAddress(ctx context.Context, vrf int, claim *ipamv1alpha1.IPClaim) (*ipamv1alpha1.IPClaim, error) {
+	existing := netboxList[netboxIPAddress]{}
+	if err := r.do(ctx, http.MethodGet, "/api/ipam/ip-addresses/", url.Values{"vrf_id": {fmt.Sprint(vrf)}, "description": {claim.GetName()}}, nil, &existing); err != nil {
+		return nil, err
+	}
+	address := ""
+	if len(existing.Results) > 0 {
+		address = existing.Results[0].Address
+	} else {
+		prefixes, err := r.getPrefixes(ctx, vrf, claim)
+		if err != nil {
+			return nil, err
+		}
+		req := map[string]any{"vrf": vrf, "description": claim.GetName()}
+		for _, p := range prefixes {
+			allocated := netboxIPAddress{}
+			if err := r.do(ctx, http.MethodPost, fmt.Sprintf("/api/ipam/prefixes/%d/available-ips/", p.ID), nil, req, &allocated); err != nil {
+				// the prefix has no address left, try the next prefix
+				fn.Logf("netbox prefix %s, err: %s\n", p.Prefix, err.Error())
+				continue
+			}
+			address = allocated.Address
+			break
+		}
+		if address == "" {
+			return nil, fmt.Errorf("no %s address available in netbox vrf %s for ip claim %s", claim.Spec.Kind, claim.Spec.NetworkInstance.Name, claim.GetName())
+		}
+	}
+	pi, err := netip.ParsePrefix(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid netbox ip address %s for ip claim %s: %s", address, claim.GetName(), err.Error())
+	}
+	if claim.Spec.Kind == ipamv1alpha1.PrefixKindLoopback {
+		claim.Status = ipamv1alpha1.IPClaimStatus{Prefix: pointer.String(netip.PrefixFrom(pi.Addr(), pi.Addr().BitLen()).String())}
+		return claim, nil
+	}
+	claim.Status = ipamv1alpha1.IPClaimStatus{
+		Prefix:  pointer.String(pi.String()),
+		Gateway: pointer.String(pi.Masked().Addr().Next().String()),
+	}
+	return claim, nil
+}
+
+// getVRF returns the id of the vrf of the network instance
+func (r *netbox) getVRF(ctx context.Context, networkInstance string) (int, error) {
+	vrfs := netboxList[netboxObject]{}
+	if err := r.do(ctx, http.MethodGet, "/api/ipam/vrfs/", url.Values{"name": {networkInstance}}, nil, &vrfs); err != nil {
+		return 0, err
+	}
+	if len(vrfs.Results) == 0 {
+		return 0, fmt.Errorf("no netbox vrf for network instance %s", networkInstance)
+	}
+	return vrfs.Results[0].ID, nil
+}
+
+// getPrefixes returns the prefixes of the vrf with the role of the claim kind and the address family of the claim
+func (r *netbox) getPrefixes(ctx context.Context, vrf int, claim *ipamv1alpha1.IPClaim) ([]netboxPrefix, error) {
+	query := url.Values{"vrf_id": {fmt.Sprint(vrf)}, "role": {string(claim.Spec.Kind)}}
+	switch getClaimAddressFamily(claim) {
+	case iputil.AddressFamilyIpv4:
+		query.Set("family", "4")
+	case iputil.AddressFamilyIpv6:
+		query.Set("family", "6")
+	}
+	prefixes := netboxList[netboxPrefix]{}
+	if err := r.do(ctx, http.MethodGet, "/api/ipam/prefixes/", query, nil, &prefixes); err != nil {
+		return nil, err
+	}
+	if len(prefixes.Results) == 0 {
+		return nil, fmt.Errorf("no %s prefix in netbox vrf %s for ip claim %s", claim.Spec.Kind, claim.Spec.NetworkInstance.Name, claim.GetName())
+	}
+	return prefixes.Results, nil
+}
+
+// do performs a request to the netbox api and decodes the response in resp
+func (r *netbox) do(ctx context.Context, method, path string, query url.Values, body, resp any) error {
+	u := r.url + path
+	if len(query) > 0 {
+		u = u + "?" + query.Encode()
+	}
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+r.token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	res, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("netbox %s %s failed, status: %s, body: %s", method, path, res.Status, strings.TrimSpace(string(b)))
+	}
+	return json.Unmarshal(b, resp)
+}
+
+func getIPClaim(cr client.Object) (*ipamv1alpha1.IPClaim, error) {
+	claim, ok := cr.(*ipamv1alpha1.IPClaim)
+	if !ok {
+		return nil, fmt.Errorf("expecting IPClaim, got: %v", reflect.TypeOf(cr))
+	}
+	return claim, nil
+}
+
+// getClaimAddressFamily returns the address family of the claim, from the spec or the selector of the claim
+func getClaimAddressFamily(claim *ipamv1alpha1.IPClaim) iputil.AddressFamily {
+	if claim.Spec.AddressFamily != nil {
+		return *claim.Spec.AddressFamily
+	}
+	if claim.Spec.Selector != nil {
+		if af, ok := claim.Spec.Selector.MatchLabels[resourcev1alpha1.NephioAddressFamilyKey]; ok {
+			return iputil.AddressFamily(af)
+		}
+	}
+	return ""
+}
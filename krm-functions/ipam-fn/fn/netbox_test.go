@@ -0,0 +1,193 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+// newFakeNetbox returns a netbox api serving the vrf vpc-internet with a pool and a network prefix,
+// the ip address of the claim upf-cluster01-n4 is allocated already
+func newFakeNetbox(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Token secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		q := req.URL.Query()
+		var resp any
+		switch {
+		case req.URL.Path == "/api/ipam/vrfs/" && q.Get("name") == "vpc-internet":
+			resp = netboxList[netboxObject]{Count: 1, Results: []netboxObject{{ID: 1}}}
+		case req.URL.Path == "/api/ipam/vrfs/":
+			resp = netboxList[netboxObject]{}
+		case req.URL.Path == "/api/ipam/prefixes/" && q.Get("description") != "":
+			resp = netboxList[netboxPrefix]{}
+		case req.URL.Path == "/api/ipam/prefixes/" && q.Get("role") == "pool" && q.Get("family") == "4":
+			resp = netboxList[netboxPrefix]{Count: 1, Results: []netboxPrefix{{ID: 10, Prefix: "10.0.0.0/8"}}}
+		case req.URL.Path == "/api/ipam/prefixes/" && q.Get("role") == "network" && q.Get("family") == "4":
+			resp = netboxList[netboxPrefix]{Count: 1, Results: []netboxPrefix{{ID: 20, Prefix: "172.0.0.0/16"}}}
+		case req.URL.Path == "/api/ipam/prefixes/":
+			resp = netboxList[netboxPrefix]{}
+		case req.URL.Path == "/api/ipam/prefixes/10/available-prefixes/" && req.Method == http.MethodPost:
+			body := map[string]any{}
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body["prefix_length"] != float64(16) {
+				t.Errorf("unexpected available-prefixes request: %v, err: %v", body, err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			resp = netboxPrefix{ID: 11, Prefix: "10.0.0.0/16"}
+		case req.URL.Path == "/api/ipam/prefixes/20/available-ips/" && req.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			resp = netboxIPAddress{ID: 21, Address: "172.0.0.3/16"}
+		case req.URL.Path == "/api/ipam/ip-addresses/" && q.Get("description") == "upf-cluster01-n4":
+			resp = netboxList[netboxIPAddress]{Count: 1, Results: []netboxIPAddress{{ID: 22, Address: "172.0.0.2/16"}}}
+		case req.URL.Path == "/api/ipam/ip-addresses/":
+			resp = netboxList[netboxIPAddress]{}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func newTestClaim(name string, kind ipamv1alpha1.PrefixKind, ni string, prefixLength *uint8) *ipamv1alpha1.IPClaim {
+	return &ipamv1alpha1.IPClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: ipamv1alpha1.IPClaimSpec{
+			Kind:            kind,
+			NetworkInstance: corev1.ObjectReference{Name: ni},
+			PrefixLength:    prefixLength,
+			ClaimLabels: resourcev1alpha1.ClaimLabels{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{resourcev1alpha1.NephioAddressFamilyKey: "ipv4"},
+				},
+			},
+		},
+	}
+}
+
+func TestNetboxClaim(t *testing.T) {
+	poolLength := uint8(16)
+	s := newFakeNetbox(t)
+	defer s.Close()
+	t.Setenv(netboxTokenEnv, "secret")
+	fc, err := fn.ParseKubeObject([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: ipam-fn-config\ndata:\n  backend: netbox\n  netboxURL: " + s.URL + "/\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := New(nil)
+	b, err := f.getBackend(&fn.ResourceList{FunctionConfig: fc})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		claim   *ipamv1alpha1.IPClaim
+		prefix  string
+		gateway string
+		wantErr bool
+	}{
+		"Pool": {
+			claim:  newTestClaim("upf-cluster01-internet-pool1", ipamv1alpha1.PrefixKindPool, "vpc-internet", &poolLength),
+			prefix: "10.0.0.0/16",
+		},
+		"Network": {
+			claim:   newTestClaim("upf-cluster01-n6", ipamv1alpha1.PrefixKindNetwork, "vpc-internet", nil),
+			prefix:  "172.0.0.3/16",
+			gateway: "172.0.0.1",
+		},
+		"NetworkAllocated": {
+			claim:   newTestClaim("upf-cluster01-n4", ipamv1alpha1.PrefixKindNetwork, "vpc-internet", nil),
+			prefix:  "172.0.0.2/16",
+			gateway: "172.0.0.1",
+		},
+		"PoolWithoutPrefixLength": {
+			claim:   newTestClaim("upf-cluster01-internet-pool2", ipamv1alpha1.PrefixKindPool, "vpc-internet", nil),
+			wantErr: true,
+		},
+		"LoopbackWithoutPrefix": {
+			claim:   newTestClaim("upf-cluster01-lo", ipamv1alpha1.PrefixKindLoopback, "vpc-internet", nil),
+			wantErr: true,
+		},
+		"MissingVRF": {
+			claim:   newTestClaim("upf-cluster01-n3", ipamv1alpha1.PrefixKindNetwork, "vpc-ran", nil),
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			claim, err := b.Claim(context.Background(), tc.claim, nil)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got status %v", claim.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pointer.StringDeref(claim.Status.Prefix, ""); got != tc.prefix {
+				t.Errorf("prefix: want %s, got %s", tc.prefix, got)
+			}
+			if got := pointer.StringDeref(claim.Status.Gateway, ""); got != tc.gateway {
+				t.Errorf("gateway: want %s, got %s", tc.gateway, got)
+			}
+		})
+	}
+}
+
+func TestGetBackend(t *testing.T) {
+	tests := map[string]struct {
+		data    string
+		env     string
+		wantErr bool
+	}{
+		"Default":          {data: "{}"},
+		"Offline":          {data: "{backend: offline}"},
+		"Netbox":           {data: "{backend: netbox, netboxURL: http://netbox}", env: "secret"},
+		"NetboxWithoutURL": {data: "{backend: netbox}", env: "secret", wantErr: true},
+		"NetboxWithoutEnv": {data: "{backend: netbox, netboxURL: http://netbox}", wantErr: true},
+		"Unsupported":      {data: "{backend: infoblox}", wantErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(netboxTokenEnv, tc.env)
+			fc, err := fn.ParseKubeObject([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: ipam-fn-config\ndata: " + tc.data + "\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = New(nil).getBackend(&fn.ResourceList{FunctionConfig: fc})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %t, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -16,9 +16,14 @@ limitations under the License.
 package condkptsdk
 
 import (
+	"strings"
+	"text/template"
+
 	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
 	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
 	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	"github.com/nephio-project/nephio/krm-functions/lib/ref"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -31,6 +36,128 @@ const (
 	ConditionReasonSpecialize ConditionReason = "Specialize"
 )
 
+// Reasons a fn/controller sets in its own conditions, such that the approval controller and
+// UIs can parse why a specialization is blocked
+const (
+	// ConditionReasonGenerated indicates the fn/controller generated the resource
+	ConditionReasonGenerated ConditionReason = "Generated"
+	// ConditionReasonGenerationFailed indicates the fn/controller failed generating the resource
+	ConditionReasonGenerationFailed ConditionReason = "GenerationFailed"
+	// ConditionReasonWaitingForDependency indicates a resource the fn/controller depends on is not ready
+	ConditionReasonWaitingForDependency ConditionReason = "WaitingForDependency"
+	// ConditionReasonMissingResource indicates a resource the fn/controller depends on is missing from the package
+	ConditionReasonMissingResource ConditionReason = "MissingResource"
+	// ConditionReasonInvalidResource indicates a resource the fn/controller depends on is invalid
+	ConditionReasonInvalidResource ConditionReason = "InvalidResource"
+)
+
+// IsBlocking returns true if the reason blocks the specialization
+func (r ConditionReason) IsBlocking() bool {
+	switch r {
+	case ConditionReasonFailed, ConditionReasonGenerationFailed, ConditionReasonWaitingForDependency,
+		ConditionReasonMissingResource, ConditionReasonInvalidResource:
+		return true
+	}
+	return false
+}
+
+// ConditionMessageData is the data the message template of a condition is rendered with
+type ConditionMessageData struct {
+	// For is the name of the for resource
+	For string
+	// ForKind is the kind of the for resource
+	ForKind string
+	// Owner is the name of the owner of the for resource
+	Owner string
+	// OwnerKind is the kind of the owner of the for resource
+	OwnerKind string
+	// Detail is the detail of the condition, e.g. the error or the missing status fields
+	Detail string
+}
+
+// NewConditionMessageData returns the message data of the for resource; the owner is
+// resolved from the owner annotation of the for resource
+func NewConditionMessageData(forObj *fn.KubeObject) ConditionMessageData {
+	if forObj == nil {
+		return ConditionMessageData{}
+	}
+	data := ConditionMessageData{
+		For:     forObj.GetName(),
+		ForKind: forObj.GetKind(),
+	}
+	if owner := forObj.GetAnnotation(SpecializerOwner); owner != "" {
+		ownerRef := kptfilelibv1.GetGVKNFromConditionType(owner)
+		data.Owner = ownerRef.Name
+		data.OwnerKind = ownerRef.Kind
+	}
+	return data
+}
+
+// newConditionMessageDataFromRef returns the message data of the for reference; the owner is
+// resolved from the reason of the for condition
+func newConditionMessageDataFromRef(forRef corev1.ObjectReference, forCondition *kptv1.Condition, detail string) ConditionMessageData {
+	data := ConditionMessageData{
+		For:     forRef.Name,
+		ForKind: forRef.Kind,
+		Detail:  detail,
+	}
+	if ownerRef := getConditionOwner(forCondition); ownerRef != nil {
+		data.Owner = ownerRef.Name
+		data.OwnerKind = ownerRef.Kind
+	}
+	return data
+}
+
+// getConditionOwner returns the owner the reason of a for condition holds, nil when the reason
+// is not an owner reference
+func getConditionOwner(forCondition *kptv1.Condition) *corev1.ObjectReference {
+	if forCondition == nil {
+		return nil
+	}
+	ownerRef := kptfilelibv1.GetGVKNFromConditionType(forCondition.Reason)
+	if ref.ValidateGVKNRef(*ownerRef) != nil {
+		return nil
+	}
+	return ownerRef
+}
+
+// GetErrorReason returns the standardized reason of an error of the fn/controller, based on the
+// code of the error when it is a coded error of the results library
+func GetErrorReason(err error) ConditionReason {
+	e, ok := results.AsError(err)
+	if !ok {
+		return ConditionReasonGenerationFailed
+	}
+	switch e.Code {
+	case results.CodeMissingResource:
+		return ConditionReasonMissingResource
+	case results.CodeInvalidInput, results.CodeDuplicateResource, results.CodeUnsupportedByCluster:
+		return ConditionReasonInvalidResource
+	case results.CodeWaitingForStatus:
+		return ConditionReasonWaitingForDependency
+	}
+	return ConditionReasonGenerationFailed
+}
+
+// NewCondition returns a condition with a standardized reason and a human-readable message
+// rendered from the message template with the data, e.g. "nad {{.For}} of {{.OwnerKind}} {{.Owner}} generated"
+func NewCondition(ct string, status kptv1.ConditionStatus, reason ConditionReason, msgTemplate string, data ConditionMessageData) (kptv1.Condition, error) {
+	tmpl, err := template.New(ct).Option("missingkey=error").Parse(msgTemplate)
+	if err != nil {
+		return kptv1.Condition{}, err
+	}
+	var msg strings.Builder
+	if err := tmpl.Execute(&msg, data); err != nil {
+		return kptv1.Condition{}, err
+	}
+	return kptv1.Condition{
+		Type:    ct,
+		Status:  status,
+		Reason:  string(reason),
+		Message: msg.String(),
+	}, nil
+}
+
 func getSpecializationConditionType() string {
 	return kptfilelibv1.GetConditionType(&corev1.ObjectReference{
 		APIVersion: "nephio.org",
@@ -79,10 +206,37 @@ func ready() kptv1.Condition {
 	}
 }
 
+// setForCondition sets the condition of the for resource with the standardized reason and the message
+// rendered from the template; the reason of a for condition holding its owner is kept, since the
+// owner relies on it to track the resources it requested
+func (r *sdk) setForCondition(forRef corev1.ObjectReference, forCondition *kptv1.Condition, status kptv1.ConditionStatus, reason ConditionReason, msgTemplate, detail string) error {
+	c, err := NewCondition(kptfilelibv1.GetConditionType(&forRef), status, reason, msgTemplate, newConditionMessageDataFromRef(forRef, forCondition, detail))
+	if err != nil {
+		return err
+	}
+	if getConditionOwner(forCondition) != nil {
+		c.Reason = forCondition.Reason
+	}
+	r.traceCondition(c)
+	return r.conditions.SetConditions(c)
+}
+
 func (r *sdk) failForConditions(msg string) {
-	forObjs := r.rl.Items.Where(fn.IsGroupVersionKind(r.cfg.For.GroupVersionKind()))
-	for _, forObj := range forObjs {
-		if err := r.kptfile.SetConditionRefFailed(corev1.ObjectReference{APIVersion: forObj.GetAPIVersion(), Kind: forObj.GetKind(), Name: forObj.GetName()}, msg); err != nil {
+	if r.cfg.GenerateFor {
+		// the generated for resources are named by the fn/controller, hence the for conditions are failed
+		for _, c := range r.conditions.GetConditions() {
+			objRef := kptfilelibv1.GetGVKNFromConditionType(c.Type)
+			if kindCtx, ok := r.inv.isGVKMatch(objRef); ok && kindCtx.gvkKind == forGVKKind && !r.isGeneratedCondition(c) {
+				if err := r.conditions.SetConditionRefFailed(*objRef, msg); err != nil {
+					fn.Logf("set fail for condition failed, err: %s\n", err.Error())
+					r.rl.Results.ErrorE(err)
+				}
+			}
+		}
+		return
+	}
+	for _, forObj := range r.getForObjects() {
+		if err := r.conditions.SetConditionRefFailed(corev1.ObjectReference{APIVersion: forObj.GetAPIVersion(), Kind: forObj.GetKind(), Name: forObj.GetName()}, msg); err != nil {
 			fn.Logf("set fail for condition failed, err: %s\n", err.Error())
 			r.rl.Results.ErrorE(err)
 		}
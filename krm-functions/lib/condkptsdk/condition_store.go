@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ConditionStore stores the conditions the sdk maintains for the resources of the package
+// The Kptfile of the package is the default store
+type ConditionStore interface {
+	// GetConditions returns a copy of the conditions in the store
+	GetConditions() []kptv1.Condition
+	// GetCondition returns the condition with the condition type, nil when not present
+	GetCondition(ct string) *kptv1.Condition
+	// SetConditions upserts the conditions in the store
+	SetConditions(cs ...kptv1.Condition) error
+	// DeleteCondition deletes the condition with the condition type from the store
+	DeleteCondition(ct string) error
+	// SetConditionRefFailed sets the condition of the reference to false with the message
+	SetConditionRefFailed(ref corev1.ObjectReference, msg string) error
+	// IsReady returns true when conditions with the condition type prefix exist and none of them is false
+	IsReady(ctPrefix string) bool
+}
+
+// NewConditionStoreFn returns the condition store of the resourceList
+type NewConditionStoreFn func(rl *fn.ResourceList) (ConditionStore, error)
+
+// NewStatusConditionStore returns a NewConditionStoreFn that stores the conditions in the status of
+// the resource with the reference, e.g. a dedicated status resource or the PackageVariant of the package,
+// which allows pipelines that don't allow the Kptfile to be mutated. The resource is added to the
+// package as local config when it is missing
+func NewStatusConditionStore(objRef corev1.ObjectReference) NewConditionStoreFn {
+	return func(rl *fn.ResourceList) (ConditionStore, error) {
+		if objRef.APIVersion == "" || objRef.Kind == "" || objRef.Name == "" {
+			return nil, fmt.Errorf("the condition store needs an apiVersion, kind and name, got: %v", objRef)
+		}
+		for _, o := range rl.Items.Where(fn.IsGroupVersionKind(objRef.GroupVersionKind())) {
+			if o.GetName() == objRef.Name {
+				return &kptfilelibv1.KptFile{Kptfile: o}, nil
+			}
+		}
+		o := fn.NewEmptyKubeObject()
+		if err := o.SetAPIVersion(objRef.APIVersion); err != nil {
+			return nil, err
+		}
+		if err := o.SetKind(objRef.Kind); err != nil {
+			return nil, err
+		}
+		if err := o.SetName(objRef.Name); err != nil {
+			return nil, err
+		}
+		if objRef.Namespace != "" {
+			if err := o.SetNamespace(objRef.Namespace); err != nil {
+				return nil, err
+			}
+		}
+		if err := o.SetAnnotation("config.kubernetes.io/local-config", "true"); err != nil {
+			return nil, err
+		}
+		rl.Items = append(rl.Items, o)
+		return &kptfilelibv1.KptFile{Kptfile: o}, nil
+	}
+}
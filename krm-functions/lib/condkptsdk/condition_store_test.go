@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestStatusConditionStore(t *testing.T) {
+	statusRef := corev1.ObjectReference{APIVersion: "specializer.nephio.org/v1alpha1", Kind: "SpecializerStatus", Name: "status"}
+	cases := map[string]struct {
+		status string
+	}{
+		"NewStatus": {},
+		"ExistingStatus": {
+			status: `
+- apiVersion: specializer.nephio.org/v1alpha1
+  kind: SpecializerStatus
+  metadata:
+    name: status
+  status:
+    conditions:
+    - type: a.nephio.org/v1.A.x
+      status: "False"
+      reason: o.nephio.org/v1.O.x
+`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+- apiVersion: a.nephio.org/v1
+  kind: A
+  metadata:
+    name: x
+- apiVersion: b.nephio.org/v1
+  kind: B
+  metadata:
+    name: b1
+` + tc.status))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "b.nephio.org/v1", Kind: "B"}: func(*fn.KubeObject) error { return nil },
+				},
+				UpdateResourceFn: func(forObj *fn.KubeObject, _ fn.KubeObjects) (fn.KubeObjects, error) {
+					return fn.KubeObjects{forObj}, nil
+				},
+				ConditionStore: NewStatusConditionStore(statusRef),
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			// the Kptfile is not mutated
+			kf := kptfilelibv1.KptFile{Kptfile: rl.Items.GetRootKptfile()}
+			assert.Equal(t, 0, len(kf.GetConditions()))
+
+			statusObjs := rl.Items.Where(fn.IsGroupVersionKind(statusRef.GroupVersionKind()))
+			if !assert.Equal(t, 1, statusObjs.Len()) {
+				return
+			}
+			status := kptfilelibv1.KptFile{Kptfile: statusObjs[0]}
+			c := status.GetCondition("a.nephio.org/v1.A.x")
+			if assert.NotNil(t, c) {
+				assert.Equal(t, kptv1.ConditionTrue, c.Status)
+			}
+		})
+	}
+}
+
+func TestStatusConditionStoreInvalidRef(t *testing.T) {
+	_, err := NewStatusConditionStore(corev1.ObjectReference{Kind: "SpecializerStatus"})(&fn.ResourceList{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,198 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/google/go-cmp/cmp"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewConditionMessageData(t *testing.T) {
+	cases := map[string]struct {
+		input    string
+		expected ConditionMessageData
+	}{
+		"WithOwner": {
+			input: `apiVersion: k8s.cni.cncf.io/v1
+kind: NetworkAttachmentDefinition
+metadata:
+  name: upf-n3
+  annotations:
+    specializer.nephio.org/owner: req.nephio.org/v1alpha1.Interface.n3
+`,
+			expected: ConditionMessageData{For: "upf-n3", ForKind: "NetworkAttachmentDefinition", Owner: "n3", OwnerKind: "Interface"},
+		},
+		"WithoutOwner": {
+			input: `apiVersion: k8s.cni.cncf.io/v1
+kind: NetworkAttachmentDefinition
+metadata:
+  name: upf-n3
+`,
+			expected: ConditionMessageData{For: "upf-n3", ForKind: "NetworkAttachmentDefinition"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o, err := fn.ParseKubeObject([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("cannot parse object: %s", err.Error())
+			}
+			if diff := cmp.Diff(tc.expected, NewConditionMessageData(o)); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+	assert.Equal(t, ConditionMessageData{}, NewConditionMessageData(nil))
+}
+
+func TestNewCondition(t *testing.T) {
+	data := ConditionMessageData{For: "upf-n3", ForKind: "NetworkAttachmentDefinition", Owner: "n3", OwnerKind: "Interface"}
+	cases := map[string]struct {
+		reason      ConditionReason
+		template    string
+		expected    kptv1.Condition
+		errExpected bool
+	}{
+		"Generated": {
+			reason:   ConditionReasonGenerated,
+			template: "nad {{.For}} of {{.OwnerKind}} {{.Owner}} generated",
+			expected: kptv1.Condition{Type: "a", Status: kptv1.ConditionTrue, Reason: "Generated", Message: "nad upf-n3 of Interface n3 generated"},
+		},
+		"PlainMessage": {
+			reason:   ConditionReasonMissingResource,
+			template: "workload cluster is missing",
+			expected: kptv1.Condition{Type: "a", Status: kptv1.ConditionTrue, Reason: "MissingResource", Message: "workload cluster is missing"},
+		},
+		"InvalidTemplate": {
+			reason:      ConditionReasonGenerated,
+			template:    "nad {{.For",
+			errExpected: true,
+		},
+		"UnknownField": {
+			reason:      ConditionReasonGenerated,
+			template:    "nad {{.Name}}",
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewCondition("a", kptv1.ConditionTrue, tc.reason, tc.template, data)
+			if tc.errExpected {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tc.expected, c); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConditionReasonIsBlocking(t *testing.T) {
+	cases := map[ConditionReason]bool{
+		ConditionReasonReady:                false,
+		ConditionReasonSpecialize:           false,
+		ConditionReasonGenerated:            false,
+		ConditionReasonFailed:               true,
+		ConditionReasonGenerationFailed:     true,
+		ConditionReasonWaitingForDependency: true,
+		ConditionReasonMissingResource:      true,
+		ConditionReasonInvalidResource:      true,
+	}
+	for reason, expected := range cases {
+		assert.Equal(t, expected, reason.IsBlocking(), string(reason))
+	}
+}
+
+func TestGetErrorReason(t *testing.T) {
+	cases := map[string]struct {
+		err      error
+		expected ConditionReason
+	}{
+		"PlainError": {
+			err:      fmt.Errorf("cannot generate"),
+			expected: ConditionReasonGenerationFailed,
+		},
+		"MissingResource": {
+			err:      results.Errorf(results.CodeMissingResource, "workload cluster is missing"),
+			expected: ConditionReasonMissingResource,
+		},
+		"InvalidInput": {
+			err:      results.Errorf(results.CodeInvalidInput, "invalid vlan range"),
+			expected: ConditionReasonInvalidResource,
+		},
+		"UnsupportedByCluster": {
+			err:      results.Errorf(results.CodeUnsupportedByCluster, "cniType not supported"),
+			expected: ConditionReasonInvalidResource,
+		},
+		"Internal": {
+			err:      results.Errorf(results.CodeInternal, "internal"),
+			expected: ConditionReasonGenerationFailed,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, GetErrorReason(tc.err))
+		})
+	}
+}
+
+func TestSetForCondition(t *testing.T) {
+	forRef := corev1.ObjectReference{APIVersion: "k8s.cni.cncf.io/v1", Kind: "NetworkAttachmentDefinition", Name: "n3"}
+	cases := map[string]struct {
+		forCondition *kptv1.Condition
+		expected     kptv1.Condition
+	}{
+		"WithoutCondition": {
+			expected: kptv1.Condition{Type: "k8s.cni.cncf.io/v1.NetworkAttachmentDefinition.n3", Status: kptv1.ConditionFalse, Reason: "WaitingForDependency", Message: "NetworkAttachmentDefinition n3 waits for status.prefix"},
+		},
+		"OwnerKept": {
+			forCondition: &kptv1.Condition{Type: "k8s.cni.cncf.io/v1.NetworkAttachmentDefinition.n3", Reason: "req.nephio.org/v1alpha1.Interface.n3"},
+			expected:     kptv1.Condition{Type: "k8s.cni.cncf.io/v1.NetworkAttachmentDefinition.n3", Status: kptv1.ConditionFalse, Reason: "req.nephio.org/v1alpha1.Interface.n3", Message: "NetworkAttachmentDefinition n3 waits for status.prefix"},
+		},
+		"ReasonReplaced": {
+			forCondition: &kptv1.Condition{Type: "k8s.cni.cncf.io/v1.NetworkAttachmentDefinition.n3", Reason: "Generated"},
+			expected:     kptv1.Condition{Type: "k8s.cni.cncf.io/v1.NetworkAttachmentDefinition.n3", Status: kptv1.ConditionFalse, Reason: "WaitingForDependency", Message: "NetworkAttachmentDefinition n3 waits for status.prefix"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kf, err := fn.ParseKubeObject([]byte("apiVersion: kpt.dev/v1\nkind: Kptfile\nmetadata:\n  name: pkg\n"))
+			if err != nil {
+				t.Fatalf("cannot parse kptfile: %s", err.Error())
+			}
+			r := &sdk{kptfile: kptfilelibv1.KptFile{Kptfile: kf}}
+			r.conditions = &r.kptfile
+			err = r.setForCondition(forRef, tc.forCondition, kptv1.ConditionFalse, ConditionReasonWaitingForDependency, "{{.ForKind}} {{.For}} waits for {{.Detail}}", "status.prefix")
+			assert.NoError(t, err)
+			if diff := cmp.Diff(&tc.expected, r.kptfile.GetCondition(tc.expected.Type)); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package condkptsdk
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/nephio-project/nephio/krm-functions/lib/ref"
@@ -80,14 +81,19 @@ type inv struct {
 // used to provide faster lookup if the GVK is relevant for the fn/controller
 // and to provide context if there is a match
 func (r *inv) initializeGVKInventory(cfg *Config) error {
-	if err := ref.ValidateGVKRef(cfg.For); err != nil {
-		return err
+	if len(cfg.For) == 0 {
+		return fmt.Errorf("a function always needs a for reference")
 	}
-	if ref.IsWildCardRef(cfg.For) {
-		return fmt.Errorf("no wildcard refs allowed in for reference")
-	}
-	if err := r.addGVKObjectReference(&gvkKindCtx{gvkKind: forGVKKind}, cfg.For); err != nil {
-		return err
+	for _, forRef := range cfg.For {
+		if err := ref.ValidateGVKRef(forRef); err != nil {
+			return err
+		}
+		if ref.IsWildCardRef(forRef) {
+			return fmt.Errorf("no wildcard refs allowed in for reference")
+		}
+		if err := r.addGVKObjectReference(&gvkKindCtx{gvkKind: forGVKKind}, forRef); err != nil {
+			return err
+		}
 	}
 	for objRef, rk := range cfg.Owns {
 		if err := ref.ValidateGVKRef(objRef); err != nil {
@@ -109,10 +115,56 @@ func (r *inv) initializeGVKInventory(cfg *Config) error {
 		if ref.IsWildCardRef(objRef) {
 			return fmt.Errorf("no wildcard refs allowed in watch resource reference")
 		}
-		if err := r.addGVKObjectReference(&gvkKindCtx{gvkKind: watchGVKKind, callbackFn: cb}, objRef); err != nil {
+		var selector *watchSelector
+		if s, ok := cfg.WatchSelectors[objRef]; ok {
+			var err error
+			if selector, err = newWatchSelector(s); err != nil {
+				return fmt.Errorf("invalid watch selector for %s, err: %s", ref.GetRefsString(objRef), err.Error())
+			}
+		}
+		var schema *watchSchema
+		if s, ok := cfg.WatchSchemas[objRef]; ok {
+			var err error
+			if schema, err = newWatchSchema(s); err != nil {
+				return fmt.Errorf("invalid watch schema for %s, err: %s", ref.GetRefsString(objRef), err.Error())
+			}
+		}
+		if err := r.addGVKObjectReference(&gvkKindCtx{gvkKind: watchGVKKind, callbackFn: cb, selector: selector, schema: schema}, objRef); err != nil {
 			return err
 		}
 	}
+	for objRef, s := range cfg.WatchSelectors {
+		if _, ok := cfg.Watch[objRef]; !ok {
+			return fmt.Errorf("watch selector for %s without watch resource reference", ref.GetRefsString(objRef))
+		}
+		if s.ReferencedBy != nil {
+			if _, ok := cfg.Watch[s.ReferencedBy.Ref]; !ok {
+				return fmt.Errorf("watch selector for %s referenced by %s without watch resource reference", ref.GetRefsString(objRef), ref.GetRefsString(s.ReferencedBy.Ref))
+			}
+			if s.ReferencedBy.Annotation == "" {
+				return fmt.Errorf("watch selector for %s referenced by %s without annotation", ref.GetRefsString(objRef), ref.GetRefsString(s.ReferencedBy.Ref))
+			}
+		}
+	}
+	for objRef := range cfg.WatchSchemas {
+		if _, ok := cfg.Watch[objRef]; !ok {
+			return fmt.Errorf("watch schema for %s without watch resource reference", ref.GetRefsString(objRef))
+		}
+	}
+	for objRef, fields := range cfg.RequiredStatus {
+		_, isWatch := cfg.Watch[objRef]
+		_, isOwn := cfg.Owns[objRef]
+		if !isWatch && !isOwn {
+			return fmt.Errorf("required status for %s without own or watch resource reference", ref.GetRefsString(objRef))
+		}
+		for _, field := range fields {
+			for _, f := range strings.Split(field, "|") {
+				if f == "" {
+					return fmt.Errorf("required status for %s with an empty field", ref.GetRefsString(objRef))
+				}
+			}
+		}
+	}
 	if cfg.UpdateResourceFn == nil {
 		return fmt.Errorf("a function always needs a GenerateResource function")
 	}
@@ -28,7 +28,7 @@ import (
 
 func TestDiffWithSameSpec(t *testing.T) {
 	inv, err := newInventory(&Config{
-		For:              corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+		For:              []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 		UpdateResourceFn: UpdateResourceFnNop,
 	})
 	if err != nil {
@@ -120,7 +120,7 @@ func TestDiffWithSameSpec(t *testing.T) {
 
 func TestDiffWithSpecToUpdate(t *testing.T) {
 	inv, err := newInventory(&Config{
-		For:              corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+		For:              []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 		UpdateResourceFn: UpdateResourceFnNop,
 	})
 	if err != nil {
@@ -226,7 +226,7 @@ func TestDiffWithSpecToUpdate(t *testing.T) {
 
 func TestDiffWithSpecToAdd(t *testing.T) {
 	inv, err := newInventory(&Config{
-		For:              corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+		For:              []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 		UpdateResourceFn: UpdateResourceFnNop,
 	})
 	if err != nil {
@@ -315,7 +315,7 @@ func TestDiffWithSpecToAdd(t *testing.T) {
 
 func TestDiffWithSpecToDelete(t *testing.T) {
 	inv, err := newInventory(&Config{
-		For:              corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+		For:              []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 		UpdateResourceFn: UpdateResourceFnNop,
 	})
 	if err != nil {
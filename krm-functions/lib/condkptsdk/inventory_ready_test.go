@@ -51,7 +51,7 @@ func TestGetReadyMap(t *testing.T) {
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			inv, err := newInventory(&Config{
-				For:                corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+				For:                []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 				GenerateResourceFn: GenerateResourceFnNop,
 			})
 			if err != nil {
@@ -45,6 +45,8 @@ type gvkKindCtx struct {
 	gvkKind    gvkKind
 	ownKind    ResourceKind    // only used for kind == own
 	callbackFn WatchCallbackFn // only used for global watches
+	selector   *watchSelector  // only used for kind == watch
+	schema     *watchSchema    // only used for kind == watch
 }
 
 type resourceCtx struct {
@@ -110,7 +110,7 @@ func TestSet(t *testing.T) {
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			inv, err := newInventory(&Config{
-				For:              corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+				For:              []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 				UpdateResourceFn: UpdateResourceFnNop,
 			})
 			if err != nil {
@@ -167,7 +167,7 @@ func TestDelete(t *testing.T) {
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			inv, err := newInventory(&Config{
-				For:              corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+				For:              []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 				UpdateResourceFn: UpdateResourceFnNop,
 			})
 			if err != nil {
@@ -257,7 +257,7 @@ func TestGet(t *testing.T) {
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			inv, err := newInventory(&Config{
-				For:              corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+				For:              []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 				UpdateResourceFn: UpdateResourceFnNop,
 			})
 			if err != nil {
@@ -307,7 +307,7 @@ func TestList(t *testing.T) {
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			inv, err := newInventory(&Config{
-				For:              corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+				For:              []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 				UpdateResourceFn: UpdateResourceFnNop,
 			})
 			if err != nil {
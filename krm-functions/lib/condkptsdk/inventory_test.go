@@ -30,7 +30,7 @@ func TestNewInventory(t *testing.T) {
 	}{
 		"Normal": {
 			input: &Config{
-				For: corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 				Owns: map[corev1.ObjectReference]ResourceKind{
 					{APIVersion: "b", Kind: "b"}: ChildRemote,
 				},
@@ -49,13 +49,13 @@ func TestNewInventory(t *testing.T) {
 		},
 		"GenerateResourceFn": {
 			input: &Config{
-				For: corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 			},
 			errExpected: true,
 		},
 		"DuplicateGVK1": {
 			input: &Config{
-				For: corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 				Owns: map[corev1.ObjectReference]ResourceKind{
 					{APIVersion: "b", Kind: "b"}: ChildRemote,
 				},
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Scope defines the owner deployment a resource belongs to, which allows multiple
+// deployments, e.g. a UPFDeployment and a SMFDeployment, to share a package
+type Scope struct {
+	// Owner is the root resource of the specialization, e.g. UPFDeployment, SMFDeployment, AMFDeployment
+	Owner corev1.ObjectReference
+	// Namespace is the namespace the resources of the owner are deployed in
+	Namespace string
+}
+
+// GetScope returns the scope of the resource; the owner is resolved from the for annotation
+// and falls back to the owner annotation of the resource
+func GetScope(o *fn.KubeObject) Scope {
+	if o == nil {
+		return Scope{}
+	}
+	owner := o.GetAnnotation(SpecializerOwner)
+	if forOwner := o.GetAnnotation(SpecializerFor); forOwner != "" {
+		owner = forOwner
+	}
+	s := Scope{Namespace: o.GetAnnotation(SpecializerNamespace)}
+	if owner != "" {
+		s.Owner = *kptfilelibv1.GetGVKNFromConditionType(owner)
+		if s.Owner.Name == "" {
+			// the owner is no condition type, the name is the last part of the owner
+			split := strings.Split(owner, ".")
+			s.Owner.Name = split[len(split)-1]
+		}
+	}
+	return s
+}
+
+// IsShared returns true when the scope has no owner, hence the resource is shared by all owners of the package
+func (s Scope) IsShared() bool {
+	return s.Owner.Name == ""
+}
+
+// Validate returns an error when the scope has no owner name or namespace
+func (s Scope) Validate() error {
+	if s.Owner.Name == "" || s.Namespace == "" {
+		return fmt.Errorf("expecting a for name and for namespace, got forName: %s, forNamespace: %s", s.Owner.Name, s.Namespace)
+	}
+	return nil
+}
+
+// Matches returns true when the resource is shared or belongs to the same owner as the scope
+func (s Scope) Matches(o *fn.KubeObject) bool {
+	os := GetScope(o)
+	if os.IsShared() {
+		return true
+	}
+	return os.Owner.APIVersion == s.Owner.APIVersion && os.Owner.Kind == s.Owner.Kind && os.Owner.Name == s.Owner.Name
+}
+
+// Filter returns the resources that are shared or belong to the same owner as the scope
+func (s Scope) Filter(objs fn.KubeObjects) fn.KubeObjects {
+	scoped := fn.KubeObjects{}
+	for _, o := range objs {
+		if s.Matches(o) {
+			scoped = append(scoped, o)
+		}
+	}
+	return scoped
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func newScopeTestObject(t *testing.T, annotations map[string]string) *fn.KubeObject {
+	o := fn.NewEmptyKubeObject()
+	if err := o.SetAPIVersion("req.nephio.org/v1alpha1"); err != nil {
+		t.Fatalf("cannot set apiVersion: %s", err.Error())
+	}
+	if err := o.SetKind("Interface"); err != nil {
+		t.Fatalf("cannot set kind: %s", err.Error())
+	}
+	if err := o.SetName("n3"); err != nil {
+		t.Fatalf("cannot set name: %s", err.Error())
+	}
+	for k, v := range annotations {
+		if err := o.SetAnnotation(k, v); err != nil {
+			t.Fatalf("cannot set annotation: %s", err.Error())
+		}
+	}
+	return o
+}
+
+func TestGetScope(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		want        Scope
+		wantErr     bool
+	}{
+		"Owner": {
+			annotations: map[string]string{
+				SpecializerOwner:     "workload.nephio.org/v1alpha1.UPFDeployment.upf-cluster01",
+				SpecializerNamespace: "upf",
+			},
+			want: Scope{
+				Owner:     corev1.ObjectReference{APIVersion: "workload.nephio.org/v1alpha1", Kind: "UPFDeployment", Name: "upf-cluster01"},
+				Namespace: "upf",
+			},
+		},
+		"For": {
+			annotations: map[string]string{
+				SpecializerOwner:     "req.nephio.org/v1alpha1.Interface.n3",
+				SpecializerFor:       "workload.nephio.org/v1alpha1.SMFDeployment.smf-cluster01",
+				SpecializerNamespace: "smf",
+			},
+			want: Scope{
+				Owner:     corev1.ObjectReference{APIVersion: "workload.nephio.org/v1alpha1", Kind: "SMFDeployment", Name: "smf-cluster01"},
+				Namespace: "smf",
+			},
+		},
+		"NoNamespace": {
+			annotations: map[string]string{
+				SpecializerOwner: "workload.nephio.org/v1alpha1.UPFDeployment.upf-cluster01",
+			},
+			want: Scope{
+				Owner: corev1.ObjectReference{APIVersion: "workload.nephio.org/v1alpha1", Kind: "UPFDeployment", Name: "upf-cluster01"},
+			},
+			wantErr: true,
+		},
+		"Shared": {
+			want:    Scope{},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := GetScope(newScopeTestObject(t, tc.annotations))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+			if tc.wantErr {
+				assert.Error(t, got.Validate())
+			} else {
+				assert.NoError(t, got.Validate())
+			}
+		})
+	}
+}
+
+func TestScopeFilter(t *testing.T) {
+	upf := newScopeTestObject(t, map[string]string{SpecializerOwner: "workload.nephio.org/v1alpha1.UPFDeployment.upf-cluster01"})
+	smf := newScopeTestObject(t, map[string]string{SpecializerOwner: "workload.nephio.org/v1alpha1.SMFDeployment.smf-cluster01"})
+	shared := newScopeTestObject(t, nil)
+
+	scope := GetScope(upf)
+	assert.True(t, scope.Matches(upf))
+	assert.False(t, scope.Matches(smf))
+	assert.True(t, scope.Matches(shared))
+	assert.Equal(t, fn.KubeObjects{upf, shared}, scope.Filter(fn.KubeObjects{upf, smf, shared}))
+}
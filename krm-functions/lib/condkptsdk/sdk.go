@@ -20,8 +20,10 @@ import (
 	"fmt"
 
 	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
 	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -35,6 +37,11 @@ const (
 
 type KptCondSDK interface {
 	Run() (bool, error)
+	// SetCondition upserts a condition in the condition store of the package, the Kptfile by default
+	// The condition store is resolved by Run, hence this can be used from the callbacks and once Run is done
+	SetCondition(c kptv1.Condition) error
+	// DeleteCondition deletes a condition the fn/controller set from the condition store of the package
+	DeleteCondition(ct string) error
 }
 type ResourceKind string
 
@@ -50,12 +57,35 @@ const (
 )
 
 type Config struct {
-	Root                   bool
-	For                    corev1.ObjectReference
-	Owns                   map[corev1.ObjectReference]ResourceKind    // ResourceKind distinguishes different types of child resources.
-	Watch                  map[corev1.ObjectReference]WatchCallbackFn // Used for watches to non specific resources
+	Root           bool
+	For            []corev1.ObjectReference                   // For defines the kinds the fn/controller generates, at least one is required
+	Owns           map[corev1.ObjectReference]ResourceKind    // ResourceKind distinguishes different types of child resources.
+	Watch          map[corev1.ObjectReference]WatchCallbackFn // Used for watches to non specific resources
+	WatchSelectors map[corev1.ObjectReference]WatchSelector   // optional, selects the resources of a Watch entry
+	// WatchSchemas defines per watch kind the go type, e.g. infrav1alpha1.WorkloadCluster{}, the resources
+	// are validated against before the callbacks are called; optional
+	WatchSchemas map[corev1.ObjectReference]any
+	// WatchDependencies defines per watch kind the watch kinds whose callbacks must complete before
+	// the callbacks of the watch kind are called, e.g. WorkloadCluster before Network; optional
+	WatchDependencies      map[corev1.ObjectReference][]corev1.ObjectReference
 	PopulateOwnResourcesFn PopulateOwnResourcesFn
 	UpdateResourceFn       UpdateResourceFn
+	DeleteResourceFn       DeleteResourceFn // optional, called before a for/own resource is removed by the sdk
+	SubPackageFn           SubPackageFn     // optional, places the resources of PopulateOwnResourcesFn in a sub-package
+	// RequiredStatus defines per own or watch kind the status fields, e.g. "prefix", that must be populated
+	// before the UpdateResourceFn is called; the for resource waits for them otherwise. Alternative fields
+	// are separated by |, e.g. "vlanID|vlanRange", one of them must be populated. optional
+	RequiredStatus map[corev1.ObjectReference][]string
+	// ConditionStore returns the store of the conditions of the resources; optional, the conditions
+	// are stored in the Kptfile when not set. The specialize condition and readiness gate of a root
+	// fn/controller are always stored in the Kptfile
+	ConditionStore NewConditionStoreFn
+	// GenerateFor defines the fn/controller generates the for resources requested by the for conditions
+	// of their owner, e.g. the NADs of an Interface. The resources returned by the UpdateResourceFn are
+	// all the resources generated for the for condition: the first resource of the for kind is the for
+	// resource, the other ones get a condition of their own. Generated resources which are no longer
+	// returned, or whose owner is removed, are deleted by the sdk. optional
+	GenerateFor bool
 }
 
 type PopulateOwnResourcesFn func(*fn.KubeObject) (fn.KubeObjects, error)
@@ -65,17 +95,49 @@ type UpdateResourceFn func(*fn.KubeObject, fn.KubeObjects) (fn.KubeObjects, erro
 
 func UpdateResourceFnNop(*fn.KubeObject, fn.KubeObjects) (fn.KubeObjects, error) { return nil, nil }
 
+// DeleteResourceFn is called with the for or own resource the sdk removes since the resources
+// driving it disappeared, which allows the fn to cleanup what it generated next to the resource
+type DeleteResourceFn func(*fn.KubeObject) error
+
 type WatchCallbackFn func(*fn.KubeObject) error
 
+// WatchSelector selects the resources of a Watch entry the fn/controller is interested in,
+// other resources of the GVK are ignored by the sdk. A resource is selected when it matches
+// all the fields that are set
+type WatchSelector struct {
+	// Namespace selects the resources in the namespace
+	Namespace string
+	// LabelSelector selects the resources by their labels
+	LabelSelector *metav1.LabelSelector
+	// Annotations selects the resources having all the annotations with the given values
+	Annotations map[string]string
+	// ReferencedBy selects the resources whose name is referenced by a resource of another Watch entry
+	ReferencedBy *WatchReference
+}
+
+// WatchReference identifies the resources of a Watch entry referencing resources of another kind
+// by name in an annotation, e.g. the Interfaces referencing a ConfigMap
+type WatchReference struct {
+	// Ref is the GVK of the referencing resources, the GVK needs a Watch entry
+	Ref corev1.ObjectReference
+	// Annotation holds the name of the referenced resource
+	Annotation string
+}
+
 func New(rl *fn.ResourceList, cfg *Config) (KptCondSDK, error) {
 	inv, err := newInventory(cfg)
 	if err != nil {
 		return nil, err
 	}
+	watchOrder, err := getWatchOrder(cfg)
+	if err != nil {
+		return nil, err
+	}
 	r := &sdk{
-		cfg: cfg,
-		inv: inv,
-		rl:  rl,
+		cfg:        cfg,
+		inv:        inv,
+		rl:         rl,
+		watchOrder: watchOrder,
 		//ready: true,
 	}
 	return r, nil
@@ -86,10 +148,42 @@ type sdk struct {
 	inv     inventory
 	rl      *fn.ResourceList
 	kptfile kptfilelibv1.KptFile
-	debug   bool // set based on for annotation
+	// conditions stores the conditions of the resources, the Kptfile by default
+	conditions ConditionStore
+	debug      bool   // set based on for annotation
+	trace      *trace // set based on the function config or environment
+	// summary records the metrics of the run, set based on the function config or environment
+	summary *summary
+	// watchOrder defines the order of the watch kinds their callbacks are called in
+	watchOrder []corev1.ObjectReference
+	// span traces the run as part of the specialization of the package, set based on the Kptfile
+	span *runSpan
+	// generatedOrphans are the generated for resources whose owner was removed, used with GenerateFor
+	generatedOrphans fn.KubeObjects
+}
+
+func (r *sdk) SetCondition(c kptv1.Condition) error {
+	if r.conditions == nil {
+		return fmt.Errorf("cannot set condition %s, the condition store is only available once the sdk runs", c.Type)
+	}
+	return r.conditions.SetConditions(c)
+}
+
+func (r *sdk) DeleteCondition(ct string) error {
+	if r.conditions == nil {
+		return fmt.Errorf("cannot delete condition %s, the condition store is only available once the sdk runs", ct)
+	}
+	return r.conditions.DeleteCondition(ct)
 }
 
 func (r *sdk) Run() (bool, error) {
+	r.startSpan()
+	ok, err := r.run()
+	r.endSpan(err)
+	return ok, err
+}
+
+func (r *sdk) run() (bool, error) {
 	if r.rl.Items.Len() == 0 {
 		r.rl.Results.Infof("no resources present in the resourcelist")
 		return true, nil
@@ -105,24 +199,64 @@ func (r *sdk) Run() (bool, error) {
 		return false, fmt.Errorf(msg)
 	}
 	r.kptfile = kptfilelibv1.KptFile{Kptfile: kfko}
+	// the trace report is added to the resourceList once the sdk has run
+	r.initTrace()
+	defer r.writeTrace()
+	// the plan and the summary compare the resources of the package before and after the run
+	planEnabled := isPlanEnabled(r.rl.FunctionConfig)
+	summaryEnabled := isSummaryEnabled(r.rl.FunctionConfig)
+	var before fn.KubeObjects
+	if planEnabled || summaryEnabled {
+		var err error
+		if before, err = copyItems(r.rl.Items); err != nil {
+			fn.Logf("cannot copy resources of the package, err: %s\n", err.Error())
+			r.rl.Results.ErrorE(err)
+			return false, err
+		}
+	}
+	// in plan mode the changes of the sdk are reported as plan and the resourceList is restored once the sdk has run
+	if planEnabled {
+		defer r.writePlan(before)
+	}
+	// the conditions are stored in the Kptfile unless the fn/controller provides a condition store
+	r.conditions = &r.kptfile
+	if r.cfg.ConditionStore != nil {
+		cs, err := r.cfg.ConditionStore(r.rl)
+		if err != nil {
+			msg := "cannot get the condition store of the package"
+			fn.Logf("%s, error: %s\n", msg, err.Error())
+			r.rl.Results.Errorf("%s, error: %s", msg, err.Error())
+			return false, fmt.Errorf("%s, error: %s", msg, err.Error())
+		}
+		r.conditions = cs
+	}
+	// the summary is reported before the plan restores the resourceList
+	if summaryEnabled {
+		r.initSummary()
+		defer r.writeSummary(before)
+	}
 
 	if r.cfg.Root {
 		if err := r.ensureConditionsAndGates(); err != nil {
 			msg := "cannot ensure specialize conditions and readiness gates"
 			fn.Logf("%s, error: %s\n", msg, err.Error())
-			r.rl.Results.Errorf("%s, error: %s\n", msg, err.Error())
-			return false, fmt.Errorf(err.Error(), msg)
+			r.rl.Results.Errorf("%s, error: %s", msg, err.Error())
+			return false, fmt.Errorf("%s, error: %s", msg, err.Error())
 		}
 	}
 
 	// check if debug needs to be enabled.
 	// Debugging can be enabled by setting the SpecializerDebug annotation on the for resource
 	r.setDebug()
+	// the resources selected by reference depend on the referencing resources in the package
+	r.resolveWatchSelectors()
 	// initialize inventory
+	r.traceStage("populate inventory")
 	if err := r.populateInventory(); err != nil {
 		r.failForConditions(fmt.Sprintf("stage1: cannot populate inventory, err: %s", err.Error()))
 		return true, nil
 	}
+	r.summarizeInventory()
 	// list the result of inventory -> used for debug only
 	if r.debug {
 		r.listInventory()
@@ -130,6 +264,7 @@ func (r *sdk) Run() (bool, error) {
 	// call the global watches is used to inform the fn/controller
 	// of global watch data. The fn/controller can use it to parse the data
 	// and/or return an error is certain info is missing
+	r.traceStage("global watches")
 	if err := r.callGlobalWatches(); err != nil {
 		// the for condition status is updated but we don't return since
 		// we might act upon the readiness status, set by the global watch return status
@@ -150,6 +285,7 @@ func (r *sdk) Run() (bool, error) {
 	// populate the child resources as if nothing existed; errors are put in the conditions of the for resources
 	// we only call the populate children if we are in ready status and if there are own resources. As such
 	// we don't populate the children and the next part in stage 1 will act upon the result
+	r.traceStage("stage1")
 	if r.inv.isReady() && len(r.cfg.Owns) > 0 {
 		r.populateChildren()
 	}
@@ -165,14 +301,19 @@ func (r *sdk) Run() (bool, error) {
 
 	// stage 2 of the sdk pipeline -> update resources (forObj and adjacent resources)
 	// the error and condition update is handled in the fn as we can have multiple for resource
+	r.traceStage("stage2")
 	r.updateResources()
 
+	// cleanup the conditions of own resources that no longer exist in the package
+	r.traceStage("stale conditions")
+	r.deleteStaleConditions()
+	r.traceStage("readiness")
+
 	// handle readiness condition -> if all conditions of the for resource are true we can declare readiness
 	if r.cfg.Root {
 		// when not ready leave the condition as is
 		if r.inv.isReady() {
-			ctPrefix := kptfilelibv1.GetConditionType(&corev1.ObjectReference{APIVersion: r.cfg.For.APIVersion, Kind: r.cfg.For.Kind})
-			if r.kptfile.IsReady(ctPrefix) {
+			if r.isForReady() {
 				if err := r.kptfile.SetConditions(ready()); err != nil {
 					fn.Logf("set conditions, err: %s\n", err.Error())
 					r.rl.Results.ErrorE(err)
@@ -188,11 +329,30 @@ func (r *sdk) Run() (bool, error) {
 	return true, nil
 }
 
+// isForReady returns true when the conditions of all for kinds are ready
+func (r *sdk) isForReady() bool {
+	for _, forRef := range r.cfg.For {
+		ctPrefix := kptfilelibv1.GetConditionType(&corev1.ObjectReference{APIVersion: forRef.APIVersion, Kind: forRef.Kind})
+		if !r.conditions.IsReady(ctPrefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// getForObjects returns the resources matching any of the for kinds
+func (r *sdk) getForObjects() fn.KubeObjects {
+	forObjs := fn.KubeObjects{}
+	for _, forRef := range r.cfg.For {
+		forObjs = append(forObjs, r.rl.Items.Where(fn.IsGroupVersionKind(forRef.GroupVersionKind()))...)
+	}
+	return forObjs
+}
+
 func (r *sdk) setDebug() {
 	// check if debug needs to be enabled.
 	// Debugging can be enabled by setting the SpecializerDebug annotation on the for resource
-	forObjs := r.rl.Items.Where(fn.IsGroupVersionKind(r.cfg.For.GroupVersionKind()))
-	for _, forObj := range forObjs {
+	for _, forObj := range r.getForObjects() {
 		if forObj.GetAnnotation(SpecializerDebug) != "" {
 			r.debug = true
 			r.inv.setdebug()
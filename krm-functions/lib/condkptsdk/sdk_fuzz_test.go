@@ -0,0 +1,349 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// the kinds of the fuzzed fn: A is the for kind, B, C, D and E are the own kinds per resource kind
+// and W is the watch kind
+var (
+	fuzzForRef        = corev1.ObjectReference{APIVersion: "a.nephio.org/v1", Kind: "A"}
+	fuzzRemoteRef     = corev1.ObjectReference{APIVersion: "b.nephio.org/v1", Kind: "B"}
+	fuzzConditionRef  = corev1.ObjectReference{APIVersion: "c.nephio.org/v1", Kind: "C"}
+	fuzzLocalRef      = corev1.ObjectReference{APIVersion: "d.nephio.org/v1", Kind: "D"}
+	fuzzInitialRef    = corev1.ObjectReference{APIVersion: "e.nephio.org/v1", Kind: "E"}
+	fuzzWatchRef      = corev1.ObjectReference{APIVersion: "w.nephio.org/v1", Kind: "W"}
+	fuzzOtherOwnerRef = corev1.ObjectReference{APIVersion: "x.nephio.org/v1", Kind: "X"}
+
+	fuzzOwnRefs = []corev1.ObjectReference{fuzzRemoteRef, fuzzConditionRef, fuzzLocalRef, fuzzInitialRef}
+	fuzzRefs    = append([]corev1.ObjectReference{fuzzForRef, fuzzWatchRef, fuzzOtherOwnerRef}, fuzzOwnRefs...)
+	// a small set of names such that the resources and conditions of a package collide
+	fuzzNames = []string{"x", "y", "z"}
+)
+
+// fuzzPackage generates the random packages of the fuzz tests: the owners, conditions, annotations
+// and statuses of the resources vary, including malformed ones
+type fuzzPackage struct {
+	rand *rand.Rand
+	b    strings.Builder
+}
+
+func newFuzzPackage(seed int64) *fuzzPackage {
+	return &fuzzPackage{rand: rand.New(rand.NewSource(seed))} // #nosec G404 -- reproducible packages
+}
+
+func (r *fuzzPackage) chance(percent int) bool {
+	return r.rand.Intn(100) < percent
+}
+
+func (r *fuzzPackage) name() string {
+	return fuzzNames[r.rand.Intn(len(fuzzNames))]
+}
+
+func (r *fuzzPackage) ref() corev1.ObjectReference {
+	ref := fuzzRefs[r.rand.Intn(len(fuzzRefs))]
+	ref.Name = r.name()
+	return ref
+}
+
+// conditionType returns a condition type, mostly of the kinds of the fn
+func (r *fuzzPackage) conditionType() string {
+	switch r.rand.Intn(10) {
+	case 0:
+		return ""
+	case 1:
+		return "garbage"
+	case 2:
+		return getSpecializationConditionType()
+	default:
+		ref := r.ref()
+		return kptfilelibv1.GetConditionType(&ref)
+	}
+}
+
+func (r *fuzzPackage) status() string {
+	return []string{"True", "False", "Unknown", ""}[r.rand.Intn(4)]
+}
+
+func (r *fuzzPackage) writeKptfile() {
+	r.b.WriteString("- apiVersion: kpt.dev/v1\n  kind: Kptfile\n  metadata:\n    name: pkg\n")
+	if r.chance(30) {
+		r.b.WriteString("  info:\n    readinessGates:\n")
+		for i := r.rand.Intn(3); i >= 0; i-- {
+			fmt.Fprintf(&r.b, "    - conditionType: %q\n", r.conditionType())
+		}
+	}
+	if !r.chance(80) {
+		return
+	}
+	r.b.WriteString("  status:\n    conditions:\n")
+	for i := r.rand.Intn(8); i >= 0; i-- {
+		fmt.Fprintf(&r.b, "    - type: %q\n      status: %q\n", r.conditionType(), r.status())
+		if r.chance(70) {
+			fmt.Fprintf(&r.b, "      reason: %q\n", r.conditionType())
+		}
+		if r.chance(30) {
+			fmt.Fprintf(&r.b, "      message: %q\n", []string{"create initial resource", "update resource", "delete resource", "done"}[r.rand.Intn(4)])
+		}
+	}
+}
+
+func (r *fuzzPackage) writeResource(ref corev1.ObjectReference) {
+	fmt.Fprintf(&r.b, "- apiVersion: %s\n  kind: %s\n  metadata:\n    name: %s\n", ref.APIVersion, ref.Kind, ref.Name)
+	annotations := map[string]string{}
+	if r.chance(60) {
+		owner := r.ref()
+		annotations[SpecializerOwner] = kptfilelibv1.GetConditionType(&owner)
+		if r.chance(10) {
+			annotations[SpecializerOwner] = "garbage"
+		}
+	}
+	if r.chance(30) {
+		owner := r.ref()
+		annotations[SpecializerFor] = kptfilelibv1.GetConditionType(&owner)
+	}
+	if r.chance(10) {
+		annotations[SpecializerDelete] = "true"
+	}
+	if r.chance(10) {
+		annotations["fuzz.nephio.org/fail"] = "true"
+	}
+	if len(annotations) > 0 {
+		r.b.WriteString("    annotations:\n")
+		for k, v := range annotations {
+			fmt.Fprintf(&r.b, "      %s: %q\n", k, v)
+		}
+	}
+	if r.chance(50) {
+		fmt.Fprintf(&r.b, "  spec:\n    value: %d\n", r.rand.Intn(3))
+	}
+	switch r.rand.Intn(3) {
+	case 0:
+		r.b.WriteString("  status:\n    prefix: 10.0.0.1/24\n")
+	case 1:
+		r.b.WriteString("  status: {}\n")
+	}
+}
+
+// resourceList returns a random package as ResourceList
+func (r *fuzzPackage) resourceList() string {
+	r.b.Reset()
+	r.b.WriteString("apiVersion: config.kubernetes.io/v1\nkind: ResourceList\nitems:\n")
+	if r.chance(95) {
+		r.writeKptfile()
+	}
+	for i := r.rand.Intn(10); i > 0; i-- {
+		r.writeResource(r.ref())
+	}
+	return r.b.String()
+}
+
+// newFuzzConfig returns the config of the fuzzed fn, the callbacks fail for the resources with the
+// fuzz.nephio.org/fail annotation
+func newFuzzConfig(root bool) *Config {
+	failFn := func(o *fn.KubeObject) error {
+		if o.GetAnnotation("fuzz.nephio.org/fail") != "" {
+			return fmt.Errorf("%s %s failed", o.GetKind(), o.GetName())
+		}
+		return nil
+	}
+	return &Config{
+		Root: root,
+		For:  []corev1.ObjectReference{fuzzForRef},
+		Owns: map[corev1.ObjectReference]ResourceKind{
+			fuzzRemoteRef:    ChildRemote,
+			fuzzConditionRef: ChildRemoteCondition,
+			fuzzLocalRef:     ChildLocal,
+			fuzzInitialRef:   ChildInitial,
+		},
+		Watch: map[corev1.ObjectReference]WatchCallbackFn{
+			fuzzWatchRef: failFn,
+		},
+		PopulateOwnResourcesFn: func(o *fn.KubeObject) (fn.KubeObjects, error) {
+			if err := failFn(o); err != nil {
+				return nil, err
+			}
+			objs := fn.KubeObjects{}
+			for _, ref := range []corev1.ObjectReference{fuzzRemoteRef, fuzzConditionRef, fuzzLocalRef} {
+				child := fn.NewEmptyKubeObject()
+				if err := child.SetAPIVersion(ref.APIVersion); err != nil {
+					return nil, err
+				}
+				if err := child.SetKind(ref.Kind); err != nil {
+					return nil, err
+				}
+				if err := child.SetName(o.GetName()); err != nil {
+					return nil, err
+				}
+				objs = append(objs, child)
+			}
+			return objs, nil
+		},
+		UpdateResourceFn: func(o *fn.KubeObject, _ fn.KubeObjects) (fn.KubeObjects, error) {
+			if o == nil {
+				return nil, nil
+			}
+			if err := failFn(o); err != nil {
+				return nil, err
+			}
+			return fn.KubeObjects{o}, nil
+		},
+	}
+}
+
+// getDuplicateConditionTypes returns the condition types present multiple times in the Kptfile
+func getDuplicateConditionTypes(kf kptfilelibv1.KptFile) map[string]bool {
+	seen := map[string]bool{}
+	duplicates := map[string]bool{}
+	for _, c := range kf.GetConditions() {
+		if seen[c.Type] {
+			duplicates[c.Type] = true
+		}
+		seen[c.Type] = true
+	}
+	return duplicates
+}
+
+// checkInvariants checks the invariants of the package once the sdk has run, inputDuplicates are the
+// duplicate condition types of the input package which the sdk is not expected to repair
+func checkInvariants(rl *fn.ResourceList, root bool, inputDuplicates map[string]bool) error {
+	kfko := rl.Items.GetRootKptfile()
+	if kfko == nil {
+		return nil
+	}
+	kf := kptfilelibv1.KptFile{Kptfile: kfko}
+	for ct := range getDuplicateConditionTypes(kf) {
+		if !inputDuplicates[ct] {
+			return fmt.Errorf("duplicate condition %s", ct)
+		}
+	}
+	conditions := map[string]kptv1.Condition{}
+	for _, c := range kf.GetConditions() {
+		conditions[c.Type] = c
+	}
+	if root {
+		ct := getSpecializationConditionType()
+		if _, ok := conditions[ct]; !ok {
+			return fmt.Errorf("specialize condition %s is missing", ct)
+		}
+		if !kf.HasReadinessGate(ct) {
+			return fmt.Errorf("readiness gate %s is missing", ct)
+		}
+	}
+	// a condition of a remote own resource is kept only while the resource or its owner exists
+	for _, c := range conditions {
+		objRef := kptfilelibv1.GetGVKNFromConditionType(c.Type)
+		if objRef.APIVersion != fuzzRemoteRef.APIVersion || objRef.Kind != fuzzRemoteRef.Kind {
+			continue
+		}
+		if hasFuzzResource(rl, *objRef) {
+			continue
+		}
+		ownerRef := kptfilelibv1.GetGVKNFromConditionType(c.Reason)
+		if ownerRef.APIVersion != fuzzForRef.APIVersion || ownerRef.Kind != fuzzForRef.Kind {
+			// owned by another fn/controller or without valid owner, the owner is unknown
+			continue
+		}
+		if hasFuzzResource(rl, *ownerRef) {
+			continue
+		}
+		if _, ok := conditions[kptfilelibv1.GetConditionType(ownerRef)]; ok {
+			continue
+		}
+		return fmt.Errorf("stale condition %s, neither %s %s nor its owner %s exists", c.Type, objRef.Kind, objRef.Name, c.Reason)
+	}
+	return nil
+}
+
+func hasFuzzResource(rl *fn.ResourceList, ref corev1.ObjectReference) bool {
+	for _, o := range rl.Items {
+		if o.GetAPIVersion() == ref.APIVersion && o.GetKind() == ref.Kind && o.GetName() == ref.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// runFuzzSDK runs the sdk on the ResourceList and checks the invariants, such that a malformed package
+// is reported with the package instead of an opaque runtime error
+func runFuzzSDK(t *testing.T, input string, root bool) {
+	rl, err := fn.ParseResourceList([]byte(input))
+	if err != nil {
+		// not a ResourceList, nothing to check
+		return
+	}
+	inputDuplicates := map[string]bool{}
+	if kfko := rl.Items.GetRootKptfile(); kfko != nil {
+		inputDuplicates = getDuplicateConditionTypes(kptfilelibv1.KptFile{Kptfile: kfko})
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			t.Fatalf("sdk panicked: %v\n%s\nroot: %t\ninput:\n%s", p, debug.Stack(), root, input)
+		}
+	}()
+	kptsdk, err := New(rl, newFuzzConfig(root))
+	if err != nil {
+		t.Fatalf("cannot create sdk: %s", err.Error())
+	}
+	_, err = kptsdk.Run()
+	if err != nil && rl.Results.ExitCode() == 0 {
+		t.Fatalf("error not reported in the results: %s\nroot: %t\ninput:\n%s", err.Error(), root, input)
+	}
+	if rl.Results.ExitCode() != 0 {
+		// a malformed package fails the run, the errors must be reported as readable results
+		for _, result := range rl.Results {
+			if strings.Contains(result.Message, "%!") {
+				t.Fatalf("malformed error message: %s\nroot: %t\ninput:\n%s", result.Message, root, input)
+			}
+		}
+		return
+	}
+	if err := checkInvariants(rl, root, inputDuplicates); err != nil {
+		t.Fatalf("%s\nroot: %t\ninput:\n%s", err.Error(), root, input)
+	}
+}
+
+// FuzzRun runs the sdk on random packages, run it with go test -fuzz=FuzzRun
+func FuzzRun(f *testing.F) {
+	for seed := int64(0); seed < 64; seed++ {
+		f.Add(seed, seed%2 == 0)
+	}
+	f.Fuzz(func(t *testing.T, seed int64, root bool) {
+		runFuzzSDK(t, newFuzzPackage(seed).resourceList(), root)
+	})
+}
+
+// FuzzRunResourceList runs the sdk on arbitrary ResourceLists, run it with go test -fuzz=FuzzRunResourceList
+func FuzzRunResourceList(f *testing.F) {
+	for seed := int64(0); seed < 16; seed++ {
+		f.Add(newFuzzPackage(seed).resourceList(), seed%2 == 0)
+	}
+	f.Fuzz(func(t *testing.T, input string, root bool) {
+		runFuzzSDK(t, input, root)
+	})
+}
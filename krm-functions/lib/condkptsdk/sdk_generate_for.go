@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	"github.com/nephio-project/nephio/krm-functions/lib/ref"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// isGeneratedCondition returns true for the condition of a resource generated next to a for resource,
+// e.g. the NAD of an additional network instance of an Interface; the reason of the condition is
+// the condition type of the for resource
+func (r *sdk) isGeneratedCondition(c kptv1.Condition) bool {
+	if !r.cfg.GenerateFor {
+		return false
+	}
+	forRef := kptfilelibv1.GetGVKNFromConditionType(c.Reason)
+	if ref.ValidateGVKNRef(*forRef) != nil {
+		return false
+	}
+	kindCtx, ok := r.inv.isGVKMatch(forRef)
+	return ok && kindCtx.gvkKind == forGVKKind
+}
+
+// getGeneratedConditions returns the conditions of the resources generated next to the for resource
+func (r *sdk) getGeneratedConditions(forRef corev1.ObjectReference) []kptv1.Condition {
+	conditions := []kptv1.Condition{}
+	for _, c := range r.conditions.GetConditions() {
+		if c.Reason == kptfilelibv1.GetConditionType(&forRef) && r.isGeneratedCondition(c) {
+			conditions = append(conditions, c)
+		}
+	}
+	return conditions
+}
+
+// getGeneratedForRef returns the for reference of a generated resource of a for kind in the package
+// A resource without a condition of its own is the for resource of the for condition of its owner,
+// since the fn/controller names the resources it generates, e.g. the NAD upf-cluster01-n3 is the for
+// resource of the condition of NAD n3 requested by Interface n3. The resource is not added to the
+// inventory when it is generated next to a for resource, when it is not generated by the fn/controller
+// or when its owner was removed, in which case the resource is an orphan
+func (r *sdk) getGeneratedForRef(owners *forOwners, linked map[corev1.ObjectReference]bool, objRef corev1.ObjectReference, o *fn.KubeObject) (corev1.ObjectReference, bool) {
+	if c := r.conditions.GetCondition(kptfilelibv1.GetConditionType(&objRef)); c != nil {
+		// a generated resource is handled together with its for resource
+		return objRef, !r.isGeneratedCondition(*c)
+	}
+	ownerRef := kptfilelibv1.GetGVKNFromConditionType(o.GetAnnotation(SpecializerOwner))
+	if ref.ValidateGVKNRef(*ownerRef) != nil {
+		// the resource is not generated by the fn/controller
+		return corev1.ObjectReference{}, false
+	}
+	forRefs := owners.get(*ownerRef)
+	for _, forRef := range forRefs {
+		if forRef.APIVersion == objRef.APIVersion && forRef.Kind == objRef.Kind && !linked[forRef] && !r.hasResource(forRef) {
+			linked[forRef] = true
+			return forRef, true
+		}
+	}
+	if len(forRefs) == 0 && !r.hasResource(*ownerRef) && r.conditions.GetCondition(kptfilelibv1.GetConditionType(ownerRef)) == nil {
+		r.generatedOrphans = append(r.generatedOrphans, o)
+	}
+	return corev1.ObjectReference{}, false
+}
+
+// deleteGeneratedOrphans deletes the generated resources whose owner was removed and the resources
+// generated next to a for resource whose condition was removed, together with their condition
+func (r *sdk) deleteGeneratedOrphans() {
+	for _, o := range r.generatedOrphans {
+		r.deleteGeneratedResource(o, "delete generated resource, owner removed")
+	}
+	for _, c := range r.conditions.GetConditions() {
+		if !r.isGeneratedCondition(c) || r.conditions.GetCondition(c.Reason) != nil {
+			continue
+		}
+		r.deleteGeneratedObject(c, "delete generated resource, for resource removed")
+	}
+}
+
+// deleteGeneratedObjects deletes the resources generated next to the for resource, together with
+// their condition, except the resources in keep
+func (r *sdk) deleteGeneratedObjects(forRef corev1.ObjectReference, keep map[corev1.ObjectReference]bool, msg string) {
+	for _, c := range r.getGeneratedConditions(forRef) {
+		if keep[*kptfilelibv1.GetGVKNFromConditionType(c.Type)] {
+			continue
+		}
+		r.deleteGeneratedObject(c, msg)
+	}
+}
+
+// deleteGeneratedObject deletes the generated resource of the condition and the condition
+func (r *sdk) deleteGeneratedObject(c kptv1.Condition, msg string) {
+	objRef := kptfilelibv1.GetGVKNFromConditionType(c.Type)
+	for _, o := range r.rl.Items {
+		if o.GetAPIVersion() == objRef.APIVersion && o.GetKind() == objRef.Kind && o.GetName() == objRef.Name {
+			r.deleteGeneratedResource(o, msg)
+			break
+		}
+	}
+	r.traceConditionDelete(c.Type)
+	if err := r.conditions.DeleteCondition(c.Type); err != nil {
+		fn.Logf("cannot delete condition %s, err: %v\n", c.Type, err.Error())
+		r.rl.Results.ErrorE(err)
+	}
+}
+
+// deleteGeneratedResource informs the fn/controller and removes the generated resource from the resourceList
+func (r *sdk) deleteGeneratedResource(o *fn.KubeObject, msg string) {
+	r.traceEvent(traceEventInventory, traceRefs(o), msg)
+	// the error is already logged and reported in the results, the resource is deleted anyhow
+	_ = r.callDeleteResource(o)
+	r.deleteObjFromResourceList(o)
+}
+
+// updateGeneratedResources updates the resources the fn/controller generated for the for resource
+// The first resource of the kind of the for resource is the for resource, the other resources are
+// generated next to the for resource and get a condition of their own with the condition of the
+// for resource as reason. The resources generated before which are not returned anymore are deleted.
+func (r *sdk) updateGeneratedResources(forRef corev1.ObjectReference, readyCtx *readyCtx, newObjs fn.KubeObjects) {
+	generated := map[corev1.ObjectReference]bool{}
+	forUpdated := false
+	for _, newObj := range newObjs {
+		objRef := corev1.ObjectReference{APIVersion: newObj.GetAPIVersion(), Kind: newObj.GetKind(), Name: newObj.GetName()}
+		gvkKindCtx, ok := r.inv.isGVKMatch(&objRef)
+		if !ok || gvkKindCtx.gvkKind != forGVKKind {
+			err := fmt.Errorf("stage 2 fn returned an object that is not a for kind in the config: ref: %s", ref.GetRefsString(objRef))
+			fn.Logf("%s\n", err.Error())
+			r.rl.Results.ErrorE(err)
+			continue
+		}
+		generated[objRef] = true
+		if !forUpdated && objRef.APIVersion == forRef.APIVersion && objRef.Kind == forRef.Kind {
+			forUpdated = true
+			if err := r.upsertChildObject(forGVKKind, []corev1.ObjectReference{forRef}, object{obj: *newObj}, readyCtx.forCondition, "update done", kptv1.ConditionTrue, true); err != nil {
+				fn.Logf("cannot update resourcelist and inventory after handleUpdateResource: objRef %s, err: %v\n", ref.GetRefsString(forRef), err.Error())
+			}
+			continue
+		}
+		if err := r.upsertGeneratedObject([]corev1.ObjectReference{forRef, objRef}, newObj); err != nil {
+			fn.Logf("cannot update resourcelist after handleUpdateResource: objRef %s, err: %v\n", ref.GetRefsString(forRef, objRef), err.Error())
+		}
+	}
+	// the for condition is done when the for resource is generated as resources of other for kinds
+	if !forUpdated && len(generated) > 0 {
+		c := *readyCtx.forCondition
+		c.Message = "update done"
+		c.Status = kptv1.ConditionTrue
+		r.traceCondition(c)
+		if err := r.conditions.SetConditions(c); err != nil {
+			fn.Logf("cannot set condition in kptfile objref: %s, err: %s\n", ref.GetRefsString(forRef), err.Error())
+			r.rl.Results.ErrorE(err)
+		}
+	}
+	// the for resource is replaced when the fn/controller generates it with another name or not at all
+	if readyCtx.forObj != nil && !generated[corev1.ObjectReference{APIVersion: readyCtx.forObj.GetAPIVersion(), Kind: readyCtx.forObj.GetKind(), Name: readyCtx.forObj.GetName()}] {
+		r.deleteGeneratedResource(readyCtx.forObj, "delete for resource, no longer generated")
+	}
+	r.deleteGeneratedObjects(forRef, generated, "delete generated resource, no longer generated")
+}
+
+// upsertGeneratedObject upserts the resource generated next to the for resource in the resourceList
+// and sets its condition; the refs are the for reference and the reference of the generated resource
+func (r *sdk) upsertGeneratedObject(refs []corev1.ObjectReference, obj *fn.KubeObject) error {
+	c, err := kptfilelibv1.GetConditionByRef(refs, "update done", kptv1.ConditionTrue, nil)
+	if err != nil {
+		return err
+	}
+	r.traceCondition(c)
+	if err := r.conditions.SetConditions(c); err != nil {
+		fn.Logf("cannot set condition in kptfile objref: %s, err: %v\n", ref.GetRefsString(refs...), err.Error())
+		r.rl.Results.ErrorE(err)
+		return err
+	}
+	if err := r.rl.UpsertObjectToItems(obj, nil, true); err != nil {
+		fn.Logf("cannot set resource in resourceList objref: %s, err: %v\n", ref.GetRefsString(refs...), err.Error())
+		r.rl.Results.ErrorE(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PlanEnv enables the plan mode of the sdk when set to true
+	PlanEnv = "SPECIALIZER_PLAN"
+	// PlanKey enables the plan mode of the sdk when set to true in the data of the function config
+	PlanKey = "plan"
+
+	planAPIVersion = "specializer.nephio.org/v1alpha1"
+	planKind       = "SpecializerPlan"
+)
+
+type planAction string
+
+const (
+	planActionCreate planAction = "create"
+	planActionUpdate planAction = "update"
+	planActionDelete planAction = "delete"
+)
+
+// plan is the set of changes the sdk would perform on the package
+type plan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              planSpec `json:"spec"`
+}
+
+type planSpec struct {
+	// Resources defines the changes to the resources of the package
+	Resources []planResource `json:"resources,omitempty"`
+	// Conditions defines the changes to the conditions of the Kptfile
+	Conditions []planCondition `json:"conditions,omitempty"`
+}
+
+type planResource struct {
+	Action     planAction `json:"action"`
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Name       string     `json:"name"`
+	Namespace  string     `json:"namespace,omitempty"`
+}
+
+type planCondition struct {
+	Action  planAction            `json:"action"`
+	Type    string                `json:"type"`
+	Status  kptv1.ConditionStatus `json:"status,omitempty"`
+	Reason  string                `json:"reason,omitempty"`
+	Message string                `json:"message,omitempty"`
+}
+
+// isPlanEnabled returns true when the plan mode is enabled in the function config or the environment
+func isPlanEnabled(fc *fn.KubeObject) bool {
+	if os.Getenv(PlanEnv) == "true" {
+		return true
+	}
+	if fc == nil {
+		return false
+	}
+	v, _, _ := fc.NestedString("data", PlanKey)
+	return v == "true"
+}
+
+// copyItems returns a deep copy of the resources of the package
+func copyItems(items fn.KubeObjects) (fn.KubeObjects, error) {
+	copies := fn.KubeObjects{}
+	for _, o := range items {
+		c, err := fn.ParseKubeObject([]byte(o.String()))
+		if err != nil {
+			return nil, err
+		}
+		copies = append(copies, c)
+	}
+	return copies, nil
+}
+
+// writePlan computes the plan from the resources of the package before and after the run
+// of the sdk, restores the resources of the package as they were before the run and adds
+// the plan to the resourceList and its results
+func (r *sdk) writePlan(before fn.KubeObjects) {
+	p := &plan{
+		TypeMeta: metav1.TypeMeta{APIVersion: planAPIVersion, Kind: planKind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-plan", strings.ToLower(r.cfg.For[0].Kind)),
+			Annotations: map[string]string{"config.kubernetes.io/local-config": "true"},
+		},
+	}
+	after := r.rl.Items
+	p.Spec.Resources = getPlanResources(before, after)
+	p.Spec.Conditions = getPlanConditions(before, after)
+
+	r.rl.Items = before
+	for _, res := range p.Spec.Resources {
+		r.rl.Results.Infof("plan: %s %s %s", res.Action, res.Kind, res.Name)
+	}
+	for _, c := range p.Spec.Conditions {
+		r.rl.Results.Infof("plan: %s condition %s", c.Action, c.Type)
+	}
+	o, err := fn.NewFromTypedObject(p)
+	if err != nil {
+		fn.Logf("cannot create plan, err: %s\n", err.Error())
+		r.rl.Results.ErrorE(err)
+		return
+	}
+	if err := r.rl.UpsertObjectToItems(o, nil, true); err != nil {
+		fn.Logf("cannot add plan to the resourceList, err: %s\n", err.Error())
+		r.rl.Results.ErrorE(err)
+	}
+}
+
+func getPlanResourceKey(o *fn.KubeObject) string {
+	return fmt.Sprintf("%s/%s/%s/%s", o.GetAPIVersion(), o.GetKind(), o.GetNamespace(), o.GetName())
+}
+
+func isPlanIgnored(o *fn.KubeObject) bool {
+	// the Kptfile changes are reported as condition changes, the report of the sdk is no resource of the package
+	return o.GetKind() == kptv1.KptFileKind ||
+		(o.GetAPIVersion() == planAPIVersion && o.GetKind() == planKind) ||
+		(o.GetAPIVersion() == traceAPIVersion && o.GetKind() == traceKind)
+}
+
+func newPlanResource(action planAction, o *fn.KubeObject) planResource {
+	return planResource{
+		Action:     action,
+		APIVersion: o.GetAPIVersion(),
+		Kind:       o.GetKind(),
+		Name:       o.GetName(),
+		Namespace:  o.GetNamespace(),
+	}
+}
+
+// getPlanResources returns the resources that are created, updated or deleted; resources
+// that get the delete annotation are reported as deleted
+func getPlanResources(before, after fn.KubeObjects) []planResource {
+	beforeObjs := map[string]*fn.KubeObject{}
+	for _, o := range before {
+		beforeObjs[getPlanResourceKey(o)] = o
+	}
+	afterObjs := map[string]bool{}
+	resources := []planResource{}
+	for _, o := range after {
+		if isPlanIgnored(o) {
+			continue
+		}
+		afterObjs[getPlanResourceKey(o)] = true
+		bo, ok := beforeObjs[getPlanResourceKey(o)]
+		switch {
+		case !ok:
+			resources = append(resources, newPlanResource(planActionCreate, o))
+		case o.GetAnnotation(SpecializerDelete) == "true" && bo.GetAnnotation(SpecializerDelete) != "true":
+			resources = append(resources, newPlanResource(planActionDelete, o))
+		case o.String() != bo.String():
+			resources = append(resources, newPlanResource(planActionUpdate, o))
+		}
+	}
+	for _, o := range before {
+		if isPlanIgnored(o) {
+			continue
+		}
+		if !afterObjs[getPlanResourceKey(o)] {
+			resources = append(resources, newPlanResource(planActionDelete, o))
+		}
+	}
+	return resources
+}
+
+func getConditions(objs fn.KubeObjects) []kptv1.Condition {
+	kf := objs.GetRootKptfile()
+	if kf == nil {
+		return nil
+	}
+	return (&kptfilelibv1.KptFile{Kptfile: kf}).GetConditions()
+}
+
+// getPlanConditions returns the conditions of the Kptfile that are created, updated or deleted
+func getPlanConditions(before, after fn.KubeObjects) []planCondition {
+	beforeConditions := map[string]kptv1.Condition{}
+	for _, c := range getConditions(before) {
+		beforeConditions[c.Type] = c
+	}
+	afterConditions := map[string]bool{}
+	conditions := []planCondition{}
+	for _, c := range getConditions(after) {
+		afterConditions[c.Type] = true
+		bc, ok := beforeConditions[c.Type]
+		switch {
+		case !ok:
+			conditions = append(conditions, planCondition{Action: planActionCreate, Type: c.Type, Status: c.Status, Reason: c.Reason, Message: c.Message})
+		case bc != c:
+			conditions = append(conditions, planCondition{Action: planActionUpdate, Type: c.Type, Status: c.Status, Reason: c.Reason, Message: c.Message})
+		}
+	}
+	for _, c := range getConditions(before) {
+		if !afterConditions[c.Type] {
+			conditions = append(conditions, planCondition{Action: planActionDelete, Type: c.Type})
+		}
+	}
+	return conditions
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package condkptsdk
 
 import (
+	"fmt"
+
 	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
 	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
 	"github.com/nephio-project/nephio/krm-functions/lib/ref"
@@ -30,59 +32,127 @@ func (r *sdk) populateInventory() error {
 	// versus specific resources associated to a forInstance (specified through the SDK Config).
 	// To perform this filtering we use the concept of the forOwnerRef, which is
 	// an ownerReference associated to the forGVK
-	// A watchedResource matching the forOwnerRef is associated to the specific
+	// A watchedResource matching a forOwnerRef is associated to the specific
 	// forInventory context. If no match was found to the forOwnerRef the watchedResource is associated
 	// to the global context
-	var forOwnerRef *corev1.ObjectReference
-	// keeps track a map to link the forOwner name to the specific for resourceName
-	// used by NAD since we don't do the intelligent diff, we need to handle the mapping
-	// used only to populate the inventory for specific watches
-	forOwnerRefNameMap := map[string]string{}
+	// The forOwners are scoped per owner (GVK and name), such that the for resources of different owners
+	// in the same package, e.g. the Interfaces of a UPFDeployment and a SMFDeployment, are dispatched
+	// with the watches of their own owner only
+	owners := newForOwners()
 
 	// We first run through the conditions to check if an ownRef is associated
 	// to the for resource objects. We call this the forOwnerRef
 	// When a forOwnerRef exists it is used to associate a watch resource to the
 	// inventory specific to the for resource or globally.
-	for _, c := range r.kptfile.GetConditions() {
+	for _, c := range r.conditions.GetConditions() {
 		// get the specific inventory context from the conditionType
 		objRef := kptfilelibv1.GetGVKNFromConditionType(c.Type)
 		// check if the conditionType is coming from a for KRM resource
 		kindCtx, ok := r.inv.isGVKMatch(objRef)
-		if ok && kindCtx.gvkKind == forGVKKind {
+		if ok && kindCtx.gvkKind == forGVKKind && !r.isGeneratedCondition(c) {
 			// get the ownerRef from the conditionReason
-			// to see if the forOwnerref is present and if so initialize the forOwnerRef using the GVK
-			// information
+			// to see if the forOwnerref is present and if so add the for resource to the forOwner
 			ownerRef := kptfilelibv1.GetGVKNFromConditionType(c.Reason)
 			if err := ref.ValidateGVKRef(*ownerRef); err == nil {
-				forOwnerRef = &corev1.ObjectReference{APIVersion: ownerRef.APIVersion, Kind: ownerRef.Kind}
-				forOwnerRefNameMap[ownerRef.Name] = objRef.Name
+				owners.add(*ownerRef, *objRef)
 				if r.debug {
-					fn.Logf("forOwnerRefNameMap: refKind: %s, refName: %s, forOwnRefName: %s\n", objRef.Kind, objRef.Name, ownerRef.Name)
+					fn.Logf("forOwners: refKind: %s, refName: %s, forOwnRef: %s\n", objRef.Kind, objRef.Name, ref.GetRefsString(*ownerRef))
 				}
 			}
 		}
 	}
-	// Now we have the forOwnerRef we run through the condition again to populate the remaining
+	// Now we have the forOwnerRefs we run through the condition again to populate the remaining
 	// resources in the inventory
-	for _, c := range r.kptfile.GetConditions() {
+	for _, c := range r.conditions.GetConditions() {
+		if r.isGeneratedCondition(c) {
+			// the resources generated next to a for resource are handled together with the for resource
+			continue
+		}
 		ref := kptfilelibv1.GetGVKNFromConditionType(c.Type)
 		ownerRef := kptfilelibv1.GetGVKNFromConditionType(c.Reason)
 		x := c
-		if err := r.populate(forOwnerRefNameMap, forOwnerRef, ref, ownerRef, &x, r.kptfile.Kptfile); err != nil {
+		if err := r.populate(owners, ref, ownerRef, &x, r.kptfile.Kptfile); err != nil {
 			return err
 		}
 	}
+	// the inventory is keyed by GVK and name, hence resources of the same kind and name with
+	// a different owner would collide
+	scopes := map[corev1.ObjectReference]Scope{}
+	// linked keeps track of the for resources linked to a generated resource with another name
+	linked := map[corev1.ObjectReference]bool{}
 	for _, o := range r.rl.Items {
+		o, err := r.convertWatchResource(o)
+		if err != nil {
+			return err
+		}
 		ref := &corev1.ObjectReference{APIVersion: o.GetAPIVersion(), Kind: o.GetKind(), Name: o.GetName()}
+		if err := validateUniqueInScope(scopes, *ref, GetScope(o)); err != nil {
+			return err
+		}
+		if kindCtx, ok := r.inv.isGVKMatch(ref); ok && kindCtx.gvkKind == forGVKKind && r.cfg.GenerateFor {
+			forRef, ok := r.getGeneratedForRef(owners, linked, *ref, o)
+			if !ok {
+				continue
+			}
+			ref = &forRef
+		}
 		ownerRef := kptfilelibv1.GetGVKNFromConditionType(o.GetAnnotation(SpecializerOwner))
-		if err := r.populate(forOwnerRefNameMap, forOwnerRef, ref, ownerRef, o, o); err != nil {
+		if err := r.populate(owners, ref, ownerRef, o, o); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (r *sdk) populate(forOwnerRefNameMap map[string]string, forOwnerRef, objRef, ownerRef *corev1.ObjectReference, x any, relatedObject *fn.KubeObject) error {
+// forOwners keeps track of the for resources per forOwnerRef, used to link the watches
+// of a forOwner to the specific for resources
+// a forOwner can own a for resource per for kind
+type forOwners struct {
+	// kinds are the GVKs of the forOwnerRefs
+	kinds map[corev1.ObjectReference]struct{}
+	// forRefs are the for resources per forOwnerRef
+	forRefs map[corev1.ObjectReference][]corev1.ObjectReference
+}
+
+func newForOwners() *forOwners {
+	return &forOwners{
+		kinds:   map[corev1.ObjectReference]struct{}{},
+		forRefs: map[corev1.ObjectReference][]corev1.ObjectReference{},
+	}
+}
+
+func (r *forOwners) add(ownerRef, forRef corev1.ObjectReference) {
+	r.kinds[corev1.ObjectReference{APIVersion: ownerRef.APIVersion, Kind: ownerRef.Kind}] = struct{}{}
+	ownerRef = corev1.ObjectReference{APIVersion: ownerRef.APIVersion, Kind: ownerRef.Kind, Name: ownerRef.Name}
+	r.forRefs[ownerRef] = append(r.forRefs[ownerRef], forRef)
+}
+
+// isOwnerKind returns true when the resource is of the kind of a forOwnerRef
+func (r *forOwners) isOwnerKind(objRef *corev1.ObjectReference) bool {
+	if objRef == nil {
+		return false
+	}
+	_, ok := r.kinds[corev1.ObjectReference{APIVersion: objRef.APIVersion, Kind: objRef.Kind}]
+	return ok
+}
+
+// get returns the for resources of the forOwner
+func (r *forOwners) get(ownerRef corev1.ObjectReference) []corev1.ObjectReference {
+	return r.forRefs[corev1.ObjectReference{APIVersion: ownerRef.APIVersion, Kind: ownerRef.Kind, Name: ownerRef.Name}]
+}
+
+// validateUniqueInScope returns an error when a resource of the same kind and name was seen
+// with another owner, since the inventory, the conditions and the callbacks are keyed by kind and name
+func validateUniqueInScope(scopes map[corev1.ObjectReference]Scope, objRef corev1.ObjectReference, scope Scope) error {
+	if s, ok := scopes[objRef]; ok && s.Owner != scope.Owner {
+		return fmt.Errorf("resource %s exists for owner %s and owner %s, the names of a kind must be unique within a package",
+			kptfilelibv1.GetConditionType(&objRef), kptfilelibv1.GetConditionType(&s.Owner), kptfilelibv1.GetConditionType(&scope.Owner))
+	}
+	scopes[objRef] = scope
+	return nil
+}
+
+func (r *sdk) populate(owners *forOwners, objRef, ownerRef *corev1.ObjectReference, x any, relatedObject *fn.KubeObject) error {
 	// we lookup in the GVK context we initialized in the beginning to validate
 	// if the gvk is relevant for this fn/controller
 	// what the gvk Kind is about through the kindContext
@@ -101,6 +171,7 @@ func (r *sdk) populate(forOwnerRefNameMap map[string]string, forOwnerRef, objRef
 		if r.debug {
 			fn.Logf("stag1: set existing object in inventory, kind %s, ref: %v ownerRef: %v\n", gvkKindCtx.gvkKind, objRef, nil)
 		}
+		r.traceEvent(traceEventInventory, []corev1.ObjectReference{*objRef}, "existing %s %s", gvkKindCtx.gvkKind, traceSource(x))
 		if err := r.inv.set(gvkKindCtx, []corev1.ObjectReference{*objRef}, x, false, false); err != nil {
 			fn.Logf("stag1: cannot set existing object in the inventory: %v\n", err.Error())
 			//r.rl.Results = append(r.rl.Results, fn.ErrorConfigObjectResult(err, relatedObject))
@@ -121,37 +192,50 @@ func (r *sdk) populate(forOwnerRefNameMap map[string]string, forOwnerRef, objRef
 		if r.debug {
 			fn.Logf("stage1: set existing object in inventory, kind %s, ref: %v ownerRef: %v\n", gvkKindCtx.gvkKind, objRef, ownerRef)
 		}
+		r.traceEvent(traceEventInventory, []corev1.ObjectReference{*ownerRef, *objRef}, "existing %s %s", gvkKindCtx.gvkKind, traceSource(x))
 		if err := r.inv.set(gvkKindCtx, []corev1.ObjectReference{*ownerRef, *objRef}, x, false, false); err != nil {
 			fn.Logf("stage1: cannot set existing resource to the inventory: %v\n", err.Error())
 			//r.rl.Results = append(r.rl.Results, fn.ErrorConfigObjectResult(err, relatedObject))
 			return err
 		}
 	case watchGVKKind:
+		// resources of the watch that are not selected are not relevant for this fn/controller
+		if o, ok := x.(*fn.KubeObject); ok && !gvkKindCtx.selector.matches(o) {
+			r.traceEvent(traceEventInventory, []corev1.ObjectReference{*objRef}, "watch resource not selected")
+			if r.debug {
+				fn.Logf("stage1: populate watch not selected, ref: %v \n", objRef)
+			}
+			return nil
+		}
 		// check if the watch is specific or global
 		// if no forOwnerRef is set the watch is global
-		// if a forOwnerref is set we check if either the ownerRef or ref is match the GVK
+		// if a forOwnerref is set we check if either the ownerRef or ref is match the GVK of a forOwnerRef
 		// the specifics of the name is sorted out later
-		if forOwnerRef != nil && (forOwnerRef.APIVersion == ownerRef.APIVersion && forOwnerRef.Kind == ownerRef.Kind ||
-			forOwnerRef.APIVersion == objRef.APIVersion && forOwnerRef.Kind == objRef.Kind) {
+		if owners.isOwnerKind(ownerRef) || owners.isOwnerKind(objRef) {
 			// this is a specific watch
 
 			// The name is a bit complicated ->
 			// in general we take the ownerref
-			// when the forOwnerRef matches we take the name of the ref since the ownerref here is owned by another resource
-			// e.g. interface in NAD context is owned by nfdeploy, so we take the name of the ref iso ownerref
-			name := forOwnerRefNameMap[ownerRef.Name]
-			if forOwnerRef.APIVersion == objRef.APIVersion && forOwnerRef.Kind == objRef.Kind {
-				name = forOwnerRefNameMap[objRef.Name]
+			// when the forOwnerRef matches we take the ref since the ownerref here is owned by another resource
+			// e.g. interface in NAD context is owned by nfdeploy, so we take the ref iso ownerref
+			// The watch is added to the for resource of every for kind of the forOwner
+			forRefs := owners.get(*ownerRef)
+			if owners.isOwnerKind(objRef) {
+				forRefs = owners.get(*objRef)
 			}
-			forRef := &corev1.ObjectReference{APIVersion: r.cfg.For.APIVersion, Kind: r.cfg.For.Kind, Name: name}
-
-			if r.debug {
-				fn.Logf("stage1: set existing object in inventory, kind %s, forRef: %v, ref: %v ownerRef: %v\n", gvkKindCtx.gvkKind, forRef, objRef, ownerRef)
+			if len(forRefs) == 0 {
+				forRefs = []corev1.ObjectReference{{APIVersion: r.cfg.For[0].APIVersion, Kind: r.cfg.For[0].Kind}}
 			}
-			if err := r.inv.set(gvkKindCtx, []corev1.ObjectReference{*forRef, *objRef}, x, false, false); err != nil {
-				fn.Logf("stage1: cannot set existing resource to the inventory: %v\n", err.Error())
-				//r.rl.Results = append(r.rl.Results, fn.ErrorConfigObjectResult(err, relatedObject))
-				return err
+			for _, forRef := range forRefs {
+				if r.debug {
+					fn.Logf("stage1: set existing object in inventory, kind %s, forRef: %v, ref: %v ownerRef: %v\n", gvkKindCtx.gvkKind, forRef, objRef, ownerRef)
+				}
+				r.traceEvent(traceEventInventory, []corev1.ObjectReference{forRef, *objRef}, "existing specific %s %s", gvkKindCtx.gvkKind, traceSource(x))
+				if err := r.inv.set(gvkKindCtx, []corev1.ObjectReference{forRef, *objRef}, x, false, false); err != nil {
+					fn.Logf("stage1: cannot set existing resource to the inventory: %v\n", err.Error())
+					//r.rl.Results = append(r.rl.Results, fn.ErrorConfigObjectResult(err, relatedObject))
+					return err
+				}
 			}
 		} else {
 			// don't add a resource to the global watch if the ownerref was set, since this would be an intermediate
@@ -162,6 +246,7 @@ func (r *sdk) populate(forOwnerRefNameMap map[string]string, forOwnerRef, objRef
 				if r.debug {
 					fn.Logf("stage1: set existing object in inventory, kind %s, ref: %v ownerRef: %v\n", gvkKindCtx.gvkKind, objRef, nil)
 				}
+				r.traceEvent(traceEventInventory, []corev1.ObjectReference{*objRef}, "existing global %s %s", gvkKindCtx.gvkKind, traceSource(x))
 				if err := r.inv.set(gvkKindCtx, []corev1.ObjectReference{*objRef}, x, false, false); err != nil {
 					fn.Logf("stage1: cannot set existing resource to the inventory: %v\n", err.Error())
 					//r.rl.Results = append(r.rl.Results, fn.ErrorConfigObjectResult(err, relatedObject))
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// getMissingStatus returns the required status fields that are not yet populated in the own and
+// watch resources of the for resource, e.g. the prefix of an IPClaim that is not yet allocated
+func (r *sdk) getMissingStatus(objs fn.KubeObjects) []string {
+	missing := []string{}
+	for _, o := range objs {
+		fields := r.cfg.RequiredStatus[corev1.ObjectReference{APIVersion: o.GetAPIVersion(), Kind: o.GetKind()}]
+		for _, field := range fields {
+			// alternative fields are separated by |, one of them must be populated
+			found := false
+			alternatives := strings.Split(field, "|")
+			for _, f := range alternatives {
+				if hasStatusField(o, f) {
+					found = true
+				}
+			}
+			if !found {
+				missing = append(missing, fmt.Sprintf("status.%s of %s %s", strings.Join(alternatives, " or status."), o.GetKind(), o.GetName()))
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// hasStatusField returns true when the field, a dot separated path within the status, has a value
+func hasStatusField(o *fn.KubeObject, field string) bool {
+	b, err := yaml.YAMLToJSON([]byte(o.String()))
+	if err != nil {
+		return false
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return false
+	}
+	for _, f := range append([]string{"status"}, strings.Split(field, ".")...) {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return false
+		}
+		if v, ok = m[f]; !ok {
+			return false
+		}
+	}
+	switch x := v.(type) {
+	case nil:
+		return false
+	case string:
+		return x != ""
+	case float64:
+		return x != 0
+	case []any:
+		return len(x) != 0
+	case map[string]any:
+		return len(x) != 0
+	}
+	return true
+}
+
+// setWaitingCondition sets the condition of the for resource to false, waiting for the missing status
+// The reason of a for condition holding its owner is kept, otherwise the reason indicates the wait
+func (r *sdk) setWaitingCondition(forRef corev1.ObjectReference, forCondition *kptv1.Condition, missing []string) error {
+	detail := strings.Join(missing, ", ")
+	r.traceEvent(traceEventInventory, []corev1.ObjectReference{forRef}, "skip update, waiting for %s", detail)
+	results.Infof(r.rl, results.CodeWaitingForStatus, forRef, "%s %s is waiting for %s", forRef.Kind, forRef.Name, detail)
+	return r.setForCondition(forRef, forCondition, kptv1.ConditionFalse, ConditionReasonWaitingForDependency, "waiting for {{.Detail}}", detail)
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRequiredStatus(t *testing.T) {
+	cases := map[string]struct {
+		required        []string
+		status          string
+		expectedUpdate  bool
+		expectedMessage string
+	}{
+		"MissingStatus": {
+			status:          "",
+			expectedUpdate:  false,
+			expectedMessage: "waiting for status.prefix of C c1",
+		},
+		"EmptyStatusField": {
+			status: `
+  status:
+    prefix: ""`,
+			expectedUpdate:  false,
+			expectedMessage: "waiting for status.prefix of C c1",
+		},
+		"PopulatedStatus": {
+			status: `
+  status:
+    prefix: 10.0.0.1/24`,
+			expectedUpdate: true,
+		},
+		"MissingAlternativeStatus": {
+			required:        []string{"vlanID|vlanRange"},
+			status:          "",
+			expectedUpdate:  false,
+			expectedMessage: "waiting for status.vlanID or status.vlanRange of C c1",
+		},
+		"PopulatedAlternativeStatus": {
+			required: []string{"vlanID|vlanRange"},
+			status: `
+  status:
+    vlanRange: 100:109`,
+			expectedUpdate: true,
+		},
+	}
+
+	for name, tc := range cases {
+		if tc.required == nil {
+			tc.required = []string{"prefix"}
+		}
+		t.Run(name, func(t *testing.T) {
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+  status:
+    conditions:
+    - type: a.nephio.org/v1.A.a1
+      status: "False"
+      reason: x.nephio.org/v1.X.x1
+    - type: c.nephio.org/v1.C.c1
+      status: "True"
+      reason: x.nephio.org/v1.X.x1
+- apiVersion: c.nephio.org/v1
+  kind: C
+  metadata:
+    name: c1
+    annotations:
+      specializer.nephio.org/owner: x.nephio.org/v1.X.x1` + tc.status + `
+`))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			updated := false
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "c.nephio.org/v1", Kind: "C"}: nil,
+				},
+				RequiredStatus: map[corev1.ObjectReference][]string{
+					{APIVersion: "c.nephio.org/v1", Kind: "C"}: tc.required,
+				},
+				UpdateResourceFn: func(_ *fn.KubeObject, _ fn.KubeObjects) (fn.KubeObjects, error) {
+					updated = true
+					return nil, nil
+				},
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			assert.Equal(t, tc.expectedUpdate, updated)
+			if tc.expectedUpdate {
+				return
+			}
+			kf := kptfilelibv1.KptFile{Kptfile: rl.Items.GetRootKptfile()}
+			c := kf.GetCondition("a.nephio.org/v1.A.a1")
+			if assert.NotNil(t, c) {
+				assert.Equal(t, kptv1.ConditionFalse, c.Status)
+				assert.Equal(t, "x.nephio.org/v1.X.x1", c.Reason)
+				assert.Equal(t, tc.expectedMessage, c.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// addCallbackResult reports the coded error of a callback of the fn/controller as warning result
+// for the resource, such that tooling distinguishes e.g. invalid input from an unsupported request.
+// The failure itself is held by the condition of the for resource, hence the render does not fail;
+// errors without code are only reported in the condition
+func (r *sdk) addCallbackResult(err error, objRef corev1.ObjectReference) {
+	e, ok := results.AsError(err)
+	if !ok {
+		return
+	}
+	if e.Resource == nil {
+		e.WithRef(objRef)
+	}
+	results.WarningE(r.rl, err)
+}
@@ -0,0 +1,97 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/go-cmp/cmp"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCallbackResults(t *testing.T) {
+	forRef := corev1.ObjectReference{APIVersion: "a.nephio.org/v1", Kind: "A", Name: "a1"}
+	cases := map[string]struct {
+		populateErr error
+		want        []results.Result
+	}{
+		"Coded": {
+			populateErr: results.Errorf(results.CodeInvalidInput, "invalid a1"),
+			want: []results.Result{{
+				Code:        results.CodeInvalidInput,
+				Severity:    fn.Warning,
+				Message:     "invalid a1",
+				Resource:    &forRef,
+				Remediation: "correct the resource in the package",
+			}},
+		},
+		"CodedWithResource": {
+			populateErr: results.Errorf(results.CodeMissingResource, "w1 is missing").WithRef(corev1.ObjectReference{Kind: "W", Name: "w1"}),
+			want: []results.Result{{
+				Code:        results.CodeMissingResource,
+				Severity:    fn.Warning,
+				Message:     "w1 is missing",
+				Resource:    &corev1.ObjectReference{Kind: "W", Name: "w1"},
+				Remediation: "add the resource to the package",
+			}},
+		},
+		"Uncoded": {
+			populateErr: fmt.Errorf("invalid a1"),
+			want:        []results.Result{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+- apiVersion: a.nephio.org/v1
+  kind: A
+  metadata:
+    name: a1
+`))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Owns: map[corev1.ObjectReference]ResourceKind{
+					{APIVersion: "b.nephio.org/v1", Kind: "B"}: ChildRemote,
+				},
+				PopulateOwnResourcesFn: func(*fn.KubeObject) (fn.KubeObjects, error) { return nil, tc.populateErr },
+				UpdateResourceFn:       UpdateResourceFnNop,
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			if diff := cmp.Diff(tc.want, results.GetResults(rl.Results)); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
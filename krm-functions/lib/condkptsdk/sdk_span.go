@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/nephio-project/nephio/krm-functions/lib/tracing"
+)
+
+const (
+	// spanServiceName is the service name of the spans of the sdk, the OTEL_SERVICE_NAME environment overrides it
+	spanServiceName = "condkptsdk"
+	// spanExportTimeout bounds the export of the spans once the sdk has run
+	spanExportTimeout = 5 * time.Second
+)
+
+// runSpan traces the run of the sdk and its stages
+type runSpan struct {
+	tracer *tracing.Tracer
+	ctx    context.Context
+	run    *tracing.Span
+	stage  *tracing.Span
+}
+
+// startSpan starts the span of the run when the Kptfile of the package carries the trace context of
+// the specialization of the package, set by the controller driving it
+func (r *sdk) startSpan() {
+	kfko := r.rl.Items.GetRootKptfile()
+	if kfko == nil {
+		return
+	}
+	sc, err := tracing.ParseTraceParent(kfko.GetAnnotation(tracing.TraceParentAnnotation))
+	if err != nil {
+		return
+	}
+	forKinds := make([]string, 0, len(r.cfg.For))
+	for _, forRef := range r.cfg.For {
+		forKinds = append(forKinds, forRef.Kind)
+	}
+	tracer := tracing.NewTracerFromEnv(spanServiceName)
+	ctx, span := tracer.Start(tracing.ContextWithSpanContext(context.Background(), sc), "condkptsdk run "+strings.Join(forKinds, ","))
+	span.SetAttribute("package", kfko.GetName())
+	span.SetAttribute("for", strings.Join(forKinds, ","))
+	r.span = &runSpan{tracer: tracer, ctx: ctx, run: span}
+}
+
+// spanStage ends the span of the previous stage of the sdk pipeline and starts the span of the stage
+func (r *sdk) spanStage(stage string) {
+	if r.span == nil {
+		return
+	}
+	if r.span.stage != nil {
+		r.span.stage.End()
+	}
+	_, r.span.stage = r.span.tracer.Start(r.span.ctx, stage)
+}
+
+// endSpan ends the span of the run and exports the spans, an export error does not fail the run
+func (r *sdk) endSpan(err error) {
+	if r.span == nil {
+		return
+	}
+	if r.span.stage != nil {
+		r.span.stage.End()
+	}
+	r.span.run.RecordError(err)
+	r.span.run.End()
+	ctx, cancel := context.WithTimeout(context.Background(), spanExportTimeout)
+	defer cancel()
+	if err := r.span.tracer.Flush(ctx); err != nil {
+		fn.Logf("cannot export the spans of the run, err: %s\n", err.Error())
+	}
+	r.span = nil
+}
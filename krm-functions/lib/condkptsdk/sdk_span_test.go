@@ -0,0 +1,121 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/nephio-project/nephio/krm-functions/lib/tracing"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSpan(t *testing.T) {
+	cases := map[string]struct {
+		traceParent string
+		wantSpans   []string
+	}{
+		"NotTraced": {},
+		"InvalidTraceParent": {
+			traceParent: "invalid",
+		},
+		"Traced": {
+			traceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			wantSpans:   []string{"populate inventory", "global watches", "stage1", "stage2", "stale conditions", "readiness", "condkptsdk run A"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			type span struct {
+				TraceID      string `json:"traceId"`
+				ParentSpanID string `json:"parentSpanId"`
+				Name         string `json:"name"`
+			}
+			spans := []span{}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				body := struct {
+					ResourceSpans []struct {
+						ScopeSpans []struct {
+							Spans []span `json:"spans"`
+						} `json:"scopeSpans"`
+					} `json:"resourceSpans"`
+				}{}
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				for _, rs := range body.ResourceSpans {
+					for _, ss := range rs.ScopeSpans {
+						spans = append(spans, ss.Spans...)
+					}
+				}
+			}))
+			defer srv.Close()
+			t.Setenv(tracing.EndpointEnv, srv.URL)
+
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+- apiVersion: b.nephio.org/v1
+  kind: B
+  metadata:
+    name: b1
+`))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			if tc.traceParent != "" {
+				if err := rl.Items.GetRootKptfile().SetAnnotation(tracing.TraceParentAnnotation, tc.traceParent); err != nil {
+					t.Fatal(err)
+				}
+			}
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "b.nephio.org/v1", Kind: "B"}: func(*fn.KubeObject) error { return nil },
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			names := []string{}
+			for _, s := range spans {
+				names = append(names, s.Name)
+				assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", s.TraceID)
+			}
+			assert.Equal(t, len(tc.wantSpans), len(names))
+			if len(tc.wantSpans) != 0 {
+				assert.Equal(t, tc.wantSpans, names)
+				assert.Equal(t, "b7ad6b7169203331", spans[len(spans)-1].ParentSpanID)
+			}
+		})
+	}
+}
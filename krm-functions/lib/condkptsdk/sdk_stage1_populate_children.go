@@ -37,7 +37,9 @@ func (r *sdk) populateChildren() {
 		}
 		if r.cfg.PopulateOwnResourcesFn != nil && forObj != nil {
 			res, err := r.cfg.PopulateOwnResourcesFn(forObj)
+			r.traceCallback("PopulateOwnResourcesFn", []corev1.ObjectReference{forRef}, err)
 			if err != nil {
+				r.addCallbackResult(err, forRef)
 				msg := fmt.Sprintf("stage1: cannot populate new resource err: %v", err.Error())
 				// set the condition in the inventory and update the condition
 				if err := r.setCondition(forGVKKind, []corev1.ObjectReference{forRef}, msg, kptv1.ConditionFalse, true); err != nil {
@@ -56,7 +58,7 @@ func (r *sdk) populateChildren() {
 					if r.debug {
 						fn.Log(msg)
 					}
-					if err := r.kptfile.SetConditionRefFailed(forRef, msg); err != nil {
+					if err := r.conditions.SetConditionRefFailed(forRef, msg); err != nil {
 						// we continue but put the result in the resourcelist as this is the only way to convey the message
 						fn.Logf("stage1: cannot set the condition objRef: %s err: %v", ref.GetRefsString(forRef), err.Error())
 						r.rl.Results.ErrorE(err)
@@ -69,7 +71,17 @@ func (r *sdk) populateChildren() {
 				// set owner reference on the new resource
 				if err := newObj.SetAnnotation(SpecializerOwner, kptfilelibv1.GetConditionType(&forRef)); err != nil {
 					msg := fmt.Sprintf("stage1: cannot set new annotation objRef: %s, err: %v", ref.GetRefsString(forRef), err.Error())
-					if err := r.kptfile.SetConditionRefFailed(forRef, msg); err != nil {
+					if err := r.conditions.SetConditionRefFailed(forRef, msg); err != nil {
+						// we continue but put the result in the resourcelist as this is the only way to convey the message
+						fn.Logf("stage1: cannot set the condition objRef: %s, err: %v", ref.GetRefsString(forRef), err.Error())
+						r.rl.Results.ErrorE(err)
+					}
+					continue
+				}
+				// place the new resource in its sub-package
+				if err := r.setSubPackagePath(forObj, newObj); err != nil {
+					msg := fmt.Sprintf("stage1: cannot set sub-package objRef: %s, err: %v", ref.GetRefsString(forRef), err.Error())
+					if err := r.conditions.SetConditionRefFailed(forRef, msg); err != nil {
 						// we continue but put the result in the resourcelist as this is the only way to convey the message
 						fn.Logf("stage1: cannot set the condition objRef: %s, err: %v", ref.GetRefsString(forRef), err.Error())
 						r.rl.Results.ErrorE(err)
@@ -79,7 +91,7 @@ func (r *sdk) populateChildren() {
 				// add the resource to the existing list as a new resource
 				if err := r.inv.set(kc, []corev1.ObjectReference{forRef, objRef}, newObj, true, false); err != nil {
 					msg := fmt.Sprintf("stage1: cannot set new resource to the inventory objRef: %s, err: %v\n", ref.GetRefsString(forRef), err.Error())
-					if err := r.kptfile.SetConditionRefFailed(forRef, msg); err != nil {
+					if err := r.conditions.SetConditionRefFailed(forRef, msg); err != nil {
 						// we continue but put the result in the resourcelist as this is the only way to convey the message
 						fn.Logf("stage1: cannot set the condition objRef: %s, err: %v", ref.GetRefsString(forRef), err.Error())
 						r.rl.Results.ErrorE(err)
@@ -34,6 +34,7 @@ func (r *sdk) updateChildren() {
 	// if the fn is not ready we delete the for condition and its children
 	if !r.inv.isReady() {
 		for forRef, diff := range diffMap {
+			r.traceDiff(forRef, diff)
 			var e error
 			// delete the overall condition for the object
 			if diff.deleteForCondition {
@@ -64,7 +65,7 @@ func (r *sdk) updateChildren() {
 			}
 			// handle all errors and set them in the condition
 			if e != nil {
-				if err := r.kptfile.SetConditionRefFailed(forRef, e.Error()); err != nil {
+				if err := r.conditions.SetConditionRefFailed(forRef, e.Error()); err != nil {
 					// we continue but put the result in the resourcelist as this is the only way to convey the message
 					fn.Logf("stage1: cannot set the condition objRef: %s err: %v", ref.GetRefsString(forRef), err.Error())
 					r.rl.Results.ErrorE(err)
@@ -77,6 +78,7 @@ func (r *sdk) updateChildren() {
 	for _, forRef := range diffMapKeysInDeterministicOrder(diffMap) {
 		forRef := forRef // to get rid of the gosec error: G601 (CWE-118): Implicit memory aliasing in for loop.
 		diff := diffMap[forRef]
+		r.traceDiff(forRef, diff)
 
 		var e error
 		// update conditions
@@ -189,7 +191,7 @@ func (r *sdk) updateChildren() {
 		}
 		// handle all errors and set them in the condition
 		if e != nil {
-			if err := r.kptfile.SetConditionRefFailed(forRef, e.Error()); err != nil {
+			if err := r.conditions.SetConditionRefFailed(forRef, e.Error()); err != nil {
 				// we continue but put the result in the resourcelist as this is the only way to convey the message
 				fn.Logf("stage1: cannot set the condition objRef: %s err: %v", ref.GetRefsString(forRef), err.Error())
 				r.rl.Results.ErrorE(err)
@@ -18,9 +18,11 @@ package condkptsdk
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
 	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
 	"github.com/nephio-project/nephio/krm-functions/lib/ref"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -33,33 +35,53 @@ func (r *sdk) updateResources() {
 	if r.debug {
 		fn.Logf("updateResource isReady: %t\n", r.inv.isReady())
 	}
+	if r.cfg.GenerateFor {
+		r.deleteGeneratedOrphans()
+	}
 	if !r.inv.isReady() {
 		// when the overall status is not ready delete all resources
 		// TODO if we need to check the delete annotation
 		// TODO check if the owned resources were dynamic or static
+		// the for resources are deleted in a deterministic order, such that the results are stable
 		readyMap := r.inv.getReadyMap()
-		for _, readyCtx := range readyMap {
+		for _, forRef := range readyMapKeysInDeterministicOrder(readyMap) {
+			readyCtx := readyMap[forRef]
 			if readyCtx.forObj != nil {
 				if len(r.cfg.Owns) == 0 {
+					r.traceEvent(traceEventInventory, traceRefs(readyCtx.forObj), "delete for resource, not ready")
+					// the error is already logged and reported in the results, the resource is deleted anyhow
+					_ = r.callDeleteResource(readyCtx.forObj)
 					r.deleteObjFromResourceList(readyCtx.forObj)
 				}
 			}
+			if r.cfg.GenerateFor {
+				r.deleteGeneratedObjects(forRef, nil, "delete generated resource, not ready")
+			}
 		}
 		return
 	}
 	// the overall status is ready, so lets check the readiness map
+	// the for resources are updated in a deterministic order, such that the conditions
+	// in the Kptfile are rendered in the same order
 	readyMap := r.inv.getReadyMap()
-	for forRef, readyCtx := range readyMap {
+	for _, forRef := range readyMapKeysInDeterministicOrder(readyMap) {
+		readyCtx := readyMap[forRef]
 		if r.debug {
 			fn.Logf("updateResource readyMap: objRef %s, readyCtx: %v\n", ref.GetRefsString(forRef), readyCtx)
 		}
 		// if the for is not ready delete the object
 		if !readyCtx.ready || readyCtx.failed {
+			r.traceEvent(traceEventInventory, []corev1.ObjectReference{forRef}, "skip update, ready: %t, failed: %t", readyCtx.ready, readyCtx.failed)
 			/*
 				TODO defines what to do here
 			*/
 			continue
 		}
+		if r.cfg.GenerateFor && readyCtx.forCondition == nil {
+			// the fn/controller only generates the for resources requested by a for condition
+			r.traceEvent(traceEventInventory, []corev1.ObjectReference{forRef}, "skip update, no for condition")
+			continue
+		}
 		if r.cfg.UpdateResourceFn != nil {
 			objs := fn.KubeObjects{}
 			for _, o := range readyCtx.owns {
@@ -70,17 +92,31 @@ func (r *sdk) updateResources() {
 				x := o
 				objs = append(objs, &x)
 			}
+			// the update waits for the required status of the own and watch resources instead of
+			// the fn/controller failing on it
+			if missing := r.getMissingStatus(objs); len(missing) > 0 {
+				if err := r.setWaitingCondition(forRef, readyCtx.forCondition, missing); err != nil {
+					fn.Logf("set waiting condition, err: %s\n", err.Error())
+					r.rl.Results.ErrorE(err)
+				}
+				continue
+			}
 			// we can return multiple objects if the stage 2 generates additional resources
 			// when updating its status
 			newObjs, err := r.handleUpdateResource(forRef, readyCtx.forObj, readyCtx.forCondition, objs)
 			if err != nil {
 				fn.Logf("cannot handleUpdateResource objRef %s, err: %v\n", ref.GetRefsString(forRef), err.Error())
-				if err := r.kptfile.SetConditionRefFailed(forRef, err.Error()); err != nil {
+				r.addCallbackResult(err, forRef)
+				if err := r.setForCondition(forRef, readyCtx.forCondition, kptv1.ConditionFalse, GetErrorReason(err), "{{.Detail}}", err.Error()); err != nil {
 					fn.Logf("set condition failed error, err: %s\n", err.Error())
 					r.rl.Results.ErrorE(err)
 				}
 				continue
 			}
+			if r.cfg.GenerateFor {
+				r.updateGeneratedResources(forRef, readyCtx, newObjs)
+				continue
+			}
 
 			for _, newObj := range newObjs {
 				// need to do validation to check the returned object
@@ -95,7 +131,10 @@ func (r *sdk) updateResources() {
 				}
 				switch gvkKindCtx.gvkKind {
 				case forGVKKind:
-					if err := r.upsertChildObject(gvkKindCtx.gvkKind, []corev1.ObjectReference{forRef}, object{obj: *newObj}, readyCtx.forCondition, "update done", kptv1.ConditionTrue, true); err != nil {
+					// with multiple for kinds the fn/controller can return a resource of another for kind,
+					// which gets a condition of its own kind
+					newForRef, forCondition := getForRefAndCondition(forRef, *objRef, readyCtx.forCondition)
+					if err := r.upsertChildObject(gvkKindCtx.gvkKind, []corev1.ObjectReference{newForRef}, object{obj: *newObj}, forCondition, "update done", kptv1.ConditionTrue, true); err != nil {
 						fn.Logf("cannot update resourcelist and inventory after handleUpdateResource: objRef %s, err: %v\n", ref.GetRefsString(forRef), err.Error())
 					}
 				case ownGVKKind:
@@ -118,6 +157,7 @@ func (r *sdk) updateResources() {
 // by updating the condition and resource in kptfile/resourcelist
 func (r *sdk) handleUpdateResource(forRef corev1.ObjectReference, forObj *fn.KubeObject, forCondition *kptv1.Condition, objs fn.KubeObjects) (fn.KubeObjects, error) {
 	newObjs, err := r.cfg.UpdateResourceFn(forObj, objs)
+	r.traceCallback("UpdateResourceFn", []corev1.ObjectReference{forRef}, err)
 	if err != nil {
 		return newObjs, err
 	}
@@ -148,3 +188,31 @@ func (r *sdk) handleUpdateResource(forRef corev1.ObjectReference, forObj *fn.Kub
 	return newObjs, nil
 	//return r.handleUpdate(actionUpdate, forGVKKind, []corev1.ObjectReference{forRef}, object{obj: *newObj}, forCondition, kptv1.ConditionTrue, "done", true)
 }
+
+// getForRefAndCondition returns the for reference and the existing condition of a for resource
+// returned by the fn/controller. A resource of another for kind than the for reference is
+// recorded with the name of the for reference and the condition of the for reference as
+// template, such that every for kind has its own condition
+func getForRefAndCondition(forRef, objRef corev1.ObjectReference, forCondition *kptv1.Condition) (corev1.ObjectReference, *kptv1.Condition) {
+	if objRef.APIVersion == forRef.APIVersion && objRef.Kind == forRef.Kind {
+		return forRef, forCondition
+	}
+	newForRef := corev1.ObjectReference{APIVersion: objRef.APIVersion, Kind: objRef.Kind, Name: forRef.Name}
+	if forCondition == nil {
+		return newForRef, nil
+	}
+	c := *forCondition
+	c.Type = kptfilelibv1.GetConditionType(&newForRef)
+	return newForRef, &c
+}
+
+func readyMapKeysInDeterministicOrder(readyMap map[corev1.ObjectReference]*readyCtx) []corev1.ObjectReference {
+	keys := make([]corev1.ObjectReference, 0, len(readyMap))
+	for k := range readyMap {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	"github.com/nephio-project/nephio/krm-functions/lib/ref"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// deleteStaleConditions deletes the conditions of own resources which no longer exist in the
+// package and whose owner no longer exists either, such that repeated runs of the pipeline
+// don't accumulate dead conditions that block the approval of the package.
+// The owner is resolved from the condition reason; conditions owned by a resource of a kind
+// that is not a for kind of this fn/controller belong to another fn/controller and are kept.
+func (r *sdk) deleteStaleConditions() {
+	for _, c := range r.conditions.GetConditions() {
+		objRef := kptfilelibv1.GetGVKNFromConditionType(c.Type)
+		kindCtx, ok := r.inv.isGVKMatch(objRef)
+		if !ok || kindCtx.gvkKind != ownGVKKind {
+			continue
+		}
+		if _, ok := r.cfg.Owns[*ref.GetGVKRefFromGVKNref(objRef)]; !ok {
+			// the condition matched a wildcard own reference, hence it can belong to any resource
+			continue
+		}
+		if r.hasResource(*objRef) {
+			continue
+		}
+		ownerRef := kptfilelibv1.GetGVKNFromConditionType(c.Reason)
+		if ref.ValidateGVKNRef(*ownerRef) == nil {
+			ownerKindCtx, ok := r.inv.isGVKMatch(ownerRef)
+			if !ok || ownerKindCtx.gvkKind != forGVKKind {
+				continue
+			}
+			if r.hasResource(*ownerRef) || r.conditions.GetCondition(kptfilelibv1.GetConditionType(ownerRef)) != nil {
+				continue
+			}
+		}
+		if r.debug {
+			fn.Logf("delete stale condition: %s, reason: %s\n", c.Type, c.Reason)
+		}
+		r.traceConditionDelete(c.Type)
+		if err := r.conditions.DeleteCondition(c.Type); err != nil {
+			fn.Logf("cannot delete stale condition %s, err: %v\n", c.Type, err.Error())
+			r.rl.Results.ErrorE(err)
+		}
+	}
+}
+
+// hasResource returns true if the resource exists in the package
+func (r *sdk) hasResource(objRef corev1.ObjectReference) bool {
+	for _, o := range r.rl.Items {
+		if o.GetAPIVersion() == objRef.APIVersion && o.GetKind() == objRef.Kind && o.GetName() == objRef.Name {
+			return true
+		}
+	}
+	return false
+}
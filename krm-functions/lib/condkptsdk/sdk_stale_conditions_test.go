@@ -0,0 +1,84 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDeleteStaleConditions(t *testing.T) {
+	rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+  status:
+    conditions:
+    - type: b.nephio.org/v1.B.noOwner
+      status: "False"
+      message: delete resource
+    - type: b.nephio.org/v1.B.ownerDeleted
+      status: "False"
+      reason: a.nephio.org/v1.A.deleted
+    - type: b.nephio.org/v1.B.otherOwner
+      status: "False"
+      reason: x.nephio.org/v1.X.x1
+    - type: b.nephio.org/v1.B.resourceExists
+      status: "False"
+    - type: c.nephio.org/v1.C.notOwned
+      status: "False"
+- apiVersion: b.nephio.org/v1
+  kind: B
+  metadata:
+    name: resourceExists
+`))
+	if err != nil {
+		t.Fatalf("cannot parse resourcelist: %s", err.Error())
+	}
+	kptsdk, err := New(rl, &Config{
+		For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+		Owns: map[corev1.ObjectReference]ResourceKind{
+			{APIVersion: "b.nephio.org/v1", Kind: "B"}: ChildRemote,
+		},
+		UpdateResourceFn: UpdateResourceFnNop,
+	})
+	if err != nil {
+		t.Fatalf("cannot create sdk: %s", err.Error())
+	}
+	if _, err := kptsdk.Run(); err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+
+	kf := kptfilelibv1.KptFile{Kptfile: rl.Items.GetRootKptfile()}
+	cases := map[string]bool{
+		"b.nephio.org/v1.B.noOwner":        false,
+		"b.nephio.org/v1.B.ownerDeleted":   false,
+		"b.nephio.org/v1.B.otherOwner":     true,
+		"b.nephio.org/v1.B.resourceExists": true,
+		"c.nephio.org/v1.C.notOwned":       true,
+	}
+	for ct, expected := range cases {
+		assert.Equal(t, expected, kf.GetCondition(ct) != nil, ct)
+	}
+}
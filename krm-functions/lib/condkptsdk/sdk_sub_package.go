@@ -0,0 +1,47 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+)
+
+// SubPackageFn returns the directory of the sub-package, relative to the package, the own resource
+// populated for the for resource is placed in, e.g. per NF or per cluster; the resource stays in the
+// package when the directory is empty
+type SubPackageFn func(forObj, ownObj *fn.KubeObject) string
+
+// setSubPackagePath sets the path annotation of the own resource to a file in its sub-package
+func (r *sdk) setSubPackagePath(forObj, ownObj *fn.KubeObject) error {
+	if r.cfg.SubPackageFn == nil {
+		return nil
+	}
+	dir := r.cfg.SubPackageFn(forObj, ownObj)
+	if dir == "" {
+		return nil
+	}
+	dir = path.Clean(dir)
+	if path.IsAbs(dir) || dir == "." || dir == ".." || strings.HasPrefix(dir, "../") {
+		return fmt.Errorf("invalid sub-package %s for %s %s, expecting a directory within the package", dir, ownObj.GetKind(), ownObj.GetName())
+	}
+	return ownObj.SetAnnotation(kioutil.PathAnnotation, path.Join(dir, fmt.Sprintf("%s_%s.yaml", strings.ToLower(ownObj.GetKind()), ownObj.GetName())))
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestSubPackageFn(t *testing.T) {
+	cases := map[string]struct {
+		subPackage   string
+		expectedPath string
+		errExpected  bool
+	}{
+		"NoSubPackage": {
+			expectedPath: "",
+		},
+		"SubPackage": {
+			subPackage:   "clusters/a1",
+			expectedPath: "clusters/a1/b_a1.yaml",
+		},
+		"CleanedSubPackage": {
+			subPackage:   "./clusters//a1/",
+			expectedPath: "clusters/a1/b_a1.yaml",
+		},
+		"OutsidePackage": {
+			subPackage:  "../a1",
+			errExpected: true,
+		},
+		"AbsolutePath": {
+			subPackage:  "/a1",
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+- apiVersion: a.nephio.org/v1
+  kind: A
+  metadata:
+    name: a1
+`))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Owns: map[corev1.ObjectReference]ResourceKind{
+					{APIVersion: "b.nephio.org/v1", Kind: "B"}: ChildRemote,
+				},
+				PopulateOwnResourcesFn: func(forObj *fn.KubeObject) (fn.KubeObjects, error) {
+					o := fn.NewEmptyKubeObject()
+					if err := o.SetAPIVersion("b.nephio.org/v1"); err != nil {
+						return nil, err
+					}
+					if err := o.SetKind("B"); err != nil {
+						return nil, err
+					}
+					if err := o.SetName(forObj.GetName()); err != nil {
+						return nil, err
+					}
+					return fn.KubeObjects{o}, nil
+				},
+				SubPackageFn: func(_, _ *fn.KubeObject) string {
+					return tc.subPackage
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			owns := rl.Items.Where(fn.IsGroupVersionKind(schema.GroupVersionKind{Group: "b.nephio.org", Version: "v1", Kind: "B"}))
+			if tc.errExpected {
+				assert.Equal(t, 0, owns.Len())
+				kf := kptfilelibv1.KptFile{Kptfile: rl.Items.GetRootKptfile()}
+				c := kf.GetCondition("a.nephio.org/v1.A.a1")
+				if assert.NotNil(t, c) {
+					assert.Equal(t, kptv1.ConditionFalse, c.Status)
+				}
+				return
+			}
+			if assert.Equal(t, 1, owns.Len()) {
+				assert.Equal(t, tc.expectedPath, owns[0].PathAnnotation())
+			}
+		})
+	}
+}
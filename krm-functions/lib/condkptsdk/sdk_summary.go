@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SummaryEnv enables the summary result of the sdk when set to true
+	SummaryEnv = "SPECIALIZER_SUMMARY"
+	// SummaryKey enables the summary result of the sdk when set to true in the data of the function config
+	SummaryKey = "summary"
+)
+
+// summary records the metrics of a sdk run, which are reported as a result at the end of the run
+type summary struct {
+	start          time.Time
+	forResources   int
+	ownResources   int
+	watchResources int
+	conditions     *countingConditionStore
+}
+
+// countingConditionStore counts the conditions that are set and deleted in the condition store
+type countingConditionStore struct {
+	ConditionStore
+	set     int
+	deleted int
+}
+
+func (r *countingConditionStore) SetConditions(cs ...kptv1.Condition) error {
+	r.set += len(cs)
+	return r.ConditionStore.SetConditions(cs...)
+}
+
+func (r *countingConditionStore) SetConditionRefFailed(ref corev1.ObjectReference, msg string) error {
+	r.set++
+	return r.ConditionStore.SetConditionRefFailed(ref, msg)
+}
+
+func (r *countingConditionStore) DeleteCondition(ct string) error {
+	r.deleted++
+	return r.ConditionStore.DeleteCondition(ct)
+}
+
+// isSummaryEnabled returns true when the summary is enabled in the function config or the environment
+func isSummaryEnabled(fc *fn.KubeObject) bool {
+	if os.Getenv(SummaryEnv) == "true" {
+		return true
+	}
+	if fc == nil {
+		return false
+	}
+	v, _, _ := fc.NestedString("data", SummaryKey)
+	return v == "true"
+}
+
+// initSummary starts the summary; the condition store is wrapped to count the condition updates
+func (r *sdk) initSummary() {
+	r.summary = &summary{
+		start:      time.Now(),
+		conditions: &countingConditionStore{ConditionStore: r.conditions},
+	}
+	r.conditions = r.summary.conditions
+}
+
+// summarizeInventory records the number of for, own and watch resources in the inventory
+func (r *sdk) summarizeInventory() {
+	if r.summary == nil {
+		return
+	}
+	forResCtxs := r.inv.get(forGVKKind, []corev1.ObjectReference{{}})
+	r.summary.forResources = len(forResCtxs)
+	r.summary.watchResources = len(r.inv.get(watchGVKKind, []corev1.ObjectReference{{}}))
+	for forRef := range forResCtxs {
+		r.summary.ownResources += len(r.inv.get(ownGVKKind, []corev1.ObjectReference{forRef, {}}))
+		r.summary.watchResources += len(r.inv.get(watchGVKKind, []corev1.ObjectReference{forRef, {}}))
+	}
+}
+
+// writeSummary adds the summary of the run to the results; the resources that are created, updated
+// and deleted are derived from the resources of the package before the run
+func (r *sdk) writeSummary(before fn.KubeObjects) {
+	resources := map[planAction]int{}
+	for _, res := range getPlanResources(before, r.rl.Items) {
+		resources[res.Action]++
+	}
+	duration := time.Since(r.summary.start)
+	r.rl.Results = append(r.rl.Results, &fn.Result{
+		Severity: fn.Info,
+		Message: fmt.Sprintf("summary: for resources: %d, own resources: %d, watch resources: %d, conditions set: %d, conditions deleted: %d, resources created: %d, updated: %d, deleted: %d, duration: %s",
+			r.summary.forResources, r.summary.ownResources, r.summary.watchResources,
+			r.summary.conditions.set, r.summary.conditions.deleted,
+			resources[planActionCreate], resources[planActionUpdate], resources[planActionDelete],
+			duration.Round(time.Millisecond)),
+		Tags: map[string]string{
+			"forResources":      strconv.Itoa(r.summary.forResources),
+			"ownResources":      strconv.Itoa(r.summary.ownResources),
+			"watchResources":    strconv.Itoa(r.summary.watchResources),
+			"conditionsSet":     strconv.Itoa(r.summary.conditions.set),
+			"conditionsDeleted": strconv.Itoa(r.summary.conditions.deleted),
+			"resourcesCreated":  strconv.Itoa(resources[planActionCreate]),
+			"resourcesUpdated":  strconv.Itoa(resources[planActionUpdate]),
+			"resourcesDeleted":  strconv.Itoa(resources[planActionDelete]),
+			"durationMs":        strconv.FormatInt(duration.Milliseconds(), 10),
+		},
+	})
+}
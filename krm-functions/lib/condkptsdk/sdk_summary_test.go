@@ -0,0 +1,148 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestSummary(t *testing.T) {
+	cases := map[string]struct {
+		functionConfig string
+		env            string
+		plan           bool
+		expected       bool
+	}{
+		"Disabled": {
+			expected: false,
+		},
+		"FunctionConfig": {
+			functionConfig: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fn-config
+data:
+  summary: "true"
+`,
+			expected: true,
+		},
+		"Env": {
+			env:      "true",
+			expected: true,
+		},
+		"Plan": {
+			functionConfig: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fn-config
+data:
+  summary: "true"
+  plan: "true"
+`,
+			plan:     true,
+			expected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(SummaryEnv, tc.env)
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+  status:
+    conditions:
+    - type: a.nephio.org/v1.A.x
+      status: "False"
+      reason: o.nephio.org/v1.O.x
+- apiVersion: b.nephio.org/v1
+  kind: B
+  metadata:
+    name: b1
+`))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			if tc.functionConfig != "" {
+				if rl.FunctionConfig, err = fn.ParseKubeObject([]byte(tc.functionConfig)); err != nil {
+					t.Fatalf("cannot parse function config: %s", err.Error())
+				}
+			}
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "b.nephio.org/v1", Kind: "B"}: func(*fn.KubeObject) error { return nil },
+				},
+				UpdateResourceFn: func(_ *fn.KubeObject, _ fn.KubeObjects) (fn.KubeObjects, error) {
+					o := fn.NewEmptyKubeObject()
+					if err := o.SetAPIVersion("a.nephio.org/v1"); err != nil {
+						return nil, err
+					}
+					if err := o.SetKind("A"); err != nil {
+						return nil, err
+					}
+					if err := o.SetName("x"); err != nil {
+						return nil, err
+					}
+					return fn.KubeObjects{o}, nil
+				},
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			var summary *fn.Result
+			for _, res := range rl.Results {
+				if strings.HasPrefix(res.Message, "summary:") {
+					summary = res
+				}
+			}
+			if !tc.expected {
+				assert.Nil(t, summary)
+				return
+			}
+			if !assert.NotNil(t, summary) {
+				return
+			}
+			assert.Equal(t, fn.Info, summary.Severity)
+			assert.Equal(t, "1", summary.Tags["forResources"])
+			assert.Equal(t, "0", summary.Tags["ownResources"])
+			assert.Equal(t, "1", summary.Tags["watchResources"])
+			assert.Equal(t, "1", summary.Tags["conditionsSet"])
+			assert.Equal(t, "0", summary.Tags["conditionsDeleted"])
+			// in plan mode the summary reports the changes before the resourceList is restored
+			assert.Equal(t, "1", summary.Tags["resourcesCreated"])
+			assert.Equal(t, "0", summary.Tags["resourcesUpdated"])
+			assert.Equal(t, "0", summary.Tags["resourcesDeleted"])
+			assert.NotEmpty(t, summary.Tags["durationMs"])
+			forObjs := rl.Items.Where(fn.IsGroupVersionKind(schema.GroupVersionKind{Group: "a.nephio.org", Version: "v1", Kind: "A"}))
+			assert.Equal(t, tc.plan, forObjs.Len() == 0)
+		})
+	}
+}
@@ -17,10 +17,17 @@
 package condkptsdk
 
 import (
+	"fmt"
+	"sort"
 	"testing"
 
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestNew(t *testing.T) {
@@ -30,7 +37,7 @@ func TestNew(t *testing.T) {
 	}{
 		"Normal": {
 			input: &Config{
-				For: corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 				Owns: map[corev1.ObjectReference]ResourceKind{
 					{APIVersion: "b", Kind: "b"}: ChildRemote,
 				},
@@ -49,13 +56,148 @@ func TestNew(t *testing.T) {
 		},
 		"GenerateResourceFn": {
 			input: &Config{
-				For: corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
+			},
+			errExpected: true,
+		},
+		"MultipleFor": {
+			input: &Config{
+				For: []corev1.ObjectReference{
+					{APIVersion: "a", Kind: "a"},
+					{APIVersion: "d", Kind: "d"},
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			},
+			errExpected: false,
+		},
+		"DuplicateFor": {
+			input: &Config{
+				For: []corev1.ObjectReference{
+					{APIVersion: "a", Kind: "a"},
+					{APIVersion: "a", Kind: "a"},
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			},
+			errExpected: true,
+		},
+		"WatchSelectorWithoutWatch": {
+			input: &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
+				WatchSelectors: map[corev1.ObjectReference]WatchSelector{
+					{APIVersion: "c", Kind: "c"}: {Namespace: "default"},
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			},
+			errExpected: true,
+		},
+		"InvalidWatchSelector": {
+			input: &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "c", Kind: "c"}: nil,
+				},
+				WatchSelectors: map[corev1.ObjectReference]WatchSelector{
+					{APIVersion: "c", Kind: "c"}: {LabelSelector: &metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "a", Operator: "invalid"}},
+					}},
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			},
+			errExpected: true,
+		},
+		"WatchSelectorReferencedByWithoutWatch": {
+			input: &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "c", Kind: "c"}: nil,
+				},
+				WatchSelectors: map[corev1.ObjectReference]WatchSelector{
+					{APIVersion: "c", Kind: "c"}: {ReferencedBy: &WatchReference{
+						Ref:        corev1.ObjectReference{APIVersion: "d", Kind: "d"},
+						Annotation: "d",
+					}},
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			},
+			errExpected: true,
+		},
+		"WatchSchemaWithoutWatch": {
+			input: &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
+				WatchSchemas: map[corev1.ObjectReference]any{
+					{APIVersion: "c", Kind: "c"}: testWatchSchema{},
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			},
+			errExpected: true,
+		},
+		"InvalidWatchSchema": {
+			input: &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "c", Kind: "c"}: nil,
+				},
+				WatchSchemas: map[corev1.ObjectReference]any{
+					{APIVersion: "c", Kind: "c"}: "invalid",
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			},
+			errExpected: true,
+		},
+		"WatchDependencyCycle": {
+			input: &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "c", Kind: "c"}: nil,
+					{APIVersion: "d", Kind: "d"}: nil,
+				},
+				WatchDependencies: map[corev1.ObjectReference][]corev1.ObjectReference{
+					{APIVersion: "c", Kind: "c"}: {{APIVersion: "d", Kind: "d"}},
+					{APIVersion: "d", Kind: "d"}: {{APIVersion: "c", Kind: "c"}},
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			},
+			errExpected: true,
+		},
+		"WatchDependencyWithoutWatch": {
+			input: &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "c", Kind: "c"}: nil,
+				},
+				WatchDependencies: map[corev1.ObjectReference][]corev1.ObjectReference{
+					{APIVersion: "c", Kind: "c"}: {{APIVersion: "d", Kind: "d"}},
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			},
+			errExpected: true,
+		},
+		"RequiredStatusWithoutWatch": {
+			input: &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
+				RequiredStatus: map[corev1.ObjectReference][]string{
+					{APIVersion: "c", Kind: "c"}: {"prefix"},
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			},
+			errExpected: true,
+		},
+		"RequiredStatusEmptyField": {
+			input: &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "c", Kind: "c"}: nil,
+				},
+				RequiredStatus: map[corev1.ObjectReference][]string{
+					{APIVersion: "c", Kind: "c"}: {""},
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
 			},
 			errExpected: true,
 		},
 		"DuplicateGVK1": {
 			input: &Config{
-				For: corev1.ObjectReference{APIVersion: "a", Kind: "a"},
+				For: []corev1.ObjectReference{{APIVersion: "a", Kind: "a"}},
 				Owns: map[corev1.ObjectReference]ResourceKind{
 					{APIVersion: "b", Kind: "b"}: ChildRemote,
 				},
@@ -80,3 +222,630 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestSetCondition(t *testing.T) {
+	kf, err := fn.ParseKubeObject([]byte(`apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: pkg
+`))
+	if err != nil {
+		t.Fatalf("cannot parse kptfile: %s", err.Error())
+	}
+	cases := map[string]struct {
+		kptfile     kptfilelibv1.KptFile
+		errExpected bool
+	}{
+		"Running": {
+			kptfile:     kptfilelibv1.KptFile{Kptfile: kf},
+			errExpected: false,
+		},
+		"NotRunning": {
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &sdk{kptfile: tc.kptfile}
+			if tc.kptfile.Kptfile != nil {
+				// Run resolves the condition store
+				r.conditions = &r.kptfile
+			}
+			c := kptv1.Condition{Type: "a/b", Status: kptv1.ConditionTrue, Reason: "c"}
+			err := r.SetCondition(c)
+			if tc.errExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, &c, r.kptfile.GetCondition(c.Type))
+			}
+			err = r.DeleteCondition(c.Type)
+			if tc.errExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Nil(t, r.kptfile.GetCondition(c.Type))
+			}
+		})
+	}
+}
+
+func TestDeleteResourceFn(t *testing.T) {
+	cases := map[string]struct {
+		deleteErr   error
+		errExpected bool
+	}{
+		"Deleted": {
+			errExpected: false,
+		},
+		"DeleteFailed": {
+			deleteErr:   fmt.Errorf("cleanup failed"),
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+- apiVersion: a.nephio.org/v1
+  kind: A
+  metadata:
+    name: a1
+- apiVersion: b.nephio.org/v1
+  kind: B
+  metadata:
+    name: b1
+`))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			deleted := []string{}
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					// the watch rejects the resource, hence the sdk is not ready and deletes the for resource
+					{APIVersion: "b.nephio.org/v1", Kind: "B"}: func(*fn.KubeObject) error { return fmt.Errorf("not ready") },
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+				DeleteResourceFn: func(o *fn.KubeObject) error {
+					deleted = append(deleted, o.GetName())
+					return tc.deleteErr
+				},
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			assert.Equal(t, []string{"a1"}, deleted)
+			assert.Equal(t, 0, rl.Items.Where(fn.IsGroupVersionKind(schema.GroupVersionKind{Group: "a.nephio.org", Version: "v1", Kind: "A"})).Len())
+			hasErr := false
+			for _, r := range rl.Results {
+				if r.Severity == fn.Error {
+					hasErr = true
+				}
+			}
+			assert.Equal(t, tc.errExpected, hasErr)
+		})
+	}
+}
+
+func TestMultipleFor(t *testing.T) {
+	rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+  status:
+    conditions:
+    - type: a.nephio.org/v1.A.x
+      status: "False"
+      reason: o.nephio.org/v1.O.x
+`))
+	if err != nil {
+		t.Fatalf("cannot parse resourcelist: %s", err.Error())
+	}
+	kptsdk, err := New(rl, &Config{
+		For: []corev1.ObjectReference{
+			{APIVersion: "a.nephio.org/v1", Kind: "A"},
+			{APIVersion: "c.nephio.org/v1", Kind: "C"},
+		},
+		UpdateResourceFn: func(_ *fn.KubeObject, _ fn.KubeObjects) (fn.KubeObjects, error) {
+			objs := fn.KubeObjects{}
+			for _, gvk := range []corev1.ObjectReference{
+				{APIVersion: "a.nephio.org/v1", Kind: "A"},
+				{APIVersion: "c.nephio.org/v1", Kind: "C"},
+			} {
+				o := fn.NewEmptyKubeObject()
+				if err := o.SetAPIVersion(gvk.APIVersion); err != nil {
+					return nil, err
+				}
+				if err := o.SetKind(gvk.Kind); err != nil {
+					return nil, err
+				}
+				if err := o.SetName("x-generated"); err != nil {
+					return nil, err
+				}
+				objs = append(objs, o)
+			}
+			return objs, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("cannot create sdk: %s", err.Error())
+	}
+	if _, err := kptsdk.Run(); err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+	kf := kptfilelibv1.KptFile{Kptfile: rl.Items.GetRootKptfile()}
+	// every for kind gets its own condition, owned by the owner of the for resource
+	for _, ct := range []string{"a.nephio.org/v1.A.x", "c.nephio.org/v1.C.x"} {
+		c := kf.GetCondition(ct)
+		if assert.NotNil(t, c, ct) {
+			assert.Equal(t, kptv1.ConditionTrue, c.Status)
+			assert.Equal(t, "o.nephio.org/v1.O.x", c.Reason)
+		}
+	}
+	assert.Equal(t, 1, rl.Items.Where(fn.IsGroupVersionKind(schema.GroupVersionKind{Group: "c.nephio.org", Version: "v1", Kind: "C"})).Len())
+}
+
+func TestWatchSelectors(t *testing.T) {
+	cases := map[string]struct {
+		selector WatchSelector
+		expected []string
+	}{
+		"Namespace": {
+			selector: WatchSelector{Namespace: "ns1"},
+			expected: []string{"b1", "b2"},
+		},
+		"LabelSelector": {
+			selector: WatchSelector{LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"nephio.org/site": "edge1"}}},
+			expected: []string{"b1", "b3"},
+		},
+		"Annotations": {
+			selector: WatchSelector{Annotations: map[string]string{"nephio.org/purpose": "ran"}},
+			expected: []string{"b2"},
+		},
+		"All": {
+			selector: WatchSelector{
+				Namespace:     "ns1",
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"nephio.org/site": "edge1"}},
+			},
+			expected: []string{"b1"},
+		},
+		"ReferencedBy": {
+			selector: WatchSelector{ReferencedBy: &WatchReference{
+				Ref:        corev1.ObjectReference{APIVersion: "c.nephio.org/v1", Kind: "C"},
+				Annotation: "nephio.org/b",
+			}},
+			expected: []string{"b1", "b3"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+- apiVersion: b.nephio.org/v1
+  kind: B
+  metadata:
+    name: b1
+    namespace: ns1
+    labels:
+      nephio.org/site: edge1
+- apiVersion: b.nephio.org/v1
+  kind: B
+  metadata:
+    name: b2
+    namespace: ns1
+    annotations:
+      nephio.org/purpose: ran
+- apiVersion: b.nephio.org/v1
+  kind: B
+  metadata:
+    name: b3
+    namespace: ns2
+    labels:
+      nephio.org/site: edge1
+- apiVersion: c.nephio.org/v1
+  kind: C
+  metadata:
+    name: c1
+    annotations:
+      nephio.org/b: b1
+- apiVersion: c.nephio.org/v1
+  kind: C
+  metadata:
+    name: c2
+    annotations:
+      nephio.org/b: b3
+`))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			watched := []string{}
+			bRef := corev1.ObjectReference{APIVersion: "b.nephio.org/v1", Kind: "B"}
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					bRef: func(o *fn.KubeObject) error {
+						watched = append(watched, o.GetName())
+						return nil
+					},
+					{APIVersion: "c.nephio.org/v1", Kind: "C"}: nil,
+				},
+				WatchSelectors:   map[corev1.ObjectReference]WatchSelector{bRef: tc.selector},
+				UpdateResourceFn: UpdateResourceFnNop,
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			sort.Strings(watched)
+			assert.Equal(t, tc.expected, watched)
+		})
+	}
+}
+
+func TestWatchDependencies(t *testing.T) {
+	cases := map[string]struct {
+		items         string
+		expected      []string
+		expectedError string
+	}{
+		"Ordered": {
+			items: `- apiVersion: c.nephio.org/v1
+  kind: Interface
+  metadata:
+    name: c1
+- apiVersion: b.nephio.org/v1
+  kind: Network
+  metadata:
+    name: b1
+- apiVersion: d.nephio.org/v1
+  kind: WorkloadCluster
+  metadata:
+    name: d1
+`,
+			expected: []string{"d1", "b1", "c1"},
+		},
+		"MissingPrerequisite": {
+			items: `- apiVersion: c.nephio.org/v1
+  kind: Interface
+  metadata:
+    name: c1
+- apiVersion: b.nephio.org/v1
+  kind: Network
+  metadata:
+    name: b1
+`,
+			expected:      []string{},
+			expectedError: "Network depends on WorkloadCluster, which is missing from the kpt package",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+- apiVersion: a.nephio.org/v1
+  kind: A
+  metadata:
+    name: a1
+` + tc.items))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			called := []string{}
+			callback := func(o *fn.KubeObject) error {
+				called = append(called, o.GetName())
+				return nil
+			}
+			wcRef := corev1.ObjectReference{APIVersion: "d.nephio.org/v1", Kind: "WorkloadCluster"}
+			networkRef := corev1.ObjectReference{APIVersion: "b.nephio.org/v1", Kind: "Network"}
+			itfceRef := corev1.ObjectReference{APIVersion: "c.nephio.org/v1", Kind: "Interface"}
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					wcRef:      callback,
+					networkRef: callback,
+					itfceRef:   callback,
+				},
+				WatchDependencies: map[corev1.ObjectReference][]corev1.ObjectReference{
+					networkRef: {wcRef},
+					itfceRef:   {networkRef},
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			assert.Equal(t, tc.expected, called)
+			if tc.expectedError != "" {
+				kf := kptfilelibv1.KptFile{Kptfile: rl.Items.GetRootKptfile()}
+				c := kf.GetCondition("a.nephio.org/v1.A.a1")
+				if assert.NotNil(t, c) {
+					assert.Equal(t, tc.expectedError, c.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestOwnerScope(t *testing.T) {
+	cases := map[string]struct {
+		items         string
+		expected      map[string][]string
+		expectedError string
+	}{
+		"OwnersWithSameName": {
+			items: `- apiVersion: o.nephio.org/v1
+  kind: O
+  metadata:
+    name: x
+- apiVersion: p.nephio.org/v1
+  kind: P
+  metadata:
+    name: x
+`,
+			expected: map[string][]string{
+				"a1": {"O"},
+				"a2": {"P"},
+			},
+		},
+		"DuplicateNameOtherOwner": {
+			items: `- apiVersion: o.nephio.org/v1
+  kind: O
+  metadata:
+    name: x
+    annotations:
+      specializer.nephio.org/owner: workload.nephio.org/v1alpha1.UPFDeployment.upf
+- apiVersion: o.nephio.org/v1
+  kind: O
+  metadata:
+    name: x
+    annotations:
+      specializer.nephio.org/owner: workload.nephio.org/v1alpha1.SMFDeployment.smf
+`,
+			expected:      map[string][]string{},
+			expectedError: "stage1: cannot populate inventory, err: resource o.nephio.org/v1.O.x exists for owner workload.nephio.org/v1alpha1.UPFDeployment.upf and owner workload.nephio.org/v1alpha1.SMFDeployment.smf, the names of a kind must be unique within a package",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+  status:
+    conditions:
+    - type: a.nephio.org/v1.A.a1
+      status: "False"
+      reason: o.nephio.org/v1.O.x
+    - type: a.nephio.org/v1.A.a2
+      status: "False"
+      reason: p.nephio.org/v1.P.x
+- apiVersion: a.nephio.org/v1
+  kind: A
+  metadata:
+    name: a1
+` + tc.items))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			called := map[string][]string{}
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "o.nephio.org/v1", Kind: "O"}: nil,
+					{APIVersion: "p.nephio.org/v1", Kind: "P"}: nil,
+				},
+				UpdateResourceFn: func(forObj *fn.KubeObject, objs fn.KubeObjects) (fn.KubeObjects, error) {
+					// the for resource a2 does not exist yet
+					forName := "a2"
+					if forObj != nil {
+						forName = forObj.GetName()
+					}
+					for _, o := range objs {
+						called[forName] = append(called[forName], o.GetKind())
+					}
+					return nil, nil
+				},
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			// the watches are only provided to the for resource of their own owner
+			assert.Equal(t, tc.expected, called)
+			if tc.expectedError != "" {
+				kf := kptfilelibv1.KptFile{Kptfile: rl.Items.GetRootKptfile()}
+				c := kf.GetCondition("a.nephio.org/v1.A.a1")
+				if assert.NotNil(t, c) {
+					assert.Equal(t, tc.expectedError, c.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateFor(t *testing.T) {
+	cases := map[string]struct {
+		items              string
+		conditions         string
+		generate           []string
+		expected           []string
+		expectedDeleted    []string
+		expectedConditions map[string]string
+	}{
+		"Generated": {
+			generate: []string{"o-x", "o-x-extra"},
+			expected: []string{"o-x", "o-x-extra"},
+			expectedConditions: map[string]string{
+				"a.nephio.org/v1.A.x":         "o.nephio.org/v1.O.x",
+				"a.nephio.org/v1.A.o-x-extra": "a.nephio.org/v1.A.x",
+			},
+		},
+		"Regenerated": {
+			items: `- apiVersion: a.nephio.org/v1
+  kind: A
+  metadata:
+    name: o-x
+    annotations:
+      specializer.nephio.org/owner: o.nephio.org/v1.O.x
+- apiVersion: a.nephio.org/v1
+  kind: A
+  metadata:
+    name: o-x-extra
+    annotations:
+      specializer.nephio.org/owner: o.nephio.org/v1.O.x
+`,
+			conditions: `    - type: a.nephio.org/v1.A.o-x-extra
+      status: "True"
+      reason: a.nephio.org/v1.A.x
+`,
+			generate: []string{"o-x"},
+			expected: []string{"o-x"},
+			// the extra resource is no longer generated
+			expectedDeleted: []string{"o-x-extra"},
+			expectedConditions: map[string]string{
+				"a.nephio.org/v1.A.x": "o.nephio.org/v1.O.x",
+			},
+		},
+		"OwnerRemoved": {
+			items: `- apiVersion: a.nephio.org/v1
+  kind: A
+  metadata:
+    name: o-y
+    annotations:
+      specializer.nephio.org/owner: o.nephio.org/v1.O.y
+`,
+			generate:        []string{"o-x"},
+			expected:        []string{"o-x"},
+			expectedDeleted: []string{"o-y"},
+			expectedConditions: map[string]string{
+				"a.nephio.org/v1.A.x": "o.nephio.org/v1.O.x",
+			},
+		},
+		"NotGenerated": {
+			items: `- apiVersion: a.nephio.org/v1
+  kind: A
+  metadata:
+    name: user
+`,
+			generate: []string{"o-x"},
+			expected: []string{"o-x", "user"},
+			expectedConditions: map[string]string{
+				"a.nephio.org/v1.A.x": "o.nephio.org/v1.O.x",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+  status:
+    conditions:
+    - type: a.nephio.org/v1.A.x
+      status: "False"
+      reason: o.nephio.org/v1.O.x
+` + tc.conditions + `- apiVersion: o.nephio.org/v1
+  kind: O
+  metadata:
+    name: x
+` + tc.items))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			deleted := []string{}
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "o.nephio.org/v1", Kind: "O"}: nil,
+				},
+				UpdateResourceFn: func(_ *fn.KubeObject, _ fn.KubeObjects) (fn.KubeObjects, error) {
+					objs := fn.KubeObjects{}
+					for _, name := range tc.generate {
+						o := fn.NewEmptyKubeObject()
+						if err := o.SetAPIVersion("a.nephio.org/v1"); err != nil {
+							return nil, err
+						}
+						if err := o.SetKind("A"); err != nil {
+							return nil, err
+						}
+						if err := o.SetName(name); err != nil {
+							return nil, err
+						}
+						objs = append(objs, o)
+					}
+					return objs, nil
+				},
+				DeleteResourceFn: func(o *fn.KubeObject) error {
+					deleted = append(deleted, o.GetName())
+					return nil
+				},
+				GenerateFor: true,
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			names := []string{}
+			for _, o := range rl.Items.Where(fn.IsGroupVersionKind(schema.GroupVersionKind{Group: "a.nephio.org", Version: "v1", Kind: "A"})) {
+				names = append(names, o.GetName())
+			}
+			sort.Strings(names)
+			assert.Equal(t, tc.expected, names)
+			if tc.expectedDeleted == nil {
+				tc.expectedDeleted = []string{}
+			}
+			assert.Equal(t, tc.expectedDeleted, deleted)
+			kf := kptfilelibv1.KptFile{Kptfile: rl.Items.GetRootKptfile()}
+			conditions := map[string]string{}
+			for _, c := range kf.GetConditions() {
+				conditions[c.Type] = c.Reason
+				assert.Equal(t, kptv1.ConditionTrue, c.Status, c.Type)
+			}
+			assert.Equal(t, tc.expectedConditions, conditions)
+		})
+	}
+}
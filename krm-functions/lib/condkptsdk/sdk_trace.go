@@ -0,0 +1,215 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	"github.com/nephio-project/nephio/krm-functions/lib/ref"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// TraceEnv enables the trace report of the sdk when set to true
+	TraceEnv = "SPECIALIZER_TRACE"
+	// TraceKey enables the trace report of the sdk when set to true in the data of the function config
+	TraceKey = "trace"
+
+	traceAPIVersion = "specializer.nephio.org/v1alpha1"
+	traceKind       = "SpecializerTrace"
+)
+
+type traceEventType string
+
+const (
+	traceEventCallback  traceEventType = "callback"
+	traceEventInventory traceEventType = "inventory"
+	traceEventCondition traceEventType = "condition"
+)
+
+// trace is the report of a sdk run, it records every callback invocation, inventory decision
+// and condition transition
+type trace struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              traceSpec `json:"spec"`
+	// stage is the stage of the sdk pipeline being executed
+	stage string
+}
+
+type traceSpec struct {
+	// For defines the for kinds of the fn/controller that was traced
+	For []string `json:"for"`
+	// Events defines the events of the run in the order they happened
+	Events []traceEvent `json:"events,omitempty"`
+}
+
+type traceEvent struct {
+	// Stage defines the stage of the sdk pipeline the event happened in
+	Stage string `json:"stage"`
+	// Type defines the type of the event: callback, inventory or condition
+	Type traceEventType `json:"type"`
+	// Ref defines the references the event applies to
+	Ref string `json:"ref,omitempty"`
+	// Message describes the event
+	Message string `json:"message"`
+}
+
+// isTraceEnabled returns true when the trace is enabled in the function config or the environment
+func isTraceEnabled(fc *fn.KubeObject) bool {
+	if os.Getenv(TraceEnv) == "true" {
+		return true
+	}
+	if fc == nil {
+		return false
+	}
+	v, _, _ := fc.NestedString("data", TraceKey)
+	return v == "true"
+}
+
+func (r *sdk) initTrace() {
+	if !isTraceEnabled(r.rl.FunctionConfig) {
+		return
+	}
+	forKinds := make([]string, 0, len(r.cfg.For))
+	for _, forRef := range r.cfg.For {
+		forKinds = append(forKinds, kptfilelibv1.GetConditionType(&corev1.ObjectReference{APIVersion: forRef.APIVersion, Kind: forRef.Kind}))
+	}
+	r.trace = &trace{
+		TypeMeta: metav1.TypeMeta{APIVersion: traceAPIVersion, Kind: traceKind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-trace", strings.ToLower(r.cfg.For[0].Kind)),
+			Annotations: map[string]string{"config.kubernetes.io/local-config": "true"},
+		},
+		Spec: traceSpec{For: forKinds},
+	}
+}
+
+// traceStage sets the stage of the sdk pipeline the next events happen in
+func (r *sdk) traceStage(stage string) {
+	r.spanStage(stage)
+	if r.trace == nil {
+		return
+	}
+	r.trace.stage = stage
+}
+
+// traceEvent records an event in the trace, when the trace is enabled
+func (r *sdk) traceEvent(t traceEventType, refs []corev1.ObjectReference, format string, a ...any) {
+	if r.trace == nil {
+		return
+	}
+	e := traceEvent{Stage: r.trace.stage, Type: t, Message: fmt.Sprintf(format, a...)}
+	if len(refs) > 0 {
+		e.Ref = ref.GetRefsString(refs...)
+	}
+	r.trace.Spec.Events = append(r.trace.Spec.Events, e)
+}
+
+// traceCallback records the outcome of a fn/controller callback
+func (r *sdk) traceCallback(callback string, refs []corev1.ObjectReference, err error) {
+	if err != nil {
+		r.traceEvent(traceEventCallback, refs, "%s failed: %s", callback, err.Error())
+		return
+	}
+	r.traceEvent(traceEventCallback, refs, "%s succeeded", callback)
+}
+
+// traceCondition records the transition of a condition
+func (r *sdk) traceCondition(c kptv1.Condition) {
+	if r.trace == nil {
+		return
+	}
+	from := "none"
+	if ec := r.conditions.GetCondition(c.Type); ec != nil {
+		from = string(ec.Status)
+	}
+	r.traceEvent(traceEventCondition, nil, "%s: %s -> %s, reason: %s, message: %s", c.Type, from, c.Status, c.Reason, c.Message)
+}
+
+// traceConditionDelete records the deletion of a condition
+func (r *sdk) traceConditionDelete(ct string) {
+	r.traceEvent(traceEventCondition, nil, "%s: deleted", ct)
+}
+
+// traceRefs returns the reference of the object for the trace
+func traceRefs(o *fn.KubeObject) []corev1.ObjectReference {
+	if o == nil {
+		return nil
+	}
+	return []corev1.ObjectReference{{APIVersion: o.GetAPIVersion(), Kind: o.GetKind(), Name: o.GetName()}}
+}
+
+// traceSource describes if the inventory entry is populated from a condition or a resource
+func traceSource(x any) string {
+	if _, ok := x.(*fn.KubeObject); ok {
+		return "resource"
+	}
+	return "condition"
+}
+
+// traceDiff records the actions of the inventory diff of a for resource
+func (r *sdk) traceDiff(forRef corev1.ObjectReference, diff *inventoryDiff) {
+	if r.trace == nil {
+		return
+	}
+	if diff.deleteForCondition {
+		r.traceEvent(traceEventInventory, []corev1.ObjectReference{forRef}, "diff: delete for condition")
+	}
+	if diff.updateForCondition {
+		r.traceEvent(traceEventInventory, []corev1.ObjectReference{forRef}, "diff: update for condition")
+	}
+	actions := []struct {
+		action string
+		objs   []object
+	}{
+		{action: "create condition", objs: diff.createConditions},
+		{action: "delete condition", objs: diff.deleteConditions},
+		{action: "create resource", objs: diff.createObjs},
+		{action: "update resource", objs: diff.updateObjs},
+		{action: "delete resource", objs: diff.deleteObjs},
+		{action: "update delete annotation", objs: diff.updateDeleteAnnotations},
+	}
+	for _, a := range actions {
+		for _, obj := range a.objs {
+			r.traceEvent(traceEventInventory, []corev1.ObjectReference{forRef, obj.ref}, "diff: %s", a.action)
+		}
+	}
+}
+
+// writeTrace adds the trace report to the resourceList, replacing the report of a previous run
+func (r *sdk) writeTrace() {
+	if r.trace == nil {
+		return
+	}
+	o, err := fn.NewFromTypedObject(r.trace)
+	if err != nil {
+		fn.Logf("cannot create trace report, err: %s\n", err.Error())
+		r.rl.Results.ErrorE(err)
+		return
+	}
+	if err := r.rl.UpsertObjectToItems(o, nil, true); err != nil {
+		fn.Logf("cannot add trace report to the resourceList, err: %s\n", err.Error())
+		r.rl.Results.ErrorE(err)
+	}
+}
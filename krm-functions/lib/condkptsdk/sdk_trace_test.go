@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestTrace(t *testing.T) {
+	cases := map[string]struct {
+		functionConfig string
+		env            string
+		expected       bool
+	}{
+		"Disabled": {
+			expected: false,
+		},
+		"FunctionConfig": {
+			functionConfig: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fn-config
+data:
+  trace: "true"
+`,
+			expected: true,
+		},
+		"Env": {
+			env:      "true",
+			expected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(TraceEnv, tc.env)
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+  status:
+    conditions:
+    - type: a.nephio.org/v1.A.x
+      status: "False"
+      reason: o.nephio.org/v1.O.x
+- apiVersion: b.nephio.org/v1
+  kind: B
+  metadata:
+    name: b1
+`))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			if tc.functionConfig != "" {
+				if rl.FunctionConfig, err = fn.ParseKubeObject([]byte(tc.functionConfig)); err != nil {
+					t.Fatalf("cannot parse function config: %s", err.Error())
+				}
+			}
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "b.nephio.org/v1", Kind: "B"}: func(*fn.KubeObject) error { return nil },
+				},
+				UpdateResourceFn: func(_ *fn.KubeObject, _ fn.KubeObjects) (fn.KubeObjects, error) {
+					o := fn.NewEmptyKubeObject()
+					if err := o.SetAPIVersion("a.nephio.org/v1"); err != nil {
+						return nil, err
+					}
+					if err := o.SetKind("A"); err != nil {
+						return nil, err
+					}
+					if err := o.SetName("x"); err != nil {
+						return nil, err
+					}
+					return fn.KubeObjects{o}, nil
+				},
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			traces := rl.Items.Where(fn.IsGroupVersionKind(schema.GroupVersionKind{Group: "specializer.nephio.org", Version: "v1alpha1", Kind: traceKind}))
+			if !tc.expected {
+				assert.Equal(t, 0, traces.Len())
+				return
+			}
+			if !assert.Equal(t, 1, traces.Len()) {
+				return
+			}
+			assert.Equal(t, "a-trace", traces[0].GetName())
+			events, _, err := traces[0].NestedSlice("spec", "events")
+			assert.NoError(t, err)
+			messages := []string{}
+			for _, e := range events {
+				msg, _, _ := e.NestedString("message")
+				messages = append(messages, msg)
+			}
+			assert.Contains(t, messages, "WatchCallbackFn succeeded")
+			assert.Contains(t, messages, "UpdateResourceFn succeeded")
+			assert.Contains(t, messages, "a.nephio.org/v1.A.x: False -> True, reason: o.nephio.org/v1.O.x, message: update done")
+		})
+	}
+}
@@ -35,8 +35,13 @@ func (r *sdk) deleteChildObject(kind gvkKind, refs []corev1.ObjectReference, obj
 		return err
 	}
 	var e error
+	// inform the fn/controller the resource gets deleted
+	if err := r.callDeleteResource(&obj.obj); err != nil {
+		e = errors.Join(e, err)
+	}
 	// set the condition in the kptfile
-	if err := r.kptfile.SetConditions(c); err != nil {
+	r.traceCondition(c)
+	if err := r.conditions.SetConditions(c); err != nil {
 		// this is an internal error -> return
 		e = errors.Join(e, err)
 		fn.Logf("cannot set condition in kptfile objref: %s, err: %v\n", ref.GetRefsString(refs...), err.Error())
@@ -76,7 +81,8 @@ func (r *sdk) upsertChildObject(kind gvkKind, refs []corev1.ObjectReference, obj
 
 	var e error
 	// set the condition in the kptfile
-	if err := r.kptfile.SetConditions(c); err != nil {
+	r.traceCondition(c)
+	if err := r.conditions.SetConditions(c); err != nil {
 		// this is an internal error -> return
 		e = errors.Join(e, err)
 		fn.Logf("cannot set condition in kptfile objref: %s, err: %v\n", ref.GetRefsString(refs...), err.Error())
@@ -111,7 +117,8 @@ func (r *sdk) deleteCondition(kind gvkKind, refs []corev1.ObjectReference) error
 	}
 	var e error
 	// delete the condition from the kptfile
-	if err := r.kptfile.DeleteCondition(c.Type); err != nil {
+	r.traceConditionDelete(c.Type)
+	if err := r.conditions.DeleteCondition(c.Type); err != nil {
 		e = errors.Join(e, err)
 		fn.Logf("cannot delete condition from Kptfile objref: %s, err: %v\n", ref.GetRefsString(refs...), err.Error())
 		r.rl.Results.ErrorE(err)
@@ -135,7 +142,8 @@ func (r *sdk) setCondition(kind gvkKind, refs []corev1.ObjectReference, msg stri
 	}
 	var e error
 	// set the condition in the kptfile
-	if err := r.kptfile.SetConditions(c); err != nil {
+	r.traceCondition(c)
+	if err := r.conditions.SetConditions(c); err != nil {
 		e = errors.Join(e, err)
 		fn.Logf("cannot set condition in Kptfile objref: %s, err: %v\n", ref.GetRefsString(refs...), err.Error())
 		r.rl.Results.ErrorE(err)
@@ -186,6 +194,21 @@ func (r *sdk) setObjectInResourceList(kind gvkKind, refs []corev1.ObjectReferenc
 	return nil
 }
 
+// callDeleteResource performs the fn/controller delete callback if one is configured
+func (r *sdk) callDeleteResource(obj *fn.KubeObject) error {
+	if r.cfg.DeleteResourceFn == nil {
+		return nil
+	}
+	err := r.cfg.DeleteResourceFn(obj)
+	r.traceCallback("DeleteResourceFn", traceRefs(obj), err)
+	if err != nil {
+		fn.Logf("cannot delete resource objRef: %s, err: %v\n", ref.GetRefsString(corev1.ObjectReference{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(), Name: obj.GetName()}), err.Error())
+		r.rl.Results.ErrorE(err)
+		return err
+	}
+	return nil
+}
+
 func (r *sdk) deleteObjFromResourceList(obj *fn.KubeObject) {
 	for idx, o := range r.rl.Items {
 		if ref.IsGVKNNEqual(o, obj) {
@@ -0,0 +1,242 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"sigs.k8s.io/yaml"
+)
+
+// watchSchema validates the resources of a Watch entry against the Go type of the resource
+type watchSchema struct {
+	t reflect.Type
+}
+
+// schemaViolation defines a field of the resource that does not match the schema
+type schemaViolation struct {
+	path    string
+	message string
+}
+
+func (r schemaViolation) String() string {
+	return fmt.Sprintf("%s: %s", r.path, r.message)
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func newWatchSchema(s any) (*watchSchema, error) {
+	t := reflect.TypeOf(s)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expecting a go struct as schema, got: %v", reflect.TypeOf(s))
+	}
+	return &watchSchema{t: t}, nil
+}
+
+// validate returns the fields of the resource that do not match the schema; a nil schema matches all resources
+func (r *watchSchema) validate(o *fn.KubeObject) []schemaViolation {
+	if r == nil || o == nil {
+		return nil
+	}
+	b, err := yaml.YAMLToJSON([]byte(o.String()))
+	if err != nil {
+		return []schemaViolation{{path: ".", message: err.Error()}}
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return []schemaViolation{{path: ".", message: err.Error()}}
+	}
+	return validateSchemaValue("", v, r.t)
+}
+
+func joinSchemaPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return fmt.Sprintf("%s.%s", path, field)
+}
+
+// validateSchemaValue validates the json value at the path against the go type
+func validateSchemaValue(path string, v any, t reflect.Type) []schemaViolation {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if v == nil {
+		return nil
+	}
+	// types with a custom json encoding, e.g. metav1.Time, resource.Quantity, are validated by their decoder
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		b, err := json.Marshal(v)
+		if err == nil {
+			err = json.Unmarshal(b, reflect.New(t).Interface())
+		}
+		if err != nil {
+			return []schemaViolation{{path: path, message: fmt.Sprintf("invalid value %v for %s", v, t.Name())}}
+		}
+		return nil
+	}
+	mismatch := func(expected string) []schemaViolation {
+		return []schemaViolation{{path: path, message: fmt.Sprintf("expected %s, got %s", expected, getSchemaValueType(v))}}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return mismatch("object")
+		}
+		fields := map[string]reflect.Type{}
+		getSchemaFields(t, fields)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		violations := []schemaViolation{}
+		for _, k := range keys {
+			ft, ok := fields[k]
+			if !ok {
+				violations = append(violations, schemaViolation{path: joinSchemaPath(path, k), message: "unknown field"})
+				continue
+			}
+			violations = append(violations, validateSchemaValue(joinSchemaPath(path, k), m[k], ft)...)
+		}
+		return violations
+	case reflect.Map:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return mismatch("object")
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		violations := []schemaViolation{}
+		for _, k := range keys {
+			violations = append(violations, validateSchemaValue(joinSchemaPath(path, k), m[k], t.Elem())...)
+		}
+		return violations
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// bytes are encoded as base64 string
+			if _, ok := v.(string); !ok {
+				return mismatch("string")
+			}
+			return nil
+		}
+		l, ok := v.([]any)
+		if !ok {
+			return mismatch("array")
+		}
+		violations := []schemaViolation{}
+		for i, e := range l {
+			violations = append(violations, validateSchemaValue(fmt.Sprintf("%s[%d]", path, i), e, t.Elem())...)
+		}
+		return violations
+	case reflect.String:
+		if _, ok := v.(string); !ok {
+			return mismatch("string")
+		}
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			return mismatch("boolean")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, ok := v.(float64)
+		if !ok || f != float64(int64(f)) {
+			return mismatch("integer")
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := v.(float64); !ok {
+			return mismatch("number")
+		}
+	}
+	return nil
+}
+
+// getSchemaFields returns the json fields of the struct, the fields of inlined structs included
+func getSchemaFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			getSchemaFields(ft, fields)
+			continue
+		}
+		if opts == "inline" && ft.Kind() == reflect.Struct {
+			getSchemaFields(ft, fields)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+}
+
+func getSchemaValueType(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// validateWatchSchema reports the schema violations of the watch resource as results with the
+// field path of the violation and returns an error when the resource does not match the schema
+func (r *sdk) validateWatchSchema(s *watchSchema, o *fn.KubeObject) error {
+	violations := s.validate(o)
+	if len(violations) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(violations))
+	for _, v := range violations {
+		res := fn.ConfigObjectResult(v.message, o, fn.Error)
+		res.Field = &fn.Field{Path: v.path}
+		r.rl.Results = append(r.rl.Results, res)
+		msgs = append(msgs, v.String())
+	}
+	return fmt.Errorf("%s %s does not match the schema: %s", o.GetKind(), o.GetName(), strings.Join(msgs, "; "))
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type testWatchSchema struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              testWatchSchemaSpec `json:"spec,omitempty"`
+}
+
+type testWatchSchemaSpec struct {
+	Replicas *int              `json:"replicas,omitempty"`
+	Names    []string          `json:"names,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+func TestWatchSchemas(t *testing.T) {
+	cases := map[string]struct {
+		spec       string
+		violations []string
+	}{
+		"Valid": {
+			spec: `
+    replicas: 2
+    names:
+    - a
+    labels:
+      a: b`,
+		},
+		"InvalidType": {
+			spec: `
+    replicas: two`,
+			violations: []string{"spec.replicas"},
+		},
+		"UnknownField": {
+			spec: `
+    replica: 2`,
+			violations: []string{"spec.replica"},
+		},
+		"InvalidListEntry": {
+			spec: `
+    names:
+    - a
+    - 3`,
+			violations: []string{"spec.names[1]"},
+		},
+		"InvalidMapEntries": {
+			spec: `
+    labels:
+      b: true
+      a: 1`,
+			violations: []string{"spec.labels.a", "spec.labels.b"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+- apiVersion: b.nephio.org/v1
+  kind: B
+  metadata:
+    name: b1
+  spec:` + tc.spec + `
+`))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			called := false
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "b.nephio.org/v1", Kind: "B"}: func(*fn.KubeObject) error {
+						called = true
+						return nil
+					},
+				},
+				WatchSchemas: map[corev1.ObjectReference]any{
+					{APIVersion: "b.nephio.org/v1", Kind: "B"}: &testWatchSchema{},
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			// the callback is only called for resources matching the schema
+			assert.Equal(t, len(tc.violations) == 0, called)
+			violations := []string{}
+			for _, res := range rl.Results {
+				if res.Field != nil {
+					assert.Equal(t, fn.Error, res.Severity)
+					assert.Equal(t, "b1", res.ResourceRef.Name)
+					violations = append(violations, res.Field.Path)
+				}
+			}
+			if len(tc.violations) == 0 {
+				assert.Equal(t, 0, len(violations))
+				return
+			}
+			if diff := cmp.Diff(tc.violations, violations); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
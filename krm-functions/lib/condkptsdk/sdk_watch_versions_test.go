@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package condkptsdk
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWatchConvertedVersion(t *testing.T) {
+	cases := map[string]struct {
+		apiVersion     string
+		wantAPIVersion string
+	}{
+		"Hub": {
+			apiVersion:     "infra.nephio.org/v1alpha1",
+			wantAPIVersion: "infra.nephio.org/v1alpha1",
+		},
+		"Converted": {
+			apiVersion:     "infra.nephio.org/v1alpha2",
+			wantAPIVersion: "infra.nephio.org/v1alpha1",
+		},
+		"Unknown": {
+			apiVersion: "infra.nephio.org/v1beta1",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rl, err := fn.ParseResourceList([]byte(`apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: kpt.dev/v1
+  kind: Kptfile
+  metadata:
+    name: pkg
+- apiVersion: ` + tc.apiVersion + `
+  kind: WorkloadCluster
+  metadata:
+    name: edge01
+  spec:
+    clusterName: edge01
+`))
+			if err != nil {
+				t.Fatalf("cannot parse resourcelist: %s", err.Error())
+			}
+			gotAPIVersion := ""
+			kptsdk, err := New(rl, &Config{
+				For: []corev1.ObjectReference{{APIVersion: "a.nephio.org/v1", Kind: "A"}},
+				Watch: map[corev1.ObjectReference]WatchCallbackFn{
+					{APIVersion: "infra.nephio.org/v1alpha1", Kind: "WorkloadCluster"}: func(o *fn.KubeObject) error {
+						gotAPIVersion = o.GetAPIVersion()
+						return nil
+					},
+				},
+				UpdateResourceFn: UpdateResourceFnNop,
+			})
+			if err != nil {
+				t.Fatalf("cannot create sdk: %s", err.Error())
+			}
+			if _, err := kptsdk.Run(); err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			// the callback sees the resource in the version of the watch, the package is not modified
+			assert.Equal(t, tc.wantAPIVersion, gotAPIVersion)
+			assert.Equal(t, tc.apiVersion, rl.Items.Where(fn.IsGroupKind(schema.GroupKind{Group: "infra.nephio.org", Kind: "WorkloadCluster"}))[0].GetAPIVersion())
+		})
+	}
+}
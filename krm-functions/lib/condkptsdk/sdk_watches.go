@@ -17,28 +17,229 @@ limitations under the License.
 package condkptsdk
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // call the global watch callbacks to provide info to the fns in a generic way
 // so they don't have to parse the complete resourcelist
 // Also it provide readiness feedback when an error is returned
+// The callbacks are called per watch kind, in the order of the watch dependencies
 func (r *sdk) callGlobalWatches() error {
-	for _, resCtx := range r.inv.get(watchGVKKind, []corev1.ObjectReference{{}}) {
-		if r.debug {
-			fn.Logf("stage1: global watch: %v\n", resCtx.existingResource)
-		}
-		if resCtx.gvkKindCtx.callbackFn != nil {
-			if err := resCtx.gvkKindCtx.callbackFn(resCtx.existingResource); err != nil {
-				if r.debug {
-					fn.Logf("stage1: global watch returned an error %v\n", err.Error())
-				}
-				//r.rl.Results = append(r.rl.Results, fn.ErrorConfigObjectResult(err, resCtx.existingResource))
+	resCtxs := r.inv.get(watchGVKKind, []corev1.ObjectReference{{}})
+	watches := map[corev1.ObjectReference][]corev1.ObjectReference{}
+	for objRef := range resCtxs {
+		gvk := corev1.ObjectReference{APIVersion: objRef.APIVersion, Kind: objRef.Kind}
+		watches[gvk] = append(watches[gvk], objRef)
+	}
+	for _, gvk := range r.watchOrder {
+		// all prerequisite watch kinds must be present before the callbacks of the watch kind are called
+		if err := r.validateWatchDependencies(gvk); err != nil {
+			if r.debug {
+				fn.Logf("stage1: global watch dependency error %v\n", err.Error())
+			}
+			r.traceEvent(traceEventInventory, []corev1.ObjectReference{gvk}, "%s", err.Error())
+			r.inv.setReady(false)
+			return err
+		}
+		objRefs := watches[gvk]
+		sort.Slice(objRefs, func(i, j int) bool {
+			return objRefs[i].Name < objRefs[j].Name
+		})
+		for _, objRef := range objRefs {
+			resCtx := resCtxs[objRef]
+			if r.debug {
+				fn.Logf("stage1: global watch: %v\n", resCtx.existingResource)
+			}
+			// resources that don't match the schema are reported with the field path instead of
+			// failing in the callback while converting them
+			if err := r.validateWatchSchema(resCtx.gvkKindCtx.schema, resCtx.existingResource); err != nil {
+				r.traceEvent(traceEventInventory, traceRefs(resCtx.existingResource), "%s", err.Error())
 				r.inv.setReady(false)
 				return err
 			}
+			if resCtx.gvkKindCtx.callbackFn != nil {
+				err := resCtx.gvkKindCtx.callbackFn(resCtx.existingResource)
+				r.traceCallback("WatchCallbackFn", traceRefs(resCtx.existingResource), err)
+				if err != nil {
+					if r.debug {
+						fn.Logf("stage1: global watch returned an error %v\n", err.Error())
+					}
+					r.addCallbackResult(err, objRef)
+					r.inv.setReady(false)
+					return err
+				}
+			}
 		}
 	}
 	return nil
 }
+
+// validateWatchDependencies returns an error when the package has resources of the watch kind
+// while a watch kind it depends on has no resources in the package
+func (r *sdk) validateWatchDependencies(gvk corev1.ObjectReference) error {
+	deps := r.cfg.WatchDependencies[gvk]
+	if len(deps) == 0 || !r.hasWatchResources(gvk) {
+		return nil
+	}
+	for _, dep := range deps {
+		if !r.hasWatchResources(dep) {
+			return fmt.Errorf("%s depends on %s, which is missing from the kpt package", gvk.Kind, dep.Kind)
+		}
+	}
+	return nil
+}
+
+// hasWatchResources returns true if the package has selected resources of the watch kind
+func (r *sdk) hasWatchResources(gvk corev1.ObjectReference) bool {
+	kindCtx, ok := r.inv.isGVKMatch(&gvk)
+	for _, o := range r.rl.Items.Where(ko.IsGroupVersionKind(schema.FromAPIVersionAndKind(gvk.APIVersion, gvk.Kind))) {
+		if !ok || kindCtx.selector.matches(o) {
+			return true
+		}
+	}
+	return false
+}
+
+// convertWatchResource returns the resource converted to the api version of the watch when it is of
+// another version of a watch kind, e.g. a v1alpha2 WorkloadCluster for a v1alpha1 watch, such that the
+// schema and the callbacks see the version the fn was built with
+func (r *sdk) convertWatchResource(o *fn.KubeObject) (*fn.KubeObject, error) {
+	gvk := ko.HubGroupVersionKind(o.GroupVersionKind())
+	if gvk == o.GroupVersionKind() {
+		return o, nil
+	}
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	kindCtx, ok := r.inv.isGVKMatch(&corev1.ObjectReference{APIVersion: apiVersion, Kind: kind})
+	if !ok || kindCtx.gvkKind != watchGVKKind {
+		return o, nil
+	}
+	return ko.ConvertToHub(o)
+}
+
+// getWatchOrder returns the watch kinds in the order their callbacks are called, such that
+// the watch kinds another watch kind depends on come first. Independent watch kinds are
+// ordered alphabetically to make the order deterministic
+func getWatchOrder(cfg *Config) ([]corev1.ObjectReference, error) {
+	for gvk, deps := range cfg.WatchDependencies {
+		if _, ok := cfg.Watch[gvk]; !ok {
+			return nil, fmt.Errorf("watch dependency for %s without watch resource reference", gvk.Kind)
+		}
+		for _, dep := range deps {
+			if _, ok := cfg.Watch[dep]; !ok {
+				return nil, fmt.Errorf("watch dependency %s of %s without watch resource reference", dep.Kind, gvk.Kind)
+			}
+		}
+	}
+	gvks := make([]corev1.ObjectReference, 0, len(cfg.Watch))
+	for gvk := range cfg.Watch {
+		gvks = append(gvks, gvk)
+	}
+	sort.Slice(gvks, func(i, j int) bool {
+		return gvks[i].String() < gvks[j].String()
+	})
+
+	order := make([]corev1.ObjectReference, 0, len(gvks))
+	// visiting tracks the watch kinds being ordered to detect dependency cycles
+	visited := map[corev1.ObjectReference]bool{}
+	visiting := map[corev1.ObjectReference]bool{}
+	var visit func(gvk corev1.ObjectReference) error
+	visit = func(gvk corev1.ObjectReference) error {
+		if visited[gvk] {
+			return nil
+		}
+		if visiting[gvk] {
+			return fmt.Errorf("watch dependency cycle detected at %s", gvk.Kind)
+		}
+		visiting[gvk] = true
+		for _, dep := range cfg.WatchDependencies[gvk] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		visiting[gvk] = false
+		visited[gvk] = true
+		order = append(order, gvk)
+		return nil
+	}
+	for _, gvk := range gvks {
+		if err := visit(gvk); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// watchSelector is the parsed WatchSelector of a watch
+type watchSelector struct {
+	namespace    string
+	labels       labels.Selector
+	annotations  map[string]string
+	referencedBy *WatchReference
+	// referencedNames are the names referenced by the resources of the package, resolved per run
+	referencedNames map[string]bool
+}
+
+func newWatchSelector(s WatchSelector) (*watchSelector, error) {
+	r := &watchSelector{
+		namespace:    s.Namespace,
+		labels:       labels.Everything(),
+		annotations:  s.Annotations,
+		referencedBy: s.ReferencedBy,
+	}
+	if s.LabelSelector != nil {
+		var err error
+		if r.labels, err = metav1.LabelSelectorAsSelector(s.LabelSelector); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// matches returns true if the resource is selected, without selector all resources are selected
+func (r *watchSelector) matches(o *fn.KubeObject) bool {
+	if r == nil {
+		return true
+	}
+	if r.namespace != "" && o.GetNamespace() != r.namespace {
+		return false
+	}
+	if !r.labels.Matches(labels.Set(o.GetLabels())) {
+		return false
+	}
+	for k, v := range r.annotations {
+		if o.GetAnnotation(k) != v {
+			return false
+		}
+	}
+	if r.referencedBy != nil && !r.referencedNames[o.GetName()] {
+		return false
+	}
+	return true
+}
+
+// resolveWatchSelectors resolves the names the selectors by reference select from the selected
+// referencing resources in the package
+func (r *sdk) resolveWatchSelectors() {
+	for objRef := range r.cfg.WatchSelectors {
+		kindCtx, ok := r.inv.isGVKMatch(&objRef)
+		if !ok || kindCtx.selector == nil || kindCtx.selector.referencedBy == nil {
+			continue
+		}
+		refBy := kindCtx.selector.referencedBy
+		refKindCtx, _ := r.inv.isGVKMatch(&refBy.Ref)
+		kindCtx.selector.referencedNames = map[string]bool{}
+		for _, o := range r.rl.Items.Where(ko.IsGroupVersionKind(schema.FromAPIVersionAndKind(refBy.Ref.APIVersion, refBy.Ref.Kind))) {
+			if name := o.GetAnnotation(refBy.Annotation); name != "" && refKindCtx.selector.matches(o) {
+				kindCtx.selector.referencedNames[name] = true
+			}
+		}
+	}
+}
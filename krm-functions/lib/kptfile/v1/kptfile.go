@@ -126,10 +126,10 @@ func (r *KptFile) SetConditions(ncs ...kptv1.Condition) error {
 
 // DeleteCondition deletes the conditions from the list with a given type
 func (r *KptFile) DeleteCondition(ct string) error {
-	ecs := r.GetConditions()
-	for idx, c := range ecs {
-		if c.Type == ct {
-			ecs = append(ecs[:idx], ecs[idx+1:]...)
+	ecs := []kptv1.Condition{}
+	for _, c := range r.GetConditions() {
+		if c.Type != ct {
+			ecs = append(ecs, c)
 		}
 	}
 	return ko.SetNestedFieldKeepFormatting(r.Kptfile, ecs, statusFieldName, conditionsFieldName)
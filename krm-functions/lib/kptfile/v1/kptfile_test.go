@@ -332,8 +332,9 @@ func TestSetConditions(t *testing.T) {
 
 func TestDeleteCondition(t *testing.T) {
 	cases := map[string]struct {
-		t    []string
-		want []kptv1.Condition
+		input string
+		t     []string
+		want  []kptv1.Condition
 	}{
 		"First": {
 			t: []string{"a"},
@@ -351,6 +352,15 @@ func TestDeleteCondition(t *testing.T) {
 			t:    []string{"b", "a"},
 			want: []kptv1.Condition{},
 		},
+		"Duplicate": {
+			input: f2 + `  - type: b
+    status: "True"
+`,
+			t: []string{"b"},
+			want: []kptv1.Condition{
+				{Type: "a", Status: kptv1.ConditionFalse, Reason: "a", Message: "a"},
+			},
+		},
 		"Unknown": {
 			t: []string{"c"},
 			want: []kptv1.Condition{
@@ -362,7 +372,10 @@ func TestDeleteCondition(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			ko, err := fn.ParseKubeObject([]byte(f2))
+			if tc.input == "" {
+				tc.input = f2
+			}
+			ko, err := fn.ParseKubeObject([]byte(tc.input))
 			if err != nil {
 				assert.Error(t, err)
 			}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeobject
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+)
+
+// structCache holds the structs converted by CachedKubeObjectToStruct during the renders in
+// progress, it is emptied when the last render ends
+var structCache = &renderCache{}
+
+type renderCache struct {
+	m       sync.Mutex
+	renders int
+	structs map[structCacheKey]any
+}
+
+// structCacheKey keys a struct by its type and the KubeObject it is converted from, the KubeObjects
+// of a ResourceList are shared by the functions of a render
+type structCacheKey struct {
+	t   reflect.Type
+	obj *fn.KubeObject
+}
+
+// StartRender enables the cache of CachedKubeObjectToStruct until the returned func is called. A
+// render running several functions in one process, e.g. a request of the nephio-fn binary or of the
+// specializer server, starts a render such that a shared resource like the WorkloadCluster is
+// converted once per render rather than by every function
+func StartRender() (end func()) {
+	structCache.m.Lock()
+	defer structCache.m.Unlock()
+	if structCache.renders == 0 {
+		structCache.structs = map[structCacheKey]any{}
+	}
+	structCache.renders++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			structCache.m.Lock()
+			defer structCache.m.Unlock()
+			structCache.renders--
+			if structCache.renders == 0 {
+				structCache.structs = nil
+			}
+		})
+	}
+}
+
+// CachedKubeObjectToStruct is KubeObjectToStruct with a cache of the conversions of the KubeObject
+// during a render, see StartRender, outside of a render the KubeObject is converted on every call.
+// The cache is keyed by the KubeObject rather than its content, hence it is meant for the resources
+// the functions read but do not modify during the render.
+// The struct is deep copied when T has a DeepCopy method, otherwise it is shared and must not be modified
+func CachedKubeObjectToStruct[T any](obj *fn.KubeObject) (*T, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("cannot convert nil KubeObject")
+	}
+	key := structCacheKey{t: reflect.TypeOf((*T)(nil)).Elem(), obj: obj}
+	structCache.m.Lock()
+	defer structCache.m.Unlock()
+	if structCache.structs == nil {
+		return KubeObjectToStruct[T](obj)
+	}
+	if v, ok := structCache.structs[key]; ok {
+		return deepCopy(v.(*T)), nil
+	}
+	x, err := KubeObjectToStruct[T](obj)
+	if err != nil {
+		return x, err
+	}
+	structCache.structs[key] = x
+	return deepCopy(x), nil
+}
+
+func deepCopy[T any](x *T) *T {
+	if c, ok := any(x).(interface{ DeepCopy() *T }); ok {
+		return c.DeepCopy()
+	}
+	return x
+}
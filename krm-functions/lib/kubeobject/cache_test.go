@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeobject
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const cacheDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: upf
+spec:
+  replicas: 3
+`
+
+func TestCachedKubeObjectToStruct(t *testing.T) {
+	o, err := fn.ParseKubeObject([]byte(cacheDeployment))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	end := StartRender()
+	first, err := CachedKubeObjectToStruct[appsv1.Deployment](o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the cached struct is a copy, a change is not seen by the next conversion
+	first.Name = "changed"
+	second, err := CachedKubeObjectToStruct[appsv1.Deployment](o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Name != "upf" || *second.Spec.Replicas != 3 {
+		t.Errorf("unexpected cached struct: %s with %d replicas", second.Name, *second.Spec.Replicas)
+	}
+	if len(structCache.structs) != 1 {
+		t.Errorf("want 1 cached struct, got %d", len(structCache.structs))
+	}
+	end()
+	end()
+	if structCache.structs != nil || structCache.renders != 0 {
+		t.Errorf("want cache emptied at the end of the render, got %d structs of %d renders", len(structCache.structs), structCache.renders)
+	}
+
+	// outside of a render the KubeObject is converted on every call
+	if err := o.SetNestedInt(5, "spec", "replicas"); err != nil {
+		t.Fatal(err)
+	}
+	third, err := CachedKubeObjectToStruct[appsv1.Deployment](o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *third.Spec.Replicas != 5 {
+		t.Errorf("want 5 replicas, got %d", *third.Spec.Replicas)
+	}
+	if structCache.structs != nil {
+		t.Errorf("want no cache outside of a render")
+	}
+
+	if _, err := CachedKubeObjectToStruct[appsv1.Deployment](nil); err == nil {
+		t.Errorf("want error for nil KubeObject")
+	}
+}
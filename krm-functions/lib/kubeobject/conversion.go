@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeobject
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ConversionFunc converts the fields of a KubeObject to another api version of its kind. The
+// KubeObject is a copy with the apiVersion already set to the target version
+type ConversionFunc func(o *fn.KubeObject) error
+
+var (
+	conversionsMu sync.RWMutex
+	// conversions holds the conversion functions per source group-version-kind and target version
+	conversions = map[schema.GroupVersionKind]map[string]ConversionFunc{}
+	// hubVersions holds per kind the api version of the Go types the objects are decoded in
+	hubVersions = map[schema.GroupKind]string{}
+)
+
+// RegisterConversionFunc registers the conversion of the objects of the group-version-kind `from` to the
+// api version `toVersion` of the same kind. A nil `convertFn` converts versions with the same schema
+// by setting the apiVersion only.
+func RegisterConversionFunc(from schema.GroupVersionKind, toVersion string, convertFn ConversionFunc) {
+	conversionsMu.Lock()
+	defer conversionsMu.Unlock()
+	if conversions[from] == nil {
+		conversions[from] = map[string]ConversionFunc{}
+	}
+	conversions[from][toVersion] = convertFn
+}
+
+// RegisterHubVersion registers the api version of the Go type of a kind, the objects of the other versions
+// of the kind, with a conversion to the hub version, are converted by KubeObjectToStruct before decoding,
+// such that a function reads the versions of a resource it was not built with.
+func RegisterHubVersion(gvk schema.GroupVersionKind) {
+	conversionsMu.Lock()
+	defer conversionsMu.Unlock()
+	hubVersions[gvk.GroupKind()] = gvk.Version
+}
+
+// HubGroupVersionKind returns the group-version-kind the objects of `gvk` are decoded in, which is
+// `gvk` itself unless `gvk` converts to the hub version of its kind
+func HubGroupVersionKind(gvk schema.GroupVersionKind) schema.GroupVersionKind {
+	conversionsMu.RLock()
+	defer conversionsMu.RUnlock()
+	hub, ok := hubVersions[gvk.GroupKind()]
+	if !ok || hub == gvk.Version {
+		return gvk
+	}
+	if _, ok := conversions[gvk][hub]; !ok {
+		return gvk
+	}
+	return gvk.GroupKind().WithVersion(hub)
+}
+
+// IsGroupVersionKind is fn.IsGroupVersionKind matching also the objects of the other versions of the
+// kind that are converted to `gvk`
+func IsGroupVersionKind(gvk schema.GroupVersionKind) func(*fn.KubeObject) bool {
+	return func(o *fn.KubeObject) bool {
+		return HubGroupVersionKind(o.GroupVersionKind()) == gvk
+	}
+}
+
+// ConvertToHub returns the object converted to the hub version of its kind, the object itself when
+// it is in the hub version or has no conversion to it
+func ConvertToHub(o *fn.KubeObject) (*fn.KubeObject, error) {
+	if o == nil {
+		return nil, fmt.Errorf("cannot convert nil KubeObject")
+	}
+	return ConvertKubeObject(o, HubGroupVersionKind(o.GroupVersionKind()).Version)
+}
+
+// ConvertKubeObject returns a copy of the object converted to the api version `toVersion` of its
+// kind, or the object itself when it is already in that version
+func ConvertKubeObject(o *fn.KubeObject, toVersion string) (*fn.KubeObject, error) {
+	if o == nil {
+		return nil, fmt.Errorf("cannot convert nil KubeObject")
+	}
+	from := o.GroupVersionKind()
+	if from.Version == toVersion {
+		return o, nil
+	}
+	conversionsMu.RLock()
+	convertFn, ok := conversions[from][toVersion]
+	conversionsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no conversion of %s %s from %s to %s", from.Kind, o.GetName(), from.Version, toVersion)
+	}
+	x, err := fn.ParseKubeObject([]byte(o.String()))
+	if err != nil {
+		return nil, err
+	}
+	if err := x.SetAPIVersion(from.GroupKind().WithVersion(toVersion).GroupVersion().String()); err != nil {
+		return nil, err
+	}
+	if convertFn != nil {
+		if err := convertFn(x); err != nil {
+			return nil, fmt.Errorf("cannot convert %s %s from %s to %s: %s", from.Kind, o.GetName(), from.Version, toVersion, err.Error())
+		}
+	}
+	return x, nil
+}
+
+// the infra resources shared by the functions, e.g. the WorkloadCluster, are decoded in v1alpha1; v1alpha2
+// keeps the schema of v1alpha1 until its fields diverge, then the conversion functions move the fields
+func init() {
+	for _, kind := range []string{"WorkloadCluster", "Network"} {
+		v1alpha1 := schema.GroupVersionKind{Group: "infra.nephio.org", Version: "v1alpha1", Kind: kind}
+		RegisterHubVersion(v1alpha1)
+		RegisterConversionFunc(v1alpha1.GroupKind().WithVersion("v1alpha2"), v1alpha1.Version, nil)
+		RegisterConversionFunc(v1alpha1, "v1alpha2", nil)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeobject
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type widget struct {
+	APIVersion string `json:"apiVersion"`
+	Spec       struct {
+		Replicas int `json:"replicas"`
+	} `json:"spec"`
+}
+
+// widget v2 renamed spec.replicas of v1 to spec.size
+func init() {
+	v1 := schema.GroupVersionKind{Group: "example.nephio.org", Version: "v1", Kind: "Widget"}
+	RegisterHubVersion(v1)
+	RegisterConversionFunc(v1.GroupKind().WithVersion("v2"), v1.Version, func(o *fn.KubeObject) error {
+		size, _, err := o.NestedInt("spec", "size")
+		if err != nil {
+			return err
+		}
+		if _, err := o.RemoveNestedField("spec", "size"); err != nil {
+			return err
+		}
+		return o.SetNestedInt(size, "spec", "replicas")
+	})
+	RegisterConversionFunc(v1, "v2", func(o *fn.KubeObject) error {
+		replicas, _, err := o.NestedInt("spec", "replicas")
+		if err != nil {
+			return err
+		}
+		if _, err := o.RemoveNestedField("spec", "replicas"); err != nil {
+			return err
+		}
+		return o.SetNestedInt(replicas, "spec", "size")
+	})
+}
+
+func TestKubeObjectToStructConversion(t *testing.T) {
+	cases := map[string]struct {
+		yaml         string
+		wantReplicas int
+		wantError    bool
+	}{
+		"Hub": {
+			yaml:         "apiVersion: example.nephio.org/v1\nkind: Widget\nmetadata:\n  name: a\nspec:\n  replicas: 2\n",
+			wantReplicas: 2,
+		},
+		"Converted": {
+			yaml:         "apiVersion: example.nephio.org/v2\nkind: Widget\nmetadata:\n  name: a\nspec:\n  size: 3\n",
+			wantReplicas: 3,
+		},
+		"NoConversion": {
+			yaml:      "apiVersion: example.nephio.org/v3\nkind: Widget\nmetadata:\n  name: a\nspec:\n  size: 3\n",
+			wantError: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o, err := fn.ParseKubeObject([]byte(tc.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := KubeObjectToStruct[widget](o)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("KubeObjectToStruct() error = %v, wantError %v", err, tc.wantError)
+			}
+			if got.Spec.Replicas != tc.wantReplicas {
+				t.Errorf("replicas = %d, want %d", got.Spec.Replicas, tc.wantReplicas)
+			}
+		})
+	}
+}
+
+func TestConvertKubeObject(t *testing.T) {
+	o, err := fn.ParseKubeObject([]byte("apiVersion: example.nephio.org/v1\nkind: Widget\nmetadata:\n  name: a\nspec:\n  replicas: 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ConvertKubeObject(o, "v2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "apiVersion: example.nephio.org/v2\nkind: Widget\nmetadata:\n  name: a\nspec:\n  size: 2\n"
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+	// the object itself is not modified
+	if o.GetAPIVersion() != "example.nephio.org/v1" {
+		t.Errorf("the source object was modified: %s", o.GetAPIVersion())
+	}
+	if _, err := ConvertKubeObject(o, "v3"); err == nil {
+		t.Errorf("expected an error for a version without conversion")
+	}
+}
+
+func TestIsGroupVersionKind(t *testing.T) {
+	wc := schema.GroupVersionKind{Group: "infra.nephio.org", Version: "v1alpha1", Kind: "WorkloadCluster"}
+	cases := map[string]struct {
+		apiVersion string
+		want       bool
+	}{
+		"v1alpha1": {apiVersion: "infra.nephio.org/v1alpha1", want: true},
+		"v1alpha2": {apiVersion: "infra.nephio.org/v1alpha2", want: true},
+		"v1beta1":  {apiVersion: "infra.nephio.org/v1beta1", want: false},
+		"Group":    {apiVersion: "example.nephio.org/v1alpha1", want: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o := fn.NewEmptyKubeObject()
+			_ = o.SetAPIVersion(tc.apiVersion)
+			_ = o.SetKind("WorkloadCluster")
+			if got := IsGroupVersionKind(wc)(o); got != tc.want {
+				t.Errorf("IsGroupVersionKind() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
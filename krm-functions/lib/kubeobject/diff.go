@@ -0,0 +1,179 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeobject
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultDiffIgnorePaths are the fields populated by the api server, which are always ignored by Diff
+var DefaultDiffIgnorePaths = []string{
+	"metadata.managedFields",
+	"metadata.creationTimestamp",
+	"metadata.generation",
+	"metadata.resourceVersion",
+	"metadata.uid",
+}
+
+// DiffOptions defines the options of Diff
+type DiffOptions struct {
+	// IgnorePaths defines additional dot separated field paths that are ignored, e.g. "status" or
+	// "metadata.annotations". A path ignores the field and all the fields nested in it.
+	IgnorePaths []string
+}
+
+type FieldDiffType string
+
+const (
+	FieldAdded   FieldDiffType = "added"
+	FieldRemoved FieldDiffType = "removed"
+	FieldChanged FieldDiffType = "changed"
+)
+
+// FieldDiff defines a field that differs between 2 KubeObjects
+type FieldDiff struct {
+	// Path defines the dot separated path of the field, list items are referenced by index, e.g. spec.containers[0].image
+	Path string
+	Type FieldDiffType
+	// Old defines the value of the field in the first KubeObject, nil when the field was added
+	Old any
+	// New defines the value of the field in the second KubeObject, nil when the field was removed
+	New any
+}
+
+func (r FieldDiff) String() string {
+	return fmt.Sprintf("%s %s", r.Path, r.Type)
+}
+
+// Diff compares 2 KubeObjects semantically, so formatting, comments and the order of the fields are not relevant.
+// The fields populated by the api server (DefaultDiffIgnorePaths) and the IgnorePaths of the options are ignored.
+// Diff returns the list of fields that differ, sorted by path; an empty list means the KubeObjects are equal.
+func Diff(a, b *fn.KubeObject, opts *DiffOptions) ([]FieldDiff, error) {
+	if a == nil || b == nil {
+		return nil, fmt.Errorf("cannot diff a nil KubeObject")
+	}
+	av, err := toJSONValue(a)
+	if err != nil {
+		return nil, err
+	}
+	bv, err := toJSONValue(b)
+	if err != nil {
+		return nil, err
+	}
+	ignorePaths := append([]string{}, DefaultDiffIgnorePaths...)
+	if opts != nil {
+		ignorePaths = append(ignorePaths, opts.IgnorePaths...)
+	}
+	diffs := diffValues("", av, bv, ignorePaths, []FieldDiff{})
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs, nil
+}
+
+// toJSONValue returns the KubeObject as generic json value
+func toJSONValue(o *fn.KubeObject) (any, error) {
+	b, err := yaml.YAMLToJSON([]byte(o.String()))
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %s %s to json: %s", o.GetKind(), o.GetName(), err.Error())
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("cannot convert %s %s to json: %s", o.GetKind(), o.GetName(), err.Error())
+	}
+	return v, nil
+}
+
+// diffValues appends the differences between the json values `a` and `b` at `path` to `diffs` recursively
+func diffValues(path string, a, b any, ignorePaths []string, diffs []FieldDiff) []FieldDiff {
+	if isIgnoredPath(path, ignorePaths) {
+		return diffs
+	}
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := map[string]bool{}
+		for k := range av {
+			keys[k] = true
+		}
+		for k := range bv {
+			keys[k] = true
+		}
+		for k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			ak, aok := av[k]
+			bk, bok := bv[k]
+			switch {
+			case !aok:
+				if !isIgnoredPath(p, ignorePaths) {
+					diffs = append(diffs, FieldDiff{Path: p, Type: FieldAdded, New: bk})
+				}
+			case !bok:
+				if !isIgnoredPath(p, ignorePaths) {
+					diffs = append(diffs, FieldDiff{Path: p, Type: FieldRemoved, Old: ak})
+				}
+			default:
+				diffs = diffValues(p, ak, bk, ignorePaths, diffs)
+			}
+		}
+		return diffs
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(av) || i < len(bv); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(av):
+				diffs = append(diffs, FieldDiff{Path: p, Type: FieldAdded, New: bv[i]})
+			case i >= len(bv):
+				diffs = append(diffs, FieldDiff{Path: p, Type: FieldRemoved, Old: av[i]})
+			default:
+				diffs = diffValues(p, av[i], bv[i], ignorePaths, diffs)
+			}
+		}
+		return diffs
+	}
+	if !reflect.DeepEqual(a, b) {
+		diffs = append(diffs, FieldDiff{Path: path, Type: FieldChanged, Old: a, New: b})
+	}
+	return diffs
+}
+
+// isIgnoredPath returns true when the path is one of the ignore paths or nested in one of them
+func isIgnoredPath(path string, ignorePaths []string) bool {
+	for _, p := range ignorePaths {
+		if path == p || strings.HasPrefix(path, p+".") || strings.HasPrefix(path, p+"[") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeobject
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiff(t *testing.T) {
+	base := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+  labels:
+    app: a
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: a
+        image: a:1
+`
+	testcases := map[string]struct {
+		b        string
+		opts     *DiffOptions
+		expected []FieldDiff
+	}{
+		"Equal": {
+			b: `# comment
+kind: Deployment
+apiVersion: apps/v1
+metadata:
+  labels:
+    app: a # comment
+  name: a
+spec:
+  template:
+    spec:
+      containers:
+      - image: a:1
+        name: a
+  replicas: 3
+`,
+			expected: []FieldDiff{},
+		},
+		"ServerPopulatedFields": {
+			b: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+  labels:
+    app: a
+  creationTimestamp: "2023-06-01T00:00:00Z"
+  generation: 2
+  resourceVersion: "1234"
+  managedFields:
+  - manager: kubectl
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: a
+        image: a:1
+`,
+			expected: []FieldDiff{},
+		},
+		"ChangedFields": {
+			b: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: a
+        image: a:2
+      - name: b
+        image: b:1
+`,
+			expected: []FieldDiff{
+				{Path: "metadata.labels", Type: FieldRemoved, Old: map[string]any{"app": "a"}},
+				{Path: "spec.replicas", Type: FieldChanged, Old: float64(3), New: float64(2)},
+				{Path: "spec.template.spec.containers[0].image", Type: FieldChanged, Old: "a:1", New: "a:2"},
+				{Path: "spec.template.spec.containers[1]", Type: FieldAdded, New: map[string]any{"name": "b", "image": "b:1"}},
+			},
+		},
+		"IgnorePaths": {
+			b: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+  labels:
+    app: b
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: a
+        image: a:1
+status:
+  replicas: 2
+`,
+			opts:     &DiffOptions{IgnorePaths: []string{"metadata.labels", "spec.replicas", "status"}},
+			expected: []FieldDiff{},
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			a, err := fn.ParseKubeObject([]byte(base))
+			if err != nil {
+				t.Fatalf("cannot parse object: %v", err)
+			}
+			b, err := fn.ParseKubeObject([]byte(tc.b))
+			if err != nil {
+				t.Fatalf("cannot parse object: %v", err)
+			}
+			diffs, err := Diff(a, b, tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error in Diff: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, diffs); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiffWithNilObject(t *testing.T) {
+	if _, err := Diff(nil, nil, nil); err == nil {
+		t.Errorf("expected an error in Diff with a nil KubeObject")
+	}
+}
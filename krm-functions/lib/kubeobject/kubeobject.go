@@ -27,13 +27,22 @@ import (
 )
 
 // KubeObjectToStruct is a lightweight wrapper around `obj.As()`, only meant to slightly improve code readability
+// The defaulting functions registered in `TheScheme` for `T` are applied to the result.
+// An object of another api version is converted to the hub version of its kind first, see RegisterHubVersion
 func KubeObjectToStruct[T any](obj *fn.KubeObject) (*T, error) {
 	if obj == nil {
 		return nil, fmt.Errorf("cannot convert nil KubeObject")
 	}
+	obj, err := ConvertToHub(obj)
+	if err != nil {
+		return nil, err
+	}
 	var x T
-	err := obj.As(&x)
-	return &x, err
+	if err := obj.As(&x); err != nil {
+		return &x, err
+	}
+	applyDefaults(&x)
+	return &x, nil
 }
 
 type KubeObjectExt[T1 any] struct {
@@ -42,8 +51,12 @@ type KubeObjectExt[T1 any] struct {
 
 func (r *KubeObjectExt[T1]) GetGoStruct() (*T1, error) {
 	validateTypeOrPanic[T1]()
+	o, err := ConvertToHub(&r.KubeObject)
+	if err != nil {
+		return nil, err
+	}
 	var x T1
-	err := r.KubeObject.As(&x)
+	err = o.As(&x)
 	return &x, err
 }
 
@@ -100,6 +113,13 @@ func (o *KubeObjectExt[T1]) SetFromTypedObject(value *T1) error {
 	return SetNestedFieldKeepFormatting(&o.KubeObject, value)
 }
 
+// SetFromTypedObjectKeepUnknownFields sets the value of `o` to `value`, while keeping most of the YAML
+// formatting and the fields of `o` that are unknown to the Go type, so a round-trip through the Go type
+// doesn't drop them
+func (o *KubeObjectExt[T1]) SetFromTypedObjectKeepUnknownFields(value *T1) error {
+	return SetFromTypedObjectKeepUnknownFields(&o.KubeObject, value)
+}
+
 // SetSpec sets the `spec` field of a KubeObjectExt to the value of `newSpec`,
 // while trying to keep as much formatting as possible
 func (o *KubeObjectExt[T1]) SetSpec(value *T1) error {
@@ -143,6 +163,37 @@ func SetNestedFieldKeepFormatting(obj *fn.KubeObject, value interface{}, fields
 	return setYamlNodeOf(obj, newNode)
 }
 
+// SetFromTypedObjectKeepUnknownFields is similar to SetNestedFieldKeepFormatting() without fields, but
+// also keeps the fields of `obj` that are not part of the Go type `T`, e.g. fields of a newer API version
+// or fields added by other tools. Fields known by `T` that are removed in `value` are removed from `obj`.
+func SetFromTypedObjectKeepUnknownFields[T any](obj *fn.KubeObject, value *T) error {
+	if value == nil {
+		return fmt.Errorf("cannot set from a nil pointer")
+	}
+	oldNode := yamlNodeOf(&obj.SubObject)
+	// the fields of the original object known by the Go type
+	var x T
+	if err := obj.As(&x); err != nil {
+		return err
+	}
+	knownObj, err := fn.NewFromTypedObject(&x)
+	if err != nil {
+		return err
+	}
+	knownNode := yamlNodeOf(&knownObj.SubObject)
+
+	newObj, err := fn.NewFromTypedObject(value)
+	if err != nil {
+		return err
+	}
+	newNode := yamlNodeOf(&newObj.SubObject)
+
+	copyUnknownFields(oldNode, knownNode, newNode)
+	deepCopyFormatting(oldNode, newNode)
+
+	return setYamlNodeOf(obj, newNode)
+}
+
 ///////////////// internals
 
 func (o *KubeObjectExt[T1]) getFieldOrPanic(value *T1, fieldName string) interface{} {
@@ -248,6 +299,44 @@ func copyListFormatting(src, dst *yaml.Node) {
 	}
 }
 
+// copyUnknownFields copies the fields of `src` that are missing in `known` to `dst` recursively
+// `known` is the round-trip of `src` through a Go type, hence it lacks the fields unknown to the Go type
+func copyUnknownFields(src, known, dst *yaml.Node) {
+	if src.Kind != known.Kind || src.Kind != dst.Kind {
+		return
+	}
+	switch src.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(src.Content); i += 2 {
+			key, ok := asString(src.Content[i])
+			if !ok {
+				continue
+			}
+			k, found := findKey(known.Content, key)
+			if !found {
+				// an unknown field is kept, unless the field is set in `dst` already
+				if _, found := findKey(dst.Content, key); !found {
+					dst.Content = append(dst.Content, src.Content[i], src.Content[i+1])
+				}
+				continue
+			}
+			if j, found := findKey(dst.Content, key); found {
+				copyUnknownFields(src.Content[i+1], known.Content[k+1], dst.Content[j+1])
+			}
+		}
+	case yaml.SequenceNode:
+		// the round-trip keeps the order of the list items, so the items of `src` and `known` correspond by index
+		if len(src.Content) != len(known.Content) {
+			return
+		}
+		for i, srcItem := range src.Content {
+			if j, found := findMatchingItemForFormattingCopy(known.Content[i], dst.Content); found {
+				copyUnknownFields(srcItem, known.Content[i], dst.Content[j])
+			}
+		}
+	}
+}
+
 func asString(node *yaml.Node) (string, bool) {
 	if node.Kind == yaml.ScalarNode && (node.Tag == "!!str" || node.Tag == "") {
 		return node.Value, true
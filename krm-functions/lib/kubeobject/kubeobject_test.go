@@ -532,6 +532,50 @@ func TestSetFromTypedObject(t *testing.T) {
 	}
 }
 
+func TestSetFromTypedObjectKeepUnknownFields(t *testing.T) {
+	testcases := []deploymentTestcase{
+		{
+			inputFile:    formattingTestDataDir + "deployment_unknown_fields.yaml",
+			expectedFile: formattingTestDataDir + "deployment_unknown_fields__noop_expected.yaml",
+			transform:    noop,
+		},
+		{
+			inputFile:    formattingTestDataDir + "deployment_unknown_fields.yaml",
+			expectedFile: formattingTestDataDir + "deployment_unknown_fields__change_spec_fields_expected.yaml",
+			transform:    setSpecFields,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.expectedFile, func(t *testing.T) {
+			obj := testlib.MustParseKubeObject(t, tc.inputFile)
+			koe, err := NewFromKubeObject[appsv1.Deployment](obj)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			deploy, err := koe.GetGoStruct()
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			tc.transform(deploy)
+
+			err = koe.SetFromTypedObjectKeepUnknownFields(deploy)
+			if err != nil {
+				t.Errorf("unexpected error in SetFromTypedObjectKeepUnknownFields: %v", err)
+			}
+
+			compareKubeObjectWithExpectedYaml(t, &koe.KubeObject, tc.expectedFile)
+		})
+	}
+}
+
+func TestSetFromTypedObjectKeepUnknownFieldsWithNilPointer(t *testing.T) {
+	obj := testlib.MustParseKubeObject(t, formattingTestDataDir+"deployment_unknown_fields.yaml")
+	if err := SetFromTypedObjectKeepUnknownFields[appsv1.Deployment](obj, nil); err == nil {
+		t.Errorf("expected an error for a nil pointer")
+	}
+}
+
 func TestKubeObjectExtSetSpec(t *testing.T) {
 	testcases := []deploymentTestcase{
 		{
@@ -17,6 +17,7 @@ limitations under the License.
 package kubeobject
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 
@@ -33,6 +34,30 @@ import (
 //	_ = nephioreqv1alpha1.AddToScheme(kubeobject.TheScheme)
 var TheScheme *runtime.Scheme = runtime.NewScheme()
 
+// RegisterDefaultingFunc registers a function in `TheScheme` that sets the API defaults of the Go type `T`,
+// e.g. optional fields that are nil. The defaulting function is applied by KubeObjectToStruct, KubeObjectsToStructs
+// and FilterByType after the conversion, so the defaults are present before validation.
+// E.g.:
+//
+//	kubeobject.RegisterDefaultingFunc(func(wc *infrav1alpha1.WorkloadCluster) {
+//		if wc.Spec.MasterInterface == nil {
+//			wc.Spec.MasterInterface = pointer.String("eth1")
+//		}
+//	})
+func RegisterDefaultingFunc[T any, PT PtrIsRuntimeObject[T]](defaultFn func(PT)) {
+	var pt PT = new(T)
+	TheScheme.AddTypeDefaultingFunc(pt, func(o interface{}) {
+		defaultFn(o.(PT))
+	})
+}
+
+// applyDefaults applies the defaulting function registered in `TheScheme` for the type of `x`, if any
+func applyDefaults(x any) {
+	if o, ok := x.(runtime.Object); ok {
+		TheScheme.Default(o)
+	}
+}
+
 // Type constraint for checking if *T implements the runtime.Object interface
 type PtrIsRuntimeObject[T any] interface {
 	runtime.Object
@@ -50,7 +75,8 @@ func GetGVKOrPanic[T any, PT PtrIsRuntimeObject[T]]() schema.GroupVersionKind {
 	return gvks[0]
 }
 
-// FilterByType returns the objects in `objs` whose Group-Version-Kind matches with the Go type `T`.
+// FilterByType returns the objects in `objs` whose Group-Version-Kind matches with the Go type `T`,
+// or converts to it.
 // Panics if `T` is not registered in `TheScheme`.
 // FilterByType returns with
 //   - the list of matching KubeObjects converted to `*T`.
@@ -60,12 +86,16 @@ func FilterByType[T any, PT PtrIsRuntimeObject[T]](objs fn.KubeObjects) ([]*T, f
 	result := make([]*T, 0, len(objs))
 	var rest fn.KubeObjects
 	for _, o := range objs {
-		if o.GroupVersionKind() == GetGVKOrPanic[T, PT]() {
+		if HubGroupVersionKind(o.GroupVersionKind()) == GetGVKOrPanic[T, PT]() {
 			var x T
-			err := o.As(&x)
+			converted, err := ConvertToHub(o)
 			if err != nil {
 				return nil, nil, err
 			}
+			if err := converted.As(&x); err != nil {
+				return nil, nil, err
+			}
+			applyDefaults(&x)
 			result = append(result, &x)
 		} else {
 			rest = append(rest, o)
@@ -88,3 +118,27 @@ func GetSingleton[T any, PT PtrIsRuntimeObject[T]](objs fn.KubeObjects) (*T, err
 	}
 	return typedObjs[0], nil
 }
+
+// KubeObjectsToStructs converts all KubeObjects in `objs` to the Go type `T`, keeping the order of `objs`.
+// Unlike FilterByType it doesn't filter by Group-Version-Kind, so `T` doesn't need to be registered in `TheScheme`.
+// The conversion errors of the individual KubeObjects are aggregated in the returned error.
+func KubeObjectsToStructs[T any](objs fn.KubeObjects) ([]T, error) {
+	result := make([]T, 0, len(objs))
+	var errs []error
+	for _, o := range objs {
+		x, err := KubeObjectToStruct[T](o)
+		if err != nil {
+			if o == nil {
+				errs = append(errs, err)
+			} else {
+				errs = append(errs, fmt.Errorf("cannot convert %s %s: %s", o.GetKind(), o.GetName(), err.Error()))
+			}
+			continue
+		}
+		result = append(result, *x)
+	}
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+	return result, nil
+}
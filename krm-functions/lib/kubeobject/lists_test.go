@@ -17,8 +17,10 @@ limitations under the License.
 package kubeobject
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
 	tlib "github.com/nephio-project/nephio/krm-functions/lib/test"
 	appsv1 "k8s.io/api/apps/v1"
 )
@@ -60,3 +62,63 @@ func TestGetSingleton(t *testing.T) {
 	}
 
 }
+
+func TestKubeObjectsToStructs(t *testing.T) {
+	objs := tlib.MustParseKubeObjects(t, "testdata/lists/resources.yaml")
+	// the first 2 objects are Deployments
+	deploys := objs[:2]
+
+	structs, err := KubeObjectsToStructs[appsv1.Deployment](deploys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(structs) != len(deploys) {
+		t.Fatalf("wrong number of Deployments were converted: got %v, expected %v", len(structs), len(deploys))
+	}
+	for i := range structs {
+		if structs[i].Name != deploys[i].GetName() {
+			t.Errorf("the order of the objects isn't kept: got %v, expected %v", structs[i].Name, deploys[i].GetName())
+		}
+	}
+
+	// the conversion errors of all objects are reported
+	_, err = KubeObjectsToStructs[appsv1.Deployment](fn.KubeObjects{deploys[0], nil, nil})
+	if err == nil {
+		t.Fatalf("KubeObjectsToStructs should return with an error if an object cannot be converted, but it hasn't")
+	}
+	if n := strings.Count(err.Error(), "cannot convert nil KubeObject"); n != 2 {
+		t.Errorf("expected the errors of 2 objects, got %d: %v", n, err)
+	}
+}
+
+func TestRegisterDefaultingFunc(t *testing.T) {
+	RegisterDefaultingFunc(func(ds *appsv1.DaemonSet) {
+		if ds.Spec.MinReadySeconds == 0 {
+			ds.Spec.MinReadySeconds = 10
+		}
+	})
+	objs := tlib.MustParseKubeObjects(t, "testdata/lists/resources.yaml")
+
+	ds, err := GetSingleton[appsv1.DaemonSet](objs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ds.Spec.MinReadySeconds != 10 {
+		t.Errorf("the defaulting function wasn't applied by GetSingleton: got %v, expected 10", ds.Spec.MinReadySeconds)
+	}
+	ds, err = KubeObjectToStruct[appsv1.DaemonSet](objs[2])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ds.Spec.MinReadySeconds != 10 {
+		t.Errorf("the defaulting function wasn't applied by KubeObjectToStruct: got %v, expected 10", ds.Spec.MinReadySeconds)
+	}
+	// types without defaulting function are left untouched
+	deploy, err := KubeObjectToStruct[appsv1.Deployment](objs[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deploy.Spec.MinReadySeconds != 0 {
+		t.Errorf("unexpected default for a type without defaulting function: got %v", deploy.Spec.MinReadySeconds)
+	}
+}
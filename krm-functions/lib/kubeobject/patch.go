@@ -0,0 +1,78 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeobject
+
+import (
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+)
+
+// ApplyStrategicMergePatch applies a strategic merge patch, serialized as YAML or JSON, to the KubeObjectExt,
+// while trying to keep as much formatting as possible.
+// The list merge keys and patch strategies are taken from the Go type `T1`, e.g. containers are merged by name.
+func (o *KubeObjectExt[T1]) ApplyStrategicMergePatch(patch []byte) error {
+	var x T1
+	return o.applyPatch(patch, func(original, patch []byte) ([]byte, error) {
+		return strategicpatch.StrategicMergePatch(original, patch, x)
+	})
+}
+
+// ApplyJSONPatch applies a JSON patch (RFC 6902), serialized as YAML or JSON, to the KubeObjectExt,
+// while trying to keep as much formatting as possible
+func (o *KubeObjectExt[T1]) ApplyJSONPatch(patch []byte) error {
+	return o.applyPatch(patch, func(original, patch []byte) ([]byte, error) {
+		p, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, err
+		}
+		return p.Apply(original)
+	})
+}
+
+// applyPatch applies the patch to the KubeObjectExt using the apply function, which works on JSON
+func (o *KubeObjectExt[T1]) applyPatch(patch []byte, apply func(original, patch []byte) ([]byte, error)) error {
+	jsonPatch, err := yaml.YAMLToJSON(patch)
+	if err != nil {
+		return fmt.Errorf("cannot convert patch to json: %s", err.Error())
+	}
+	original, err := yaml.YAMLToJSON([]byte(o.KubeObject.String()))
+	if err != nil {
+		return fmt.Errorf("cannot convert %s %s to json: %s", o.GetKind(), o.GetName(), err.Error())
+	}
+	patched, err := apply(original, jsonPatch)
+	if err != nil {
+		return fmt.Errorf("cannot patch %s %s: %s", o.GetKind(), o.GetName(), err.Error())
+	}
+	b, err := yaml.JSONToYAML(patched)
+	if err != nil {
+		return err
+	}
+	newObj, err := fn.ParseKubeObject(b)
+	if err != nil {
+		return err
+	}
+	oldNode := yamlNodeOf(&o.KubeObject.SubObject)
+	newNode := yamlNodeOf(&newObj.SubObject)
+
+	deepCopyFormatting(oldNode, newNode)
+
+	return setYamlNodeOf(&o.KubeObject, newNode)
+}
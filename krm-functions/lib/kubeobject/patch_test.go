@@ -0,0 +1,118 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeobject
+
+import (
+	"testing"
+
+	testlib "github.com/nephio-project/nephio/krm-functions/lib/test"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+var patchTestDataDir = "testdata/patch/"
+
+func TestApplyStrategicMergePatch(t *testing.T) {
+	testcases := map[string]struct {
+		patch        string
+		expectedFile string
+		errExpected  bool
+	}{
+		"MergeContainerByName": {
+			patch: `
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx-2
+        image: nginx:1.15.0
+`,
+			expectedFile: patchTestDataDir + "deployment_full__smp_merge_container_expected.yaml",
+		},
+		"DeleteContainer": {
+			patch:        `{"spec":{"replicas":5,"template":{"spec":{"containers":[{"name":"nginx","$patch":"delete"}]}}}}`,
+			expectedFile: patchTestDataDir + "deployment_full__smp_delete_container_expected.yaml",
+		},
+		"InvalidPatch": {
+			patch:       `spec: [`,
+			errExpected: true,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			obj := testlib.MustParseKubeObject(t, formattingTestDataDir+"deployment_full.yaml")
+			koe, err := NewFromKubeObject[appsv1.Deployment](obj)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = koe.ApplyStrategicMergePatch([]byte(tc.patch))
+			if tc.errExpected {
+				if err == nil {
+					t.Errorf("expected an error in ApplyStrategicMergePatch")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error in ApplyStrategicMergePatch: %v", err)
+			}
+			compareKubeObjectWithExpectedYaml(t, &koe.KubeObject, tc.expectedFile)
+		})
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	testcases := map[string]struct {
+		patch        string
+		expectedFile string
+		errExpected  bool
+	}{
+		"ReplaceAndAdd": {
+			patch: `
+- op: replace
+  path: /spec/template/spec/containers/1/image
+  value: nginx:1.15.0
+- op: add
+  path: /metadata/labels/tier
+  value: frontend
+`,
+			expectedFile: patchTestDataDir + "deployment_full__jsonpatch_replace_expected.yaml",
+		},
+		"MissingPath": {
+			patch:       `[{"op":"remove","path":"/spec/doesNotExist"}]`,
+			errExpected: true,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			obj := testlib.MustParseKubeObject(t, formattingTestDataDir+"deployment_full.yaml")
+			koe, err := NewFromKubeObject[appsv1.Deployment](obj)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = koe.ApplyJSONPatch([]byte(tc.patch))
+			if tc.errExpected {
+				if err == nil {
+					t.Errorf("expected an error in ApplyJSONPatch")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error in ApplyJSONPatch: %v", err)
+			}
+			compareKubeObjectWithExpectedYaml(t, &koe.KubeObject, tc.expectedFile)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeobject
+
+import (
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+)
+
+// GetStatus returns the `status` field of the KubeObject converted to the Go type `T` of the status,
+// e.g. ipamv1alpha1.IPClaimStatus. An error is returned when the KubeObject has no status.
+func GetStatus[T any](obj *fn.KubeObject) (*T, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("cannot get the status of a nil KubeObject")
+	}
+	status, found, err := obj.NestedSubObject("status")
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the status of %s %s: %s", obj.GetKind(), obj.GetName(), err.Error())
+	}
+	if !found {
+		return nil, fmt.Errorf("%s %s has no status", obj.GetKind(), obj.GetName())
+	}
+	var x T
+	if err := status.As(&x); err != nil {
+		return nil, fmt.Errorf("cannot convert the status of %s %s: %s", obj.GetKind(), obj.GetName(), err.Error())
+	}
+	return &x, nil
+}
+
+// SetStatus sets the `status` field of the KubeObject to the value of `status`, while trying to keep
+// as much formatting as possible. The rest of the KubeObject is left untouched.
+func SetStatus[T any](obj *fn.KubeObject, status *T) error {
+	if obj == nil {
+		return fmt.Errorf("cannot set the status of a nil KubeObject")
+	}
+	if status == nil {
+		return fmt.Errorf("cannot set the status of %s %s to a nil pointer", obj.GetKind(), obj.GetName())
+	}
+	return SetNestedFieldKeepFormatting(obj, status, "status")
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeobject
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestGetStatus(t *testing.T) {
+	testcases := map[string]struct {
+		input       string
+		expected    *appsv1.DeploymentStatus
+		errExpected bool
+	}{
+		"Status": {
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+status:
+  replicas: 2
+  availableReplicas: 1
+`,
+			expected: &appsv1.DeploymentStatus{Replicas: 2, AvailableReplicas: 1},
+		},
+		"NoStatus": {
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+`,
+			errExpected: true,
+		},
+		"InvalidStatus": {
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+status:
+  replicas: two
+`,
+			errExpected: true,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			obj, err := fn.ParseKubeObject([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("cannot parse object: %v", err)
+			}
+			status, err := GetStatus[appsv1.DeploymentStatus](obj)
+			if tc.errExpected {
+				if err == nil {
+					t.Errorf("expected an error in GetStatus")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error in GetStatus: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, status); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetStatusOnKubeObject(t *testing.T) {
+	obj, err := fn.ParseKubeObject([]byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+spec:
+  replicas: 3 # comment
+`))
+	if err != nil {
+		t.Fatalf("cannot parse object: %v", err)
+	}
+	if err := SetStatus(obj, &appsv1.DeploymentStatus{Replicas: 3}); err != nil {
+		t.Fatalf("unexpected error in SetStatus: %v", err)
+	}
+	status, err := GetStatus[appsv1.DeploymentStatus](obj)
+	if err != nil {
+		t.Fatalf("unexpected error in GetStatus: %v", err)
+	}
+	if status.Replicas != 3 {
+		t.Errorf("expected 3 replicas in the status, got %d", status.Replicas)
+	}
+	if replicas, _, _ := obj.NestedInt("spec", "replicas"); replicas != 3 {
+		t.Errorf("expected the spec to be untouched, got %d replicas", replicas)
+	}
+	if err := SetStatus[appsv1.DeploymentStatus](obj, nil); err == nil {
+		t.Errorf("expected an error in SetStatus with a nil pointer")
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2023 Nephio.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	SbrPluginType       = "sbr"
+	BandwidthPluginType = "bandwidth"
+	PortMapPluginType   = "portmap"
+	FirewallPluginType  = "firewall"
+)
+
+// TuningConfig is the config of the tuning cni plugin
+type TuningConfig struct {
+	Type         string            `json:"type"`
+	Capabilities Capabilities      `json:"capabilities,omitempty"`
+	Mac          string            `json:"mac,omitempty"`
+	Mtu          int               `json:"mtu,omitempty"`
+	Promisc      bool              `json:"promisc,omitempty"`
+	Sysctl       map[string]string `json:"sysctl,omitempty"`
+}
+
+// SbrConfig is the config of the source based routing cni plugin
+type SbrConfig struct {
+	Type string `json:"type"`
+}
+
+// BandwidthConfig is the config of the bandwidth cni plugin, rates and bursts are in bits
+type BandwidthConfig struct {
+	Type         string `json:"type"`
+	IngressRate  int64  `json:"ingressRate,omitempty"`
+	IngressBurst int64  `json:"ingressBurst,omitempty"`
+	EgressRate   int64  `json:"egressRate,omitempty"`
+	EgressBurst  int64  `json:"egressBurst,omitempty"`
+}
+
+// PluginChainBuilder composes a cni plugin list (conflist) of a main plugin, e.g. macvlan,
+// followed by chained plugins like tuning, sbr and bandwidth
+// The errors of the builder are returned by Build
+type PluginChainBuilder struct {
+	name    string
+	main    any
+	chained []any
+	types   map[string]bool
+	err     error
+}
+
+// NewPluginChainBuilder returns a builder for the cni plugin list with the given name
+func NewPluginChainBuilder(name string) *PluginChainBuilder {
+	return &PluginChainBuilder{
+		name:  name,
+		types: map[string]bool{},
+	}
+}
+
+// WithMainPlugin sets the main plugin of the plugin list, e.g. a MacVlanConfig or SriovConfig
+func (b *PluginChainBuilder) WithMainPlugin(plugin any) *PluginChainBuilder {
+	if b.main != nil {
+		b.setErr(fmt.Errorf("the main plugin of the plugin list is already set"))
+		return b
+	}
+	pluginType, err := getPluginType(plugin)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	if isChainedPluginType(pluginType) {
+		b.setErr(fmt.Errorf("plugin %s cannot be the main plugin of the plugin list", pluginType))
+		return b
+	}
+	b.main = plugin
+	return b
+}
+
+// WithTuning chains the tuning plugin
+func (b *PluginChainBuilder) WithTuning(tuning TuningConfig) *PluginChainBuilder {
+	tuning.Type = TuningType
+	if tuning.Mac != "" {
+		tuning.Capabilities.Mac = true
+	}
+	return b.withChainedPlugin(TuningType, tuning)
+}
+
+// WithSbr chains the source based routing plugin
+func (b *PluginChainBuilder) WithSbr() *PluginChainBuilder {
+	return b.withChainedPlugin(SbrPluginType, SbrConfig{Type: SbrPluginType})
+}
+
+// WithBandwidth chains the bandwidth plugin
+func (b *PluginChainBuilder) WithBandwidth(bandwidth BandwidthConfig) *PluginChainBuilder {
+	bandwidth.Type = BandwidthPluginType
+	if bandwidth.IngressRate < 0 || bandwidth.IngressBurst < 0 || bandwidth.EgressRate < 0 || bandwidth.EgressBurst < 0 {
+		b.setErr(fmt.Errorf("invalid bandwidth plugin, rates and bursts cannot be negative"))
+		return b
+	}
+	return b.withChainedPlugin(BandwidthPluginType, bandwidth)
+}
+
+// Build returns the plugin list, serialized as cni config
+func (b *PluginChainBuilder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	if b.main == nil {
+		return "", fmt.Errorf("a plugin list requires a main plugin")
+	}
+	conflist := struct {
+		CniVersion string `json:"cniVersion"`
+		Name       string `json:"name,omitempty"`
+		Plugins    []any  `json:"plugins"`
+	}{
+		CniVersion: CniVersion,
+		Name:       b.name,
+		Plugins:    append([]any{b.main}, b.chained...),
+	}
+	c, err := json.Marshal(conflist)
+	if err != nil {
+		return "", err
+	}
+	return string(c), nil
+}
+
+// SetPluginChain sets the cni config of the nad to the plugin list of the builder
+func (r *NadStruct) SetPluginChain(b *PluginChainBuilder) error {
+	config, err := b.Build()
+	if err != nil {
+		return err
+	}
+	return r.K.SetNestedString(config, ConfigType...)
+}
+
+func (b *PluginChainBuilder) withChainedPlugin(pluginType string, plugin any) *PluginChainBuilder {
+	if b.types[pluginType] {
+		b.setErr(fmt.Errorf("plugin %s is already chained in the plugin list", pluginType))
+		return b
+	}
+	b.types[pluginType] = true
+	b.chained = append(b.chained, plugin)
+	return b
+}
+
+// setErr keeps the first error of the builder
+func (b *PluginChainBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// getPluginType returns the type of a typed plugin config
+func getPluginType(plugin any) (string, error) {
+	if plugin == nil {
+		return "", fmt.Errorf("a plugin cannot be nil")
+	}
+	c, err := json.Marshal(plugin)
+	if err != nil {
+		return "", fmt.Errorf("invalid plugin, %s", err)
+	}
+	p := struct {
+		Type string `json:"type,omitempty"`
+	}{}
+	if err := json.Unmarshal(c, &p); err != nil {
+		return "", fmt.Errorf("invalid plugin, %s", err)
+	}
+	if p.Type == "" {
+		return "", fmt.Errorf("invalid plugin, the plugin type is missing")
+	}
+	return p.Type, nil
+}
+
+// isChainedPluginType returns true for the meta plugins chained after the main plugin of a
+// plugin list, the nad setters leave them untouched
+func isChainedPluginType(pluginType string) bool {
+	switch pluginType {
+	case TuningType, SbrPluginType, BandwidthPluginType, PortMapPluginType, FirewallPluginType:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023 Nephio.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginChainBuilder(t *testing.T) {
+	cases := map[string]struct {
+		builder     *PluginChainBuilder
+		want        string
+		errExpected bool
+	}{
+		"MainPluginOnly": {
+			builder: NewPluginChainBuilder("n3").
+				WithMainPlugin(IpVlanConfig{Type: IpVlanPluginType, Master: "eth1", Mode: ModeL2}),
+			want: `{"cniVersion":"0.3.1","name":"n3","plugins":[{"type":"ipvlan","capabilities":{},"master":"eth1","mode":"l2","ipam":{}}]}`,
+		},
+		"FullChain": {
+			builder: NewPluginChainBuilder("n6").
+				WithMainPlugin(MacVlanConfig{Type: MacVlanPluginType, Master: "eth1", Mode: ModeBridge, Ipam: Ipam{Type: StaticNadType}}).
+				WithTuning(TuningConfig{Mac: "00:11:22:33:44:55", Sysctl: map[string]string{"net.ipv4.conf.all.rp_filter": "0"}}).
+				WithSbr().
+				WithBandwidth(BandwidthConfig{IngressRate: 1000000, IngressBurst: 10000}),
+			want: `{"cniVersion":"0.3.1","name":"n6","plugins":[{"type":"macvlan","capabilities":{},"master":"eth1","mode":"bridge","ipam":{"type":"static"}},{"type":"tuning","capabilities":{"mac":true},"mac":"00:11:22:33:44:55","sysctl":{"net.ipv4.conf.all.rp_filter":"0"}},{"type":"sbr"},{"type":"bandwidth","ingressRate":1000000,"ingressBurst":10000}]}`,
+		},
+		"MissingMainPlugin": {
+			builder:     NewPluginChainBuilder("n3").WithSbr(),
+			errExpected: true,
+		},
+		"DuplicateMainPlugin": {
+			builder: NewPluginChainBuilder("n3").
+				WithMainPlugin(IpVlanConfig{Type: IpVlanPluginType}).
+				WithMainPlugin(MacVlanConfig{Type: MacVlanPluginType}),
+			errExpected: true,
+		},
+		"ChainedPluginAsMainPlugin": {
+			builder:     NewPluginChainBuilder("n3").WithMainPlugin(SbrConfig{Type: SbrPluginType}),
+			errExpected: true,
+		},
+		"MainPluginWithoutType": {
+			builder:     NewPluginChainBuilder("n3").WithMainPlugin(IpVlanConfig{Master: "eth1"}),
+			errExpected: true,
+		},
+		"DuplicateChainedPlugin": {
+			builder: NewPluginChainBuilder("n3").
+				WithMainPlugin(IpVlanConfig{Type: IpVlanPluginType}).
+				WithSbr().
+				WithSbr(),
+			errExpected: true,
+		},
+		"NegativeBandwidth": {
+			builder: NewPluginChainBuilder("n3").
+				WithMainPlugin(IpVlanConfig{Type: IpVlanPluginType}).
+				WithBandwidth(BandwidthConfig{EgressRate: -1}),
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.builder.Build()
+			if tc.errExpected {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSetPluginChain(t *testing.T) {
+	nad, err := NewFromYAML([]byte(nadTestEmpty))
+	if err != nil {
+		t.Fatalf("cannot parse nad: %s", err.Error())
+	}
+	b := NewPluginChainBuilder("n3").
+		WithMainPlugin(IpVlanConfig{Type: IpVlanPluginType, Master: "eth1", Mode: ModeL2}).
+		WithSbr()
+	if err := nad.SetPluginChain(b); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	ipvlan, err := nad.GetIpVlanConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "eth1", ipvlan.Master)
+	cniType, err := nad.GetCNIType()
+	assert.NoError(t, err)
+	assert.Equal(t, IpVlanPluginType, cniType)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 Nephio.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+)
+
+// GetCapabilities returns the runtime capabilities requested by the plugins of the nad
+// ips is requested by the main plugin, mac by the tuning plugin and bandwidth by the bandwidth plugin
+func (r *NadStruct) GetCapabilities() (Capabilities, error) {
+	existingNadConfig, err := r.getNadConfig()
+	if err != nil {
+		return Capabilities{}, err
+	}
+	c := Capabilities{}
+	for _, plugin := range existingNadConfig.Plugins {
+		c.Ips = c.Ips || plugin.Capabilities.Ips
+		c.Mac = c.Mac || plugin.Capabilities.Mac
+		c.Bandwidth = c.Bandwidth || plugin.Capabilities.Bandwidth
+	}
+	return c, nil
+}
+
+// SetCapabilities sets the runtime capabilities of the nad, which lets multus inject the ips, mac
+// or bandwidth of the pod annotation at runtime:
+// - ips is set on the main plugin and requires the static ipam
+// - mac is set on the tuning plugin, which is added to the plugin chain when missing
+// - bandwidth is set on the bandwidth plugin, which is added to the plugin chain when missing
+// A capability that is not requested is removed from the plugins, the plugins are kept
+func (r *NadStruct) SetCapabilities(c Capabilities) error {
+	nadConfigStruct, err := r.getNadConfig()
+	if err != nil {
+		return err
+	}
+	if err := validateCapabilities(nadConfigStruct, c); err != nil {
+		return err
+	}
+	tuning := false
+	bandwidth := false
+	for i, plugin := range nadConfigStruct.Plugins {
+		switch plugin.Type {
+		case TuningType:
+			nadConfigStruct.Plugins[i].Capabilities.Mac = c.Mac
+			tuning = true
+		case BandwidthPluginType:
+			nadConfigStruct.Plugins[i].Capabilities.Bandwidth = c.Bandwidth
+			bandwidth = true
+		default:
+			if !isChainedPluginType(plugin.Type) {
+				nadConfigStruct.Plugins[i].Capabilities.Ips = c.Ips
+			}
+		}
+	}
+	if c.Mac && !tuning {
+		nadConfigStruct.Plugins = append(nadConfigStruct.Plugins, PluginCniType{
+			Type:         TuningType,
+			Capabilities: Capabilities{Mac: true},
+		})
+	}
+	if c.Bandwidth && !bandwidth {
+		nadConfigStruct.Plugins = append(nadConfigStruct.Plugins, PluginCniType{
+			Type:         BandwidthPluginType,
+			Capabilities: Capabilities{Bandwidth: true},
+		})
+	}
+	return r.setNadConfig(nadConfigStruct)
+}
+
+// validateCapabilities validates the combination of the capabilities with the cni config
+func validateCapabilities(config NadConfig, c Capabilities) error {
+	for _, plugin := range config.Plugins {
+		if isChainedPluginType(plugin.Type) {
+			continue
+		}
+		if c.Ips && plugin.Ipam.Type != StaticNadType {
+			return fmt.Errorf("capability ips requires the %s ipam, got ipam type %q", StaticNadType, plugin.Ipam.Type)
+		}
+		if c.Mac && plugin.Type == IpVlanPluginType {
+			return fmt.Errorf("capability mac is not supported by cniType %s, the interfaces share the mac address of the master", IpVlanPluginType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2023 Nephio.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetCapabilities(t *testing.T) {
+	cases := map[string]struct {
+		input       string
+		cniType     string
+		c           Capabilities
+		want        string
+		errExpected bool
+	}{
+		"IpsAndMac": {
+			input:   nadTestEmpty,
+			cniType: "macvlan",
+			c:       Capabilities{Ips: true, Mac: true},
+			want:    `{"cniVersion":"0.3.1","plugins":[{"type":"macvlan","capabilities":{"ips":true},"mode":"bridge","ipam":{"type":"static"}},{"type":"tuning","capabilities":{"mac":true},"ipam":{}}]}`,
+		},
+		"BandwidthAddsPlugin": {
+			input:   nadTestIpVlan,
+			cniType: "ipvlan",
+			c:       Capabilities{Ips: true, Bandwidth: true},
+			want:    `{"cniVersion":"0.3.1","plugins":[{"type":"ipvlan","capabilities":{"ips":true},"master":"eth1","mode":"l2","ipam":{"type":"static","addresses":[{"address":"16.0.0.2/24","gateway":"16.0.0.1"}]}},{"type":"bandwidth","capabilities":{"bandwidth":true},"ipam":{}}]}`,
+		},
+		"RemoveMac": {
+			input:   nadTestMacVlan,
+			cniType: "macvlan",
+			c:       Capabilities{Ips: true},
+			want:    `{"cniVersion":"0.3.1","plugins":[{"type":"macvlan","capabilities":{"ips":true},"master":"eth1","mode":"bridge","ipam":{"type":"static","addresses":[{"address":"14.0.0.2/24","gateway":"14.0.0.1"}]}},{"type":"tuning","capabilities":{},"ipam":{}}]}`,
+		},
+		"MacWithIpVlan": {
+			input:       nadTestIpVlan,
+			cniType:     "ipvlan",
+			c:           Capabilities{Mac: true},
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			nad, err := NewFromYAML([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("cannot parse nad: %s", err.Error())
+			}
+			if err := nad.SetCNIType(tc.cniType); err != nil {
+				t.Fatalf("cannot set cniType: %s", err.Error())
+			}
+			err = nad.SetCapabilities(tc.c)
+			if tc.errExpected {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, nad.GetConfigSpec())
+			c, err := nad.GetCapabilities()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.c, c)
+		})
+	}
+}
+
+func TestSetCapabilitiesIpsRequiresStaticIpam(t *testing.T) {
+	nad, err := NewFromYAML([]byte(`apiVersion: "k8s.cni.cncf.io/v1"
+kind: NetworkAttachmentDefinition
+metadata:
+  name: upf-us-central1-n3
+spec:
+  config: '{"cniVersion":"0.3.1","plugins":[{"type":"macvlan","master":"eth1","ipam":{"type":"dhcp"}}]}'
+`))
+	if err != nil {
+		t.Fatalf("cannot parse nad: %s", err.Error())
+	}
+	assert.Error(t, nad.SetCapabilities(Capabilities{Ips: true}))
+	assert.NoError(t, nad.SetCapabilities(Capabilities{Mac: true}))
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2023 Nephio.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// cniSpecTypes maps the cni type of the main plugin to the CniSpecType of the nad
+var cniSpecTypes = map[string]CniSpecType{
+	"ipvlan":      IpVlanType,
+	"macvlan":     MacVlanType,
+	"sriov":       SriovType,
+	"vlan":        VlanType,
+	"bridge":      BridgeType,
+	"ovs":         OvsType,
+	"host-device": HostDeviceType,
+}
+
+// DetectCniSpecType sets the CniSpecType of the nad from the cni type of its main plugin,
+// which allows to update a nad created by another tool in place; a nad without plugin but
+// with a vlan is VlanClaimOnly and an unknown cni type is OtherType
+func (r *NadStruct) DetectCniSpecType() (CniSpecType, error) {
+	configSpec := r.GetConfigSpec()
+	if configSpec == "" {
+		return r.CniSpecType, nil
+	}
+	config := map[string]any{}
+	if err := json.Unmarshal([]byte(configSpec), &config); err != nil {
+		return r.CniSpecType, fmt.Errorf("invalid NAD Config, %s", err)
+	}
+	_, hasPlugins := config["plugins"]
+	_, hasType := config["type"]
+	if !hasPlugins && !hasType {
+		if _, ok := config["vlan"]; ok {
+			r.CniSpecType = VlanClaimOnly
+		}
+		return r.CniSpecType, nil
+	}
+	config, err := normalizeConfig(config)
+	if err != nil {
+		return r.CniSpecType, err
+	}
+	r.CniSpecType = OtherType
+	plugins, _ := config["plugins"].([]any)
+	for _, p := range plugins {
+		plugin, _ := p.(map[string]any)
+		pluginType, _ := plugin["type"].(string)
+		if isChainedPluginType(pluginType) {
+			continue
+		}
+		if cniSpecType, ok := cniSpecTypes[pluginType]; ok {
+			r.CniSpecType = cniSpecType
+		}
+		break
+	}
+	return r.CniSpecType, nil
+}
+
+// normalizeConfig turns a single plugin cni config into a plugin list (conflist); the
+// cniVersion and name stay at the top level of the config
+func normalizeConfig(config map[string]any) (map[string]any, error) {
+	if _, ok := config["plugins"]; ok {
+		if _, ok := config["plugins"].([]any); !ok {
+			return config, fmt.Errorf("invalid cni config, plugins must be a list")
+		}
+		return config, nil
+	}
+	if _, ok := config["type"]; !ok {
+		return config, fmt.Errorf("invalid cni config, expecting plugins or a plugin type")
+	}
+	plugin := map[string]any{}
+	normalized := map[string]any{"plugins": []any{plugin}}
+	for k, v := range config {
+		switch k {
+		case "cniVersion", "name":
+			normalized[k] = v
+		default:
+			plugin[k] = v
+		}
+	}
+	return normalized, nil
+}
+
+// normalizeConfigSpec returns the cni config spec as a plugin list; a config spec without
+// plugin, e.g. of a VlanClaimOnly nad, is returned unchanged
+func normalizeConfigSpec(configSpec string) (string, error) {
+	config := map[string]any{}
+	if err := json.Unmarshal([]byte(configSpec), &config); err != nil {
+		return "", fmt.Errorf("invalid NAD Config, %s", err)
+	}
+	if _, ok := config["plugins"]; ok {
+		return configSpec, nil
+	}
+	if _, ok := config["type"]; !ok {
+		return configSpec, nil
+	}
+	normalized, err := normalizeConfig(config)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(normalized)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// the cni config types keep the fields unknown to the nad lib, e.g. of a nad created by
+// another tool, such that an update of a plugin preserves them
+
+type nadConfig NadConfig
+
+func (n *NadConfig) UnmarshalJSON(b []byte) error {
+	unknown, err := unmarshalKeepUnknown(b, (*nadConfig)(n))
+	n.Unknown = unknown
+	return err
+}
+
+func (n NadConfig) MarshalJSON() ([]byte, error) {
+	return marshalKeepUnknown(nadConfig(n), n.Unknown)
+}
+
+type pluginCniType PluginCniType
+
+func (p *PluginCniType) UnmarshalJSON(b []byte) error {
+	unknown, err := unmarshalKeepUnknown(b, (*pluginCniType)(p))
+	p.Unknown = unknown
+	return err
+}
+
+func (p PluginCniType) MarshalJSON() ([]byte, error) {
+	return marshalKeepUnknown(pluginCniType(p), p.Unknown)
+}
+
+type capabilities Capabilities
+
+func (c *Capabilities) UnmarshalJSON(b []byte) error {
+	unknown, err := unmarshalKeepUnknown(b, (*capabilities)(c))
+	c.Unknown = unknown
+	return err
+}
+
+func (c Capabilities) MarshalJSON() ([]byte, error) {
+	return marshalKeepUnknown(capabilities(c), c.Unknown)
+}
+
+type ipam Ipam
+
+func (i *Ipam) UnmarshalJSON(b []byte) error {
+	unknown, err := unmarshalKeepUnknown(b, (*ipam)(i))
+	i.Unknown = unknown
+	return err
+}
+
+func (i Ipam) MarshalJSON() ([]byte, error) {
+	return marshalKeepUnknown(ipam(i), i.Unknown)
+}
+
+// unmarshalKeepUnknown unmarshals the json object into the struct v points to and
+// returns the fields which are unknown to the struct
+func unmarshalKeepUnknown(b []byte, v any) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	known := jsonFields(reflect.TypeOf(v).Elem())
+	var unknown map[string]json.RawMessage
+	for k, raw := range fields {
+		if _, ok := known[k]; ok {
+			continue
+		}
+		if unknown == nil {
+			unknown = map[string]json.RawMessage{}
+		}
+		unknown[k] = raw
+	}
+	return unknown, nil
+}
+
+// marshalKeepUnknown marshals the struct v and appends the unknown fields in alphabetical order
+func marshalKeepUnknown(v any, unknown map[string]json.RawMessage) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(unknown) == 0 {
+		return b, err
+	}
+	keys := make([]string, 0, len(unknown))
+	for k := range unknown {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	buf := bytes.NewBuffer(b[:len(b)-1])
+	for _, k := range keys {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(unknown[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
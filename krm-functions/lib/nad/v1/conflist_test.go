@@ -0,0 +1,119 @@
+/*
+Copyright 2023 Nephio.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectCniSpecType(t *testing.T) {
+	cases := map[string]struct {
+		input string
+		want  CniSpecType
+	}{
+		"Sriov":      {input: nadTestSriov, want: SriovType},
+		"IpVlan":     {input: nadTestIpVlan, want: IpVlanType},
+		"MacVlan":    {input: nadTestMacVlan, want: MacVlanType},
+		"Ovs":        {input: nadTestOvs, want: OvsType},
+		"Vlan":       {input: nadTestVlan, want: VlanType},
+		"Bridge":     {input: nadTestBridge, want: BridgeType},
+		"HostDevice": {input: nadTestHostDevice, want: HostDeviceType},
+		"Empty":      {input: nadTestEmpty, want: OtherType},
+		"VlanOnly": {
+			input: fmt.Sprintf(nadTestTemplate, `{"cniVersion":"0.3.1","vlan":100}`),
+			want:  VlanClaimOnly,
+		},
+		"TuningFirst": {
+			input: fmt.Sprintf(nadTestTemplate, `{"cniVersion":"0.3.1","plugins":[{"type":"tuning"},{"type":"ipvlan","master":"eth1"}]}`),
+			want:  IpVlanType,
+		},
+		"UnknownPlugin": {
+			input: fmt.Sprintf(nadTestTemplate, `{"cniVersion":"0.3.1","plugins":[{"type":"portmap","capabilities":{"portMappings":true}}]}`),
+			want:  OtherType,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			nad, err := NewFromYAML([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("cannot parse nad: %s", err.Error())
+			}
+			assert.Equal(t, tc.want, nad.CniSpecType)
+			got, err := nad.DetectCniSpecType()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestUpdateConflistInPlace(t *testing.T) {
+	cases := map[string]struct {
+		config string
+		update func(nad *NadStruct) error
+		want   string
+	}{
+		"UnknownFieldsKept": {
+			config: `{"cniVersion":"0.3.1","name":"n3","plugins":[{"type":"macvlan","master":"eth0","mode":"bridge","ipam":{"type":"whereabouts","range":"10.0.0.0/24"}},{"type":"portmap","capabilities":{"portMappings":true},"snat":true}]}`,
+			update: func(nad *NadStruct) error { return nad.SetNadMaster("eth1") },
+			want:   `{"cniVersion":"0.3.1","plugins":[{"type":"macvlan","capabilities":{},"master":"eth1","mode":"bridge","ipam":{"type":"whereabouts","range":"10.0.0.0/24"}},{"type":"portmap","capabilities":{"portMappings":true},"ipam":{},"snat":true}],"name":"n3"}`,
+		},
+		"RelevantPluginOnly": {
+			config: `{"cniVersion":"0.3.1","plugins":[{"type":"ipvlan","master":"eth0","mode":"l2","ipam":{"type":"static"}},{"type":"sbr"},{"type":"tuning","sysctl":{"net.ipv4.conf.all.arp_notify":"1"}}]}`,
+			update: func(nad *NadStruct) error {
+				return nad.SetIpamAddress([]Address{{Address: "10.0.0.2/24", Gateway: "10.0.0.1"}})
+			},
+			want: `{"cniVersion":"0.3.1","plugins":[{"type":"ipvlan","capabilities":{},"master":"eth0","mode":"l2","ipam":{"type":"static","addresses":[{"address":"10.0.0.2/24","gateway":"10.0.0.1"}]}},{"type":"sbr","capabilities":{},"ipam":{}},{"type":"tuning","capabilities":{},"ipam":{},"sysctl":{"net.ipv4.conf.all.arp_notify":"1"}}]}`,
+		},
+		"SinglePlugin": {
+			config: `{"cniVersion":"0.3.1","name":"n3","type":"host-device","device":"ens3f1","ipam":{"type":"static"}}`,
+			update: func(nad *NadStruct) error { return nad.SetMtu(9000) },
+			want:   `{"cniVersion":"0.3.1","plugins":[{"type":"host-device","capabilities":{},"ipam":{"type":"static"},"device":"ens3f1","mtu":9000}],"name":"n3"}`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			nad, err := NewFromYAML([]byte(fmt.Sprintf(nadTestTemplate, tc.config)))
+			if err != nil {
+				t.Fatalf("cannot parse nad: %s", err.Error())
+			}
+			if err := tc.update(nad); err != nil {
+				t.Fatalf("cannot update nad: %s", err.Error())
+			}
+			assert.Equal(t, tc.want, nad.GetConfigSpec())
+		})
+	}
+}
+
+func TestMergeSinglePlugin(t *testing.T) {
+	existing, err := NewFromYAML([]byte(fmt.Sprintf(nadTestTemplate, `{"cniVersion":"0.3.1","name":"n3","type":"ipvlan","master":"eth0","mode":"l2","mtu":9000,"ipam":{"type":"static"}}`)))
+	if err != nil {
+		t.Fatalf("cannot parse nad: %s", err.Error())
+	}
+	nad, err := NewFromYAML([]byte(nadTestIpVlan))
+	if err != nil {
+		t.Fatalf("cannot parse nad: %s", err.Error())
+	}
+	if err := nad.Merge(existing); err != nil {
+		t.Fatalf("cannot merge nad: %s", err.Error())
+	}
+	assert.Equal(t, `{"cniVersion":"0.3.1","name":"n3","plugins":[{"capabilities":{"ips":true},"ipam":{"addresses":[{"address":"16.0.0.2/24","gateway":"16.0.0.1"}],"type":"static"},"master":"eth1","mode":"l2","mtu":9000,"type":"ipvlan"}]}`, nad.GetConfigSpec())
+}
@@ -19,7 +19,11 @@ package v1
 import (
 	"encoding/json"
 	"fmt"
+	"net"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
 	nadv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
@@ -30,18 +34,27 @@ const (
 	CniVersion    = "0.3.1"
 	ModeBridge    = "bridge"
 	ModeL2        = "l2"
+	ModeL3        = "l3"
+	ModeL3S       = "l3s"
 	StaticNadType = "static"
 	TuningType    = "tuning"
+	// ResourceNameAnnotation selects the device plugin resource of the nad, e.g. an sriov resource pool
+	ResourceNameAnnotation = "k8s.v1.cni.cncf.io/resourceName"
 )
 
 var (
 	ConfigType = []string{"spec", "config"}
+	// tunableFields are fields an operator may tune on an existing nad, they are only
+	// owned by the generated config when it sets them
+	tunableFields = map[string]bool{"mtu": true}
 )
 
 type NadConfig struct {
 	CniVersion string          `json:"cniVersion,omitempty"`
 	Vlan       int             `json:"vlan,omitempty"`
 	Plugins    []PluginCniType `json:"plugins,omitempty"`
+	// Unknown holds the fields of the config unknown to the nad lib, e.g. the name
+	Unknown map[string]json.RawMessage `json:"-"`
 }
 
 type PluginCniType struct {
@@ -56,17 +69,37 @@ type PluginCniType struct {
 	Vlan            int          `json:"vlan,omitempty"`            // cniType bridge
 	VlanTrunk       []VlanTrunk  `json:"vlanTrunk,omitempty"`       // cniType bridge
 	Name            string       `json:"name,omitempty"`            // cniType bridge
+	Trunk           []VlanTrunk  `json:"trunk,omitempty"`           // cniType ovs
+	Device          string       `json:"device,omitempty"`          // cniType host-device
+	PciBusID        string       `json:"pciBusID,omitempty"`        // cniType host-device
+	Mac             string       `json:"mac,omitempty"`             // cniType tuning
+	Mtu             int          `json:"mtu,omitempty"`
+	// Unknown holds the fields of the plugin unknown to the nad lib
+	Unknown map[string]json.RawMessage `json:"-"`
 }
 
 type Capabilities struct {
-	Ips bool `json:"ips,omitempty"`
-	Mac bool `json:"mac,omitempty"`
+	Ips       bool `json:"ips,omitempty"`
+	Mac       bool `json:"mac,omitempty"`
+	Bandwidth bool `json:"bandwidth,omitempty"`
+	// Unknown holds the capabilities unknown to the nad lib, e.g. portMappings
+	Unknown map[string]json.RawMessage `json:"-"`
 }
 
 type Ipam struct {
 	Type      string    `json:"type,omitempty"`
 	Addresses []Address `json:"addresses,omitempty"`
 	Routes    []Route   `json:"routes,omitempty"`
+	DNS       *DNS      `json:"dns,omitempty"`
+	// Unknown holds the fields of the ipam unknown to the nad lib, e.g. the range of whereabouts
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+type DNS struct {
+	Nameservers []string `json:"nameservers,omitempty"`
+	Domain      string   `json:"domain,omitempty"`
+	Search      []string `json:"search,omitempty"`
+	Options     []string `json:"options,omitempty"`
 }
 
 type Address struct {
@@ -77,6 +110,51 @@ type Address struct {
 type Route struct {
 	Destination string `json:"dst,omitempty"`
 	Gateway     string `json:"gw,omitempty"`
+	Metric      int    `json:"priority,omitempty"`
+	Table       int    `json:"table,omitempty"`
+	// Scope defines the scope of the route, a link scoped route has no gateway and is
+	// reachable on the interface of the nad only
+	Scope int `json:"scope,omitempty"`
+}
+
+const (
+	RouteScopeUniverse = 0
+	RouteScopeSite     = 200
+	RouteScopeLink     = 253
+	RouteScopeHost     = 254
+)
+
+// NewOnLinkRoute returns a gateway-less route to the destination, which is reachable
+// directly on the interface of the nad
+func NewOnLinkRoute(destination string) Route {
+	return Route{Destination: destination, Scope: RouteScopeLink}
+}
+
+// validate validates the destination, gateway and scope of the route
+func (r Route) validate() error {
+	_, dst, err := net.ParseCIDR(r.Destination)
+	if err != nil {
+		return fmt.Errorf("invalid route destination %q, expecting a cidr", r.Destination)
+	}
+	switch r.Scope {
+	case RouteScopeUniverse, RouteScopeSite:
+	case RouteScopeLink, RouteScopeHost:
+		if r.Gateway != "" {
+			return fmt.Errorf("route to %s with scope %d cannot have gateway %s", r.Destination, r.Scope, r.Gateway)
+		}
+	default:
+		return fmt.Errorf("route to %s has invalid scope %d, expecting one of %d, %d, %d, %d", r.Destination, r.Scope, RouteScopeUniverse, RouteScopeSite, RouteScopeLink, RouteScopeHost)
+	}
+	if r.Gateway != "" {
+		gw := net.ParseIP(r.Gateway)
+		if gw == nil {
+			return fmt.Errorf("route to %s has invalid gateway %q", r.Destination, r.Gateway)
+		}
+		if (gw.To4() == nil) != (dst.IP.To4() == nil) {
+			return fmt.Errorf("gateway %s does not match the address family of route destination %s", r.Gateway, r.Destination)
+		}
+	}
+	return nil
 }
 
 type VlanTrunk struct {
@@ -85,6 +163,23 @@ type VlanTrunk struct {
 	ID    int `json:"id,omitempty"`
 }
 
+// validate checks the vlan trunk defines either a vlan id or a vlan range
+func (t VlanTrunk) validate() error {
+	switch {
+	case t.ID != 0 && (t.MinID != 0 || t.MaxID != 0):
+		return fmt.Errorf("vlan trunk defines both id %d and range %d-%d", t.ID, t.MinID, t.MaxID)
+	case t.ID != 0:
+		if t.ID > 4094 {
+			return fmt.Errorf("vlan trunk id %d out of range [1, 4094]", t.ID)
+		}
+	case t.MinID == 0 || t.MaxID == 0:
+		return fmt.Errorf("unknown vlanID")
+	case t.MaxID > 4094 || t.MinID > t.MaxID:
+		return fmt.Errorf("invalid vlan trunk range %d-%d", t.MinID, t.MaxID)
+	}
+	return nil
+}
+
 type CniSpecType int64
 
 const (
@@ -95,6 +190,8 @@ const (
 	MacVlanType
 	VlanType
 	BridgeType
+	OvsType
+	HostDeviceType
 )
 
 type NadStruct struct {
@@ -102,6 +199,15 @@ type NadStruct struct {
 	CniSpecType CniSpecType
 }
 
+// newNadStruct returns the nad of the KubeObject, the CniSpecType is detected from the
+// plugins of an existing cni config
+func newNadStruct(p *kubeobject.KubeObjectExt[nadv1.NetworkAttachmentDefinition]) *NadStruct {
+	r := &NadStruct{K: *p}
+	// an invalid cni config is reported when the nad is updated
+	_, _ = r.DetectCniSpecType()
+	return r
+}
+
 // NewFromKubeObject creates a new parser interface
 // It expects a *fn.KubeObject as input representing the serialized yaml file
 func NewFromKubeObject(b *fn.KubeObject) (*NadStruct, error) {
@@ -109,7 +215,11 @@ func NewFromKubeObject(b *fn.KubeObject) (*NadStruct, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &NadStruct{K: *p}, nil
+	if *b == (fn.KubeObject{}) {
+		// an empty KubeObject has no cni config to detect the CniSpecType from
+		return &NadStruct{K: *p}, nil
+	}
+	return newNadStruct(p), nil
 }
 
 // NewFromYAML creates a new parser interface
@@ -119,7 +229,7 @@ func NewFromYAML(b []byte) (*NadStruct, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &NadStruct{K: *p}, nil
+	return newNadStruct(p), nil
 }
 
 // NewFromGoStruct creates a new parser interface
@@ -129,7 +239,7 @@ func NewFromGoStruct(b *nadv1.NetworkAttachmentDefinition) (*NadStruct, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &NadStruct{K: *p}, nil
+	return newNadStruct(p), nil
 }
 
 func (r *NadStruct) getNadConfig() (NadConfig, error) {
@@ -138,6 +248,11 @@ func (r *NadStruct) getNadConfig() (NadConfig, error) {
 	if configSpec == "" {
 		configSpec = "{}"
 	}
+	// a single plugin config is updated as a plugin list
+	configSpec, err := normalizeConfigSpec(configSpec)
+	if err != nil {
+		return nadConfigStruct, err
+	}
 	if err := json.Unmarshal([]byte(configSpec), &nadConfigStruct); err != nil {
 		return nadConfigStruct, fmt.Errorf("invalid NAD Config, %s", err)
 	}
@@ -203,7 +318,7 @@ func (r *NadStruct) getNadConfig() (NadConfig, error) {
 			}
 		}
 		return nadConfigStruct, nil
-	case BridgeType:
+	case BridgeType, OvsType, HostDeviceType:
 		if nadConfigStruct.Plugins == nil || len(nadConfigStruct.Plugins) == 0 {
 			nadConfigStruct.Plugins = []PluginCniType{
 				{
@@ -262,7 +377,7 @@ func (r *NadStruct) GetCNIType() (string, error) {
 		return "", err
 	}
 	for _, plugin := range existingNadConfig.Plugins {
-		if plugin.Type == TuningType {
+		if isChainedPluginType(plugin.Type) {
 			continue
 		} else {
 			return plugin.Type, nil
@@ -285,7 +400,7 @@ func (r *NadStruct) GetNadMaster() (string, error) {
 		return "", err
 	}
 	for _, plugin := range existingNadConfig.Plugins {
-		if plugin.Type == TuningType {
+		if isChainedPluginType(plugin.Type) {
 			continue
 		} else {
 			return plugin.Master, nil
@@ -294,13 +409,60 @@ func (r *NadStruct) GetNadMaster() (string, error) {
 	return "", nil
 }
 
+func (r *NadStruct) GetMode() (string, error) {
+	existingNadConfig, err := r.getNadConfig()
+	if err != nil {
+		return "", err
+	}
+	for _, plugin := range existingNadConfig.Plugins {
+		if isChainedPluginType(plugin.Type) {
+			continue
+		} else {
+			return plugin.Mode, nil
+		}
+	}
+	return "", nil
+}
+
+func (r *NadStruct) GetMacAddress() (string, error) {
+	existingNadConfig, err := r.getNadConfig()
+	if err != nil {
+		return "", err
+	}
+	for _, plugin := range existingNadConfig.Plugins {
+		if plugin.Type == TuningType {
+			return plugin.Mac, nil
+		}
+	}
+	return "", nil
+}
+
+func (r *NadStruct) GetMtu() (int, error) {
+	existingNadConfig, err := r.getNadConfig()
+	if err != nil {
+		return 0, err
+	}
+	for _, plugin := range existingNadConfig.Plugins {
+		if isChainedPluginType(plugin.Type) {
+			continue
+		} else {
+			return plugin.Mtu, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r *NadStruct) GetResourceName() string {
+	return r.K.GetAnnotation(ResourceNameAnnotation)
+}
+
 func (r *NadStruct) GetIpamAddress() ([]Address, error) {
 	existingNadConfig, err := r.getNadConfig()
 	if err != nil {
 		return []Address{}, err
 	}
 	for _, plugin := range existingNadConfig.Plugins {
-		if plugin.Type == TuningType {
+		if isChainedPluginType(plugin.Type) {
 			continue
 		} else {
 			return plugin.Ipam.Addresses, nil
@@ -309,32 +471,103 @@ func (r *NadStruct) GetIpamAddress() ([]Address, error) {
 	return []Address{}, nil
 }
 
+func (r *NadStruct) GetIpamRoutes() ([]Route, error) {
+	existingNadConfig, err := r.getNadConfig()
+	if err != nil {
+		return []Route{}, err
+	}
+	for _, plugin := range existingNadConfig.Plugins {
+		if isChainedPluginType(plugin.Type) {
+			continue
+		} else {
+			return plugin.Ipam.Routes, nil
+		}
+	}
+	return []Route{}, nil
+}
+
+func (r *NadStruct) GetOvsTrunk() ([]VlanTrunk, error) {
+	existingNadConfig, err := r.getNadConfig()
+	if err != nil {
+		return nil, err
+	}
+	for _, plugin := range existingNadConfig.Plugins {
+		if isChainedPluginType(plugin.Type) {
+			continue
+		} else {
+			return plugin.Trunk, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetVlanTrunk returns the vlan trunk of the bridge or ovs cni
+func (r *NadStruct) GetVlanTrunk() ([]VlanTrunk, error) {
+	existingNadConfig, err := r.getNadConfig()
+	if err != nil {
+		return nil, err
+	}
+	for _, plugin := range existingNadConfig.Plugins {
+		if isChainedPluginType(plugin.Type) {
+			continue
+		} else if plugin.Type == BridgePluginType {
+			return plugin.VlanTrunk, nil
+		} else {
+			return plugin.Trunk, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetHostDevice returns the device name and pci address of the host-device cni
+func (r *NadStruct) GetHostDevice() (string, string, error) {
+	existingNadConfig, err := r.getNadConfig()
+	if err != nil {
+		return "", "", err
+	}
+	for _, plugin := range existingNadConfig.Plugins {
+		if isChainedPluginType(plugin.Type) {
+			continue
+		} else {
+			return plugin.Device, plugin.PciBusID, nil
+		}
+	}
+	return "", "", nil
+}
+
+func (r *NadStruct) GetIpamDNS() (*DNS, error) {
+	existingNadConfig, err := r.getNadConfig()
+	if err != nil {
+		return nil, err
+	}
+	for _, plugin := range existingNadConfig.Plugins {
+		if isChainedPluginType(plugin.Type) {
+			continue
+		} else {
+			return plugin.Ipam.DNS, nil
+		}
+	}
+	return nil, nil
+}
+
 // SetConfigSpec sets the spec attributes in the kubeobject according the go struct
 func (r *NadStruct) SetConfigSpec(spec *nadv1.NetworkAttachmentDefinitionSpec) error {
 	return r.K.SetNestedString(spec.Config, ConfigType...)
 }
 
 func (r *NadStruct) SetCNIType(cniType string) error {
-	switch cniSpecType := cniType; cniSpecType {
-	case "":
+	if cniType == "" {
 		return fmt.Errorf("unknown cniType")
-	case "ipvlan":
-		r.CniSpecType = IpVlanType
-	case "macvlan":
-		r.CniSpecType = MacVlanType
-	case "sriov":
-		r.CniSpecType = SriovType
-	case "vlan":
-		r.CniSpecType = VlanType
-	case "bridge":
-		r.CniSpecType = BridgeType
+	}
+	if cniSpecType, ok := cniSpecTypes[cniType]; ok {
+		r.CniSpecType = cniSpecType
 	}
 	nadConfigStruct, err := r.getNadConfig()
 	if err != nil {
 		return err
 	}
 	for i, plugin := range nadConfigStruct.Plugins {
-		if plugin.Type == TuningType {
+		if isChainedPluginType(plugin.Type) {
 			continue
 		} else {
 			nadConfigStruct.Plugins[i].Type = cniType
@@ -365,7 +598,7 @@ func (r *NadStruct) SetVlanID(vlanID int) error {
 			return err
 		}
 		for i, plugin := range nadConfigStruct.Plugins {
-			if plugin.Type == TuningType {
+			if isChainedPluginType(plugin.Type) {
 				continue
 			} else {
 				nadConfigStruct.Plugins[i].VlanId = vlanID
@@ -384,7 +617,7 @@ func (r *NadStruct) SetBridgeVlan(vlanID int) error {
 			return err
 		}
 		for i, plugin := range nadConfigStruct.Plugins {
-			if plugin.Type == TuningType {
+			if isChainedPluginType(plugin.Type) {
 				continue
 			} else {
 				nadConfigStruct.Plugins[i].Vlan = vlanID
@@ -403,7 +636,7 @@ func (r *NadStruct) SetBridgeTrunk(vlanID int) error {
 			return err
 		}
 		for i, plugin := range nadConfigStruct.Plugins {
-			if plugin.Type == TuningType {
+			if isChainedPluginType(plugin.Type) {
 				continue
 			} else {
 				nadConfigStruct.Plugins[i].VlanTrunk = []VlanTrunk{
@@ -426,7 +659,7 @@ func (r *NadStruct) SetBridgeName(vlanID int) error {
 			return err
 		}
 		for i, plugin := range nadConfigStruct.Plugins {
-			if plugin.Type == TuningType {
+			if isChainedPluginType(plugin.Type) {
 				continue
 			} else {
 				nadConfigStruct.Plugins[i].Bridge = fmt.Sprintf("cni%s", strconv.Itoa(vlanID))
@@ -437,6 +670,187 @@ func (r *NadStruct) SetBridgeName(vlanID int) error {
 	}
 }
 
+// SetOvsBridge sets the name of the OVS bridge the ovs cni attaches the pod interface to
+func (r *NadStruct) SetOvsBridge(bridgeName string) error {
+	if bridgeName == "" {
+		return fmt.Errorf("unknown ovs bridge name")
+	} else {
+		nadConfigStruct, err := r.getNadConfig()
+		if err != nil {
+			return err
+		}
+		for i, plugin := range nadConfigStruct.Plugins {
+			if isChainedPluginType(plugin.Type) {
+				continue
+			} else {
+				nadConfigStruct.Plugins[i].Bridge = bridgeName
+			}
+		}
+		return r.setNadConfig(nadConfigStruct)
+	}
+}
+
+// SetVlanTrunk sets the vlans and vlan ranges the bridge or ovs cni allows on a trunk port
+func (r *NadStruct) SetVlanTrunk(trunk []VlanTrunk) error {
+	if len(trunk) == 0 {
+		return fmt.Errorf("unknown vlan trunk")
+	}
+	for _, t := range trunk {
+		if err := t.validate(); err != nil {
+			return err
+		}
+	}
+	nadConfigStruct, err := r.getNadConfig()
+	if err != nil {
+		return err
+	}
+	for i, plugin := range nadConfigStruct.Plugins {
+		switch {
+		case isChainedPluginType(plugin.Type):
+			continue
+		case plugin.Type == BridgePluginType:
+			nadConfigStruct.Plugins[i].VlanTrunk = trunk
+		case plugin.Type == OvsPluginType:
+			nadConfigStruct.Plugins[i].Trunk = trunk
+		default:
+			return fmt.Errorf("vlan trunk not supported for cniType %s, supported cniTypes: %v", plugin.Type, []string{BridgePluginType, OvsPluginType})
+		}
+	}
+	return r.setNadConfig(nadConfigStruct)
+}
+
+// SetOvsTrunk sets the list of VLANs the ovs cni allows on a trunk port
+func (r *NadStruct) SetOvsTrunk(vlanIDs []int) error {
+	if len(vlanIDs) == 0 {
+		return fmt.Errorf("unknown vlanIDs")
+	} else {
+		nadConfigStruct, err := r.getNadConfig()
+		if err != nil {
+			return err
+		}
+		trunk := make([]VlanTrunk, 0, len(vlanIDs))
+		for _, vlanID := range vlanIDs {
+			if vlanID == 0 {
+				return fmt.Errorf("unknown vlanID")
+			}
+			trunk = append(trunk, VlanTrunk{ID: vlanID})
+		}
+		for i, plugin := range nadConfigStruct.Plugins {
+			if isChainedPluginType(plugin.Type) {
+				continue
+			} else {
+				nadConfigStruct.Plugins[i].Trunk = trunk
+			}
+		}
+		return r.setNadConfig(nadConfigStruct)
+	}
+}
+
+// SetHostDevice sets the device the host-device cni moves into the pod
+// The device is either selected by name or by pci address, the pci address takes precedence
+func (r *NadStruct) SetHostDevice(device, pciBusID string) error {
+	if device == "" && pciBusID == "" {
+		return fmt.Errorf("unknown host device, a device name or pci address is required")
+	} else {
+		nadConfigStruct, err := r.getNadConfig()
+		if err != nil {
+			return err
+		}
+		for i, plugin := range nadConfigStruct.Plugins {
+			if isChainedPluginType(plugin.Type) {
+				continue
+			} else {
+				if pciBusID != "" {
+					nadConfigStruct.Plugins[i].PciBusID = pciBusID
+					nadConfigStruct.Plugins[i].Device = ""
+				} else {
+					nadConfigStruct.Plugins[i].Device = device
+					nadConfigStruct.Plugins[i].PciBusID = ""
+				}
+			}
+		}
+		return r.setNadConfig(nadConfigStruct)
+	}
+}
+
+// SetIpVlanMode sets the mode of the ipvlan cni, one of l2, l3 or l3s
+func (r *NadStruct) SetIpVlanMode(mode string) error {
+	if mode != ModeL2 && mode != ModeL3 && mode != ModeL3S {
+		return fmt.Errorf("unknown ipvlan mode %s, supported modes: %s, %s, %s", mode, ModeL2, ModeL3, ModeL3S)
+	} else {
+		nadConfigStruct, err := r.getNadConfig()
+		if err != nil {
+			return err
+		}
+		for i, plugin := range nadConfigStruct.Plugins {
+			if isChainedPluginType(plugin.Type) {
+				continue
+			} else {
+				nadConfigStruct.Plugins[i].Mode = mode
+			}
+		}
+		return r.setNadConfig(nadConfigStruct)
+	}
+}
+
+// SetMacAddress sets a static mac address on the tuning plugin
+// the tuning plugin is added to the plugin chain when the cni type does not provide one
+func (r *NadStruct) SetMacAddress(mac string) error {
+	if _, err := net.ParseMAC(mac); err != nil {
+		return fmt.Errorf("unknown mac address %s, err: %s", mac, err.Error())
+	} else {
+		nadConfigStruct, err := r.getNadConfig()
+		if err != nil {
+			return err
+		}
+		found := false
+		for i, plugin := range nadConfigStruct.Plugins {
+			if plugin.Type == TuningType {
+				nadConfigStruct.Plugins[i].Capabilities.Mac = true
+				nadConfigStruct.Plugins[i].Mac = mac
+				found = true
+			}
+		}
+		if !found {
+			nadConfigStruct.Plugins = append(nadConfigStruct.Plugins, PluginCniType{
+				Type:         TuningType,
+				Capabilities: Capabilities{Mac: true},
+				Mac:          mac,
+			})
+		}
+		return r.setNadConfig(nadConfigStruct)
+	}
+}
+
+// SetMtu sets the mtu of the interface in the pod
+func (r *NadStruct) SetMtu(mtu int) error {
+	if mtu <= 0 {
+		return fmt.Errorf("invalid mtu %d", mtu)
+	} else {
+		nadConfigStruct, err := r.getNadConfig()
+		if err != nil {
+			return err
+		}
+		for i, plugin := range nadConfigStruct.Plugins {
+			if isChainedPluginType(plugin.Type) {
+				continue
+			} else {
+				nadConfigStruct.Plugins[i].Mtu = mtu
+			}
+		}
+		return r.setNadConfig(nadConfigStruct)
+	}
+}
+
+// SetResourceName sets the device plugin resource, e.g. an sriov resource pool,
+// the pods attached to the nad are scheduled on
+func (r *NadStruct) SetResourceName(resourceName string) error {
+	if resourceName == "" {
+		return fmt.Errorf("unknown resource name")
+	}
+	return r.K.SetAnnotation(ResourceNameAnnotation, resourceName)
+}
+
 func (r *NadStruct) SetNadMaster(nadMaster string) error {
 	if nadMaster == "" {
 		return fmt.Errorf("unknown nad master interface")
@@ -446,7 +860,7 @@ func (r *NadStruct) SetNadMaster(nadMaster string) error {
 			return err
 		}
 		for i, plugin := range nadConfigStruct.Plugins {
-			if plugin.Type == TuningType {
+			if isChainedPluginType(plugin.Type) {
 				continue
 			} else {
 				nadConfigStruct.Plugins[i].Master = nadMaster
@@ -465,7 +879,7 @@ func (r *NadStruct) SetIpamAddress(addresses []Address) error {
 			return err
 		}
 		for i, plugin := range nadConfigStruct.Plugins {
-			if plugin.Type == TuningType {
+			if isChainedPluginType(plugin.Type) {
 				continue
 			} else {
 				nadConfigStruct.Plugins[i].Ipam.Addresses = addresses
@@ -475,16 +889,23 @@ func (r *NadStruct) SetIpamAddress(addresses []Address) error {
 	}
 }
 
+// SetIpamRoutes sets the routes of the ipam; routes without a gateway are on-link routes,
+// a link scoped route is reachable directly on the interface of the nad
 func (r *NadStruct) SetIpamRoutes(routes []Route) error {
 	if routes == nil {
 		return nil
 	} else {
+		for _, route := range routes {
+			if err := route.validate(); err != nil {
+				return err
+			}
+		}
 		nadConfigStruct, err := r.getNadConfig()
 		if err != nil {
 			return err
 		}
 		for i, plugin := range nadConfigStruct.Plugins {
-			if plugin.Type == TuningType {
+			if isChainedPluginType(plugin.Type) {
 				continue
 			} else {
 				nadConfigStruct.Plugins[i].Ipam.Routes = routes
@@ -494,6 +915,274 @@ func (r *NadStruct) SetIpamRoutes(routes []Route) error {
 	}
 }
 
+func (r *NadStruct) SetIpamDNS(dns *DNS) error {
+	if dns == nil {
+		return nil
+	} else {
+		if len(dns.Nameservers) == 0 {
+			return fmt.Errorf("unknown dns nameservers")
+		}
+		nadConfigStruct, err := r.getNadConfig()
+		if err != nil {
+			return err
+		}
+		for i, plugin := range nadConfigStruct.Plugins {
+			if isChainedPluginType(plugin.Type) {
+				continue
+			} else {
+				nadConfigStruct.Plugins[i].Ipam.DNS = dns
+			}
+		}
+		return r.setNadConfig(nadConfigStruct)
+	}
+}
+
+// Merge merges the existing nad into the nad, preserving the annotations, labels and
+// cni config fields of the existing nad which are not generated.
+// Generated values take precedence over existing ones and plugins are matched by type
+func (r *NadStruct) Merge(existing *NadStruct) error {
+	if existing == nil {
+		return nil
+	}
+	annotations := existing.K.GetAnnotations()
+	for _, k := range sortedKeys(annotations) {
+		if _, ok := r.K.GetAnnotations()[k]; !ok {
+			if err := r.K.SetAnnotation(k, annotations[k]); err != nil {
+				return err
+			}
+		}
+	}
+	labels := existing.K.GetLabels()
+	for _, k := range sortedKeys(labels) {
+		if _, ok := r.K.GetLabels()[k]; !ok {
+			if err := r.K.SetLabel(k, labels[k]); err != nil {
+				return err
+			}
+		}
+	}
+	existingConfigSpec := existing.GetConfigSpec()
+	if existingConfigSpec == "" {
+		return nil
+	}
+	existingConfig := map[string]any{}
+	if err := json.Unmarshal([]byte(existingConfigSpec), &existingConfig); err != nil {
+		return fmt.Errorf("invalid existing NAD Config, %s", err)
+	}
+	// the plugin of a single plugin config, e.g. of a nad created by another tool, is merged
+	// with the generated plugin of the same type
+	if _, ok := existingConfig["type"]; ok {
+		normalized, err := normalizeConfig(existingConfig)
+		if err != nil {
+			return fmt.Errorf("invalid existing NAD Config, %s", err)
+		}
+		existingConfig = normalized
+	}
+	configSpec := r.GetConfigSpec()
+	if configSpec == "" {
+		configSpec = "{}"
+	}
+	config := map[string]any{}
+	if err := json.Unmarshal([]byte(configSpec), &config); err != nil {
+		return fmt.Errorf("invalid NAD Config, %s", err)
+	}
+	b, err := json.Marshal(mergeConfig(existingConfig, config, reflect.TypeOf(NadConfig{})))
+	if err != nil {
+		return err
+	}
+	return r.K.SetNestedString(string(b), ConfigType...)
+}
+
+// SetRawConfig replaces the cni config with a user supplied raw cni config, either a
+// plugin list or a single plugin, and merges the generated data of the nad into it:
+// the ipam addresses, routes and dns, the vlan and the master unless the raw config defines it
+// are merged into the first plugin which is not a tuning plugin, the mac address
+// is merged into the tuning plugin
+func (r *NadStruct) SetRawConfig(raw string) error {
+	rawConfig := map[string]any{}
+	if err := json.Unmarshal([]byte(raw), &rawConfig); err != nil {
+		return fmt.Errorf("invalid raw cni config, %s", err)
+	}
+	// a single plugin config is turned into a plugin list
+	rawConfig, err := normalizeConfig(rawConfig)
+	if err != nil {
+		return fmt.Errorf("invalid raw %s", err)
+	}
+	if _, ok := rawConfig["cniVersion"]; !ok {
+		rawConfig["cniVersion"] = CniVersion
+	}
+	plugins := rawConfig["plugins"].([]any)
+
+	generated, err := r.getNadConfig()
+	if err != nil {
+		return err
+	}
+	var generatedPlugin, generatedTuning *PluginCniType
+	for i, plugin := range generated.Plugins {
+		if plugin.Type == TuningType {
+			if generatedTuning == nil {
+				generatedTuning = &generated.Plugins[i]
+			}
+		} else if generatedPlugin == nil {
+			generatedPlugin = &generated.Plugins[i]
+		}
+	}
+
+	pluginDone := false
+	tuningDone := false
+	for _, p := range plugins {
+		plugin, ok := p.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid raw cni config, plugin must be an object")
+		}
+		if plugin["type"] == TuningType {
+			if generatedTuning != nil && generatedTuning.Mac != "" && !tuningDone {
+				capabilities, _ := plugin["capabilities"].(map[string]any)
+				if capabilities == nil {
+					capabilities = map[string]any{}
+				}
+				capabilities["mac"] = true
+				plugin["capabilities"] = capabilities
+				plugin["mac"] = generatedTuning.Mac
+				tuningDone = true
+			}
+			continue
+		}
+		if generatedPlugin == nil || pluginDone {
+			continue
+		}
+		if _, ok := plugin["master"]; !ok && generatedPlugin.Master != "" {
+			plugin["master"] = generatedPlugin.Master
+		}
+		if generatedPlugin.VlanId != 0 {
+			plugin["vlanId"] = generatedPlugin.VlanId
+		}
+		if generatedPlugin.Vlan != 0 {
+			plugin["vlan"] = generatedPlugin.Vlan
+		}
+		ipam, _ := plugin["ipam"].(map[string]any)
+		if ipam == nil {
+			ipam = map[string]any{}
+		}
+		if _, ok := ipam["type"]; !ok {
+			ipam["type"] = StaticNadType
+		}
+		if len(generatedPlugin.Ipam.Addresses) > 0 {
+			ipam["addresses"] = generatedPlugin.Ipam.Addresses
+		}
+		if len(generatedPlugin.Ipam.Routes) > 0 {
+			ipam["routes"] = generatedPlugin.Ipam.Routes
+		}
+		if generatedPlugin.Ipam.DNS != nil {
+			ipam["dns"] = generatedPlugin.Ipam.DNS
+		}
+		plugin["ipam"] = ipam
+		pluginDone = true
+	}
+	if generatedTuning != nil && generatedTuning.Mac != "" && !tuningDone {
+		rawConfig["plugins"] = append(plugins, *generatedTuning)
+	}
+
+	b, err := json.Marshal(rawConfig)
+	if err != nil {
+		return err
+	}
+	return r.K.SetNestedString(string(b), ConfigType...)
+}
+
+// mergeConfig merges the generated cni config on top of the existing cni config
+// fields known to the config type t are owned by the generated config, unknown fields
+// of the existing config are preserved
+func mergeConfig(existing, generated map[string]any, t reflect.Type) map[string]any {
+	fields := jsonFields(t)
+	merged := map[string]any{}
+	for k, v := range existing {
+		if _, ok := fields[k]; !ok {
+			merged[k] = v
+		}
+		if _, ok := generated[k]; !ok && tunableFields[k] {
+			merged[k] = v
+		}
+	}
+	for k, v := range generated {
+		ft := fields[k]
+		switch gv := v.(type) {
+		case map[string]any:
+			if ev, ok := existing[k].(map[string]any); ok && ft != nil && ft.Kind() == reflect.Struct {
+				merged[k] = mergeConfig(ev, gv, ft)
+				continue
+			}
+		case []any:
+			if ev, ok := existing[k].([]any); ok && ft != nil && ft.Kind() == reflect.Slice && ft.Elem() == reflect.TypeOf(PluginCniType{}) {
+				merged[k] = mergePlugins(ev, gv)
+				continue
+			}
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// mergePlugins merges the generated plugins on top of the existing plugins with the same type
+// existing plugins without a generated counterpart are kept at the end of the plugin list
+func mergePlugins(existing, generated []any) []any {
+	merged := make([]any, 0, len(generated))
+	used := make([]bool, len(existing))
+	for _, g := range generated {
+		gp, ok := g.(map[string]any)
+		if !ok {
+			merged = append(merged, g)
+			continue
+		}
+		found := false
+		for i, e := range existing {
+			ep, ok := e.(map[string]any)
+			if !ok || used[i] || ep["type"] != gp["type"] {
+				continue
+			}
+			merged = append(merged, mergeConfig(ep, gp, reflect.TypeOf(PluginCniType{})))
+			used[i] = true
+			found = true
+			break
+		}
+		if !found {
+			merged = append(merged, gp)
+		}
+	}
+	for i, e := range existing {
+		if !used[i] {
+			merged = append(merged, e)
+		}
+	}
+	return merged
+}
+
+// sortedKeys returns the keys of the map in a deterministic order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonFields returns the types of the fields of struct type t indexed by their json name
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		ft := t.Field(i).Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		fields[name] = ft
+	}
+	return fields
+}
+
 func (n *NadConfig) String() (string, error) {
 	b, err := json.Marshal(n)
 	if err != nil {
@@ -54,6 +54,15 @@ spec:
   config: '{"cniVersion":"0.3.1","plugins":[{"type":"macvlan","capabilities":{"ips":true},"master":"eth1","mode":"bridge","ipam":{"type":"static","addresses":[{"address":"14.0.0.2/24","gateway":"14.0.0.1"}]}},{"type":"tuning","capabilities":{"mac":true},"ipam":{}}]}'
 `
 
+var nadTestOvs = `apiVersion: "k8s.cni.cncf.io/v1"
+kind: NetworkAttachmentDefinition
+metadata:
+  creationTimestamp: null
+  name: upf-us-central1-n3
+spec:
+  config: '{"cniVersion":"0.3.1","plugins":[{"type":"ovs","bridge":"br1","vlan":100,"ipam":{"type":"static","addresses":[{"address":"14.0.0.2/24","gateway":"14.0.0.1"}]}}]}'
+`
+
 var nadTestEmpty = `apiVersion: "k8s.cni.cncf.io/v1"
 kind: NetworkAttachmentDefinition
 metadata:
@@ -391,6 +400,85 @@ func TestGetIpamAddress(t *testing.T) {
 
 }
 
+func TestGetIpamRoutes(t *testing.T) {
+
+	cases := map[string]struct {
+		file   string
+		routes []Route
+		want   []Route
+	}{
+		"GetIpamRoutesNormal": {
+			file: nadTestSriov,
+			routes: []Route{
+				{Destination: "0.0.0.0/0", Gateway: "10.0.0.1"},
+			},
+			want: []Route{
+				{Destination: "0.0.0.0/0", Gateway: "10.0.0.1"},
+			},
+		},
+		"GetIpamRoutesOnLink": {
+			file: nadTestSriov,
+			routes: []Route{
+				NewOnLinkRoute("10.1.0.0/16"),
+				{Destination: "10.2.0.0/16"},
+			},
+			want: []Route{
+				{Destination: "10.1.0.0/16", Scope: RouteScopeLink},
+				{Destination: "10.2.0.0/16"},
+			},
+		},
+		"GetIpamRoutesEmpty": {
+			file: nadTestEmpty,
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		i, err := NewFromYAML([]byte(tc.file))
+		if err != nil {
+			t.Errorf("cannot unmarshal file: %s", err.Error())
+		}
+
+		t.Run(name, func(t *testing.T) {
+			if tc.routes != nil {
+				if err := i.SetIpamRoutes(tc.routes); err != nil {
+					t.Errorf("cannot set ipam routes: %s", err.Error())
+				}
+			}
+			got, err := i.GetIpamRoutes()
+			if err != nil {
+				t.Errorf("cannot get ipam routes: %s", err.Error())
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("TestGetIpamRoutes: -want, +got:\n%s", diff)
+			}
+		})
+	}
+
+}
+
+func TestSetIpamRoutesInvalid(t *testing.T) {
+	cases := map[string]Route{
+		"InvalidDestination":    {Destination: "10.1.0.0"},
+		"InvalidGateway":        {Destination: "10.1.0.0/16", Gateway: "10.0.0"},
+		"GatewayFamilyMismatch": {Destination: "10.1.0.0/16", Gateway: "2001:db8::1"},
+		"LinkScopeWithGateway":  {Destination: "10.1.0.0/16", Gateway: "10.0.0.1", Scope: RouteScopeLink},
+		"InvalidScope":          {Destination: "10.1.0.0/16", Scope: 1},
+	}
+
+	for name, route := range cases {
+		t.Run(name, func(t *testing.T) {
+			i, err := NewFromYAML([]byte(nadTestSriov))
+			if err != nil {
+				t.Fatalf("cannot unmarshal file: %s", err.Error())
+			}
+			if err := i.SetIpamRoutes([]Route{route}); err == nil {
+				t.Errorf("expected an error for route %v", route)
+			}
+		})
+	}
+}
+
 func TestSetConfigSpec(t *testing.T) {
 	cases := map[string]struct {
 		file        string
@@ -487,6 +575,11 @@ func TestSetCNIType(t *testing.T) {
 			value:       "macvlan",
 			errExpected: false,
 		},
+		"SetNewAttachmentTypeOvs": {
+			file:        nadTestEmpty,
+			value:       "ovs",
+			errExpected: false,
+		},
 	}
 
 	for name, tc := range cases {
@@ -648,3 +741,541 @@ func TestSetNadAddress(t *testing.T) {
 	}
 
 }
+
+func TestSetOvsTrunk(t *testing.T) {
+	cases := map[string]struct {
+		file        string
+		value       []int
+		want        []VlanTrunk
+		errExpected bool
+	}{
+		"SetOvsTrunkNormal": {
+			file:        nadTestOvs,
+			value:       []int{100, 200},
+			want:        []VlanTrunk{{ID: 100}, {ID: 200}},
+			errExpected: false,
+		},
+		"SetOvsTrunkEmpty": {
+			file:        nadTestOvs,
+			value:       nil,
+			errExpected: true,
+		},
+		"SetOvsTrunkInvalidVlan": {
+			file:        nadTestOvs,
+			value:       []int{100, 0},
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		i, err := NewFromYAML([]byte(tc.file))
+		if err != nil {
+			t.Errorf("cannot unmarshal file: %s", err.Error())
+		}
+
+		t.Run(name, func(t *testing.T) {
+			err := i.SetOvsTrunk(tc.value)
+			if tc.errExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				got, err := i.GetOvsTrunk()
+				if err != nil {
+					t.Errorf("cannot get ovs trunk: %s", err.Error())
+				}
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("TestSetOvsTrunk: -want, +got:\n%s", diff)
+				}
+			}
+
+		})
+	}
+
+}
+
+func TestSetVlanTrunk(t *testing.T) {
+	cases := map[string]struct {
+		file        string
+		value       []VlanTrunk
+		errExpected bool
+	}{
+		"SetVlanTrunkOvs": {
+			file:        nadTestOvs,
+			value:       []VlanTrunk{{ID: 100}, {MinID: 200, MaxID: 209}},
+			errExpected: false,
+		},
+		"SetVlanTrunkBridge": {
+			file:        nadTestBridge,
+			value:       []VlanTrunk{{MinID: 200, MaxID: 209}},
+			errExpected: false,
+		},
+		"SetVlanTrunkEmpty": {
+			file:        nadTestOvs,
+			value:       nil,
+			errExpected: true,
+		},
+		"SetVlanTrunkInvalidRange": {
+			file:        nadTestOvs,
+			value:       []VlanTrunk{{MinID: 209, MaxID: 200}},
+			errExpected: true,
+		},
+		"SetVlanTrunkIdAndRange": {
+			file:        nadTestOvs,
+			value:       []VlanTrunk{{ID: 100, MinID: 200, MaxID: 209}},
+			errExpected: true,
+		},
+		"SetVlanTrunkUnsupportedCni": {
+			file:        nadTestSriov,
+			value:       []VlanTrunk{{MinID: 200, MaxID: 209}},
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		i, err := NewFromYAML([]byte(tc.file))
+		if err != nil {
+			t.Errorf("cannot unmarshal file: %s", err.Error())
+		}
+
+		t.Run(name, func(t *testing.T) {
+			err := i.SetVlanTrunk(tc.value)
+			if tc.errExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				got, err := i.GetVlanTrunk()
+				if err != nil {
+					t.Errorf("cannot get vlan trunk: %s", err.Error())
+				}
+				if diff := cmp.Diff(tc.value, got); diff != "" {
+					t.Errorf("TestSetVlanTrunk: -want, +got:\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestSetHostDevice(t *testing.T) {
+	cases := map[string]struct {
+		file           string
+		device         string
+		pciBusID       string
+		wantDevice     string
+		wantPciAddress string
+		errExpected    bool
+	}{
+		"SetHostDeviceName": {
+			file:        nadTestEmpty,
+			device:      "ens3f1",
+			wantDevice:  "ens3f1",
+			errExpected: false,
+		},
+		"SetHostDevicePciAddress": {
+			file:           nadTestEmpty,
+			pciBusID:       "0000:3b:00.1",
+			wantPciAddress: "0000:3b:00.1",
+			errExpected:    false,
+		},
+		"SetHostDevicePciAddressPrecedence": {
+			file:           nadTestEmpty,
+			device:         "ens3f1",
+			pciBusID:       "0000:3b:00.1",
+			wantPciAddress: "0000:3b:00.1",
+			errExpected:    false,
+		},
+		"SetHostDeviceEmpty": {
+			file:        nadTestEmpty,
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		i, err := NewFromYAML([]byte(tc.file))
+		if err != nil {
+			t.Errorf("cannot unmarshal file: %s", err.Error())
+		}
+
+		t.Run(name, func(t *testing.T) {
+			if err := i.SetCNIType("host-device"); err != nil {
+				t.Errorf("cannot set cniType: %s", err.Error())
+			}
+			err := i.SetHostDevice(tc.device, tc.pciBusID)
+			if tc.errExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				gotDevice, gotPciAddress, err := i.GetHostDevice()
+				if err != nil {
+					t.Errorf("cannot get host device: %s", err.Error())
+				}
+				if diff := cmp.Diff(tc.wantDevice, gotDevice); diff != "" {
+					t.Errorf("TestSetHostDevice device: -want, +got:\n%s", diff)
+				}
+				if diff := cmp.Diff(tc.wantPciAddress, gotPciAddress); diff != "" {
+					t.Errorf("TestSetHostDevice pciBusID: -want, +got:\n%s", diff)
+				}
+			}
+
+		})
+	}
+
+}
+
+func TestSetIpamDNS(t *testing.T) {
+	cases := map[string]struct {
+		file        string
+		value       *DNS
+		want        *DNS
+		errExpected bool
+	}{
+		"SetIpamDNSNormal": {
+			file: nadTestSriov,
+			value: &DNS{
+				Nameservers: []string{"10.0.0.53"},
+				Search:      []string{"example.com"},
+			},
+			want: &DNS{
+				Nameservers: []string{"10.0.0.53"},
+				Search:      []string{"example.com"},
+			},
+			errExpected: false,
+		},
+		"SetIpamDNSNil": {
+			file:        nadTestSriov,
+			value:       nil,
+			want:        nil,
+			errExpected: false,
+		},
+		"SetIpamDNSNoNameservers": {
+			file:        nadTestSriov,
+			value:       &DNS{Search: []string{"example.com"}},
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		i, err := NewFromYAML([]byte(tc.file))
+		if err != nil {
+			t.Errorf("cannot unmarshal file: %s", err.Error())
+		}
+
+		t.Run(name, func(t *testing.T) {
+			err := i.SetIpamDNS(tc.value)
+			if tc.errExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				got, err := i.GetIpamDNS()
+				if err != nil {
+					t.Errorf("cannot get ipam dns: %s", err.Error())
+				}
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("TestSetIpamDNS: -want, +got:\n%s", diff)
+				}
+			}
+
+		})
+	}
+
+}
+
+func TestMerge(t *testing.T) {
+	cases := map[string]struct {
+		file            string
+		existing        string
+		wantConfig      string
+		wantAnnotations map[string]string
+	}{
+		"MergeUnknownFields": {
+			file: nadTestIpVlan,
+			existing: `apiVersion: "k8s.cni.cncf.io/v1"
+kind: NetworkAttachmentDefinition
+metadata:
+  name: upf-us-central1-n3
+  annotations:
+    example.com/owner: operator
+spec:
+  config: '{"cniVersion":"0.3.1","name":"n3","plugins":[{"type":"ipvlan","master":"eth0","mode":"l2","mtu":9000,"ipam":{"type":"static","addresses":[{"address":"16.0.0.9/24"}],"routes":[{"dst":"0.0.0.0/0"}]}},{"type":"portmap","capabilities":{"portMappings":true}}]}'
+`,
+			wantConfig:      `{"cniVersion":"0.3.1","name":"n3","plugins":[{"capabilities":{"ips":true},"ipam":{"addresses":[{"address":"16.0.0.2/24","gateway":"16.0.0.1"}],"type":"static"},"master":"eth1","mode":"l2","mtu":9000,"type":"ipvlan"},{"capabilities":{"portMappings":true},"type":"portmap"}]}`,
+			wantAnnotations: map[string]string{"example.com/owner": "operator"},
+		},
+		"MergeEmptyExisting": {
+			file:            nadTestIpVlan,
+			existing:        nadTestEmpty,
+			wantConfig:      `{"cniVersion":"0.3.1","plugins":[{"type":"ipvlan","capabilities":{"ips":true},"master":"eth1","mode":"l2","ipam":{"type":"static","addresses":[{"address":"16.0.0.2/24","gateway":"16.0.0.1"}]}}]}`,
+			wantAnnotations: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		i, err := NewFromYAML([]byte(tc.file))
+		if err != nil {
+			t.Errorf("cannot unmarshal file: %s", err.Error())
+		}
+		existing, err := NewFromYAML([]byte(tc.existing))
+		if err != nil {
+			t.Errorf("cannot unmarshal file: %s", err.Error())
+		}
+
+		t.Run(name, func(t *testing.T) {
+			err := i.Merge(existing)
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tc.wantConfig, i.GetConfigSpec()); diff != "" {
+				t.Errorf("TestMerge: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantAnnotations, i.K.GetAnnotations()); diff != "" {
+				t.Errorf("TestMerge: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetIpVlanMode(t *testing.T) {
+	cases := map[string]struct {
+		file        string
+		value       string
+		want        string
+		errExpected bool
+	}{
+		"SetIpVlanModeL3": {
+			file:        nadTestIpVlan,
+			value:       ModeL3,
+			want:        ModeL3,
+			errExpected: false,
+		},
+		"SetIpVlanModeL3S": {
+			file:        nadTestIpVlan,
+			value:       ModeL3S,
+			want:        ModeL3S,
+			errExpected: false,
+		},
+		"SetIpVlanModeUnknown": {
+			file:        nadTestIpVlan,
+			value:       "l4",
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		i, err := NewFromYAML([]byte(tc.file))
+		if err != nil {
+			t.Errorf("cannot unmarshal file: %s", err.Error())
+		}
+
+		t.Run(name, func(t *testing.T) {
+			err := i.SetIpVlanMode(tc.value)
+			if tc.errExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				got, err := i.GetMode()
+				if err != nil {
+					t.Errorf("cannot get mode: %s", err.Error())
+				}
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("TestSetIpVlanMode: -want, +got:\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestSetMacAddress(t *testing.T) {
+	cases := map[string]struct {
+		file        string
+		value       string
+		want        string
+		wantPlugins int
+		errExpected bool
+	}{
+		"SetMacAddressExistingTuning": {
+			file:        nadTestMacVlan,
+			value:       "02:00:00:00:00:01",
+			want:        "02:00:00:00:00:01",
+			wantPlugins: 2,
+			errExpected: false,
+		},
+		"SetMacAddressNewTuning": {
+			file:        nadTestSriov,
+			value:       "02:00:00:00:00:01",
+			want:        "02:00:00:00:00:01",
+			wantPlugins: 2,
+			errExpected: false,
+		},
+		"SetMacAddressInvalid": {
+			file:        nadTestSriov,
+			value:       "02:00:00:00:00",
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		i, err := NewFromYAML([]byte(tc.file))
+		if err != nil {
+			t.Errorf("cannot unmarshal file: %s", err.Error())
+		}
+
+		t.Run(name, func(t *testing.T) {
+			err := i.SetMacAddress(tc.value)
+			if tc.errExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				got, err := i.GetMacAddress()
+				if err != nil {
+					t.Errorf("cannot get mac address: %s", err.Error())
+				}
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("TestSetMacAddress: -want, +got:\n%s", diff)
+				}
+				config, err := i.getNadConfig()
+				if err != nil {
+					t.Errorf("cannot get nad config: %s", err.Error())
+				}
+				assert.Equal(t, tc.wantPlugins, len(config.Plugins))
+			}
+		})
+	}
+}
+
+func TestSetRawConfig(t *testing.T) {
+	cases := map[string]struct {
+		file        string
+		raw         string
+		want        string
+		errExpected bool
+	}{
+		"SetRawConfigSinglePlugin": {
+			file:        nadTestIpVlan,
+			raw:         `{"cniVersion":"0.4.0","type":"custom","mtu":1400}`,
+			want:        `{"cniVersion":"0.4.0","plugins":[{"ipam":{"addresses":[{"address":"16.0.0.2/24","gateway":"16.0.0.1"}],"type":"static"},"master":"eth1","mtu":1400,"type":"custom"}]}`,
+			errExpected: false,
+		},
+		"SetRawConfigPluginListOwnMaster": {
+			file:        nadTestOvs,
+			raw:         `{"name":"n3","plugins":[{"type":"custom","master":"ens1","ipam":{"type":"host-local"}},{"type":"portmap","capabilities":{"portMappings":true}}]}`,
+			want:        `{"cniVersion":"0.3.1","name":"n3","plugins":[{"ipam":{"addresses":[{"address":"14.0.0.2/24","gateway":"14.0.0.1"}],"type":"host-local"},"master":"ens1","type":"custom","vlan":100},{"capabilities":{"portMappings":true},"type":"portmap"}]}`,
+			errExpected: false,
+		},
+		"SetRawConfigInvalidJSON": {
+			file:        nadTestIpVlan,
+			raw:         `{"type":`,
+			errExpected: true,
+		},
+		"SetRawConfigNoPlugin": {
+			file:        nadTestIpVlan,
+			raw:         `{"cniVersion":"0.3.1"}`,
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		i, err := NewFromYAML([]byte(tc.file))
+		if err != nil {
+			t.Errorf("cannot unmarshal file: %s", err.Error())
+		}
+
+		t.Run(name, func(t *testing.T) {
+			err := i.SetRawConfig(tc.raw)
+			if tc.errExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				if diff := cmp.Diff(tc.want, i.GetConfigSpec()); diff != "" {
+					t.Errorf("TestSetRawConfig: -want, +got:\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestSetMtu(t *testing.T) {
+	cases := map[string]struct {
+		file        string
+		value       int
+		want        int
+		errExpected bool
+	}{
+		"SetMtuIpVlan": {
+			file:        nadTestIpVlan,
+			value:       9000,
+			want:        9000,
+			errExpected: false,
+		},
+		"SetMtuMacVlan": {
+			file:        nadTestMacVlan,
+			value:       1500,
+			want:        1500,
+			errExpected: false,
+		},
+		"SetMtuInvalid": {
+			file:        nadTestIpVlan,
+			value:       0,
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		i, err := NewFromYAML([]byte(tc.file))
+		if err != nil {
+			t.Errorf("cannot unmarshal file: %s", err.Error())
+		}
+
+		t.Run(name, func(t *testing.T) {
+			err := i.SetMtu(tc.value)
+			if tc.errExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				got, err := i.GetMtu()
+				if err != nil {
+					t.Errorf("cannot get mtu: %s", err.Error())
+				}
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("TestSetMtu: -want, +got:\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestSetResourceName(t *testing.T) {
+	cases := map[string]struct {
+		file        string
+		value       string
+		want        string
+		errExpected bool
+	}{
+		"SetResourceName": {
+			file:        nadTestSriov,
+			value:       "intel.com/sriov_netdevice_n3",
+			want:        "intel.com/sriov_netdevice_n3",
+			errExpected: false,
+		},
+		"SetResourceNameEmpty": {
+			file:        nadTestSriov,
+			value:       "",
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		i, err := NewFromYAML([]byte(tc.file))
+		if err != nil {
+			t.Errorf("cannot unmarshal file: %s", err.Error())
+		}
+
+		t.Run(name, func(t *testing.T) {
+			err := i.SetResourceName(tc.value)
+			if tc.errExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				if diff := cmp.Diff(tc.want, i.GetResourceName()); diff != "" {
+					t.Errorf("TestSetResourceName: -want, +got:\n%s", diff)
+				}
+			}
+		})
+	}
+}
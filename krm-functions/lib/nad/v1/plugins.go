@@ -0,0 +1,173 @@
+/*
+Copyright 2023 Nephio.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	MacVlanPluginType    = "macvlan"
+	IpVlanPluginType     = "ipvlan"
+	SriovPluginType      = "sriov"
+	BridgePluginType     = "bridge"
+	VlanPluginType       = "vlan"
+	HostDevicePluginType = "host-device"
+	OvsPluginType        = "ovs"
+)
+
+// MacVlanConfig is the config of the macvlan cni plugin
+type MacVlanConfig struct {
+	Type         string       `json:"type"`
+	Capabilities Capabilities `json:"capabilities,omitempty"`
+	Master       string       `json:"master,omitempty"`
+	Mode         string       `json:"mode,omitempty"`
+	Mtu          int          `json:"mtu,omitempty"`
+	Ipam         Ipam         `json:"ipam,omitempty"`
+}
+
+// IpVlanConfig is the config of the ipvlan cni plugin
+type IpVlanConfig struct {
+	Type         string       `json:"type"`
+	Capabilities Capabilities `json:"capabilities,omitempty"`
+	Master       string       `json:"master,omitempty"`
+	Mode         string       `json:"mode,omitempty"`
+	Mtu          int          `json:"mtu,omitempty"`
+	Ipam         Ipam         `json:"ipam,omitempty"`
+}
+
+// SriovConfig is the config of the sriov cni plugin
+type SriovConfig struct {
+	Type         string       `json:"type"`
+	Capabilities Capabilities `json:"capabilities,omitempty"`
+	Master       string       `json:"master,omitempty"`
+	Mode         string       `json:"mode,omitempty"`
+	DeviceID     string       `json:"deviceID,omitempty"`
+	Vlan         int          `json:"vlan,omitempty"`
+	Mtu          int          `json:"mtu,omitempty"`
+	Ipam         Ipam         `json:"ipam,omitempty"`
+}
+
+// BridgeConfig is the config of the bridge cni plugin
+type BridgeConfig struct {
+	Type         string       `json:"type"`
+	Capabilities Capabilities `json:"capabilities,omitempty"`
+	Bridge       string       `json:"bridge,omitempty"`
+	Name         string       `json:"name,omitempty"`
+	Vlan         int          `json:"vlan,omitempty"`
+	VlanTrunk    []VlanTrunk  `json:"vlanTrunk,omitempty"`
+	Mtu          int          `json:"mtu,omitempty"`
+	Ipam         Ipam         `json:"ipam,omitempty"`
+}
+
+// VlanConfig is the config of the vlan cni plugin
+type VlanConfig struct {
+	Type            string       `json:"type"`
+	Capabilities    Capabilities `json:"capabilities,omitempty"`
+	Master          string       `json:"master,omitempty"`
+	VlanId          int          `json:"vlanId,omitempty"`
+	LinkInContainer bool         `json:"linkInContainer,omitempty"`
+	Mtu             int          `json:"mtu,omitempty"`
+	Ipam            Ipam         `json:"ipam,omitempty"`
+}
+
+// HostDeviceConfig is the config of the host-device cni plugin
+type HostDeviceConfig struct {
+	Type         string       `json:"type"`
+	Capabilities Capabilities `json:"capabilities,omitempty"`
+	Device       string       `json:"device,omitempty"`
+	PciBusID     string       `json:"pciBusID,omitempty"`
+	Ipam         Ipam         `json:"ipam,omitempty"`
+}
+
+// ParsePluginConfig parses the config of the plugin with type pluginType from a cni config
+// into the typed config T, e.g. MacVlanConfig. The cni config is either a plugin list or a
+// single plugin config
+func ParsePluginConfig[T any](config, pluginType string) (*T, error) {
+	raw, err := getRawPluginConfig(config, pluginType)
+	if err != nil {
+		return nil, err
+	}
+	var x T
+	if err := json.Unmarshal(raw, &x); err != nil {
+		return nil, fmt.Errorf("invalid %s plugin config, %s", pluginType, err)
+	}
+	return &x, nil
+}
+
+// getRawPluginConfig returns the raw config of the first plugin with type pluginType of the cni config
+func getRawPluginConfig(config, pluginType string) (json.RawMessage, error) {
+	if config == "" {
+		return nil, fmt.Errorf("no %s plugin in an empty NAD config", pluginType)
+	}
+	conf := struct {
+		Type    string            `json:"type,omitempty"`
+		Plugins []json.RawMessage `json:"plugins,omitempty"`
+	}{}
+	if err := json.Unmarshal([]byte(config), &conf); err != nil {
+		return nil, fmt.Errorf("invalid NAD Config, %s", err)
+	}
+	if conf.Plugins == nil {
+		if conf.Type == pluginType {
+			return json.RawMessage(config), nil
+		}
+		return nil, fmt.Errorf("no %s plugin in the NAD config", pluginType)
+	}
+	for _, p := range conf.Plugins {
+		plugin := struct {
+			Type string `json:"type,omitempty"`
+		}{}
+		if err := json.Unmarshal(p, &plugin); err != nil {
+			return nil, fmt.Errorf("invalid NAD Config, %s", err)
+		}
+		if plugin.Type == pluginType {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s plugin in the NAD config", pluginType)
+}
+
+// GetMacVlanConfig returns the config of the macvlan plugin of the nad
+func (r *NadStruct) GetMacVlanConfig() (*MacVlanConfig, error) {
+	return ParsePluginConfig[MacVlanConfig](r.GetConfigSpec(), MacVlanPluginType)
+}
+
+// GetIpVlanConfig returns the config of the ipvlan plugin of the nad
+func (r *NadStruct) GetIpVlanConfig() (*IpVlanConfig, error) {
+	return ParsePluginConfig[IpVlanConfig](r.GetConfigSpec(), IpVlanPluginType)
+}
+
+// GetSriovConfig returns the config of the sriov plugin of the nad
+func (r *NadStruct) GetSriovConfig() (*SriovConfig, error) {
+	return ParsePluginConfig[SriovConfig](r.GetConfigSpec(), SriovPluginType)
+}
+
+// GetBridgeConfig returns the config of the bridge plugin of the nad
+func (r *NadStruct) GetBridgeConfig() (*BridgeConfig, error) {
+	return ParsePluginConfig[BridgeConfig](r.GetConfigSpec(), BridgePluginType)
+}
+
+// GetVlanConfig returns the config of the vlan plugin of the nad
+func (r *NadStruct) GetVlanConfig() (*VlanConfig, error) {
+	return ParsePluginConfig[VlanConfig](r.GetConfigSpec(), VlanPluginType)
+}
+
+// GetHostDeviceConfig returns the config of the host-device plugin of the nad
+func (r *NadStruct) GetHostDeviceConfig() (*HostDeviceConfig, error) {
+	return ParsePluginConfig[HostDeviceConfig](r.GetConfigSpec(), HostDevicePluginType)
+}
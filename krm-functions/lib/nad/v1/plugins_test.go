@@ -0,0 +1,150 @@
+/*
+Copyright 2023 Nephio.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+var nadTestVlan = `apiVersion: "k8s.cni.cncf.io/v1"
+kind: NetworkAttachmentDefinition
+metadata:
+  name: upf-us-central1-n3
+spec:
+  config: '{"cniVersion":"0.3.1","plugins":[{"type":"vlan","master":"eth1","vlanId":100,"linkInContainer":true,"ipam":{"type":"static","addresses":[{"address":"14.0.0.2/24","gateway":"14.0.0.1"}]}}]}'
+`
+
+var nadTestBridge = `apiVersion: "k8s.cni.cncf.io/v1"
+kind: NetworkAttachmentDefinition
+metadata:
+  name: upf-us-central1-n3
+spec:
+  config: '{"cniVersion":"0.3.1","plugins":[{"type":"bridge","bridge":"cni100","name":"cni100","vlan":100,"ipam":{"type":"static"}}]}'
+`
+
+var nadTestHostDevice = `apiVersion: "k8s.cni.cncf.io/v1"
+kind: NetworkAttachmentDefinition
+metadata:
+  name: upf-us-central1-n3
+spec:
+  config: '{"cniVersion":"0.3.1","type":"host-device","pciBusID":"0000:00:1f.6","ipam":{"type":"static"}}'
+`
+
+func TestGetPluginConfig(t *testing.T) {
+	cases := map[string]struct {
+		input       string
+		get         func(*NadStruct) (any, error)
+		want        any
+		errExpected bool
+	}{
+		"MacVlan": {
+			input: nadTestMacVlan,
+			get:   func(r *NadStruct) (any, error) { return r.GetMacVlanConfig() },
+			want: &MacVlanConfig{
+				Type:         MacVlanPluginType,
+				Capabilities: Capabilities{Ips: true},
+				Master:       "eth1",
+				Mode:         ModeBridge,
+				Ipam:         Ipam{Type: StaticNadType, Addresses: []Address{{Address: "14.0.0.2/24", Gateway: "14.0.0.1"}}},
+			},
+		},
+		"IpVlan": {
+			input: nadTestIpVlan,
+			get:   func(r *NadStruct) (any, error) { return r.GetIpVlanConfig() },
+			want: &IpVlanConfig{
+				Type:         IpVlanPluginType,
+				Capabilities: Capabilities{Ips: true},
+				Master:       "eth1",
+				Mode:         ModeL2,
+				Ipam:         Ipam{Type: StaticNadType, Addresses: []Address{{Address: "16.0.0.2/24", Gateway: "16.0.0.1"}}},
+			},
+		},
+		"Sriov": {
+			input: nadTestSriov,
+			get:   func(r *NadStruct) (any, error) { return r.GetSriovConfig() },
+			want: &SriovConfig{
+				Type:         SriovPluginType,
+				Capabilities: Capabilities{Ips: true},
+				Master:       "bond0",
+				Mode:         ModeBridge,
+				Ipam:         Ipam{Type: StaticNadType, Addresses: []Address{{Address: "10.0.0.3/24", Gateway: "10.0.0.1"}}},
+			},
+		},
+		"Vlan": {
+			input: nadTestVlan,
+			get:   func(r *NadStruct) (any, error) { return r.GetVlanConfig() },
+			want: &VlanConfig{
+				Type:            VlanPluginType,
+				Master:          "eth1",
+				VlanId:          100,
+				LinkInContainer: true,
+				Ipam:            Ipam{Type: StaticNadType, Addresses: []Address{{Address: "14.0.0.2/24", Gateway: "14.0.0.1"}}},
+			},
+		},
+		"Bridge": {
+			input: nadTestBridge,
+			get:   func(r *NadStruct) (any, error) { return r.GetBridgeConfig() },
+			want: &BridgeConfig{
+				Type:   BridgePluginType,
+				Bridge: "cni100",
+				Name:   "cni100",
+				Vlan:   100,
+				Ipam:   Ipam{Type: StaticNadType},
+			},
+		},
+		"HostDeviceSinglePlugin": {
+			input: nadTestHostDevice,
+			get:   func(r *NadStruct) (any, error) { return r.GetHostDeviceConfig() },
+			want: &HostDeviceConfig{
+				Type:     HostDevicePluginType,
+				PciBusID: "0000:00:1f.6",
+				Ipam:     Ipam{Type: StaticNadType},
+			},
+		},
+		"PluginNotPresent": {
+			input:       nadTestIpVlan,
+			get:         func(r *NadStruct) (any, error) { return r.GetMacVlanConfig() },
+			errExpected: true,
+		},
+		"EmptyConfig": {
+			input:       nadTestEmpty,
+			get:         func(r *NadStruct) (any, error) { return r.GetSriovConfig() },
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			nad, err := NewFromYAML([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("cannot parse nad: %s", err.Error())
+			}
+			got, err := tc.get(nad)
+			if tc.errExpected {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2023 Nephio.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"path"
+	"sort"
+	"strings"
+)
+
+// schemaFS bundles the json schemas of the plugin and ipam configs; the schema of a plugin
+// is named plugin-<type>.json and the schema of an ipam is named ipam-<type>.json
+//
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+// schema is the subset of json schema used by the bundled schemas
+type schema struct {
+	Type       string             `json:"type,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Properties map[string]*schema `json:"properties,omitempty"`
+	Items      *schema            `json:"items,omitempty"`
+	Enum       []any              `json:"enum,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Minimum    *float64           `json:"minimum,omitempty"`
+	Maximum    *float64           `json:"maximum,omitempty"`
+}
+
+// getSchema returns the bundled schema with the given name, false when no schema is bundled
+func getSchema(name string) (*schema, bool, error) {
+	b, err := schemaFS.ReadFile(path.Join("schemas", name+".json"))
+	if err != nil {
+		// no schema is bundled for the plugin or ipam type
+		return nil, false, nil
+	}
+	s := &schema{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, false, fmt.Errorf("invalid schema %s, %s", name, err)
+	}
+	return s, true, nil
+}
+
+// ValidateConfig validates the cni config of the nad against the schemas bundled for the plugins
+// and ipams, which reports a missing master or an invalid address or route destination before
+// multus fails at pod creation time. Plugins and ipams without a bundled schema are not validated.
+func (r *NadStruct) ValidateConfig() error {
+	configSpec := r.GetConfigSpec()
+	if configSpec == "" {
+		return nil
+	}
+	config := map[string]any{}
+	if err := json.Unmarshal([]byte(configSpec), &config); err != nil {
+		return fmt.Errorf("invalid NAD Config, %s", err)
+	}
+	if _, ok := config["plugins"]; !ok {
+		if _, ok := config["type"]; !ok {
+			// no plugin to validate, e.g. a nad with only a vlan
+			return nil
+		}
+		return validatePlugin("", config)
+	}
+	plugins, ok := config["plugins"].([]any)
+	if !ok {
+		return fmt.Errorf("plugins: expected type array")
+	}
+	var errs []error
+	for i, p := range plugins {
+		plugin, ok := p.(map[string]any)
+		if !ok {
+			errs = append(errs, fmt.Errorf("plugins[%d]: expected type object", i))
+			continue
+		}
+		if err := validatePlugin(fmt.Sprintf("plugins[%d]", i), plugin); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validatePlugin validates the plugin config and its ipam config at the given path
+func validatePlugin(fieldPath string, plugin map[string]any) error {
+	pluginType, ok := plugin["type"].(string)
+	if !ok || pluginType == "" {
+		return fmt.Errorf("%s is required", joinFieldPath(fieldPath, "type"))
+	}
+	var errs []error
+	if err := validateWithSchema("plugin-"+pluginType, fieldPath, plugin); err != nil {
+		errs = append(errs, err)
+	}
+	if ipam, ok := plugin["ipam"].(map[string]any); ok {
+		if ipamType, ok := ipam["type"].(string); ok && ipamType != "" {
+			if err := validateWithSchema("ipam-"+ipamType, joinFieldPath(fieldPath, "ipam"), ipam); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateWithSchema(name, fieldPath string, value any) error {
+	s, ok, err := getSchema(name)
+	if err != nil || !ok {
+		return err
+	}
+	return errors.Join(s.validate(fieldPath, value)...)
+}
+
+// validate returns the errors of the value at the given path against the schema
+func (s *schema) validate(fieldPath string, value any) []error {
+	if err := s.validateType(fieldPath, value); err != nil {
+		return []error{err}
+	}
+	var errs []error
+	switch v := value.(type) {
+	case map[string]any:
+		for _, f := range s.Required {
+			if _, ok := v[f]; !ok {
+				errs = append(errs, fmt.Errorf("%s is required", joinFieldPath(fieldPath, f)))
+			}
+		}
+		fields := make([]string, 0, len(s.Properties))
+		for f := range s.Properties {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
+		for _, f := range fields {
+			if fv, ok := v[f]; ok {
+				errs = append(errs, s.Properties[f].validate(joinFieldPath(fieldPath, f), fv)...)
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				errs = append(errs, s.Items.validate(fmt.Sprintf("%s[%d]", fieldPath, i), item)...)
+			}
+		}
+	case string:
+		if err := validateFormat(s.Format, v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s", fieldPath, err.Error()))
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum || s.Maximum != nil && v > *s.Maximum {
+			errs = append(errs, fmt.Errorf("%s: value %v out of range [%s, %s]", fieldPath, v, formatBound(s.Minimum), formatBound(s.Maximum)))
+		}
+	}
+	if len(s.Enum) != 0 && !enumContains(s.Enum, value) {
+		errs = append(errs, fmt.Errorf("%s: value %v not supported, supported values: %v", fieldPath, value, s.Enum))
+	}
+	return errs
+}
+
+func (s *schema) validateType(fieldPath string, value any) error {
+	ok := true
+	switch s.Type {
+	case "object":
+		_, ok = value.(map[string]any)
+	case "array":
+		_, ok = value.([]any)
+	case "string":
+		_, ok = value.(string)
+	case "boolean":
+		_, ok = value.(bool)
+	case "number":
+		_, ok = value.(float64)
+	case "integer":
+		v, isNumber := value.(float64)
+		ok = isNumber && v == math.Trunc(v)
+	}
+	if !ok {
+		return fmt.Errorf("%s: expected type %s, got %s", fieldPath, s.Type, jsonType(value))
+	}
+	return nil
+}
+
+// jsonType returns the json type of the decoded value
+func jsonType(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	}
+	return "null"
+}
+
+func validateFormat(format, value string) error {
+	switch format {
+	case "cidr":
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			return fmt.Errorf("invalid cidr %q", value)
+		}
+	case "ip":
+		if net.ParseIP(value) == nil {
+			return fmt.Errorf("invalid ip address %q", value)
+		}
+	case "mac":
+		if _, err := net.ParseMAC(value); err != nil {
+			return fmt.Errorf("invalid mac address %q", value)
+		}
+	}
+	return nil
+}
+
+func enumContains(enum []any, value any) bool {
+	for _, e := range enum {
+		if e == value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatBound(b *float64) string {
+	if b == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%v", *b)
+}
+
+func joinFieldPath(fieldPath, field string) string {
+	return strings.TrimPrefix(fieldPath+"."+field, ".")
+}
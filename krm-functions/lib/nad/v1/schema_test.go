@@ -0,0 +1,107 @@
+/*
+Copyright 2023 Nephio.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var nadTestTemplate = `apiVersion: "k8s.cni.cncf.io/v1"
+kind: NetworkAttachmentDefinition
+metadata:
+  name: upf-us-central1-n3
+spec:
+  config: '%s'
+`
+
+func TestValidateConfig(t *testing.T) {
+	cases := map[string]struct {
+		input string
+		want  []string
+	}{
+		"Sriov":      {input: nadTestSriov},
+		"IpVlan":     {input: nadTestIpVlan},
+		"MacVlan":    {input: nadTestMacVlan},
+		"Ovs":        {input: nadTestOvs},
+		"Vlan":       {input: nadTestVlan},
+		"Bridge":     {input: nadTestBridge},
+		"HostDevice": {input: nadTestHostDevice},
+		"Empty":      {input: nadTestEmpty},
+		"UnknownPlugin": {
+			input: fmt.Sprintf(nadTestTemplate, `{"cniVersion":"0.3.1","plugins":[{"type":"portmap","capabilities":{"portMappings":true}}]}`),
+		},
+		"MissingMaster": {
+			input: fmt.Sprintf(nadTestTemplate, `{"cniVersion":"0.3.1","plugins":[{"type":"macvlan","mode":"bridge","ipam":{"type":"static"}}]}`),
+			want:  []string{"plugins[0].master is required"},
+		},
+		"InvalidAddress": {
+			input: fmt.Sprintf(nadTestTemplate, `{"cniVersion":"0.3.1","plugins":[{"type":"ipvlan","master":"eth1","ipam":{"type":"static","addresses":[{"address":"16.0.0.2","gateway":"16.0.0"}]}}]}`),
+			want: []string{
+				`plugins[0].ipam.addresses[0].address: invalid cidr "16.0.0.2"`,
+				`plugins[0].ipam.addresses[0].gateway: invalid ip address "16.0.0"`,
+			},
+		},
+		"InvalidRoute": {
+			input: fmt.Sprintf(nadTestTemplate, `{"cniVersion":"0.3.1","plugins":[{"type":"ipvlan","master":"eth1","ipam":{"type":"static","routes":[{"dst":"default","gw":"16.0.0.1"},{"gw":"16.0.0.1"}]}}]}`),
+			want: []string{
+				`plugins[0].ipam.routes[0].dst: invalid cidr "default"`,
+				"plugins[0].ipam.routes[1].dst is required",
+			},
+		},
+		"InvalidMode": {
+			input: fmt.Sprintf(nadTestTemplate, `{"cniVersion":"0.3.1","plugins":[{"type":"ipvlan","master":"eth1","mode":"bridge"}]}`),
+			want:  []string{"plugins[0].mode: value bridge not supported, supported values: [l2 l3 l3s]"},
+		},
+		"InvalidVlan": {
+			input: fmt.Sprintf(nadTestTemplate, `{"cniVersion":"0.3.1","plugins":[{"type":"vlan","master":"eth1","vlanId":4095}]}`),
+			want:  []string{"plugins[0].vlanId: value 4095 out of range [0, 4094]"},
+		},
+		"InvalidType": {
+			input: fmt.Sprintf(nadTestTemplate, `{"cniVersion":"0.3.1","plugins":[{"type":"macvlan","master":"eth1","mtu":"9000"},{"type":"tuning","mac":"02:00:00"}]}`),
+			want: []string{
+				"plugins[0].mtu: expected type integer, got string",
+				`plugins[1].mac: invalid mac address "02:00:00"`,
+			},
+		},
+		"SinglePlugin": {
+			input: fmt.Sprintf(nadTestTemplate, `{"cniVersion":"0.3.1","type":"ipvlan","ipam":{"type":"static"}}`),
+			want:  []string{"master is required"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			nad, err := NewFromYAML([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("cannot parse nad: %s", err.Error())
+			}
+			err = nad.ValidateConfig()
+			if len(tc.want) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				for _, want := range tc.want {
+					assert.Contains(t, err.Error(), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package results
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Code classifies a result, such that tooling acts on the code instead of parsing the message
+type Code string
+
+const (
+	// CodeWaitingForStatus indicates a resource waits for the status of another resource, e.g. the prefix
+	// of an IPClaim; it clears once the controller of the other resource populates its status
+	CodeWaitingForStatus Code = "WaitingForStatus"
+	// CodeInvalidInput indicates a resource of the package holds an invalid value
+	CodeInvalidInput Code = "InvalidInput"
+	// CodeMissingResource indicates a resource required by the function is missing from the package
+	CodeMissingResource Code = "MissingResource"
+	// CodeDuplicateResource indicates the package holds multiple resources where one is expected
+	CodeDuplicateResource Code = "DuplicateResource"
+	// CodeUnsupportedByCluster indicates the workload cluster does not support the request, e.g. a cniType
+	CodeUnsupportedByCluster Code = "UnsupportedByCluster"
+	// CodeInternal indicates a failure of the function itself, the errors without code are internal
+	CodeInternal Code = "Internal"
+)
+
+const (
+	// CodeTag is the tag of a result holding its code
+	CodeTag = "nephio.org/code"
+	// RemediationTag is the tag of a result holding the hint to resolve it
+	RemediationTag = "nephio.org/remediation"
+)
+
+// remediations are the default hints per code
+var remediations = map[Code]string{
+	CodeWaitingForStatus:     "no action required, the status is populated by the controller of the resource",
+	CodeInvalidInput:         "correct the resource in the package",
+	CodeMissingResource:      "add the resource to the package",
+	CodeDuplicateResource:    "remove the duplicate resources from the package",
+	CodeUnsupportedByCluster: "change the request or deploy the package to a workload cluster supporting it",
+	CodeInternal:             "report the failure of the function",
+}
+
+// Error is an error carrying the code, the resource and the remediation of its result, such that
+// the callbacks of a function return errors that are reported as structured results
+type Error struct {
+	Code    Code
+	Message string
+	// Resource references the resource the error refers to; optional
+	Resource *corev1.ObjectReference
+	// Field is the path of the field the error refers to, e.g. metadata.annotations.nephio.org/vlan-range; optional
+	Field string
+	// Remediation is the hint to resolve the error; the default hint of the code when not set
+	Remediation string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Errorf returns an error with the code and the formatted message
+func Errorf(code Code, format string, a ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, a...)}
+}
+
+// WithResource sets the resource the error refers to
+func (e *Error) WithResource(o *fn.KubeObject) *Error {
+	if o != nil {
+		e.Resource = &corev1.ObjectReference{APIVersion: o.GetAPIVersion(), Kind: o.GetKind(), Namespace: o.GetNamespace(), Name: o.GetName()}
+	}
+	return e
+}
+
+// WithRef sets the reference of the resource the error refers to
+func (e *Error) WithRef(ref corev1.ObjectReference) *Error {
+	e.Resource = &ref
+	return e
+}
+
+// WithField sets the path of the field the error refers to
+func (e *Error) WithField(path string) *Error {
+	e.Field = path
+	return e
+}
+
+// WithRemediation sets the hint to resolve the error
+func (e *Error) WithRemediation(format string, a ...any) *Error {
+	e.Remediation = fmt.Sprintf(format, a...)
+	return e
+}
+
+// AsError returns the Error of err, wrapped or not
+func AsError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// NewResult returns the result of err with the severity, an error without code is an internal error
+func NewResult(err error, severity fn.Severity) *fn.Result {
+	e, ok := AsError(err)
+	if !ok {
+		e = &Error{Code: CodeInternal}
+	}
+	remediation := e.Remediation
+	if remediation == "" {
+		remediation = remediations[e.Code]
+	}
+	r := &fn.Result{
+		Message:  err.Error(),
+		Severity: severity,
+		Tags: map[string]string{
+			CodeTag:        string(e.Code),
+			RemediationTag: remediation,
+		},
+	}
+	if e.Resource != nil {
+		r.ResourceRef = &fn.ResourceRef{
+			APIVersion: e.Resource.APIVersion,
+			Kind:       e.Resource.Kind,
+			Namespace:  e.Resource.Namespace,
+			Name:       e.Resource.Name,
+		}
+	}
+	if e.Field != "" {
+		r.Field = &fn.Field{Path: e.Field}
+	}
+	return r
+}
+
+// ErrorE adds the error result of err to the results of the ResourceList
+func ErrorE(rl *fn.ResourceList, err error) {
+	rl.Results = append(rl.Results, NewResult(err, fn.Error))
+}
+
+// WarningE adds the warning result of err to the results of the ResourceList
+func WarningE(rl *fn.ResourceList, err error) {
+	rl.Results = append(rl.Results, NewResult(err, fn.Warning))
+}
+
+// Infof adds an info result with the code and the formatted message for the resource to the results
+// of the ResourceList
+func Infof(rl *fn.ResourceList, code Code, ref corev1.ObjectReference, format string, a ...any) {
+	rl.Results = append(rl.Results, NewResult(Errorf(code, format, a...).WithRef(ref), fn.Info))
+}
+
+// Result is the structured form of a result for the tooling processing the results of the functions
+type Result struct {
+	Code        Code
+	Severity    fn.Severity
+	Message     string
+	Resource    *corev1.ObjectReference
+	Field       string
+	Remediation string
+}
+
+// GetResults returns the structured form of the results holding a code, the other results are left out
+func GetResults(rs fn.Results) []Result {
+	structured := []Result{}
+	for _, r := range rs {
+		if r == nil || r.Tags[CodeTag] == "" {
+			continue
+		}
+		sr := Result{
+			Code:        Code(r.Tags[CodeTag]),
+			Severity:    r.Severity,
+			Message:     r.Message,
+			Remediation: r.Tags[RemediationTag],
+		}
+		if r.ResourceRef != nil {
+			sr.Resource = &corev1.ObjectReference{
+				APIVersion: r.ResourceRef.APIVersion,
+				Kind:       r.ResourceRef.Kind,
+				Namespace:  r.ResourceRef.Namespace,
+				Name:       r.ResourceRef.Name,
+			}
+		}
+		if r.Field != nil {
+			sr.Field = r.Field.Path
+		}
+		structured = append(structured, sr)
+	}
+	return structured
+}
@@ -0,0 +1,119 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package results
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewResult(t *testing.T) {
+	ref := corev1.ObjectReference{APIVersion: "req.nephio.org/v1alpha1", Kind: "Interface", Name: "n3"}
+	cases := map[string]struct {
+		err  error
+		want *fn.Result
+	}{
+		"Coded": {
+			err: Errorf(CodeInvalidInput, "invalid vlan range 10").WithRef(ref).WithField("metadata.annotations.nephio.org/vlan-range"),
+			want: &fn.Result{
+				Message:     "invalid vlan range 10",
+				Severity:    fn.Error,
+				ResourceRef: &fn.ResourceRef{APIVersion: "req.nephio.org/v1alpha1", Kind: "Interface", Name: "n3"},
+				Field:       &fn.Field{Path: "metadata.annotations.nephio.org/vlan-range"},
+				Tags: map[string]string{
+					CodeTag:        string(CodeInvalidInput),
+					RemediationTag: remediations[CodeInvalidInput],
+				},
+			},
+		},
+		"Remediation": {
+			err: Errorf(CodeUnsupportedByCluster, "mtu 9000 exceeds the max mtu 1500").WithRemediation("lower the mtu to at most %d", 1500),
+			want: &fn.Result{
+				Message:  "mtu 9000 exceeds the max mtu 1500",
+				Severity: fn.Error,
+				Tags: map[string]string{
+					CodeTag:        string(CodeUnsupportedByCluster),
+					RemediationTag: "lower the mtu to at most 1500",
+				},
+			},
+		},
+		"Wrapped": {
+			err: fmt.Errorf("stage1: %w", Errorf(CodeMissingResource, "workload cluster is missing")),
+			want: &fn.Result{
+				Message:  "stage1: workload cluster is missing",
+				Severity: fn.Error,
+				Tags: map[string]string{
+					CodeTag:        string(CodeMissingResource),
+					RemediationTag: remediations[CodeMissingResource],
+				},
+			},
+		},
+		"Uncoded": {
+			err: fmt.Errorf("cannot parse"),
+			want: &fn.Result{
+				Message:  "cannot parse",
+				Severity: fn.Error,
+				Tags: map[string]string{
+					CodeTag:        string(CodeInternal),
+					RemediationTag: remediations[CodeInternal],
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := NewResult(tc.err, fn.Error)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetResults(t *testing.T) {
+	ref := corev1.ObjectReference{APIVersion: "req.nephio.org/v1alpha1", Kind: "Interface", Name: "n3"}
+	rl := &fn.ResourceList{}
+	rl.Results.Infof("uncoded")
+	Infof(rl, CodeWaitingForStatus, ref, "waiting for status.prefix of IPClaim n3-ipv4")
+	WarningE(rl, Errorf(CodeInvalidInput, "invalid vlan range 10").WithRef(ref).WithField("spec.vlan"))
+
+	want := []Result{
+		{
+			Code:        CodeWaitingForStatus,
+			Severity:    fn.Info,
+			Message:     "waiting for status.prefix of IPClaim n3-ipv4",
+			Resource:    &ref,
+			Remediation: remediations[CodeWaitingForStatus],
+		},
+		{
+			Code:        CodeInvalidInput,
+			Severity:    fn.Warning,
+			Message:     "invalid vlan range 10",
+			Resource:    &ref,
+			Field:       "spec.vlan",
+			Remediation: remediations[CodeInvalidInput],
+		},
+	}
+	if diff := cmp.Diff(want, GetResults(rl.Results)); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testhelpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/go-cmp/cmp"
+	tst "github.com/nephio-project/nephio/krm-functions/lib/test"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// ExpectedDir is the directory of a test case holding the expected package
+	ExpectedDir = "_expected"
+	// ActualDir is the directory of a test case the actual package is written to, such that it can be
+	// compared with the expected package by an external diff tool
+	ActualDir = "_actual"
+)
+
+// Step is a function of a pipeline, run in-process with its Run entrypoint
+type Step struct {
+	// Name identifies the step in the errors and selects the _fnconfig_<name>.yaml function config
+	Name string
+	Fn   fn.ResourceListProcessorFunc
+}
+
+// RunPipelineGoldenTests runs the pipeline of steps on the package of every sub-directory of basedir
+// and compares the resulting package with the expected package of the sub-directory.
+//
+// For example, if "testdata" is the basedir, it contains two cases "test1" and "test2":
+//
+//	└── testdata
+//	    └── test1
+//	        ├── _expected
+//	        │   ├── Kptfile
+//	        │   ├── interface.yaml
+//	        │   └── ipclaim_n3.yaml
+//	        ├── _fnconfig.yaml
+//	        ├── Kptfile
+//	        └── interface.yaml
+//	    └── test2
+//	        ├── _expected_error.txt
+//	        ├── _fnconfig_nad-fn.yaml
+//	        ├── Kptfile
+//	        └── subpkg
+//	            ├── Kptfile
+//	            └── deployment.yaml
+//
+// The files in a test case's subdirectory are interpreted as follows:
+//   - the YAML files and Kptfiles whose name doesn't start with an underscore, including the ones of the sub
+//     directories, are the input package.
+//   - _fnconfig_<step name>.yaml, if present, holds the function config of the step, else _fnconfig.yaml, if
+//     present, holds the function config of every step.
+//   - _expected holds the expected package. The resources of the package are compared per file, in normalized
+//     order, such that the order in which the functions add resources or the order of the input files does
+//     not fail the test. A resource added by a function without a path is expected in <kind>_<name>.yaml.
+//   - _expected_results.yaml and _expected_error.txt are interpreted as by test.RunGoldenTests.
+//
+// After running a test case the actual package is written to _actual. If the `WRITE_GOLDEN_OUTPUT`
+// environment variable is set with a non-empty value, then _expected is overwritten with the actual package.
+func RunPipelineGoldenTests(t *testing.T, basedir string, steps []Step) {
+	entries, err := os.ReadDir(basedir)
+	if err != nil {
+		t.Fatalf("ReadDir(%q) failed: %v", basedir, err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), "_") {
+			continue
+		}
+		dir := filepath.Join(basedir, e.Name())
+		t.Run(e.Name(), func(t *testing.T) {
+			RunPipelineGoldenTest(t, dir, steps)
+		})
+	}
+}
+
+// RunPipelineGoldenTest runs the pipeline of steps on the package in dir, the files in dir are interpreted
+// as by RunPipelineGoldenTests
+func RunPipelineGoldenTest(t *testing.T, dir string, steps []Step) {
+	rl, err := LoadPackage(dir)
+	if err != nil {
+		t.Fatalf("failed to load package %q: %v", dir, err)
+	}
+	fnConfigs, err := loadFunctionConfigs(dir, steps)
+	if err != nil {
+		t.Fatalf("failed to load function configs of %q: %v", dir, err)
+	}
+
+	tst.CheckRunError(t, dir, RunPipeline(rl, steps, fnConfigs))
+	tst.CheckResults(t, dir, rl)
+	CheckExpectedPackage(t, dir, rl)
+}
+
+// LoadPackage reads the resources of the package in dir, including its sub-packages, as porch would
+// pass them to the functions of a render; the files whose name starts with an underscore are left out
+func LoadPackage(dir string) (*fn.ResourceList, error) {
+	nodes, err := (&kio.LocalPackageReader{
+		PackagePath:        dir,
+		MatchFilesGlob:     []string{"*.yaml", "*.yml", "Kptfile"},
+		IncludeSubpackages: true,
+		FileSkipFunc: func(relPath string) bool {
+			for _, p := range strings.Split(filepath.ToSlash(relPath), "/") {
+				if strings.HasPrefix(p, "_") {
+					return true
+				}
+			}
+			return false
+		},
+	}).Read()
+	if err != nil {
+		return nil, err
+	}
+	rl := &fn.ResourceList{Items: fn.KubeObjects{}}
+	for _, n := range nodes {
+		o, err := fn.ParseKubeObject([]byte(n.MustString()))
+		if err != nil {
+			return nil, err
+		}
+		rl.Items = append(rl.Items, o)
+	}
+	return rl, nil
+}
+
+// RunPipeline runs the steps in order on the ResourceList with their function config, a step without
+// function config gets an empty function config. Like a render the pipeline stops at the first step
+// returning an error or an error result.
+func RunPipeline(rl *fn.ResourceList, steps []Step, fnConfigs map[string]*fn.KubeObject) error {
+	for i, s := range steps {
+		rl.FunctionConfig = fnConfigs[s.Name]
+		if rl.FunctionConfig == nil {
+			rl.FunctionConfig = fn.NewEmptyKubeObject()
+		}
+		if _, err := s.Fn(rl); err != nil {
+			return fmt.Errorf("in step %d (%s) of the pipeline: %v", i+1, s.Name, err)
+		}
+		if rl.Results.ExitCode() != 0 {
+			return fmt.Errorf("in step %d (%s) of the pipeline: %v", i+1, s.Name, rl.Results)
+		}
+	}
+	return nil
+}
+
+// GetPackageFiles returns the files of the package of the ResourceList in normalized form: the resources
+// of a file are sorted by apiVersion, kind, namespace and name, and the annotations recording the path
+// and the index of the resources are removed, as in the package written by a render
+func GetPackageFiles(rl *fn.ResourceList) (map[string]string, error) {
+	resources := map[string][]*fn.KubeObject{}
+	for _, o := range rl.Items {
+		p := o.GetAnnotation(kioutil.PathAnnotation)
+		if p == "" {
+			p = o.GetAnnotation(kioutil.LegacyPathAnnotation)
+		}
+		if p == "" {
+			p = fmt.Sprintf("%s_%s.yaml", strings.ToLower(o.GetKind()), o.GetName())
+		}
+		resources[p] = append(resources[p], o)
+	}
+
+	files := map[string]string{}
+	for p, objs := range resources {
+		sort.SliceStable(objs, func(i, j int) bool {
+			return getSortKey(objs[i]) < getSortKey(objs[j])
+		})
+		docs := make([]string, 0, len(objs))
+		for _, o := range objs {
+			n, err := yaml.Parse(o.String())
+			if err != nil {
+				return nil, err
+			}
+			if err := normalizeAnnotations(n); err != nil {
+				return nil, err
+			}
+			s, err := n.String()
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, s)
+		}
+		files[filepath.FromSlash(p)] = strings.Join(docs, "---\n")
+	}
+	return files, nil
+}
+
+// CheckExpectedPackage compares the package of the ResourceList with the _expected package of dir
+func CheckExpectedPackage(t *testing.T, dir string, rl *fn.ResourceList) {
+	actual, err := GetPackageFiles(rl)
+	if err != nil {
+		t.Fatalf("failed to get the files of the package: %v", err)
+	}
+	if err := writePackageFiles(filepath.Join(dir, ActualDir), actual); err != nil {
+		t.Fatalf("failed to write the actual package: %v", err)
+	}
+	expectedDir := filepath.Join(dir, ExpectedDir)
+	if os.Getenv("WRITE_GOLDEN_OUTPUT") != "" {
+		if err := writePackageFiles(expectedDir, actual); err != nil {
+			t.Fatalf("failed to write the expected package: %v", err)
+		}
+		return
+	}
+	if _, err := os.Stat(expectedDir); os.IsNotExist(err) {
+		// skip the comparison if _expected is missing
+		return
+	}
+	expected, err := readPackageFiles(expectedDir)
+	if err != nil {
+		t.Fatalf("failed to read the expected package: %v", err)
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("package of %s differs from the expected package (-want, +got):\n%s", dir, diff)
+	}
+}
+
+// loadFunctionConfigs returns the function configs of the steps of the test case in dir
+func loadFunctionConfigs(dir string, steps []Step) (map[string]*fn.KubeObject, error) {
+	fnConfigs := map[string]*fn.KubeObject{}
+	for _, s := range steps {
+		for _, name := range []string{fmt.Sprintf("_fnconfig_%s.yaml", s.Name), "_fnconfig.yaml"} {
+			b, err := os.ReadFile(filepath.Clean(filepath.Join(dir, name)))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			o, err := fn.ParseKubeObject(b)
+			if err != nil {
+				return nil, fmt.Errorf("invalid function config %s: %s", name, err.Error())
+			}
+			fnConfigs[s.Name] = o
+			break
+		}
+	}
+	return fnConfigs, nil
+}
+
+func getSortKey(o *fn.KubeObject) string {
+	return strings.Join([]string{o.GetAPIVersion(), o.GetKind(), o.GetNamespace(), o.GetName()}, "/")
+}
+
+// readerAnnotations are the annotations set by the package reader, the path is recorded by the file
+var readerAnnotations = []string{
+	kioutil.PathAnnotation,
+	kioutil.LegacyPathAnnotation,
+	kioutil.IndexAnnotation,
+	kioutil.LegacyIndexAnnotation,
+	kioutil.IdAnnotation,
+	kioutil.LegacyIdAnnotation,
+	kioutil.InternalAnnotationsMigrationResourceIDAnnotation,
+}
+
+// normalizeAnnotations removes the reader annotations of a resource, and the annotations left empty
+func normalizeAnnotations(n *yaml.RNode) error {
+	for _, a := range readerAnnotations {
+		if _, err := n.Pipe(yaml.ClearAnnotation(a)); err != nil {
+			return err
+		}
+	}
+	if len(n.GetAnnotations()) == 0 {
+		if _, err := n.Pipe(yaml.Lookup(yaml.MetadataField), yaml.Clear(yaml.AnnotationsField)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePackageFiles(dir string, files map[string]string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	for p, s := range files {
+		path := filepath.Join(dir, p)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(s), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readPackageFiles(dir string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		p, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[p] = string(b)
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testhelpers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testdir = "testdata"
+
+// addLabel labels every resource of the package
+func addLabel(rl *fn.ResourceList) (bool, error) {
+	for _, o := range rl.Items {
+		if err := o.SetLabel("nephio.org/test", "true"); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// addConfigMap prepends a ConfigMap named by the function config to the package
+func addConfigMap(rl *fn.ResourceList) (bool, error) {
+	name, _, _ := rl.FunctionConfig.NestedString("data", "name")
+	if name == "" {
+		return false, fmt.Errorf("name is missing from the function config")
+	}
+	o := fn.NewEmptyKubeObject()
+	if err := o.SetAPIVersion("v1"); err != nil {
+		return false, err
+	}
+	if err := o.SetKind("ConfigMap"); err != nil {
+		return false, err
+	}
+	if err := o.SetName(name); err != nil {
+		return false, err
+	}
+	rl.Items = append(fn.KubeObjects{o}, rl.Items...)
+	return true, nil
+}
+
+func TestRunPipelineGoldenTests(t *testing.T) {
+	RunPipelineGoldenTests(t, testdir, []Step{
+		{Name: "add-label", Fn: addLabel},
+		{Name: "add-configmap", Fn: addConfigMap},
+	})
+}
+
+func TestGetPackageFiles(t *testing.T) {
+	cases := map[string]struct {
+		input []string
+		want  map[string]string
+	}{
+		"Ordering": {
+			input: []string{`
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+  annotations:
+    internal.config.kubernetes.io/path: resources.yaml
+    internal.config.kubernetes.io/index: '0'`, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: a
+  annotations:
+    internal.config.kubernetes.io/path: resources.yaml
+    internal.config.kubernetes.io/index: '1'`,
+			},
+			want: map[string]string{
+				"resources.yaml": "apiVersion: v1\nkind: Service\nmetadata:\n  name: a\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: b\n",
+			},
+		},
+		"NoPath": {
+			input: []string{`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  annotations:
+    nephio.org/test: "true"`,
+			},
+			want: map[string]string{
+				"configmap_a.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n  annotations:\n    nephio.org/test: \"true\"\n",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rl := &fn.ResourceList{}
+			for _, s := range tc.input {
+				o, err := fn.ParseKubeObject([]byte(s))
+				if err != nil {
+					t.Fatalf("cannot parse object: %v", err)
+				}
+				rl.Items = append(rl.Items, o)
+			}
+			got, err := GetPackageFiles(rl)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// EndpointEnv defines the OTLP/HTTP endpoint the spans are exported to, e.g. http://otel-collector:4318,
+	// the spans are posted to its /v1/traces path
+	EndpointEnv = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	// TracesEndpointEnv defines the OTLP/HTTP traces endpoint, it takes precedence over EndpointEnv
+	TracesEndpointEnv = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	// ServiceNameEnv overrides the service name of the spans
+	ServiceNameEnv = "OTEL_SERVICE_NAME"
+
+	tracesPath = "/v1/traces"
+	// maxQueuedSpans bounds the spans waiting for export, spans are dropped once reached
+	maxQueuedSpans = 2048
+	scopeName      = "github.com/nephio-project/nephio/krm-functions/lib/tracing"
+
+	spanKindInternal = 1
+	statusCodeOk     = 1
+	statusCodeError  = 2
+)
+
+// NewTracerFromEnv returns a tracer of the service exporting to the OTLP endpoint of the
+// environment, the tracer only propagates the trace context when no endpoint is set
+func NewTracerFromEnv(service string) *Tracer {
+	if s := os.Getenv(ServiceNameEnv); s != "" {
+		service = s
+	}
+	endpoint := os.Getenv(TracesEndpointEnv)
+	if endpoint == "" {
+		if e := os.Getenv(EndpointEnv); e != "" {
+			endpoint = strings.TrimSuffix(e, "/") + tracesPath
+		}
+	}
+	return NewTracer(service, endpoint)
+}
+
+func (r *Tracer) queue(s *Span) {
+	if r.endpoint == "" {
+		return
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	if len(r.spans) >= maxQueuedSpans {
+		r.dropped++
+		return
+	}
+	r.spans = append(r.spans, s)
+}
+
+// Flush exports the ended spans
+func (r *Tracer) Flush(ctx context.Context) error {
+	r.m.Lock()
+	spans := r.spans
+	dropped := r.dropped
+	r.spans = nil
+	r.dropped = 0
+	r.m.Unlock()
+	if dropped > 0 {
+		return fmt.Errorf("%d spans dropped, the export does not keep up", dropped)
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(r.encode(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot export %d spans: %s", len(spans), err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("cannot export %d spans: %s %s", len(spans), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// Run exports the ended spans every interval until the context is cancelled, the remaining
+// spans are exported once cancelled. The errors of the exports are reported to errFn
+func (r *Tracer) Run(ctx context.Context, interval time.Duration, errFn func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fctx, cancel := context.WithTimeout(context.Background(), interval)
+			defer cancel()
+			if err := r.Flush(fctx); err != nil {
+				errFn(err)
+			}
+			return
+		case <-ticker.C:
+			if err := r.Flush(ctx); err != nil {
+				errFn(err)
+			}
+		}
+	}
+}
+
+// the OTLP/HTTP json encoding of the spans, the ids are encoded as hexadecimal strings
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope      `json:"scope"`
+	Spans []spanJSON `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type spanJSON struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            status     `json:"status"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func (r *Tracer) encode(spans []*Span) exportRequest {
+	ss := make([]spanJSON, 0, len(spans))
+	for _, s := range spans {
+		s.m.Lock()
+		sj := spanJSON{
+			TraceID:           hex.EncodeToString(s.sc.TraceID[:]),
+			SpanID:            hex.EncodeToString(s.sc.SpanID[:]),
+			Name:              s.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        attributes(s.attrs),
+			Status:            status{Code: statusCodeOk},
+		}
+		if s.parent != (SpanID{}) {
+			sj.ParentSpanID = hex.EncodeToString(s.parent[:])
+		}
+		if s.failed {
+			sj.Status = status{Code: statusCodeError, Message: s.errorMsg}
+		}
+		s.m.Unlock()
+		ss = append(ss, sj)
+	}
+	return exportRequest{ResourceSpans: []resourceSpans{{
+		Resource:   resource{Attributes: attributes(map[string]string{"service.name": r.service})},
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: scopeName}, Spans: ss}},
+	}}}
+}
+
+func attributes(attrs map[string]string) []keyValue {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]keyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, keyValue{Key: k, Value: anyValue{StringValue: attrs[k]}})
+	}
+	return kvs
+}
@@ -0,0 +1,115 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFlush(t *testing.T) {
+	var got exportRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != tracesPath || req.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv(EndpointEnv, srv.URL)
+	t.Setenv(ServiceNameEnv, "")
+
+	tr := NewTracerFromEnv("test")
+	ctx, parent := tr.Start(context.Background(), "reconcile")
+	_, child := tr.Start(ctx, "fn")
+	child.SetAttribute("for", "Interface")
+	child.RecordError(errors.New("workload cluster is missing"))
+	child.End()
+	child.End()
+	parent.End()
+
+	if err := tr.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if len(got.ResourceSpans) != 1 || len(got.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("want a single scope of spans, got %v", got)
+	}
+	if got := got.ResourceSpans[0].Resource.Attributes; len(got) != 1 || got[0].Value.StringValue != "test" {
+		t.Errorf("want service name test, got %v", got)
+	}
+	spans := got.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("want 2 spans, got %d", len(spans))
+	}
+	fnSpan, reconcileSpan := spans[0], spans[1]
+	if fnSpan.TraceID != reconcileSpan.TraceID || fnSpan.ParentSpanID != reconcileSpan.SpanID || reconcileSpan.ParentSpanID != "" {
+		t.Errorf("want fn span as child of the reconcile span, got %v and %v", fnSpan, reconcileSpan)
+	}
+	if fnSpan.Status.Code != statusCodeError || fnSpan.Status.Message != "workload cluster is missing" {
+		t.Errorf("want error status, got %v", fnSpan.Status)
+	}
+	if len(fnSpan.Attributes) != 1 || fnSpan.Attributes[0].Key != "for" {
+		t.Errorf("want attribute for, got %v", fnSpan.Attributes)
+	}
+	if reconcileSpan.Status.Code != statusCodeOk {
+		t.Errorf("want ok status, got %v", reconcileSpan.Status)
+	}
+
+	// the exported spans are not exported again
+	got = exportRequest{}
+	if err := tr.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if len(got.ResourceSpans) != 0 {
+		t.Errorf("want no export, got %v", got)
+	}
+}
+
+func TestFlushError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	tr := NewTracer("test", srv.URL+tracesPath)
+	_, s := tr.Start(context.Background(), "reconcile")
+	s.End()
+	if err := tr.Flush(context.Background()); err == nil {
+		t.Errorf("want error, got nil")
+	}
+}
+
+func TestNoEndpoint(t *testing.T) {
+	t.Setenv(EndpointEnv, "")
+	t.Setenv(TracesEndpointEnv, "")
+	tr := NewTracerFromEnv("test")
+	_, s := tr.Start(context.Background(), "reconcile")
+	s.End()
+	if len(tr.spans) != 0 {
+		t.Errorf("want no queued spans without endpoint, got %d", len(tr.spans))
+	}
+	if err := tr.Flush(context.Background()); err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+}
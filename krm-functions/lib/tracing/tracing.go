@@ -0,0 +1,220 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package tracing propagates the trace context of the specialization of a package from the controllers
+// through porch to the functions of its pipeline, as W3C trace context, and exports the spans as OTLP
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+)
+
+const (
+	// TraceParentAnnotation defines the W3C traceparent of the specialization of a package on its
+	// Kptfile, the functions running on the package trace their run as part of this trace
+	TraceParentAnnotation = "nephio.org/traceparent"
+
+	traceParentVersion = "00"
+	traceFlagsSampled  = "01"
+)
+
+type TraceID [16]byte
+
+type SpanID [8]byte
+
+// SpanContext identifies a span in a trace
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+}
+
+// IsValid returns true when the trace and span ids are set
+func (r SpanContext) IsValid() bool {
+	return r.TraceID != TraceID{} && r.SpanID != SpanID{}
+}
+
+// TraceParent returns the W3C traceparent of the span context
+func (r SpanContext) TraceParent() string {
+	return fmt.Sprintf("%s-%s-%s-%s", traceParentVersion, hex.EncodeToString(r.TraceID[:]), hex.EncodeToString(r.SpanID[:]), traceFlagsSampled)
+}
+
+// ParseTraceParent returns the span context of the W3C traceparent
+func ParseTraceParent(s string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || len(parts[3]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q, expected version-traceid-spanid-flags", s)
+	}
+	sc := SpanContext{}
+	if err := decodeHex(parts[1], sc.TraceID[:]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid trace id of traceparent %q: %s", s, err.Error())
+	}
+	if err := decodeHex(parts[2], sc.SpanID[:]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid span id of traceparent %q: %s", s, err.Error())
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q, the trace and span ids cannot be zero", s)
+	}
+	return sc, nil
+}
+
+func decodeHex(s string, b []byte) error {
+	if len(s) != 2*len(b) {
+		return fmt.Errorf("expected %d hexadecimal digits", 2*len(b))
+	}
+	_, err := hex.Decode(b, []byte(s))
+	return err
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns the context with the span context as parent of the spans started from it
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context of the context, an invalid span context when the
+// context is not traced
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+// Propagate joins the trace of the specialization of the package of the Kptfile: the context
+// continues the trace of the traceparent annotation of the Kptfile, and a Kptfile without a valid
+// annotation is annotated with the trace context of the context, such that the functions of its
+// pipeline join the trace
+func Propagate(ctx context.Context, kptfile *fn.KubeObject) (context.Context, error) {
+	if kptfile == nil {
+		return ctx, nil
+	}
+	if sc, err := ParseTraceParent(kptfile.GetAnnotation(TraceParentAnnotation)); err == nil {
+		return ContextWithSpanContext(ctx, sc), nil
+	}
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ctx, nil
+	}
+	return ctx, kptfile.SetAnnotation(TraceParentAnnotation, sc.TraceParent())
+}
+
+// Span is an operation of a trace, it is exported by its tracer once ended
+type Span struct {
+	tracer   *Tracer
+	name     string
+	sc       SpanContext
+	parent   SpanID
+	start    time.Time
+	end      time.Time
+	attrs    map[string]string
+	errorMsg string
+	failed   bool
+
+	m     sync.Mutex
+	ended bool
+}
+
+// SpanContext returns the span context of the span, the parent of its child spans
+func (r *Span) SpanContext() SpanContext {
+	return r.sc
+}
+
+// SetAttribute sets an attribute of the span
+func (r *Span) SetAttribute(key, value string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.attrs[key] = value
+}
+
+// RecordError marks the span as failed with the error, a nil error is ignored
+func (r *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.failed = true
+	r.errorMsg = err.Error()
+}
+
+// End ends the span and queues it for export, a span is ended once
+func (r *Span) End() {
+	r.m.Lock()
+	if r.ended {
+		r.m.Unlock()
+		return
+	}
+	r.ended = true
+	r.end = time.Now()
+	r.m.Unlock()
+	r.tracer.queue(r)
+}
+
+// Tracer starts the spans and exports them to the OTLP endpoint, a tracer without endpoint
+// only propagates the trace context
+type Tracer struct {
+	service  string
+	endpoint string
+
+	m       sync.Mutex
+	spans   []*Span
+	dropped int
+}
+
+// NewTracer returns a tracer of the service exporting the spans to the OTLP/HTTP traces endpoint,
+// e.g. http://otel-collector:4318/v1/traces
+func NewTracer(service, endpoint string) *Tracer {
+	return &Tracer{service: service, endpoint: endpoint}
+}
+
+// Start starts a span, the child of the span context of the context or the root of a new trace
+func (r *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	s := &Span{
+		tracer: r,
+		name:   name,
+		start:  time.Now(),
+		attrs:  map[string]string{},
+	}
+	parent := SpanContextFromContext(ctx)
+	if parent.IsValid() {
+		s.sc.TraceID = parent.TraceID
+		s.parent = parent.SpanID
+	} else {
+		_, _ = rand.Read(s.sc.TraceID[:])
+	}
+	_, _ = rand.Read(s.sc.SpanID[:])
+	return ContextWithSpanContext(ctx, s.sc), s
+}
+
+// defaultTracer only propagates the trace context until a tracer is set
+var defaultTracer = NewTracer("", "")
+
+// SetTracer sets the tracer of the spans started with Start
+func SetTracer(t *Tracer) {
+	defaultTracer = t
+}
+
+// Start starts a span with the tracer set by SetTracer
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return defaultTracer.Start(ctx, name)
+}
@@ -0,0 +1,170 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+)
+
+const traceParent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+func TestParseTraceParent(t *testing.T) {
+	cases := map[string]struct {
+		input   string
+		wantErr bool
+	}{
+		"Valid": {
+			input: traceParent,
+		},
+		"UnsampledFlags": {
+			input: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00",
+		},
+		"Empty": {
+			input:   "",
+			wantErr: true,
+		},
+		"ShortTraceID": {
+			input:   "00-0af7651916cd43dd-b7ad6b7169203331-01",
+			wantErr: true,
+		},
+		"NotHex": {
+			input:   "00-0af7651916cd43dd8448eb211c80319z-b7ad6b7169203331-01",
+			wantErr: true,
+		},
+		"ZeroSpanID": {
+			input:   "00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01",
+			wantErr: true,
+		},
+		"InvalidVersion": {
+			input:   "ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			sc, err := ParseTraceParent(tc.input)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("want error, got span context %v", sc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if got, want := sc.TraceParent()[:52], tc.input[:52]; got != want {
+				t.Errorf("want traceparent %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestStart(t *testing.T) {
+	parent, err := ParseTraceParent(traceParent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := NewTracer("test", "")
+
+	_, root := tr.Start(context.Background(), "root")
+	if !root.SpanContext().IsValid() {
+		t.Errorf("want a new trace, got %v", root.SpanContext())
+	}
+	if root.SpanContext().TraceID == parent.TraceID {
+		t.Errorf("want a new trace id, got the trace id of an unrelated trace")
+	}
+
+	ctx, child := tr.Start(ContextWithSpanContext(context.Background(), parent), "child")
+	if child.SpanContext().TraceID != parent.TraceID {
+		t.Errorf("want trace id %x, got %x", parent.TraceID, child.SpanContext().TraceID)
+	}
+	if child.parent != parent.SpanID {
+		t.Errorf("want parent span id %x, got %x", parent.SpanID, child.parent)
+	}
+	if got := SpanContextFromContext(ctx); got != child.SpanContext() {
+		t.Errorf("want the span context of the child in the context, got %v", got)
+	}
+}
+
+func TestPropagate(t *testing.T) {
+	parent, err := ParseTraceParent(traceParent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]struct {
+		annotation     string
+		ctx            context.Context
+		wantAnnotation string
+		wantCtx        SpanContext
+	}{
+		"AnnotateKptfile": {
+			ctx:            ContextWithSpanContext(context.Background(), parent),
+			wantAnnotation: traceParent,
+			wantCtx:        parent,
+		},
+		"JoinPackageTrace": {
+			annotation:     other.TraceParent(),
+			ctx:            ContextWithSpanContext(context.Background(), parent),
+			wantAnnotation: other.TraceParent(),
+			wantCtx:        other,
+		},
+		"ReplaceInvalidAnnotation": {
+			annotation:     "invalid",
+			ctx:            ContextWithSpanContext(context.Background(), parent),
+			wantAnnotation: traceParent,
+			wantCtx:        parent,
+		},
+		"NotTraced": {
+			ctx: context.Background(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kptfile := fn.NewEmptyKubeObject()
+			if err := kptfile.SetAPIVersion("kpt.dev/v1"); err != nil {
+				t.Fatal(err)
+			}
+			if err := kptfile.SetKind("Kptfile"); err != nil {
+				t.Fatal(err)
+			}
+			if tc.annotation != "" {
+				if err := kptfile.SetAnnotation(TraceParentAnnotation, tc.annotation); err != nil {
+					t.Fatal(err)
+				}
+			}
+			ctx, err := Propagate(tc.ctx, kptfile)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if got := kptfile.GetAnnotation(TraceParentAnnotation); got != tc.wantAnnotation {
+				t.Errorf("want annotation %q, got %q", tc.wantAnnotation, got)
+			}
+			if got := SpanContextFromContext(ctx); got != tc.wantCtx {
+				t.Errorf("want span context %v, got %v", tc.wantCtx, got)
+			}
+		})
+	}
+}
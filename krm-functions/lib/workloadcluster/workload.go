@@ -0,0 +1,34 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+
+package workloadcluster
+
+import (
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// IsWorkload returns true for the Deployments and StatefulSets
+func IsWorkload(o *fn.KubeObject) bool {
+	return o.IsGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment")) ||
+		o.IsGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("StatefulSet"))
+}
+
+// HasPodTemplate returns true for the resources with a pod template, the workloads and the DaemonSets
+func HasPodTemplate(o *fn.KubeObject) bool {
+	return IsWorkload(o) || o.IsGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("DaemonSet"))
+}
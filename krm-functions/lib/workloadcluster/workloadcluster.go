@@ -0,0 +1,97 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+
+package workloadcluster
+
+import (
+	nadlibv1 "github.com/nephio-project/nephio/krm-functions/lib/nad/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// WorkloadClusterExt captures the optional attributes of a WorkloadCluster resource that the
+// functions use to generate and place their resources, but which are not part of the infra api
+type WorkloadClusterExt struct {
+	Spec WorkloadClusterExtSpec `json:"spec,omitempty"`
+}
+
+type WorkloadClusterExtSpec struct {
+	// ClusterName defines the name of the cluster
+	ClusterName string `json:"clusterName,omitempty"`
+	// VLANDomain defines the vlan database the vlans of the cluster are claimed from; the clusters
+	// sharing a vlan domain share its vlans, by default a cluster has its own vlan domain
+	VLANDomain string `json:"vlanDomain,omitempty"`
+	// SRIOVResourcePools defines the sriov resource pools of the cluster the device plugin is configured with
+	SRIOVResourcePools []SRIOVResourcePool `json:"sriovResourcePools,omitempty"`
+	// NodePools defines the worker pools of the cluster
+	NodePools []NodePool `json:"nodePools,omitempty"`
+	// DNS defines the default resolver configuration for pods attached to secondary networks
+	DNS *nadlibv1.DNS `json:"dns,omitempty"`
+	// CNICapabilities defines the capabilities per cni of the workload cluster
+	CNICapabilities []CNICapability `json:"cniCapabilities,omitempty"`
+	// MasterInterfaces defines additional master interfaces of the workload cluster keyed by
+	// network purpose and/or node pool, e.g. the front-haul and mid-haul NICs of the nodes
+	MasterInterfaces []MasterInterface `json:"masterInterfaces,omitempty"`
+}
+
+type SRIOVResourcePool struct {
+	// Name defines the resource name of the pool, e.g. intel.com/sriov_netdevice_n6
+	Name string `json:"name"`
+	// PFNames defines the physical functions the virtual functions of the pool are created on
+	PFNames []string `json:"pfNames,omitempty"`
+	// NumVFs defines the number of virtual functions per physical function
+	NumVFs int `json:"numVfs,omitempty"`
+	// DeviceType defines the driver of the virtual functions, netdevice or vfio-pci; netdevice when not set
+	DeviceType string `json:"deviceType,omitempty"`
+	// MTU defines the mtu of the virtual functions
+	MTU int `json:"mtu,omitempty"`
+	// NodeSelector selects the nodes of the pool; the sriov capable nodes when not set
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+type NodePool struct {
+	// Name defines the name of the node pool
+	Name string `json:"name"`
+	// Capabilities defines the capabilities of the nodes of the pool, e.g. sriov, dpdk or hugepages
+	Capabilities []string `json:"capabilities,omitempty"`
+	// NodeSelector defines the labels of the nodes of the pool
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Taints defines the taints of the nodes of the pool, the workloads placed on the pool tolerate them
+	Taints []corev1.Taint `json:"taints,omitempty"`
+}
+
+// CNICapability defines what a cni of the workload cluster supports
+type CNICapability struct {
+	// Name defines the cniType the capabilities apply to
+	Name string `json:"name"`
+	// Modes defines the supported modes of the cni, e.g. l2, l3, l3s for ipvlan; all modes are supported when empty
+	Modes []string `json:"modes,omitempty"`
+	// MaxMTU defines the largest mtu supported by the cni; the mtu is not limited when 0
+	MaxMTU int `json:"maxMTU,omitempty"`
+	// VLAN defines if the cni supports vlan attachments; vlans are supported when not set
+	VLAN *bool `json:"vlan,omitempty"`
+	// SRIOVResourcePools defines the sriov resource pools of the cluster the cni can allocate devices from
+	SRIOVResourcePools []string `json:"sriovResourcePools,omitempty"`
+}
+
+type MasterInterface struct {
+	// Name defines the name of the master interface on the nodes
+	Name string `json:"name"`
+	// Purpose defines the network purpose the master interface is used for, e.g. fronthaul
+	Purpose string `json:"purpose,omitempty"`
+	// NodePool defines the node pool which has the master interface
+	NodePool string `json:"nodePool,omitempty"`
+}
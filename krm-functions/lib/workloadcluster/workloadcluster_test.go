@@ -0,0 +1,133 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+
+package workloadcluster
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/google/go-cmp/cmp"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	nadlibv1 "github.com/nephio-project/nephio/krm-functions/lib/nad/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const workloadCluster = `apiVersion: infra.nephio.org/v1alpha1
+kind: WorkloadCluster
+metadata:
+  name: cluster01
+spec:
+  clusterName: cluster01
+  cnis:
+  - sriov
+  vlanDomain: edge
+  sriovResourcePools:
+  - name: intel.com/sriov_netdevice_n6
+    pfNames:
+    - ens1f0
+    numVfs: 8
+  nodePools:
+  - name: dpdk
+    capabilities:
+    - dpdk
+    nodeSelector:
+      pool: dpdk
+    taints:
+    - key: dpdk
+      effect: NoSchedule
+  dns:
+    nameservers:
+    - 10.0.0.10
+  cniCapabilities:
+  - name: sriov
+    maxMTU: 9000
+  masterInterfaces:
+  - name: ens2f0
+    purpose: fronthaul
+`
+
+func TestWorkloadClusterExt(t *testing.T) {
+	o, err := fn.ParseKubeObject([]byte(workloadCluster))
+	if err != nil {
+		t.Fatalf("cannot parse object: %s", err.Error())
+	}
+	got, err := ko.KubeObjectToStruct[WorkloadClusterExt](o)
+	if err != nil {
+		t.Fatalf("cannot convert object: %s", err.Error())
+	}
+	want := &WorkloadClusterExt{
+		Spec: WorkloadClusterExtSpec{
+			ClusterName:        "cluster01",
+			VLANDomain:         "edge",
+			SRIOVResourcePools: []SRIOVResourcePool{{Name: "intel.com/sriov_netdevice_n6", PFNames: []string{"ens1f0"}, NumVFs: 8}},
+			NodePools: []NodePool{{
+				Name:         "dpdk",
+				Capabilities: []string{"dpdk"},
+				NodeSelector: map[string]string{"pool": "dpdk"},
+				Taints:       []corev1.Taint{{Key: "dpdk", Effect: corev1.TaintEffectNoSchedule}},
+			}},
+			DNS:              &nadlibv1.DNS{Nameservers: []string{"10.0.0.10"}},
+			CNICapabilities:  []CNICapability{{Name: "sriov", MaxMTU: 9000}},
+			MasterInterfaces: []MasterInterface{{Name: "ens2f0", Purpose: "fronthaul"}},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("-want, +got:\n%s", diff)
+	}
+}
+
+func TestIsWorkload(t *testing.T) {
+	cases := map[string]struct {
+		input          string
+		isWorkload     bool
+		hasPodTemplate bool
+	}{
+		"Deployment": {
+			input:          "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: upf\n",
+			isWorkload:     true,
+			hasPodTemplate: true,
+		},
+		"StatefulSet": {
+			input:          "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: upf\n",
+			isWorkload:     true,
+			hasPodTemplate: true,
+		},
+		"DaemonSet": {
+			input:          "apiVersion: apps/v1\nkind: DaemonSet\nmetadata:\n  name: upf\n",
+			hasPodTemplate: true,
+		},
+		"ConfigMap": {
+			input: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: upf\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o, err := fn.ParseKubeObject([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("cannot parse object: %s", err.Error())
+			}
+			if got := IsWorkload(o); got != tc.isWorkload {
+				t.Errorf("IsWorkload: want %t, got %t", tc.isWorkload, got)
+			}
+			if got := HasPodTemplate(o); got != tc.hasPodTemplate {
+				t.Errorf("HasPodTemplate: want %t, got %t", tc.hasPodTemplate, got)
+			}
+		})
+	}
+}
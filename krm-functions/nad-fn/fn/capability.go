@@ -0,0 +1,154 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	nadlibv1 "github.com/nephio-project/nephio/krm-functions/lib/nad/v1"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+	wclib "github.com/nephio-project/nephio/krm-functions/lib/workloadcluster"
+)
+
+const (
+	// mtuAnnotation requests the mtu of the interface in the pod
+	mtuAnnotation = "nephio.org/mtu"
+	// sriovResourcePoolAnnotation selects the sriov resource pool of the workload cluster the interface is allocated from
+	sriovResourcePoolAnnotation = "nephio.org/sriov-resource-pool"
+	// cniCapabilitiesAnnotation requests the runtime capabilities of the cni as a comma separated list of ips, mac and bandwidth
+	cniCapabilitiesAnnotation = "nephio.org/cni-capabilities"
+)
+
+// getCNICapability returns the capabilities of the cni with the given type in the workload cluster
+func (f *nadFn) getCNICapability(cniType nephioreqv1alpha1.CNIType) (wclib.CNICapability, bool) {
+	for _, c := range f.workloadClusterExt.Spec.CNICapabilities {
+		if nephioreqv1alpha1.CNIType(c.Name) == cniType {
+			return c, true
+		}
+	}
+	return wclib.CNICapability{}, false
+}
+
+// getCNITypes returns the cni types supported by the workload cluster
+func (f *nadFn) getCNITypes() []string {
+	cniTypes := append([]string{}, f.workloadCluster.Spec.CNIs...)
+	for _, c := range f.workloadClusterExt.Spec.CNICapabilities {
+		found := false
+		for _, cniType := range cniTypes {
+			if cniType == c.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			cniTypes = append(cniTypes, c.Name)
+		}
+	}
+	return cniTypes
+}
+
+// setCNICapabilities renders the mtu, sriov resource pool and runtime capabilities requested by the interface and
+// validates the requested configuration of the nad against the capabilities of the cni in
+// the workload cluster
+func (f *nadFn) setCNICapabilities(nad *nadlibv1.NadStruct, itfce *fn.KubeObject, cniType nephioreqv1alpha1.CNIType, vlanID int) error {
+	c, ok := f.getCNICapability(cniType)
+
+	if value := itfce.GetAnnotation(mtuAnnotation); value != "" {
+		mtu, err := strconv.Atoi(value)
+		if err != nil {
+			return results.Errorf(results.CodeInvalidInput, "invalid mtu %s requested by interface %s: %s", value, itfce.GetName(), err.Error()).
+				WithResource(itfce).WithField("metadata.annotations." + mtuAnnotation)
+		}
+		if ok && c.MaxMTU != 0 && mtu > c.MaxMTU {
+			return results.Errorf(results.CodeUnsupportedByCluster, "mtu %d requested by interface %s exceeds the max mtu %d of cniType %s in workload cluster %s; lower the mtu to at most %d", mtu, itfce.GetName(), c.MaxMTU, cniType, f.workloadCluster.Spec.ClusterName, c.MaxMTU).
+				WithResource(itfce).WithField("metadata.annotations." + mtuAnnotation)
+		}
+		if err := nad.SetMtu(mtu); err != nil {
+			return err
+		}
+	}
+
+	if value := itfce.GetAnnotation(cniCapabilitiesAnnotation); value != "" {
+		capabilities, err := nad.GetCapabilities()
+		if err != nil {
+			return err
+		}
+		for _, name := range strings.Split(value, ",") {
+			switch strings.TrimSpace(name) {
+			case "ips":
+				capabilities.Ips = true
+			case "mac":
+				capabilities.Mac = true
+			case "bandwidth":
+				capabilities.Bandwidth = true
+			default:
+				return results.Errorf(results.CodeInvalidInput, "invalid cni capability %s requested by interface %s; supported capabilities: ips, mac, bandwidth", name, itfce.GetName()).
+					WithResource(itfce).WithField("metadata.annotations." + cniCapabilitiesAnnotation)
+			}
+		}
+		if err := nad.SetCapabilities(capabilities); err != nil {
+			return fmt.Errorf("cannot set the cni capabilities requested by interface %s: %s", itfce.GetName(), err.Error())
+		}
+	}
+
+	if cniType == "sriov" {
+		pool := itfce.GetAnnotation(sriovResourcePoolAnnotation)
+		if pool == "" && ok && len(c.SRIOVResourcePools) == 1 {
+			// a single resource pool is selected by default
+			pool = c.SRIOVResourcePools[0]
+		}
+		if pool != "" {
+			if ok && len(c.SRIOVResourcePools) != 0 && !contains(c.SRIOVResourcePools, pool) {
+				return results.Errorf(results.CodeUnsupportedByCluster, "sriov resource pool %s requested by interface %s is not available in workload cluster %s; available resource pools: %v", pool, itfce.GetName(), f.workloadCluster.Spec.ClusterName, c.SRIOVResourcePools).
+					WithResource(itfce).WithField("metadata.annotations." + sriovResourcePoolAnnotation)
+			}
+			if err := nad.SetResourceName(pool); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !ok {
+		return nil
+	}
+	if vlanID != 0 && c.VLAN != nil && !*c.VLAN {
+		return results.Errorf(results.CodeUnsupportedByCluster, "vlan %d of interface %s is not supported by cniType %s in workload cluster %s; attach the interface without vlan or use a cniType with vlan support", vlanID, itfce.GetName(), cniType, f.workloadCluster.Spec.ClusterName).
+			WithResource(itfce)
+	}
+	mode, err := nad.GetMode()
+	if err != nil {
+		return err
+	}
+	if mode != "" && len(c.Modes) != 0 && !contains(c.Modes, mode) {
+		return results.Errorf(results.CodeUnsupportedByCluster, "mode %s of interface %s is not supported by cniType %s in workload cluster %s; supported modes: %v", mode, itfce.GetName(), cniType, f.workloadCluster.Spec.ClusterName, c.Modes).
+			WithResource(itfce)
+	}
+	return nil
+}
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fn
+
+import (
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	nadlibv1 "github.com/nephio-project/nephio/krm-functions/lib/nad/v1"
+	"github.com/nokia/k8s-ipam/pkg/iputil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ciliumCNIType = "cilium"
+
+	ciliumPodIPPoolAPIVersion = "cilium.io/v2alpha1"
+	ciliumPodIPPoolKind       = "CiliumPodIPPool"
+)
+
+// ciliumPodIPPool defines the ip pool cilium allocates the addresses of the pods from
+type ciliumPodIPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ciliumPodIPPoolSpec `json:"spec"`
+}
+
+type ciliumPodIPPoolSpec struct {
+	IPv4 *ciliumPoolCIDRs `json:"ipv4,omitempty"`
+	IPv6 *ciliumPoolCIDRs `json:"ipv6,omitempty"`
+}
+
+type ciliumPoolCIDRs struct {
+	CIDRs    []string `json:"cidrs"`
+	MaskSize int      `json:"maskSize"`
+}
+
+// getCNIType returns the cni type of the interface; the interface fn records the cni type
+// it selected for an interface without cniType in an annotation
+func getCNIType(itfce *nephioreqv1alpha1.Interface) nephioreqv1alpha1.CNIType {
+	if itfce.Spec.CNIType != "" {
+		return itfce.Spec.CNIType
+	}
+	return nephioreqv1alpha1.CNIType(itfce.GetAnnotations()[cniTypeAnnotation])
+}
+
+// isCiliumInterface returns true when the interface is attached by cilium instead of multus
+func isCiliumInterface(itfce *nephioreqv1alpha1.Interface) bool {
+	return getCNIType(itfce) == ciliumCNIType
+}
+
+// buildCiliumPodIPPools renders the generated nads as the cilium equivalent, an ip pool
+// holding the addresses of the nad per nad
+func buildCiliumPodIPPools(nads fn.KubeObjects) (fn.KubeObjects, error) {
+	pools := fn.KubeObjects{}
+	for _, o := range nads {
+		nad, err := nadlibv1.NewFromKubeObject(o)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := buildCiliumPodIPPool(nad)
+		if err != nil {
+			return nil, err
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// buildCiliumPodIPPool renders the ip pool holding the addresses of the nad, keeping the name of the nad
+func buildCiliumPodIPPool(nad *nadlibv1.NadStruct) (*fn.KubeObject, error) {
+	addresses, err := nad.GetIpamAddress()
+	if err != nil {
+		return nil, err
+	}
+	pool := &ciliumPodIPPool{
+		TypeMeta:   metav1.TypeMeta{APIVersion: ciliumPodIPPoolAPIVersion, Kind: ciliumPodIPPoolKind},
+		ObjectMeta: metav1.ObjectMeta{Name: nad.K.GetName()},
+	}
+	for _, address := range addresses {
+		pi, err := iputil.New(address.Address)
+		if err != nil {
+			return nil, err
+		}
+		// the pool only holds the address of the nad, hence the address is assigned to the pod
+		if pi.IsIpv4() {
+			if pool.Spec.IPv4 == nil {
+				pool.Spec.IPv4 = &ciliumPoolCIDRs{MaskSize: 32}
+			}
+			pool.Spec.IPv4.CIDRs = append(pool.Spec.IPv4.CIDRs, pi.GetIPAddressPrefix().String())
+		} else {
+			if pool.Spec.IPv6 == nil {
+				pool.Spec.IPv6 = &ciliumPoolCIDRs{MaskSize: 128}
+			}
+			pool.Spec.IPv6.CIDRs = append(pool.Spec.IPv6.CIDRs, pi.GetIPAddressPrefix().String())
+		}
+	}
+	return fn.NewFromTypedObject(pool)
+}
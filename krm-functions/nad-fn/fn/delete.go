@@ -0,0 +1,41 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+)
+
+// deleteResourceFn reports the deletion of the nad the sdk removes from the resourceList, e.g. when
+// its interface was removed, and deletes its readiness condition
+// In dry-run mode the result reports the nad would be deleted
+func (f *nadFn) deleteResourceFn(o *fn.KubeObject) error {
+	if f.dryRun {
+		f.rl.Results.Infof("dry-run: %s %s would be deleted", o.GetKind(), o.GetName())
+	} else {
+		f.rl.Results.Infof("%s %s deleted", o.GetKind(), o.GetName())
+	}
+	return f.deleteNadCondition(o)
+}
+
+// deleteNadCondition deletes the readiness condition of the nad from the condition store of the package
+func (f *nadFn) deleteNadCondition(o *fn.KubeObject) error {
+	delete(f.nadConditions, o.GetName())
+	return f.sdk.DeleteCondition(fmt.Sprintf("%s/%s", nadConditionType, o.GetName()))
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/nokia/k8s-ipam/pkg/iputil"
+)
+
+// dryRunKey is the key in the data of the function config ConfigMap that enables the
+// validation-only mode, in which the package is validated but not mutated
+const dryRunKey = "dryRun"
+
+// isDryRun returns true when the function config enables the validation-only mode
+func isDryRun(fc *fn.KubeObject) bool {
+	if fc == nil {
+		return false
+	}
+	v, _, _ := fc.NestedString("data", dryRunKey)
+	return v == "true"
+}
+
+// copyKubeObjects returns a deep copy of the objects
+func copyKubeObjects(objs fn.KubeObjects) (fn.KubeObjects, error) {
+	copies := fn.KubeObjects{}
+	for _, o := range objs {
+		c, err := fn.ParseKubeObject([]byte(o.String()))
+		if err != nil {
+			return nil, err
+		}
+		copies = append(copies, c)
+	}
+	return copies, nil
+}
+
+// dryRunUpdateResourceFn generates the nads like updateResourceFn and reports the outcome
+// as results, the package itself is restored after the sdk run
+func (f *nadFn) dryRunUpdateResourceFn(forObj *fn.KubeObject, objs fn.KubeObjects) (fn.KubeObjects, error) {
+	nads, err := f.updateResourceFn(forObj, objs)
+	if err != nil {
+		if forObj != nil {
+			f.rl.Results = append(f.rl.Results, fn.ErrorConfigObjectResult(err, forObj))
+		} else {
+			f.rl.Results.ErrorE(err)
+		}
+		return nil, err
+	}
+	for _, nad := range nads {
+		config, _, _ := nad.NestedString("spec", "config")
+		f.rl.Results = append(f.rl.Results, fn.ConfigObjectResult(fmt.Sprintf("dry-run: nad %s would be generated with config: %s", nad.GetName(), config), nad, fn.Info))
+	}
+	return nads, nil
+}
+
+// validateNetworks validates the prefixes and gateways of the routing tables of the networks
+// and reports every invalid entry as an error result
+func (f *nadFn) validateNetworks() {
+	for _, networkObj := range f.networkObjs {
+		for _, rt := range networkObj.Spec.RoutingTables {
+			for _, prefix := range rt.Prefixes {
+				if _, err := iputil.New(prefix.Prefix); err != nil {
+					f.rl.Results.Errorf("dry-run: network %s, routing table %s: invalid prefix %s: %s", networkObj.GetName(), rt.Name, prefix.Prefix, err.Error())
+				}
+			}
+		}
+		for _, rt := range f.networkExts[networkObj.GetName()].Spec.RoutingTables {
+			for _, prefix := range rt.Prefixes {
+				if prefix.Gateway == "" {
+					continue
+				}
+				if _, err := netip.ParsePrefix(prefix.Prefix); err != nil {
+					continue
+				}
+				if _, err := rt.getGateway(prefix.Prefix, ""); err != nil {
+					f.rl.Results.Errorf("dry-run: network %s: %s", networkObj.GetName(), err.Error())
+				}
+			}
+		}
+	}
+}
@@ -21,15 +21,17 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
-	"strings"
 
 	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
 	nadv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
 	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
 	"github.com/nephio-project/nephio/krm-functions/lib/condkptsdk"
 	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
 	nadlibv1 "github.com/nephio-project/nephio/krm-functions/lib/nad/v1"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+	wclib "github.com/nephio-project/nephio/krm-functions/lib/workloadcluster"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
 	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
 	"github.com/nokia/k8s-ipam/pkg/iputil"
@@ -37,26 +39,82 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-const defaultPODNetwork = "default"
+const (
+	defaultPODNetwork = "default"
+	// hostDeviceNameAnnotation selects the device by name for a host-device interface
+	hostDeviceNameAnnotation = "nephio.org/host-device-name"
+	// hostDevicePCIAddressAnnotation selects the device by pci address for a host-device interface
+	hostDevicePCIAddressAnnotation = "nephio.org/host-device-pci-address"
+	// vlanTagAnnotation identifies the position of the vlan of a VLANClaim in a QinQ (802.1ad) stack
+	vlanTagAnnotation = "nephio.org/vlan-tag"
+	vlanTagInner      = "inner"
+	// ipvlanModeAnnotation selects the mode of an ipvlan interface: l2 (default), l3 or l3s
+	ipvlanModeAnnotation = "nephio.org/ipvlan-mode"
+	// macAddressAnnotation assigns a static mac address to the interface, e.g. for NFs licensed by mac
+	macAddressAnnotation = "nephio.org/mac-address"
+	// defaultRouteAnnotation renders a default route towards the gateway of the ip claim instead
+	// of the routes to the prefixes of the routing table, e.g. for egress interfaces like N6
+	defaultRouteAnnotation = "nephio.org/default-route"
+	// networkPurposeAnnotation and nodePoolAnnotation select the master interface of the workload cluster
+	// e.g. the front-haul vs mid-haul NIC, when the workload cluster defines multiple master interfaces
+	networkPurposeAnnotation = "nephio.org/network-purpose"
+	nodePoolAnnotation       = "nephio.org/node-pool"
+	// cniConfigAnnotation references a ConfigMap in the package holding a raw cni config in its data
+	// the generated ipam and vlan data is merged into the raw cni config
+	cniConfigAnnotation = "nephio.org/cni-config"
+	cniConfigKey        = "config"
+	// nadConditionType is the type of the readiness condition of a generated NAD in the Kptfile
+	nadConditionType = "nad.k8s.cni.cncf.io"
+	// nadMergeAnnotation on an existing NAD preserves the fields of the NAD which are not generated
+	nadMergeAnnotation = "nephio.org/nad-merge"
+	// cniTypeAnnotation is the cni type the interface fn selected for an interface without cniType
+	cniTypeAnnotation = "nephio.org/cni-type"
+)
 
 type nadFn struct {
-	sdk             condkptsdk.KptCondSDK
-	workloadCluster *infrav1alpha1.WorkloadCluster
-	forName         string
-	forNamespace    string
-	networkObjs     []infrav1alpha1.Network
+	sdk                condkptsdk.KptCondSDK
+	rl                 *fn.ResourceList
+	workloadCluster    *infrav1alpha1.WorkloadCluster
+	workloadClusterExt *wclib.WorkloadClusterExt
+	networkObjs        []infrav1alpha1.Network
+	networkExts        map[string]networkExt
+	nadConditions      map[string]kptv1.Condition
+	configMaps         map[string]*fn.KubeObject
+	dryRun             bool
 }
 
 func Run(rl *fn.ResourceList) (bool, error) {
-	myFn := nadFn{}
+	myFn := nadFn{
+		rl:            rl,
+		networkExts:   map[string]networkExt{},
+		nadConditions: map[string]kptv1.Condition{},
+		configMaps:    map[string]*fn.KubeObject{},
+	}
 	var err error
+	// in dry-run mode the package is validated and the outcome is reported as results,
+	// the resources of the package are restored once the sdk has run
+	myFn.dryRun = isDryRun(rl.FunctionConfig)
+	updateResourceFn := myFn.updateResourceFn
+	var items fn.KubeObjects
+	if myFn.dryRun {
+		items, err = copyKubeObjects(rl.Items)
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		updateResourceFn = myFn.dryRunUpdateResourceFn
+	}
 	myFn.sdk, err = condkptsdk.New(
 		rl,
 		&condkptsdk.Config{
-			For: corev1.ObjectReference{
+			For: []corev1.ObjectReference{{
 				APIVersion: nadv1.SchemeGroupVersion.Identifier(),
 				Kind:       reflect.TypeOf(nadv1.NetworkAttachmentDefinition{}).Name(),
-			},
+			}, {
+				// the ip pools replace the nads of the cilium interfaces
+				APIVersion: ciliumPodIPPoolAPIVersion,
+				Kind:       ciliumPodIPPoolKind,
+			}},
 			Watch: map[corev1.ObjectReference]condkptsdk.WatchCallbackFn{
 				{
 					APIVersion: infrav1alpha1.GroupVersion.Identifier(),
@@ -78,16 +136,56 @@ func Run(rl *fn.ResourceList) (bool, error) {
 					APIVersion: nephioreqv1alpha1.GroupVersion.Identifier(),
 					Kind:       nephioreqv1alpha1.InterfaceKind,
 				}: nil,
+				{
+					APIVersion: corev1.SchemeGroupVersion.Identifier(),
+					Kind:       reflect.TypeOf(corev1.ConfigMap{}).Name(),
+				}: myFn.ConfigMapCallbackFn,
+			},
+			// only the ConfigMaps holding the raw cni config of an interface are relevant
+			WatchSelectors: map[corev1.ObjectReference]condkptsdk.WatchSelector{
+				{
+					APIVersion: corev1.SchemeGroupVersion.Identifier(),
+					Kind:       reflect.TypeOf(corev1.ConfigMap{}).Name(),
+				}: {
+					ReferencedBy: &condkptsdk.WatchReference{
+						Ref: corev1.ObjectReference{
+							APIVersion: nephioreqv1alpha1.GroupVersion.Identifier(),
+							Kind:       nephioreqv1alpha1.InterfaceKind,
+						},
+						Annotation: cniConfigAnnotation,
+					},
+				},
 			},
 			PopulateOwnResourcesFn: nil,
-			UpdateResourceFn:       myFn.updateResourceFn,
+			UpdateResourceFn:       updateResourceFn,
+			DeleteResourceFn:       myFn.deleteResourceFn,
+			// the nads are generated for the nad conditions of the interfaces, a nad per network instance
+			GenerateFor: true,
+			// the nad is generated once the ip and vlan claims are allocated
+			RequiredStatus: map[corev1.ObjectReference][]string{
+				{
+					APIVersion: ipamv1alpha1.GroupVersion.Identifier(),
+					Kind:       ipamv1alpha1.IPClaimKind,
+				}: {"prefix"},
+				{
+					APIVersion: vlanv1alpha1.GroupVersion.Identifier(),
+					Kind:       vlanv1alpha1.VLANClaimKind,
+				}: {"vlanID|vlanRange"},
+			},
 		},
 	)
 	if err != nil {
-		rl.Results.ErrorE(err)
+		results.ErrorE(rl, err)
 		return false, err
 	}
-	return myFn.sdk.Run()
+	ok, err := myFn.sdk.Run()
+	myFn.setNadConditions()
+	if myFn.dryRun {
+		myFn.validateNetworks()
+		rl.Results.Sort()
+		rl.Items = items
+	}
+	return ok, err
 }
 
 // WorkloadClusterCallbackFn provides a callback for the workload cluster
@@ -96,9 +194,13 @@ func (f *nadFn) WorkloadClusterCallbackFn(o *fn.KubeObject) error {
 	var err error
 
 	if f.workloadCluster != nil {
-		return fmt.Errorf("multiple WorkloadCluster objects found in the kpt package")
+		return results.Errorf(results.CodeDuplicateResource, "multiple WorkloadCluster objects found in the kpt package").WithResource(o)
 	}
-	f.workloadCluster, err = ko.KubeObjectToStruct[infrav1alpha1.WorkloadCluster](o)
+	f.workloadCluster, err = ko.CachedKubeObjectToStruct[infrav1alpha1.WorkloadCluster](o)
+	if err != nil {
+		return err
+	}
+	f.workloadClusterExt, err = ko.KubeObjectToStruct[wclib.WorkloadClusterExt](o)
 	if err != nil {
 		return err
 	}
@@ -113,13 +215,116 @@ func (f *nadFn) NetworkCallbackFn(o *fn.KubeObject) error {
 		return err
 	}
 	f.networkObjs = append(f.networkObjs, *networkObj)
+	networkExt, err := ko.KubeObjectToStruct[networkExt](o)
+	if err != nil {
+		return err
+	}
+	f.networkExts[networkObj.GetName()] = *networkExt
+	return nil
+}
+
+// ConfigMapCallbackFn provides a callback for the ConfigMap resources in the
+// resourceList, which can hold raw cni configs referenced by the interfaces
+func (f *nadFn) ConfigMapCallbackFn(o *fn.KubeObject) error {
+	f.configMaps[o.GetName()] = o
 	return nil
 }
 
-func (f *nadFn) updateResourceFn(_ *fn.KubeObject, objs fn.KubeObjects) (fn.KubeObjects, error) {
+// getRawCNIConfig returns the raw cni config of the ConfigMap with the given name
+// A ConfigMap with an owner only provides the raw cni config to the interfaces of that owner
+func (f *nadFn) getRawCNIConfig(name string, scope condkptsdk.Scope) (string, error) {
+	cm, ok := f.configMaps[name]
+	if !ok {
+		return "", results.Errorf(results.CodeMissingResource, "ConfigMap %s with the raw cni config is missing from the kpt package", name)
+	}
+	if !scope.Matches(cm) {
+		cmScope := condkptsdk.GetScope(cm)
+		return "", results.Errorf(results.CodeInvalidInput, "ConfigMap %s with the raw cni config belongs to %s %s, not to %s %s", name, cmScope.Owner.Kind, cmScope.Owner.Name, scope.Owner.Kind, scope.Owner.Name).WithResource(cm)
+	}
+	raw, ok, err := cm.NestedString("data", cniConfigKey)
+	if err != nil {
+		return "", err
+	}
+	if !ok || raw == "" {
+		return "", results.Errorf(results.CodeInvalidInput, "ConfigMap %s has no raw cni config in data.%s", name, cniConfigKey).
+			WithResource(cm).WithField("data." + cniConfigKey)
+	}
+	return raw, nil
+}
+
+// updateResourceFn generates the nads and records a readiness condition per nad
+func (f *nadFn) updateResourceFn(forObj *fn.KubeObject, objs fn.KubeObjects) (fn.KubeObjects, error) {
+	nads, err := f.generateNads(forObj, objs)
+	if err != nil {
+		if name := getNadName(forObj, objs); name != "" {
+			f.setNadCondition(name, kptv1.ConditionFalse, condkptsdk.GetErrorReason(err),
+				"{{.ForKind}} {{.For}} of {{.OwnerKind}} {{.Owner}} not generated: {{.Detail}}",
+				getNadMessageData(name, reflect.TypeOf(nadv1.NetworkAttachmentDefinition{}).Name(), objs, err.Error()))
+		}
+		return nil, err
+	}
+	for _, nad := range nads {
+		// the kind tells the nads apart from the cilium ip pools of the cilium interfaces
+		f.setNadCondition(nad.GetName(), kptv1.ConditionTrue, condkptsdk.ConditionReasonGenerated,
+			"{{.ForKind}} {{.For}} of {{.OwnerKind}} {{.Owner}} generated",
+			getNadMessageData(nad.GetName(), nad.GetKind(), objs, ""))
+	}
+	return nads, nil
+}
+
+// setNadCondition records the readiness condition of the nad with the standardized reason and the message
+// rendered from the template
+func (f *nadFn) setNadCondition(name string, status kptv1.ConditionStatus, reason condkptsdk.ConditionReason, msgTemplate string, data condkptsdk.ConditionMessageData) {
+	c, err := condkptsdk.NewCondition(fmt.Sprintf("%s/%s", nadConditionType, name), status, reason, msgTemplate, data)
+	if err != nil {
+		fn.Logf("cannot build nad condition %s, err: %s\n", name, err.Error())
+		f.rl.Results.ErrorE(err)
+		return
+	}
+	f.nadConditions[name] = c
+}
+
+// getNadMessageData returns the message data of the condition of the nad, the owner of the nad is its interface
+func getNadMessageData(name, kind string, objs fn.KubeObjects, detail string) condkptsdk.ConditionMessageData {
+	data := condkptsdk.ConditionMessageData{For: name, ForKind: kind, Detail: detail}
+	for _, o := range objs.Where(fn.IsGroupVersionKind(nephioreqv1alpha1.InterfaceGroupVersionKind)) {
+		data.Owner = o.GetName()
+		data.OwnerKind = o.GetKind()
+	}
+	return data
+}
+
+// setNadConditions sets the readiness conditions of the nads in the Kptfile
+// the conditions are set in alphabetical order of the nads to keep the Kptfile stable
+func (f *nadFn) setNadConditions() {
+	names := make([]string, 0, len(f.nadConditions))
+	for name := range f.nadConditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := f.sdk.SetCondition(f.nadConditions[name]); err != nil {
+			fn.Logf("cannot set nad condition %s, err: %s\n", name, err.Error())
+			f.rl.Results.ErrorE(err)
+		}
+	}
+}
+
+// getNadName returns the name of the nad of the interface
+func getNadName(forObj *fn.KubeObject, objs fn.KubeObjects) string {
+	for _, o := range objs.Where(fn.IsGroupVersionKind(nephioreqv1alpha1.InterfaceGroupVersionKind)) {
+		return fmt.Sprintf("%s-%s", condkptsdk.GetScope(o).Owner.Name, o.GetName())
+	}
+	if forObj != nil {
+		return forObj.GetName()
+	}
+	return ""
+}
+
+func (f *nadFn) generateNads(forObj *fn.KubeObject, objs fn.KubeObjects) (fn.KubeObjects, error) {
 	if f.workloadCluster == nil {
 		// no WorkloadCluster resource in the package
-		return nil, fmt.Errorf("workload cluster is missing from the kpt package")
+		return nil, results.Errorf(results.CodeMissingResource, "workload cluster is missing from the kpt package")
 	}
 
 	// the NAD needs a prefix equal to the owner of the deployment and it needs a namespace aligned with the deployment
@@ -129,15 +334,11 @@ func (f *nadFn) updateResourceFn(_ *fn.KubeObject, objs fn.KubeObjects) (fn.Kube
 	if interfaceObjs.Len() == 0 {
 		return nil, fmt.Errorf("expected %s object to generate the nad", nephioreqv1alpha1.InterfaceKind)
 	}
-	for _, o := range interfaceObjs {
-		f.forName = getForName(o.GetAnnotations())
-		f.forNamespace = o.GetAnnotation(condkptsdk.SpecializerNamespace)
-		//fn.Logf("interface callback: kind: %s, name: %s, namespace: %s, annotations: %s\n", o.GetKind(), o.GetName(), o.GetNamespace(), o.GetAnnotations())
-	}
-
-	if f.forName == "" || f.forNamespace == "" {
+	// the scope is resolved per interface since multiple deployments can share the package
+	scope := condkptsdk.GetScope(interfaceObjs[0])
+	if err := scope.Validate(); err != nil {
 		// no for name or for namespace present
-		return nil, fmt.Errorf("expecting a for name and for namespace, got forName: %s, forNamespace: %s", f.forName, f.forNamespace)
+		return nil, err
 	}
 
 	ipClaimObjs := objs.Where(fn.IsGroupVersionKind(ipamv1alpha1.IPClaimGroupVersionKind))
@@ -159,18 +360,83 @@ func (f *nadFn) updateResourceFn(_ *fn.KubeObject, objs fn.KubeObjects) (fn.Kube
 	if itfce.Spec.NetworkInstance.Name == defaultPODNetwork {
 		return nil, nil
 	}
+	// an internal-only interface is not attached, hence no nad is expected
+	if itfce.Spec.AttachmentType == nephioreqv1alpha1.AttachmentTypeNone {
+		return nil, nil
+	}
 
 	if ipClaimObjs.Len() == 0 && vlanClaimObjs.Len() == 0 {
 		return nil, fmt.Errorf("expected one of %s or %s objects to generate the nad", ipamv1alpha1.IPClaimKind, vlanv1alpha1.VLANClaimKind)
 	}
 
+	// a nad is generated per network instance the ip claims of the interface belong to
+	// the nad of the network instance of the interface keeps the name of the interface
+	// for the nads of additional network instances the network instance name is appended
+	nads := fn.KubeObjects{}
+	for _, niClaims := range groupIPClaimsByNetworkInstance(ipClaimObjs, itfce.Spec.NetworkInstance.Name) {
+		name := fmt.Sprintf("%s-%s", scope.Owner.Name, interfaceObjs[0].GetName())
+		if niClaims.networkInstance != itfce.Spec.NetworkInstance.Name {
+			name = fmt.Sprintf("%s-%s", name, niClaims.networkInstance)
+		}
+		nad, err := f.buildNad(name, scope.Namespace, interfaceObjs, niClaims.ipClaimObjs, vlanClaimObjs)
+		if err != nil {
+			return nil, err
+		}
+		// the static mac address belongs to the nad of the network instance of the interface only
+		// since the same mac on multiple attachments of the pod would conflict
+		if mac := interfaceObjs[0].GetAnnotation(macAddressAnnotation); mac != "" &&
+			niClaims.networkInstance == itfce.Spec.NetworkInstance.Name && nad.CniSpecType != nadlibv1.VlanClaimOnly {
+			if err := nad.SetMacAddress(mac); err != nil {
+				return nil, err
+			}
+		}
+
+		if cmName := interfaceObjs[0].GetAnnotation(cniConfigAnnotation); cmName != "" && nad.CniSpecType != nadlibv1.VlanClaimOnly {
+			raw, err := f.getRawCNIConfig(cmName, scope)
+			if err != nil {
+				return nil, err
+			}
+			if err := nad.SetRawConfig(raw); err != nil {
+				return nil, err
+			}
+		}
+
+		// in merge mode the existing nad, e.g. hand-edited by the operator, is updated
+		// instead of being regenerated from scratch
+		if forObj != nil && forObj.GetName() == nad.K.GetName() && forObj.GetAnnotation(nadMergeAnnotation) == "true" {
+			existingNad, err := nadlibv1.NewFromKubeObject(forObj)
+			if err != nil {
+				return nil, err
+			}
+			if err := nad.Merge(existingNad); err != nil {
+				return nil, err
+			}
+		}
+		// the rendered cni config is validated here since multus only fails at pod creation time
+		// the nad of a cilium interface only carries the addresses of its ip pool
+		if !isCiliumInterface(itfce) {
+			if err := nad.ValidateConfig(); err != nil {
+				return nil, fmt.Errorf("invalid cni config of nad %s: %s", name, err.Error())
+			}
+		}
+		nads = append(nads, &nad.K.KubeObject)
+	}
+	if isCiliumInterface(itfce) {
+		// cilium attaches the interface using the ip pools of the nads instead of the nads
+		return buildCiliumPodIPPools(nads)
+	}
+	return nads, nil
+}
+
+// buildNad generates the nad with the given name from the interface and its claims
+func (f *nadFn) buildNad(name, namespace string, interfaceObjs, ipClaimObjs, vlanClaimObjs fn.KubeObjects) (*nadlibv1.NadStruct, error) {
 	// generate an empty nad struct
 	nad, err := nadlibv1.NewFromGoStruct(&nadv1.NetworkAttachmentDefinition{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: nadv1.SchemeGroupVersion.Identifier(),
 			Kind:       reflect.TypeOf(nadv1.NetworkAttachmentDefinition{}).Name(),
 		},
-		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%s", f.forName, interfaceObjs[0].GetName()), Namespace: f.forNamespace},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
 	})
 	if err != nil {
 		return nil, err
@@ -180,13 +446,50 @@ func (f *nadFn) updateResourceFn(_ *fn.KubeObject, objs fn.KubeObjects) (fn.Kube
 		nad.CniSpecType = nadlibv1.VlanClaimOnly
 	}
 
+	// vlanID is the outer (S-VLAN) tag, innerVlanID the optional inner (C-VLAN) tag
+	// the inner tag is either provided by the vlanClaim status of the outer tag
+	// or by a separate vlanClaim annotated as the inner tag
 	vlanID := 0
+	innerVlanID := 0
+	vlanIDs := []int{}
+	// vlanRanges are the vlan ranges of the trunk claims of the interface
+	vlanRanges := []nadlibv1.VlanTrunk{}
 	for _, vlanClaim := range vlanClaimObjs {
-		vlanID, _, _ = vlanClaim.NestedInt([]string{"status", "vlanID"}...)
+		status, err := ko.GetStatus[vlanClaimStatusExt](vlanClaim)
+		if err != nil {
+			continue
+		}
+		if status.VLANRange != "" {
+			trunk, err := status.getVlanTrunk()
+			if err != nil {
+				return nil, err
+			}
+			vlanRanges = append(vlanRanges, trunk)
+			continue
+		}
+		if status.VLANID == 0 {
+			continue
+		}
+		id := status.VLANID
+		if vlanClaim.GetAnnotation(vlanTagAnnotation) == vlanTagInner {
+			innerVlanID = id
+			continue
+		}
+		vlanID = id
+		vlanIDs = append(vlanIDs, id)
+		if status.InnerVLANID != 0 {
+			innerVlanID = status.InnerVLANID
+		}
 	}
+	sort.Ints(vlanIDs)
 
 	if nad.CniSpecType != nadlibv1.VlanClaimOnly {
+		noGateway := false
+		defaultRoute := false
 		for _, itfce := range interfaceObjs {
+			if itfce.GetAnnotation(defaultRouteAnnotation) == "true" {
+				defaultRoute = true
+			}
 			i, err := ko.NewFromKubeObject[nephioreqv1alpha1.Interface](itfce)
 			if err != nil {
 				return nil, err
@@ -197,51 +500,98 @@ func (f *nadFn) updateResourceFn(_ *fn.KubeObject, objs fn.KubeObjects) (fn.Kube
 				return nil, err
 			}
 
-			if !f.IsCNITypePresent(itfceGoStruct.Spec.CNIType) {
-				return nil, fmt.Errorf("cniType not supported in workload cluster; workload cluster CNI(s): %v, interface cniType requested: %s", f.workloadCluster.Spec.CNIs, itfceGoStruct.Spec.CNIType)
+			cniType := getCNIType(itfceGoStruct)
+			if !f.IsCNITypePresent(cniType) {
+				return nil, results.Errorf(results.CodeUnsupportedByCluster, "cniType not supported in workload cluster; workload cluster CNI(s): %v, interface cniType requested: %s", f.getCNITypes(), cniType).
+					WithResource(itfce).WithField("spec.cniType")
+			}
+			if cniType == ciliumCNIType {
+				// the nad only carries the addresses the cilium ip pool is rendered from
+				continue
 			}
-			cniType := itfceGoStruct.Spec.CNIType
 
 			if err := nad.SetCNIType(string(cniType)); err != nil {
 				return nil, err
 			}
-			masterInterface := *f.workloadCluster.Spec.MasterInterface // since we validated the workload cluster before it is safe to do this
-			if cniType != "vlan" && vlanID != 0 {
-				masterInterface = fmt.Sprintf("%s.%s", masterInterface, strconv.Itoa(vlanID))
+			masterInterface, err := f.getMasterInterfaceName(itfce.GetAnnotation(networkPurposeAnnotation), itfce.GetAnnotation(nodePoolAnnotation))
+			if err != nil {
+				return nil, err
+			}
+			if len(vlanRanges) != 0 && cniType != "bridge" && cniType != "ovs" {
+				return nil, results.Errorf(results.CodeUnsupportedByCluster, "vlan range not supported for cniType %s, supported cniTypes: [bridge ovs]", cniType).WithResource(itfce)
 			}
-			if cniType != "bridge" {
-				err = nad.SetNadMaster(masterInterface)
+			switch cniType {
+			case "bridge":
+				// the bridge is named after the access vlan or the first vlan of the trunk
+				bridgeVlanID := vlanID
+				if bridgeVlanID == 0 && len(vlanRanges) != 0 {
+					bridgeVlanID = vlanRanges[0].MinID
+				}
+				err = nad.SetBridgeName(bridgeVlanID)
 				if err != nil {
 					return nil, err
 				}
-			} else {
-				err = nad.SetBridgeName(vlanID)
+				if len(vlanRanges) != 0 {
+					if err := nad.SetVlanTrunk(vlanRanges); err != nil {
+						return nil, err
+					}
+				}
+			case "host-device":
+				// the device is owned exclusively by the pod, hence no master interface or vlan is used
+				if err := nad.SetHostDevice(itfce.GetAnnotation(hostDeviceNameAnnotation), itfce.GetAnnotation(hostDevicePCIAddressAnnotation)); err != nil {
+					return nil, err
+				}
+			case "ovs":
+				// the master interface identifies the ovs bridge on the nodes of the cluster;
+				// the vlan is handled by ovs as an access tag or as a trunk
+				if err := f.setOvsConfig(nad, masterInterface, vlanIDs, vlanRanges); err != nil {
+					return nil, err
+				}
+			default:
+				err = nad.SetNadMaster(getMasterInterface(string(cniType), masterInterface, vlanID, innerVlanID))
 				if err != nil {
 					return nil, err
 				}
+				if cniType == "vlan" && vlanID != 0 && innerVlanID != 0 {
+					// the vlan cni pushes the inner tag on top of the sub-interface carrying the outer tag
+					if err := nad.SetVlanID(innerVlanID); err != nil {
+						return nil, err
+					}
+				}
+				if cniType == "ipvlan" {
+					if mode := itfce.GetAnnotation(ipvlanModeAnnotation); mode != "" {
+						if err := nad.SetIpVlanMode(mode); err != nil {
+							return nil, err
+						}
+						// in l3 modes the master interface routes the traffic, hence gateways are meaningless
+						noGateway = mode == nadlibv1.ModeL3 || mode == nadlibv1.ModeL3S
+					}
+				}
+			}
+			if err := f.setCNICapabilities(nad, itfce, cniType, vlanID); err != nil {
+				return nil, err
 			}
 		}
 
 		var nadAddresses []nadlibv1.Address
 		var nadRoutes []nadlibv1.Route
-		for _, ipClaim := range ipClaimObjs {
-			claim, err := ko.NewFromKubeObject[ipamv1alpha1.IPClaim](ipClaim)
-			if err != nil {
-				return nil, err
-			}
-
-			ipclaimGoStruct, err := claim.GetGoStruct()
-			if err != nil {
-				return nil, err
-			}
+		var nadDNS *nadlibv1.DNS
+		ipClaims, err := ko.KubeObjectsToStructs[ipamv1alpha1.IPClaim](ipClaimObjs)
+		if err != nil {
+			return nil, err
+		}
+		for _, ipclaimGoStruct := range ipClaims {
 			address := ""
 			gateway := ""
 			if ipclaimGoStruct.Status.Prefix != nil {
 				address = *ipclaimGoStruct.Status.Prefix
 			}
-			if ipclaimGoStruct.Status.Gateway != nil {
+			if ipclaimGoStruct.Status.Gateway != nil && !noGateway {
 				gateway = *ipclaimGoStruct.Status.Gateway
 			}
+			if nadDNS == nil {
+				nadDNS = f.getDNS(ipclaimGoStruct.Spec.NetworkInstance.Name)
+			}
 			if !containsAddress(nadAddresses, address) {
 				nadAddresses = append(nadAddresses, nadlibv1.Address{
 					Address: address,
@@ -249,7 +599,17 @@ func (f *nadFn) updateResourceFn(_ *fn.KubeObject, objs fn.KubeObjects) (fn.Kube
 				})
 			}
 
-			if address != "" && gateway != "" {
+			if address != "" && defaultRoute {
+				route, ok, err := f.getDefaultRoute(ipclaimGoStruct.Spec.NetworkInstance.Name, address, gateway, noGateway)
+				if err != nil {
+					return nil, err
+				}
+				if ok && !containsRoute(nadRoutes, route.Destination, route.Table) {
+					nadRoutes = append(nadRoutes, route)
+				}
+			}
+
+			if address != "" && !defaultRoute {
 				for _, networkObj := range f.networkObjs {
 					for _, rt := range networkObj.Spec.RoutingTables {
 						if rt.Name == ipclaimGoStruct.Spec.NetworkInstance.Name {
@@ -263,8 +623,33 @@ func (f *nadFn) updateResourceFn(_ *fn.KubeObject, objs fn.KubeObjects) (fn.Kube
 									return nil, err
 								}
 								if pi.GetAddressFamily().String() == pia.GetAddressFamily().String() {
-									if !containsDestination(nadRoutes, prefix.Prefix) {
-										nadRoutes = append(nadRoutes, nadlibv1.Route{Destination: prefix.Prefix, Gateway: gateway})
+									rtExt := f.getRoutingTableExt(networkObj.GetName(), rt.Name)
+									if rtExt.isOnLink(prefix.Prefix) {
+										if !containsRoute(nadRoutes, prefix.Prefix, rtExt.Table) {
+											route := nadlibv1.NewOnLinkRoute(prefix.Prefix)
+											route.Metric = rtExt.Metric
+											route.Table = rtExt.Table
+											nadRoutes = append(nadRoutes, route)
+										}
+										continue
+									}
+									routeGateway := ""
+									if !noGateway {
+										routeGateway, err = rtExt.getGateway(prefix.Prefix, gateway)
+										if err != nil {
+											return nil, err
+										}
+										if routeGateway == "" {
+											continue
+										}
+									}
+									if !containsRoute(nadRoutes, prefix.Prefix, rtExt.Table) {
+										nadRoutes = append(nadRoutes, nadlibv1.Route{
+											Destination: prefix.Prefix,
+											Gateway:     routeGateway,
+											Metric:      rtExt.Metric,
+											Table:       rtExt.Table,
+										})
 									}
 								}
 							}
@@ -281,19 +666,135 @@ func (f *nadFn) updateResourceFn(_ *fn.KubeObject, objs fn.KubeObjects) (fn.Kube
 			return nil, err
 		}
 		sort.Slice(nadRoutes, func(i, j int) bool {
-			return nadRoutes[i].Destination < nadRoutes[j].Destination
+			if nadRoutes[i].Destination != nadRoutes[j].Destination {
+				return nadRoutes[i].Destination < nadRoutes[j].Destination
+			}
+			return nadRoutes[i].Table < nadRoutes[j].Table
 		})
 		err = nad.SetIpamRoutes(nadRoutes)
 		if err != nil {
 			return nil, err
 		}
+		err = nad.SetIpamDNS(nadDNS)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return fn.KubeObjects{&nad.K.KubeObject}, nil
+	return nad, nil
+}
+
+type networkInstanceIPClaims struct {
+	networkInstance string
+	ipClaimObjs     fn.KubeObjects
+}
+
+// groupIPClaimsByNetworkInstance groups the ip claims by the network instance they belong to
+// The network instance of the interface comes first, the additional network instances follow
+// in alphabetical order. Without ip claims the network instance of the interface is returned
+func groupIPClaimsByNetworkInstance(ipClaimObjs fn.KubeObjects, networkInstance string) []networkInstanceIPClaims {
+	groups := map[string]fn.KubeObjects{}
+	for _, ipClaim := range ipClaimObjs {
+		ni, _, _ := ipClaim.NestedString([]string{"spec", "networkInstance", "name"}...)
+		if ni == "" {
+			ni = networkInstance
+		}
+		groups[ni] = append(groups[ni], ipClaim)
+	}
+	if len(groups) == 0 {
+		return []networkInstanceIPClaims{{networkInstance: networkInstance}}
+	}
+	nis := make([]string, 0, len(groups))
+	for ni := range groups {
+		nis = append(nis, ni)
+	}
+	sort.Slice(nis, func(i, j int) bool {
+		if nis[i] == networkInstance || nis[j] == networkInstance {
+			return nis[i] == networkInstance
+		}
+		return nis[i] < nis[j]
+	})
+	result := make([]networkInstanceIPClaims, 0, len(nis))
+	for _, ni := range nis {
+		result = append(result, networkInstanceIPClaims{networkInstance: ni, ipClaimObjs: groups[ni]})
+	}
+	return result
+}
+
+// getDefaultRoute returns the default route of the address family of the address towards the gateway
+// The metric and table of the routing table the ip claim belongs to apply to the default route
+// No route is returned when a gateway is required but the ip claim has none
+func (f *nadFn) getDefaultRoute(routingTableName, address, gateway string, noGateway bool) (nadlibv1.Route, bool, error) {
+	pia, err := iputil.New(address)
+	if err != nil {
+		return nadlibv1.Route{}, false, err
+	}
+	if gateway == "" && !noGateway {
+		return nadlibv1.Route{}, false, nil
+	}
+	route := nadlibv1.Route{Destination: "0.0.0.0/0", Gateway: gateway}
+	if !pia.IsIpv4() {
+		route.Destination = "::/0"
+	}
+	for _, networkObj := range f.networkObjs {
+		for _, rt := range networkObj.Spec.RoutingTables {
+			if rt.Name == routingTableName {
+				rtExt := f.getRoutingTableExt(networkObj.GetName(), rt.Name)
+				route.Metric = rtExt.Metric
+				route.Table = rtExt.Table
+				return route, true, nil
+			}
+		}
+	}
+	return route, true, nil
+}
+
+// getMasterInterface returns the master interface of the nad
+// when a vlan is used the master is the vlan sub-interface of the master interface of the cluster
+// e.g. eth1.100 or in case of QinQ (802.1ad) the stacked sub-interface eth1.100.200
+// The vlan cni creates the vlan sub-interface itself, hence only the outer tag is part of the master
+func getMasterInterface(cniType, masterInterface string, vlanID, innerVlanID int) string {
+	if vlanID == 0 {
+		return masterInterface
+	}
+	if cniType == "vlan" {
+		if innerVlanID == 0 {
+			return masterInterface
+		}
+		return fmt.Sprintf("%s.%s", masterInterface, strconv.Itoa(vlanID))
+	}
+	masterInterface = fmt.Sprintf("%s.%s", masterInterface, strconv.Itoa(vlanID))
+	if innerVlanID != 0 {
+		masterInterface = fmt.Sprintf("%s.%s", masterInterface, strconv.Itoa(innerVlanID))
+	}
+	return masterInterface
+}
+
+// setOvsConfig sets the bridge and vlan configuration of the ovs cni
+// a single vlan is configured as an access tag, multiple vlans or vlan ranges are configured as a trunk
+func (f *nadFn) setOvsConfig(nad *nadlibv1.NadStruct, bridgeName string, vlanIDs []int, vlanRanges []nadlibv1.VlanTrunk) error {
+	if err := nad.SetOvsBridge(bridgeName); err != nil {
+		return err
+	}
+	if len(vlanRanges) != 0 {
+		trunk := []nadlibv1.VlanTrunk{}
+		for _, vlanID := range vlanIDs {
+			trunk = append(trunk, nadlibv1.VlanTrunk{ID: vlanID})
+		}
+		return nad.SetVlanTrunk(append(trunk, vlanRanges...))
+	}
+	switch len(vlanIDs) {
+	case 0:
+		return nil
+	case 1:
+		return nad.SetBridgeVlan(vlanIDs[0])
+	default:
+		return nad.SetOvsTrunk(vlanIDs)
+	}
 }
 
 func (f *nadFn) IsCNITypePresent(itfceCNIType nephioreqv1alpha1.CNIType) bool {
-	for _, cni := range f.workloadCluster.Spec.CNIs {
+	for _, cni := range f.getCNITypes() {
 		if nephioreqv1alpha1.CNIType(cni) == itfceCNIType {
 			return true
 		}
@@ -310,22 +811,13 @@ func containsAddress(s []nadlibv1.Address, e string) bool {
 	return false
 }
 
-func containsDestination(s []nadlibv1.Route, e string) bool {
+// containsRoute checks if a route to the destination exists in the routing table
+// the same destination is allowed in different routing tables
+func containsRoute(s []nadlibv1.Route, dst string, table int) bool {
 	for _, a := range s {
-		if a.Destination == e {
+		if a.Destination == dst && a.Table == table {
 			return true
 		}
 	}
 	return false
 }
-
-func getForName(annotations map[string]string) string {
-	// forName is the resource that is the root resource of the specialization
-	// e.g. UPFDeployment, SMFDeployment, AMFDeployment
-	forFullName := annotations[condkptsdk.SpecializerOwner]
-	if owner, ok := annotations[condkptsdk.SpecializerFor]; ok {
-		forFullName = owner
-	}
-	split := strings.Split(forFullName, ".")
-	return split[len(split)-1]
-}
@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	nadlibv1 "github.com/nephio-project/nephio/krm-functions/lib/nad/v1"
+	"github.com/nephio-project/nephio/krm-functions/lib/results"
+	"github.com/nokia/k8s-ipam/pkg/iputil"
+)
+
+// networkExt captures the optional attributes of a Network resource that the
+// nad fn uses to render the NAD, but which are not part of the infra api
+type networkExt struct {
+	Spec networkExtSpec `json:"spec,omitempty"`
+}
+
+type networkExtSpec struct {
+	RoutingTables []routingTableExt `json:"routingTables,omitempty"`
+	// DNS defines the resolver configuration for pods attached to this network
+	DNS *nadlibv1.DNS `json:"dns,omitempty"`
+}
+
+type routingTableExt struct {
+	// Name defines the name of the routing table
+	Name string `json:"name"`
+	// Metric defines the metric of the routes generated from this routing table
+	Metric int `json:"metric,omitempty"`
+	// Table defines the routing table id the routes are installed in inside the pod
+	Table int `json:"table,omitempty"`
+	// Prefixes defines optional per prefix attributes of the routing table
+	Prefixes []prefixExt `json:"prefixes,omitempty"`
+}
+
+type prefixExt struct {
+	// Prefix defines the prefix the attributes apply to
+	Prefix string `json:"prefix"`
+	// Gateway defines the next hop of the route to this prefix, overriding the gateway of the ip claim
+	Gateway string `json:"gateway,omitempty"`
+	// OnLink defines the prefix is reachable directly on the interface, the route has no gateway and a link scope
+	OnLink bool `json:"onLink,omitempty"`
+}
+
+// vlanClaimStatusExt captures the status of a VLANClaim resource that the nad fn uses to render
+// the NAD, including the optional inner (C-VLAN) tag which is not part of the vlan api
+type vlanClaimStatusExt struct {
+	// VLANID defines the vlan ID, claimed through the VLAN backend
+	VLANID int `json:"vlanID,omitempty"`
+	// InnerVLANID defines the optional inner vlan ID of a double tagged interface
+	InnerVLANID int `json:"innerVlanID,omitempty"`
+	// VLANRange defines the vlan range of a trunk as start:end, claimed through the VLAN backend
+	VLANRange string `json:"vlanRange,omitempty"`
+}
+
+// getVlanTrunk returns the trunk of the vlan range of the status
+func (r vlanClaimStatusExt) getVlanTrunk() (nadlibv1.VlanTrunk, error) {
+	split := strings.Split(r.VLANRange, ":")
+	if len(split) != 2 {
+		return nadlibv1.VlanTrunk{}, fmt.Errorf("invalid vlan range %s, expected start:end", r.VLANRange)
+	}
+	start, err := strconv.Atoi(split[0])
+	if err != nil {
+		return nadlibv1.VlanTrunk{}, fmt.Errorf("invalid vlan range %s: %s", r.VLANRange, err.Error())
+	}
+	end, err := strconv.Atoi(split[1])
+	if err != nil {
+		return nadlibv1.VlanTrunk{}, fmt.Errorf("invalid vlan range %s: %s", r.VLANRange, err.Error())
+	}
+	return nadlibv1.VlanTrunk{MinID: start, MaxID: end}, nil
+}
+
+// getRoutingTableExt returns the optional attributes of the routing table with
+// the given name in the network with the given name
+func (f *nadFn) getRoutingTableExt(networkName, routingTableName string) routingTableExt {
+	for _, rt := range f.networkExts[networkName].Spec.RoutingTables {
+		if rt.Name == routingTableName {
+			return rt
+		}
+	}
+	return routingTableExt{Name: routingTableName}
+}
+
+// getGateway returns the gateway to use for the route towards the given prefix
+// A gateway defined on the prefix of the routing table takes precedence over the
+// claim gateway, provided it belongs to the same address family as the prefix
+func (r routingTableExt) getGateway(prefix, claimGateway string) (string, error) {
+	for _, p := range r.Prefixes {
+		if p.Prefix != prefix || p.Gateway == "" {
+			continue
+		}
+		pi, err := iputil.New(prefix)
+		if err != nil {
+			return "", err
+		}
+		gw, err := netip.ParseAddr(p.Gateway)
+		if err != nil {
+			return "", fmt.Errorf("invalid gateway %s for prefix %s in routing table %s: %s", p.Gateway, prefix, r.Name, err.Error())
+		}
+		if gw.Is4() != pi.IsIpv4() {
+			return "", fmt.Errorf("gateway %s does not match the address family of prefix %s in routing table %s", p.Gateway, prefix, r.Name)
+		}
+		return p.Gateway, nil
+	}
+	return claimGateway, nil
+}
+
+// isOnLink returns true when the prefix of the routing table is reachable directly on the interface
+func (r routingTableExt) isOnLink(prefix string) bool {
+	for _, p := range r.Prefixes {
+		if p.Prefix == prefix {
+			return p.OnLink
+		}
+	}
+	return false
+}
+
+// getMasterInterfaceName returns the master interface for the requested network purpose and node pool
+// The first master interface of the workload cluster matching all requested keys is selected; without
+// a requested purpose or node pool the default master interface of the workload cluster is used
+func (f *nadFn) getMasterInterfaceName(purpose, nodePool string) (string, error) {
+	if purpose == "" && nodePool == "" {
+		return *f.workloadCluster.Spec.MasterInterface, nil // since we validated the workload cluster before it is safe to do this
+	}
+	available := []string{}
+	for _, mi := range f.workloadClusterExt.Spec.MasterInterfaces {
+		if (purpose == "" || mi.Purpose == purpose) && (nodePool == "" || mi.NodePool == nodePool) {
+			return mi.Name, nil
+		}
+		available = append(available, fmt.Sprintf("%s (purpose: %q, nodePool: %q)", mi.Name, mi.Purpose, mi.NodePool))
+	}
+	return "", results.Errorf(results.CodeUnsupportedByCluster, "no master interface found in workload cluster %s for purpose: %q, nodePool: %q; master interfaces: %v", f.workloadCluster.Spec.ClusterName, purpose, nodePool, available)
+}
+
+// getDNS returns the resolver configuration for the routing table with the given name
+// The dns configuration of the network takes precedence over the one of the workload cluster
+func (f *nadFn) getDNS(routingTableName string) *nadlibv1.DNS {
+	for _, networkObj := range f.networkObjs {
+		dns := f.networkExts[networkObj.GetName()].Spec.DNS
+		if dns == nil {
+			continue
+		}
+		for _, rt := range networkObj.Spec.RoutingTables {
+			if rt.Name == routingTableName {
+				return dns
+			}
+		}
+	}
+	return f.workloadClusterExt.Spec.DNS
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	nephiodeployv1alpha1 "github.com/nephio-project/api/nf_deployments/v1alpha1"
+	bgpfn "github.com/nephio-project/nephio/krm-functions/bgp-fn/fn"
+	capacityfn "github.com/nephio-project/nephio/krm-functions/capacity-fn/fn"
+	configinjectfn "github.com/nephio-project/nephio/krm-functions/configinject-fn/fn"
+	dnnfn "github.com/nephio-project/nephio/krm-functions/dnn-fn/fn"
+	dnsrecordfn "github.com/nephio-project/nephio/krm-functions/dnsrecord-fn/fn"
+	genconfigmapfn "github.com/nephio-project/nephio/krm-functions/gen-configmap-fn/fn"
+	interfacefn "github.com/nephio-project/nephio/krm-functions/interface-fn/fn"
+	ipamfn "github.com/nephio-project/nephio/krm-functions/ipam-fn/fn"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	nadfn "github.com/nephio-project/nephio/krm-functions/nad-fn/fn"
+	networkpolicyfn "github.com/nephio-project/nephio/krm-functions/networkpolicy-fn/fn"
+	nfdeployfn "github.com/nephio-project/nephio/krm-functions/nfdeploy-fn/common"
+	nodepoolfn "github.com/nephio-project/nephio/krm-functions/nodepool-fn/fn"
+	packagemetadatafn "github.com/nephio-project/nephio/krm-functions/package-metadata-fn/fn"
+	qosfn "github.com/nephio-project/nephio/krm-functions/qos-fn/fn"
+	secretinjectionfn "github.com/nephio-project/nephio/krm-functions/secret-injection-fn/fn"
+	sriovoperatorfn "github.com/nephio-project/nephio/krm-functions/sriov-operator-fn/fn"
+	ueransimdeployfn "github.com/nephio-project/nephio/krm-functions/ueransim-deploy-fn/fn"
+	vlanfn "github.com/nephio-project/nephio/krm-functions/vlan-fn/fn"
+	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy/ipam"
+	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy/vlan"
+)
+
+// FunctionKey is the field of the function config selecting the comma separated functions to
+// run, at the top level of the function config or in the data of a ConfigMap
+const FunctionKey = "function"
+
+// Functions are the bundled functions keyed by the name of their image, a function is created per
+// run as some functions keep state across the callbacks of a run
+var Functions = map[string]func() fn.ResourceListProcessorFunc{
+	"amf-deploy-fn": func() fn.ResourceListProcessorFunc {
+		return func(rl *fn.ResourceList) (bool, error) {
+			return nfdeployfn.Run[nephiodeployv1alpha1.AMFDeployment](rl, nephiodeployv1alpha1.AMFDeploymentGroupVersionKind)
+		}
+	},
+	"bgp-fn":      func() fn.ResourceListProcessorFunc { return bgpfn.Run },
+	"capacity-fn": func() fn.ResourceListProcessorFunc { return capacityfn.Run },
+	"configinject-fn": func() fn.ResourceListProcessorFunc {
+		return (&configinjectfn.FnR{Client: nil}).Run
+	},
+	"dnn-fn":           func() fn.ResourceListProcessorFunc { return dnnfn.Run },
+	"dnsrecord-fn":     func() fn.ResourceListProcessorFunc { return dnsrecordfn.Run },
+	"gen-configmap-fn": func() fn.ResourceListProcessorFunc { return genconfigmapfn.Process },
+	"interface-fn":     func() fn.ResourceListProcessorFunc { return interfacefn.Run },
+	"ipam-fn":          func() fn.ResourceListProcessorFunc { return ipamfn.New(ipam.NewMock()).Run },
+	"nad-fn":           func() fn.ResourceListProcessorFunc { return nadfn.Run },
+	"networkpolicy-fn": func() fn.ResourceListProcessorFunc { return networkpolicyfn.Run },
+	"nodepool-fn":      func() fn.ResourceListProcessorFunc { return nodepoolfn.Run },
+	"package-metadata-fn": func() fn.ResourceListProcessorFunc {
+		return packagemetadatafn.Run
+	},
+	"qos-fn": func() fn.ResourceListProcessorFunc { return qosfn.Run },
+	"secret-injection-fn": func() fn.ResourceListProcessorFunc {
+		return secretinjectionfn.Run
+	},
+	"smf-deploy-fn": func() fn.ResourceListProcessorFunc {
+		return func(rl *fn.ResourceList) (bool, error) {
+			return nfdeployfn.Run[nephiodeployv1alpha1.SMFDeployment](rl, nephiodeployv1alpha1.SMFDeploymentGroupVersionKind)
+		}
+	},
+	"sriov-operator-fn":  func() fn.ResourceListProcessorFunc { return sriovoperatorfn.Run },
+	"ueransim-deploy-fn": func() fn.ResourceListProcessorFunc { return ueransimdeployfn.Run },
+	"upf-deploy-fn": func() fn.ResourceListProcessorFunc {
+		return func(rl *fn.ResourceList) (bool, error) {
+			return nfdeployfn.Run[nephiodeployv1alpha1.UPFDeployment](rl, nephiodeployv1alpha1.UPFDeploymentGroupVersionKind)
+		}
+	},
+	"vlan-fn": func() fn.ResourceListProcessorFunc { return vlanfn.New(vlan.NewMock()).Run },
+}
+
+// Router dispatches a ResourceList to the functions selected by the function config, or else to the
+// function named like the invoked path, e.g. a /usr/local/bin/interface-fn link to the binary
+type Router struct {
+	invoked string
+}
+
+// NewRouter returns the router of the binary invoked with the path
+func NewRouter(invokedPath string) *Router {
+	return &Router{invoked: filepath.Base(invokedPath)}
+}
+
+// Process runs the selected functions in order on the ResourceList, the run stops at the first
+// function failing
+func (r *Router) Process(rl *fn.ResourceList) (bool, error) {
+	defer ko.StartRender()()
+	names, err := r.getFunctionNames(rl.FunctionConfig)
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	if err := removeFunctionKey(rl.FunctionConfig); err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	for _, name := range names {
+		ok, err := Functions[name]()(rl)
+		if err != nil {
+			return false, fmt.Errorf("%s: %s", name, err.Error())
+		}
+		if !ok || rl.Results.ExitCode() != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// getFunctionNames returns the functions selected by the function config or the invoked path
+func (r *Router) getFunctionNames(fc *fn.KubeObject) ([]string, error) {
+	s := getFunctionKey(fc)
+	if s == "" {
+		if _, ok := Functions[r.invoked]; ok {
+			return []string{r.invoked}, nil
+		}
+		return nil, fmt.Errorf("no function selected, set the %s field of the function config or invoke one of: [%s]", FunctionKey, strings.Join(functionNames(), ", "))
+	}
+	names := []string{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := Functions[name]; !ok {
+			return nil, fmt.Errorf("function %s not supported, supported functions: [%s]", name, strings.Join(functionNames(), ", "))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func getFunctionKey(fc *fn.KubeObject) string {
+	if fc == nil {
+		return ""
+	}
+	if s, ok, _ := fc.NestedString(FunctionKey); ok && s != "" {
+		return s
+	}
+	if fc.GetKind() == "ConfigMap" {
+		s, _, _ := fc.NestedString("data", FunctionKey)
+		return s
+	}
+	return ""
+}
+
+// removeFunctionKey removes the routing from the function config, such that the functions see
+// their own config only
+func removeFunctionKey(fc *fn.KubeObject) error {
+	if fc == nil {
+		return nil
+	}
+	if _, err := fc.RemoveNestedField(FunctionKey); err != nil {
+		return err
+	}
+	if fc.GetKind() == "ConfigMap" {
+		if _, err := fc.RemoveNestedField("data", FunctionKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func functionNames() []string {
+	names := make([]string, 0, len(Functions))
+	for name := range Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
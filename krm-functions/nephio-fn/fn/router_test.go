@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The Nephio Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fn
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFunctionNames(t *testing.T) {
+	cases := map[string]struct {
+		invoked     string
+		input       string
+		want        []string
+		errExpected bool
+	}{
+		"Field": {
+			input: `
+apiVersion: fn.kpt.dev/v1alpha1
+kind: Specializer
+metadata:
+  name: test
+function: interface-fn`,
+			want: []string{"interface-fn"},
+		},
+		"ConfigMapSequence": {
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test
+data:
+  function: interface-fn, nad-fn,dnn-fn`,
+			want: []string{"interface-fn", "nad-fn", "dnn-fn"},
+		},
+		"FieldOverridesInvokedPath": {
+			invoked: "/usr/local/bin/nad-fn",
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test
+data:
+  function: dnn-fn`,
+			want: []string{"dnn-fn"},
+		},
+		"InvokedPath": {
+			invoked: "/usr/local/bin/nad-fn",
+			want:    []string{"nad-fn"},
+		},
+		"Unsupported": {
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test
+data:
+  function: interface-fn,foo-fn`,
+			errExpected: true,
+		},
+		"NoFunction": {
+			invoked:     "/usr/local/bin/function",
+			errExpected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var fc *fn.KubeObject
+			if tc.input != "" {
+				var err error
+				fc, err = fn.ParseKubeObject([]byte(tc.input))
+				assert.NoError(t, err)
+			}
+			got, err := NewRouter(tc.invoked).getFunctionNames(fc)
+			if tc.errExpected {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRemoveFunctionKey(t *testing.T) {
+	cases := map[string]struct {
+		input string
+		want  string
+	}{
+		"Field": {
+			input: `
+apiVersion: fn.kpt.dev/v1alpha1
+kind: Specializer
+metadata:
+  name: test
+function: interface-fn
+spec:
+  foo: bar`,
+			want: `apiVersion: fn.kpt.dev/v1alpha1
+kind: Specializer
+metadata:
+  name: test
+spec:
+  foo: bar
+`,
+		},
+		"ConfigMap": {
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test
+data:
+  function: interface-fn
+  cniPreference: sriov,macvlan`,
+			want: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test
+data:
+  cniPreference: sriov,macvlan
+`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fc, err := fn.ParseKubeObject([]byte(tc.input))
+			assert.NoError(t, err)
+			assert.NoError(t, removeFunctionKey(fc))
+			assert.Equal(t, tc.want, fc.String())
+		})
+	}
+}
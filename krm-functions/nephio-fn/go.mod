@@ -0,0 +1,127 @@
+module github.com/nephio-project/nephio/krm-functions/nephio-fn
+
+go 1.20
+
+replace (
+	github.com/nephio-project/nephio/krm-functions/bgp-fn => ../bgp-fn
+	github.com/nephio-project/nephio/krm-functions/capacity-fn => ../capacity-fn
+	github.com/nephio-project/nephio/krm-functions/configinject-fn => ../configinject-fn
+	github.com/nephio-project/nephio/krm-functions/dnn-fn => ../dnn-fn
+	github.com/nephio-project/nephio/krm-functions/dnsrecord-fn => ../dnsrecord-fn
+	github.com/nephio-project/nephio/krm-functions/gen-configmap-fn => ../gen-configmap-fn
+	github.com/nephio-project/nephio/krm-functions/interface-fn => ../interface-fn
+	github.com/nephio-project/nephio/krm-functions/ipam-fn => ../ipam-fn
+	github.com/nephio-project/nephio/krm-functions/lib => ../lib
+	github.com/nephio-project/nephio/krm-functions/nad-fn => ../nad-fn
+	github.com/nephio-project/nephio/krm-functions/networkpolicy-fn => ../networkpolicy-fn
+	github.com/nephio-project/nephio/krm-functions/nfdeploy-fn => ../nfdeploy-fn
+	github.com/nephio-project/nephio/krm-functions/nodepool-fn => ../nodepool-fn
+	github.com/nephio-project/nephio/krm-functions/package-metadata-fn => ../package-metadata-fn
+	github.com/nephio-project/nephio/krm-functions/qos-fn => ../qos-fn
+	github.com/nephio-project/nephio/krm-functions/secret-injection-fn => ../secret-injection-fn
+	github.com/nephio-project/nephio/krm-functions/sriov-operator-fn => ../sriov-operator-fn
+	github.com/nephio-project/nephio/krm-functions/ueransim-deploy-fn => ../ueransim-deploy-fn
+	github.com/nephio-project/nephio/krm-functions/vlan-fn => ../vlan-fn
+)
+
+require (
+	github.com/GoogleContainerTools/kpt-functions-sdk/go/fn v0.0.0-20230427202446-3255accc518d
+	github.com/nephio-project/api v0.0.0-20230627152656-a2bf013a68da
+	github.com/nephio-project/nephio/krm-functions/bgp-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/capacity-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/configinject-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/dnn-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/dnsrecord-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/gen-configmap-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/interface-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/ipam-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/lib v0.0.0-20230627172556-8b92b80484c8
+	github.com/nephio-project/nephio/krm-functions/nad-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/networkpolicy-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/nfdeploy-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/nodepool-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/package-metadata-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/qos-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/secret-injection-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/sriov-operator-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/ueransim-deploy-fn v0.0.0-00010101000000-000000000000
+	github.com/nephio-project/nephio/krm-functions/vlan-fn v0.0.0-00010101000000-000000000000
+	github.com/nokia/k8s-ipam v0.0.4-0.20230628092530-8a292aec80a4
+	github.com/stretchr/testify v1.8.2
+)
+
+require (
+	google.golang.org/appengine v1.6.7 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230526203410-71b5a4ffd15e // indirect
+)
+
+require (
+	github.com/GoogleContainerTools/kpt v1.0.0-beta.29.0.20230327202912-01513604feaa // indirect
+	github.com/GoogleContainerTools/kpt-functions-sdk/go/api v0.0.0-20230427202446-3255accc518d // indirect
+	github.com/GoogleContainerTools/kpt/porch/api v0.0.0-20230608012444-ee7c8cf378e9 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/emicklei/go-restful/v3 v3.10.2 // indirect
+	github.com/evanphx/json-patch/v5 v5.6.0 // indirect
+	github.com/fsnotify/fsnotify v1.6.0 // indirect
+	github.com/go-errors/errors v1.4.2 // indirect
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-openapi/jsonpointer v0.19.6 // indirect
+	github.com/go-openapi/jsonreference v0.20.2 // indirect
+	github.com/go-openapi/swag v0.22.3 // indirect
+	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/google/gnostic v0.6.9 // indirect
+	github.com/google/go-cmp v0.5.9 // indirect
+	github.com/google/gofuzz v1.2.0 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/hansthienpondt/nipam v0.0.5 // indirect
+	github.com/imdario/mergo v0.3.16 // indirect
+	github.com/josharian/intern v1.0.0 // indirect
+	github.com/json-iterator/go v1.1.12 // indirect
+	github.com/k8snetworkplumbingwg/network-attachment-definition-client v1.4.0 // indirect
+	github.com/kentik/patricia v1.2.0 // indirect
+	github.com/mailru/easyjson v0.7.7 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.4 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	github.com/monochromegane/go-gitignore v0.0.0-20200626010858-205db1a8cc00 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/prometheus/client_golang v1.15.1 // indirect
+	github.com/prometheus/client_model v0.4.0 // indirect
+	github.com/prometheus/common v0.44.0 // indirect
+	github.com/prometheus/procfs v0.10.1 // indirect
+	github.com/spf13/pflag v1.0.5 // indirect
+	github.com/xlab/treeprint v1.2.0 // indirect
+	go4.org/netipx v0.0.0-20230303233057-f1b76eb4bb35 // indirect
+	golang.org/x/net v0.10.0 // indirect
+	golang.org/x/oauth2 v0.8.0 // indirect
+	golang.org/x/sys v0.8.0 // indirect
+	golang.org/x/term v0.8.0 // indirect
+	golang.org/x/text v0.9.0 // indirect
+	golang.org/x/time v0.3.0 // indirect
+	gomodules.xyz/jsonpatch/v2 v2.3.0 // indirect
+	google.golang.org/grpc v1.55.0 // indirect
+	google.golang.org/protobuf v1.30.0 // indirect
+	gopkg.in/inf.v0 v0.9.1 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+	k8s.io/api v0.27.3 // indirect
+	k8s.io/apiextensions-apiserver v0.27.2 // indirect
+	k8s.io/apimachinery v0.27.3 // indirect
+	k8s.io/client-go v0.27.2 // indirect
+	k8s.io/component-base v0.27.2 // indirect
+	k8s.io/klog/v2 v2.100.1 // indirect
+	k8s.io/kube-openapi v0.0.0-20230525220651-2546d827e515 // indirect
+	k8s.io/utils v0.0.0-20230505201702-9f6742963106 // indirect
+	sigs.k8s.io/controller-runtime v0.15.0 // indirect
+	sigs.k8s.io/json v0.0.0-20221116044647-bc3834ca7abd // indirect
+	sigs.k8s.io/kustomize/api v0.13.3 // indirect
+	sigs.k8s.io/kustomize/kyaml v0.14.2 // indirect
+	sigs.k8s.io/structured-merge-diff/v4 v4.2.3 // indirect
+	sigs.k8s.io/yaml v1.3.0 // indirect
+)
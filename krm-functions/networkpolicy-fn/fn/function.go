@@ -0,0 +1,256 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nephio-project/nephio/krm-functions/lib/condkptsdk"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultPODNetwork = "default"
+	// networkInstancesAnnotation lists the additional network instances of an interface, comma separated
+	networkInstancesAnnotation = "nephio.org/network-instances"
+	// podSelectorKey is the key in the data of the function config ConfigMap that defines the label
+	// selector of the NF pods the policies apply to, all the pods of the namespace by default
+	podSelectorKey = "podSelector"
+	// primaryNetworkKey is the key in the data of the function config ConfigMap that enables the
+	// NetworkPolicies of the interfaces attached to the default pod network
+	primaryNetworkKey = "primaryNetwork"
+
+	multiNetworkPolicyAPIVersion = "k8s.cni.cncf.io/v1beta1"
+	multiNetworkPolicyKind       = "MultiNetworkPolicy"
+	// policyForAnnotation defines the NADs a MultiNetworkPolicy applies to
+	policyForAnnotation = "k8s.v1.cni.cncf.io/policy-for"
+)
+
+// interfaceOwnerPrefix prefixes the owner annotation of the policies with the name of their Interface
+var interfaceOwnerPrefix = fmt.Sprintf("%s.%s.", nephioreqv1alpha1.GroupVersion.Identifier(), nephioreqv1alpha1.InterfaceKind)
+
+type config struct {
+	podSelector    metav1.LabelSelector
+	primaryNetwork bool
+}
+
+// Run is the entry point of the KRM function (called by the upstream fn SDK)
+// it restricts the traffic of the interfaces of the NF to the prefixes of the routing
+// tables of their network instances in the Network resources of the package
+func Run(rl *fn.ResourceList) (bool, error) {
+	cfg, err := getConfig(rl.FunctionConfig)
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	prefixes, err := getRoutingTablePrefixes(rl.Items)
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+
+	// the policies are regenerated, as such the policies of deleted interfaces are removed
+	items := fn.KubeObjects{}
+	for _, o := range rl.Items {
+		if !isPolicy(o) || !strings.HasPrefix(o.GetAnnotation(condkptsdk.SpecializerOwner), interfaceOwnerPrefix) {
+			items = append(items, o)
+		}
+	}
+	policies := fn.KubeObjects{}
+	for _, o := range items.Where(fn.IsGroupVersionKind(nephioreqv1alpha1.InterfaceGroupVersionKind)) {
+		itfce, err := ko.KubeObjectToStruct[nephioreqv1alpha1.Interface](o)
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		if itfce.Spec.NetworkInstance == nil || itfce.Spec.CNIType == "" && itfce.Spec.NetworkInstance.Name != defaultPODNetwork {
+			// a loopback interface carries no traffic of the pod
+			continue
+		}
+		if itfce.Spec.AttachmentType == nephioreqv1alpha1.AttachmentTypeNone {
+			// an internal-only interface is not attached to the pod
+			continue
+		}
+		primary := itfce.Spec.NetworkInstance.Name == defaultPODNetwork
+		if primary && !cfg.primaryNetwork {
+			continue
+		}
+		nis := append([]string{itfce.Spec.NetworkInstance.Name}, getAdditionalNetworkInstances(o.GetAnnotation(networkInstancesAnnotation), itfce.Spec.NetworkInstance.Name)...)
+		peers := []networkingv1.NetworkPolicyPeer{}
+		for _, ni := range nis {
+			rtPrefixes, ok := prefixes[ni]
+			if !ok {
+				rl.Results.Warningf("no routing table %s in the Network resources of the kpt package, the traffic of interface %s with it is not allowed", ni, itfce.GetName())
+				continue
+			}
+			for _, p := range rtPrefixes {
+				peers = append(peers, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: p}})
+			}
+		}
+		if len(peers) == 0 {
+			rl.Results.Warningf("no prefixes found for interface %s, no policy is generated", itfce.GetName())
+			continue
+		}
+		policy, err := getPolicy(o, cfg.podSelector, peers, primary)
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		policies = append(policies, policy)
+	}
+	rl.Items = append(items, policies...)
+	return true, nil
+}
+
+func getConfig(fc *fn.KubeObject) (*config, error) {
+	cfg := &config{}
+	if fc == nil {
+		return cfg, nil
+	}
+	if s, ok, _ := fc.NestedString("data", podSelectorKey); ok && s != "" {
+		selector, err := metav1.ParseToLabelSelector(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %s: %s", podSelectorKey, s, err.Error())
+		}
+		cfg.podSelector = *selector
+	}
+	if s, ok, _ := fc.NestedString("data", primaryNetworkKey); ok {
+		cfg.primaryNetwork = s == "true"
+	}
+	return cfg, nil
+}
+
+// getRoutingTablePrefixes returns the prefixes of the routing tables of the Network resources per routing table
+func getRoutingTablePrefixes(objs fn.KubeObjects) (map[string][]string, error) {
+	prefixes := map[string][]string{}
+	for _, o := range objs.Where(ko.IsGroupVersionKind(infrav1alpha1.NetworkGroupVersionKind)) {
+		network, err := ko.KubeObjectToStruct[infrav1alpha1.Network](o)
+		if err != nil {
+			return nil, err
+		}
+		for _, rt := range network.Spec.RoutingTables {
+			for _, p := range rt.Prefixes {
+				pi, err := netip.ParsePrefix(p.Prefix)
+				if err != nil {
+					return nil, fmt.Errorf("invalid prefix %s in routing table %s of Network %s: %s", p.Prefix, rt.Name, o.GetName(), err.Error())
+				}
+				prefixes[rt.Name] = append(prefixes[rt.Name], pi.Masked().String())
+			}
+		}
+	}
+	for rt := range prefixes {
+		sort.Strings(prefixes[rt])
+	}
+	return prefixes, nil
+}
+
+// getPolicy returns the policy of the interface allowing the traffic from and to the peers, a
+// NetworkPolicy for an interface of the default pod network and a MultiNetworkPolicy applying to
+// the NAD of the interface for a secondary network
+func getPolicy(o *fn.KubeObject, podSelector metav1.LabelSelector, peers []networkingv1.NetworkPolicyPeer, primary bool) (*fn.KubeObject, error) {
+	name := fmt.Sprintf("%s-%s", getForName(o.GetAnnotations()), o.GetName())
+	meta := metav1.ObjectMeta{
+		Name: name,
+		Annotations: map[string]string{
+			condkptsdk.SpecializerOwner: interfaceOwnerPrefix + o.GetName(),
+		},
+	}
+	spec := networkingv1.NetworkPolicySpec{
+		PodSelector: podSelector,
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		Ingress:     []networkingv1.NetworkPolicyIngressRule{{From: peers}},
+		Egress:      []networkingv1.NetworkPolicyEgressRule{{To: peers}},
+	}
+	if primary {
+		o, err := fn.NewFromTypedObject(&networkingv1.NetworkPolicy{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: networkingv1.SchemeGroupVersion.Identifier(),
+				Kind:       "NetworkPolicy",
+			},
+			ObjectMeta: meta,
+			Spec:       spec,
+		})
+		if err != nil {
+			return nil, err
+		}
+		_, err = o.RemoveNestedField("status")
+		return o, err
+	}
+	// the NAD of the interface is named after the interface by the interface fn
+	meta.Annotations[policyForAnnotation] = name
+	return fn.NewFromTypedObject(&multiNetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: multiNetworkPolicyAPIVersion,
+			Kind:       multiNetworkPolicyKind,
+		},
+		ObjectMeta: meta,
+		Spec:       spec,
+	})
+}
+
+// multiNetworkPolicy is the NetworkPolicy of the secondary networks of the multi-networkpolicy project
+type multiNetworkPolicy struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Spec              networkingv1.NetworkPolicySpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+func isPolicy(o *fn.KubeObject) bool {
+	return o.IsGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy")) ||
+		(o.GetAPIVersion() == multiNetworkPolicyAPIVersion && o.GetKind() == multiNetworkPolicyKind)
+}
+
+func getForName(annotations map[string]string) string {
+	// forName is the resource that is the root resource of the specialization
+	// e.g. UPFDeployment, SMFDeployment, AMFDeployment
+	forFullName := annotations[condkptsdk.SpecializerOwner]
+	if owner, ok := annotations[condkptsdk.SpecializerFor]; ok {
+		forFullName = owner
+	}
+	split := strings.Split(forFullName, ".")
+	return split[len(split)-1]
+}
+
+// getAdditionalNetworkInstances returns the network instances of the annotation
+// excluding the network instance of the interface and duplicates
+func getAdditionalNetworkInstances(annotation, networkInstance string) []string {
+	nis := []string{}
+	for _, ni := range strings.Split(annotation, ",") {
+		ni = strings.TrimSpace(ni)
+		if ni == "" || ni == networkInstance || ni == defaultPODNetwork {
+			continue
+		}
+		found := false
+		for _, n := range nis {
+			if n == ni {
+				found = true
+			}
+		}
+		if !found {
+			nis = append(nis, ni)
+		}
+	}
+	return nis
+}
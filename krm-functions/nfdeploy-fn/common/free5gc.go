@@ -0,0 +1,119 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package common
+
+import (
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	nephiodeployv1alpha1 "github.com/nephio-project/api/nf_deployments/v1alpha1"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// free5gcSchema is the schema of the CRs of the free5gc operator
+const free5gcSchema = "free5gc"
+
+// Free5GCGroupVersion is the GroupVersion of the CRs of the free5gc operator, the kind of a CR is
+// the NF type, e.g. UPF
+var Free5GCGroupVersion = schema.GroupVersion{Group: "nf.free5gc.org", Version: "v1alpha1"}
+
+// Free5GCNF is the CR of an NF of the free5gc operator
+type Free5GCNF struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Spec              Free5GCNFSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+type Free5GCNFSpec struct {
+	Capacity   *nephioreqv1alpha1.CapacitySpec `json:"capacity,omitempty" yaml:"capacity,omitempty"`
+	Interfaces []Free5GCInterface              `json:"interfaces,omitempty" yaml:"interfaces,omitempty"`
+	DNNList    []Free5GCDNN                    `json:"dnnList,omitempty" yaml:"dnnList,omitempty"`
+	ConfigRefs []corev1.ObjectReference        `json:"configRefs,omitempty" yaml:"configRefs,omitempty"`
+}
+
+type Free5GCInterface struct {
+	// Name of the interface in the free5gc config, e.g. N3
+	Name            string  `json:"name" yaml:"name"`
+	NetworkInstance string  `json:"networkInstance,omitempty" yaml:"networkInstance,omitempty"`
+	IPv4Address     string  `json:"ipv4Address,omitempty" yaml:"ipv4Address,omitempty"`
+	IPv4Gateway     *string `json:"ipv4Gateway,omitempty" yaml:"ipv4Gateway,omitempty"`
+	IPv6Address     string  `json:"ipv6Address,omitempty" yaml:"ipv6Address,omitempty"`
+	IPv6Gateway     *string `json:"ipv6Gateway,omitempty" yaml:"ipv6Gateway,omitempty"`
+	VLANID          *uint16 `json:"vlanID,omitempty" yaml:"vlanID,omitempty"`
+}
+
+type Free5GCDNN struct {
+	DNN             string   `json:"dnn" yaml:"dnn"`
+	NetworkInstance string   `json:"networkInstance" yaml:"networkInstance"`
+	Pools           []string `json:"pools,omitempty" yaml:"pools,omitempty"`
+}
+
+type free5gcConverter struct{}
+
+func (free5gcConverter) Convert(nf *fn.KubeObject, spec *nephiodeployv1alpha1.NFDeploymentSpec) (*fn.KubeObject, error) {
+	networkInstances := getNetworkInstances(spec)
+	itfces := make([]Free5GCInterface, 0, len(spec.Interfaces))
+	for _, itfce := range spec.Interfaces {
+		i := Free5GCInterface{
+			Name:            strings.ToUpper(itfce.Name),
+			NetworkInstance: networkInstances[itfce.Name],
+			VLANID:          itfce.VLANID,
+		}
+		if itfce.IPv4 != nil {
+			i.IPv4Address = itfce.IPv4.Address
+			i.IPv4Gateway = itfce.IPv4.Gateway
+		}
+		if itfce.IPv6 != nil {
+			i.IPv6Address = itfce.IPv6.Address
+			i.IPv6Gateway = itfce.IPv6.Gateway
+		}
+		itfces = append(itfces, i)
+	}
+	dnns := []Free5GCDNN{}
+	for _, ni := range spec.NetworkInstances {
+		for _, dnn := range ni.DataNetworks {
+			if dnn.Name == nil {
+				continue
+			}
+			d := Free5GCDNN{DNN: *dnn.Name, NetworkInstance: ni.Name}
+			for _, pool := range dnn.Pool {
+				d.Pools = append(d.Pools, pool.Prefix)
+			}
+			dnns = append(dnns, d)
+		}
+	}
+
+	return fn.NewFromTypedObject(&Free5GCNF{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: Free5GCGroupVersion.Identifier(),
+			Kind:       getNFType(nf),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nf.GetName(),
+			Namespace: nf.GetNamespace(),
+		},
+		Spec: Free5GCNFSpec{
+			Capacity:   spec.Capacity,
+			Interfaces: itfces,
+			DNNList:    dnns,
+			ConfigRefs: spec.ConfigRefs,
+		},
+	})
+}
@@ -49,10 +49,10 @@ func Run[T any, PT PtrIsNFDeployment[T]](rl *fn.ResourceList, gvk schema.GroupVe
 	nfDeployFn.sdk, err = condkptsdk.New(
 		rl,
 		&condkptsdk.Config{
-			For: corev1.ObjectReference{
+			For: []corev1.ObjectReference{{
 				APIVersion: nephiodeployv1alpha1.GroupVersion.Identifier(),
 				Kind:       nfDeployFn.gvk.Kind,
-			},
+			}},
 			Owns: map[corev1.ObjectReference]condkptsdk.ResourceKind{
 				{
 					APIVersion: nephioreqv1alpha1.GroupVersion.Identifier(),
@@ -88,7 +88,21 @@ func Run[T any, PT PtrIsNFDeployment[T]](rl *fn.ResourceList, gvk schema.GroupVe
 		return false, err
 	}
 
-	return nfDeployFn.sdk.Run()
+	ok, err := nfDeployFn.sdk.Run()
+	if !ok || err != nil {
+		return ok, err
+	}
+	// the vendor CRs follow the specialized NF deployments
+	if err := nfDeployFn.convertNFDeployments(rl); err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	// the status summary follows the conditions of the package
+	if err := setNFDeploymentStatus(rl, gvk); err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	return true, nil
 }
 
 func (f *NfDeployFn[T, PT]) WorkloadClusterCallbackFn(o *fn.KubeObject) error {
@@ -97,7 +111,7 @@ func (f *NfDeployFn[T, PT]) WorkloadClusterCallbackFn(o *fn.KubeObject) error {
 	if f.workloadCluster != nil {
 		return fmt.Errorf("multiple WorkloadCluster objects found in the kpt package")
 	}
-	f.workloadCluster, err = ko.KubeObjectToStruct[infrav1alpha1.WorkloadCluster](o)
+	f.workloadCluster, err = ko.CachedKubeObjectToStruct[infrav1alpha1.WorkloadCluster](o)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,123 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package common
+
+import (
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	nephiodeployv1alpha1 "github.com/nephio-project/api/nf_deployments/v1alpha1"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nokia/k8s-ipam/pkg/iputil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// oaiSchema is the schema of the CRs of the OAI operator
+const oaiSchema = "oai"
+
+// OAIGroupVersion is the GroupVersion of the CRs of the OAI operator, the kind of a CR is the NF
+// type, e.g. UPF
+var OAIGroupVersion = schema.GroupVersion{Group: "nf.openairinterface.org", Version: "v1alpha1"}
+
+// OAINF is the CR of an NF of the OAI operator
+type OAINF struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Spec              OAINFSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+type OAINFSpec struct {
+	// NFType is the lower case NF type, e.g. upf
+	NFType            string                          `json:"nfType" yaml:"nfType"`
+	Resources         *nephioreqv1alpha1.CapacitySpec `json:"resources,omitempty" yaml:"resources,omitempty"`
+	NetworkInterfaces []OAINetworkInterface           `json:"networkInterfaces,omitempty" yaml:"networkInterfaces,omitempty"`
+	DNNs              []OAIDNN                        `json:"dnns,omitempty" yaml:"dnns,omitempty"`
+	ConfigRefs        []corev1.ObjectReference        `json:"configRefs,omitempty" yaml:"configRefs,omitempty"`
+}
+
+type OAINetworkInterface struct {
+	Name            string                     `json:"name" yaml:"name"`
+	NetworkInstance string                     `json:"networkInstance,omitempty" yaml:"networkInstance,omitempty"`
+	IPv4            *nephiodeployv1alpha1.IPv4 `json:"ipv4,omitempty" yaml:"ipv4,omitempty"`
+	IPv6            *nephiodeployv1alpha1.IPv6 `json:"ipv6,omitempty" yaml:"ipv6,omitempty"`
+	VLAN            *uint16                    `json:"vlan,omitempty" yaml:"vlan,omitempty"`
+}
+
+type OAIDNN struct {
+	DNN             string   `json:"dnn" yaml:"dnn"`
+	NetworkInstance string   `json:"networkInstance" yaml:"networkInstance"`
+	IPv4Subnets     []string `json:"ipv4Subnets,omitempty" yaml:"ipv4Subnets,omitempty"`
+	IPv6Subnets     []string `json:"ipv6Subnets,omitempty" yaml:"ipv6Subnets,omitempty"`
+}
+
+type oaiConverter struct{}
+
+func (oaiConverter) Convert(nf *fn.KubeObject, spec *nephiodeployv1alpha1.NFDeploymentSpec) (*fn.KubeObject, error) {
+	networkInstances := getNetworkInstances(spec)
+	itfces := make([]OAINetworkInterface, 0, len(spec.Interfaces))
+	for _, itfce := range spec.Interfaces {
+		itfces = append(itfces, OAINetworkInterface{
+			Name:            itfce.Name,
+			NetworkInstance: networkInstances[itfce.Name],
+			IPv4:            itfce.IPv4,
+			IPv6:            itfce.IPv6,
+			VLAN:            itfce.VLANID,
+		})
+	}
+	dnns := []OAIDNN{}
+	for _, ni := range spec.NetworkInstances {
+		for _, dnn := range ni.DataNetworks {
+			if dnn.Name == nil {
+				continue
+			}
+			d := OAIDNN{DNN: *dnn.Name, NetworkInstance: ni.Name}
+			for _, pool := range dnn.Pool {
+				pi, err := iputil.New(pool.Prefix)
+				if err != nil {
+					return nil, err
+				}
+				// the OAI config separates the ipv4 and ipv6 subnets of a dnn
+				if pi.IsIpv6() {
+					d.IPv6Subnets = append(d.IPv6Subnets, pool.Prefix)
+				} else {
+					d.IPv4Subnets = append(d.IPv4Subnets, pool.Prefix)
+				}
+			}
+			dnns = append(dnns, d)
+		}
+	}
+
+	return fn.NewFromTypedObject(&OAINF{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: OAIGroupVersion.Identifier(),
+			Kind:       getNFType(nf),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nf.GetName(),
+			Namespace: nf.GetNamespace(),
+		},
+		Spec: OAINFSpec{
+			NFType:            strings.ToLower(getNFType(nf)),
+			Resources:         spec.Capacity,
+			NetworkInterfaces: itfces,
+			DNNs:              dnns,
+			ConfigRefs:        spec.ConfigRefs,
+		},
+	})
+}
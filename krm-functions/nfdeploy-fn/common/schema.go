@@ -0,0 +1,142 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	nephiodeployv1alpha1 "github.com/nephio-project/api/nf_deployments/v1alpha1"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+)
+
+const (
+	// schemaKey is the key in the data of the function config ConfigMap that selects the comma separated
+	// schemas the NF deployments are specialized in, by default only the nephio NFDeployment is specialized
+	schemaKey = "schema"
+	// nephioSchema is the schema of the nephio NFDeployment api
+	nephioSchema = "nephio"
+	// ConvertedFromAnnotation marks the vendor CRs converted from an NF deployment with the name of the NF deployment
+	ConvertedFromAnnotation = "nephio.org/converted-from"
+)
+
+// Converter converts a specialized NF deployment into the CR of the operator of an NF vendor
+type Converter interface {
+	// Convert returns the vendor CR of the NF deployment with the spec
+	Convert(nf *fn.KubeObject, spec *nephiodeployv1alpha1.NFDeploymentSpec) (*fn.KubeObject, error)
+}
+
+// converters holds the converters of the supported vendor schemas
+var converters = map[string]Converter{
+	free5gcSchema: free5gcConverter{},
+	oaiSchema:     oaiConverter{},
+}
+
+// getSchemas returns the schemas selected by the function config
+func getSchemas(fc *fn.KubeObject) ([]string, error) {
+	s := ""
+	if fc != nil {
+		s, _, _ = fc.NestedString("data", schemaKey)
+	}
+	if s == "" {
+		return []string{nephioSchema}, nil
+	}
+	schemas := []string{}
+	for _, schema := range strings.Split(s, ",") {
+		schema = strings.TrimSpace(schema)
+		if _, ok := converters[schema]; !ok && schema != nephioSchema {
+			return nil, fmt.Errorf("schema %s not supported, supported schemas: [%s]", schema, strings.Join(supportedSchemas(), ", "))
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
+
+func supportedSchemas() []string {
+	schemas := []string{nephioSchema}
+	for schema := range converters {
+		schemas = append(schemas, schema)
+	}
+	sort.Strings(schemas[1:])
+	return schemas
+}
+
+// convertNFDeployments replaces the vendor CRs of the package by the conversion of the specialized
+// NF deployments in the schemas of the function config; when the nephio schema is not selected the
+// NF deployments are kept as local config such that only the vendor CRs are deployed
+func (f *NfDeployFn[T, PT]) convertNFDeployments(rl *fn.ResourceList) error {
+	schemas, err := getSchemas(rl.FunctionConfig)
+	if err != nil {
+		return err
+	}
+
+	items := fn.KubeObjects{}
+	for _, o := range rl.Items {
+		if o.GetAnnotation(ConvertedFromAnnotation) == "" {
+			items = append(items, o)
+		}
+	}
+	for _, o := range items.Where(fn.IsGroupVersionKind(f.gvk)) {
+		nf, err := ko.KubeObjectToStruct[T](o)
+		if err != nil {
+			return err
+		}
+		spec := PT(nf).GetNFDeploymentSpec()
+		local := true
+		for _, schema := range schemas {
+			if schema == nephioSchema {
+				local = false
+				continue
+			}
+			cr, err := converters[schema].Convert(o, spec)
+			if err != nil {
+				return fmt.Errorf("cannot convert %s %s to the %s schema: %s", o.GetKind(), o.GetName(), schema, err.Error())
+			}
+			if err := cr.SetAnnotation(ConvertedFromAnnotation, o.GetName()); err != nil {
+				return err
+			}
+			items = append(items, cr)
+			rl.Results.Infof("%s %s converted to %s %s", o.GetKind(), o.GetName(), cr.GetAPIVersion(), cr.GetKind())
+		}
+		if local {
+			if err := o.SetAnnotation(filters.LocalConfigAnnotation, "true"); err != nil {
+				return err
+			}
+		}
+	}
+	rl.Items = items
+	return nil
+}
+
+// getNetworkInstances returns the network instance of the interfaces of the spec
+func getNetworkInstances(spec *nephiodeployv1alpha1.NFDeploymentSpec) map[string]string {
+	networkInstances := map[string]string{}
+	for _, ni := range spec.NetworkInstances {
+		for _, itfce := range ni.Interfaces {
+			networkInstances[itfce] = ni.Name
+		}
+	}
+	return networkInstances
+}
+
+// getNFType returns the NF type of the kind of the NF deployment, e.g. UPF for UPFDeployment
+func getNFType(nf *fn.KubeObject) string {
+	return strings.TrimSuffix(nf.GetKind(), "Deployment")
+}
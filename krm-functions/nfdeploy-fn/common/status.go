@@ -0,0 +1,227 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	nephiodeployv1alpha1 "github.com/nephio-project/api/nf_deployments/v1alpha1"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	kptfilelibv1 "github.com/nephio-project/nephio/krm-functions/lib/kptfile/v1"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+)
+
+const (
+	// NFDeploymentStatusKind is the kind of the status summary of the NF deployments of a package,
+	// it is not part of the nephio api yet
+	NFDeploymentStatusKind = "NFDeploymentStatus"
+
+	ConditionTypeInterfacesReady = "InterfacesReady"
+	ConditionTypeClaimsResolved  = "ClaimsResolved"
+	ConditionTypeConfigInjected  = "ConfigInjected"
+	ConditionTypeReady           = "Ready"
+)
+
+// NFDeploymentStatusGroupVersionKind is the GroupVersionKind of the status summary of the NF deployments
+var NFDeploymentStatusGroupVersionKind = nephiodeployv1alpha1.GroupVersion.WithKind(NFDeploymentStatusKind)
+
+// NFDeploymentStatus summarizes the specialization of the NF deployments of a package, such that
+// the readiness of a package is checked with a single object instead of the conditions of the Kptfile
+type NFDeploymentStatus struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Spec              NFDeploymentStatusSpec   `json:"spec,omitempty" yaml:"spec,omitempty"`
+	Status            NFDeploymentStatusStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+type NFDeploymentStatusSpec struct {
+	// NFDeployments references the NF deployments of the package
+	NFDeployments []corev1.ObjectReference `json:"nfDeployments" yaml:"nfDeployments"`
+}
+
+type NFDeploymentStatusStatus struct {
+	// Ready is true when all the conditions are true
+	Ready bool `json:"ready" yaml:"ready"`
+	// Conditions holds the InterfacesReady, ClaimsResolved, ConfigInjected and Ready conditions
+	Conditions []kptv1.Condition `json:"conditions" yaml:"conditions"`
+}
+
+// setNFDeploymentStatus replaces the status summary of the package by the summary of its current state
+func setNFDeploymentStatus(rl *fn.ResourceList, gvk schema.GroupVersionKind) error {
+	kfko := rl.Items.GetRootKptfile()
+	if kfko == nil {
+		// nothing was specialized
+		return nil
+	}
+	kf := kptfilelibv1.KptFile{Kptfile: kfko}
+
+	items := fn.KubeObjects{}
+	for _, o := range rl.Items {
+		if !o.IsGroupVersionKind(NFDeploymentStatusGroupVersionKind) {
+			items = append(items, o)
+		}
+	}
+	nfDeployments := items.Where(fn.IsGroupVersionKind(gvk))
+	if len(nfDeployments) == 0 {
+		rl.Items = items
+		return nil
+	}
+	refs := make([]corev1.ObjectReference, 0, len(nfDeployments))
+	for _, o := range nfDeployments {
+		refs = append(refs, corev1.ObjectReference{APIVersion: o.GetAPIVersion(), Kind: o.GetKind(), Name: o.GetName()})
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Name < refs[j].Name
+	})
+
+	claimsCondition, err := getClaimsResolvedCondition(items)
+	if err != nil {
+		return err
+	}
+	conditions := []kptv1.Condition{
+		getKptfileCondition(kf, ConditionTypeInterfacesReady, nephioreqv1alpha1.InterfaceKind, "interfaces"),
+		claimsCondition,
+		getKptfileCondition(kf, ConditionTypeConfigInjected, nephioreqv1alpha1.DependencyKind, "dependencies"),
+	}
+	ready := true
+	notReady := []string{}
+	for _, c := range conditions {
+		if c.Status != kptv1.ConditionTrue {
+			ready = false
+			notReady = append(notReady, c.Type)
+		}
+	}
+	readyCondition := kptv1.Condition{Type: ConditionTypeReady, Status: kptv1.ConditionTrue, Reason: "Ready", Message: "nf deployments specialized"}
+	if !ready {
+		readyCondition.Status = kptv1.ConditionFalse
+		readyCondition.Reason = "NotReady"
+		readyCondition.Message = fmt.Sprintf("waiting for %s", strings.Join(notReady, ", "))
+	}
+	conditions = append(conditions, readyCondition)
+
+	o, err := fn.NewFromTypedObject(&NFDeploymentStatus{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: NFDeploymentStatusGroupVersionKind.GroupVersion().Identifier(),
+			Kind:       NFDeploymentStatusKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: kfko.GetName(),
+			Annotations: map[string]string{
+				filters.LocalConfigAnnotation: "true",
+			},
+		},
+		Spec: NFDeploymentStatusSpec{NFDeployments: refs},
+		Status: NFDeploymentStatusStatus{
+			Ready:      ready,
+			Conditions: conditions,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	rl.Items = append(items, o)
+	return nil
+}
+
+// getKptfileCondition aggregates the conditions of the Kptfile of the resources of the kind
+func getKptfileCondition(kf kptfilelibv1.KptFile, ct, kind, resources string) kptv1.Condition {
+	total := 0
+	pending := []string{}
+	for _, c := range kf.GetConditions() {
+		ref := kptfilelibv1.GetGVKNFromConditionType(c.Type)
+		if ref.APIVersion != nephioreqv1alpha1.GroupVersion.Identifier() || ref.Kind != kind {
+			continue
+		}
+		total++
+		if c.Status != kptv1.ConditionTrue {
+			pending = append(pending, ref.Name)
+		}
+	}
+	return newCondition(ct, resources, total, pending)
+}
+
+// getClaimsResolvedCondition aggregates the claims of the interfaces and the pools of the data networks
+func getClaimsResolvedCondition(objs fn.KubeObjects) (kptv1.Condition, error) {
+	total := 0
+	pending := []string{}
+	for _, o := range objs.Where(fn.IsGroupVersionKind(nephioreqv1alpha1.InterfaceGroupVersionKind)) {
+		itfce, err := ko.KubeObjectToStruct[nephioreqv1alpha1.Interface](o)
+		if err != nil {
+			return kptv1.Condition{}, err
+		}
+		total++
+		resolved := false
+		for _, s := range itfce.Status.IPClaimStatus {
+			if s.Prefix != nil {
+				resolved = true
+			}
+		}
+		if itfce.Spec.AttachmentType == nephioreqv1alpha1.AttachmentTypeVLAN && itfce.Status.VLANClaimStatus == nil {
+			resolved = false
+		}
+		if !resolved {
+			pending = append(pending, fmt.Sprintf("%s %s", nephioreqv1alpha1.InterfaceKind, itfce.GetName()))
+		}
+	}
+	for _, o := range objs.Where(fn.IsGroupVersionKind(nephioreqv1alpha1.DataNetworkGroupVersionKind)) {
+		dnn, err := ko.KubeObjectToStruct[nephioreqv1alpha1.DataNetwork](o)
+		if err != nil {
+			return kptv1.Condition{}, err
+		}
+		total++
+		resolved := len(dnn.Status.Pools) >= len(dnn.Spec.Pools)
+		for _, p := range dnn.Status.Pools {
+			if p.IPClaim.Prefix == nil {
+				resolved = false
+			}
+		}
+		if !resolved {
+			pending = append(pending, fmt.Sprintf("%s %s", nephioreqv1alpha1.DataNetworkKind, dnn.GetName()))
+		}
+	}
+	sort.Strings(pending)
+	return newCondition(ConditionTypeClaimsResolved, "claims", total, pending), nil
+}
+
+func newCondition(ct, resources string, total int, pending []string) kptv1.Condition {
+	if total == 0 {
+		return kptv1.Condition{Type: ct, Status: kptv1.ConditionTrue, Reason: ct, Message: fmt.Sprintf("no %s", resources)}
+	}
+	if len(pending) == 0 {
+		return kptv1.Condition{
+			Type:    ct,
+			Status:  kptv1.ConditionTrue,
+			Reason:  ct,
+			Message: fmt.Sprintf("%d/%d %s ready", total, total, resources),
+		}
+	}
+	sort.Strings(pending)
+	return kptv1.Condition{
+		Type:    ct,
+		Status:  kptv1.ConditionFalse,
+		Reason:  "Pending",
+		Message: fmt.Sprintf("%d/%d %s ready, pending: %s", total-len(pending), total, resources, strings.Join(pending, ", ")),
+	}
+}
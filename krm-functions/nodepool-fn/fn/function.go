@@ -0,0 +1,200 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	wclib "github.com/nephio-project/nephio/krm-functions/lib/workloadcluster"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// placementKey is the key in the data of the function config ConfigMap that selects how the
+	// workloads are placed on their node pool, with a nodeSelector by default
+	placementKey = "placement"
+	// nodeSelectorPlacement places the workloads with a nodeSelector
+	nodeSelectorPlacement = "nodeSelector"
+	// affinityPlacement places the workloads with a required node affinity
+	affinityPlacement = "affinity"
+)
+
+// Run is the entry point of the KRM function (called by the upstream fn SDK)
+// it places the workloads of the package on the node pool of the WorkloadCluster
+// with the capabilities the workloads require
+func Run(rl *fn.ResourceList) (bool, error) {
+	placement := nodeSelectorPlacement
+	if rl.FunctionConfig != nil {
+		if p, ok, _ := rl.FunctionConfig.NestedString("data", placementKey); ok {
+			placement = p
+		}
+	}
+	if placement != nodeSelectorPlacement && placement != affinityPlacement {
+		err := fmt.Errorf("placement %s not supported, supported placements: [%s %s]", placement, nodeSelectorPlacement, affinityPlacement)
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+
+	clusters := rl.Items.Where(ko.IsGroupVersionKind(infrav1alpha1.WorkloadClusterGroupVersionKind))
+	if len(clusters) == 0 {
+		rl.Results.Infof("no WorkloadCluster found in the kpt package, the workloads are not placed")
+		return true, nil
+	}
+	if len(clusters) > 1 {
+		err := fmt.Errorf("multiple WorkloadCluster objects found in the kpt package")
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	cluster, err := ko.KubeObjectToStruct[wclib.WorkloadClusterExt](clusters[0])
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	if err := validateNodePools(&cluster.Spec); err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+
+	for _, o := range rl.Items {
+		if !wclib.IsWorkload(o) {
+			continue
+		}
+		capabilities, err := getWorkloadCapabilities(o)
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		poolName := o.GetAnnotation(nodePoolAnnotation)
+		if poolName == "" && len(capabilities) == 0 {
+			// the workload can run on any node
+			continue
+		}
+		pool, err := getNodePool(&cluster.Spec, poolName, capabilities)
+		if err != nil {
+			err = fmt.Errorf("cannot place %s %s: %s", o.GetKind(), o.GetName(), err.Error())
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		if err := placeWorkload(o, pool, placement); err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		rl.Results.Infof("%s %s placed on node pool %s", o.GetKind(), o.GetName(), pool.Name)
+	}
+	return true, nil
+}
+
+// workload captures the pod template of a Deployment or a StatefulSet
+type workload struct {
+	Spec struct {
+		Template corev1.PodTemplateSpec `json:"template"`
+	} `json:"spec"`
+}
+
+// getWorkloadCapabilities returns the capabilities of the annotation of the workload,
+// a workload with containers requesting hugepages also requires the hugepages capability
+func getWorkloadCapabilities(o *fn.KubeObject) ([]string, error) {
+	capabilities := getCapabilities(o.GetAnnotation(capabilitiesAnnotation))
+	w, err := ko.KubeObjectToStruct[workload](o)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range w.Spec.Template.Spec.Containers {
+		for name := range c.Resources.Requests {
+			if strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) && !contains(capabilities, hugepagesCapability) {
+				capabilities = append(capabilities, hugepagesCapability)
+			}
+		}
+		for name := range c.Resources.Limits {
+			if strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) && !contains(capabilities, hugepagesCapability) {
+				capabilities = append(capabilities, hugepagesCapability)
+			}
+		}
+	}
+	sort.Strings(capabilities)
+	return capabilities, nil
+}
+
+// placeWorkload patches the pod template of the workload with the nodeSelector or the
+// node affinity of the node pool and with the tolerations of its taints
+func placeWorkload(o *fn.KubeObject, pool *wclib.NodePool, placement string) error {
+	// the node labels are patched in key order to keep the output stable
+	keys := make([]string, 0, len(pool.NodeSelector))
+	for k := range pool.NodeSelector {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	switch placement {
+	case affinityPlacement:
+		term := corev1.NodeSelectorTerm{}
+		for _, k := range keys {
+			req := corev1.NodeSelectorRequirement{Key: k, Operator: corev1.NodeSelectorOpExists}
+			if v := pool.NodeSelector[k]; v != "" {
+				req.Operator = corev1.NodeSelectorOpIn
+				req.Values = []string{v}
+			}
+			term.MatchExpressions = append(term.MatchExpressions, req)
+		}
+		if err := o.SetNestedField(&corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{term}},
+			"spec", "template", "spec", "affinity", "nodeAffinity", "requiredDuringSchedulingIgnoredDuringExecution"); err != nil {
+			return err
+		}
+	default:
+		for _, k := range keys {
+			if err := o.SetNestedString(pool.NodeSelector[k], "spec", "template", "spec", "nodeSelector", k); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(pool.Taints) == 0 {
+		return nil
+	}
+	w, err := ko.KubeObjectToStruct[workload](o)
+	if err != nil {
+		return err
+	}
+	tolerations := w.Spec.Template.Spec.Tolerations
+	poolTolerations := getTolerations(pool)
+	for i := range poolTolerations {
+		found := false
+		for _, x := range tolerations {
+			if x.MatchToleration(&poolTolerations[i]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tolerations = append(tolerations, poolTolerations[i])
+		}
+	}
+	return o.SetNestedField(tolerations, "spec", "template", "spec", "tolerations")
+}
+
+func contains(s []string, x string) bool {
+	for _, v := range s {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	wclib "github.com/nephio-project/nephio/krm-functions/lib/workloadcluster"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// nodePoolAnnotation selects the node pool of the WorkloadCluster a workload is placed on
+	nodePoolAnnotation = "nephio.org/node-pool"
+	// capabilitiesAnnotation lists the node pool capabilities a workload requires, comma separated
+	// e.g. sriov,dpdk
+	capabilitiesAnnotation = "nephio.org/node-pool-capabilities"
+	// hugepagesCapability is required by the workloads requesting hugepages
+	hugepagesCapability = "hugepages"
+)
+
+// validateNodePools checks the node pools of the workload cluster
+func validateNodePools(r *wclib.WorkloadClusterExtSpec) error {
+	names := map[string]struct{}{}
+	for i, pool := range r.NodePools {
+		if pool.Name == "" {
+			return fmt.Errorf("node pool %d of workload cluster %s has no name", i, r.ClusterName)
+		}
+		if _, ok := names[pool.Name]; ok {
+			return fmt.Errorf("node pool %s of workload cluster %s is defined multiple times", pool.Name, r.ClusterName)
+		}
+		names[pool.Name] = struct{}{}
+		if len(pool.NodeSelector) == 0 {
+			return fmt.Errorf("node pool %s of workload cluster %s has no nodeSelector", pool.Name, r.ClusterName)
+		}
+	}
+	return nil
+}
+
+// getNodePool returns the node pool with the name when set, otherwise the first node pool
+// with all the capabilities
+func getNodePool(r *wclib.WorkloadClusterExtSpec, name string, capabilities []string) (*wclib.NodePool, error) {
+	if name != "" {
+		for i := range r.NodePools {
+			if r.NodePools[i].Name != name {
+				continue
+			}
+			if missing := getMissingCapabilities(&r.NodePools[i], capabilities); len(missing) > 0 {
+				return nil, fmt.Errorf("node pool %s of workload cluster %s misses the capabilities %v", name, r.ClusterName, missing)
+			}
+			return &r.NodePools[i], nil
+		}
+		return nil, fmt.Errorf("node pool %s not found in workload cluster %s", name, r.ClusterName)
+	}
+	for i := range r.NodePools {
+		if len(getMissingCapabilities(&r.NodePools[i], capabilities)) == 0 {
+			return &r.NodePools[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no node pool of workload cluster %s has the capabilities %v", r.ClusterName, capabilities)
+}
+
+func getMissingCapabilities(r *wclib.NodePool, capabilities []string) []string {
+	missing := []string{}
+	for _, c := range capabilities {
+		found := false
+		for _, pc := range r.Capabilities {
+			if pc == c {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
+
+// getTolerations returns the tolerations of the taints of the node pool
+func getTolerations(r *wclib.NodePool) []corev1.Toleration {
+	tolerations := []corev1.Toleration{}
+	for _, t := range r.Taints {
+		toleration := corev1.Toleration{
+			Key:      t.Key,
+			Operator: corev1.TolerationOpExists,
+			Effect:   t.Effect,
+		}
+		if t.Value != "" {
+			toleration.Operator = corev1.TolerationOpEqual
+			toleration.Value = t.Value
+		}
+		tolerations = append(tolerations, toleration)
+	}
+	return tolerations
+}
+
+// getCapabilities returns the sorted capabilities of the annotation
+func getCapabilities(annotation string) []string {
+	capabilities := []string{}
+	for _, c := range strings.Split(annotation, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			capabilities = append(capabilities, c)
+		}
+	}
+	sort.Strings(capabilities)
+	return capabilities
+}
@@ -0,0 +1,211 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	kptv1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	nfdeployv1alpha1 "github.com/nephio-project/api/nf_deployments/v1alpha1"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	wclib "github.com/nephio-project/nephio/krm-functions/lib/workloadcluster"
+	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+)
+
+const (
+	// metadataConfigMapName is the name of the ConfigMap holding the cluster identity for the NFs
+	metadataConfigMapName = "package-metadata"
+
+	clusterNameKey = "clusterName"
+	siteNameKey    = "siteName"
+	regionKey      = "region"
+)
+
+// Run is the entry point of the KRM function (called by the upstream fn SDK)
+// it stamps the identity of the WorkloadCluster of the package, its cluster name, site and
+// region, as labels on the resources of the package and provides it to the NFs as a ConfigMap
+func Run(rl *fn.ResourceList) (bool, error) {
+	clusters := rl.Items.Where(ko.IsGroupVersionKind(infrav1alpha1.WorkloadClusterGroupVersionKind))
+	if len(clusters) == 0 {
+		rl.Results.Infof("no WorkloadCluster found in the kpt package, no metadata is stamped")
+		return true, nil
+	}
+	if len(clusters) > 1 {
+		err := fmt.Errorf("multiple WorkloadCluster objects found in the kpt package")
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	labels, err := getClusterLabels(clusters[0])
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	// the labels are stamped in key order to keep the output stable
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// the ConfigMaps are regenerated, as such they follow the WorkloadCluster and the NF deployments
+	items := fn.KubeObjects{}
+	for _, o := range rl.Items {
+		if !(o.IsGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap")) && o.GetName() == metadataConfigMapName) {
+			items = append(items, o)
+		}
+	}
+	// a configRef resolves in the namespace of the NF deployment, hence a ConfigMap is provided per namespace
+	for _, namespace := range getNFNamespaces(items) {
+		cm, err := getMetadataConfigMap(namespace, labels)
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		items = append(items, cm)
+	}
+
+	for _, o := range items {
+		// the local config and the Kptfile are not deployed in the workload cluster
+		if o.GetAnnotation(filters.LocalConfigAnnotation) == "true" || o.IsGroupVersionKind(kptv1.KptFileGVK()) {
+			continue
+		}
+		for _, k := range keys {
+			if err := o.SetLabel(k, labels[k]); err != nil {
+				rl.Results.ErrorE(err)
+				return false, err
+			}
+			if wclib.HasPodTemplate(o) {
+				if err := o.SetNestedString(labels[k], "spec", "template", "metadata", "labels", k); err != nil {
+					rl.Results.ErrorE(err)
+					return false, err
+				}
+			}
+		}
+		if isNFDeployment(o) {
+			if err := addConfigRef(o); err != nil {
+				rl.Results.ErrorE(err)
+				return false, err
+			}
+		}
+	}
+	rl.Items = items
+	rl.Results.Infof("metadata of workload cluster %s stamped on the kpt package", labels[resourcev1alpha1.NephioClusterNameKey])
+	return true, nil
+}
+
+// getClusterLabels returns the identity of the workload cluster, the cluster name of its spec and
+// the site and region of its labels
+func getClusterLabels(o *fn.KubeObject) (map[string]string, error) {
+	clusterName, _, _ := o.NestedString("spec", "clusterName")
+	if clusterName == "" {
+		return nil, fmt.Errorf("workload cluster %s has no clusterName", o.GetName())
+	}
+	labels := map[string]string{
+		resourcev1alpha1.NephioClusterNameKey: clusterName,
+	}
+	for _, k := range []string{resourcev1alpha1.NephioSiteNameKey, resourcev1alpha1.NephioRegionKey} {
+		if v := o.GetLabels()[k]; v != "" {
+			labels[k] = v
+		}
+	}
+	return labels, nil
+}
+
+// getNFNamespaces returns the sorted namespaces of the NF deployments; the package without NF deployments
+// gets the ConfigMap in the namespace of the package
+func getNFNamespaces(objs fn.KubeObjects) []string {
+	namespaces := map[string]struct{}{}
+	for _, o := range objs {
+		if isNFDeployment(o) {
+			namespaces[o.GetNamespace()] = struct{}{}
+		}
+	}
+	if len(namespaces) == 0 {
+		return []string{""}
+	}
+	keys := make([]string, 0, len(namespaces))
+	for namespace := range namespaces {
+		keys = append(keys, namespace)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getMetadataConfigMap returns the ConfigMap the NF deployments of the namespace reference in their configRefs
+func getMetadataConfigMap(namespace string, labels map[string]string) (*fn.KubeObject, error) {
+	data := map[string]string{
+		clusterNameKey: labels[resourcev1alpha1.NephioClusterNameKey],
+	}
+	if v, ok := labels[resourcev1alpha1.NephioSiteNameKey]; ok {
+		data[siteNameKey] = v
+	}
+	if v, ok := labels[resourcev1alpha1.NephioRegionKey]; ok {
+		data[regionKey] = v
+	}
+	return fn.NewFromTypedObject(&corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.Identifier(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      metadataConfigMapName,
+			Namespace: namespace,
+		},
+		Data: data,
+	})
+}
+
+// isNFDeployment returns true for the NF deployments, e.g. UPFDeployment, SMFDeployment, AMFDeployment
+func isNFDeployment(o *fn.KubeObject) bool {
+	return o.IsGroupVersionKind(nfdeployv1alpha1.UPFDeploymentGroupVersionKind) ||
+		o.IsGroupVersionKind(nfdeployv1alpha1.SMFDeploymentGroupVersionKind) ||
+		o.IsGroupVersionKind(nfdeployv1alpha1.AMFDeploymentGroupVersionKind)
+}
+
+// addConfigRef adds the metadata ConfigMap to the configRefs of the NF deployment
+func addConfigRef(o *fn.KubeObject) error {
+	// the NF deployments share the NFDeploymentSpec
+	nfDeployment, err := ko.KubeObjectToStruct[nfdeployv1alpha1.UPFDeployment](o)
+	if err != nil {
+		return err
+	}
+	refs := nfDeployment.Spec.ConfigRefs
+	configRef := corev1.ObjectReference{
+		APIVersion: corev1.SchemeGroupVersion.Identifier(),
+		Kind:       "ConfigMap",
+		Namespace:  o.GetNamespace(),
+		Name:       metadataConfigMapName,
+	}
+	for i, ref := range refs {
+		if ref.Kind == "ConfigMap" && ref.Name == metadataConfigMapName {
+			// the ref of a previous run is updated, e.g. when the NF deployment moved to another namespace
+			if ref == configRef {
+				return nil
+			}
+			refs[i] = configRef
+			return o.SetNestedField(refs, "spec", "configRefs")
+		}
+	}
+	refs = append(refs, configRef)
+	return o.SetNestedField(refs, "spec", "configRefs")
+}
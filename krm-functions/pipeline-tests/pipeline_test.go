@@ -22,6 +22,7 @@ import (
 
 	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
 	tlib "github.com/nephio-project/nephio/krm-functions/lib/test"
+	"github.com/nephio-project/nephio/krm-functions/lib/testhelpers"
 
 	nephiodeployv1alpha1 "github.com/nephio-project/api/nf_deployments/v1alpha1"
 	dnn_fn "github.com/nephio-project/nephio/krm-functions/dnn-fn/fn"
@@ -151,3 +152,21 @@ func TestPipelines(t *testing.T) {
 		})
 	}
 }
+
+// TestPipelinePackages runs the specialization pipeline on the packages of testdata/packages and compares
+// the specialized packages with their _expected package
+func TestPipelinePackages(t *testing.T) {
+	testhelpers.RunPipelineGoldenTests(t, filepath.Join(testdir, "packages"), []testhelpers.Step{
+		{Name: "upf-deploy-fn", Fn: upfFn},
+		{Name: "interface-fn", Fn: if_fn.Run},
+		{Name: "dnn-fn", Fn: dnn_fn.Run},
+
+		{Name: "ipam-fn", Fn: ipamFn.Run},
+		{Name: "vlan-fn", Fn: vlanFn.Run},
+
+		{Name: "nad-fn", Fn: nad_fn.Run},
+		{Name: "interface-fn", Fn: if_fn.Run},
+		{Name: "dnn-fn", Fn: dnn_fn.Run},
+		{Name: "upf-deploy-fn", Fn: upfFn},
+	})
+}
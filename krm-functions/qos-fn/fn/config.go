@@ -0,0 +1,208 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	free5gcVendor = "free5gc"
+	oaiVendor     = "oai"
+
+	// qosField is the field of the QoS section in the config files
+	qosField = "qos"
+)
+
+// qosFlow is a QoS flow of the profile with the UE subnets of the pools of its 5QI
+type qosFlow struct {
+	fiveQI    int
+	dscp      int
+	ueSubnets []string
+}
+
+// qosConfig is the QoS config of the profile rendered in the config files
+type qosConfig struct {
+	default5QI  int
+	sessionAMBR *SessionAMBR
+	flows       []qosFlow
+}
+
+// configSection defines the QoS section of an NF in a config file of an NF vendor
+type configSection struct {
+	vendor string
+	nf     string
+	// path is the path of the mapping the QoS section is set in, the config file
+	// is skipped when the mapping does not exist
+	path   []string
+	render func(cfg *qosConfig) (any, error)
+}
+
+// configFiles are the QoS sections per data key of the ConfigMaps holding the config files of the NFs;
+// free5gc has a config file per NF while OAI shares a config file between the NFs
+var configFiles = map[string][]configSection{
+	"smfcfg.yaml": {{vendor: free5gcVendor, nf: "SMF", path: []string{"configuration"}, render: renderFree5gcSMF}},
+	"upfcfg.yaml": {{vendor: free5gcVendor, nf: "UPF", render: renderFree5gcUPF}},
+	"config.yaml": {
+		{vendor: oaiVendor, nf: "SMF", path: []string{"smf"}, render: renderOAISMF},
+		{vendor: oaiVendor, nf: "UPF", path: []string{"upf"}, render: renderOAIUPF},
+	},
+}
+
+// renderConfigFile sets the QoS sections in the config file, the sections are overwritten such
+// that the config file follows the profile; it returns the rendered sections
+func renderConfigFile(data string, sections []configSection, cfg *qosConfig) (string, []configSection, error) {
+	node, err := yaml.Parse(data)
+	if err != nil {
+		return "", nil, err
+	}
+	rendered := []configSection{}
+	for _, s := range sections {
+		parent, err := node.Pipe(yaml.Lookup(s.path...))
+		if err != nil {
+			return "", nil, err
+		}
+		if parent == nil {
+			continue
+		}
+		section, err := s.render(cfg)
+		if err != nil {
+			return "", nil, err
+		}
+		b, err := yaml.Marshal(section)
+		if err != nil {
+			return "", nil, err
+		}
+		sectionNode, err := yaml.Parse(string(b))
+		if err != nil {
+			return "", nil, err
+		}
+		if err := parent.PipeE(yaml.SetField(qosField, sectionNode)); err != nil {
+			return "", nil, err
+		}
+		rendered = append(rendered, s)
+	}
+	if len(rendered) == 0 {
+		return data, rendered, nil
+	}
+	s, err := node.String()
+	return s, rendered, err
+}
+
+// free5gc config files use camel case fields and bit rates with a space, e.g. 1 Gbps
+
+type free5gcSMFQoS struct {
+	Default5QI  int              `yaml:"default5qi"`
+	SessionAMBR *free5gcAMBR     `yaml:"sessionAmbr,omitempty"`
+	Flows       []free5gcQoSFlow `yaml:"flows,omitempty"`
+}
+
+type free5gcAMBR struct {
+	Uplink   string `yaml:"uplink"`
+	Downlink string `yaml:"downlink"`
+}
+
+type free5gcQoSFlow struct {
+	FiveQI    int      `yaml:"5qi"`
+	DSCP      int      `yaml:"dscp"`
+	UESubnets []string `yaml:"ueSubnets,omitempty"`
+}
+
+type free5gcUPFQoS struct {
+	DSCPMarking []free5gcQoSFlow `yaml:"dscpMarking"`
+}
+
+func renderFree5gcSMF(cfg *qosConfig) (any, error) {
+	qos := free5gcSMFQoS{Default5QI: cfg.default5QI}
+	if cfg.sessionAMBR != nil {
+		ul, err := formatBitRate(cfg.sessionAMBR.Uplink, "%d %s")
+		if err != nil {
+			return nil, err
+		}
+		dl, err := formatBitRate(cfg.sessionAMBR.Downlink, "%d %s")
+		if err != nil {
+			return nil, err
+		}
+		qos.SessionAMBR = &free5gcAMBR{Uplink: ul, Downlink: dl}
+	}
+	for _, f := range cfg.flows {
+		qos.Flows = append(qos.Flows, free5gcQoSFlow{FiveQI: f.fiveQI, DSCP: f.dscp})
+	}
+	return qos, nil
+}
+
+func renderFree5gcUPF(cfg *qosConfig) (any, error) {
+	qos := free5gcUPFQoS{DSCPMarking: []free5gcQoSFlow{}}
+	for _, f := range cfg.flows {
+		qos.DSCPMarking = append(qos.DSCPMarking, free5gcQoSFlow{FiveQI: f.fiveQI, DSCP: f.dscp, UESubnets: f.ueSubnets})
+	}
+	return qos, nil
+}
+
+// OAI config files use snake case fields and bit rates without a space, e.g. 1Gbps
+
+type oaiSMFQoS struct {
+	Default5QI    int          `yaml:"default_5qi"`
+	SessionAMBRUL string       `yaml:"session_ambr_ul,omitempty"`
+	SessionAMBRDL string       `yaml:"session_ambr_dl,omitempty"`
+	Flows         []oaiQoSFlow `yaml:"flows,omitempty"`
+}
+
+type oaiQoSFlow struct {
+	FiveQI    int      `yaml:"5qi"`
+	DSCP      int      `yaml:"dscp"`
+	UESubnets []string `yaml:"ue_subnets,omitempty"`
+}
+
+type oaiUPFQoS struct {
+	DSCPMarking []oaiQoSFlow `yaml:"dscp_marking"`
+}
+
+func renderOAISMF(cfg *qosConfig) (any, error) {
+	qos := oaiSMFQoS{Default5QI: cfg.default5QI}
+	if cfg.sessionAMBR != nil {
+		var err error
+		if qos.SessionAMBRUL, err = formatBitRate(cfg.sessionAMBR.Uplink, "%d%s"); err != nil {
+			return nil, err
+		}
+		if qos.SessionAMBRDL, err = formatBitRate(cfg.sessionAMBR.Downlink, "%d%s"); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range cfg.flows {
+		qos.Flows = append(qos.Flows, oaiQoSFlow{FiveQI: f.fiveQI, DSCP: f.dscp})
+	}
+	return qos, nil
+}
+
+func renderOAIUPF(cfg *qosConfig) (any, error) {
+	qos := oaiUPFQoS{DSCPMarking: []oaiQoSFlow{}}
+	for _, f := range cfg.flows {
+		qos.DSCPMarking = append(qos.DSCPMarking, oaiQoSFlow{FiveQI: f.fiveQI, DSCP: f.dscp, UESubnets: f.ueSubnets})
+	}
+	return qos, nil
+}
+
+func formatBitRate(s, format string) (string, error) {
+	v, unit, err := parseBitRate(s)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(format, v, unit), nil
+}
@@ -0,0 +1,140 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// fiveQILabel is the label of a pool IPClaim carrying the 5QI of the pool, set by the dnn fn
+const fiveQILabel = "nephio.org/5qi"
+
+// Run is the entry point of the KRM function (called by the upstream fn SDK)
+// it renders the QoSProfile of the package in the QoS sections of the UPF and SMF config files
+// of the package, the 5QI to DSCP mapping of the QoS flows and the session AMBR defaults
+func Run(rl *fn.ResourceList) (bool, error) {
+	profiles := rl.Items.Where(fn.IsGroupVersionKind(QoSProfileGroupVersionKind))
+	if len(profiles) == 0 {
+		rl.Results.Infof("no QoSProfile found in the kpt package, no qos config is rendered")
+		return true, nil
+	}
+	if len(profiles) > 1 {
+		err := fmt.Errorf("multiple QoSProfile objects found in the kpt package")
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	profile, err := ko.KubeObjectToStruct[QoSProfile](profiles[0])
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	if err := profile.Validate(); err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	ueSubnets, err := getUESubnets(rl.Items)
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	cfg := &qosConfig{
+		default5QI:  profile.GetDefault5QI(),
+		sessionAMBR: profile.Spec.SessionAMBR,
+	}
+	for _, f := range profile.Spec.Flows {
+		cfg.flows = append(cfg.flows, qosFlow{fiveQI: f.FiveQI, dscp: f.GetDSCP(), ueSubnets: ueSubnets[f.FiveQI]})
+	}
+	sort.Slice(cfg.flows, func(i, j int) bool {
+		return cfg.flows[i].fiveQI < cfg.flows[j].fiveQI
+	})
+
+	rendered := false
+	for _, o := range rl.Items.Where(fn.IsGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))) {
+		data, _, err := o.NestedStringMap("data")
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		// the config files are rendered in key order to keep the results stable
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sections, ok := configFiles[k]
+			if !ok {
+				continue
+			}
+			s, renderedSections, err := renderConfigFile(data[k], sections, cfg)
+			if err != nil {
+				err = fmt.Errorf("cannot render the qos config in %s of ConfigMap %s: %s", k, o.GetName(), err.Error())
+				rl.Results.ErrorE(err)
+				return false, err
+			}
+			if len(renderedSections) == 0 {
+				continue
+			}
+			if err := o.SetNestedString(s, "data", k); err != nil {
+				rl.Results.ErrorE(err)
+				return false, err
+			}
+			for _, section := range renderedSections {
+				rl.Results.Infof("qos config of %s %s rendered in %s of ConfigMap %s", section.vendor, section.nf, k, o.GetName())
+			}
+			rendered = true
+		}
+	}
+	if !rendered {
+		rl.Results.Warningf("no UPF or SMF config file found in the kpt package, QoSProfile %s is not rendered", profile.GetName())
+	}
+	return true, nil
+}
+
+// getUESubnets returns the prefixes of the resolved pool IPClaims per 5QI of the pool
+func getUESubnets(objs fn.KubeObjects) (map[int][]string, error) {
+	ueSubnets := map[int][]string{}
+	for _, o := range objs.Where(fn.IsGroupVersionKind(ipamv1alpha1.IPClaimGroupVersionKind)) {
+		v, ok := o.GetLabels()[fiveQILabel]
+		if !ok {
+			continue
+		}
+		fiveQI, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s label %s of IPClaim %s", fiveQILabel, v, o.GetName())
+		}
+		claim, err := ko.KubeObjectToStruct[ipamv1alpha1.IPClaim](o)
+		if err != nil {
+			return nil, err
+		}
+		if claim.Spec.Kind != ipamv1alpha1.PrefixKindPool || claim.Status.Prefix == nil {
+			continue
+		}
+		ueSubnets[fiveQI] = append(ueSubnets[fiveQI], *claim.Status.Prefix)
+	}
+	for _, subnets := range ueSubnets {
+		sort.Strings(subnets)
+	}
+	return ueSubnets, nil
+}
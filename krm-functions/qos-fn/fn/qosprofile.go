@@ -0,0 +1,151 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// QoSProfileKind is the kind of the QoS requirement, it is not part of the nephio api yet
+	QoSProfileKind = "QoSProfile"
+
+	// default5QI is the 5QI of the default QoS flow, the best effort non-GBR traffic
+	default5QI = 9
+)
+
+// QoSProfileGroupVersionKind is the GroupVersionKind of the QoS requirement
+var QoSProfileGroupVersionKind = nephioreqv1alpha1.GroupVersion.WithKind(QoSProfileKind)
+
+// standardDSCP is the DSCP marking of the standardized 5QIs, used for the QoS flows without a DSCP
+var standardDSCP = map[int]int{
+	1:  46, // conversational voice, EF
+	2:  34, // conversational video, AF41
+	3:  32, // real time gaming, CS4
+	4:  36, // non-conversational video, AF42
+	5:  40, // IMS signalling, CS5
+	6:  18, // buffered streaming, AF21
+	7:  26, // interactive traffic, AF31
+	8:  10, // TCP based traffic, AF11
+	9:  0,  // best effort, BE
+	65: 46, // mission critical push to talk voice, EF
+	66: 46, // non mission critical push to talk voice, EF
+	69: 40, // mission critical signalling, CS5
+	70: 18, // mission critical data, AF21
+}
+
+var bitRateRegexp = regexp.MustCompile(`^([0-9]+) ?(bps|Kbps|Mbps|Gbps|Tbps)$`)
+
+// QoSProfile defines the QoS requirements of the PDU sessions handled by the NFs of the package,
+// which are rendered in the config files of the UPF and the SMF
+type QoSProfile struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Spec              QoSProfileSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+type QoSProfileSpec struct {
+	// Default5QI defines the 5QI of the default QoS flow of the PDU sessions, 9 by default
+	Default5QI int `json:"default5qi,omitempty" yaml:"default5qi,omitempty"`
+	// SessionAMBR defines the default session aggregate maximum bit rate of the PDU sessions
+	SessionAMBR *SessionAMBR `json:"sessionAMBR,omitempty" yaml:"sessionAMBR,omitempty"`
+	// Flows defines the QoS flows of the PDU sessions and the DSCP marking of their traffic
+	Flows []QoSFlow `json:"flows,omitempty" yaml:"flows,omitempty"`
+}
+
+type SessionAMBR struct {
+	// Uplink defines the uplink bit rate, e.g. 1 Gbps
+	Uplink string `json:"uplink" yaml:"uplink"`
+	// Downlink defines the downlink bit rate, e.g. 2 Gbps
+	Downlink string `json:"downlink" yaml:"downlink"`
+}
+
+type QoSFlow struct {
+	// FiveQI defines the 5G QoS identifier of the flow
+	FiveQI int `json:"5qi" yaml:"5qi"`
+	// DSCP defines the DSCP marking of the traffic of the flow, the marking of the standardized
+	// 5QI by default
+	DSCP *int `json:"dscp,omitempty" yaml:"dscp,omitempty"`
+}
+
+// Validate checks the QoS profile
+func (r *QoSProfile) Validate() error {
+	if r.Spec.Default5QI < 0 || r.Spec.Default5QI > 255 {
+		return fmt.Errorf("default5qi %d of QoSProfile %s out of range [1, 255]", r.Spec.Default5QI, r.GetName())
+	}
+	if r.Spec.SessionAMBR != nil {
+		for _, br := range []string{r.Spec.SessionAMBR.Uplink, r.Spec.SessionAMBR.Downlink} {
+			if _, _, err := parseBitRate(br); err != nil {
+				return fmt.Errorf("invalid sessionAMBR of QoSProfile %s: %s", r.GetName(), err.Error())
+			}
+		}
+	}
+	fiveQIs := map[int]struct{}{}
+	for _, f := range r.Spec.Flows {
+		if f.FiveQI < 1 || f.FiveQI > 255 {
+			return fmt.Errorf("5qi %d of QoSProfile %s out of range [1, 255]", f.FiveQI, r.GetName())
+		}
+		if _, ok := fiveQIs[f.FiveQI]; ok {
+			return fmt.Errorf("duplicate flow with 5qi %d in QoSProfile %s", f.FiveQI, r.GetName())
+		}
+		fiveQIs[f.FiveQI] = struct{}{}
+		if f.DSCP == nil {
+			if _, ok := standardDSCP[f.FiveQI]; !ok {
+				return fmt.Errorf("flow with 5qi %d of QoSProfile %s requires a dscp since the 5qi is not standardized", f.FiveQI, r.GetName())
+			}
+			continue
+		}
+		if *f.DSCP < 0 || *f.DSCP > 63 {
+			return fmt.Errorf("dscp %d of QoSProfile %s out of range [0, 63]", *f.DSCP, r.GetName())
+		}
+	}
+	return nil
+}
+
+// GetDefault5QI returns the 5QI of the default QoS flow
+func (r *QoSProfile) GetDefault5QI() int {
+	if r.Spec.Default5QI != 0 {
+		return r.Spec.Default5QI
+	}
+	return default5QI
+}
+
+// GetDSCP returns the DSCP marking of the flow
+func (r *QoSFlow) GetDSCP() int {
+	if r.DSCP != nil {
+		return *r.DSCP
+	}
+	return standardDSCP[r.FiveQI]
+}
+
+// parseBitRate returns the value and the unit of a bit rate, e.g. 1 Gbps
+func parseBitRate(s string) (int, string, error) {
+	m := bitRateRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return 0, "", fmt.Errorf("bit rate %q is not a number followed by bps, Kbps, Mbps, Gbps or Tbps", s)
+	}
+	v, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, "", err
+	}
+	return v, m[2], nil
+}
@@ -0,0 +1,169 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// CredentialKind is the kind of the credential requirement, it is not part of the nephio api yet
+	CredentialKind = "Credential"
+
+	externalSecretAPIVersion = "external-secrets.io/v1beta1"
+	externalSecretKind       = "ExternalSecret"
+	defaultSecretStoreKind   = "ClusterSecretStore"
+	defaultKeyPrefix         = "nephio"
+)
+
+// CredentialGroupVersionKind is the GroupVersionKind of the credential requirement
+var CredentialGroupVersionKind = nephioreqv1alpha1.GroupVersion.WithKind(CredentialKind)
+
+// Credential defines the requirement of an NF for a credential, e.g. the SUPI keys, a TLS
+// certificate or repository credentials, which is provided by a Secret of the workload cluster
+type Credential struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Spec              CredentialSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+type CredentialSpec struct {
+	// Type defines the type of the Secret, e.g. kubernetes.io/tls, Opaque by default
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// SecretName defines the name of the Secret, the name of the credential by default
+	SecretName string `json:"secretName,omitempty" yaml:"secretName,omitempty"`
+	// Selector selects the Deployments and StatefulSets of the package the credential is injected
+	// into by their labels, all of them by default
+	Selector *metav1.LabelSelector `json:"selector,omitempty" yaml:"selector,omitempty"`
+	// Containers restricts the containers the credential is injected into, all containers by default
+	Containers []string `json:"containers,omitempty" yaml:"containers,omitempty"`
+	// MountPath defines the directory the keys of the Secret are mounted in, the keys of the
+	// Secret are injected as environment variables when not set
+	MountPath string `json:"mountPath,omitempty" yaml:"mountPath,omitempty"`
+	// ExternalSecret provides the Secret with an ExternalSecret per workload cluster, the Secret
+	// is provisioned in the workload cluster out of band when not set
+	ExternalSecret *ExternalSecretSpec `json:"externalSecret,omitempty" yaml:"externalSecret,omitempty"`
+}
+
+type ExternalSecretSpec struct {
+	// SecretStoreRef defines the secret store the credential is fetched from
+	SecretStoreRef SecretStoreRef `json:"secretStoreRef" yaml:"secretStoreRef"`
+	// KeyPrefix prefixes the key of the credential in the secret store, the key is
+	// <keyPrefix>/<clusterName>/<credential name>; nephio by default
+	KeyPrefix string `json:"keyPrefix,omitempty" yaml:"keyPrefix,omitempty"`
+	// RefreshInterval defines the interval the Secret is refreshed from the secret store
+	RefreshInterval string `json:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty"`
+}
+
+type SecretStoreRef struct {
+	// Name defines the name of the secret store
+	Name string `json:"name" yaml:"name"`
+	// Kind defines the kind of the secret store, SecretStore or ClusterSecretStore; ClusterSecretStore by default
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+}
+
+// Validate checks the credential
+func (r *Credential) Validate() error {
+	if r.Spec.MountPath != "" && !path.IsAbs(r.Spec.MountPath) {
+		return fmt.Errorf("mountPath %s of credential %s is not an absolute path", r.Spec.MountPath, r.GetName())
+	}
+	if r.Spec.ExternalSecret != nil && r.Spec.ExternalSecret.SecretStoreRef.Name == "" {
+		return fmt.Errorf("externalSecret of credential %s has no secretStoreRef name", r.GetName())
+	}
+	return nil
+}
+
+// GetSecretName returns the name of the Secret of the credential
+func (r *Credential) GetSecretName() string {
+	if r.Spec.SecretName != "" {
+		return r.Spec.SecretName
+	}
+	return r.GetName()
+}
+
+// getRemoteKey returns the key of the credential of the workload cluster in the secret store
+func (r *Credential) getRemoteKey(clusterName string) string {
+	prefix := r.Spec.ExternalSecret.KeyPrefix
+	if prefix == "" {
+		prefix = defaultKeyPrefix
+	}
+	return strings.Join([]string{strings.Trim(prefix, "/"), clusterName, r.GetName()}, "/")
+}
+
+// externalSecret is the ExternalSecret resource of the external secrets operator
+type externalSecret struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Spec              externalSecretSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+type externalSecretSpec struct {
+	RefreshInterval string               `json:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty"`
+	SecretStoreRef  SecretStoreRef       `json:"secretStoreRef" yaml:"secretStoreRef"`
+	Target          externalSecretTarget `json:"target" yaml:"target"`
+	DataFrom        []externalSecretData `json:"dataFrom" yaml:"dataFrom"`
+}
+
+type externalSecretTarget struct {
+	Name     string                  `json:"name" yaml:"name"`
+	Template *externalSecretTemplate `json:"template,omitempty" yaml:"template,omitempty"`
+}
+
+type externalSecretTemplate struct {
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+}
+
+type externalSecretData struct {
+	Extract externalSecretRemoteRef `json:"extract" yaml:"extract"`
+}
+
+type externalSecretRemoteRef struct {
+	Key string `json:"key" yaml:"key"`
+}
+
+// getExternalSecret returns the ExternalSecret providing the Secret of the credential from
+// the key of the workload cluster in the secret store
+func (r *Credential) getExternalSecret(meta metav1.ObjectMeta, clusterName string) *externalSecret {
+	storeRef := r.Spec.ExternalSecret.SecretStoreRef
+	if storeRef.Kind == "" {
+		storeRef.Kind = defaultSecretStoreKind
+	}
+	target := externalSecretTarget{Name: r.GetSecretName()}
+	if r.Spec.Type != "" {
+		target.Template = &externalSecretTemplate{Type: r.Spec.Type}
+	}
+	return &externalSecret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: externalSecretAPIVersion,
+			Kind:       externalSecretKind,
+		},
+		ObjectMeta: meta,
+		Spec: externalSecretSpec{
+			RefreshInterval: r.Spec.ExternalSecret.RefreshInterval,
+			SecretStoreRef:  storeRef,
+			Target:          target,
+			DataFrom: []externalSecretData{{
+				Extract: externalSecretRemoteRef{Key: r.getRemoteKey(clusterName)},
+			}},
+		},
+	}
+}
@@ -0,0 +1,204 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	"github.com/nephio-project/nephio/krm-functions/lib/condkptsdk"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	wclib "github.com/nephio-project/nephio/krm-functions/lib/workloadcluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// credentialOwnerPrefix prefixes the owner annotation of the ExternalSecrets with the name of their Credential
+var credentialOwnerPrefix = fmt.Sprintf("%s.%s.", CredentialGroupVersionKind.GroupVersion().Identifier(), CredentialKind)
+
+// Run is the entry point of the KRM function (called by the upstream fn SDK)
+// it injects the Secrets of the Credential requirements of the package into its workloads
+// and provides the Secrets of the workload cluster with ExternalSecrets
+func Run(rl *fn.ResourceList) (bool, error) {
+	credentials, err := ko.KubeObjectsToStructs[Credential](rl.Items.Where(fn.IsGroupVersionKind(CredentialGroupVersionKind)))
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	if len(credentials) == 0 {
+		rl.Results.Infof("no Credential found in the kpt package, no secrets are injected")
+		return true, nil
+	}
+	sort.Slice(credentials, func(i, j int) bool {
+		return credentials[i].GetName() < credentials[j].GetName()
+	})
+	clusters := rl.Items.Where(ko.IsGroupVersionKind(infrav1alpha1.WorkloadClusterGroupVersionKind))
+	if len(clusters) > 1 {
+		err := fmt.Errorf("multiple WorkloadCluster objects found in the kpt package")
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+
+	// the ExternalSecrets are regenerated, as such the ExternalSecrets of deleted credentials are removed
+	items := fn.KubeObjects{}
+	for _, o := range rl.Items {
+		if !isExternalSecret(o) || !strings.HasPrefix(o.GetAnnotation(condkptsdk.SpecializerOwner), credentialOwnerPrefix) {
+			items = append(items, o)
+		}
+	}
+	for i := range credentials {
+		c := &credentials[i]
+		if err := c.Validate(); err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		if c.Spec.ExternalSecret != nil {
+			if len(clusters) == 0 {
+				err := fmt.Errorf("credential %s requires a WorkloadCluster in the kpt package to fetch its secret per cluster", c.GetName())
+				rl.Results.ErrorE(err)
+				return false, err
+			}
+			clusterName, _, _ := clusters[0].NestedString("spec", "clusterName")
+			o, err := fn.NewFromTypedObject(c.getExternalSecret(metav1.ObjectMeta{
+				Name: c.GetName(),
+				Annotations: map[string]string{
+					condkptsdk.SpecializerOwner: credentialOwnerPrefix + c.GetName(),
+				},
+			}, clusterName))
+			if err != nil {
+				rl.Results.ErrorE(err)
+				return false, err
+			}
+			items = append(items, o)
+		}
+
+		selector := labels.Everything()
+		if c.Spec.Selector != nil {
+			selector, err = metav1.LabelSelectorAsSelector(c.Spec.Selector)
+			if err != nil {
+				rl.Results.ErrorE(err)
+				return false, err
+			}
+		}
+		for _, o := range items {
+			if !wclib.IsWorkload(o) || !selector.Matches(labels.Set(o.GetLabels())) {
+				continue
+			}
+			if err := injectCredential(o, c); err != nil {
+				rl.Results.ErrorE(err)
+				return false, err
+			}
+			rl.Results.Infof("credential %s injected into %s %s", c.GetName(), o.GetKind(), o.GetName())
+		}
+	}
+	rl.Items = items
+	return true, nil
+}
+
+// workload captures the pod template of a Deployment or a StatefulSet
+type workload struct {
+	Spec struct {
+		Template corev1.PodTemplateSpec `json:"template"`
+	} `json:"spec"`
+}
+
+func isExternalSecret(o *fn.KubeObject) bool {
+	return o.GetAPIVersion() == externalSecretAPIVersion && o.GetKind() == externalSecretKind
+}
+
+// injectCredential adds the Secret of the credential as a volume of the pod template of the workload
+// mounted in the containers, or as environment variables of the containers when no mount path is set;
+// the credential is injected once, as such the function is idempotent
+func injectCredential(o *fn.KubeObject, c *Credential) error {
+	w, err := ko.KubeObjectToStruct[workload](o)
+	if err != nil {
+		return err
+	}
+	podSpec := w.Spec.Template.Spec
+	if c.Spec.MountPath != "" {
+		volumes := []corev1.Volume{}
+		for _, v := range podSpec.Volumes {
+			if v.Name != c.GetName() {
+				volumes = append(volumes, v)
+			}
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: c.GetName(),
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: c.GetSecretName()},
+			},
+		})
+		if err := o.SetNestedField(volumes, "spec", "template", "spec", "volumes"); err != nil {
+			return err
+		}
+	}
+
+	cs, _, err := o.NestedSlice("spec", "template", "spec", "containers")
+	if err != nil {
+		return err
+	}
+	for i, sc := range cs {
+		if i >= len(podSpec.Containers) {
+			break
+		}
+		container := podSpec.Containers[i]
+		if len(c.Spec.Containers) > 0 && !contains(c.Spec.Containers, container.Name) {
+			continue
+		}
+		if c.Spec.MountPath != "" {
+			mounts := []corev1.VolumeMount{}
+			for _, m := range container.VolumeMounts {
+				if m.Name != c.GetName() {
+					mounts = append(mounts, m)
+				}
+			}
+			mounts = append(mounts, corev1.VolumeMount{Name: c.GetName(), MountPath: c.Spec.MountPath, ReadOnly: true})
+			if err := sc.SetNestedField(mounts, "volumeMounts"); err != nil {
+				return err
+			}
+			continue
+		}
+		found := false
+		for _, e := range container.EnvFrom {
+			if e.SecretRef != nil && e.SecretRef.Name == c.GetSecretName() {
+				found = true
+			}
+		}
+		if !found {
+			envFrom := append(container.EnvFrom, corev1.EnvFromSource{
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: c.GetSecretName()}},
+			})
+			if err := sc.SetNestedField(envFrom, "envFrom"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func contains(s []string, x string) bool {
+	for _, v := range s {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,280 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	infrav1alpha1 "github.com/nephio-project/api/infra/v1alpha1"
+	nephioreqv1alpha1 "github.com/nephio-project/api/nf_requirements/v1alpha1"
+	"github.com/nephio-project/nephio/krm-functions/lib/condkptsdk"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	wclib "github.com/nephio-project/nephio/krm-functions/lib/workloadcluster"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/ipam/v1alpha1"
+	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultPODNetwork = "default"
+	sriovCNIType      = nephioreqv1alpha1.CNIType("sriov")
+	// cniTypeAnnotation is the cni type the interface fn selected for an interface without cniType
+	cniTypeAnnotation = "nephio.org/cni-type"
+	// sriovOperatorForAnnotation identifies the resources generated by the fn with the resource
+	// the interfaces belong to
+	sriovOperatorForAnnotation = "nephio.org/sriov-operator-for"
+
+	nadAPIVersion = "k8s.cni.cncf.io/v1"
+	nadKind       = "NetworkAttachmentDefinition"
+)
+
+// interfaceOwnerPrefix prefixes the owner annotation of the claims of an Interface with the name of the Interface
+var interfaceOwnerPrefix = fmt.Sprintf("%s.%s.", nephioreqv1alpha1.GroupVersion.Identifier(), nephioreqv1alpha1.InterfaceKind)
+
+// Run is the entry point of the KRM function (called by the upstream fn SDK)
+// it renders the SriovNetworks of the sriov interfaces of the package from their resolved
+// claims, the sriov network operator renders their NADs in the workload cluster
+func Run(rl *fn.ResourceList) (bool, error) {
+	clusters := rl.Items.Where(ko.IsGroupVersionKind(infrav1alpha1.WorkloadClusterGroupVersionKind))
+	if len(clusters) == 0 {
+		rl.Results.Infof("no WorkloadCluster found in the kpt package, no sriov resources are rendered")
+		return true, nil
+	}
+	if len(clusters) > 1 {
+		err := fmt.Errorf("multiple WorkloadCluster objects found in the kpt package")
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	cluster, err := ko.KubeObjectToStruct[wclib.WorkloadClusterExt](clusters[0])
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+
+	// the sriov resources are regenerated, as such the resources of deleted interfaces are removed
+	items := fn.KubeObjects{}
+	for _, o := range rl.Items {
+		if o.GetAnnotation(sriovOperatorForAnnotation) == "" {
+			items = append(items, o)
+		}
+	}
+	// the resource pools with a node policy in the package, e.g. generated by the interface fn
+	nodePolicies := map[string]struct{}{}
+	for _, o := range items {
+		if o.GetAPIVersion() == sriovNetworkAPIVersion && o.GetKind() == sriovNetworkNodePolicyKind {
+			resourceName, _, _ := o.NestedString("spec", "resourceName")
+			nodePolicies[resourceName] = struct{}{}
+		}
+	}
+
+	resources := fn.KubeObjects{}
+	nadNames := map[string]struct{}{}
+	for _, o := range items.Where(fn.IsGroupVersionKind(nephioreqv1alpha1.InterfaceGroupVersionKind)) {
+		itfce, err := ko.KubeObjectToStruct[nephioreqv1alpha1.Interface](o)
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		if !isSRIOVInterface(o, itfce) {
+			continue
+		}
+		scope := condkptsdk.GetScope(o)
+		ipClaims, vlanClaims := getClaims(items, scope, itfce)
+		if !isResolved(ipClaims, vlanClaims) {
+			rl.Results.Infof("the claims of interface %s are not resolved, its SriovNetwork is not rendered", itfce.GetName())
+			continue
+		}
+		pool, err := getResourcePool(&cluster.Spec, itfce.GetName(), o.GetAnnotation(sriovResourcePoolAnnotation))
+		if err != nil {
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		name := fmt.Sprintf("%s-%s", scope.Owner.Name, itfce.GetName())
+		network, err := getSRIOVNetwork(name, scope, pool, ipClaims, vlanClaims)
+		if err != nil {
+			err = fmt.Errorf("cannot render the SriovNetwork of interface %s: %s", itfce.GetName(), err.Error())
+			rl.Results.ErrorE(err)
+			return false, err
+		}
+		resources = append(resources, network)
+		nadNames[name] = struct{}{}
+
+		if _, ok := nodePolicies[getResourceName(pool)]; !ok {
+			policy, err := getSRIOVNetworkNodePolicy(scope, pool, cluster.Spec.ClusterName)
+			if err != nil {
+				rl.Results.ErrorE(err)
+				return false, err
+			}
+			resources = append(resources, policy)
+			nodePolicies[getResourceName(pool)] = struct{}{}
+		}
+		rl.Results.Infof("SriovNetwork %s rendered for interface %s with resource pool %s", name, itfce.GetName(), pool.Name)
+	}
+
+	// the sriov network operator renders the NADs of the SriovNetworks, which replace the NADs of the package
+	rl.Items = fn.KubeObjects{}
+	for _, o := range items {
+		if _, ok := nadNames[o.GetName()]; ok && o.GetAPIVersion() == nadAPIVersion && o.GetKind() == nadKind {
+			continue
+		}
+		rl.Items = append(rl.Items, o)
+	}
+	rl.Items = append(rl.Items, resources...)
+	return true, nil
+}
+
+// isSRIOVInterface returns true for the interfaces attached to the pod with the sriov cni
+func isSRIOVInterface(o *fn.KubeObject, itfce *nephioreqv1alpha1.Interface) bool {
+	if itfce.Spec.NetworkInstance == nil || itfce.Spec.NetworkInstance.Name == defaultPODNetwork {
+		return false
+	}
+	if itfce.Spec.AttachmentType == nephioreqv1alpha1.AttachmentTypeNone {
+		return false
+	}
+	cniType := itfce.Spec.CNIType
+	if cniType == "" {
+		cniType = nephioreqv1alpha1.CNIType(o.GetAnnotation(cniTypeAnnotation))
+	}
+	return cniType == sriovCNIType
+}
+
+// getClaims returns the ip claims of the network instance of the interface and the vlan claims of
+// the interface, the claims of the additional network instances are attached with their own NADs
+func getClaims(objs fn.KubeObjects, scope condkptsdk.Scope, itfce *nephioreqv1alpha1.Interface) (fn.KubeObjects, fn.KubeObjects) {
+	ipClaims := fn.KubeObjects{}
+	vlanClaims := fn.KubeObjects{}
+	for _, o := range scope.Filter(objs) {
+		if o.GetAnnotation(condkptsdk.SpecializerOwner) != interfaceOwnerPrefix+itfce.GetName() {
+			continue
+		}
+		switch {
+		case o.IsGroupVersionKind(ipamv1alpha1.IPClaimGroupVersionKind):
+			if ni, _, _ := o.NestedString("spec", "networkInstance", "name"); ni == itfce.Spec.NetworkInstance.Name {
+				ipClaims = append(ipClaims, o)
+			}
+		case o.IsGroupVersionKind(vlanv1alpha1.VLANClaimGroupVersionKind):
+			vlanClaims = append(vlanClaims, o)
+		}
+	}
+	sort.Slice(ipClaims, func(i, j int) bool {
+		return ipClaims[i].GetName() < ipClaims[j].GetName()
+	})
+	return ipClaims, vlanClaims
+}
+
+// isResolved returns true when the ip claims have a prefix and the vlan claims a vlan
+func isResolved(ipClaims, vlanClaims fn.KubeObjects) bool {
+	for _, o := range ipClaims {
+		if _, ok, _ := o.NestedString("status", "prefix"); !ok {
+			return false
+		}
+	}
+	for _, o := range vlanClaims {
+		_, okID, _ := o.NestedInt("status", "vlanID")
+		_, okRange, _ := o.NestedString("status", "vlanRange")
+		if !okID && !okRange {
+			return false
+		}
+	}
+	return true
+}
+
+// getSRIOVNetwork returns the SriovNetwork of the interface with the vlan and the static
+// addresses of its claims; the SriovNetwork is deployed in the workload cluster and hence is not local config
+func getSRIOVNetwork(name string, scope condkptsdk.Scope, pool *wclib.SRIOVResourcePool, ipClaims, vlanClaims fn.KubeObjects) (*fn.KubeObject, error) {
+	spec := sriovNetworkSpec{
+		ResourceName:     getResourceName(pool),
+		NetworkNamespace: scope.Namespace,
+	}
+	for _, o := range vlanClaims {
+		claim, err := ko.KubeObjectToStruct[vlanv1alpha1.VLANClaim](o)
+		if err != nil {
+			return nil, err
+		}
+		if claim.Status.VLANRange != nil {
+			return nil, fmt.Errorf("vlan range %s of VLANClaim %s not supported, a SriovNetwork has a single vlan", *claim.Status.VLANRange, claim.GetName())
+		}
+		if spec.Vlan != 0 {
+			return nil, fmt.Errorf("multiple VLANClaims found, a SriovNetwork has a single vlan")
+		}
+		spec.Vlan = int(*claim.Status.VLANID)
+	}
+	if len(ipClaims) > 0 {
+		ipam := staticIPAM{Type: "static"}
+		for _, o := range ipClaims {
+			claim, err := ko.KubeObjectToStruct[ipamv1alpha1.IPClaim](o)
+			if err != nil {
+				return nil, err
+			}
+			address := staticAddress{Address: *claim.Status.Prefix}
+			if claim.Status.Gateway != nil {
+				address.Gateway = *claim.Status.Gateway
+			}
+			ipam.Addresses = append(ipam.Addresses, address)
+		}
+		b, err := json.Marshal(ipam)
+		if err != nil {
+			return nil, err
+		}
+		spec.IPAM = string(b)
+	}
+	return fn.NewFromTypedObject(&sriovNetwork{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: sriovNetworkAPIVersion,
+			Kind:       sriovNetworkKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: sriovNetworkOperatorNamespace,
+			Annotations: map[string]string{
+				sriovOperatorForAnnotation: getScopeFullName(scope),
+			},
+		},
+		Spec: spec,
+	})
+}
+
+// getSRIOVNetworkNodePolicy returns the SriovNetworkNodePolicy of the resource pool, named after
+// the resource name of the pool since the pool is shared by the interfaces
+func getSRIOVNetworkNodePolicy(scope condkptsdk.Scope, pool *wclib.SRIOVResourcePool, clusterName string) (*fn.KubeObject, error) {
+	spec, err := getNodePolicySpec(pool, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	return fn.NewFromTypedObject(&sriovNetworkNodePolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: sriovNetworkAPIVersion,
+			Kind:       sriovNetworkNodePolicyKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      strings.ReplaceAll(getResourceName(pool), "_", "-"),
+			Namespace: sriovNetworkOperatorNamespace,
+			Annotations: map[string]string{
+				sriovOperatorForAnnotation: getScopeFullName(scope),
+			},
+		},
+		Spec: *spec,
+	})
+}
+
+func getScopeFullName(scope condkptsdk.Scope) string {
+	return fmt.Sprintf("%s.%s.%s", scope.Owner.APIVersion, scope.Owner.Kind, scope.Owner.Name)
+}
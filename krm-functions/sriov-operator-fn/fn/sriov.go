@@ -0,0 +1,138 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"fmt"
+	"strings"
+
+	wclib "github.com/nephio-project/nephio/krm-functions/lib/workloadcluster"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	sriovNetworkAPIVersion        = "sriovnetwork.openshift.io/v1"
+	sriovNetworkKind              = "SriovNetwork"
+	sriovNetworkNodePolicyKind    = "SriovNetworkNodePolicy"
+	sriovNetworkOperatorNamespace = "sriov-network-operator"
+	sriovDefaultDeviceType        = "netdevice"
+	sriovCapableNodeLabel         = "feature.node.kubernetes.io/network-sriov.capable"
+	// sriovResourcePoolAnnotation selects the sriov resource pool of the workload cluster the interface is allocated from
+	sriovResourcePoolAnnotation = "nephio.org/sriov-resource-pool"
+)
+
+// sriovNetwork is the SriovNetwork of the sriov network operator, from which the operator
+// renders the NAD of the interface in the network namespace
+type sriovNetwork struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              sriovNetworkSpec `json:"spec,omitempty"`
+}
+
+type sriovNetworkSpec struct {
+	ResourceName     string `json:"resourceName"`
+	NetworkNamespace string `json:"networkNamespace,omitempty"`
+	Vlan             int    `json:"vlan,omitempty"`
+	IPAM             string `json:"ipam,omitempty"`
+}
+
+// staticIPAM is the static ipam config of the SriovNetwork with the addresses of the ip claims
+type staticIPAM struct {
+	Type      string          `json:"type"`
+	Addresses []staticAddress `json:"addresses,omitempty"`
+}
+
+type staticAddress struct {
+	Address string `json:"address"`
+	Gateway string `json:"gateway,omitempty"`
+}
+
+// sriovNetworkNodePolicy is the SriovNetworkNodePolicy of the sriov network operator, which
+// configures the virtual functions and the device plugin resource of a resource pool
+type sriovNetworkNodePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              sriovNetworkNodePolicySpec `json:"spec,omitempty"`
+}
+
+type sriovNetworkNodePolicySpec struct {
+	ResourceName string            `json:"resourceName"`
+	NodeSelector map[string]string `json:"nodeSelector"`
+	MTU          int               `json:"mtu,omitempty"`
+	NumVfs       int               `json:"numVfs"`
+	NicSelector  sriovNicSelector  `json:"nicSelector"`
+	DeviceType   string            `json:"deviceType,omitempty"`
+}
+
+type sriovNicSelector struct {
+	PfNames []string `json:"pfNames,omitempty"`
+}
+
+// getResourcePool returns the sriov resource pool of the workload cluster the interface is
+// allocated from; the pool is selected with the sriov resource pool annotation of the interface
+// and a single pool is selected by default
+func getResourcePool(r *wclib.WorkloadClusterExtSpec, itfceName, name string) (*wclib.SRIOVResourcePool, error) {
+	if len(r.SRIOVResourcePools) == 0 {
+		return nil, fmt.Errorf("workload cluster %s has no sriov resource pools", r.ClusterName)
+	}
+	if name == "" {
+		if len(r.SRIOVResourcePools) != 1 {
+			return nil, fmt.Errorf("interface %s requires the %s annotation since workload cluster %s has multiple sriov resource pools", itfceName, sriovResourcePoolAnnotation, r.ClusterName)
+		}
+		return &r.SRIOVResourcePools[0], nil
+	}
+	available := make([]string, 0, len(r.SRIOVResourcePools))
+	for i, p := range r.SRIOVResourcePools {
+		if p.Name == name {
+			return &r.SRIOVResourcePools[i], nil
+		}
+		available = append(available, p.Name)
+	}
+	return nil, fmt.Errorf("sriov resource pool %s requested by interface %s is not available in workload cluster %s; available resource pools: %v", name, itfceName, r.ClusterName, available)
+}
+
+// getResourceName returns the resource name of the pool without the resource prefix of the
+// operator, e.g. intel.com
+func getResourceName(r *wclib.SRIOVResourcePool) string {
+	if i := strings.LastIndex(r.Name, "/"); i >= 0 {
+		return r.Name[i+1:]
+	}
+	return r.Name
+}
+
+// getNodePolicySpec returns the spec of the SriovNetworkNodePolicy of the resource pool
+func getNodePolicySpec(r *wclib.SRIOVResourcePool, clusterName string) (*sriovNetworkNodePolicySpec, error) {
+	if len(r.PFNames) == 0 || r.NumVFs == 0 {
+		return nil, fmt.Errorf("sriov resource pool %s of workload cluster %s requires pfNames and numVfs", r.Name, clusterName)
+	}
+	nodeSelector := r.NodeSelector
+	if len(nodeSelector) == 0 {
+		nodeSelector = map[string]string{sriovCapableNodeLabel: "true"}
+	}
+	deviceType := r.DeviceType
+	if deviceType == "" {
+		deviceType = sriovDefaultDeviceType
+	}
+	return &sriovNetworkNodePolicySpec{
+		ResourceName: getResourceName(r),
+		NodeSelector: nodeSelector,
+		MTU:          r.MTU,
+		NumVfs:       r.NumVFs,
+		NicSelector:  sriovNicSelector{PfNames: r.PFNames},
+		DeviceType:   deviceType,
+	}, nil
+}
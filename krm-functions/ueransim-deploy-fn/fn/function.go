@@ -46,10 +46,10 @@ func Run(rl *fn.ResourceList) (bool, error) {
 	myFn.sdk, err = condkptsdk.New(
 		rl,
 		&condkptsdk.Config{
-			For: corev1.ObjectReference{
+			For: []corev1.ObjectReference{{
 				APIVersion: appsv1.SchemeGroupVersion.Identifier(),
 				Kind:       reflect.TypeOf(appsv1.Deployment{}).Name(),
-			},
+			}},
 			Owns: map[corev1.ObjectReference]condkptsdk.ResourceKind{
 				{
 					APIVersion: nephioreqv1alpha1.GroupVersion.Identifier(),
@@ -92,7 +92,7 @@ func (f *deployFn) WorkloadClusterCallbackFn(o *fn.KubeObject) error {
 	if f.workloadCluster != nil {
 		return fmt.Errorf("multiple WorkloadCluster objects found in the kpt package")
 	}
-	f.workloadCluster, err = ko.KubeObjectToStruct[infrav1alpha1.WorkloadCluster](o)
+	f.workloadCluster, err = ko.CachedKubeObjectToStruct[infrav1alpha1.WorkloadCluster](o)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,60 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// backendKey is the key in the data of the function config ConfigMap that selects the vlan backend
+	// resolving the vlan claims, by default the vlan claims are resolved by the backend the fn is created with
+	backendKey = "backend"
+	// staticBackend resolves the vlan claims from the VLAN resources in the package
+	staticBackend = "static"
+)
+
+// Backend resolves the vlan claims of the package. A clientproxy of the k8s-ipam controller
+// implements the Backend, alternative implementations resolve the vlan claims from
+// the vlans pre-assigned in the package
+type Backend interface {
+	// GetClaim returns the vlan claim with the status of the existing vlan
+	GetClaim(ctx context.Context, cr client.Object, d any) (*vlanv1alpha1.VLANClaim, error)
+	// Claim returns the vlan claim with the status of the claimed vlan
+	Claim(ctx context.Context, cr client.Object, d any) (*vlanv1alpha1.VLANClaim, error)
+}
+
+// getBackend returns the backend selected by the function config
+func (f *FnR) getBackend(rl *fn.ResourceList) (Backend, error) {
+	backend := ""
+	if rl.FunctionConfig != nil {
+		backend, _, _ = rl.FunctionConfig.NestedString("data", backendKey)
+	}
+	switch backend {
+	case "":
+		return f.Backend, nil
+	case staticBackend:
+		return newStaticMapping(rl.Items)
+	default:
+		return nil, fmt.Errorf("backend %s not supported, supported backends: [%s]", backend, staticBackend)
+	}
+}
@@ -25,24 +25,25 @@ import (
 	"github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
 	resourcev1alpha1 "github.com/nokia/k8s-ipam/apis/resource/common/v1alpha1"
 	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
-	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy"
 	corev1 "k8s.io/api/core/v1"
 )
 
 type FnR struct {
-	ClientProxy clientproxy.Proxy[*vlanv1alpha1.VLANIndex, *vlanv1alpha1.VLANClaim]
-	sdkConfig   *condkptsdk.Config
+	Backend   Backend
+	sdkConfig *condkptsdk.Config
+	// backend is the backend of the run, the Backend or the backend selected by the function config
+	backend Backend
 }
 
-func New(c clientproxy.Proxy[*vlanv1alpha1.VLANIndex, *vlanv1alpha1.VLANClaim]) *FnR {
+func New(b Backend) *FnR {
 	f := &FnR{
-		ClientProxy: c,
+		Backend: b,
 	}
 	f.sdkConfig = &condkptsdk.Config{
-		For: corev1.ObjectReference{
+		For: []corev1.ObjectReference{{
 			APIVersion: vlanv1alpha1.GroupVersion.Identifier(),
 			Kind:       vlanv1alpha1.VLANClaimKind,
-		},
+		}},
 		PopulateOwnResourcesFn: nil,
 		UpdateResourceFn:       f.updateVLANClaimResource,
 	}
@@ -54,6 +55,12 @@ func (f *FnR) GetConfig() condkptsdk.Config {
 }
 
 func (f *FnR) Run(rl *fn.ResourceList) (bool, error) {
+	backend, err := f.getBackend(rl)
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, err
+	}
+	f.backend = backend
 	sdk, err := condkptsdk.New(
 		rl,
 		f.sdkConfig,
@@ -80,12 +87,21 @@ func (f *FnR) updateVLANClaimResource(forObj *fn.KubeObject, objs fn.KubeObjects
 	if err != nil {
 		return nil, err
 	}
+	// a range claims a trunk of vlans, either as start:end or as a size
+	claimCtx, err := claim.GetVLANClaimCtx()
+	if err != nil {
+		return nil, fmt.Errorf("invalid vlan claim %s: %s", claim.GetName(), err.Error())
+	}
+	isRange := claimCtx.Kind == vlanv1alpha1.VLANClaimTypeRange || claimCtx.Kind == vlanv1alpha1.VLANClaimTypeSize
+	if isRange && claimCtx.Size == 0 {
+		return nil, fmt.Errorf("invalid vlan claim %s: the range %s is empty", claim.GetName(), *claim.Spec.VLANRange)
+	}
 	newclaim := claim.DeepCopy()
 	var resp *vlanv1alpha1.VLANClaim
 	if _, ok := claim.Annotations[resourcev1alpha1.NephioAPIAction]; ok {
 		// get action
 		fn.Log("claim action get\n")
-		resp, err = f.ClientProxy.GetClaim(context.Background(), newclaim, nil)
+		resp, err = f.backend.GetClaim(context.Background(), newclaim, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -93,15 +109,21 @@ func (f *FnR) updateVLANClaimResource(forObj *fn.KubeObject, objs fn.KubeObjects
 		// claim action
 		fn.Log("claim action claim\n")
 		newclaim.Name = claim.GetAnnotations()[condkptsdk.SpecializervlanClaimName]
-		resp, err = f.ClientProxy.Claim(context.Background(), newclaim, nil)
+		resp, err = f.backend.Claim(context.Background(), newclaim, nil)
 		if err != nil {
 			return nil, err
 		}
 	}
+	if isRange && resp.Status.VLANRange == nil {
+		return nil, fmt.Errorf("vlan backend returned no vlan range for vlan claim %s with range %s", claim.GetName(), *claim.Spec.VLANRange)
+	}
 	claim.Status = resp.Status
 	if claim.Status.VLANID != nil {
 		fn.Logf("claim resp vlan: %v\n", *resp.Status.VLANID)
 	}
+	if claim.Status.VLANRange != nil {
+		fn.Logf("claim resp vlan range: %v\n", *resp.Status.VLANRange)
+	}
 	// set the status
 	err = claimKOE.SetStatus(resp)
 	return fn.KubeObjects{&claimKOE.KubeObject}, err
@@ -0,0 +1,120 @@
+/*
+ Copyright 2023 The Nephio Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package fn
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	ko "github.com/nephio-project/nephio/krm-functions/lib/kubeobject"
+	vlanv1alpha1 "github.com/nokia/k8s-ipam/apis/resource/vlan/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// networkInstanceKey is the label of a VLAN resource in the package that maps
+// the network instance to the vlan of the VLAN resource
+const networkInstanceKey = "nephio.org/network-instance"
+
+// staticMapping resolves the vlan claims of a package from the VLAN resources in the package,
+// which statically map the network instances of a vlan index to the vlan ids pre-assigned
+// by the transport team
+type staticMapping struct {
+	// vlans holds the VLAN resources per vlan index and network instance
+	vlans map[string]map[string]vlanv1alpha1.VLAN
+}
+
+func newStaticMapping(objs fn.KubeObjects) (Backend, error) {
+	r := &staticMapping{
+		vlans: map[string]map[string]vlanv1alpha1.VLAN{},
+	}
+	vlans, err := ko.KubeObjectsToStructs[vlanv1alpha1.VLAN](objs.Where(fn.IsGroupVersionKind(vlanv1alpha1.VLANGroupVersionKind)))
+	if err != nil {
+		return nil, err
+	}
+	for _, vlan := range vlans {
+		ni, ok := vlan.Spec.Labels[networkInstanceKey]
+		if !ok {
+			return nil, fmt.Errorf("VLAN %s has no %s label", vlan.GetName(), networkInstanceKey)
+		}
+		if vlan.Spec.VLANID == nil && vlan.Spec.VLANRange == nil {
+			return nil, fmt.Errorf("VLAN %s has no vlanID or range", vlan.GetName())
+		}
+		index := vlan.Spec.VLANIndex.Name
+		if _, ok := r.vlans[index]; !ok {
+			r.vlans[index] = map[string]vlanv1alpha1.VLAN{}
+		}
+		if x, ok := r.vlans[index][ni]; ok {
+			return nil, fmt.Errorf("VLAN %s and %s map the same network instance %s in vlan index %s", x.GetName(), vlan.GetName(), ni, index)
+		}
+		r.vlans[index][ni] = vlan
+	}
+	return r, nil
+}
+
+func (r *staticMapping) GetClaim(ctx context.Context, cr client.Object, d any) (*vlanv1alpha1.VLANClaim, error) {
+	return r.getClaim(cr)
+}
+
+func (r *staticMapping) Claim(ctx context.Context, cr client.Object, d any) (*vlanv1alpha1.VLANClaim, error) {
+	return r.getClaim(cr)
+}
+
+func (r *staticMapping) getClaim(cr client.Object) (*vlanv1alpha1.VLANClaim, error) {
+	claim, ok := cr.(*vlanv1alpha1.VLANClaim)
+	if !ok {
+		return nil, fmt.Errorf("expecting VLANClaim, got: %v", reflect.TypeOf(cr))
+	}
+	index := claim.Spec.VLANIndex.Name
+	ni := getNetworkInstance(claim.GetName(), index)
+	vlan, ok := r.vlans[index][ni]
+	if !ok {
+		return nil, fmt.Errorf("no VLAN in the package maps network instance %s in vlan index %s for vlan claim %s", ni, index, claim.GetName())
+	}
+	if claim.Spec.VLANRange != nil {
+		// a range claim maps to a trunk of vlans as start:end
+		if vlan.Spec.VLANRange == nil || len(strings.Split(*vlan.Spec.VLANRange, ":")) != 2 {
+			return nil, fmt.Errorf("VLAN %s mapped by range vlan claim %s has no start:end range", vlan.GetName(), claim.GetName())
+		}
+		claimCtx, err := claim.GetVLANClaimCtx()
+		if err != nil {
+			return nil, err
+		}
+		vlanCtx, err := (&vlanv1alpha1.VLANClaim{Spec: vlanv1alpha1.VLANClaimSpec{VLANRange: vlan.Spec.VLANRange}}).GetVLANClaimCtx()
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %s in VLAN %s: %s", *vlan.Spec.VLANRange, vlan.GetName(), err.Error())
+		}
+		if claimCtx.Size != vlanCtx.Size {
+			return nil, fmt.Errorf("VLAN %s range %s does not match the range %s of vlan claim %s", vlan.GetName(), *vlan.Spec.VLANRange, *claim.Spec.VLANRange, claim.GetName())
+		}
+		claim.Status.VLANRange = vlan.Spec.VLANRange
+		return claim, nil
+	}
+	if vlan.Spec.VLANID == nil {
+		return nil, fmt.Errorf("VLAN %s mapped by vlan claim %s has no vlanID", vlan.GetName(), claim.GetName())
+	}
+	claim.Status.VLANID = vlan.Spec.VLANID
+	return claim, nil
+}
+
+// getNetworkInstance returns the network instance of a claim, the claim is named after the
+// bridge domain <network instance>-<vlan index>-bd of the network instance in the vlan index
+func getNetworkInstance(name, index string) string {
+	return strings.TrimSuffix(name, fmt.Sprintf("-%s-bd", index))
+}
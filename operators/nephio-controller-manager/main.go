@@ -21,10 +21,16 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/nephio-project/nephio/controllers/pkg/catalog"
 	porchclient "github.com/nephio-project/nephio/controllers/pkg/porch/client"
 	ctrlrconfig "github.com/nephio-project/nephio/controllers/pkg/reconcilers/config"
 	reconciler "github.com/nephio-project/nephio/controllers/pkg/reconcilers/reconciler-interface"
+	"github.com/nephio-project/nephio/controllers/pkg/sharding"
+	"github.com/nephio-project/nephio/controllers/pkg/specializer"
+	"github.com/nephio-project/nephio/controllers/pkg/webhook"
+	"github.com/nephio-project/nephio/krm-functions/lib/tracing"
 	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy"
 	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy/ipam"
 	"github.com/nokia/k8s-ipam/pkg/proxy/clientproxy/vlan"
@@ -51,11 +57,14 @@ import (
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/approval"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/bootstrap-packages"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/bootstrap-secret"
+	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/drift-detection"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/generic-specializer"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/network"
+	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/package-catalog"
 
 	//_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/ipam-specializer"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/repository"
+	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/specialization-status"
 	_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/token"
 	//_ "github.com/nephio-project/nephio/controllers/pkg/reconcilers/vlan-specializer"
 )
@@ -69,6 +78,14 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var enabledReconcilersString string
+	var specializerGRPCAddr string
+	var specializerHTTPAddr string
+	var shards int
+	var shardIndex int
+	var shardBy string
+	var enableWebhooks bool
+	var catalogGRPCAddr string
+	var catalogHTTPAddr string
 
 	//klog.InitFlags(nil)
 
@@ -78,6 +95,14 @@ func main() {
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&enabledReconcilersString, "reconcilers", "", "reconcilers that should be enabled; use * to mean 'enable all'")
+	flag.StringVar(&specializerGRPCAddr, "specializer-grpc-bind-address", "", "The address the grpc api of the specializer service binds to; disabled when empty.")
+	flag.StringVar(&specializerHTTPAddr, "specializer-http-bind-address", "", "The address the http api of the specializer service binds to; disabled when empty.")
+	flag.IntVar(&shards, "shards", 1, "The number of shards the reconciles are partitioned into; sharding is disabled with 1 shard.")
+	flag.IntVar(&shardIndex, "shard-index", -1, "The shard reconciled by this replica; by default the ordinal of the statefulset pod, which makes the pod the only replica of its shard: the shard is not reconciled until the pod is restarted. Set it explicitly on multiple replicas with --leader-elect for a shard to fail over.")
+	flag.StringVar(&shardBy, "shard-by", string(sharding.ByNamespace), "The key assigning a request to a shard: namespace or name.")
+	flag.StringVar(&catalogGRPCAddr, "catalog-grpc-bind-address", "", "The address the grpc api of the package catalog binds to; disabled when empty.")
+	flag.StringVar(&catalogHTTPAddr, "catalog-http-bind-address", "", "The address the http api of the package catalog binds to; disabled when empty.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "Enable the admission webhooks validating the requirements and workload clusters; the serving certificate is read from /tmp/k8s-webhook-server/serving-certs.")
 
 	opts := zap.Options{
 		Development: true,
@@ -108,14 +133,36 @@ func main() {
 		os.Exit(1)
 	}
 
+	shard := sharding.Shard{Shards: shards, Index: shardIndex, By: sharding.Mode(shardBy)}
+	if shard.Enabled() && shard.Index < 0 {
+		hostname, err := os.Hostname()
+		if err != nil {
+			setupLog.Error(err, "cannot get hostname")
+			os.Exit(1)
+		}
+		if shard.Index, err = sharding.IndexFromHostname(hostname); err != nil {
+			setupLog.Error(err, "cannot get shard index, set --shard-index")
+			os.Exit(1)
+		}
+	}
+	if err := shard.Validate(); err != nil {
+		setupLog.Error(err, "invalid sharding")
+		os.Exit(1)
+	}
+
 	managerOptions := ctrl.Options{
 		Scheme:                     scheme,
 		MetricsBindAddress:         metricsAddr,
 		Port:                       9443,
 		HealthProbeBindAddress:     probeAddr,
-		LeaderElection:             false,
-		LeaderElectionID:           "nephio-operators.nephio.org",
+		LeaderElection:             enableLeaderElection,
+		LeaderElectionID:           shard.LeaderElectionID("nephio-operators.nephio.org"),
 		LeaderElectionResourceLock: resourcelock.LeasesResourceLock,
+		// the leader steps down on shutdown, so that a replica takes over without waiting for the lease to expire
+		LeaderElectionReleaseOnCancel: true,
+	}
+	if ns, ok := os.LookupEnv("POD_NAMESPACE"); ok {
+		managerOptions.LeaderElectionNamespace = ns
 	}
 
 	ctrl.SetLogger(klogr.New())
@@ -158,6 +205,8 @@ func main() {
 		VlanClientProxy: vlan.New(ctx, clientproxy.Config{
 			Address: backendAddress,
 		}),
+		Shard:   shard,
+		Catalog: catalog.New(),
 	}
 
 	enabledReconcilers := parseReconcilers(enabledReconcilersString)
@@ -182,6 +231,34 @@ func main() {
 		//klog.Infof("enabled reconcilers: %v", strings.Join(enabled, ","))
 	}
 
+	if specializerGRPCAddr != "" || specializerHTTPAddr != "" {
+		if err := mgr.Add(specializer.NewServer(specializer.NewPipeline(ctrlCfg), specializerGRPCAddr, specializerHTTPAddr)); err != nil {
+			setupLog.Error(err, "cannot add specializer service")
+			os.Exit(1)
+		}
+	}
+
+	if catalogGRPCAddr != "" || catalogHTTPAddr != "" {
+		if err := mgr.Add(catalog.NewServer(ctrlCfg.Catalog, catalogGRPCAddr, catalogHTTPAddr)); err != nil {
+			setupLog.Error(err, "cannot add package catalog service")
+			os.Exit(1)
+		}
+	}
+
+	if enableWebhooks {
+		if err := webhook.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "cannot setup webhooks")
+			os.Exit(1)
+		}
+	}
+
+	// the spans of the reconciles are exported to the OTLP endpoint of the environment, when set
+	tracer := tracing.NewTracerFromEnv("nephio-controller-manager")
+	tracing.SetTracer(tracer)
+	go tracer.Run(ctx, 5*time.Second, func(err error) {
+		setupLog.Error(err, "cannot export spans")
+	})
+
 	//+kubebuilder:scaffold:builder
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -192,7 +269,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	setupLog.Info("starting manager")
+	setupLog.Info("starting manager", "leaderElection", enableLeaderElection, "shards", shard.Shards, "shardIndex", shard.Index)
 	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)